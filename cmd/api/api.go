@@ -3,10 +3,42 @@ package main
 // Gin boilerplate with ping endpoint
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/apiauth"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/apiclient"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/store"
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
 )
 
 func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, relying on environment variables")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	activities := temporal.NewActivities(cfg)
+	auth := apiauth.New(cfg.API.Keys, cfg.API.AdminKeys, cfg.API.JWTSecret)
+	if !auth.Enabled() {
+		log.Println("Neither API_KEYS, API_ADMIN_KEYS nor API_JWT_SECRET is set, the API is open to everyone")
+	}
+	limiter := apiauth.NewLimiter(cfg.API.RateLimit, cfg.API.RateBurst)
+
 	r := gin.Default()
 
 	r.GET("/ping", func(c *gin.Context) {
@@ -15,5 +47,262 @@ func main() {
 		})
 	})
 
+	// OpenAPI specification of the API, from which pkg/apiclient is generated
+	r.GET("/openapi.yaml", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/yaml", apiclient.Spec)
+	})
+
+	// Queries of the ledger, open to read-only and admin clients
+	v1 := r.Group("/v1", authenticate(auth, limiter), requireScope(apiauth.ScopeRead))
+
+	// Inclusion proof of the event of a domain against the Merkle root anchored to HCS
+	v1.GET("/proofs/:domain", func(c *gin.Context) {
+		if _, err := domain.NewDomainName(c.Param("domain")); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		proof, err := activities.InclusionProofActivity(c.Request.Context(), c.Param("domain"))
+		switch {
+		case errors.Is(err, temporal.ErrNotAnchored):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusOK, proof)
+		}
+	})
+
+	// Ledger entry of a domain: zone, collection, serial, mint transaction and current status
+	v1.GET("/domains/:name", func(c *gin.Context) {
+		dn, err := domain.NewDomainName(c.Param("name"))
+		if err == nil && dn.ParentDomain() == "" {
+			err = fmt.Errorf("%s is a zone, not a domain within a zone", dn.String())
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		record, err := activities.LookupDomainActivity(c.Request.Context(), dn.String())
+		switch {
+		case errors.Is(err, temporal.ErrDomainNotMinted):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusOK, record)
+		}
+	})
+
+	// History of a domain: the transactions of every NFT it had, its mint receipts and anchors, oldest first
+	v1.GET("/domains/:name/history", func(c *gin.Context) {
+		dn, err := domain.NewDomainName(c.Param("name"))
+		if err == nil && dn.ParentDomain() == "" {
+			err = fmt.Errorf("%s is a zone, not a domain within a zone", dn.String())
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		history, err := activities.DomainHistoryActivity(c.Request.Context(), dn.String())
+		switch {
+		case errors.Is(err, temporal.ErrDomainNotMinted):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusOK, history)
+		}
+	})
+
+	// Zones of the zone registry with their collections
+	v1.GET("/zones", func(c *gin.Context) {
+		zones, err := activities.ListZonesActivity(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"zones": zones})
+	})
+
+	// Domains of a zone in the order they were minted, a page at a time
+	v1.GET("/zones/:zone/domains", func(c *gin.Context) {
+		query, err := zoneDomainsQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		page, err := activities.ListZoneDomainsActivity(c.Request.Context(), query)
+		switch {
+		case errors.Is(err, temporal.ErrInvalidCursor):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusOK, page)
+		}
+	})
+
+	// Live mints, burns and transfers: pushed by the workers with an admin key, without rate limit as they
+	// come at the pace of the ledger, and streamed to clients as server-sent events
+	hub := newActivityHub()
+	r.POST("/v1/activity", authenticate(auth, nil), requireScope(apiauth.ScopeAdmin), pushActivity(hub))
+	v1.GET("/activity/stream", streamActivity(hub))
+
+	// Aggregates of the registry store backing the monitoring dashboard
+	v1.GET("/stats/mints", statsHandler("days", activities.MintsPerDayActivity))
+	v1.GET("/stats/failures", statsHandler("zones", activities.FailureRatesActivity))
+	v1.GET("/stats/fees", statsHandler("days", activities.FeesPerDayActivity))
+	v1.GET("/stats/registrars", func(c *gin.Context) {
+		limit := defaultTopRegistrars
+		if v := c.Query("limit"); v != "" {
+			var err error
+			if limit, err = strconv.Atoi(v); err != nil || limit <= 0 || limit > maxTopRegistrars {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit: %q is not a count between 1 and %d", v, maxTopRegistrars)})
+				return
+			}
+		}
+		statsHandler("registrars", func(ctx context.Context, q store.StatsQuery) ([]store.RegistrarCount, error) {
+			return activities.TopRegistrarsActivity(ctx, q, limit)
+		})(c)
+	})
+
 	r.Run()
 }
+
+// authenticate admits requests with an API key or JSON Web Token, as bearer token or X-API-Key header, within
+// the rate limit of their client, if any. Requests are admitted with the read scope without credentials when
+// none are configured.
+func authenticate(auth *apiauth.Authenticator, limiter *apiauth.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client := apiauth.Client{Name: c.ClientIP(), Scope: apiauth.ScopeRead}
+		if auth.Enabled() {
+			credential, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+			if !ok {
+				credential = c.GetHeader("X-API-Key")
+			}
+			var err error
+			if client, err = auth.Authenticate(credential); err != nil {
+				c.Header("WWW-Authenticate", `Bearer realm="api"`)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if limiter != nil {
+			if ok, wait := limiter.Allow(client.Name); !ok {
+				c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded, see API_RATE_LIMIT"})
+				return
+			}
+		}
+		c.Set("client", client)
+		c.Next()
+	}
+}
+
+// requireScope rejects the requests of authenticated clients that do not hold the scope
+func requireScope(scope apiauth.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if client := c.MustGet("client").(apiauth.Client); !client.Allows(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("%s requires the %s scope", client.Name, scope)})
+			return
+		}
+		c.Next()
+	}
+}
+
+// Defaults of the dashboard statistics
+const (
+	defaultStatsPeriod   = 30 * 24 * time.Hour
+	defaultTopRegistrars = 10
+	maxTopRegistrars     = 100
+)
+
+// statsHandler answers the rows of a statistic of the registry store as the named list, for the period and zone
+// of statsQuery
+func statsHandler[T any](key string, stats func(context.Context, store.StatsQuery) ([]T, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query, err := statsQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		rows, err := stats(c.Request.Context(), query)
+		switch {
+		case errors.Is(err, temporal.ErrNoRegistryStore):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		default:
+			if rows == nil {
+				rows = []T{}
+			}
+			c.JSON(http.StatusOK, gin.H{"since": query.Since, "until": query.Until, key: rows})
+		}
+	}
+}
+
+// statsQuery reads the period and zone of a statistic: ?since=<RFC 3339 time>, 30 days ago by default,
+// ?until=<RFC 3339 time>, now by default, and ?zone=<zone>, all zones by default
+func statsQuery(c *gin.Context) (store.StatsQuery, error) {
+	query := store.StatsQuery{Until: time.Now().UTC().Truncate(time.Second)}
+	query.Since = query.Until.Add(-defaultStatsPeriod)
+	for name, t := range map[string]*time.Time{"since": &query.Since, "until": &query.Until} {
+		if v := c.Query(name); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return query, fmt.Errorf("%s: %q is not an RFC 3339 time", name, v)
+			}
+			*t = parsed.UTC()
+		}
+	}
+	if !query.Since.Before(query.Until) {
+		return query, errors.New("since must be before until")
+	}
+	if v := c.Query("zone"); v != "" {
+		zone, err := domain.NewZone(v)
+		if err != nil {
+			return query, err
+		}
+		query.Zone = zone
+	}
+	return query, nil
+}
+
+// Page sizes of the domain listings
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// zoneDomainsQuery reads the query of a domain listing: ?status=minted|burned, ?created_after=<RFC 3339 time>,
+// ?limit=<page size> and ?cursor=<next of the previous page>
+func zoneDomainsQuery(c *gin.Context) (temporal.ZoneDomainsQuery, error) {
+	query := temporal.ZoneDomainsQuery{
+		Status: c.Query("status"),
+		Cursor: c.Query("cursor"),
+		Limit:  defaultPageSize,
+	}
+	zone, err := domain.NewZone(c.Param("zone"))
+	if err != nil {
+		return query, err
+	}
+	query.Zone = zone
+	if query.Status != "" && query.Status != store.StatusMinted && query.Status != store.StatusBurned {
+		return query, fmt.Errorf("status: %q is neither %s nor %s", query.Status, store.StatusMinted, store.StatusBurned)
+	}
+	if v := c.Query("created_after"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return query, fmt.Errorf("created_after: %q is not an RFC 3339 time", v)
+		}
+		query.CreatedAfter = t
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxPageSize {
+			return query, fmt.Errorf("limit: %q is not a page size between 1 and %d", v, maxPageSize)
+		}
+		query.Limit = limit
+	}
+	return query, nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+const (
+	recentActivity     = 1000             // Events kept for clients resuming with Last-Event-ID
+	subscriberBuffer   = 256              // Events queued for a client before it is dropped as too slow
+	keepAliveInterval  = 15 * time.Second // Comments keeping idle streams open through proxies
+	maxActivityPayload = 64 << 10
+)
+
+// activityEvent is a ledger event numbered in the order the API server received it
+type activityEvent struct {
+	ID    uint64
+	Event temporal.LedgerEvent
+}
+
+// activityHub fans the ledger events pushed by the workers out to the clients of the activity stream. Events are
+// numbered from 1 when the server starts, and the most recent ones are kept for clients that reconnect.
+type activityHub struct {
+	mu          sync.Mutex
+	lastID      uint64
+	recent      []activityEvent
+	subscribers map[chan activityEvent]struct{}
+}
+
+func newActivityHub() *activityHub {
+	return &activityHub{subscribers: make(map[chan activityEvent]struct{})}
+}
+
+// publish numbers an event and sends it to every subscriber. Subscribers too slow to keep up are dropped,
+// their clients reconnect and resume from the recent events.
+func (h *activityHub) publish(event temporal.LedgerEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastID++
+	e := activityEvent{ID: h.lastID, Event: event}
+	h.recent = append(h.recent, e)
+	if len(h.recent) > recentActivity {
+		h.recent = h.recent[len(h.recent)-recentActivity:]
+	}
+	for ch := range h.subscribers {
+		select {
+		case ch <- e:
+		default:
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// subscribe returns the channel of the events after lastID, starting with the recent ones still kept, and the
+// function ending the subscription
+func (h *activityHub) subscribe(lastID uint64) (<-chan activityEvent, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ch := make(chan activityEvent, subscriberBuffer+recentActivity)
+	for _, e := range h.recent {
+		if e.ID > lastID {
+			ch <- e
+		}
+	}
+	h.subscribers[ch] = struct{}{}
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subscribers[ch]; ok {
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// pushActivity receives a ledger event from a worker
+func pushActivity(hub *activityHub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var event temporal.LedgerEvent
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxActivityPayload)
+		if err := c.ShouldBindJSON(&event); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		switch {
+		case event.Type != temporal.LedgerEventMint && event.Type != temporal.LedgerEventBurn && event.Type != temporal.LedgerEventTransfer:
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown event type %q", event.Type)})
+			return
+		case event.Domain == "" || event.Zone == "":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "domain and zone are required"})
+			return
+		}
+		hub.publish(event)
+		c.Status(http.StatusAccepted)
+	}
+}
+
+// streamActivity streams the ledger events as server-sent events, of the ?zone= and ?type= given only.
+// A client reconnecting with the Last-Event-ID header first gets the recent events it missed.
+func streamActivity(hub *activityHub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		zone, eventType := c.Query("zone"), c.Query("type")
+		lastID, _ := strconv.ParseUint(c.GetHeader("Last-Event-ID"), 10, 64)
+		events, unsubscribe := hub.subscribe(lastID)
+		defer unsubscribe()
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("X-Accel-Buffering", "no") // Disables the buffering of nginx
+		c.Status(http.StatusOK)
+		c.Writer.Flush()
+
+		keepAlive := time.NewTicker(keepAliveInterval)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case <-c.Request.Context().Done():
+				return
+			case <-keepAlive.C:
+				fmt.Fprint(c.Writer, ": keep-alive\n\n")
+			case e, ok := <-events:
+				if !ok {
+					return // Too slow, the client resumes from Last-Event-ID
+				}
+				if (zone != "" && e.Event.Zone != zone) || (eventType != "" && e.Event.Type != eventType) {
+					continue
+				}
+				data, err := json.Marshal(e.Event)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Event.Type, data)
+			}
+			c.Writer.Flush()
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+// Intake server: registries push their events over HTTP instead of dropping log files
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	temporalsdk "go.temporal.io/sdk/temporal"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+// maxBodyBytes caps the size of a pushed request
+const maxBodyBytes = 16 << 20
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, relying on environment variables")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if len(cfg.Intake.Tokens) == 0 {
+		log.Fatalln("INTAKE_TOKENS is required, the intake does not accept unauthenticated events")
+	}
+	temporalClient, err := temporal.Dial(cfg)
+	if err != nil {
+		log.Fatalln("Unable to create Temporal client", err)
+	}
+	defer temporalClient.Close()
+	activities := temporal.NewActivities(cfg)
+
+	r := gin.Default()
+
+	r.GET("/ping", func(c *gin.Context) {
+		c.JSON(200, gin.H{
+			"message": "pong",
+		})
+	})
+
+	// Push of one event ({"registry-event":{...},"sig":"..."}) or an array of them. The batch is accepted or
+	// refused as a whole, and ingested like a log file containing its events.
+	r.POST("/v1/events", authenticate(cfg.Intake.Tokens), func(c *gin.Context) {
+		source := c.GetString("source")
+		events, err := readEvents(c, cfg.Intake.MaxEvents)
+		if err != nil {
+			status := http.StatusBadRequest
+			var tooLarge *http.MaxBytesError
+			if errors.Is(err, errTooManyEvents) || errors.As(err, &tooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx := c.Request.Context()
+		validation, err := activities.ValidateIntakeEventsActivity(ctx, events)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if len(validation.Rejected) > 0 {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "events refused, nothing was ingested", "rejected": validation.Rejected})
+			return
+		}
+
+		file, err := activities.SpoolIntakeActivity(ctx, source, validation.Lines)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		options := temporal.IngestWorkflowOptions(cfg.Temporal, file.ContentHash)
+
+		// A batch pushed again is not ingested twice
+		previous, err := temporal.LookupIngestRun(ctx, temporalClient, cfg.Temporal, temporal.IngestLedgerQuery{ContentHash: file.ContentHash})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if previous.Outcome == temporal.IngestOutcomeCompleted {
+			c.JSON(http.StatusOK, gin.H{"status": "duplicate", "workflow_id": previous.WorkflowID, "content_hash": file.ContentHash})
+			return
+		}
+
+		we, err := temporalClient.ExecuteWorkflow(ctx, options, temporal.IngestFileWorkflow, temporal.IngestRequest{
+			FilePath:       file.FilePath,
+			ContentHash:    file.ContentHash,
+			ZoneTaskQueues: temporal.ZoneTaskQueues(cfg.Temporal.TaskQueue, cfg.Temporal.ShardedZones),
+			HCS:            cfg.HCS,
+			Zones:          cfg.Zones,
+			MaxMints:       cfg.Limits.MaxMintsPerRun,
+			BudgetTinybar:  cfg.Limits.BudgetTinybar(),
+			BudgetUSD:      cfg.Limits.BudgetUSD,
+			Notify:         cfg.Notify.Enabled(),
+			FailureAlert:   cfg.Notify.FailureThreshold,
+			EmailReport:    cfg.Email.Enabled(),
+			ArchiveReport:  cfg.Reports.Bucket != "",
+			EscalateAfter:  cfg.Escalation.Threshold(),
+
+			VisibilityTimeout: cfg.Mirror.VisibilityTimeout,
+		})
+		if temporalsdk.IsWorkflowExecutionAlreadyStartedError(err) {
+			c.JSON(http.StatusOK, gin.H{"status": "duplicate", "workflow_id": options.ID, "content_hash": file.ContentHash})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "unable to start ingestion: " + err.Error()})
+			return
+		}
+		log.Printf("%s pushed %d events, ingesting them with workflow %s", source, len(events), we.GetID())
+		c.JSON(http.StatusAccepted, gin.H{
+			"status":       "accepted",
+			"events":       len(events),
+			"content_hash": file.ContentHash,
+			"workflow_id":  we.GetID(),
+			"run_id":       we.GetRunID(),
+		})
+	})
+
+	if err := r.Run(cfg.Intake.ListenAddr); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+var errTooManyEvents = errors.New("too many events in one request, see INTAKE_MAX_EVENTS")
+
+// authenticate admits requests with a bearer token of INTAKE_TOKENS and records the name of the token as source
+func authenticate(tokens map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if ok && token != "" {
+			for name, expected := range tokens {
+				if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1 {
+					c.Set("source", name)
+					c.Next()
+					return
+				}
+			}
+		}
+		c.Header("WWW-Authenticate", `Bearer realm="intake"`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid token"})
+	}
+}
+
+// readEvents reads the body of a push, a single event object or an array of at most maxEvents events
+func readEvents(c *gin.Context, maxEvents int) ([]json.RawMessage, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes))
+	if err != nil {
+		return nil, err
+	}
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, errors.New("no events in request")
+	}
+
+	var events []json.RawMessage
+	if body[0] == '[' {
+		if err := json.Unmarshal(body, &events); err != nil {
+			return nil, errors.New("invalid JSON: " + err.Error())
+		}
+	} else {
+		if !json.Valid(body) {
+			return nil, errors.New("invalid JSON")
+		}
+		events = []json.RawMessage{body}
+	}
+	if len(events) == 0 {
+		return nil, errors.New("no events in request")
+	}
+	if len(events) > maxEvents {
+		return nil, errTooManyEvents
+	}
+	return events, nil
+}
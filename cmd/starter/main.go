@@ -4,10 +4,10 @@ import (
 	"context"
 	"log"
 
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
 	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
 
 	"github.com/joho/godotenv"
-	"go.temporal.io/sdk/client"
 )
 
 func main() {
@@ -17,8 +17,14 @@ func main() {
 		log.Println("No .env file found, relying on environment variables")
 	}
 
+	// Load and validate configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalln(err)
+	}
+
 	// Create a new Temporal client
-	c, err := client.Dial(client.Options{})
+	c, err := temporal.Dial(cfg)
 	if err != nil {
 		log.Fatalln("Unable to create client", err)
 	}
@@ -27,14 +33,31 @@ func main() {
 	// The file to process
 	filePath := "testdata/dotBuild-events-2025-08.head20.log"
 
-	// Workflow options
-	workflowOptions := client.StartWorkflowOptions{
-		ID:        "domain-ingest-workflow_" + filePath,
-		TaskQueue: temporal.IngestTaskQueue,
+	// Workflow options, the ID is derived from the file content
+	contentHash, err := temporal.HashFile(filePath)
+	if err != nil {
+		log.Fatalln("Unable to hash file", err)
 	}
+	workflowOptions := temporal.IngestWorkflowOptions(cfg.Temporal, contentHash)
 
 	// Execute the workflow
-	we, err := c.ExecuteWorkflow(context.Background(), workflowOptions, temporal.IngestFileWorkflow, filePath)
+	we, err := c.ExecuteWorkflow(context.Background(), workflowOptions, temporal.IngestFileWorkflow, temporal.IngestRequest{
+		FilePath:       filePath,
+		ContentHash:    contentHash,
+		ZoneTaskQueues: temporal.ZoneTaskQueues(cfg.Temporal.TaskQueue, cfg.Temporal.ShardedZones),
+		HCS:            cfg.HCS,
+		Zones:          cfg.Zones,
+		MaxMints:       cfg.Limits.MaxMintsPerRun,
+		BudgetTinybar:  cfg.Limits.BudgetTinybar(),
+		BudgetUSD:      cfg.Limits.BudgetUSD,
+		Notify:         cfg.Notify.Enabled(),
+		FailureAlert:   cfg.Notify.FailureThreshold,
+		EmailReport:    cfg.Email.Enabled(),
+		ArchiveReport:  cfg.Reports.Bucket != "",
+		EscalateAfter:  cfg.Escalation.Threshold(),
+
+		VisibilityTimeout: cfg.Mirror.VisibilityTimeout,
+	})
 	if err != nil {
 		log.Fatalln("Unable to execute workflow", err)
 	}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+var (
+	accountsNote string
+	accountsKind string
+)
+
+// accountsCmd groups the commands working on the account registry
+var accountsCmd = &cobra.Command{
+	Use:   "accounts",
+	Short: "Map registrars and registrants to Hedera accounts",
+	Long: `Maintain the account registry, mapping registrars (by IANA ID) and registrants (by handle)
+to the Hedera accounts receiving their NFTs. Claims of a registrant without --account resolve
+the account through the registry.`,
+	// Only the account registry file is used, Temporal is not contacted
+	PersistentPreRun: loadConfigOnly,
+}
+
+// accountsSetCmd represents the accounts set command
+var accountsSetCmd = &cobra.Command{
+	Use:       "set [registrar|registrant] [external-id] [account]",
+	Short:     "Map a registrar or registrant to a Hedera account",
+	Args:      cobra.ExactArgs(3),
+	ValidArgs: []string{temporal.AccountKindRegistrar, temporal.AccountKindRegistrant},
+	Run: func(cmd *cobra.Command, args []string) {
+		_, err := temporal.NewActivities(cfg).PutAccountMappingActivity(context.Background(), temporal.AccountMapping{
+			Kind:       args[0],
+			ExternalID: args[1],
+			AccountID:  args[2],
+			Note:       accountsNote,
+		})
+		if err != nil {
+			log.Fatalf("Unable to map account: %v", err)
+		}
+	},
+}
+
+// accountsGetCmd represents the accounts get command
+var accountsGetCmd = &cobra.Command{
+	Use:       "get [registrar|registrant] [external-id]",
+	Short:     "Show the Hedera account of a registrar or registrant",
+	Args:      cobra.ExactArgs(2),
+	ValidArgs: []string{temporal.AccountKindRegistrar, temporal.AccountKindRegistrant},
+	Run: func(cmd *cobra.Command, args []string) {
+		mapping, err := temporal.NewActivities(cfg).GetAccountMappingActivity(context.Background(), args[0], args[1])
+		if err != nil {
+			log.Fatalf("Unable to get account: %v", err)
+		}
+		fmt.Println(mapping.AccountID)
+	},
+}
+
+// accountsListCmd represents the accounts list command
+var accountsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the account mappings",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		mappings, err := temporal.NewActivities(cfg).ListAccountMappingsActivity(context.Background(), accountsKind)
+		if err != nil {
+			log.Fatalf("Unable to list accounts: %v", err)
+		}
+		if len(mappings) == 0 {
+			fmt.Println("No account mappings in the account registry")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KIND\tEXTERNAL ID\tACCOUNT\tUPDATED\tNOTE")
+		for _, mapping := range mappings {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", mapping.Kind, mapping.ExternalID, mapping.AccountID,
+				mapping.UpdatedAt.Local().Format(time.DateTime), mapping.Note)
+		}
+		w.Flush()
+	},
+}
+
+// accountsDeleteCmd represents the accounts delete command
+var accountsDeleteCmd = &cobra.Command{
+	Use:       "delete [registrar|registrant] [external-id]",
+	Short:     "Remove the account mapping of a registrar or registrant",
+	Args:      cobra.ExactArgs(2),
+	ValidArgs: []string{temporal.AccountKindRegistrar, temporal.AccountKindRegistrant},
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := temporal.NewActivities(cfg).DeleteAccountMappingActivity(context.Background(), args[0], args[1]); err != nil {
+			log.Fatalf("Unable to delete account mapping: %v", err)
+		}
+		fmt.Printf("Removed the account mapping of %s %s\n", args[0], args[1])
+	},
+}
+
+func init() {
+	accountsSetCmd.Flags().StringVar(&accountsNote, "note", "", "free-form note stored with the mapping")
+	accountsListCmd.Flags().StringVar(&accountsKind, "kind", "", "only list mappings of this kind (registrar or registrant)")
+	accountsCmd.AddCommand(accountsSetCmd)
+	accountsCmd.AddCommand(accountsGetCmd)
+	accountsCmd.AddCommand(accountsListCmd)
+	accountsCmd.AddCommand(accountsDeleteCmd)
+	rootCmd.AddCommand(accountsCmd)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+// Exit statuses of the zone audit, so CI jobs can tell findings from an audit that could not run
+const (
+	auditExitFindings = 1
+	auditExitError    = 2
+)
+
+var (
+	zoneAuditList        bool
+	zoneAuditIgnoreExtra bool
+	zoneAuditJSON        bool
+)
+
+// auditCmd represents the audit command
+var auditCmd = &cobra.Command{
+	Use:   "audit [zone] [file]",
+	Short: "Compare the domains of a source file with the collection of a zone",
+	Long: `Compare the domains a source file expects in a zone with the NFTs of the zone collection, in
+every shard, on the mirror node. An event log is replayed through the policy of the zone: the
+domains it mints are expected, those it burns are not, the last event of a domain deciding. With
+--list, the file is a domain list, plain or CSV, and all of its domains are expected.
+
+Flags domains the file expects without an NFT as missing, NFTs of domains the file does not
+expect, or burns, as extra, and domains with several NFTs as duplicates. With REGISTRY_STORE_DSN,
+the findings tell whether the domain index records an NFT of their domain. Pass --ignore-extra
+for files that only cover part of the zone, e.g. the events of one day: the NFTs of domains the
+file does not mention are then left out.
+
+Exits with status 0 when nothing is flagged, 1 when something is, and 2 when the audit could
+not run, e.g. for an unreadable file or a zone without a collection.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeZoneFileArgs,
+	// Only the registries and the mirror node are used, Temporal is not contacted
+	PersistentPreRun: loadConfigOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		// The activities log to stdout, keep it for the audit
+		stdout := os.Stdout
+		os.Stdout = os.Stderr
+		activities := temporal.NewActivities(cfg)
+		audit, err := activities.ZoneAuditActivity(context.Background(), temporal.ZoneAuditRequest{
+			Zone:        args[0],
+			FilePath:    args[1],
+			List:        zoneAuditList,
+			IgnoreExtra: zoneAuditIgnoreExtra,
+		})
+		os.Stdout = stdout
+		if err != nil {
+			log.Printf("Unable to audit %s: %v", args[0], err)
+			os.Exit(auditExitError)
+		}
+
+		if zoneAuditJSON {
+			out, err := json.MarshalIndent(audit, "", "  ")
+			if err != nil {
+				log.Printf("Unable to encode the audit: %v", err)
+				os.Exit(auditExitError)
+			}
+			fmt.Println(string(out))
+		} else {
+			for _, finding := range audit.Findings {
+				var detail []string
+				switch finding.Issue {
+				case temporal.ZoneAuditMissing:
+					detail = append(detail, fmt.Sprintf("expected by line %d, no NFT in the collection", finding.Line))
+					if finding.Indexed {
+						detail = append(detail, "the domain index records one, it may not show on the mirror node yet")
+					}
+				case temporal.ZoneAuditExtra:
+					if finding.Deleted {
+						detail = append(detail, fmt.Sprintf("burned by line %d, still held as %s", finding.Line, strings.Join(finding.NFTs, ", ")))
+					} else {
+						detail = append(detail, "not in the file, held as "+strings.Join(finding.NFTs, ", "))
+					}
+				case temporal.ZoneAuditDuplicate:
+					detail = append(detail, fmt.Sprintf("%d NFTs: %s", len(finding.NFTs), strings.Join(finding.NFTs, ", ")))
+				}
+				fmt.Printf("%s\t%s\t%s\n", finding.Issue, finding.Domain, strings.Join(detail, "; "))
+			}
+			indexed := "without the domain index"
+			if audit.Indexed {
+				indexed = "with the domain index"
+			}
+			fmt.Printf(".%s: %d domains expected, %d burned, %d NFTs in %d collections (%s): %d missing, %d extra, %d duplicates\n",
+				audit.Zone, audit.Expected, audit.Burned, audit.Minted, len(audit.TokenIDs), indexed,
+				audit.Missing, audit.Extra, audit.Duplicates)
+		}
+		if len(audit.Findings) > 0 {
+			os.Exit(auditExitFindings)
+		}
+	},
+}
+
+func init() {
+	auditCmd.Flags().BoolVar(&zoneAuditList, "list", false, "the file is a domain list, plain or CSV, instead of an event log")
+	auditCmd.Flags().BoolVar(&zoneAuditIgnoreExtra, "ignore-extra", false, "leave out the NFTs of domains the file does not mention")
+	auditCmd.Flags().BoolVar(&zoneAuditJSON, "json", false, "print the audit as JSON")
+	rootCmd.AddCommand(auditCmd)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	temporalsdk "go.temporal.io/sdk/temporal"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/archive"
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+var (
+	backfillFrom            string
+	backfillTo              string
+	backfillContinueOnError bool
+)
+
+// backfillCmd represents the backfill command
+var backfillCmd = &cobra.Command{
+	Use:   "backfill [archive]",
+	Short: "Ingest the archived event logs of a date range",
+	Long: `Start a BackfillWorkflow ingesting the event log files of an archive dated from --from to --to,
+oldest first. The archive is a local directory, an S3 compatible bucket given as s3://bucket/prefix, or a
+Google Cloud Storage bucket given as gs://bucket/prefix;
+files are dated by their name, e.g. events-2026-03-01.log. Content that was already ingested, or that
+appears earlier in the range, is skipped. The first failed file stops the backfill, unless
+--continue-on-error is passed. A summary of every file is written to REPORT_DIR.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		source := args[0]
+		if !archive.IsBucket(source) {
+			if _, err := os.Stat(source); err != nil {
+				log.Fatalf("Invalid archive: %v", err)
+			}
+		}
+		from, err := time.Parse(time.DateOnly, backfillFrom)
+		if err != nil {
+			log.Fatalf("Invalid --from: %v", err)
+		}
+		to, err := time.Parse(time.DateOnly, backfillTo)
+		if err != nil {
+			log.Fatalf("Invalid --to: %v", err)
+		}
+		if to.Before(from) {
+			log.Fatalf("--to %s is before --from %s", backfillTo, backfillFrom)
+		}
+
+		ctx := context.Background()
+		options := temporal.BackfillWorkflowOptions(cfg.Temporal, source, from, to)
+		we, attached, err := startOrAttach(ctx, options, temporal.BackfillWorkflow, temporal.BackfillRequest{
+			Source:          source,
+			From:            from,
+			To:              to,
+			ContinueOnError: backfillContinueOnError,
+			ZoneTaskQueues:  temporal.ZoneTaskQueues(cfg.Temporal.TaskQueue, cfg.Temporal.ShardedZones),
+			HCS:             cfg.HCS,
+			Zones:           cfg.Zones,
+			MaxMints:        cfg.Limits.MaxMintsPerRun,
+			BudgetTinybar:   cfg.Limits.BudgetTinybar(),
+			BudgetUSD:       cfg.Limits.BudgetUSD,
+			Notify:          cfg.Notify.Enabled(),
+			FailureAlert:    cfg.Notify.FailureThreshold,
+			EmailReport:     cfg.Email.Enabled(),
+			ArchiveReport:   cfg.Reports.Bucket != "",
+			EscalateAfter:   cfg.Escalation.Threshold(),
+
+			WorkflowIDScope: cfg.Temporal.WorkflowIDScope,
+
+			VisibilityTimeout: cfg.Mirror.VisibilityTimeout,
+		})
+		if temporalsdk.IsWorkflowExecutionAlreadyStartedError(err) {
+			log.Fatalf("This range of %s is being backfilled, or was backfilled, by workflow %s", source, options.ID)
+		}
+		if err != nil {
+			log.Fatalf("Unable to execute workflow: %v", err)
+		}
+		if attached {
+			fmt.Printf("This range of %s is already being backfilled, attached to workflow %s (run %s)\n", source, we.GetID(), we.GetRunID())
+		} else {
+			fmt.Printf("Started workflow %s (run %s)\n", we.GetID(), we.GetRunID())
+		}
+
+		var summary temporal.BackfillSummary
+		if err := we.Get(ctx, &summary); err != nil {
+			log.Fatalf("Backfill failed: %v", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DATE\tFILE\tOUTCOME\tWORKFLOW")
+		for _, file := range summary.Files {
+			workflowID := file.WorkflowID
+			if file.Error != "" {
+				workflowID = file.Error
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", file.Date.Format(time.DateOnly), file.Name, file.Outcome, workflowID)
+		}
+		w.Flush()
+		fmt.Printf("%d files: %d ingested, %d duplicates, %d failed\n",
+			summary.Total, summary.Ingested, summary.Duplicates, summary.Failed)
+		if summary.Stopped {
+			fmt.Printf("Stopped at the first failure, %d files were not processed\n", summary.Total-len(summary.Files))
+		}
+		if summary.Report != "" {
+			fmt.Printf("Report written to %s\n", summary.Report)
+		}
+		if summary.Failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	backfillCmd.Flags().StringVar(&backfillFrom, "from", "", "first day of the range, YYYY-MM-DD")
+	backfillCmd.Flags().StringVar(&backfillTo, "to", "", "last day of the range, YYYY-MM-DD (included)")
+	backfillCmd.Flags().BoolVar(&backfillContinueOnError, "continue-on-error", false, "go on with the next file when one fails")
+	rootCmd.AddCommand(backfillCmd)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	temporalsdk "go.temporal.io/sdk/temporal"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/archive"
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+var batchContinueOnError bool
+
+// mintDomainsBatchCmd represents the mintDomains-batch command
+var mintDomainsBatchCmd = &cobra.Command{
+	Use:   "mintDomains-batch [dir]",
+	Short: "Ingest the log files of a directory one at a time, oldest first",
+	Long: `Start an IngestBatchWorkflow ingesting the event log files of a directory, the files named *.log,
+also compressed, in chronological order: files are dated by their name, e.g. events-2026-03-01.log, files
+of the same day are ordered by name and undated files come last. The files are ingested one at a time, so
+the events are applied in order. Content that was already ingested, or that repeats an earlier file, is
+skipped. The first failed file stops the batch, unless --continue-on-error is passed. The run reports of
+the files are consolidated into one report, written to REPORT_DIR.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		// The workflow ID is derived from the path, so one directory is ingested by one batch however it is named
+		dir, err := filepath.Abs(args[0])
+		if err != nil {
+			log.Fatalf("Invalid directory %s: %v", args[0], err)
+		}
+		files, err := archive.LogFiles(dir)
+		if err != nil {
+			log.Fatalf("Unable to list %s: %v", dir, err)
+		}
+		if len(files) == 0 {
+			log.Fatalf("No log files in %s", dir)
+		}
+
+		req := temporal.IngestBatchRequest{
+			Dir:             dir,
+			ContinueOnError: batchContinueOnError,
+			ZoneTaskQueues:  temporal.ZoneTaskQueues(cfg.Temporal.TaskQueue, cfg.Temporal.ShardedZones),
+			HCS:             cfg.HCS,
+			Zones:           cfg.Zones,
+			MaxMints:        cfg.Limits.MaxMintsPerRun,
+			BudgetTinybar:   cfg.Limits.BudgetTinybar(),
+			BudgetUSD:       cfg.Limits.BudgetUSD,
+			Notify:          cfg.Notify.Enabled(),
+			FailureAlert:    cfg.Notify.FailureThreshold,
+			EmailReport:     cfg.Email.Enabled(),
+			ArchiveReport:   cfg.Reports.Bucket != "",
+			EscalateAfter:   cfg.Escalation.Threshold(),
+
+			WorkflowIDScope: cfg.Temporal.WorkflowIDScope,
+
+			VisibilityTimeout: cfg.Mirror.VisibilityTimeout,
+		}
+		undated := 0
+		for _, file := range files {
+			filePath := filepath.Join(dir, file.Name)
+			contentHash, err := temporal.HashFile(filePath)
+			if err != nil {
+				log.Fatalf("Unable to hash %s: %v", filePath, err)
+			}
+			if file.Date.IsZero() {
+				undated++
+			}
+			req.Files = append(req.Files, temporal.BatchFile{FilePath: filePath, Date: file.Date, ContentHash: contentHash})
+		}
+		if undated > 0 {
+			fmt.Printf("Warning: %d files carry no date in their name, they are ingested last, by name\n", undated)
+		}
+
+		options := temporal.IngestBatchWorkflowOptions(cfg.Temporal, dir)
+		we, attached, err := startOrAttach(ctx, options, temporal.IngestBatchWorkflow, req)
+		if temporalsdk.IsWorkflowExecutionAlreadyStartedError(err) {
+			log.Fatalf("%s is being ingested, or was ingested, by workflow %s", dir, options.ID)
+		}
+		if err != nil {
+			log.Fatalf("Unable to execute workflow: %v", err)
+		}
+		if attached {
+			fmt.Printf("%s is already being ingested, attached to workflow %s (run %s)\n", dir, we.GetID(), we.GetRunID())
+		} else {
+			fmt.Printf("Started workflow %s (run %s) ingesting %d files one at a time\n", we.GetID(), we.GetRunID(), len(req.Files))
+		}
+
+		var summary temporal.BatchSummary
+		if err := we.Get(ctx, &summary); err != nil {
+			log.Fatalf("Batch failed: %v", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DATE\tFILE\tOUTCOME\tWORKFLOW")
+		for _, file := range summary.Files {
+			date, workflowID := "-", file.WorkflowID
+			if !file.Date.IsZero() {
+				date = file.Date.Format(time.DateOnly)
+			}
+			if file.Error != "" {
+				workflowID = file.Error
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", date, filepath.Base(file.FilePath), file.Outcome, workflowID)
+		}
+		w.Flush()
+		fmt.Printf("%d files: %d ingested, %d duplicates, %d failed\n",
+			summary.Total, summary.Ingested, summary.Duplicates, summary.Failed)
+		if summary.Stopped {
+			fmt.Printf("Stopped at the first failure, %d files were not processed\n", summary.Total-len(summary.Files))
+		}
+		totals := summary.Totals
+		fmt.Printf("%d events: %d minted, %d burned, %d skipped, %d ignored, %d failed, fees %d tinybar\n",
+			totals.Events, totals.Minted, totals.Burned, totals.Skipped, totals.Ignored, totals.Failed, totals.FeesTinybar)
+		if len(summary.Missing) > 0 {
+			fmt.Printf("Warning: the totals leave out %d files whose run report was not found\n", len(summary.Missing))
+		}
+		if summary.Report != "" {
+			fmt.Printf("Report written to %s\n", summary.Report)
+		}
+		if summary.Failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	mintDomainsBatchCmd.Flags().BoolVar(&batchContinueOnError, "continue-on-error", false, "go on with the next file when one fails")
+	rootCmd.AddCommand(mintDomainsBatchCmd)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/spf13/cobra"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+var (
+	budgetAddHbar   float64
+	budgetUnlimited bool
+)
+
+// budgetCmd represents the budget command
+var budgetCmd = &cobra.Command{
+	Use:   "budget [workflowID]",
+	Short: "Raise or lift the fee budget of a running ingest run, import or quarantine retry",
+	Long: `Raise the fee budget (RUN_BUDGET_HBAR) of a running ingest run, domain list import or
+quarantine retry by --add HBAR, or lift it with --unlimited. A run that spent its budget pauses
+its zones until the budget is raised above the fees spent, an import pauses before its next
+batch and a quarantine retry before its next mint or burn.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeIngestWorkflows(temporal.IngestOutcomeRunning),
+	Run: func(cmd *cobra.Command, args []string) {
+		workflowID := args[0]
+		if budgetAddHbar <= 0 && !budgetUnlimited {
+			log.Fatalln("Pass --add with a positive amount of HBAR, or --unlimited")
+		}
+		raise := temporal.BudgetRaise{
+			AddTinybar: int64(math.Round(budgetAddHbar * config.TinybarsPerHbar)),
+			Unlimited:  budgetUnlimited,
+		}
+		question := fmt.Sprintf("Raise the budget of workflow %s by %.8g HBAR?", workflowID, budgetAddHbar)
+		if budgetUnlimited {
+			question = fmt.Sprintf("Lift the budget of workflow %s?", workflowID)
+		}
+		if !confirm(question) {
+			log.Fatalln("Aborted")
+		}
+		if err := temporalClient.SignalWorkflow(context.Background(), workflowID, "", temporal.BudgetSignal, raise); err != nil {
+			log.Fatalf("Unable to signal workflow: %v", err)
+		}
+		fmt.Printf("Signaled workflow %s\n", workflowID)
+	},
+}
+
+func init() {
+	budgetCmd.Flags().Float64Var(&budgetAddHbar, "add", 0, "HBAR added to the budget")
+	budgetCmd.Flags().BoolVar(&budgetUnlimited, "unlimited", false, "lift the budget")
+	rootCmd.AddCommand(budgetCmd)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+	temporalsdk "go.temporal.io/sdk/temporal"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+var (
+	cancelWait      bool
+	terminateReason string
+)
+
+// cancelCmd represents the cancel command
+var cancelCmd = &cobra.Command{
+	Use:   "cancel [workflowID]",
+	Short: "Cancel a running workflow, letting it stop cleanly",
+	Long: `Request cancellation of a running workflow. An ingest run finishes the mint that is in
+flight in each zone, checkpoints it, writes a partial run report and records the run as
+canceled in the ingest ledger, so it can later be continued with resume.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeIngestWorkflows(temporal.IngestOutcomeRunning),
+	Run: func(cmd *cobra.Command, args []string) {
+		workflowID := args[0]
+		ctx := context.Background()
+
+		if !confirm(fmt.Sprintf("Cancel workflow %s?", workflowID)) {
+			log.Fatalln("Aborted")
+		}
+		if err := temporalClient.CancelWorkflow(ctx, workflowID, ""); err != nil {
+			log.Fatalf("Unable to cancel workflow: %v", err)
+		}
+		fmt.Printf("Requested cancellation of workflow %s\n", workflowID)
+		if !cancelWait {
+			return
+		}
+
+		// Wait for the workflow to finish its cleanup
+		run := temporalClient.GetWorkflow(ctx, workflowID, "")
+		err := run.Get(ctx, nil)
+		switch {
+		case err == nil:
+			fmt.Println("Workflow completed before the cancellation took effect.")
+		case temporalsdk.IsCanceledError(err):
+			fmt.Println("Workflow canceled.")
+		default:
+			log.Fatalf("Workflow failed: %v", err)
+		}
+		fmt.Printf("Run report: %s\n", temporal.RunReportPath(cfg.Reports.Dir, workflowID, run.GetRunID()))
+	},
+}
+
+// terminateCmd represents the terminate command
+var terminateCmd = &cobra.Command{
+	Use:   "terminate [workflowID]",
+	Short: "Terminate a workflow immediately, without cleanup",
+	Long: `Terminate a workflow immediately. Unlike cancel, the workflow gets no chance to clean up:
+in-flight activities are abandoned, no run report is written and the ingest ledger keeps the
+run as running. Use it only when a workflow does not react to cancel.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeIngestWorkflows(temporal.IngestOutcomeRunning),
+	Run: func(cmd *cobra.Command, args []string) {
+		workflowID := args[0]
+
+		if !confirm(fmt.Sprintf("Terminate workflow %s without cleanup?", workflowID)) {
+			log.Fatalln("Aborted")
+		}
+		if err := temporalClient.TerminateWorkflow(context.Background(), workflowID, "", terminateReason); err != nil {
+			log.Fatalf("Unable to terminate workflow: %v", err)
+		}
+		fmt.Printf("Terminated workflow %s\n", workflowID)
+	},
+}
+
+func init() {
+	cancelCmd.Flags().BoolVar(&cancelWait, "wait", false, "wait until the workflow has stopped")
+	terminateCmd.Flags().StringVar(&terminateReason, "reason", "terminated with wfstart", "reason recorded in the workflow history")
+	rootCmd.AddCommand(cancelCmd)
+	rootCmd.AddCommand(terminateCmd)
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	temporalsdk "go.temporal.io/sdk/temporal"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/entityid"
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+var (
+	claimAccount     string
+	claimRegistrant  string
+	claimAttestation string
+	claimTimeout     time.Duration
+	claimWait        bool
+)
+
+// claimCmd groups the commands handing domain NFTs over to their registrants
+var claimCmd = &cobra.Command{
+	Use:   "claim",
+	Short: "Transfer the NFT of a domain to its registrant",
+}
+
+// claimStartCmd represents the claim start command
+var claimStartCmd = &cobra.Command{
+	Use:   "start [domain]",
+	Short: "Start the claim of a domain by its registrant",
+	Long: `Start the claim workflow of a domain. The registrant proves control of the domain and
+receives the NFT from the treasury in their Hedera account, which must be associated with the
+zone collection. Instead of --account, --registrant names the registrant by handle and the
+account is looked up in the account registry (see "accounts set").
+
+By default, control is proven by publishing a TXT record with the challenge printed by this
+command; the workflow checks the record every few minutes until --timeout. With --attestation,
+the file holds a detached JWS of the registrar over {"account":"<account>","domain":"<domain>"},
+signed with a key of CLAIM_KEYS_FILE.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dn, err := domain.NewDomainName(args[0])
+		if err != nil {
+			log.Fatalf("Invalid domain name: %v", err)
+		}
+		req := temporal.ClaimRequest{
+			Domain:     dn.String(),
+			Registrant: claimRegistrant,
+			Method:     temporal.ClaimProofDNS,
+			Timeout:    claimTimeout,
+			Association: temporal.AssociationOptions{
+				Policy:  cfg.Transfers.AssociationPolicy,
+				Timeout: cfg.Transfers.AssociationTimeout,
+			},
+		}
+		if claimAccount != "" {
+			account, err := entityid.ParseAccount(claimAccount, cfg.Hedera.Network)
+			if err != nil {
+				log.Fatalf("Invalid --account: %v", err)
+			}
+			req.AccountID = account.String()
+		}
+		if claimAttestation != "" {
+			data, err := os.ReadFile(claimAttestation)
+			if err != nil {
+				log.Fatalf("Unable to read attestation: %v", err)
+			}
+			req.Method = temporal.ClaimProofAttestation
+			req.Attestation = string(data)
+		}
+
+		ctx := context.Background()
+		workflowOptions := temporal.ClaimWorkflowOptions(cfg.Temporal, req.Domain)
+		we, err := executeWorkflow(ctx, workflowOptions, temporal.ClaimDomainWorkflow, req)
+		if temporalsdk.IsWorkflowExecutionAlreadyStartedError(err) {
+			log.Fatalf("%s has already been claimed or is being claimed by workflow %s", req.Domain, workflowOptions.ID)
+		}
+		if err != nil {
+			log.Fatalf("Unable to execute workflow: %v", err)
+		}
+		fmt.Printf("Started workflow - WorkflowID: %s, RunID: %s\n", we.GetID(), we.GetRunID())
+
+		// Wait until the challenge is known, or the claim is over
+		var status temporal.ClaimStatus
+		for deadline := time.Now().Add(time.Minute); time.Now().Before(deadline); time.Sleep(time.Second) {
+			if status, err = queryClaim(ctx, we.GetID()); err == nil && status.Stage != temporal.ClaimStageLocating {
+				break
+			}
+		}
+		printClaimStatus(status)
+
+		if claimWait || req.Method == temporal.ClaimProofAttestation {
+			if err := we.Get(ctx, &status); err != nil {
+				log.Fatalf("Claim failed: %v", err)
+			}
+			fmt.Println()
+			printClaimStatus(status)
+		}
+	},
+}
+
+// claimStatusCmd represents the claim status command
+var claimStatusCmd = &cobra.Command{
+	Use:   "status [domain]",
+	Short: "Show the state of the claim of a domain",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dn, err := domain.NewDomainName(args[0])
+		if err != nil {
+			log.Fatalf("Invalid domain name: %v", err)
+		}
+		status, err := queryClaim(context.Background(), temporal.ScopedWorkflowID(cfg.Temporal.WorkflowIDScope, temporal.ClaimWorkflowID(dn.String())))
+		if err != nil {
+			log.Fatalf("Unable to query claim: %v", err)
+		}
+		printClaimStatus(status)
+	},
+}
+
+// queryClaim returns the status of a running or closed claim workflow
+func queryClaim(ctx context.Context, workflowID string) (temporal.ClaimStatus, error) {
+	var status temporal.ClaimStatus
+	value, err := temporalClient.QueryWorkflow(ctx, workflowID, "", temporal.ClaimQuery)
+	if err != nil {
+		return status, err
+	}
+	err = value.Get(&status)
+	return status, err
+}
+
+// printClaimStatus prints the state of a claim, with the instructions for the registrant while proof is awaited
+func printClaimStatus(status temporal.ClaimStatus) {
+	claimant := status.AccountID
+	switch {
+	case status.Registrant != "" && claimant != "":
+		claimant = fmt.Sprintf("%s (registrant %s)", claimant, status.Registrant)
+	case status.Registrant != "":
+		claimant = "registrant " + status.Registrant
+	}
+	fmt.Printf("Claim of %s by %s: %s\n", status.Domain, claimant, status.Stage)
+	if status.TokenID != "" {
+		fmt.Printf("  NFT:       serial %d of %s\n", status.SerialNumber, status.TokenID)
+	}
+	if status.Stage == temporal.ClaimStageAwaitProof && status.ChallengeRecord != "" {
+		fmt.Printf("\nPublish this DNS record to prove control of %s:\n\n  %s. IN TXT \"%s\"\n\n", status.Domain, status.ChallengeRecord, status.ChallengeValue)
+		fmt.Printf("and associate %s with %s. The record is checked every few minutes.\n", status.AccountID, status.TokenID)
+	}
+	if status.Stage == temporal.ClaimStageAssociation && status.Association != "" {
+		fmt.Printf("\nWaiting for the token association. %s.\n\n", status.Association)
+	}
+	if status.ProofDetail != "" {
+		fmt.Printf("  Proof:     %s\n", status.ProofDetail)
+	}
+	if status.TransactionID != "" {
+		fmt.Printf("  Transfer:  %s\n", status.TransactionID)
+	}
+	if status.Error != "" {
+		fmt.Printf("  Error:     %s\n", status.Error)
+	}
+}
+
+func init() {
+	claimStartCmd.Flags().StringVar(&claimAccount, "account", "", "Hedera account of the registrant receiving the NFT")
+	claimStartCmd.Flags().StringVar(&claimAttestation, "attestation", "", "file holding the registrar attestation, instead of a DNS challenge")
+	claimStartCmd.Flags().DurationVar(&claimTimeout, "timeout", temporal.DefaultClaimTimeout, "how long to wait for the DNS challenge")
+	claimStartCmd.Flags().BoolVar(&claimWait, "wait", false, "wait until the NFT is transferred")
+	claimStartCmd.Flags().StringVar(&claimRegistrant, "registrant", "", "handle of the registrant, whose account is looked up in the account registry")
+	claimStartCmd.MarkFlagsOneRequired("account", "registrant")
+	claimStartCmd.MarkFlagsMutuallyExclusive("account", "registrant")
+	claimCmd.AddCommand(claimStartCmd)
+	claimCmd.AddCommand(claimStatusCmd)
+	rootCmd.AddCommand(claimCmd)
+}
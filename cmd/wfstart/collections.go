@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	temporalsdk "go.temporal.io/sdk/temporal"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/export"
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+var (
+	exportFormat  string
+	exportOutput  string
+	exportTokenID string
+	supplyKey     string
+	retire        string
+)
+
+// collectionsCmd groups the commands working on zone collections
+var collectionsCmd = &cobra.Command{
+	Use:   "collections",
+	Short: "Work with the NFT collections of the zones",
+}
+
+// collectionsExportCmd represents the collections export command
+var collectionsExportCmd = &cobra.Command{
+	Use:   "export [zone]",
+	Short: "Export all NFTs of a zone collection to CSV, JSON or Parquet",
+	Long: `Stream all NFTs of a zone collection from the mirror node, decode their metadata and
+write them to a CSV, JSON or Parquet file for BI tooling and regulator reporting.
+
+The format defaults to the extension of --output, and the output to <zone>.<format>.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeZoneArgs,
+	// Only the mirror node is used, Temporal is not contacted
+	PersistentPreRun: loadConfigOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		zone := args[0]
+
+		format := exportFormat
+		if format == "" {
+			format = export.FormatFromPath(exportOutput)
+		}
+		if format == "" {
+			format = export.FormatCSV
+		}
+		output := exportOutput
+		if output == "" {
+			output = fmt.Sprintf("%s.%s", zone, format)
+		}
+
+		// Keep stdout for the summary, progress goes to stderr
+		stdout := os.Stdout
+		os.Stdout = os.Stderr
+		activities := temporal.NewActivities(cfg)
+		result, err := activities.ExportCollectionActivity(context.Background(), temporal.CollectionExportRequest{
+			Zone:       zone,
+			TokenID:    exportTokenID,
+			Format:     format,
+			OutputPath: output,
+		})
+		os.Stdout = stdout
+		if err != nil {
+			log.Fatalf("Unable to export collection: %v", err)
+		}
+		fmt.Printf("Exported %d NFTs of %s to %s\n", result.Count, result.TokenID, result.OutputPath)
+	},
+}
+
+// collectionsBrandCmd represents the collections brand command
+var collectionsBrandCmd = &cobra.Command{
+	Use:   "brand [zone...]",
+	Short: "Apply the branding of COLLECTION_BRANDING_FILE to existing zone collections",
+	Long: `Upload the collection document (HIP-766: description, logo, website) of each zone with its
+current branding from COLLECTION_BRANDING_FILE, and point the token metadata of the zone
+collection to it, so wallets and marketplaces show the updated branding. New collections
+are branded when they are created; token symbols can only be set at creation.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeZoneArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		failed := false
+		for _, zone := range args {
+			options := temporal.BrandingWorkflowOptions(cfg.Temporal, zone)
+			we, err := executeWorkflow(ctx, options, temporal.BrandCollectionWorkflow, zone)
+			if temporalsdk.IsWorkflowExecutionAlreadyStartedError(err) {
+				log.Fatalf("The collection of .%s is already being branded by workflow %s", zone, options.ID)
+			}
+			if err != nil {
+				log.Fatalf("Unable to execute workflow: %v", err)
+			}
+			var result temporal.CollectionBrandingResult
+			if err := we.Get(ctx, &result); err != nil {
+				fmt.Printf(".%s: branding failed: %v\n", zone, err)
+				failed = true
+				continue
+			}
+			fmt.Printf(".%s: %s now points to %s (transaction %s)\n", zone, result.TokenID, result.MetadataURI, result.TransactionID)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// collectionsRotateSupplyKeyCmd represents the collections rotate-supply-key command
+var collectionsRotateSupplyKeyCmd = &cobra.Command{
+	Use:   "rotate-supply-key --key <public key> [zone...]",
+	Short: "Set the supply key of existing zone collections, signed by the admin signer",
+	Long: `Set the supply key of the collection of each zone to --key, e.g. when the operator key of the
+workers is rotated: update the key of the operator account, rotate the supply key of the
+collections, then set HEDERA_PRIVATE_KEY. The token updates are signed by the external admin
+signer (HEDERA_ADMIN_SIGNER_URL or HEDERA_ADMIN_SIGNER_COMMAND); only collections created with
+an admin signer have an admin key allowing it.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeZoneArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if supplyKey == "" {
+			log.Fatalf("--key is required")
+		}
+		ctx := context.Background()
+		failed := false
+		for _, zone := range args {
+			options := temporal.SupplyKeyWorkflowOptions(cfg.Temporal, zone)
+			we, err := executeWorkflow(ctx, options, temporal.RotateSupplyKeyWorkflow, zone, supplyKey)
+			if temporalsdk.IsWorkflowExecutionAlreadyStartedError(err) {
+				log.Fatalf("The supply key of .%s is already being updated by workflow %s", zone, options.ID)
+			}
+			if err != nil {
+				log.Fatalf("Unable to execute workflow: %v", err)
+			}
+			var result temporal.SupplyKeyRotation
+			if err := we.Get(ctx, &result); err != nil {
+				fmt.Printf(".%s: supply key update failed: %v\n", zone, err)
+				failed = true
+				continue
+			}
+			fmt.Printf(".%s: %s is now minted with %s (transaction %s)\n", zone, result.TokenID, result.SupplyKey, result.TransactionID)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// collectionsMigrateCmd represents the collections migrate command
+var collectionsMigrateCmd = &cobra.Command{
+	Use:   "migrate <zone>",
+	Short: "Move a zone to a new collection, re-minting its NFTs",
+	Long: `Create a new collection for the zone with the current keys, naming and branding, e.g. after
+its keys were compromised or to apply a new policy. Every NFT of the old collection that is
+not burned is minted into the new collection with its original metadata, the zone registry
+is switched to the new collection, and the old collection is retired according to --retire:
+its NFTs still in the treasury are burned (burn), the token is deleted with the admin signer
+(delete), or it is left as it is (keep).
+
+NFTs held outside the treasury are minted into the treasury and listed, to be transferred to
+their holders. Stop the ingests of the zone while it is migrated.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeZoneArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		zone := args[0]
+		ctx := context.Background()
+		options := temporal.MigrationWorkflowOptions(cfg.Temporal, zone)
+		we, err := executeWorkflow(ctx, options, temporal.MigrateCollectionWorkflow, temporal.CollectionMigrationRequest{
+			Zone:   zone,
+			Retire: retire,
+		})
+		if temporalsdk.IsWorkflowExecutionAlreadyStartedError(err) {
+			log.Fatalf("The collection of .%s is already being migrated by workflow %s", zone, options.ID)
+		}
+		if err != nil {
+			log.Fatalf("Unable to execute workflow: %v", err)
+		}
+		fmt.Printf("Started workflow %s (run %s)\n", we.GetID(), we.GetRunID())
+		var result temporal.CollectionMigrationResult
+		if err := we.Get(ctx, &result); err != nil {
+			log.Fatalf(".%s: migration failed: %v", zone, err)
+		}
+		fmt.Printf(".%s: migrated %d NFTs from %s to %s\n", zone, result.Reminted, result.FromTokenID, result.ToTokenID)
+		switch {
+		case result.Deleted:
+			fmt.Printf("Deleted %s\n", result.FromTokenID)
+		case result.Burned > 0:
+			fmt.Printf("Burned %d NFTs of %s\n", result.Burned, result.FromTokenID)
+		}
+		for _, nft := range result.HeldOutside {
+			fmt.Printf("%s: serial %d of %s is in the treasury, serial %d of %s is held by %s\n",
+				nft.Domain, nft.ToSerial, result.ToTokenID, nft.FromSerial, result.FromTokenID, nft.Holder)
+		}
+	},
+}
+
+func init() {
+	collectionsExportCmd.Flags().StringVar(&exportFormat, "format", "", "output format: csv, json or parquet (default from --output, else csv)")
+	collectionsExportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "output file (default <zone>.<format>)")
+	collectionsExportCmd.Flags().StringVar(&exportTokenID, "token", "", "token ID of the zone collection, instead of the zone registry or a lookup by name")
+	collectionsExportCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(
+		[]cobra.Completion{export.FormatCSV, export.FormatJSON, export.FormatParquet}, cobra.ShellCompDirectiveNoFileComp))
+	collectionsCmd.AddCommand(collectionsExportCmd)
+	collectionsRotateSupplyKeyCmd.Flags().StringVar(&supplyKey, "key", "", "public key to set as supply key")
+	collectionsCmd.AddCommand(collectionsBrandCmd)
+	collectionsCmd.AddCommand(collectionsRotateSupplyKeyCmd)
+	collectionsMigrateCmd.Flags().StringVar(&retire, "retire", temporal.RetireBurn, "how to retire the old collection: burn, delete or keep")
+	collectionsMigrateCmd.RegisterFlagCompletionFunc("retire", cobra.FixedCompletions(
+		[]cobra.Completion{temporal.RetireBurn, temporal.RetireDelete, temporal.RetireKeep}, cobra.ShellCompDirectiveNoFileComp))
+	collectionsCmd.AddCommand(collectionsMigrateCmd)
+	rootCmd.AddCommand(collectionsCmd)
+}
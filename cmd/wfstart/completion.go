@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+var assumeYes bool
+
+// isCompletionCmd reports whether cmd generates or serves shell completions.
+// These must not dial Temporal or print anything besides the completions.
+func isCompletionCmd(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		switch c.Name() {
+		case "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+			return true
+		}
+	}
+	return false
+}
+
+// completionActivities returns activities to read the local registries from, or nil if the
+// configuration cannot be loaded. Completions are best effort and never fail loudly.
+func completionActivities() *temporal.Activities {
+	c, err := config.LoadProfile(profileName)
+	if err != nil {
+		return nil
+	}
+	return temporal.NewActivities(c)
+}
+
+// completeIngestWorkflows completes the first argument with the workflow IDs of the ingest
+// runs recorded in the ingest ledger, restricted to the given outcomes
+func completeIngestWorkflows(outcomes ...string) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		activities := completionActivities()
+		if activities == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		runs, err := activities.ListIngestRunsActivity(context.Background())
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		var completions []cobra.Completion
+		for _, run := range runs {
+			if len(outcomes) > 0 && !slices.Contains(outcomes, run.Outcome) {
+				continue
+			}
+			if strings.HasPrefix(run.WorkflowID, toComplete) {
+				completions = append(completions, cobra.CompletionWithDesc(run.WorkflowID, fmt.Sprintf("%s (%s)", run.FilePath, run.Outcome)))
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeZones completes a flag value with the zones of the zone registry
+func completeZones(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	activities := completionActivities()
+	if activities == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	collections, err := activities.ListZoneCollectionsActivity(context.Background())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []cobra.Completion
+	for _, collection := range collections {
+		if strings.HasPrefix(collection.Zone.String(), toComplete) {
+			completions = append(completions, cobra.CompletionWithDesc(collection.Zone.String(), collection.TokenID))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeZoneArgs completes the first argument with the zones of the zone registry
+func completeZoneArgs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeZones(cmd, args, toComplete)
+}
+
+// completeZoneFileArgs completes the first argument with the zones of the zone registry, and the second with files
+func completeZoneFileArgs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return completeZones(cmd, args, toComplete)
+	case 1:
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProfiles completes --profile with the profiles of the config file
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	file, err := config.LoadFile(config.ConfigFilePath())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return file.ProfileNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// confirm asks the user to confirm a destructive action and returns their answer.
+// It returns true without asking when --yes was passed, and false when stdin is not a terminal.
+func confirm(question string) bool {
+	if assumeYes {
+		return true
+	}
+	if stat, err := os.Stdin.Stat(); err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+		fmt.Fprintln(os.Stderr, "Refusing to continue without confirmation, pass --yes to run non-interactively")
+		return false
+	}
+
+	fmt.Printf("%s [y/N]: ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
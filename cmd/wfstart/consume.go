@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	temporalsdk "go.temporal.io/sdk/temporal"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/entityid"
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+var (
+	consumeGroup     string
+	consumeBatchSize int
+	consumeWait      time.Duration
+	consumeFollow    bool
+	offsetsResetTo   uint64
+)
+
+// consumeCmd represents the consume command
+var consumeCmd = &cobra.Command{
+	Use:   "consume [topic]",
+	Short: "Consume an HCS topic as a consumer group",
+	Long: `Start a ConsumeTopicWorkflow reading the messages of a topic, given by ID or by topic registry name,
+after the offset committed by the consumer group. The offset is committed after every batch, so
+a consumer that is stopped and started again neither skips nor repeats messages. Without --follow,
+the workflow returns once the group has caught up with the topic.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if consumeGroup == "" {
+			log.Fatalf("--group is required")
+		}
+		ctx := context.Background()
+		topicID, err := resolveTopic(ctx, args[0])
+		if err != nil {
+			log.Fatalf("Unable to resolve topic: %v", err)
+		}
+		options := temporal.TopicConsumerWorkflowOptions(cfg.Temporal, consumeGroup, topicID)
+		we, err := executeWorkflow(ctx, options, temporal.ConsumeTopicWorkflow, temporal.ConsumeTopicRequest{
+			Group:     consumeGroup,
+			TopicID:   topicID,
+			BatchSize: consumeBatchSize,
+			Wait:      consumeWait,
+			Follow:    consumeFollow,
+		})
+		if temporalsdk.IsWorkflowExecutionAlreadyStartedError(err) {
+			log.Fatalf("Group %s is already consuming %s in workflow %s", consumeGroup, topicID, options.ID)
+		}
+		if err != nil {
+			log.Fatalf("Unable to execute workflow: %v", err)
+		}
+		fmt.Printf("Started workflow %s (run %s)\n", we.GetID(), we.GetRunID())
+		if consumeFollow {
+			return
+		}
+		var result temporal.ConsumeTopicResult
+		if err := we.Get(ctx, &result); err != nil {
+			log.Fatalf("Consumer failed: %v", err)
+		}
+		fmt.Printf("Consumed %d messages, group %s is at sequence %d of %s\n", result.Consumed, consumeGroup, result.Offset.SequenceNumber, topicID)
+	},
+}
+
+// offsetsCmd groups the commands working on the committed offsets of the consumer groups
+var offsetsCmd = &cobra.Command{
+	Use:   "offsets",
+	Short: "Manage the topic offsets of the consumer groups",
+	// Only TOPIC_OFFSETS_FILE and the mirror node are used, Temporal is not contacted
+	PersistentPreRun: loadConfigOnly,
+}
+
+// offsetsListCmd represents the offsets list command
+var offsetsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the committed offsets",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		offsets, err := temporal.NewActivities(cfg).ListTopicOffsetsActivity(context.Background())
+		if err != nil {
+			log.Fatalf("Unable to list offsets: %v", err)
+		}
+		if len(offsets) == 0 {
+			fmt.Println("No committed offsets")
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "GROUP\tTOPIC\tSEQUENCE\tCONSENSUS TIME\tUPDATED")
+		for _, offset := range offsets {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", offset.Group, offset.TopicID, offset.SequenceNumber,
+				offset.ConsensusTime.Format(time.RFC3339Nano), offset.UpdatedAt.Local().Format(time.DateTime))
+		}
+		w.Flush()
+	},
+}
+
+// offsetsResetCmd represents the offsets reset command
+var offsetsResetCmd = &cobra.Command{
+	Use:   "reset [group] [topic]",
+	Short: "Move the offset of a consumer group",
+	Long: `Move the offset of a consumer group to a message of a topic, with --to, so the group resumes after it.
+Without --to, the offset is removed and the group consumes the topic again from its first message.
+Stop the consumer of the group first, it would otherwise commit over the reset offset.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		topicID, err := resolveTopic(ctx, args[1])
+		if err != nil {
+			log.Fatalf("Unable to resolve topic: %v", err)
+		}
+		if _, err := temporal.NewActivities(cfg).ResetTopicOffsetActivity(ctx, args[0], topicID, offsetsResetTo); err != nil {
+			log.Fatalf("Unable to reset offset: %v", err)
+		}
+	},
+}
+
+// resolveTopic returns the ID of a topic given by ID or by topic registry name
+func resolveTopic(ctx context.Context, topic string) (string, error) {
+	if id, err := entityid.ParseTopic(topic, cfg.Hedera.Network); err == nil {
+		return id.String(), nil
+	}
+	info, err := temporal.NewActivities(cfg).GetTopicInfoActivity(ctx, topic)
+	if err != nil {
+		return "", err
+	}
+	return info.TopicID, nil
+}
+
+func init() {
+	consumeCmd.Flags().StringVar(&consumeGroup, "group", "", "consumer group whose offset is resumed and committed")
+	consumeCmd.Flags().IntVar(&consumeBatchSize, "batch-size", temporal.DefaultConsumeBatchSize, "messages handled per batch")
+	consumeCmd.Flags().DurationVar(&consumeWait, "wait", time.Minute, "how long a batch waits for messages")
+	consumeCmd.Flags().BoolVar(&consumeFollow, "follow", false, "keep consuming new messages instead of returning once caught up")
+	rootCmd.AddCommand(consumeCmd)
+
+	offsetsResetCmd.Flags().Uint64Var(&offsetsResetTo, "to", 0, "sequence number of the last message the group has processed")
+	offsetsCmd.AddCommand(offsetsListCmd)
+	offsetsCmd.AddCommand(offsetsResetCmd)
+	rootCmd.AddCommand(offsetsCmd)
+}
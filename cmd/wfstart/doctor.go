@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Verify the environment before starting any workflow",
+	Long: `Run a self-check that verifies the configuration, Temporal connectivity,
+Hedera credentials, operator balance, mirror node reachability and registry file health.
+
+Exits with a non-zero status if any check fails.`,
+	Args: cobra.NoArgs,
+	// Unlike the other commands, doctor must not abort on an invalid configuration
+	// or an unreachable Temporal server, but report them as failed checks.
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if err := godotenv.Load(); err != nil {
+			log.Println("No .env file found, relying on environment variables")
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		var err error
+		cfg, err = config.FromProfile(profileName)
+		if err != nil {
+			log.Fatalf("Unable to read configuration: %v", err)
+		}
+		if cfg.Profile != "" {
+			fmt.Printf("Using profile %s\n", cfg.Profile)
+		}
+
+		results := []temporal.CheckResult{{Name: "configuration", OK: true, Detail: "valid"}}
+		if err := cfg.Validate(); err != nil {
+			results[0] = temporal.CheckResult{Name: "configuration", Detail: err.Error()}
+		}
+
+		var dialErr error
+		temporalClient, dialErr = temporal.Dial(cfg)
+		results = append(results, temporal.SelfCheck(context.Background(), cfg, temporalClient, dialErr)...)
+
+		for _, r := range results {
+			fmt.Println(r)
+		}
+		if !temporal.AllPassed(results) {
+			if temporalClient != nil {
+				temporalClient.Close()
+			}
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+var domainHistoryJSON bool
+
+// domainCmd groups the commands working on the ledger entry of a domain
+var domainCmd = &cobra.Command{
+	Use:   "domain",
+	Short: "Inspect the ledger entries of domains",
+}
+
+// domainHistoryCmd represents the domain history command
+var domainHistoryCmd = &cobra.Command{
+	Use:   "history [domain]",
+	Short: "Show the chronological history of a domain on the ledger",
+	Long: `Show the history of a domain: the mints, metadata updates, transfers and burns of every
+NFT the domain had, also across re-registrations and shards, read from the mirror node, with
+the mint receipts of HCS_RECEIPTS_TOPIC and the anchors of ANCHOR_DIR holding its events.
+The API serves the same history at /v1/domains/{name}/history.`,
+	Args: cobra.ExactArgs(1),
+	// Only the registries, the anchor directory and the mirror node are used, Temporal is not contacted
+	PersistentPreRun: loadConfigOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		// The activities log to stdout, keep it for the history
+		stdout := os.Stdout
+		os.Stdout = os.Stderr
+		history, err := temporal.NewActivities(cfg).DomainHistoryActivity(context.Background(), args[0])
+		os.Stdout = stdout
+		if err != nil {
+			log.Fatalf("Unable to read the history of %s: %v", args[0], err)
+		}
+
+		if domainHistoryJSON {
+			out, err := json.MarshalIndent(history, "", "  ")
+			if err != nil {
+				log.Fatalf("Unable to encode the history: %v", err)
+			}
+			fmt.Println(string(out))
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tEVENT\tNFT\tDETAIL")
+		for _, entry := range history.Entries {
+			nft := "-"
+			if entry.TokenID != "" {
+				nft = fmt.Sprintf("%s/%d", entry.TokenID, entry.SerialNumber)
+			}
+			var detail string
+			switch entry.Kind {
+			case temporal.HistoryTransfer:
+				detail = fmt.Sprintf("%s -> %s, %s", entry.From, entry.To, entry.TransactionID)
+			case temporal.HistoryBurn:
+				detail = entry.TransactionID
+				if entry.From != "" {
+					detail = fmt.Sprintf("wiped from %s, %s", entry.From, entry.TransactionID)
+				}
+			case temporal.HistoryReceipt:
+				detail = fmt.Sprintf("message #%d of %s", entry.SequenceNumber, entry.TopicID)
+				if entry.Producer != "" {
+					detail += ", published by " + entry.Producer
+				}
+			case temporal.HistoryAnchor:
+				detail = fmt.Sprintf("batch %s, message #%d of %s", entry.BatchID, entry.SequenceNumber, entry.TopicID)
+			default:
+				detail = entry.TransactionID
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.At.UTC().Format(time.RFC3339), entry.Kind, nft, detail)
+		}
+		w.Flush()
+		fmt.Printf("%s: %d NFTs, %d entries\n", history.Domain, len(history.NFTs), len(history.Entries))
+	},
+}
+
+func init() {
+	domainHistoryCmd.Flags().BoolVar(&domainHistoryJSON, "json", false, "print the history as JSON")
+	domainCmd.AddCommand(domainHistoryCmd)
+	rootCmd.AddCommand(domainCmd)
+}
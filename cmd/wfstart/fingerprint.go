@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/fingerprint"
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+// fingerprintCmd represents the fingerprint command
+var fingerprintCmd = &cobra.Command{
+	Use:   "fingerprint [registrant-handle]...",
+	Short: "Compute the fingerprints of registrant handles",
+	Long: `Compute the fingerprints recorded in metadata documents and HCS receipts for registrant handles,
+with the key of REGISTRANT_FINGERPRINT_KEY_FILE. Holders of the key can find the registrations of a
+registrant by its fingerprint; without the key, fingerprints only show which registrations share a
+registrant.`,
+	Args: cobra.MinimumNArgs(1),
+	// Only the fingerprint key is used, Temporal is not contacted
+	PersistentPreRun: loadConfigOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		if cfg.Events.FingerprintKeyFile == "" {
+			log.Fatalln("REGISTRANT_FINGERPRINT_KEY_FILE is not set")
+		}
+		key, err := fingerprint.LoadKey(cfg.Events.FingerprintKeyFile)
+		if err != nil {
+			log.Fatalf("Unable to load fingerprint key: %v", err)
+		}
+		for _, handle := range args {
+			fmt.Printf("%s\t%s\n", handle, key.Sum(temporal.FingerprintRegistrant, handle))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fingerprintCmd)
+}
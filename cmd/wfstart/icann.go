@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+var (
+	icannZone  string
+	icannMonth string
+	icannJSON  bool
+
+	icannNoEmail bool
+)
+
+// icannCmd groups the commands working on ICANN registry reports
+var icannCmd = &cobra.Command{
+	Use:   "icann",
+	Short: "Cross-check the ledger against ICANN registry reports",
+}
+
+// icannReconcileCmd represents the icann reconcile command
+var icannReconcileCmd = &cobra.Command{
+	Use:   "reconcile [report.csv]...",
+	Short: "Compare monthly transaction reports with the ledger",
+	Long: `Compare ICANN monthly registry transaction reports (<tld>-transactions-<yyyymm>-en.csv)
+with the ledger: the domains added and deleted during the month and the total at its end,
+against the NFTs of the zone collection minted, burned and held on the mirror node.
+
+A reconciliation of every report is written to the report directory as
+icann_<zone>_<yyyymm>.json, and emailed to REPORT_EMAIL_TO when it is set.
+Exits with a non-zero status when any count differs.`,
+	Args: cobra.MinimumNArgs(1),
+	// Only the zone registry and mirror node are used, Temporal is not contacted
+	PersistentPreRun: loadConfigOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		var month time.Time
+		if icannMonth != "" {
+			var err error
+			if month, err = time.Parse("2006-01", icannMonth); err != nil {
+				log.Fatalf("Invalid --month %q, expected yyyy-mm", icannMonth)
+			}
+		}
+		if len(args) > 1 && (icannZone != "" || icannMonth != "") {
+			log.Fatalln("--zone and --month apply to a single report")
+		}
+
+		// The activities log to stdout, keep it for the results
+		stdout := os.Stdout
+		os.Stdout = os.Stderr
+		activities := temporal.NewActivities(cfg)
+		var results []temporal.ICANNReconciliation
+		for _, path := range args {
+			rec, err := activities.ReconcileICANNReportActivity(context.Background(), path, icannZone, month)
+			if err != nil {
+				os.Stdout = stdout
+				log.Fatalf("Unable to reconcile %s: %v", path, err)
+			}
+			results = append(results, rec)
+		}
+		if cfg.Email.Enabled() && !icannNoEmail {
+			if err := activities.EmailReconciliationsActivity(context.Background(), results); err != nil {
+				os.Stdout = stdout
+				log.Fatalf("Unable to email the reconciliations: %v", err)
+			}
+		}
+		os.Stdout = stdout
+
+		if icannJSON {
+			out, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				log.Fatalf("Unable to encode reconciliations: %v", err)
+			}
+			fmt.Println(string(out))
+		} else {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ZONE\tMONTH\tADDS (REPORT/LEDGER)\tDELETES (REPORT/LEDGER)\tTOTAL (REPORT/LEDGER)\tSTATUS")
+			for _, rec := range results {
+				status := "ok"
+				if len(rec.Discrepancies) > 0 {
+					status = fmt.Sprintf("%d discrepancies", len(rec.Discrepancies))
+				}
+				fmt.Fprintf(w, ".%s\t%s\t%d/%d\t%d/%d\t%d/%d\t%s\n", rec.Zone, rec.Month,
+					rec.Report.Adds, rec.LedgerAdds, rec.Report.Deletes, rec.LedgerDeletes,
+					rec.Report.TotalDomains, rec.LedgerTotal, status)
+			}
+			w.Flush()
+		}
+
+		for _, rec := range results {
+			if len(rec.Discrepancies) > 0 {
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+func init() {
+	icannReconcileCmd.Flags().StringVar(&icannZone, "zone", "", "zone of the report (default: the TLD in the file name)")
+	icannReconcileCmd.Flags().StringVar(&icannMonth, "month", "", "month of the report as yyyy-mm (default: the month in the file name)")
+	icannReconcileCmd.Flags().BoolVar(&icannJSON, "json", false, "print the reconciliations as JSON")
+	icannReconcileCmd.Flags().BoolVar(&icannNoEmail, "no-email", false, "do not email the reconciliations to REPORT_EMAIL_TO")
+	icannReconcileCmd.RegisterFlagCompletionFunc("zone", completeZones)
+	icannCmd.AddCommand(icannReconcileCmd)
+	rootCmd.AddCommand(icannCmd)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+	temporalsdk "go.temporal.io/sdk/temporal"
+)
+
+var (
+	idReusePolicy    string
+	idConflictPolicy string
+)
+
+// idReusePolicies are the values of --id-reuse-policy: whether a workflow ID whose last run closed may be started
+// again
+var idReusePolicies = map[string]enumspb.WorkflowIdReusePolicy{
+	"reject-duplicate":            enumspb.WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE,
+	"allow-duplicate-failed-only": enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE_FAILED_ONLY,
+	"allow-duplicate":             enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE,
+}
+
+// idConflictPolicies are the values of --id-conflict-policy: what is done when a run of the workflow ID is running
+var idConflictPolicies = map[string]enumspb.WorkflowIdConflictPolicy{
+	"fail":               enumspb.WORKFLOW_ID_CONFLICT_POLICY_FAIL,
+	"use-existing":       enumspb.WORKFLOW_ID_CONFLICT_POLICY_USE_EXISTING,
+	"terminate-existing": enumspb.WORKFLOW_ID_CONFLICT_POLICY_TERMINATE_EXISTING,
+}
+
+// policyNames returns the sorted names of a policy flag
+func policyNames[P any](policies map[string]P) []string {
+	names := make([]string, 0, len(policies))
+	for name := range policies {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// executeWorkflow starts a workflow like temporalClient.ExecuteWorkflow, with the workflow ID policies of
+// --id-reuse-policy and --id-conflict-policy in place of those of the command, when they are set. Without
+// --id-reuse-policy, the command keeps its own policy rather than reject-duplicate: the IDs are derived from what
+// the workflows work on, so rejecting duplicates would refuse every second digest, report or consumer of the same
+// ID, and ingests allow duplicates of failed runs only, so content is retried after a failure but never minted
+// twice.
+func executeWorkflow(ctx context.Context, options client.StartWorkflowOptions, workflow any, args ...any) (client.WorkflowRun, error) {
+	if idReusePolicy != "" {
+		policy, ok := idReusePolicies[idReusePolicy]
+		if !ok {
+			log.Fatalf("Unknown --id-reuse-policy %q (expected %s)", idReusePolicy, strings.Join(policyNames(idReusePolicies), ", "))
+		}
+		options.WorkflowIDReusePolicy = policy
+	}
+	if idConflictPolicy != "" {
+		policy, ok := idConflictPolicies[idConflictPolicy]
+		if !ok {
+			log.Fatalf("Unknown --id-conflict-policy %q (expected %s)", idConflictPolicy, strings.Join(policyNames(idConflictPolicies), ", "))
+		}
+		options.WorkflowIDConflictPolicy = policy
+	}
+	return temporalClient.ExecuteWorkflow(ctx, options, workflow, args...)
+}
+
+// startOrAttach starts an ingest like executeWorkflow. When the start is refused as the same content is being
+// ingested, it returns the running ingest instead, telling it attached, unless --id-conflict-policy is set. Starts
+// refused as the content was ingested already keep their error.
+func startOrAttach(ctx context.Context, options client.StartWorkflowOptions, workflow any, args ...any) (client.WorkflowRun, bool, error) {
+	we, err := executeWorkflow(ctx, options, workflow, args...)
+	if !temporalsdk.IsWorkflowExecutionAlreadyStartedError(err) || idConflictPolicy != "" {
+		return we, false, err
+	}
+	desc, describeErr := temporalClient.DescribeWorkflowExecution(ctx, options.ID, "")
+	if describeErr != nil || desc.WorkflowExecutionInfo.Status != enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING {
+		return nil, false, err
+	}
+	return temporalClient.GetWorkflow(ctx, options.ID, desc.WorkflowExecutionInfo.Execution.RunId), true, nil
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&idReusePolicy, "id-reuse-policy", "",
+		"whether a workflow ID whose last run closed may be started again: "+strings.Join(policyNames(idReusePolicies), ", ")+" (default: the policy of the command, allow-duplicate-failed-only for ingests)")
+	rootCmd.PersistentFlags().StringVar(&idConflictPolicy, "id-conflict-policy", "",
+		"what is done when a run of the workflow ID is running: "+strings.Join(policyNames(idConflictPolicies), ", ")+" (default: fail, ingests attach to the run)")
+	rootCmd.RegisterFlagCompletionFunc("id-reuse-policy", cobra.FixedCompletions(policyNames(idReusePolicies), cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.RegisterFlagCompletionFunc("id-conflict-policy", cobra.FixedCompletions(policyNames(idConflictPolicies), cobra.ShellCompDirectiveNoFileComp))
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	temporalsdk "go.temporal.io/sdk/temporal"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+var (
+	importBatchSize int
+	importInterval  time.Duration
+)
+
+// importDomainsCmd represents the importDomains command
+var importDomainsCmd = &cobra.Command{
+	Use:   "importDomains [file]",
+	Short: "Mint the NFTs of a list of existing registered domains",
+	Long: `Start the domain list import workflow, which bootstraps the ledger of a zone that predates
+event logging. The file lists one domain per line, or is a CSV file (.csv) with the domain in the
+first column, optionally followed by the registration time and the registrar, or a header row
+naming the domain, registered_at and registrar columns. Lines starting with # are ignored.
+Lists compressed with gzip, zstd or bzip2 (.gz, .zst, .bz2) are decompressed while read.
+
+The domains are minted in batches of --batch-size, pausing --interval between batches.
+Domains that are already minted are skipped, so an interrupted import can be started again.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		filePath := args[0]
+
+		if _, err := os.Stat(filePath); os.IsNotExist(err) {
+			log.Fatalf("File does not exist: %s", filePath)
+		}
+		contentHash, err := temporal.HashFile(filePath)
+		if err != nil {
+			log.Fatalf("Unable to hash file: %v", err)
+		}
+		workflowOptions := temporal.ImportWorkflowOptions(cfg.Temporal, contentHash)
+
+		we, attached, err := startOrAttach(context.Background(), workflowOptions, temporal.ImportDomainListWorkflow, temporal.DomainListImportRequest{
+			FilePath:       filePath,
+			BatchSize:      importBatchSize,
+			BatchInterval:  importInterval,
+			ZoneTaskQueues: temporal.ZoneTaskQueues(cfg.Temporal.TaskQueue, cfg.Temporal.ShardedZones),
+			HCS:            cfg.HCS,
+			Zones:          cfg.Zones,
+			MaxMints:       cfg.Limits.MaxMintsPerRun,
+			BudgetTinybar:  cfg.Limits.BudgetTinybar(),
+			BudgetUSD:      cfg.Limits.BudgetUSD,
+
+			VisibilityTimeout: cfg.Mirror.VisibilityTimeout,
+		})
+		if temporalsdk.IsWorkflowExecutionAlreadyStartedError(err) {
+			log.Fatalf("The content of %s has already been imported or is being imported by workflow %s", filePath, workflowOptions.ID)
+		}
+		if err != nil {
+			log.Fatalf("Unable to execute workflow: %v", err)
+		}
+		if attached {
+			fmt.Printf("The content of %s is already being imported, attached to workflow %s (run %s)\n", filePath, we.GetID(), we.GetRunID())
+		} else {
+			fmt.Printf("Started workflow - WorkflowID: %s, RunID: %s\n", we.GetID(), we.GetRunID())
+		}
+
+		// Wait for the import to complete, following it across continue-as-new
+		if err := we.Get(context.Background(), nil); err != nil {
+			log.Fatalf("Unable to get workflow result: %v", err)
+		}
+		value, err := temporalClient.QueryWorkflow(context.Background(), we.GetID(), "", temporal.ProgressQuery)
+		if err != nil {
+			fmt.Println("Workflow completed.")
+			return
+		}
+		var progress temporal.DomainListImportProgress
+		if err := value.Get(&progress); err != nil {
+			fmt.Println("Workflow completed.")
+			return
+		}
+		fmt.Printf("Workflow completed. Minted %d, skipped %d, failed %d, invalid entries %d\n",
+			progress.Minted, progress.Skipped, progress.Failed, progress.Invalid)
+	},
+}
+
+func init() {
+	importDomainsCmd.Flags().IntVar(&importBatchSize, "batch-size", temporal.DefaultImportBatchSize, "number of domains minted per batch")
+	importDomainsCmd.Flags().DurationVar(&importInterval, "interval", 0, "pause between batches, e.g. 30s")
+	rootCmd.AddCommand(importDomainsCmd)
+}
@@ -5,16 +5,26 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 	"go.temporal.io/sdk/client"
+	temporalsdk "go.temporal.io/sdk/temporal"
 
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
 	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
 )
 
 var (
-	temporalClient client.Client
+	cfg                *config.Config
+	temporalClient     client.Client
+	forceIngest        bool
+	mintParallelism    int
+	followFile         bool
+	followPollInterval time.Duration
+	followFromEnd      bool
+	profileName        string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -24,17 +34,58 @@ var rootCmd = &cobra.Command{
 	Long: `A CLI tool to start various workflows in the Shadow Domain Ledger system.
 	
 This tool provides convenient commands to trigger different workflows:
-- mintDomains: Start the domain ingestion and NFT minting workflow
-- hcsDemo: Start the HCS (Hedera Consensus Service) demonstration workflow`,
+- mintDomains: Start the domain ingestion and NFT minting workflow, for one or several files or globs
+- importDomains: Mint the NFTs of a list of existing registered domains
+- mintDomains-batch: Ingest the log files of a directory one at a time, oldest first
+- backfill: Ingest the archived event logs of a date range, oldest first
+- hcsDemo: Start the HCS (Hedera Consensus Service) demonstration workflow
+- resume: Resume a failed or canceled ingest run from its last checkpoint
+- resume-zone: Resume a zone paused by an incident
+- retry-quarantine: Reprocess the quarantined events and merge the outcomes into their run reports
+- registry digest, registry verify: Publish digests of the registry state to HCS and verify the registry against them
+- registry sign, registry check: Sign the zone and topic registry files and check them against their signatures
+- signing list, signing sign: List and sign the transactions exported for offline signing
+- tail: Follow the live progress of an ingest run
+- cancel: Cancel a running workflow, letting it stop cleanly
+- terminate: Terminate a workflow immediately, without cleanup
+- verify: Independently verify the ledger entry of a domain
+- domain history: Show the chronological history of a domain on the ledger
+- collections export: Export the NFTs of a zone collection to CSV, JSON or Parquet
+- collections rotate-supply-key: Set the supply key of zone collections, signed by the admin signer
+- collections migrate: Move a zone to a new collection, re-minting its NFTs
+- stats: Show per-zone totals of the ledger
+- audit: Compare the domains of a source file with the collection of a zone, for compliance checks
+- treasury audit: Audit the NFTs held by the collection treasuries against the domain index
+- icann reconcile: Compare ICANN monthly transaction reports with the ledger
+- snapshot: Capture and query the ledger at a point in time
+- proof get, proof check: Produce and check Merkle inclusion proofs of anchored events
+- metadata get: Print a metadata document stored on HCS
+- fingerprint: Compute the fingerprints of registrant handles
+- topics: Manage the HCS topic registry, its registry topic and the producer key
+- consume, offsets: Consume HCS topics as consumer groups and manage their committed offsets
+- doctor: Verify the environment before starting any workflow`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if isCompletionCmd(cmd) {
+			return
+		}
+
 		// Load .env file
 		err := godotenv.Load()
 		if err != nil {
 			log.Println("No .env file found, relying on environment variables")
 		}
 
+		// Load and validate configuration
+		cfg, err = config.LoadProfile(profileName)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if err := applyProfileFlags(cmd); err != nil {
+			log.Fatalln(err)
+		}
+
 		// Create a new Temporal client
-		temporalClient, err = client.Dial(client.Options{})
+		temporalClient, err = temporal.Dial(cfg)
 		if err != nil {
 			log.Fatalf("Unable to create Temporal client: %v", err)
 		}
@@ -46,6 +97,23 @@ This tool provides convenient commands to trigger different workflows:
 	},
 }
 
+// applyProfileFlags sets the flags of a command that were not passed explicitly to the defaults of the profile
+func applyProfileFlags(cmd *cobra.Command) error {
+	for name, value := range cfg.DefaultFlags[cmd.Name()] {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil {
+			return fmt.Errorf("profile %s: command %s has no flag --%s", cfg.Profile, cmd.Name(), name)
+		}
+		if flag.Changed {
+			continue
+		}
+		if err := cmd.Flags().Set(name, value); err != nil {
+			return fmt.Errorf("profile %s: invalid default for --%s: %w", cfg.Profile, name, err)
+		}
+	}
+	return nil
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -55,32 +123,91 @@ func main() {
 
 // mintDomainsCmd represents the mintDomains command
 var mintDomainsCmd = &cobra.Command{
-	Use:   "mintDomains [file]",
+	Use:   "mintDomains [file or glob]...",
 	Short: "Start the domain ingestion and NFT minting workflow",
 	Long: `Start the domain ingestion workflow that reads domain events from a file,
-parses them, groups by zones, and mints NFTs for each domain.`,
-	Args: cobra.ExactArgs(1),
+parses them, groups by zones, and mints NFTs for each domain. Files ending in .gz, .zst
+or .bz2 are decompressed while they are read.
+
+Several files or globs, e.g. 'logs/2025-08/*.log', start an IngestFilesWorkflow ingesting
+each file in a child workflow, --parallel files at a time.
+
+With --follow, a FollowFileWorkflow follows the growing file like tail -f, minting the events
+of new lines every --poll-interval and following the file across rotations, until canceled.`,
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		filePath := args[0]
+		filePaths, err := expandPaths(args)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if followFile {
+			if len(filePaths) != 1 {
+				log.Fatalln("--follow takes a single file")
+			}
+			mintFollow(filePaths[0])
+			return
+		}
+		if len(filePaths) > 1 {
+			mintFiles(filePaths)
+			return
+		}
+		filePath := filePaths[0]
 
-		// Check if file exists
-		if _, err := os.Stat(filePath); os.IsNotExist(err) {
-			log.Fatalf("File does not exist: %s", filePath)
+		// The workflow ID is derived from the file content, so the same data is never ingested twice
+		contentHash, err := temporal.HashFile(filePath)
+		if err != nil {
+			log.Fatalf("Unable to hash file: %v", err)
 		}
+		workflowOptions := temporal.IngestWorkflowOptions(cfg.Temporal, contentHash)
 
-		// Workflow options
-		workflowOptions := client.StartWorkflowOptions{
-			ID:        "domain-ingest-workflow_" + filePath,
-			TaskQueue: temporal.IngestTaskQueue,
+		// Refuse to reprocess content that the ingest ledger records as fully ingested. The ledger is read by a
+		// worker, it is not a file of the host the command runs on.
+		previous, err := temporal.LookupIngestRun(context.Background(), temporalClient, cfg.Temporal, temporal.IngestLedgerQuery{ContentHash: contentHash})
+		if err != nil {
+			log.Fatalf("Unable to check ingest ledger: %v", err)
+		}
+		if previous.Outcome == temporal.IngestOutcomeCompleted {
+			if !forceIngest {
+				log.Fatalf("The content of %s was already fully ingested from %s by workflow %s on %s. Use --force to ingest it again.",
+					filePath, previous.FilePath, previous.WorkflowID, previous.FinishedAt.Format(time.RFC3339))
+			}
+			if !confirm(fmt.Sprintf("The content of %s was already fully ingested by workflow %s. Mint it again?", filePath, previous.WorkflowID)) {
+				log.Fatalln("Aborted")
+			}
+			fmt.Printf("Re-ingesting content previously ingested by workflow %s (--force)\n", previous.WorkflowID)
+			workflowOptions = temporal.ForcedIngestWorkflowOptions(cfg.Temporal, contentHash, time.Now())
 		}
 
 		// Execute the workflow
-		we, err := temporalClient.ExecuteWorkflow(context.Background(), workflowOptions, temporal.IngestFileWorkflow, filePath)
+		we, attached, err := startOrAttach(context.Background(), workflowOptions, temporal.IngestFileWorkflow, temporal.IngestRequest{
+			FilePath:       filePath,
+			ContentHash:    contentHash,
+			ZoneTaskQueues: temporal.ZoneTaskQueues(cfg.Temporal.TaskQueue, cfg.Temporal.ShardedZones),
+			HCS:            cfg.HCS,
+			Zones:          cfg.Zones,
+			MaxMints:       cfg.Limits.MaxMintsPerRun,
+			BudgetTinybar:  cfg.Limits.BudgetTinybar(),
+			BudgetUSD:      cfg.Limits.BudgetUSD,
+			Notify:         cfg.Notify.Enabled(),
+			FailureAlert:   cfg.Notify.FailureThreshold,
+			EmailReport:    cfg.Email.Enabled(),
+			ArchiveReport:  cfg.Reports.Bucket != "",
+			EscalateAfter:  cfg.Escalation.Threshold(),
+
+			VisibilityTimeout: cfg.Mirror.VisibilityTimeout,
+		})
+		if temporalsdk.IsWorkflowExecutionAlreadyStartedError(err) {
+			log.Fatalf("The content of %s has already been ingested or is being ingested by workflow %s", filePath, workflowOptions.ID)
+		}
 		if err != nil {
 			log.Fatalf("Unable to execute workflow: %v", err)
 		}
 
-		fmt.Printf("Started workflow - WorkflowID: %s, RunID: %s\n", we.GetID(), we.GetRunID())
+		if attached {
+			fmt.Printf("The content of %s is already being ingested, attached to workflow %s (run %s)\n", filePath, we.GetID(), we.GetRunID())
+		} else {
+			fmt.Printf("Started workflow - WorkflowID: %s, RunID: %s\n", we.GetID(), we.GetRunID())
+		}
 
 		// Wait for the workflow to complete
 		var result string
@@ -105,11 +232,11 @@ creates a topic, sends messages, and demonstrates subscription functionality.`,
 		// Workflow options
 		workflowOptions := client.StartWorkflowOptions{
 			ID:        "hcs-demo-workflow_" + topicName,
-			TaskQueue: temporal.IngestTaskQueue,
+			TaskQueue: cfg.Temporal.TaskQueue,
 		}
 
 		// Execute the workflow
-		we, err := temporalClient.ExecuteWorkflow(context.Background(), workflowOptions, temporal.HCSDemoWorkflow, topicName)
+		we, err := executeWorkflow(context.Background(), workflowOptions, temporal.HCSDemoWorkflow, topicName)
 		if err != nil {
 			log.Fatalf("Unable to execute workflow: %v", err)
 		}
@@ -127,7 +254,16 @@ creates a topic, sends messages, and demonstrates subscription functionality.`,
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "profile of the config file to use (default $SDL_PROFILE or the file's default_profile)")
+	rootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "do not ask for confirmation of destructive actions")
+	rootCmd.RegisterFlagCompletionFunc("profile", completeProfiles)
+
 	// Add subcommands
+	mintDomainsCmd.Flags().BoolVar(&forceIngest, "force", false, "ingest the file even if its content was already fully ingested")
+	mintDomainsCmd.Flags().BoolVar(&followFile, "follow", false, "keep following the file and ingest the events appended to it")
+	mintDomainsCmd.Flags().DurationVar(&followPollInterval, "poll-interval", temporal.DefaultFollowPollInterval, "how often a followed file is checked for new lines")
+	mintDomainsCmd.Flags().BoolVar(&followFromEnd, "from-end", false, "with --follow, only ingest the lines appended from now on")
+	mintDomainsCmd.Flags().IntVar(&mintParallelism, "parallel", temporal.DefaultIngestParallelism, "files ingested at the same time when ingesting several files")
 	rootCmd.AddCommand(mintDomainsCmd)
 	rootCmd.AddCommand(hcsDemoCmd)
 }
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+// metadataCmd groups the commands working on stored metadata documents
+var metadataCmd = &cobra.Command{
+	Use:   "metadata",
+	Short: "Work with the metadata documents of domain NFTs",
+	// Only the mirror node is queried, Temporal is not contacted
+	PersistentPreRun: loadConfigOnly,
+}
+
+// metadataGetCmd represents the metadata get command
+var metadataGetCmd = &cobra.Command{
+	Use:   "get [hcs-uri]",
+	Short: "Reassemble a metadata document stored on HCS",
+	Long: `Reassemble a metadata document stored on HCS (METADATA_STORE=hcs) from its topic messages on the
+mirror node, check it against the hash in its hcs://<topic>/<sequence>/<sha256> URI and print it.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		doc, err := temporal.NewActivities(cfg).ResolveMetadataDocumentActivity(context.Background(), args[0])
+		if err != nil {
+			log.Fatalf("Unable to resolve metadata document: %v", err)
+		}
+		fmt.Println(doc)
+	},
+}
+
+func init() {
+	metadataCmd.AddCommand(metadataGetCmd)
+	rootCmd.AddCommand(metadataCmd)
+}
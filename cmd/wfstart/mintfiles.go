@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	temporalsdk "go.temporal.io/sdk/temporal"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/compressed"
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+// expandPaths expands the globs among the arguments of mintDomains and returns the regular files they name,
+// in argument order without repeats. A glob matching no file, or a path that does not exist, is an error.
+func expandPaths(args []string) ([]string, error) {
+	var filePaths []string
+	listed := make(map[string]bool)
+	for _, arg := range args {
+		matches := []string{arg}
+		if strings.ContainsAny(arg, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(arg); err != nil {
+				return nil, fmt.Errorf("invalid glob %s: %w", arg, err)
+			}
+		}
+		found := false
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("file does not exist: %s", match)
+			}
+			if err != nil {
+				return nil, err
+			}
+			if info.IsDir() {
+				continue
+			}
+			found = true
+			if !listed[match] {
+				listed[match] = true
+				filePaths = append(filePaths, match)
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no files match %s", arg)
+		}
+	}
+	return filePaths, nil
+}
+
+// mintFiles ingests several files with an IngestFilesWorkflow and prints the outcome of every file
+func mintFiles(filePaths []string) {
+	ctx := context.Background()
+	req := temporal.IngestFilesRequest{
+		Parallelism:    mintParallelism,
+		Force:          forceIngest,
+		ZoneTaskQueues: temporal.ZoneTaskQueues(cfg.Temporal.TaskQueue, cfg.Temporal.ShardedZones),
+		HCS:            cfg.HCS,
+		Zones:          cfg.Zones,
+		MaxMints:       cfg.Limits.MaxMintsPerRun,
+		BudgetTinybar:  cfg.Limits.BudgetTinybar(),
+		BudgetUSD:      cfg.Limits.BudgetUSD,
+		Notify:         cfg.Notify.Enabled(),
+		FailureAlert:   cfg.Notify.FailureThreshold,
+		EmailReport:    cfg.Email.Enabled(),
+		ArchiveReport:  cfg.Reports.Bucket != "",
+		EscalateAfter:  cfg.Escalation.Threshold(),
+
+		WorkflowIDScope: cfg.Temporal.WorkflowIDScope,
+
+		VisibilityTimeout: cfg.Mirror.VisibilityTimeout,
+	}
+	contentHashes := make([]string, len(filePaths))
+	for i, filePath := range filePaths {
+		contentHash, err := temporal.HashFile(filePath)
+		if err != nil {
+			log.Fatalf("Unable to hash %s: %v", filePath, err)
+		}
+		contentHashes[i] = contentHash
+		req.Files = append(req.Files, temporal.IngestFile{FilePath: filePath, ContentHash: contentHash})
+	}
+	if forceIngest && !confirm(fmt.Sprintf("Mint the %d files again, even those that were already fully ingested?", len(filePaths))) {
+		log.Fatalln("Aborted")
+	}
+
+	options := temporal.IngestFilesWorkflowOptions(cfg.Temporal, contentHashes)
+	we, attached, err := startOrAttach(ctx, options, temporal.IngestFilesWorkflow, req)
+	if temporalsdk.IsWorkflowExecutionAlreadyStartedError(err) {
+		log.Fatalf("These files are being ingested, or were ingested, by workflow %s", options.ID)
+	}
+	if err != nil {
+		log.Fatalf("Unable to execute workflow: %v", err)
+	}
+	if attached {
+		fmt.Printf("These files are already being ingested, attached to workflow %s (run %s)\n", we.GetID(), we.GetRunID())
+	} else {
+		fmt.Printf("Started workflow %s (run %s) ingesting %d files, %d at a time\n", we.GetID(), we.GetRunID(), len(filePaths), mintParallelism)
+	}
+
+	var result temporal.IngestFilesResult
+	if err := we.Get(ctx, &result); err != nil {
+		log.Fatalf("Unable to get workflow result: %v", err)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tOUTCOME\tWORKFLOW")
+	for _, file := range result.Files {
+		workflowID := file.WorkflowID
+		if file.Error != "" {
+			workflowID = file.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", file.FilePath, file.Outcome, workflowID)
+	}
+	w.Flush()
+	fmt.Printf("%d files: %d ingested, %d duplicates, %d failed\n", len(result.Files), result.Ingested, result.Duplicates, result.Failed)
+	if result.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// mintFollow starts a FollowFileWorkflow following a growing file. The workflow runs until it is canceled.
+func mintFollow(filePath string) {
+	if compressed.IsCompressed(filePath) {
+		log.Fatalf("Cannot follow compressed file %s", filePath)
+	}
+	// The workflow ID is derived from the path, so one file is followed by one workflow however it is named
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		log.Fatalf("Invalid path %s: %v", filePath, err)
+	}
+	options := temporal.FollowWorkflowOptions(cfg.Temporal, absPath)
+	we, err := executeWorkflow(context.Background(), options, temporal.FollowFileWorkflow, temporal.FollowRequest{
+		FilePath:       absPath,
+		PollInterval:   followPollInterval,
+		FromEnd:        followFromEnd,
+		ZoneTaskQueues: temporal.ZoneTaskQueues(cfg.Temporal.TaskQueue, cfg.Temporal.ShardedZones),
+		HCS:            cfg.HCS,
+		Zones:          cfg.Zones,
+
+		VisibilityTimeout: cfg.Mirror.VisibilityTimeout,
+	})
+	if temporalsdk.IsWorkflowExecutionAlreadyStartedError(err) {
+		log.Fatalf("%s is already followed by workflow %s", absPath, options.ID)
+	}
+	if err != nil {
+		log.Fatalf("Unable to execute workflow: %v", err)
+	}
+	fmt.Printf("Started workflow %s (run %s) following %s\n", we.GetID(), we.GetRunID(), absPath)
+	fmt.Printf("Stop it with: wfstart cancel %s\n", we.GetID())
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+var proofOutput string
+
+// proofCmd groups the commands working on inclusion proofs of anchored events
+var proofCmd = &cobra.Command{
+	Use:   "proof",
+	Short: "Produce and check Merkle inclusion proofs of anchored domain events",
+}
+
+// proofGetCmd represents the proof get command
+var proofGetCmd = &cobra.Command{
+	Use:   "get [domain]",
+	Short: "Produce the inclusion proof of the event of a domain",
+	Long: `Produce a Merkle inclusion proof showing that the event of a domain is part of a batch
+whose root was anchored to HCS. The proof is a self-contained JSON document that anybody
+can check with "proof check", using nothing but the public mirror node.`,
+	Args: cobra.ExactArgs(1),
+	// Only the anchor directory is used, Temporal is not contacted
+	PersistentPreRun: loadConfigOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		proof, err := temporal.NewActivities(cfg).InclusionProofActivity(context.Background(), args[0])
+		if err != nil {
+			log.Fatalf("Unable to produce proof: %v", err)
+		}
+		out, err := json.MarshalIndent(proof, "", "  ")
+		if err != nil {
+			log.Fatalf("Unable to encode proof: %v", err)
+		}
+		if proofOutput == "" {
+			fmt.Println(string(out))
+			return
+		}
+		if err := os.WriteFile(proofOutput, append(out, '\n'), 0644); err != nil {
+			log.Fatalf("Unable to write proof: %v", err)
+		}
+		fmt.Printf("Wrote inclusion proof of %s to %s\n", proof.Domain, proofOutput)
+	},
+}
+
+// proofCheckCmd represents the proof check command
+var proofCheckCmd = &cobra.Command{
+	Use:   "check [file]",
+	Short: "Check an inclusion proof against the root anchored to HCS",
+	Long: `Check an inclusion proof: the audit path must lead from the event hash to the root, and
+the root must be anchored in the HCS message named by the proof, as read from the mirror
+node. Exits with a non-zero status unless the proof holds.`,
+	Args: cobra.ExactArgs(1),
+	// Only the mirror node is used, Temporal is not contacted
+	PersistentPreRun: loadConfigOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			log.Fatalf("Unable to read proof: %v", err)
+		}
+		var proof temporal.InclusionProof
+		if err := json.Unmarshal(data, &proof); err != nil {
+			log.Fatalf("Unable to parse proof: %v", err)
+		}
+
+		checks, err := temporal.NewActivities(cfg).VerifyInclusionProofActivity(context.Background(), proof)
+		if err != nil {
+			log.Fatalf("Unable to check proof: %v", err)
+		}
+		fmt.Printf("Inclusion proof of %s (event %s)\n", proof.Domain, proof.EventHash)
+		for _, check := range checks {
+			fmt.Println(check)
+		}
+		if !temporal.AllPassed(checks) {
+			fmt.Println("\nINVALID")
+			os.Exit(1)
+		}
+		fmt.Println("\nVALID")
+	},
+}
+
+func init() {
+	proofGetCmd.Flags().StringVarP(&proofOutput, "output", "o", "", "write the proof to a file instead of stdout")
+	proofCmd.AddCommand(proofGetCmd)
+	proofCmd.AddCommand(proofCheckCmd)
+	rootCmd.AddCommand(proofCmd)
+}
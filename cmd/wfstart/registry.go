@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	temporalsdk "go.temporal.io/sdk/temporal"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+var (
+	registryDigestInterval time.Duration
+	registryVerifyTopic    string
+	registryVerifyJSON     bool
+)
+
+// registryCmd groups the commands proving the state of the local registry
+var registryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: "Publish and verify digests of the registry state, sign the registry files",
+	Long: `Make the local registry tamper-evident. The zone registry, the topic registry and the registry
+store are digested and the digests published to HCS_DIGEST_TOPIC, so the registry can later be
+checked against what was published. With REGISTRY_INTEGRITY set, the zone and topic registry files
+are also signed with the operator key whenever they are saved.`,
+}
+
+// registryDigestCmd represents the registry digest command
+var registryDigestCmd = &cobra.Command{
+	Use:   "digest",
+	Short: "Publish a digest of the registry state every interval",
+	Long: `Start a RegistryDigestWorkflow digesting the registry state every --interval and publishing the
+digest to HCS_DIGEST_TOPIC whenever the state changed. It runs until canceled.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if cfg.HCS.DigestTopic == "" {
+			log.Fatalln("HCS_DIGEST_TOPIC is not set")
+		}
+		options := temporal.RegistryDigestWorkflowOptions(cfg.Temporal)
+		we, err := executeWorkflow(context.Background(), options, temporal.RegistryDigestWorkflow, temporal.RegistryDigestRequest{
+			Topic:    cfg.HCS.DigestTopic,
+			Interval: registryDigestInterval,
+		})
+		if temporalsdk.IsWorkflowExecutionAlreadyStartedError(err) {
+			log.Fatalf("The registry is already digested by workflow %s", options.ID)
+		}
+		if err != nil {
+			log.Fatalf("Unable to execute workflow: %v", err)
+		}
+		fmt.Printf("Started workflow %s (run %s), digesting the registry every %s\n", we.GetID(), we.GetRunID(), registryDigestInterval)
+	},
+}
+
+// registryVerifyCmd represents the registry verify command
+var registryVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the registry state against the last digest published",
+	Long: `Digest the local registry state and compare it with the last digest published on the digest
+topic, HCS_DIGEST_TOPIC or --topic. Exits with a non-zero status unless they match. A registry
+modified outside the pipeline does not match; neither does one the pipeline changed since the last
+digest, until the next one is published.`,
+	Args: cobra.NoArgs,
+	// Only the registry, the mirror node and the registry store are read, Temporal is not contacted
+	PersistentPreRun: loadConfigOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		topic := registryVerifyTopic
+		if topic == "" {
+			topic = cfg.HCS.DigestTopic
+		}
+		if topic == "" {
+			log.Fatalln("HCS_DIGEST_TOPIC is not set, pass the digest topic with --topic")
+		}
+
+		// The activities log to stdout, keep it clean for the JSON document
+		stdout := os.Stdout
+		if registryVerifyJSON {
+			os.Stdout = os.Stderr
+		}
+		v, err := temporal.NewActivities(cfg).VerifyRegistryActivity(context.Background(), topic)
+		os.Stdout = stdout
+		if err != nil {
+			log.Fatalf("Unable to verify the registry: %v", err)
+		}
+
+		if registryVerifyJSON {
+			out, err := json.MarshalIndent(struct {
+				temporal.RegistryVerification
+				Verified bool `json:"verified"`
+			}{v, v.Verified()}, "", "  ")
+			if err != nil {
+				log.Fatalf("Unable to encode verification: %v", err)
+			}
+			fmt.Println(string(out))
+		} else {
+			fmt.Printf("Local registry digest: %s\n", v.Local.Digest)
+			for _, component := range v.Local.Components {
+				fmt.Printf("  %s: %d entries, %s\n", component.Name, component.Entries, component.SHA256)
+			}
+			if v.Published == nil {
+				fmt.Printf("\nNo digest published on topic %s\n", v.TopicID)
+			} else {
+				fmt.Printf("Last published digest: %s (message %d of topic %s, %s", v.Published.Digest, v.SequenceNumber, v.TopicID,
+					v.ConsensusTime.Local().Format(time.DateTime))
+				if v.Producer != "" {
+					fmt.Printf(", producer %s", v.Producer)
+				}
+				fmt.Println(")")
+				for _, name := range v.Changed {
+					fmt.Printf("  %s changed\n", name)
+				}
+			}
+			if v.Verified() {
+				fmt.Println("\nVERIFIED")
+			} else {
+				fmt.Println("\nNOT VERIFIED")
+			}
+		}
+		if !v.Verified() {
+			os.Exit(1)
+		}
+	},
+}
+
+// registrySignCmd represents the registry sign command
+var registrySignCmd = &cobra.Command{
+	Use:   "sign",
+	Short: "Sign the zone and topic registry files as they are",
+	Long: `Sign the zone and topic registry files with the operator key, replacing their signatures. Run it
+once after enabling REGISTRY_INTEGRITY, or after reviewing a change made to the files by hand; the
+pipeline signs the files it saves itself.`,
+	Args: cobra.NoArgs,
+	// Only the registry files are read, Temporal is not contacted
+	PersistentPreRun: loadConfigOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		signed, err := temporal.NewActivities(cfg).SignRegistryFilesActivity(context.Background())
+		if err != nil {
+			log.Fatalf("Unable to sign the registry files: %v", err)
+		}
+		if len(signed) == 0 {
+			fmt.Println("No registry file to sign")
+		}
+	},
+}
+
+// registryCheckCmd represents the registry check command
+var registryCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the zone and topic registry files against their signatures",
+	Long: `Check that the zone and topic registry files were signed by the operator key and not modified
+since. Exits with a non-zero status if any file fails the check.`,
+	Args: cobra.NoArgs,
+	// Only the registry files are read, Temporal is not contacted
+	PersistentPreRun: loadConfigOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		checks, err := temporal.NewActivities(cfg).CheckRegistryFilesActivity(context.Background())
+		if err != nil {
+			log.Fatalf("Unable to check the registry files: %v", err)
+		}
+		failed := false
+		for _, check := range checks {
+			switch {
+			case check.Error != "":
+				failed = true
+				fmt.Printf("FAIL %s\n", check.Error)
+			case check.SignedAt.IsZero():
+				fmt.Printf("OK   %s (missing, not signed)\n", check.Path)
+			default:
+				fmt.Printf("OK   %s (signed %s)\n", check.Path, check.SignedAt.Local().Format(time.DateTime))
+			}
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	registryDigestCmd.Flags().DurationVar(&registryDigestInterval, "interval", temporal.DefaultRegistryDigestInterval, "how often the registry state is digested")
+	registryVerifyCmd.Flags().StringVar(&registryVerifyTopic, "topic", "", "ID or topic registry name of the digest topic (default HCS_DIGEST_TOPIC)")
+	registryVerifyCmd.Flags().BoolVar(&registryVerifyJSON, "json", false, "print the verification as JSON")
+	registryCmd.AddCommand(registryDigestCmd)
+	registryCmd.AddCommand(registryVerifyCmd)
+	registryCmd.AddCommand(registrySignCmd)
+	registryCmd.AddCommand(registryCheckCmd)
+	rootCmd.AddCommand(registryCmd)
+}
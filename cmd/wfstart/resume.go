@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+	enumspb "go.temporal.io/api/enums/v1"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+// resumeCmd represents the resume command
+var resumeCmd = &cobra.Command{
+	Use:   "resume [workflowID]",
+	Short: "Resume a failed or canceled ingest run from its last checkpoint",
+	Long: `Resume an ingest run that failed or was canceled. The run's cursor (the last processed
+line per zone) is read from the ingest ledger and a new run of the same workflow is started
+that skips every event up to that line, instead of restarting from the top of the file.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeIngestWorkflows(temporal.IngestOutcomeFailed, temporal.IngestOutcomeCanceled, temporal.IngestOutcomeRunning),
+	Run: func(cmd *cobra.Command, args []string) {
+		workflowID := args[0]
+		ctx := context.Background()
+
+		previous, err := temporal.LookupIngestRun(ctx, temporalClient, cfg.Temporal, temporal.IngestLedgerQuery{WorkflowID: workflowID})
+		if err != nil {
+			log.Fatalf("Unable to resume: %v", err)
+		}
+		switch previous.Outcome {
+		case temporal.IngestOutcomeCompleted:
+			log.Fatalf("Workflow %s completed on %s, there is nothing to resume", workflowID, previous.FinishedAt.Format("2006-01-02 15:04:05"))
+		case temporal.IngestOutcomeRunning:
+			// The ledger may lag behind a run that was terminated, ask Temporal
+			desc, err := temporalClient.DescribeWorkflowExecution(ctx, workflowID, "")
+			if err == nil && desc.WorkflowExecutionInfo.Status == enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING {
+				log.Fatalf("Workflow %s is still running", workflowID)
+			}
+		}
+
+		// Make sure the file still holds the content the cursor refers to
+		contentHash, err := temporal.HashFile(previous.FilePath)
+		if err != nil {
+			log.Fatalf("Unable to hash file: %v", err)
+		}
+		if contentHash != previous.ContentHash {
+			log.Fatalf("The content of %s changed since workflow %s ran, refusing to resume", previous.FilePath, workflowID)
+		}
+
+		workflowOptions := temporal.IngestWorkflowOptions(cfg.Temporal, contentHash)
+		workflowOptions.ID = workflowID
+		we, err := executeWorkflow(ctx, workflowOptions, temporal.IngestFileWorkflow, temporal.IngestRequest{
+			FilePath:       previous.FilePath,
+			ContentHash:    contentHash,
+			ZoneTaskQueues: temporal.ZoneTaskQueues(cfg.Temporal.TaskQueue, cfg.Temporal.ShardedZones),
+			HCS:            cfg.HCS,
+			Zones:          cfg.Zones,
+			MaxMints:       cfg.Limits.MaxMintsPerRun,
+			BudgetTinybar:  cfg.Limits.BudgetTinybar(),
+			BudgetUSD:      cfg.Limits.BudgetUSD,
+			Notify:         cfg.Notify.Enabled(),
+			FailureAlert:   cfg.Notify.FailureThreshold,
+			EmailReport:    cfg.Email.Enabled(),
+			ArchiveReport:  cfg.Reports.Bucket != "",
+			EscalateAfter:  cfg.Escalation.Threshold(),
+			ResumeFrom:     previous.Cursor,
+
+			VisibilityTimeout: cfg.Mirror.VisibilityTimeout,
+		})
+		if err != nil {
+			log.Fatalf("Unable to execute workflow: %v", err)
+		}
+
+		fmt.Printf("Resumed workflow - WorkflowID: %s, RunID: %s\n", we.GetID(), we.GetRunID())
+		for zone, line := range previous.Cursor {
+			fmt.Printf("  .%s: continuing after line %d\n", zone, line)
+		}
+
+		// Wait for the workflow to complete
+		if err := we.Get(ctx, nil); err != nil {
+			log.Fatalf("Unable to get workflow result: %v", err)
+		}
+		fmt.Println("Workflow completed.")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(resumeCmd)
+}
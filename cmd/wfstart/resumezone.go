@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+// resumeZoneCmd represents the resume-zone command
+var resumeZoneCmd = &cobra.Command{
+	Use:   "resume-zone [zoneWorkflowID]",
+	Short: "Resume a zone paused by an incident",
+	Long: `Resume a zone whose mints or burns kept failing (ESCALATION_THRESHOLD). Such a zone opened an
+incident in PagerDuty or Opsgenie and waits until an operator resumes it; the incident names
+the workflow of the zone, <runWorkflowID>_zone_<zone>. Resuming resolves the incident, the
+zone goes on with its next domain and is escalated again if the failures continue.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		workflowID := args[0]
+		if !confirm(fmt.Sprintf("Resume zone workflow %s?", workflowID)) {
+			log.Fatalln("Aborted")
+		}
+		if err := temporalClient.SignalWorkflow(context.Background(), workflowID, "", temporal.ResumeZoneSignal, nil); err != nil {
+			log.Fatalf("Unable to signal workflow: %v", err)
+		}
+		fmt.Printf("Signaled workflow %s\n", workflowID)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(resumeZoneCmd)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+	temporalsdk "go.temporal.io/sdk/temporal"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+var (
+	retryCauses     []string
+	retryWorkflowID string
+)
+
+// retryQuarantineCmd represents the retry-quarantine command
+var retryQuarantineCmd = &cobra.Command{
+	Use:   "retry-quarantine",
+	Short: "Reprocess the quarantined events with the current code and configuration",
+	Long: `Start a RetryQuarantineWorkflow reprocessing the events kept in QUARANTINE_DIR, e.g. after a
+fix: lines that could not be parsed, events of an unknown schema version or of the wrong zone
+are parsed again, domains whose mint or burn failed after all retries are minted or burned
+again. Resolved events leave the quarantine, the others stay with their retry counted. The
+outcomes are merged into the report of the ingest run that read the events.
+
+--cause selects the causes retried (parse_error, unknown_schema, zone_mismatch, mint_failed,
+burn_failed), --workflow the events of one ingest run.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		causes := []string{
+			temporal.QuarantineParseError, temporal.QuarantineUnknownSchema, temporal.QuarantineZoneMismatch,
+			temporal.QuarantineMintFailed, temporal.QuarantineBurnFailed,
+		}
+		for _, cause := range retryCauses {
+			if !slices.Contains(causes, cause) {
+				log.Fatalf("Invalid --cause %q, expected one of %v", cause, causes)
+			}
+		}
+
+		ctx := context.Background()
+		options := temporal.RetryQuarantineWorkflowOptions(cfg.Temporal)
+		we, err := executeWorkflow(ctx, options, temporal.RetryQuarantineWorkflow, temporal.RetryQuarantineRequest{
+			Causes:     retryCauses,
+			WorkflowID: retryWorkflowID,
+			HCS:        cfg.HCS,
+			Zones:      cfg.Zones,
+			MaxMints:   cfg.Limits.MaxMintsPerRun,
+
+			BudgetTinybar:     cfg.Limits.BudgetTinybar(),
+			BudgetUSD:         cfg.Limits.BudgetUSD,
+			VisibilityTimeout: cfg.Mirror.VisibilityTimeout,
+		})
+		if temporalsdk.IsWorkflowExecutionAlreadyStartedError(err) {
+			log.Fatalf("The quarantine is already being retried by workflow %s", options.ID)
+		}
+		if err != nil {
+			log.Fatalf("Unable to execute workflow: %v", err)
+		}
+		fmt.Printf("Started workflow %s (run %s)\n", we.GetID(), we.GetRunID())
+
+		var result temporal.RetryQuarantineResult
+		if err := we.Get(ctx, &result); err != nil {
+			log.Fatalf("Retry failed: %v", err)
+		}
+		fmt.Printf("%d events retried: %d resolved, %d still quarantined\n", result.Retried, result.Resolved, result.Failed)
+		for _, report := range result.Reports {
+			fmt.Printf("Merged into %s\n", report)
+		}
+		if result.Failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	retryQuarantineCmd.Flags().StringSliceVar(&retryCauses, "cause", nil, "only retry events quarantined for these causes (default all)")
+	retryQuarantineCmd.Flags().StringVar(&retryWorkflowID, "workflow", "", "only retry the events read by this ingest run")
+	rootCmd.AddCommand(retryQuarantineCmd)
+}
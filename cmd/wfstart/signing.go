@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+var (
+	signingListJSON bool
+	signingSignAll  bool
+)
+
+// signingCmd groups the commands of the transactions signed offline
+var signingCmd = &cobra.Command{
+	Use:   "signing",
+	Short: "List and sign the transactions exported for offline signing",
+	Long: `With HEDERA_SIGNING=offline, the workers export the mints and collection creations to SIGNING_DIR
+instead of signing them, and submit them once signed. These commands read SIGNING_DIR, neither Temporal
+nor the network is contacted, so they can run on an air-gapped machine holding a copy of the directory.`,
+	PersistentPreRun: loadConfigOnly,
+}
+
+// signingListCmd represents the signing list command
+var signingListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the transactions waiting to be signed",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		pending, err := temporal.NewActivities(cfg).PendingTransactionsActivity(context.Background())
+		if err != nil {
+			log.Fatalf("Unable to list pending transactions: %v", err)
+		}
+
+		if signingListJSON {
+			out, err := json.MarshalIndent(pending, "", "  ")
+			if err != nil {
+				log.Fatalf("Unable to encode pending transactions: %v", err)
+			}
+			fmt.Println(string(out))
+			return
+		}
+		if len(pending) == 0 {
+			fmt.Printf("No transactions waiting to be signed in %s\n", cfg.Registry.SigningDir)
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tTYPE\tSIGN BEFORE\tSUMMARY")
+		for _, tx := range pending {
+			signBefore := tx.ValidUntil.Local().Format(time.DateTime)
+			if time.Now().After(tx.ValidUntil) {
+				signBefore = "expired"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", tx.ID, tx.Type, signBefore, tx.Summary)
+		}
+		w.Flush()
+	},
+}
+
+// signingSignCmd represents the signing sign command
+var signingSignCmd = &cobra.Command{
+	Use:   "sign [id...]",
+	Short: "Sign transactions waiting to be signed with HEDERA_PRIVATE_KEY",
+	Long: `Sign the given transactions of SIGNING_DIR, or all of them with --all, with HEDERA_PRIVATE_KEY and
+write the signed bytes next to them, where the workers pick them up. Review the summaries with
+"wfstart signing list" first. Expired transactions are not signed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		activities := temporal.NewActivities(cfg)
+		ids := args
+		if signingSignAll {
+			pending, err := activities.PendingTransactionsActivity(context.Background())
+			if err != nil {
+				log.Fatalf("Unable to list pending transactions: %v", err)
+			}
+			ids = nil
+			for _, tx := range pending {
+				ids = append(ids, tx.ID)
+			}
+		}
+		if len(ids) == 0 {
+			log.Fatalln("No transaction to sign, give their IDs or --all")
+		}
+
+		failed := false
+		for _, id := range ids {
+			tx, err := activities.SignPendingTransactionActivity(context.Background(), id)
+			if err != nil {
+				log.Printf("Unable to sign %s: %v", id, err)
+				failed = true
+				continue
+			}
+			fmt.Printf("Signed %s: %s\n", tx.TransactionID, tx.Summary)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	signingListCmd.Flags().BoolVar(&signingListJSON, "json", false, "print the pending transactions as JSON")
+	signingSignCmd.Flags().BoolVar(&signingSignAll, "all", false, "sign every transaction waiting to be signed")
+	signingCmd.AddCommand(signingListCmd)
+	signingCmd.AddCommand(signingSignCmd)
+	rootCmd.AddCommand(signingCmd)
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	temporalsdk "go.temporal.io/sdk/temporal"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/export"
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+var (
+	snapshotAt     string
+	snapshotZones  []string
+	snapshotFormat string
+)
+
+// snapshotCmd groups the commands working on point-in-time snapshots of the ledger
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Capture and query the ledger at a point in time",
+	Long: `Capture the active domains of the ledger at a past point in time, or ask what the ledger said
+about a single domain then, e.g. to settle a dispute. The state is rebuilt from the mint,
+transfer and burn history on the mirror node.`,
+}
+
+// snapshotCreateCmd represents the snapshot create command
+var snapshotCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Capture the active domains of every zone at a point in time",
+	Long: `Start a SnapshotWorkflow writing the domains active at --at to SNAPSHOT_DIR/<snapshot ID>: one file
+per zone, with the owner of each NFT at that time, and a snapshot.json describing the snapshot.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		at, err := parseSnapshotTime(snapshotAt)
+		if err != nil {
+			log.Fatalf("Invalid --at: %v", err)
+		}
+		if snapshotFormat != export.FormatCSV && snapshotFormat != export.FormatJSON && snapshotFormat != export.FormatParquet {
+			log.Fatalf("Invalid --format: %v", export.ErrUnknownFormat)
+		}
+		ctx := context.Background()
+		options := temporal.SnapshotWorkflowOptions(cfg.Temporal, at)
+		we, err := executeWorkflow(ctx, options, temporal.SnapshotWorkflow, temporal.SnapshotRequest{
+			At:     at,
+			Zones:  snapshotZones,
+			Format: snapshotFormat,
+		})
+		if temporalsdk.IsWorkflowExecutionAlreadyStartedError(err) {
+			log.Fatalf("A snapshot at %s is already being taken by workflow %s", at.Format(time.RFC3339), options.ID)
+		}
+		if err != nil {
+			log.Fatalf("Unable to execute workflow: %v", err)
+		}
+		fmt.Printf("Started workflow %s (run %s)\n", we.GetID(), we.GetRunID())
+		var snapshot temporal.LedgerSnapshot
+		if err := we.Get(ctx, &snapshot); err != nil {
+			log.Fatalf("Snapshot failed: %v", err)
+		}
+		failed := false
+		for _, zone := range snapshot.Zones {
+			if zone.Error != "" {
+				fmt.Printf(".%s: %s\n", zone.Zone, zone.Error)
+				failed = true
+				continue
+			}
+			fmt.Printf(".%s: %d active domains, %d burned, written to %s\n", zone.Zone, zone.Active, zone.Burned, zone.File)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// snapshotListCmd represents the snapshot list command
+var snapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the snapshots of SNAPSHOT_DIR",
+	Args:  cobra.NoArgs,
+	// Only SNAPSHOT_DIR is read, Temporal is not contacted
+	PersistentPreRun: loadConfigOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		snapshots, err := temporal.NewActivities(cfg).ListSnapshotsActivity(context.Background())
+		if err != nil {
+			log.Fatalf("Unable to list snapshots: %v", err)
+		}
+		if len(snapshots) == 0 {
+			fmt.Println("No snapshots")
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tAT\tFORMAT\tZONES\tACTIVE")
+		for _, snapshot := range snapshots {
+			active := 0
+			zones := make([]string, 0, len(snapshot.Zones))
+			for _, zone := range snapshot.Zones {
+				active += zone.Active
+				zones = append(zones, zone.Zone)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n", snapshot.ID, snapshot.At.UTC().Format(time.RFC3339), snapshot.Format,
+				strings.Join(zones, ","), active)
+		}
+		w.Flush()
+	},
+}
+
+// snapshotQueryCmd represents the snapshot query command
+var snapshotQueryCmd = &cobra.Command{
+	Use:   "query [domain]",
+	Short: "Show what the ledger said about a domain at a point in time",
+	Args:  cobra.ExactArgs(1),
+	// Only the mirror node is queried, Temporal is not contacted
+	PersistentPreRun: loadConfigOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		at, err := parseSnapshotTime(snapshotAt)
+		if err != nil {
+			log.Fatalf("Invalid --at: %v", err)
+		}
+		state, err := temporal.NewActivities(cfg).DomainAtActivity(context.Background(), args[0], at)
+		if err != nil {
+			log.Fatalf("Unable to query domain: %v", err)
+		}
+		switch {
+		case state.SerialNumber == 0:
+			fmt.Printf("%s: no NFT in collection %s\n", state.Domain, state.TokenID)
+		case !state.Registered && state.MintedAt.After(at):
+			fmt.Printf("%s: not registered at %s, serial %d of %s was minted at %s\n", state.Domain, at.Format(time.RFC3339),
+				state.SerialNumber, state.TokenID, state.MintedAt.Format(time.RFC3339))
+		case !state.Registered:
+			fmt.Printf("%s: not registered at %s, serial %d of %s was burned\n", state.Domain, at.Format(time.RFC3339),
+				state.SerialNumber, state.TokenID)
+		default:
+			fmt.Printf("%s: registered at %s as serial %d of %s, owned by %s\n", state.Domain, at.Format(time.RFC3339),
+				state.SerialNumber, state.TokenID, state.OwnerAccountID)
+		}
+	},
+}
+
+// parseSnapshotTime parses an RFC 3339 time or a date, taken as midnight UTC
+func parseSnapshotTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, fmt.Errorf("a time is required")
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+func init() {
+	snapshotCmd.PersistentFlags().StringVar(&snapshotAt, "at", "", "point in time, RFC 3339 or a date (midnight UTC)")
+	snapshotCreateCmd.Flags().StringSliceVar(&snapshotZones, "zone", nil, "only capture these zones (repeatable or comma separated, default all zones)")
+	snapshotCreateCmd.Flags().StringVar(&snapshotFormat, "format", export.FormatCSV, "format of the zone files: csv, json or parquet")
+	snapshotCreateCmd.RegisterFlagCompletionFunc("zone", completeZones)
+	snapshotCreateCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(
+		[]cobra.Completion{export.FormatCSV, export.FormatJSON, export.FormatParquet}, cobra.ShellCompDirectiveNoFileComp))
+	snapshotCmd.AddCommand(snapshotCreateCmd)
+	snapshotCmd.AddCommand(snapshotListCmd)
+	snapshotCmd.AddCommand(snapshotQueryCmd)
+	rootCmd.AddCommand(snapshotCmd)
+}
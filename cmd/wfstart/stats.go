@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+var (
+	statsZones []string
+	statsJSON  bool
+)
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show per-zone totals of the ledger",
+	Long: `Show per-zone totals: NFTs minted, mints this month, burns, fees spent by the treasury
+(in HBAR and in USD at the current exchange rate), domains skipped as duplicates and the time
+of the last ingest run.
+
+Mints, burns and fees are aggregated from the mirror node, duplicate skips and ingest times
+from the run reports in the report directory.`,
+	Args: cobra.NoArgs,
+	// Only the registries, run reports and mirror node are used, Temporal is not contacted
+	PersistentPreRun: loadConfigOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		// The activities log to stdout, keep it for the statistics
+		stdout := os.Stdout
+		os.Stdout = os.Stderr
+		activities := temporal.NewActivities(cfg)
+		stats, err := activities.ZoneStatsActivity(context.Background(), statsZones)
+		os.Stdout = stdout
+		if err != nil {
+			log.Fatalf("Unable to aggregate statistics: %v", err)
+		}
+
+		if statsJSON {
+			out, err := json.MarshalIndent(stats, "", "  ")
+			if err != nil {
+				log.Fatalf("Unable to encode statistics: %v", err)
+			}
+			fmt.Println(string(out))
+			return
+		}
+		if len(stats) == 0 {
+			fmt.Println("No zone collections in the zone registry")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ZONE\tTOKEN\tMINTED\tTHIS MONTH\tBURNED\tFEES (HBAR)\tFEES (USD)\tDUPLICATES\tLAST INGEST")
+		for _, zone := range stats {
+			lastIngest := "never"
+			if !zone.LastIngestAt.IsZero() {
+				lastIngest = zone.LastIngestAt.Local().Format(time.DateTime)
+			}
+			if zone.Error != "" {
+				fmt.Fprintf(w, ".%s\t%s\t-\t-\t-\t-\t-\t%d\t%s\n", zone.Zone, zone.TokenID, zone.DuplicateSkips, lastIngest)
+				continue
+			}
+			feesUSD := "-"
+			if zone.FeesUSD > 0 || zone.FeesTinybar == 0 {
+				feesUSD = fmt.Sprintf("%.2f", zone.FeesUSD)
+			}
+			fmt.Fprintf(w, ".%s\t%s\t%d\t%d\t%d\t%.4f\t%s\t%d\t%s\n", zone.Zone, zone.TokenID,
+				zone.Minted, zone.MintedThisMonth, zone.Burned, float64(zone.FeesTinybar)/config.TinybarsPerHbar,
+				feesUSD, zone.DuplicateSkips, lastIngest)
+		}
+		w.Flush()
+
+		for _, zone := range stats {
+			if zone.Error != "" {
+				fmt.Fprintf(os.Stderr, "Warning: .%s: %s\n", zone.Zone, zone.Error)
+			}
+		}
+	},
+}
+
+func init() {
+	statsCmd.Flags().StringSliceVar(&statsZones, "zone", nil, "only show these zones (repeatable or comma separated)")
+	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "print the statistics as JSON")
+	statsCmd.RegisterFlagCompletionFunc("zone", completeZones)
+	rootCmd.AddCommand(statsCmd)
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	enumspb "go.temporal.io/api/enums/v1"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+var (
+	tailInterval time.Duration
+	tailZones    []string
+)
+
+// progressBarWidth is the number of characters of the progress bars
+const progressBarWidth = 30
+
+// tailCmd represents the tail command
+var tailCmd = &cobra.Command{
+	Use:   "tail [workflowID]",
+	Short: "Follow the live progress of an ingest run",
+	Long: `Follow the progress of an ingest run until it finishes. The ingest workflow and each of
+its zone workflows are queried periodically and rendered as a progress bar with per-zone
+counts, an estimated time to completion and the most recent failures.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeIngestWorkflows(temporal.IngestOutcomeRunning),
+	Run: func(cmd *cobra.Command, args []string) {
+		workflowID := args[0]
+		ctx := context.Background()
+
+		ticker := time.NewTicker(tailInterval)
+		defer ticker.Stop()
+		for {
+			progress, err := queryIngestProgress(ctx, workflowID)
+			if err != nil {
+				log.Fatalf("Unable to query progress: %v", err)
+			}
+			desc, err := temporalClient.DescribeWorkflowExecution(ctx, workflowID, "")
+			if err != nil {
+				log.Fatalf("Unable to describe workflow: %v", err)
+			}
+			status := desc.WorkflowExecutionInfo.Status
+
+			// Clear the screen and redraw from the top left corner
+			fmt.Print("\033[H\033[2J")
+			progress.Zones = filterZones(progress.Zones, tailZones)
+			fmt.Print(renderProgress(workflowID, progress, time.Now()))
+
+			if status != enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING {
+				fmt.Printf("\nWorkflow %s\n", strings.ToLower(status.String()))
+				return
+			}
+			<-ticker.C
+		}
+	},
+}
+
+// queryIngestProgress queries an ingest run and the live progress of each of its zones.
+// Zones whose workflow cannot be queried (not started yet or already closed) keep the
+// totals reported by the ingest workflow.
+func queryIngestProgress(ctx context.Context, workflowID string) (temporal.IngestProgress, error) {
+	var progress temporal.IngestProgress
+	value, err := temporalClient.QueryWorkflow(ctx, workflowID, "", temporal.ProgressQuery)
+	if err != nil {
+		return progress, err
+	}
+	if err := value.Get(&progress); err != nil {
+		return progress, err
+	}
+
+	for i, zone := range progress.Zones {
+		value, err := temporalClient.QueryWorkflow(ctx, zone.WorkflowID, "", temporal.ProgressQuery)
+		if err != nil {
+			continue
+		}
+		var live temporal.ZoneProgress
+		if err := value.Get(&live); err != nil {
+			continue
+		}
+		live.Done = live.Done || zone.Done
+		progress.Zones[i] = live
+	}
+	return progress, nil
+}
+
+// filterZones keeps the progress of the given zones only, or of all zones if none are given
+func filterZones(zones []temporal.ZoneProgress, keep []string) []temporal.ZoneProgress {
+	if len(keep) == 0 {
+		return zones
+	}
+	var filtered []temporal.ZoneProgress
+	for _, zone := range zones {
+		if slices.Contains(keep, zone.Zone) {
+			filtered = append(filtered, zone)
+		}
+	}
+	return filtered
+}
+
+// renderProgress formats a progress snapshot for the terminal
+func renderProgress(workflowID string, progress temporal.IngestProgress, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Workflow: %s\n", workflowID)
+	fmt.Fprintf(&b, "File:     %s\n", progress.FilePath)
+	fmt.Fprintf(&b, "Stage:    %s\n", progress.Stage)
+	if progress.BudgetTinybar > 0 {
+		fmt.Fprintf(&b, "Budget:   %.4f of %.4f HBAR spent\n",
+			float64(progress.FeesTinybar)/config.TinybarsPerHbar, float64(progress.BudgetTinybar)/config.TinybarsPerHbar)
+	}
+	b.WriteString("\n")
+
+	total, processed := 0, 0
+	var failures []temporal.MintFailure
+	for _, zone := range progress.Zones {
+		total += zone.Total
+		processed += zone.Processed()
+		failures = append(failures, zone.RecentFailures...)
+	}
+
+	fmt.Fprintf(&b, "%s %d/%d", progressBar(processed, total), processed, total)
+	if eta, ok := estimateRemaining(progress.StartedAt, now, processed, total); ok {
+		fmt.Fprintf(&b, "  ETA %s", eta.Round(time.Second))
+	}
+	b.WriteString("\n\n")
+
+	for _, zone := range progress.Zones {
+		state := ""
+		switch {
+		case zone.Done:
+			state = "  done"
+		case zone.Escalated:
+			state = "  paused, incident open"
+		case zone.Paused:
+			state = "  paused"
+		}
+		fmt.Fprintf(&b, "  .%-12s %s %d/%d  minted %d, skipped %d, failed %d",
+			zone.Zone, progressBar(zone.Processed(), zone.Total), zone.Processed(), zone.Total,
+			zone.Minted, zone.Skipped, zone.Failed)
+		if zone.Burned > 0 || zone.Ignored > 0 {
+			fmt.Fprintf(&b, ", burned %d, ignored %d", zone.Burned, zone.Ignored)
+		}
+		b.WriteString(state + "\n")
+	}
+	refused := slices.Sorted(maps.Keys(progress.RefusedZones))
+	for _, zone := range refused {
+		fmt.Fprintf(&b, "  .%-12s refused %d, zone not allowed\n", zone, progress.RefusedZones[zone])
+	}
+
+	if len(failures) > 0 {
+		sort.Slice(failures, func(i, j int) bool { return failures[i].Time.After(failures[j].Time) })
+		if len(failures) > 5 {
+			failures = failures[:5]
+		}
+		b.WriteString("\nRecent failures:\n")
+		for _, f := range failures {
+			fmt.Fprintf(&b, "  %s %s: %s\n", f.Time.Local().Format("15:04:05"), f.Domain, f.Error)
+		}
+	}
+	return b.String()
+}
+
+// progressBar renders a fixed width bar for done out of total
+func progressBar(done, total int) string {
+	filled := 0
+	if total > 0 {
+		filled = done * progressBarWidth / total
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", progressBarWidth-filled) + "]"
+}
+
+// estimateRemaining extrapolates the time left from the average rate since the run started
+func estimateRemaining(startedAt, now time.Time, processed, total int) (time.Duration, bool) {
+	elapsed := now.Sub(startedAt)
+	if processed == 0 || elapsed <= 0 || processed >= total {
+		return 0, false
+	}
+	perItem := elapsed / time.Duration(processed)
+	return perItem * time.Duration(total-processed), true
+}
+
+func init() {
+	tailCmd.Flags().DurationVar(&tailInterval, "interval", 2*time.Second, "how often to refresh the progress")
+	tailCmd.Flags().StringSliceVar(&tailZones, "zone", nil, "only show these zones (repeatable or comma separated)")
+	tailCmd.RegisterFlagCompletionFunc("zone", completeZones)
+	rootCmd.AddCommand(tailCmd)
+}
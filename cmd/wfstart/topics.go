@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+// registryTopicName is the topic registry name of the registry topic created by topics init-registry
+const registryTopicName = "topic-registry"
+
+var topicsListSync bool
+
+// topicsCmd groups the commands working on the topic registry
+var topicsCmd = &cobra.Command{
+	Use:   "topics",
+	Short: "Manage the HCS topic registry",
+	Long: `Manage the HCS topics of the ledger. With HCS_REGISTRY_TOPIC set, every topic created is recorded
+on that registry topic and the topic registry file is a local cache of it, so workers discover
+each other's topics without sharing the file.`,
+	// Only the topic registry file and Hedera are used, Temporal is not contacted
+	PersistentPreRun: loadConfigOnly,
+}
+
+// topicsListCmd represents the topics list command
+var topicsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the topics of the topic registry",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		activities := temporal.NewActivities(cfg)
+		if topicsListSync {
+			if _, err := activities.SyncTopicRegistryActivity(context.Background()); err != nil {
+				log.Fatalf("Unable to sync topic registry: %v", err)
+			}
+		}
+		topics, err := activities.ListTopicsActivity(context.Background())
+		if err != nil {
+			log.Fatalf("Unable to list topics: %v", err)
+		}
+		if len(topics) == 0 {
+			fmt.Println("No topics in the topic registry")
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tTOPIC\tCREATED\tRECORD")
+		for _, topic := range topics {
+			record := "-"
+			if topic.RegistrySequence > 0 {
+				record = strconv.FormatUint(topic.RegistrySequence, 10)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", topic.TopicName, topic.TopicID, topic.CreatedAt.Local().Format(time.DateTime), record)
+		}
+		w.Flush()
+	},
+}
+
+// topicsSyncCmd represents the topics sync command
+var topicsSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Update the topic registry file from the registry topic",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		result, err := temporal.NewActivities(cfg).SyncTopicRegistryActivity(context.Background())
+		if err != nil {
+			log.Fatalf("Unable to sync topic registry: %v", err)
+		}
+		for _, name := range result.Conflicts {
+			fmt.Printf("Ignored a later record of topic '%s', the name is already taken\n", name)
+		}
+	},
+}
+
+// topicsPublishCmd represents the topics publish command
+var topicsPublishCmd = &cobra.Command{
+	Use:   "publish",
+	Short: "Record the topics of the topic registry file on the registry topic",
+	Long: `Record the topics that are only in the topic registry file, such as topics created before
+HCS_REGISTRY_TOPIC was set, on the registry topic.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		published, err := temporal.NewActivities(cfg).PublishTopicRegistryActivity(context.Background())
+		for _, topic := range published {
+			fmt.Printf("Published topic '%s' (%s) as record %d\n", topic.TopicName, topic.TopicID, topic.RegistrySequence)
+		}
+		if err != nil {
+			log.Fatalf("Unable to publish topics: %v", err)
+		}
+		if len(published) == 0 {
+			fmt.Println("All topics are on the registry topic")
+		}
+	},
+}
+
+// topicsInitRegistryCmd represents the topics init-registry command
+var topicsInitRegistryCmd = &cobra.Command{
+	Use:   "init-registry",
+	Short: "Create the registry topic",
+	Long: `Create the registry topic, with the operator key as submit key so only the ledger can record
+topics on it. Set HCS_REGISTRY_TOPIC to its ID on every worker, then run topics publish.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if cfg.HCS.RegistryTopic != "" {
+			log.Fatalf("HCS_REGISTRY_TOPIC is already set to %s", cfg.HCS.RegistryTopic)
+		}
+		topic, err := temporal.NewActivities(cfg).CreateTopicActivity(context.Background(), registryTopicName,
+			"Topic registry of the shadow domain ledger", true, true)
+		if err != nil {
+			log.Fatalf("Unable to create registry topic: %v", err)
+		}
+		fmt.Printf("Created registry topic %s, set HCS_REGISTRY_TOPIC=%s\n", topic.TopicID, topic.TopicID)
+	},
+}
+
+// topicsProducerKeyCmd represents the topics producer-key command
+var topicsProducerKeyCmd = &cobra.Command{
+	Use:   "producer-key",
+	Short: "Print the public key signing the envelopes of this worker",
+	Long: `Print the public key of HCS_PRODUCER_KEY as a JSON Web Key, with HCS_PRODUCER_ID as key ID.
+Consumers add it to their key set to verify the audit messages published by this worker.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		jwk, err := temporal.ProducerJWK(cfg)
+		if err != nil {
+			log.Fatalf("Unable to export producer key: %v", err)
+		}
+		fmt.Println(string(jwk))
+	},
+}
+
+func init() {
+	topicsListCmd.Flags().BoolVar(&topicsListSync, "sync", false, "sync with the registry topic before listing")
+	topicsCmd.AddCommand(topicsListCmd)
+	topicsCmd.AddCommand(topicsSyncCmd)
+	topicsCmd.AddCommand(topicsPublishCmd)
+	topicsCmd.AddCommand(topicsInitRegistryCmd)
+	topicsCmd.AddCommand(topicsProducerKeyCmd)
+	rootCmd.AddCommand(topicsCmd)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+var (
+	transactionsZone string
+	transactionsJSON bool
+)
+
+// transactionsCmd represents the transactions command
+var transactionsCmd = &cobra.Command{
+	Use:   "transactions",
+	Short: "List the transaction records kept for audits",
+	Long: `List the full records of the collection creations, mints and burns of the ledger, as kept in
+TRANSACTION_RECORD_DIR by the workers: transaction ID, consensus time, status, fee and serials.
+
+The records are read from disk, neither Temporal nor the mirror node is contacted.`,
+	Args:             cobra.NoArgs,
+	PersistentPreRun: loadConfigOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		activities := temporal.NewActivities(cfg)
+		records, err := activities.ListTransactionRecordsActivity(context.Background(), transactionsZone)
+		if err != nil {
+			log.Fatalf("Unable to list transaction records: %v", err)
+		}
+
+		if transactionsJSON {
+			out, err := json.MarshalIndent(records, "", "  ")
+			if err != nil {
+				log.Fatalf("Unable to encode transaction records: %v", err)
+			}
+			fmt.Println(string(out))
+			return
+		}
+		if len(records) == 0 {
+			fmt.Printf("No transaction records in %s\n", cfg.Registry.TransactionDir)
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CONSENSUS\tTYPE\tZONE\tDOMAIN\tTOKEN\tSERIALS\tSTATUS\tFEE (HBAR)\tTRANSACTION")
+		for _, record := range records {
+			domain := record.Domain
+			if domain == "" {
+				domain = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t.%s\t%s\t%s\t%v\t%s\t%.4f\t%s\n", record.ConsensusAt.Local().Format(time.DateTime),
+				record.Type, record.Zone, domain, record.TokenID, record.Serials, record.Status,
+				float64(record.FeeTinybar)/config.TinybarsPerHbar, record.TransactionID)
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	transactionsCmd.Flags().StringVar(&transactionsZone, "zone", "", "only list the transactions of this zone")
+	transactionsCmd.Flags().BoolVar(&transactionsJSON, "json", false, "print the records as JSON")
+	transactionsCmd.RegisterFlagCompletionFunc("zone", completeZones)
+	rootCmd.AddCommand(transactionsCmd)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+var (
+	auditZones []string
+	auditJSON  bool
+)
+
+// treasuryCmd groups the commands working on the treasury accounts of the zone collections
+var treasuryCmd = &cobra.Command{
+	Use:   "treasury",
+	Short: "Work with the treasury accounts of the zone collections",
+}
+
+// treasuryAuditCmd represents the treasury audit command
+var treasuryAuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Audit the NFTs held by the collection treasuries against the domain index",
+	Long: `List the NFTs of every zone collection, and of every shard of sharded zones, with their
+holders on the mirror node, and count those still held by the treasury of the collection.
+
+NFTs outside the treasury are flagged as transferred unless the transfer out of the treasury
+was the claim of their domain. With REGISTRY_STORE_DSN, the NFTs are also cross-checked against
+the domain index: NFTs it does not record for their domain are flagged as not indexed, and
+domains it records as minted whose NFT the collection does not have as missing.
+
+Exits with a non-zero status when anything is flagged.`,
+	Args: cobra.NoArgs,
+	// Only the registries and the mirror node are used, Temporal is not contacted
+	PersistentPreRun: loadConfigOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		// The activities log to stdout, keep it for the audit
+		stdout := os.Stdout
+		os.Stdout = os.Stderr
+		activities := temporal.NewActivities(cfg)
+		audits, err := activities.TreasuryAuditActivity(context.Background(), auditZones)
+		os.Stdout = stdout
+		if err != nil {
+			log.Fatalf("Unable to audit the treasuries: %v", err)
+		}
+
+		flagged := false
+		for _, audit := range audits {
+			flagged = flagged || audit.Error != "" || len(audit.Findings) > 0
+		}
+		if auditJSON {
+			out, err := json.MarshalIndent(audits, "", "  ")
+			if err != nil {
+				log.Fatalf("Unable to encode the audit: %v", err)
+			}
+			fmt.Println(string(out))
+		} else {
+			if len(audits) == 0 {
+				fmt.Println("No zone collections in the zone registry")
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ZONE\tTOKEN\tTREASURY\tMINTED\tIN TREASURY\tCLAIMED\tFINDINGS")
+			for _, audit := range audits {
+				if audit.Error != "" {
+					fmt.Fprintf(w, ".%s\t%s\t-\t-\t-\t-\t%s\n", audit.Zone, audit.TokenID, audit.Error)
+					continue
+				}
+				fmt.Fprintf(w, ".%s\t%s\t%s\t%d\t%d\t%d\t%d\n", audit.Zone, audit.TokenID, audit.Treasury,
+					audit.Minted, audit.InTreasury, audit.Claimed, len(audit.Findings))
+			}
+			w.Flush()
+
+			for _, audit := range audits {
+				for _, finding := range audit.Findings {
+					switch finding.Issue {
+					case temporal.AuditTransferred:
+						by := "without a transfer from the treasury"
+						if finding.TransactionID != "" {
+							by = "by " + finding.TransactionID
+						}
+						fmt.Printf("%s: serial %d of %s is held by %s, %s\n",
+							finding.Domain, finding.SerialNumber, finding.TokenID, finding.Holder, by)
+					case temporal.AuditNotIndexed:
+						fmt.Printf("%s: serial %d of %s is not in the domain index\n", finding.Domain, finding.SerialNumber, finding.TokenID)
+					case temporal.AuditMissing:
+						fmt.Printf("%s: the domain index records serial %d of %s, which is burned or not in the collection\n",
+							finding.Domain, finding.SerialNumber, finding.TokenID)
+					}
+				}
+			}
+		}
+		if flagged {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	treasuryAuditCmd.Flags().StringSliceVar(&auditZones, "zone", nil, "only audit these zones (repeatable or comma separated)")
+	treasuryAuditCmd.Flags().BoolVar(&auditJSON, "json", false, "print the audit as JSON")
+	treasuryAuditCmd.RegisterFlagCompletionFunc("zone", completeZones)
+	treasuryCmd.AddCommand(treasuryAuditCmd)
+	rootCmd.AddCommand(treasuryCmd)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
+)
+
+var (
+	verifyTokenID string
+	verifyJSON    bool
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify [domain]",
+	Short: "Independently verify the ledger entry of a domain",
+	Long: `Verify the ledger entry of a domain using public data only. The zone collection is
+located on the mirror node by its well-known token name (or given with --token), the NFT of
+the domain is looked up, and its mint transaction is fetched and checked.
+
+Neither Temporal nor operator credentials are needed, so auditors can run it with nothing
+but a mirror node. Exits with a non-zero status unless the domain is verified.`,
+	Args: cobra.ExactArgs(1),
+	// Only the Hedera network and mirror node settings are used, Temporal is not contacted
+	PersistentPreRun: loadConfigOnly,
+	Run: func(cmd *cobra.Command, args []string) {
+		// The activities log to stdout, keep it clean for the JSON document
+		stdout := os.Stdout
+		if verifyJSON {
+			os.Stdout = os.Stderr
+		}
+		activities := temporal.NewActivities(cfg)
+		v, err := activities.VerifyDomainActivity(context.Background(), args[0], verifyTokenID)
+		os.Stdout = stdout
+		if err != nil {
+			log.Fatalf("Unable to verify: %v", err)
+		}
+
+		if verifyJSON {
+			out, err := json.MarshalIndent(struct {
+				temporal.DomainVerification
+				Verified bool `json:"verified"`
+			}{v, v.Verified()}, "", "  ")
+			if err != nil {
+				log.Fatalf("Unable to encode verification: %v", err)
+			}
+			fmt.Println(string(out))
+		} else {
+			fmt.Printf("\nVerification of %s on %s\n", v.Domain, cfg.Hedera.Network)
+			for _, check := range v.Checks {
+				fmt.Println(check)
+			}
+			if v.Verified() {
+				fmt.Println("\nVERIFIED")
+			} else {
+				fmt.Println("\nNOT VERIFIED")
+			}
+		}
+		if !v.Verified() {
+			os.Exit(1)
+		}
+	},
+}
+
+// loadConfigOnly is the PersistentPreRun of commands that work on public ledger data only:
+// it loads the configuration like the root command, but does not connect to Temporal
+func loadConfigOnly(cmd *cobra.Command, args []string) {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, relying on environment variables")
+	}
+	var err error
+	if cfg, err = config.LoadProfile(profileName); err != nil {
+		log.Fatalln(err)
+	}
+	if err := applyProfileFlags(cmd); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func init() {
+	verifyCmd.Flags().StringVar(&verifyTokenID, "token", "", "token ID of the zone collection, instead of looking it up by name")
+	verifyCmd.Flags().BoolVar(&verifyJSON, "json", false, "print the verification as JSON")
+	rootCmd.AddCommand(verifyCmd)
+}
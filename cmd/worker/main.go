@@ -1,9 +1,15 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"sync"
 
 	"github.com/joho/godotenv"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
 	"github.com/onasunnymorning/shadow-domain-ledger/temporal"
 
 	"go.temporal.io/sdk/client"
@@ -11,30 +17,134 @@ import (
 )
 
 func main() {
+	check := flag.Bool("check", false, "run the environment self-check and exit")
+	profile := flag.String("profile", "", "profile of the config file to use (default $SDL_PROFILE or the file's default_profile)")
+	zones := flag.String("zones", "", "comma separated zones to serve on their sharded task queues (e.g. build,dev) instead of the main task queue")
+	flag.Parse()
+
 	// Load .env file
 	err := godotenv.Load()
 	if err != nil {
 		log.Println("No .env file found, relying on environment variables")
 	}
 
+	// Load and validate configuration
+	cfg, err := config.LoadProfile(*profile)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if err := applyProfileFlags(cfg); err != nil {
+		log.Fatalln(err)
+	}
+
 	// Create a new Temporal client
-	c, err := client.Dial(client.Options{})
+	c, err := temporal.Dial(cfg)
+	if *check {
+		os.Exit(runSelfCheck(cfg, c, err))
+	}
+	// Signing offline, the worker holds no operator key, Validate checked the account and public key instead
+	if cfg.Hedera.Signing == config.SigningOnline {
+		if err := cfg.RequireOperator(); err != nil {
+			log.Fatalln(err)
+		}
+	}
 	if err != nil {
 		log.Fatalln("Unable to create client", err)
 	}
 	defer c.Close()
 
-	// Create a new worker
-	w := worker.New(c, temporal.IngestTaskQueue, worker.Options{})
+	// A zone worker serves only the task queues of its zones, the default worker serves the main queue
+	queues := []string{cfg.Temporal.TaskQueue}
+	if zoneList := config.ParseList(*zones); len(zoneList) > 0 {
+		queues = queues[:0]
+		for _, zone := range zoneList {
+			queues = append(queues, temporal.ZoneTaskQueue(cfg.Temporal.TaskQueue, zone))
+		}
+	}
 
-	// Register the Workflow and Activities
-	w.RegisterWorkflow(temporal.IngestFileWorkflow)
-	w.RegisterWorkflow(temporal.HCSDemoWorkflow)
-	w.RegisterActivity(&temporal.Activities{})
+	activities := temporal.NewActivities(cfg)
+	workers := make([]worker.Worker, 0, len(queues))
+	for _, queue := range queues {
+		// On SIGINT/SIGTERM the worker stops polling and gives in-flight activities
+		// WorkerStopTimeout to complete, so submitted Hedera transactions are not orphaned.
+		w := worker.New(c, queue, worker.Options{
+			WorkerStopTimeout: cfg.Temporal.WorkerStopTimeout,
+		})
 
-	// Start listening to the Task Queue
-	err = w.Run(worker.InterruptCh())
-	if err != nil {
-		log.Fatalln("Unable to start worker", err)
+		// Register the Workflows and Activities
+		w.RegisterWorkflow(temporal.IngestFileWorkflow)
+		w.RegisterWorkflow(temporal.IngestFilesWorkflow)
+		w.RegisterWorkflow(temporal.FollowFileWorkflow)
+		w.RegisterWorkflow(temporal.ProcessZoneWorkflow)
+		w.RegisterWorkflow(temporal.ImportDomainListWorkflow)
+		w.RegisterWorkflow(temporal.ClaimDomainWorkflow)
+		w.RegisterWorkflow(temporal.BrandCollectionWorkflow)
+		w.RegisterWorkflow(temporal.RotateSupplyKeyWorkflow)
+		w.RegisterWorkflow(temporal.MigrateCollectionWorkflow)
+		w.RegisterWorkflow(temporal.HCSDemoWorkflow)
+		w.RegisterWorkflow(temporal.ConsumeTopicWorkflow)
+		w.RegisterWorkflow(temporal.SnapshotWorkflow)
+		w.RegisterWorkflow(temporal.BackfillWorkflow)
+		w.RegisterWorkflow(temporal.IngestBatchWorkflow)
+		w.RegisterWorkflow(temporal.RetryQuarantineWorkflow)
+		w.RegisterWorkflow(temporal.RegistryDigestWorkflow)
+		w.RegisterWorkflow(temporal.LookupIngestRunWorkflow)
+		w.RegisterActivity(activities)
+
+		if err := w.Start(); err != nil {
+			stopWorkers(workers)
+			c.Close()
+			log.Fatalln("Unable to start worker", err)
+		}
+		log.Printf("Worker listening on %s, namespace %s, task queue %s", cfg.Temporal.Address, cfg.Temporal.Namespace, queue)
+		workers = append(workers, w)
+	}
+
+	// Run until interrupted
+	<-worker.InterruptCh()
+	stopWorkers(workers)
+	log.Println("Worker stopped, closing Temporal client")
+}
+
+// applyProfileFlags sets the flags that were not passed explicitly to the "worker" defaults of the profile
+func applyProfileFlags(cfg *config.Config) error {
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	for name, value := range cfg.DefaultFlags["worker"] {
+		if explicit[name] {
+			continue
+		}
+		if err := flag.Set(name, value); err != nil {
+			return fmt.Errorf("profile %s: invalid default for -%s: %w", cfg.Profile, name, err)
+		}
+	}
+	return nil
+}
+
+// stopWorkers stops all workers concurrently, each waiting up to WorkerStopTimeout for in-flight activities
+func stopWorkers(workers []worker.Worker) {
+	var wg sync.WaitGroup
+	for _, w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.Stop()
+		}()
+	}
+	wg.Wait()
+}
+
+// runSelfCheck prints the self-check results and returns the process exit code
+func runSelfCheck(cfg *config.Config, c client.Client, dialErr error) int {
+	if c != nil {
+		defer c.Close()
+	}
+	results := temporal.SelfCheck(context.Background(), cfg, c, dialErr)
+	for _, r := range results {
+		fmt.Println(r)
+	}
+	if !temporal.AllPassed(results) {
+		return 1
 	}
+	return 0
 }
@@ -0,0 +1,201 @@
+// Package apiauth authenticates the clients of the API service, by API key or HS256 JSON Web Token, and
+// limits the rate of their requests. Clients hold a scope: read-only clients query the ledger, admin clients
+// may also act on it.
+package apiauth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Scope is what a client is allowed to do, a scope includes the ones below it
+type Scope int
+
+// Scopes of clients
+const (
+	ScopeRead  Scope = iota + 1 // Queries of the ledger
+	ScopeAdmin                  // Queries and operations on the ledger
+)
+
+// String returns the name of the scope as used in JWT scope claims
+func (s Scope) String() string {
+	switch s {
+	case ScopeRead:
+		return "read"
+	case ScopeAdmin:
+		return "admin"
+	default:
+		return fmt.Sprintf("scope(%d)", int(s))
+	}
+}
+
+// ErrUnauthenticated is returned for missing, unknown, expired or forged credentials
+var ErrUnauthenticated = errors.New("missing or invalid credentials")
+
+// Client is an authenticated client of the API
+type Client struct {
+	Name  string // Name of its API key, or "jwt:" and the subject of its token
+	Scope Scope
+}
+
+// Allows tells whether the client holds the scope
+func (c Client) Allows(scope Scope) bool {
+	return c.Scope >= scope
+}
+
+// Authenticator identifies the clients of API keys and JSON Web Tokens
+type Authenticator struct {
+	keys      []apiKey
+	jwtSecret []byte
+	now       func() time.Time
+}
+
+// apiKey is an API key by the hash of its value, so keys of different lengths compare in constant time
+type apiKey struct {
+	hash   [sha256.Size]byte
+	client Client
+}
+
+// New returns an authenticator of the read-only and admin API keys, both maps of names to keys, and of the
+// JSON Web Tokens signed with the secret, if not empty
+func New(readKeys, adminKeys map[string]string, jwtSecret string) *Authenticator {
+	a := &Authenticator{jwtSecret: []byte(jwtSecret), now: time.Now}
+	for name, key := range readKeys {
+		a.keys = append(a.keys, apiKey{hash: sha256.Sum256([]byte(key)), client: Client{Name: name, Scope: ScopeRead}})
+	}
+	for name, key := range adminKeys {
+		a.keys = append(a.keys, apiKey{hash: sha256.Sum256([]byte(key)), client: Client{Name: name, Scope: ScopeAdmin}})
+	}
+	return a
+}
+
+// Enabled tells whether any credentials are configured, the API is open otherwise
+func (a *Authenticator) Enabled() bool {
+	return len(a.keys) > 0 || len(a.jwtSecret) > 0
+}
+
+// Authenticate returns the client of an API key or a JSON Web Token
+func (a *Authenticator) Authenticate(credential string) (Client, error) {
+	if credential == "" {
+		return Client{}, ErrUnauthenticated
+	}
+	if len(a.jwtSecret) > 0 && strings.Count(credential, ".") == 2 {
+		return a.verifyJWT(credential)
+	}
+	hash := sha256.Sum256([]byte(credential))
+	for _, key := range a.keys {
+		if subtle.ConstantTimeCompare(hash[:], key.hash[:]) == 1 {
+			return key.client, nil
+		}
+	}
+	return Client{}, ErrUnauthenticated
+}
+
+// claims are the claims of a JSON Web Token read by the authenticator
+type claims struct {
+	Subject   string `json:"sub"`
+	Scope     string `json:"scope"` // Space separated scopes, the highest known one is held
+	ExpiresAt int64  `json:"exp"`
+	NotBefore int64  `json:"nbf"`
+}
+
+// verifyJWT checks the HS256 signature and validity period of a token and returns its client. Tokens must
+// expire and name a subject and a known scope.
+func (a *Authenticator) verifyJWT(token string) (Client, error) {
+	parts := strings.Split(token, ".")
+	var header struct {
+		Algorithm string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil || header.Algorithm != "HS256" {
+		return Client{}, ErrUnauthenticated
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Client{}, ErrUnauthenticated
+	}
+	mac := hmac.New(sha256.New, a.jwtSecret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return Client{}, ErrUnauthenticated
+	}
+
+	var c claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return Client{}, ErrUnauthenticated
+	}
+	now := a.now().Unix()
+	if c.Subject == "" || c.ExpiresAt == 0 || now >= c.ExpiresAt || now < c.NotBefore {
+		return Client{}, ErrUnauthenticated
+	}
+	client := Client{Name: "jwt:" + c.Subject}
+	for _, scope := range strings.Fields(c.Scope) {
+		for _, known := range []Scope{ScopeRead, ScopeAdmin} {
+			if scope == known.String() && known > client.Scope {
+				client.Scope = known
+			}
+		}
+	}
+	if client.Scope == 0 {
+		return Client{}, ErrUnauthenticated
+	}
+	return client, nil
+}
+
+// decodeSegment decodes a base64url encoded JSON segment of a token
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Limiter limits the requests of every client to its own rate
+type Limiter struct {
+	limit rate.Limit
+	burst int
+
+	mu      sync.Mutex
+	clients map[string]*rate.Limiter
+}
+
+// NewLimiter returns a limiter admitting perMinute requests a minute per client, and bursts of up to burst
+// requests. Zero perMinute admits every request.
+func NewLimiter(perMinute, burst int) *Limiter {
+	return &Limiter{
+		limit:   rate.Limit(float64(perMinute) / 60),
+		burst:   max(burst, 1),
+		clients: make(map[string]*rate.Limiter),
+	}
+}
+
+// Allow tells whether a request of the client is admitted now, and otherwise how long it should wait
+func (l *Limiter) Allow(client string) (bool, time.Duration) {
+	if l.limit == 0 {
+		return true, 0
+	}
+	l.mu.Lock()
+	limiter, ok := l.clients[client]
+	if !ok {
+		limiter = rate.NewLimiter(l.limit, l.burst)
+		l.clients[client] = limiter
+	}
+	l.mu.Unlock()
+
+	reservation := limiter.Reserve()
+	if delay := reservation.Delay(); delay > 0 {
+		reservation.Cancel()
+		return false, delay
+	}
+	return true, 0
+}
@@ -0,0 +1,98 @@
+package apiauth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const secret = "0123456789abcdef0123456789abcdef"
+
+// signJWT returns an HS256 token of the claims
+func signJWT(t *testing.T, key, claims string) string {
+	t.Helper()
+	b64 := base64.RawURLEncoding.EncodeToString
+	unsigned := b64([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + b64([]byte(claims))
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + b64(mac.Sum(nil))
+}
+
+func TestAuthenticate_Keys(t *testing.T) {
+	a := New(map[string]string{"registrar-a": "read-key"}, map[string]string{"ops": "admin-key"}, "")
+	assert.True(t, a.Enabled())
+
+	client, err := a.Authenticate("read-key")
+	require.NoError(t, err)
+	assert.Equal(t, Client{Name: "registrar-a", Scope: ScopeRead}, client)
+	assert.True(t, client.Allows(ScopeRead))
+	assert.False(t, client.Allows(ScopeAdmin))
+
+	client, err = a.Authenticate("admin-key")
+	require.NoError(t, err)
+	assert.Equal(t, "ops", client.Name)
+	assert.True(t, client.Allows(ScopeRead))
+	assert.True(t, client.Allows(ScopeAdmin))
+
+	for _, invalid := range []string{"", "read", "read-key ", "a.b.c"} {
+		_, err := a.Authenticate(invalid)
+		assert.ErrorIs(t, err, ErrUnauthenticated, invalid)
+	}
+
+	assert.False(t, New(nil, nil, "").Enabled())
+}
+
+func TestAuthenticate_JWT(t *testing.T) {
+	a := New(nil, nil, secret)
+	now := time.Unix(1_760_000_000, 0)
+	a.now = func() time.Time { return now }
+
+	client, err := a.Authenticate(signJWT(t, secret, `{"sub":"registrar-b","scope":"openid read","exp":1760000600}`))
+	require.NoError(t, err)
+	assert.Equal(t, Client{Name: "jwt:registrar-b", Scope: ScopeRead}, client)
+
+	client, err = a.Authenticate(signJWT(t, secret, `{"sub":"ops","scope":"read admin","exp":1760000600}`))
+	require.NoError(t, err)
+	assert.Equal(t, ScopeAdmin, client.Scope)
+
+	for name, token := range map[string]string{
+		"wrong secret":   signJWT(t, "another secret", `{"sub":"x","scope":"read","exp":1760000600}`),
+		"expired":        signJWT(t, secret, `{"sub":"x","scope":"read","exp":1760000000}`),
+		"no expiry":      signJWT(t, secret, `{"sub":"x","scope":"read"}`),
+		"not yet valid":  signJWT(t, secret, `{"sub":"x","scope":"read","exp":1760000600,"nbf":1760000300}`),
+		"no subject":     signJWT(t, secret, `{"scope":"read","exp":1760000600}`),
+		"unknown scope":  signJWT(t, secret, `{"sub":"x","scope":"write","exp":1760000600}`),
+		"not signed":     "eyJhbGciOiJub25lIn0.eyJzdWIiOiJ4Iiwic2NvcGUiOiJhZG1pbiIsImV4cCI6MTc2MDAwMDYwMH0.",
+		"not a jwt part": "a.b.c",
+	} {
+		_, err := a.Authenticate(token)
+		assert.ErrorIs(t, err, ErrUnauthenticated, name)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter(60, 2)
+	for range 2 {
+		ok, _ := l.Allow("registrar-a")
+		assert.True(t, ok)
+	}
+	ok, wait := l.Allow("registrar-a")
+	assert.False(t, ok)
+	assert.Greater(t, wait, time.Duration(0))
+	assert.LessOrEqual(t, wait, time.Second)
+
+	// Every client has its own rate
+	ok, _ = l.Allow("registrar-b")
+	assert.True(t, ok)
+
+	unlimited := NewLimiter(0, 0)
+	for range 100 {
+		ok, _ := unlimited.Allow("registrar-a")
+		assert.True(t, ok)
+	}
+}
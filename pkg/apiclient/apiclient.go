@@ -0,0 +1,101 @@
+// Package apiclient is the Go client of the ledger API, generated from its OpenAPI specification, so registrar
+// systems can query the ledger without hand-writing HTTP calls:
+//
+//	client := apiclient.New("https://ledger.example.net", os.Getenv("API_KEY"))
+//	record, err := client.GetDomain(ctx, "example.build")
+//
+// The models and operations in client.gen.go are generated from openapi.yaml by gen.go, run go generate after
+// changing the specification.
+package apiclient
+
+//go:generate go run gen.go
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Spec is the OpenAPI 3 specification of the ledger API, as served at /openapi.yaml
+//
+//go:embed openapi.yaml
+var Spec []byte
+
+// Client calls the ledger API
+type Client struct {
+	BaseURL    string       // URL of the API server, e.g. "http://localhost:8080"
+	Credential string       // API key or JSON Web Token sent as bearer token, none if empty
+	HTTPClient *http.Client // http.DefaultClient if nil
+}
+
+// New returns a client of the API server at baseURL authenticating with the credential, if not empty
+func New(baseURL, credential string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), Credential: credential}
+}
+
+// Error is returned for the error responses of the API
+type Error struct {
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration // Wait before the next request, set by 429 responses
+}
+
+// Error returns the message of the API with the status code
+func (e *Error) Error() string {
+	return fmt.Sprintf("ledger API: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// IsNotFound tells whether an error is a 404 response of the API
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// get sends a GET request of the path and query and decodes the JSON body of a 200 response into out
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.Credential != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Credential)
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &Error{StatusCode: resp.StatusCode}
+		var body ErrorResponse
+		if data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10)); err == nil && json.Unmarshal(data, &body) == nil {
+			apiErr.Message = body.Error
+		}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return apiErr
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("ledger API: invalid response to %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package apiclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestClient_GetDomain(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/domains/example.build", r.URL.Path)
+		assert.Equal(t, "Bearer read-key", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"domain":"example.build","zone":"build","token_id":"0.0.100","serial_number":42,
+			"mint_transaction_id":"0.0.2-1754049600-000000001","consensus_at":"2025-08-01T12:00:03Z",
+			"status":"minted","source":"store"}`))
+	}))
+	defer server.Close()
+
+	record, err := New(server.URL+"/", "read-key").GetDomain(context.Background(), "example.build")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), record.SerialNumber)
+	assert.Equal(t, DomainRecordStatusMinted, record.Status)
+	assert.True(t, record.ConsensusAt.Equal(time.Date(2025, 8, 1, 12, 0, 3, 0, time.UTC)))
+	assert.Nil(t, record.BurnedAt)
+}
+
+func TestClient_GetDomainHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/domains/example.build/history", r.URL.Path)
+		w.Write([]byte(`{"domain":"example.build","zone":"build","nfts":["0.0.100/42"],"entries":[
+			{"at":"2025-08-01T12:00:03Z","kind":"mint","token_id":"0.0.100","serial_number":42,"transaction_id":"0.0.2-1754049600-000000001"},
+			{"at":"2025-08-01T12:00:05Z","kind":"receipt","token_id":"0.0.100","serial_number":42,"topic_id":"0.0.200","sequence_number":7}]}`))
+	}))
+	defer server.Close()
+
+	history, err := New(server.URL, "").GetDomainHistory(context.Background(), "example.build")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0.0.100/42"}, history.NFTs)
+	require.Len(t, history.Entries, 2)
+	assert.Equal(t, DomainHistoryEntryKindMint, history.Entries[0].Kind)
+	assert.Equal(t, int64(7), history.Entries[1].SequenceNumber)
+}
+
+func TestClient_ListZoneDomains(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/zones/build/domains", r.URL.Path)
+		assert.Equal(t, "burned", r.URL.Query().Get("status"))
+		assert.Equal(t, "2025-08-01T00:00:00Z", r.URL.Query().Get("created_after"))
+		assert.Equal(t, "50", r.URL.Query().Get("limit"))
+		assert.False(t, r.URL.Query().Has("cursor"))
+		w.Write([]byte(`{"domains":[],"next":"s.abc","source":"store"}`))
+	}))
+	defer server.Close()
+
+	page, err := New(server.URL, "").ListZoneDomains(context.Background(), "build", ListZoneDomainsParams{
+		Status:       DomainRecordStatusBurned,
+		CreatedAfter: time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC),
+		Limit:        50,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "s.abc", page.Next)
+}
+
+func TestClient_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/zones" {
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":"rate limit exceeded"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"domain not minted"}`))
+	}))
+	defer server.Close()
+	client := New(server.URL, "")
+
+	_, err := client.GetDomain(context.Background(), "missing.build")
+	assert.True(t, IsNotFound(err))
+	assert.ErrorContains(t, err, "domain not minted")
+
+	_, err = client.ListZones(context.Background())
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+	assert.Equal(t, 3*time.Second, apiErr.RetryAfter)
+	assert.False(t, IsNotFound(err))
+}
+
+func TestSpec(t *testing.T) {
+	var spec struct {
+		OpenAPI string                    `yaml:"openapi"`
+		Paths   map[string]map[string]any `yaml:"paths"`
+	}
+	require.NoError(t, yaml.Unmarshal(Spec, &spec))
+	assert.Equal(t, "3.0.3", spec.OpenAPI)
+	for _, path := range []string{"/v1/domains/{name}", "/v1/domains/{name}/history", "/v1/zones", "/v1/zones/{zone}/domains", "/v1/proofs/{domain}"} {
+		assert.Contains(t, spec.Paths, path)
+	}
+}
+
+// TestGenerated fails when client.gen.go was not generated again after a change of openapi.yaml
+func TestGenerated(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the generator")
+	}
+	out := filepath.Join(t.TempDir(), "client.gen.go")
+	cmd := exec.Command("go", "run", "gen.go", "-o", out)
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	generated, err := os.ReadFile(out)
+	require.NoError(t, err)
+	committed, err := os.ReadFile("client.gen.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(generated), string(committed), "run go generate ./pkg/apiclient")
+}
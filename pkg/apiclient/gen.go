@@ -0,0 +1,287 @@
+//go:build ignore
+
+// gen.go generates client.gen.go, the models and operations of the client, from openapi.yaml. It covers the
+// subset of OpenAPI 3 the specification uses: GET operations with path and query parameters, answering JSON
+// objects of the component schemas. Other operations, such as event streams and the pushes of the workers,
+// are left out of the client.
+//
+// Usage: go run gen.go [-spec openapi.yaml] [-o client.gen.go]
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type schema struct {
+	Ref         string             `yaml:"$ref"`
+	Type        string             `yaml:"type"`
+	Format      string             `yaml:"format"`
+	Description string             `yaml:"description"`
+	Required    []string           `yaml:"required"`
+	Properties  map[string]*schema `yaml:"properties"`
+	Items       *schema            `yaml:"items"`
+	Enum        []string           `yaml:"enum"`
+}
+
+type parameter struct {
+	Ref         string  `yaml:"$ref"`
+	Name        string  `yaml:"name"`
+	In          string  `yaml:"in"`
+	Description string  `yaml:"description"`
+	Required    bool    `yaml:"required"`
+	Schema      *schema `yaml:"schema"`
+}
+
+type operation struct {
+	OperationID string      `yaml:"operationId"`
+	Summary     string      `yaml:"summary"`
+	Parameters  []parameter `yaml:"parameters"`
+	Responses   map[string]struct {
+		Content map[string]struct {
+			Schema *schema `yaml:"schema"`
+		} `yaml:"content"`
+	} `yaml:"responses"`
+}
+
+type spec struct {
+	Paths      map[string]map[string]*operation `yaml:"paths"`
+	Components struct {
+		Schemas    map[string]*schema   `yaml:"schemas"`
+		Parameters map[string]parameter `yaml:"parameters"`
+	} `yaml:"components"`
+}
+
+// initialisms are the words of JSON names written in capitals in Go names
+var initialisms = map[string]string{"id": "ID", "url": "URL", "api": "API", "hcs": "HCS", "nft": "NFT", "nfts": "NFTs"}
+
+// goName converts a JSON or operation name (snake or camel case) into an exported Go name
+func goName(name string) string {
+	var b strings.Builder
+	for _, word := range strings.Split(name, "_") {
+		if upper, ok := initialisms[word]; ok {
+			b.WriteString(upper)
+		} else if word != "" {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return b.String()
+}
+
+// refName returns the name of the component schema of a $ref
+func refName(ref string) string {
+	return strings.TrimPrefix(ref, "#/components/schemas/")
+}
+
+// goType returns the Go type of a schema, a pointer for optional objects and times
+func goType(s *schema, required bool) string {
+	optional := ""
+	if !required {
+		optional = "*"
+	}
+	switch {
+	case s.Ref != "":
+		return optional + refName(s.Ref)
+	case s.Type == "array":
+		return "[]" + goType(s.Items, true)
+	case s.Type == "integer" && s.Format == "int64":
+		return "int64"
+	case s.Type == "integer":
+		return "int"
+	case s.Type == "number":
+		return "float64"
+	case s.Type == "boolean":
+		return "bool"
+	case s.Type == "string" && s.Format == "date-time":
+		return optional + "time.Time"
+	case s.Type == "string":
+		return "string"
+	}
+	log.Fatalf("unsupported schema type %q", s.Type)
+	return ""
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// comment writes the description of a schema as the doc comment of its type
+func comment(b *bytes.Buffer, name, description string) {
+	description = strings.Join(strings.Fields(description), " ")
+	if description == "" {
+		return
+	}
+	fmt.Fprintf(b, "// %s is the %s\n", name, strings.ToLower(description[:1])+description[1:])
+}
+
+func main() {
+	specPath := flag.String("spec", "openapi.yaml", "OpenAPI specification")
+	out := flag.String("o", "client.gen.go", "generated file")
+	flag.Parse()
+
+	data, err := os.ReadFile(*specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var s spec
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		log.Fatalf("%s: %v", *specPath, err)
+	}
+
+	var b bytes.Buffer
+	for _, name := range sortedKeys(s.Components.Schemas) {
+		writeModel(&b, name, s.Components.Schemas[name])
+	}
+
+	operations := make(map[string]string) // Operation IDs to paths
+	for _, path := range sortedKeys(s.Paths) {
+		if op := s.Paths[path]["get"]; op != nil && op.Responses["200"].Content["application/json"].Schema != nil {
+			operations[op.OperationID] = path
+		}
+	}
+	for _, id := range sortedKeys(operations) {
+		writeOperation(&b, operations[id], s.Paths[operations[id]]["get"], s.Components.Parameters)
+	}
+
+	// Import the packages the models and operations use
+	var header bytes.Buffer
+	fmt.Fprintf(&header, "// Code generated by gen.go from %s. DO NOT EDIT.\n\npackage apiclient\n\nimport (\n", *specPath)
+	for _, pkg := range []string{"context", "net/url", "strconv", "time"} {
+		if bytes.Contains(b.Bytes(), []byte(pkg[strings.LastIndex(pkg, "/")+1:]+".")) {
+			fmt.Fprintf(&header, "%q\n", pkg)
+		}
+	}
+	header.WriteString(")\n\n")
+
+	src, err := format.Source(append(header.Bytes(), b.Bytes()...))
+	if err != nil {
+		log.Fatalf("generated code does not compile: %v\n%s", err, b.String())
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// writeModel writes the struct of an object schema and the constants of the enums of its properties
+func writeModel(b *bytes.Buffer, name string, s *schema) {
+	if s.Type != "object" {
+		log.Fatalf("schema %s: only object schemas are supported", name)
+	}
+	comment(b, name, s.Description)
+	fmt.Fprintf(b, "type %s struct {\n", name)
+	for _, prop := range sortedKeys(s.Properties) {
+		p := s.Properties[prop]
+		required := slices.Contains(s.Required, prop)
+		tag := prop
+		if !required {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(b, "%s %s `json:%q`", goName(prop), goType(p, required), tag)
+		if p.Description != "" {
+			fmt.Fprintf(b, " // %s", p.Description)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("}\n\n")
+
+	for _, prop := range sortedKeys(s.Properties) {
+		if enum := s.Properties[prop].Enum; len(enum) > 0 {
+			fmt.Fprintf(b, "// Values of %s.%s\nconst (\n", name, goName(prop))
+			for _, value := range enum {
+				fmt.Fprintf(b, "%s%s%s = %q\n", name, goName(prop), goName(value), value)
+			}
+			b.WriteString(")\n\n")
+		}
+	}
+}
+
+// writeOperation writes the method of an operation, and the struct of its query parameters if it has any.
+// Parameters may refer to the component parameters.
+func writeOperation(b *bytes.Buffer, path string, op *operation, components map[string]parameter) {
+	method := goName(strings.ToUpper(op.OperationID[:1]) + op.OperationID[1:])
+	response := op.Responses["200"].Content["application/json"].Schema
+	if response == nil || response.Ref == "" {
+		log.Fatalf("%s: the 200 response must be a JSON component schema", op.OperationID)
+	}
+	result := refName(response.Ref)
+
+	var pathParams, queryParams []parameter
+	for _, p := range op.Parameters {
+		if p.Ref != "" {
+			var ok bool
+			if p, ok = components[strings.TrimPrefix(p.Ref, "#/components/parameters/")]; !ok {
+				log.Fatalf("%s: unknown parameter %s", op.OperationID, p.Ref)
+			}
+		}
+		switch p.In {
+		case "path":
+			pathParams = append(pathParams, p)
+		case "query":
+			queryParams = append(queryParams, p)
+		default:
+			log.Fatalf("%s: %s parameters are not supported", op.OperationID, p.In)
+		}
+	}
+
+	args := []string{"ctx context.Context"}
+	for _, p := range pathParams {
+		args = append(args, p.Name+" string")
+	}
+	if len(queryParams) > 0 {
+		fmt.Fprintf(b, "// %sParams are the query parameters of %s, zero values are not sent\n", method, method)
+		fmt.Fprintf(b, "type %sParams struct {\n", method)
+		for _, p := range queryParams {
+			fmt.Fprintf(b, "%s %s", goName(p.Name), goType(p.Schema, true))
+			if p.Description != "" {
+				fmt.Fprintf(b, " // %s", p.Description)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("}\n\n")
+		args = append(args, "params "+method+"Params")
+	}
+
+	fmt.Fprintf(b, "// %s sends GET %s: %s\n", method, path, op.Summary)
+	fmt.Fprintf(b, "func (c *Client) %s(%s) (%s, error) {\n", method, strings.Join(args, ", "), result)
+	goPath := fmt.Sprintf("%q", path)
+	for _, p := range pathParams {
+		goPath = strings.Replace(goPath, "{"+p.Name+"}", `" + url.PathEscape(`+p.Name+`) + "`, 1)
+	}
+	goPath = strings.TrimSuffix(goPath, ` + ""`)
+	fmt.Fprintf(b, "path := %s\n", goPath)
+	if len(queryParams) == 0 {
+		fmt.Fprintf(b, "var result %s\nerr := c.get(ctx, path, nil, &result)\nreturn result, err\n}\n\n", result)
+		return
+	}
+	b.WriteString("query := url.Values{}\n")
+	for _, p := range queryParams {
+		field := "params." + goName(p.Name)
+		switch goType(p.Schema, true) {
+		case "string":
+			fmt.Fprintf(b, "if %s != \"\" {\nquery.Set(%q, %s)\n}\n", field, p.Name, field)
+		case "int":
+			fmt.Fprintf(b, "if %s != 0 {\nquery.Set(%q, strconv.Itoa(%s))\n}\n", field, p.Name, field)
+		case "int64":
+			fmt.Fprintf(b, "if %s != 0 {\nquery.Set(%q, strconv.FormatInt(%s, 10))\n}\n", field, p.Name, field)
+		case "time.Time":
+			fmt.Fprintf(b, "if !%s.IsZero() {\nquery.Set(%q, %s.Format(time.RFC3339Nano))\n}\n", field, p.Name, field)
+		default:
+			log.Fatalf("%s: query parameter %s has an unsupported type", op.OperationID, p.Name)
+		}
+	}
+	fmt.Fprintf(b, "var result %s\nerr := c.get(ctx, path, query, &result)\nreturn result, err\n}\n\n", result)
+}
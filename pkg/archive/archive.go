@@ -0,0 +1,180 @@
+// Package archive lists and reads the archived event log files of a registry, kept in a local directory or
+// in an S3 compatible bucket. Files are dated by their name, e.g. events-2026-03-01.log or 20260301.log,
+// so a date range of them can be replayed in chronological order.
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/compressed"
+)
+
+// ErrUndated is returned for files whose name carries no date
+var ErrUndated = errors.New("no date in file name")
+
+// File is an archived log file
+type File struct {
+	Name string    `json:"name"` // Path relative to the archive
+	Date time.Time `json:"date"` // Day of the file, from its name
+	Size int64     `json:"size"`
+}
+
+// Source is an archive of log files
+type Source interface {
+	// List returns the files of the archive, in no particular order
+	List(ctx context.Context) ([]File, error)
+	// Open returns the content of a file of the archive
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+	// String returns the location of the archive
+	String() string
+}
+
+// datePattern finds a date in a file name: 2026-03-01, 2026_03_01 or 20260301
+var datePattern = regexp.MustCompile(`(\d{4})[-_]?(\d{2})[-_]?(\d{2})`)
+
+// FileDate returns the date in the name of a file, the first valid one if there are several
+func FileDate(name string) (time.Time, error) {
+	for _, match := range datePattern.FindAllStringSubmatch(filepath.Base(name), -1) {
+		date, err := time.Parse("20060102", match[1]+match[2]+match[3])
+		if err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: %s", ErrUndated, name)
+}
+
+// InRange returns the files dated from one day to another, both included, in chronological order.
+// Files of the same day are ordered by name.
+func InRange(files []File, from, to time.Time) []File {
+	from, to = day(from), day(to)
+	var selected []File
+	for _, f := range files {
+		if !f.Date.Before(from) && !f.Date.After(to) {
+			selected = append(selected, f)
+		}
+	}
+	Chronological(selected)
+	return selected
+}
+
+// Chronological orders files by date, then files of the same day by name. Undated files come last, by name.
+func Chronological(files []File) {
+	sort.SliceStable(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+		if a.Date.IsZero() != b.Date.IsZero() {
+			return b.Date.IsZero()
+		}
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		return a.Name < b.Name
+	})
+}
+
+// LogFiles returns the event log files of a directory, in chronological order: the files named *.log, also
+// compressed, e.g. events-2026-03-01.log.gz. Hidden files and subdirectories are skipped, and files whose name
+// carries no date have a zero Date.
+func LogFiles(dir string) ([]File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []File
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.EqualFold(filepath.Ext(compressed.TrimExt(name)), ".log") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		date, _ := FileDate(name)
+		files = append(files, File{Name: name, Date: date, Size: info.Size()})
+	}
+	Chronological(files)
+	return files, nil
+}
+
+// day truncates a time to the start of its day, in UTC
+func day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// New returns the source of an archive location: s3://bucket/prefix or gs://bucket/prefix for a bucket,
+// otherwise a local directory
+func New(location string, s3 S3Options) (Source, error) {
+	if IsBucket(location) {
+		return NewBucket(location, s3)
+	}
+	info, err := os.Stat(location)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("archive %s is not a directory", location)
+	}
+	return Dir(location), nil
+}
+
+// Dir is an archive in a local directory, including its subdirectories
+type Dir string
+
+// List returns the dated files of the directory tree, skipping hidden files
+func (d Dir) List(ctx context.Context) ([]File, error) {
+	var files []File
+	err := filepath.WalkDir(string(d), func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(entry.Name(), ".") && path != string(d) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			return ctx.Err()
+		}
+		date, err := FileDate(entry.Name())
+		if err != nil {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(string(d), path)
+		if err != nil {
+			return err
+		}
+		files = append(files, File{Name: filepath.ToSlash(name), Date: date, Size: info.Size()})
+		return nil
+	})
+	return files, err
+}
+
+// Open opens a file of the directory
+func (d Dir) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	return os.Open(d.Path(name))
+}
+
+// Path returns the local path of a file of the directory
+func (d Dir) Path(name string) string {
+	return filepath.Join(string(d), filepath.FromSlash(name))
+}
+
+func (d Dir) String() string { return string(d) }
@@ -0,0 +1,301 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// emptyPayloadHash is the SHA-256 of an empty body, the payload hash of the requests reading the archive
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// GCSEndpoint is the S3 compatible XML API of Google Cloud Storage, for gs:// buckets
+const GCSEndpoint = "https://storage.googleapis.com"
+
+// S3Options are the settings of the S3 compatible service of bucket archives
+type S3Options struct {
+	Endpoint        string // Base URL of the service, default https://s3.<region>.amazonaws.com
+	Region          string
+	AccessKeyID     string // Requests are not signed without keys, for public buckets
+	SecretAccessKey string
+	HTTPClient      *http.Client
+}
+
+// S3 is an archive in a bucket of an S3 compatible object storage, read with path-style requests
+type S3 struct {
+	Bucket  string
+	Prefix  string // Only the objects under this prefix are part of the archive
+	options S3Options
+	scheme  string // Of the location of the bucket, s3 or gs
+	now     func() time.Time
+}
+
+// NewS3 returns the archive of the objects of a bucket under a prefix
+func NewS3(bucket, prefix string, options S3Options) *S3 {
+	if options.Region == "" {
+		options.Region = "us-east-1"
+	}
+	if options.Endpoint == "" {
+		options.Endpoint = "https://s3." + options.Region + ".amazonaws.com"
+	}
+	options.Endpoint = strings.TrimSuffix(options.Endpoint, "/")
+	if options.HTTPClient == nil {
+		options.HTTPClient = http.DefaultClient
+	}
+	return &S3{Bucket: bucket, Prefix: prefix, options: options, scheme: "s3", now: time.Now}
+}
+
+// IsBucket reports whether a location is a bucket, s3://bucket/prefix or gs://bucket/prefix
+func IsBucket(location string) bool {
+	return strings.HasPrefix(location, "s3://") || strings.HasPrefix(location, "gs://")
+}
+
+// NewBucket returns the bucket of a location, s3://bucket/prefix or gs://bucket/prefix. Google Cloud Storage
+// buckets are accessed through the XML API of GCSEndpoint, with HMAC keys, unless options name an endpoint.
+func NewBucket(location string, options S3Options) (*S3, error) {
+	scheme, rest, _ := strings.Cut(location, "://")
+	switch scheme {
+	case "s3":
+	case "gs":
+		if options.Endpoint == "" {
+			options.Endpoint, options.Region = GCSEndpoint, "auto"
+		}
+	default:
+		return nil, fmt.Errorf("invalid bucket %q: expected s3://bucket/prefix or gs://bucket/prefix", location)
+	}
+	bucket, prefix, _ := strings.Cut(rest, "/")
+	if bucket == "" {
+		return nil, fmt.Errorf("invalid bucket %q: no bucket", location)
+	}
+	s := NewS3(bucket, prefix, options)
+	s.scheme = scheme
+	return s, nil
+}
+
+func (s *S3) String() string {
+	if s.Prefix == "" {
+		return s.scheme + "://" + s.Bucket
+	}
+	return s.scheme + "://" + s.Bucket + "/" + s.Prefix
+}
+
+// listBucketResult is the response of ListObjectsV2
+type listBucketResult struct {
+	Contents []struct {
+		Key  string `xml:"Key"`
+		Size int64  `xml:"Size"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List returns the dated objects under the prefix, following the pages of the listing
+func (s *S3) List(ctx context.Context) ([]File, error) {
+	var files []File
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}}
+		if s.Prefix != "" {
+			query.Set("prefix", s.Prefix)
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := s.get(ctx, "", query)
+		if err != nil {
+			return nil, err
+		}
+		var page listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("invalid listing of %s: %w", s, err)
+		}
+		for _, object := range page.Contents {
+			if strings.HasSuffix(object.Key, "/") {
+				continue
+			}
+			date, err := FileDate(path.Base(object.Key))
+			if err != nil {
+				continue
+			}
+			name := strings.TrimPrefix(strings.TrimPrefix(object.Key, s.Prefix), "/")
+			files = append(files, File{Name: name, Date: date, Size: object.Size})
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return files, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// Open downloads an object of the archive
+func (s *S3) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	resp, err := s.get(ctx, s.key(name), nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// key returns the object key of a file of the archive
+func (s *S3) key(name string) string {
+	if s.Prefix == "" || strings.HasSuffix(s.Prefix, "/") {
+		return s.Prefix + name
+	}
+	return s.Prefix + "/" + name
+}
+
+// get sends a signed GET request for an object of the bucket, or for the bucket itself with an empty key
+func (s *S3) get(ctx context.Context, key string, query url.Values) (*http.Response, error) {
+	target := s.options.Endpoint + "/" + awsEscape(s.Bucket, false)
+	if key != "" {
+		target += "/" + awsEscape(key, false)
+	}
+	if len(query) > 0 {
+		target += "?" + canonicalQuery(query)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.options.AccessKeyID != "" {
+		signV4(req, emptyPayloadHash, s.options.AccessKeyID, s.options.SecretAccessKey, s.options.Region, s.now())
+	}
+	resp, err := s.options.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("GET %s: %s: %s", req.URL.Redacted(), resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// Put uploads a file to the bucket under the prefix, replacing the object of the same name
+func (s *S3) Put(ctx context.Context, name string, content []byte, contentType string) error {
+	target := s.options.Endpoint + "/" + awsEscape(s.Bucket, false) + "/" + awsEscape(s.key(name), false)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if s.options.AccessKeyID != "" {
+		sum := sha256.Sum256(content)
+		signV4(req, hex.EncodeToString(sum[:]), s.options.AccessKeyID, s.options.SecretAccessKey, s.options.Region, s.now())
+	}
+	resp, err := s.options.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("PUT %s: %s: %s", req.URL.Redacted(), resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// signV4 signs a request with AWS Signature Version 4, for the s3 service, given the SHA-256 of its body
+func signV4(req *http.Request, payloadHash, accessKeyID, secretAccessKey, region string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalPath := req.URL.EscapedPath()
+	if canonicalPath == "" {
+		canonicalPath = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes a query string the way AWS signs it: sorted, with RFC 3986 escaping
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, awsEscape(k, true)+"="+awsEscape(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsEscape percent-encodes everything but the unreserved characters of RFC 3986, and "/" unless escapeSlash
+func awsEscape(s string, escapeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !escapeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
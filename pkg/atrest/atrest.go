@@ -0,0 +1,105 @@
+// Package atrest encrypts the local registry files at rest with AES-256-GCM. A sealed file is a single line,
+// sdl-sealed-v1:<key ID>:<base64 nonce and ciphertext>, so it can tell which key it needs and is never mistaken
+// for the JSON of a file written before encryption was enabled.
+package atrest
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// KeySize is the size of an encryption key, in bytes
+const KeySize = 32
+
+// prefix starts every sealed file
+const prefix = "sdl-sealed-v1:"
+
+// ErrWrongKey is returned by Open for files sealed with another key
+var ErrWrongKey = errors.New("sealed with another key")
+
+// Key is an encryption key
+type Key struct {
+	aead cipher.AEAD
+	id   string
+}
+
+// ParseKey parses a hex encoded key of KeySize bytes, e.g. from "openssl rand -hex 32"
+func ParseKey(s string) (*Key, error) {
+	secret, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, errors.New("invalid encryption key: not hex encoded")
+	}
+	return NewKey(secret)
+}
+
+// NewKey returns the key of KeySize raw bytes
+func NewKey(secret []byte) (*Key, error) {
+	if len(secret) != KeySize {
+		return nil, fmt.Errorf("invalid encryption key: %d bytes, %d required", len(secret), KeySize)
+	}
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// The key ID tells which key a file was sealed with, so keys can be rotated
+	sum := sha256.Sum256(secret)
+	return &Key{aead: aead, id: hex.EncodeToString(sum[:4])}, nil
+}
+
+// ID returns the identifier of the key, derived from it
+func (k *Key) ID() string {
+	return k.id
+}
+
+// Sealed reports whether data is the content of a sealed file
+func Sealed(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(prefix))
+}
+
+// Seal encrypts the content of a file under a random nonce. The header, with the key ID, is authenticated too.
+func (k *Key) Seal(plaintext []byte) ([]byte, error) {
+	header := prefix + k.id + ":"
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := k.aead.Seal(nonce, nonce, plaintext, []byte(header))
+	return []byte(header + base64.StdEncoding.EncodeToString(sealed) + "\n"), nil
+}
+
+// Open decrypts the content of a sealed file. Files sealed with another key fail with an error wrapping
+// ErrWrongKey, and files modified since they were sealed fail to authenticate.
+func (k *Key) Open(data []byte) ([]byte, error) {
+	if !Sealed(data) {
+		return nil, errors.New("not a sealed file")
+	}
+	id, encoded, ok := strings.Cut(strings.TrimSpace(string(data[len(prefix):])), ":")
+	if !ok {
+		return nil, errors.New("invalid sealed file: missing key ID")
+	}
+	if id != k.id {
+		return nil, fmt.Errorf("%w %s, not %s", ErrWrongKey, id, k.id)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < k.aead.NonceSize() {
+		return nil, errors.New("invalid sealed file: not base64 encoded")
+	}
+	nonce, ciphertext := sealed[:k.aead.NonceSize()], sealed[k.aead.NonceSize():]
+	plaintext, err := k.aead.Open(nil, nonce, ciphertext, []byte(prefix+id+":"))
+	if err != nil {
+		return nil, errors.New("sealed file does not authenticate: modified or corrupted")
+	}
+	return plaintext, nil
+}
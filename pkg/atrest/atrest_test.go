@@ -0,0 +1,101 @@
+package atrest
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+func TestKey_SealOpen(t *testing.T) {
+	key, err := ParseKey(testKey)
+	require.NoError(t, err)
+	plaintext := []byte(`{"collections":{}}`)
+
+	sealed, err := key.Seal(plaintext)
+	require.NoError(t, err)
+	assert.True(t, Sealed(sealed))
+	assert.False(t, Sealed(plaintext))
+	assert.True(t, strings.HasPrefix(string(sealed), "sdl-sealed-v1:"+key.ID()+":"))
+	assert.NotContains(t, string(sealed), "collections")
+	// A random nonce every time
+	again, err := key.Seal(plaintext)
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again)
+
+	opened, err := key.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+
+	_, err = key.Open(plaintext)
+	assert.Error(t, err)
+
+	// Modified
+	tampered := bytes.Clone(sealed)
+	i := len("sdl-sealed-v1:"+key.ID()+":") + 20
+	if tampered[i] == 'A' {
+		tampered[i] = 'B'
+	} else {
+		tampered[i] = 'A'
+	}
+	_, err = key.Open(tampered)
+	assert.ErrorContains(t, err, "does not authenticate")
+
+	other, err := ParseKey(strings.Repeat("ab", KeySize))
+	require.NoError(t, err)
+	_, err = other.Open(sealed)
+	assert.ErrorIs(t, err, ErrWrongKey)
+}
+
+func TestParseKey_Invalid(t *testing.T) {
+	_, err := ParseKey("not hex")
+	assert.ErrorContains(t, err, "not hex")
+	_, err = ParseKey("0011")
+	assert.ErrorContains(t, err, "32 required")
+}
+
+func TestKMS_DecryptKey(t *testing.T) {
+	secret := bytes.Repeat([]byte{7}, KeySize)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "TrentService.Decrypt", r.Header.Get("X-Amz-Target"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"),
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20250801/eu-west-1/kms/aws4_request, SignedHeaders=content-type;host;x-amz-date;x-amz-target, Signature="))
+		var request struct{ CiphertextBlob []byte }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		if string(request.CiphertextBlob) != "encrypted" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"InvalidCiphertextException"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(struct{ Plaintext []byte }{secret})
+	}))
+	defer server.Close()
+
+	kms := KMS{
+		Region:          "eu-west-1",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "secret",
+		Endpoint:        server.URL,
+		now:             func() time.Time { return time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC) },
+	}
+	key, err := kms.DecryptKey(context.Background(), base64.StdEncoding.EncodeToString([]byte("encrypted")))
+	require.NoError(t, err)
+	expected, err := NewKey(secret)
+	require.NoError(t, err)
+	assert.Equal(t, expected.ID(), key.ID())
+
+	_, err = kms.DecryptKey(context.Background(), base64.StdEncoding.EncodeToString([]byte("other")))
+	assert.ErrorContains(t, err, "InvalidCiphertextException")
+	_, err = kms.DecryptKey(context.Background(), "not base64!")
+	assert.ErrorContains(t, err, "not base64")
+}
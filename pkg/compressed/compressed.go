@@ -0,0 +1,86 @@
+// Package compressed opens input files that may be compressed, as registries ship their logs gzip, zstd or
+// bzip2 compressed. The compression is chosen by the file extension and the content is decompressed while it
+// is read, so a compressed file is never held in memory or on disk uncompressed.
+package compressed
+
+import (
+	"bufio"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Extensions of the supported compressions
+const (
+	ExtGzip  = ".gz"
+	ExtZstd  = ".zst"
+	ExtBzip2 = ".bz2"
+)
+
+// IsCompressed reports whether a file is decompressed when it is opened, based on its extension
+func IsCompressed(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ExtGzip, ExtZstd, ExtBzip2:
+		return true
+	}
+	return false
+}
+
+// TrimExt returns a path without its compression extension, e.g. list.csv for list.csv.gz,
+// so the format of the content can be told from the rest of the name
+func TrimExt(path string) string {
+	if IsCompressed(path) {
+		return strings.TrimSuffix(path, filepath.Ext(path))
+	}
+	return path
+}
+
+// Open opens a file for reading, decompressing it if its extension is .gz, .zst or .bz2
+func Open(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReader(file, path)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// NewReader returns a reader of the decompressed content of r, read from a file with the given name.
+// Closing the reader closes r.
+func NewReader(r io.ReadCloser, name string) (io.ReadCloser, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ExtGzip:
+		gz, err := gzip.NewReader(bufio.NewReader(r))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip file %s: %w", name, err)
+		}
+		return readCloser{gz, func() error { gz.Close(); return r.Close() }}, nil
+	case ExtZstd:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid zstd file %s: %w", name, err)
+		}
+		return readCloser{zr, func() error { zr.Close(); return r.Close() }}, nil
+	case ExtBzip2:
+		return readCloser{bzip2.NewReader(bufio.NewReader(r)), r.Close}, nil
+	}
+	return r, nil
+}
+
+// readCloser reads from a decompressor and closes it and the underlying file
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (r readCloser) Close() error { return r.close() }
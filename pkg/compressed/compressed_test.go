@@ -0,0 +1,81 @@
+package compressed
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const content = "line one\nline two\n"
+
+// bzip2Content is content compressed with bzip2, which the standard library can only decompress
+const bzip2Content = "QlpoOTFBWSZTWYx3v94AAATRgAAQQAACJYSAIAAxBkxAyGmmjwssIJicJ4u5IpwoSEY73+8A"
+
+func gzipped(t *testing.T) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := io.WriteString(w, content)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func zstdCompressed(t *testing.T) []byte {
+	var buf bytes.Buffer
+	w, err := zstd.NewWriter(&buf)
+	require.NoError(t, err)
+	_, err = io.WriteString(w, content)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestOpen(t *testing.T) {
+	bz2, err := base64.StdEncoding.DecodeString(bzip2Content)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	for name, data := range map[string][]byte{
+		"events.log":     []byte(content),
+		"events.log.gz":  gzipped(t),
+		"events.log.GZ":  gzipped(t),
+		"events.log.zst": zstdCompressed(t),
+		"events.log.bz2": bz2,
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, data, 0644))
+
+		r, err := Open(path)
+		require.NoError(t, err, name)
+		got, err := io.ReadAll(r)
+		require.NoError(t, err, name)
+		require.NoError(t, r.Close(), name)
+		assert.Equal(t, content, string(got), name)
+	}
+}
+
+func TestOpen_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log.gz")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	_, err := Open(path)
+	assert.ErrorContains(t, err, "invalid gzip file")
+
+	_, err = Open(filepath.Join(t.TempDir(), "missing.log.zst"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestTrimExt(t *testing.T) {
+	assert.Equal(t, "list.csv", TrimExt("list.csv.gz"))
+	assert.Equal(t, "logs/events.log", TrimExt("logs/events.log.zst"))
+	assert.Equal(t, "list.csv", TrimExt("list.csv"))
+	assert.True(t, IsCompressed("events.BZ2"))
+	assert.False(t, IsCompressed("events.tar"))
+}
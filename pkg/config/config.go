@@ -0,0 +1,1363 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/archive"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/atrest"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/entityid"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/incident"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/naming"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/redact"
+)
+
+const (
+	DefaultNetwork            = "testnet"
+	DefaultZoneRegistryFile   = "zone_collections.json"
+	DefaultTopicRegistryFile  = "hcs_topics.json"
+	DefaultIngestLedgerFile   = "ingested_files.json"
+	DefaultAccountFile        = "account_mappings.json"
+	DefaultTopicOffsetFile    = "hcs_offsets.json"
+	DefaultReportDir          = "reports"
+	DefaultAnchorDir          = "anchors"
+	DefaultSnapshotDir        = "snapshots"
+	DefaultQuarantineDir      = "quarantine"
+	DefaultTransactionDir     = "transactions"
+	DefaultSigningDir         = "signing"
+	DefaultSigningDelay       = 10 * time.Minute
+	DefaultArchiveStagingDir  = "archive"
+	DefaultArchiveS3Region    = "us-east-1"
+	DefaultIntakeListenAddr   = ":8081"
+	DefaultIntakeSpoolDir     = "intake"
+	DefaultIntakeMaxEvents    = 1000
+	DefaultAPIRateLimit       = 600
+	DefaultAPIRateBurst       = 20
+	DefaultMaxMintsPerRun     = 50000
+	DefaultFailureThreshold   = 10
+	DefaultSMTPPort           = 587
+	DefaultSESRegion          = "us-east-1"
+	DefaultKMSRegion          = "us-east-1"
+	DefaultEscalationAfter    = 5
+	DefaultTyposquatThreshold = 0.8
+	DefaultTemporalAddress    = "localhost:7233"
+	DefaultTemporalNamespace  = "default"
+	DefaultTaskQueue          = "DOMAIN_INGEST_TASK_QUEUE"
+	DefaultWorkerStopTimeout  = 30 * time.Second
+	DefaultArweaveGateway     = "https://arweave.net"
+	DefaultIPFSPinTimeout     = time.Minute
+	DefaultAssociationTimeout = 24 * time.Hour
+	DefaultMirrorAPIKeyHeader = "x-api-key"
+	DefaultPinataURL          = "https://api.pinata.cloud"
+	DefaultWeb3StorageURL     = "https://api.web3.storage"
+	DefaultKuboURL            = "http://localhost:5001"
+	DefaultMetadataTopic      = "metadata-documents"
+)
+
+// Signature modes of registry events
+const (
+	SignaturesOff    = "off"    // Signatures are ignored
+	SignaturesVerify = "verify" // Signed events must verify, unsigned events are accepted
+	SignaturesStrict = "strict" // Only events with a valid signature are minted
+)
+
+// Signing modes of the mint and collection creation transactions
+const (
+	SigningOnline  = "online"  // Transactions are signed with HEDERA_PRIVATE_KEY and submitted by the workers
+	SigningOffline = "offline" // Transactions are exported to SIGNING_DIR, signed elsewhere and submitted once signed
+)
+
+// Integrity checks of the registry files, signed with the operator key
+const (
+	IntegrityOff     = "off"     // The registry files are neither signed nor checked
+	IntegrityWarn    = "warn"    // The files are signed on save, a file failing its check on load is reported and used
+	IntegrityEnforce = "enforce" // The files are signed on save, a file failing its check on load is refused
+)
+
+// Handling of events declaring a schema version no decoder is registered for
+const (
+	UnknownSchemaReject     = "reject"     // The event is dropped and logged, like a malformed line
+	UnknownSchemaQuarantine = "quarantine" // The event is kept in QUARANTINE_DIR to be reprocessed later
+)
+
+// Sources of the nameservers recorded with a registration
+const (
+	NameserversOff   = "off"   // Nameservers are not recorded
+	NameserversEvent = "event" // Nameservers listed in the registry event (ns)
+	NameserversDNS   = "dns"   // Nameservers of the event, or looked up in the DNS when the event lists none
+)
+
+// Metadata stores
+const (
+	MetadataStoreArweave = "arweave" // Permanent storage on Arweave, paid from ARWEAVE_WALLET_FILE
+	MetadataStoreIPFS    = "ipfs"    // IPFS, pinned with every pinner of IPFS_PINNERS
+	MetadataStoreHCS     = "hcs"     // Chunked messages of the HCS topic METADATA_TOPIC, paid by the operator
+)
+
+// Policies for transfers to accounts not associated with the token
+const (
+	AssociationWait = "wait" // Wait for the account holder to associate the token
+	AssociationAuto = "auto" // Associate accounts controlled by the operator key, wait for the others
+	AssociationFail = "fail" // Fail the transfer with instructions for the account holder
+)
+
+// Services emailing reports
+const (
+	EmailSMTP = "smtp" // An SMTP server, SMTP_HOST
+	EmailSES  = "ses"  // Amazon SES, with the SES v2 API of SES_REGION
+)
+
+// IPFS pinners
+const (
+	PinnerPinata      = "pinata"      // Pinata, receives the documents
+	PinnerWeb3Storage = "web3storage" // web3.storage (Pinning Service API), fetches the documents from the network
+	PinnerKubo        = "kubo"        // Self-hosted Kubo node, receives the documents
+)
+
+var (
+	ErrMissingOperator = errors.New("missing Hedera operator credentials: set HEDERA_ACCOUNT_ID and HEDERA_PRIVATE_KEY")
+)
+
+// mirrorNodeURLs maps the public Hedera networks to their mirror node REST API base URLs
+var mirrorNodeURLs = map[string]string{
+	"mainnet":    "https://mainnet-public.mirrornode.hedera.com/api/v1",
+	"testnet":    "https://testnet.mirrornode.hedera.com/api/v1",
+	"previewnet": "https://previewnet.mirrornode.hedera.com/api/v1",
+	"local":      "http://localhost:5551/api/v1",
+}
+
+// Config holds all runtime settings of the Shadow Domain Ledger.
+// It is loaded once at startup and injected into the components that need it.
+type Config struct {
+	Hedera     HederaConfig
+	Mirror     MirrorConfig
+	Registry   RegistryConfig
+	Limits     LimitsConfig
+	Temporal   TemporalConfig
+	Reports    ReportsConfig
+	HCS        HCSConfig
+	Events     EventsConfig
+	Metadata   MetadataConfig
+	Claims     ClaimsConfig
+	Transfers  TransfersConfig
+	Archive    ArchiveConfig
+	Intake     IntakeConfig
+	API        APIConfig
+	Notify     NotifyConfig
+	Email      EmailConfig
+	Escalation EscalationConfig
+	Encryption EncryptionConfig
+	Zones      ZonesConfig
+
+	Profile      string                       // Name of the config file profile applied, if any
+	DefaultFlags map[string]map[string]string // Default CLI flag values of the profile, by command
+}
+
+// HederaConfig holds the Hedera network and operator credentials
+type HederaConfig struct {
+	Network    string // HEDERA_NETWORK: mainnet, testnet, previewnet or local
+	AccountID  string // HEDERA_ACCOUNT_ID: operator account paying for transactions
+	PrivateKey string // HEDERA_PRIVATE_KEY: operator key, also used as supply/admin key
+	PublicKey  string // HEDERA_PUBLIC_KEY: public key of the operator key, given instead of it when signing offline
+	Signing    string // HEDERA_SIGNING: online or offline, where mints and collection creations are signed
+
+	// HEDERA_SIGNING_DELAY: how long after their export offline transactions become valid, they must be signed
+	// within it and are submitted once it elapsed
+	SigningDelay time.Duration
+
+	AdminKey           string   // HEDERA_ADMIN_KEY: public key of the external signer, admin and wipe key of new collections
+	AdminSignerURL     string   // HEDERA_ADMIN_SIGNER_URL: remote signing daemon holding the admin key
+	AdminSignerToken   string   // HEDERA_ADMIN_SIGNER_TOKEN: bearer token of the signing daemon
+	AdminSignerCommand []string // HEDERA_ADMIN_SIGNER_COMMAND: program signing with the admin key, e.g. on a Ledger device
+}
+
+// AdminSigner reports whether administrative transactions are signed by an external signer
+func (c HederaConfig) AdminSigner() bool {
+	return c.AdminSignerURL != "" || len(c.AdminSignerCommand) > 0
+}
+
+// MirrorConfig holds the mirror node settings
+type MirrorConfig struct {
+	// MIRROR_NODE_URL: REST API base URL, defaults to MIRROR_NODE_URL_<NETWORK> (e.g. MIRROR_NODE_URL_MAINNET),
+	// then to the public mirror node of the network
+	BaseURL      string
+	GRPCAddress  string            // MIRROR_NODE_GRPC: host:port of the gRPC API used for topic subscriptions, defaults to the public mirror node
+	APIKey       string            // MIRROR_NODE_API_KEY: sent with every REST request, for commercial and self-hosted mirror nodes
+	APIKeyHeader string            // MIRROR_NODE_API_KEY_HEADER: header carrying the API key
+	Headers      map[string]string // MIRROR_NODE_HEADERS: extra headers of every REST request, as "Name: value" pairs separated by commas
+
+	// MIRROR_VISIBILITY_TIMEOUT: how long minted NFTs may take to show on the mirror node, not checked if zero
+	VisibilityTimeout time.Duration
+}
+
+// Header returns the headers sent with every mirror node REST request
+func (m MirrorConfig) Header() http.Header {
+	header := make(http.Header)
+	for name, value := range m.Headers {
+		header.Set(name, value)
+	}
+	if m.APIKey != "" {
+		header.Set(m.APIKeyHeader, m.APIKey)
+	}
+	return header
+}
+
+// RegistryConfig holds the locations of the local registry files
+type RegistryConfig struct {
+	ZoneFile         string // ZONE_REGISTRY_FILE
+	TopicFile        string // TOPIC_REGISTRY_FILE
+	IngestLedgerFile string // INGEST_LEDGER_FILE: ledger of ingested files
+	AccountFile      string // ACCOUNT_REGISTRY_FILE: mappings of registrars and registrants to Hedera accounts
+	TopicOffsetFile  string // TOPIC_OFFSETS_FILE: last message processed of every topic, per consumer group
+	StoreDSN         string // REGISTRY_STORE_DSN: PostgreSQL database the mints and burns are recorded in, if set
+	AnchorDir        string // ANCHOR_DIR: directory the Merkle trees of anchored batches are stored in
+	SnapshotDir      string // SNAPSHOT_DIR: directory the point-in-time snapshots of the ledger are written to
+	QuarantineDir    string // QUARANTINE_DIR: directory events that could not be processed are kept in
+	TransactionDir   string // TRANSACTION_RECORD_DIR: directory the records of collection creations, mints and burns are kept in
+	SigningDir       string // SIGNING_DIR: directory offline transactions are exported to and picked up from once signed
+	Integrity        string // REGISTRY_INTEGRITY: off, warn or enforce, whether the zone and topic registry files are signed
+
+	// TENANT: registry this deployment runs the ledger of, when it runs them for several. Like HEDERA_NETWORK,
+	// it scopes the registry files and directories, the store schema, the task queue and the workflow IDs, see
+	// scopeRegistry.
+	Tenant      string
+	StoreSchema string // PostgreSQL schema of the registry store tables, set from HEDERA_NETWORK and TENANT
+
+	// Registry files and directories by where they were before scopeRegistry moved them, see CheckUnscoped
+	unscoped map[string]string
+}
+
+// LimitsConfig holds rate limits and safety caps. A value of 0 disables the limit.
+type LimitsConfig struct {
+	TransactionsPerSecond   float64 // HEDERA_TPS: max Hedera transactions per second per worker
+	MirrorRequestsPerSecond float64 // MIRROR_RPS: max mirror node requests per second per worker
+	MaxMintsPerRun          int     // MAX_MINTS_PER_RUN: max domains an ingest run or domain list import may mint
+	BudgetHbar              float64 // RUN_BUDGET_HBAR: fees an ingest run or domain list import may spend before it pauses
+	BudgetUSD               float64 // RUN_BUDGET_USD: the same budget in US dollars, converted when the run starts minting
+}
+
+// TinybarsPerHbar converts HBAR amounts to the tinybar of transaction fees
+const TinybarsPerHbar = 100_000_000
+
+// BudgetTinybar returns the fee budget of a run in tinybar, 0 if unlimited
+func (l LimitsConfig) BudgetTinybar() int64 {
+	return int64(math.Round(l.BudgetHbar * TinybarsPerHbar))
+}
+
+// TemporalConfig holds the Temporal settings
+type TemporalConfig struct {
+	Address           string        // TEMPORAL_ADDRESS: host:port of the Temporal frontend
+	Namespace         string        // TEMPORAL_NAMESPACE
+	Identity          string        // TEMPORAL_IDENTITY: identity reported to Temporal, defaults to the SDK's pid@hostname
+	APIKey            string        // TEMPORAL_API_KEY: Temporal Cloud API key, enables TLS
+	TLSCertFile       string        // TEMPORAL_TLS_CERT: client certificate for mTLS, enables TLS
+	TLSKeyFile        string        // TEMPORAL_TLS_KEY: private key of the client certificate
+	TLSCAFile         string        // TEMPORAL_TLS_CA: CA bundle to verify the server, defaults to the system roots
+	TLSServerName     string        // TEMPORAL_TLS_SERVER_NAME: overrides the server name verified by TLS
+	TaskQueue         string        // TEMPORAL_TASK_QUEUE
+	WorkerStopTimeout time.Duration // WORKER_STOP_TIMEOUT: grace period for in-flight activities on shutdown
+	ShardedZones      []string      // TEMPORAL_SHARDED_ZONES: zones processed on their own task queue by dedicated workers
+
+	// Prefix of the IDs of the workflows started, so deployments sharing a namespace never collide; set from
+	// HEDERA_NETWORK and TENANT
+	WorkflowIDScope string
+}
+
+// ReportsConfig holds the settings of the ingest run reports
+type ReportsConfig struct {
+	Dir    string // REPORT_DIR: directory the run reports are written to
+	Bucket string // REPORT_BUCKET: s3://bucket/prefix or gs://bucket/prefix the run reports are archived to, with the ARCHIVE_S3_* settings
+}
+
+// HCSConfig holds the Hedera Consensus Service topics the ledger publishes to.
+// Topics are named in the topic registry, they are created on first use.
+type HCSConfig struct {
+	RegistryTopic string   // HCS_REGISTRY_TOPIC: ID of the topic recording every topic created, the topic registry file is then a cache of it
+	ReceiptsTopic string   // HCS_RECEIPTS_TOPIC: topic receiving a receipt of every mint, empty disables receipts
+	AnchorTopic   string   // HCS_ANCHOR_TOPIC: topic receiving the Merkle root of every batch of an anchored zone
+	AnchoredZones []string // HCS_ANCHORED_ZONES: zones anchoring Merkle roots instead of publishing receipts, all zones if empty
+	DigestTopic   string   // HCS_DIGEST_TOPIC: topic receiving the digests of the registry state, see RegistryDigestWorkflow
+	InfraTopic    string   // HCS_INFRA_TOPIC: name prefix of the per-zone topics receiving host events, "<prefix>-<zone>", empty disables them
+	ContactTopic  string   // HCS_CONTACT_TOPIC: topic receiving contact events with hashed handles, empty disables them
+
+	SubmitKey   string // HCS_SUBMIT_KEY: private key submitting to topics whose submit key is not the operator key, the submit key of new topics
+	ProducerID  string // HCS_PRODUCER_ID: identity of this worker in the envelopes of audit messages, defaults to TEMPORAL_IDENTITY
+	ProducerKey string // HCS_PRODUCER_KEY: Ed25519 private key signing the envelopes of audit messages, unset publishes plain messages
+
+	ProducerKeysFile string // HCS_PRODUCER_KEYS_FILE: JSON Web Key Set of the producers whose audit messages are trusted, unset trusts every message
+}
+
+// Anchored reports whether the mints of a zone are anchored as Merkle roots
+func (h HCSConfig) Anchored(zone string) bool {
+	return h.AnchorTopic != "" && (len(h.AnchoredZones) == 0 || slices.Contains(h.AnchoredZones, zone))
+}
+
+// ZonesConfig restricts the zones ingested, so a malformed log cannot spawn collections for bogus zones.
+// Zones are lower case, without leading dot.
+type ZonesConfig struct {
+	Allowlist []string // ZONE_ALLOWLIST: the only zones ingested, all zones if empty
+	Denylist  []string // ZONE_DENYLIST: zones never ingested
+
+	PolicyFile string // ZONE_POLICY_FILE: YAML processing policy of the zones, every event is minted if unset
+	ShardSize  int    // ZONE_SHARD_SIZE: serials of a zone collection after which the zone mints into a new collection, unlimited if 0
+}
+
+// Allowed reports whether the domains of a zone may be ingested
+func (z ZonesConfig) Allowed(zone string) bool {
+	zone = strings.TrimPrefix(strings.ToLower(zone), ".")
+	if slices.Contains(z.Denylist, zone) {
+		return false
+	}
+	return len(z.Allowlist) == 0 || slices.Contains(z.Allowlist, zone)
+}
+
+// EventsConfig holds the settings of how registry events are verified and read, and what of them is recorded
+type EventsConfig struct {
+	SignatureMode      string        // EVENT_SIGNATURE_MODE: off, verify or strict
+	KeysFile           string        // EVENT_KEYS_FILE: JSON Web Key Set of the registry public keys, required unless the mode is off
+	UnknownSchema      string        // EVENT_UNKNOWN_SCHEMA: reject or quarantine events of an unknown schema version
+	Nameservers        string        // NAMESERVER_CAPTURE: off, event or dns
+	NSResolver         string        // NAMESERVER_RESOLVER: host:port of the DNS resolver of nameserver lookups, the system resolver if empty
+	FingerprintKeyFile string        // REGISTRANT_FINGERPRINT_KEY_FILE: hex HMAC key of registrant fingerprints, empty records none
+	Redaction          redact.Policy // REDACTION_POLICY: personal data fields kept, stripped or hashed before they are published
+	Watchlist          []string      // TYPOSQUAT_WATCHLIST: brand labels new registrations are compared with, none if unset
+	TyposquatThreshold float64       // TYPOSQUAT_THRESHOLD: similarity from 0 to 1 above which a registration is flagged
+	DGAThreshold       float64       // DGA_THRESHOLD: score from 0 to 1 above which a label is tagged as generated, off if unset
+}
+
+// MetadataConfig holds the settings of the off-chain storage of NFT metadata documents
+type MetadataConfig struct {
+	Store             string // METADATA_STORE: backend the metadata document of every mint is uploaded to, empty disables uploads
+	ArweaveGateway    string // ARWEAVE_GATEWAY: gateway Arweave uploads are posted to
+	ArweaveWalletFile string // ARWEAVE_WALLET_FILE: JWK file of the wallet paying for Arweave storage
+
+	IPFSPinners      []string      // IPFS_PINNERS: pinata, web3storage and/or kubo, every document is pinned with all of them
+	IPFSPinTimeout   time.Duration // IPFS_PIN_TIMEOUT: time allowed for all pins of a document to complete
+	PinataURL        string        // PINATA_API_URL
+	PinataJWT        string        // PINATA_JWT: API key JWT, required by the pinata pinner
+	Web3StorageURL   string        // WEB3STORAGE_URL: Pinning Service API endpoint of web3.storage
+	Web3StorageToken string        // WEB3STORAGE_TOKEN: required by the web3storage pinner
+	KuboURL          string        // IPFS_API_URL: RPC API of the self-hosted Kubo node
+
+	Topic string // METADATA_TOPIC: topic registry name of the HCS topic documents are stored on, created on first use
+
+	BrandingFile string // COLLECTION_BRANDING_FILE: YAML branding of the zone collections (description, logo, website, symbol)
+
+	// Naming of the zone collections, the verification of existing collections depends on it
+	RegistryID     string // COLLECTION_REGISTRY_ID: identifier of the registry in names and symbols, e.g. APEX
+	ZonePrefix     string // COLLECTION_ZONE_PREFIX: prefix of zone collections in symbols, e.g. ZONE
+	NameTemplate   string // COLLECTION_NAME_TEMPLATE: Go template of the token names of zone collections
+	SymbolTemplate string // COLLECTION_SYMBOL_TEMPLATE: Go template of the token symbols of zone collections
+}
+
+// ClaimsConfig holds the settings of domain claims by registrants
+type ClaimsConfig struct {
+	KeysFile string // CLAIM_KEYS_FILE: JSON Web Key Set of the registrar keys signing claim attestations, unset refuses attestations
+}
+
+// TransfersConfig holds the settings of NFT transfers out of the treasury
+type TransfersConfig struct {
+	AssociationPolicy  string        // ASSOCIATION_POLICY: wait, auto or fail, for destinations not associated with the token
+	AssociationTimeout time.Duration // ASSOCIATION_TIMEOUT: how long a transfer waits for the destination to associate the token
+}
+
+// ArchiveConfig holds the settings of the archives of event log files that backfills read from
+type ArchiveConfig struct {
+	StagingDir        string // ARCHIVE_STAGING_DIR: directory files fetched from object storage are kept in for the workers to ingest
+	S3Endpoint        string // ARCHIVE_S3_ENDPOINT: endpoint of the S3 compatible object storage, AWS S3 of ARCHIVE_S3_REGION if unset
+	S3Region          string // ARCHIVE_S3_REGION
+	S3AccessKeyID     string // ARCHIVE_S3_ACCESS_KEY_ID: unset reads public buckets without signing requests
+	S3SecretAccessKey string // ARCHIVE_S3_SECRET_ACCESS_KEY
+}
+
+// IntakeConfig holds the settings of the intake service receiving events pushed by registries
+type IntakeConfig struct {
+	ListenAddr string            // INTAKE_LISTEN_ADDR: address the intake service listens on
+	SpoolDir   string            // INTAKE_SPOOL_DIR: directory pushed batches are written to for the workers to ingest
+	Tokens     map[string]string // INTAKE_TOKENS: "name=token" pairs, the bearer tokens of the registries allowed to push
+	MaxEvents  int               // INTAKE_MAX_EVENTS: maximum number of events per request
+}
+
+// APIConfig holds the credentials and rate limits of the clients of the API service
+type APIConfig struct {
+	Keys      map[string]string // API_KEYS: "name=key" pairs, the API keys of read-only clients
+	AdminKeys map[string]string // API_ADMIN_KEYS: "name=key" pairs, the API keys of admin clients
+	JWTSecret string            // API_JWT_SECRET: secret of the HS256 JSON Web Tokens accepted besides API keys
+	RateLimit int               // API_RATE_LIMIT: requests a minute per client, unlimited if zero
+	RateBurst int               // API_RATE_BURST: requests a client may send at once
+	StreamURL string            // ACTIVITY_STREAM_URL: API server the workers push mints, burns and transfers to, none if unset
+	StreamKey string            // ACTIVITY_STREAM_KEY: admin API key the workers push with
+}
+
+// NotifyConfig holds the chat webhooks ingest runs are announced on
+type NotifyConfig struct {
+	Webhooks         []string // NOTIFY_WEBHOOKS: Slack, Discord or other webhook URLs, comma separated, none if unset
+	FailureThreshold int      // NOTIFY_FAILURE_THRESHOLD: failed domains of a run that raise an alert, no alerts if zero
+}
+
+// Enabled reports whether runs are announced
+func (n NotifyConfig) Enabled() bool {
+	return len(n.Webhooks) > 0
+}
+
+// EmailConfig holds the distribution list run reports and reconciliations are emailed to, and the mail service
+type EmailConfig struct {
+	To                 []string // REPORT_EMAIL_TO: addresses the reports are emailed to, comma separated, none if unset
+	From               string   // REPORT_EMAIL_FROM: sender of the reports
+	Transport          string   // EMAIL_TRANSPORT: smtp or ses
+	SMTPHost           string   // SMTP_HOST
+	SMTPPort           int      // SMTP_PORT: 465 is spoken over TLS, other ports upgrade with STARTTLS when offered
+	SMTPUsername       string   // SMTP_USERNAME: reports are sent without authentication if unset
+	SMTPPassword       string   // SMTP_PASSWORD
+	SESRegion          string   // SES_REGION
+	SESAccessKeyID     string   // SES_ACCESS_KEY_ID
+	SESSecretAccessKey string   // SES_SECRET_ACCESS_KEY
+	SESEndpoint        string   // SES_ENDPOINT: the SES API of SES_REGION if unset
+}
+
+// Enabled reports whether reports are emailed
+func (e EmailConfig) Enabled() bool {
+	return len(e.To) > 0
+}
+
+// EncryptionConfig holds the key the registry files are encrypted at rest with, see pkg/atrest. It is given
+// either as such or as a data key encrypted by AWS KMS.
+type EncryptionConfig struct {
+	Key                string // REGISTRY_ENCRYPTION_KEY: hex AES-256 key, the registry files are written in clear if unset
+	KMSDataKey         string // REGISTRY_KMS_DATA_KEY: base64 AES-256 data key encrypted by AWS KMS, instead of the key
+	KMSRegion          string // KMS_REGION
+	KMSAccessKeyID     string // KMS_ACCESS_KEY_ID
+	KMSSecretAccessKey string // KMS_SECRET_ACCESS_KEY
+	KMSEndpoint        string // KMS_ENDPOINT: the KMS API of KMS_REGION if unset
+}
+
+// Enabled reports whether the registry files are encrypted
+func (e EncryptionConfig) Enabled() bool {
+	return e.Key != "" || e.KMSDataKey != ""
+}
+
+// EscalationConfig holds the on-call services paged when the mints of a zone keep failing
+type EscalationConfig struct {
+	After               int    // ESCALATION_THRESHOLD: consecutive failed mints or burns of a zone that open an incident and pause it
+	PagerDutyRoutingKey string // PAGERDUTY_ROUTING_KEY: integration key of a PagerDuty service (Events API v2)
+	OpsgenieAPIKey      string // OPSGENIE_API_KEY: key of an Opsgenie API integration
+	OpsgenieURL         string // OPSGENIE_API_URL: https://api.eu.opsgenie.com for the EU instance
+}
+
+// Enabled reports whether failing zones are escalated, which needs an on-call service
+func (e EscalationConfig) Enabled() bool {
+	return e.After > 0 && (e.PagerDutyRoutingKey != "" || e.OpsgenieAPIKey != "")
+}
+
+// Threshold returns the consecutive failures escalating a zone, 0 if zones are not escalated
+func (e EscalationConfig) Threshold() int {
+	if !e.Enabled() {
+		return 0
+	}
+	return e.After
+}
+
+// Load reads the configuration from the environment and the selected profile, applies defaults and validates it.
+// It also refuses registry files left where they were before the registry was scoped by network, see
+// CheckUnscoped.
+func Load() (*Config, error) {
+	return LoadProfile("")
+}
+
+// LoadProfile is Load with an explicitly selected profile of the config file
+func LoadProfile(name string) (*Config, error) {
+	cfg, err := FromProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.CheckUnscoped(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// FromEnv reads the configuration from the environment and applies defaults, without validating it.
+// Variables that are not set fall back to the selected profile of the config file (see FromProfile).
+// It only returns an error for values that cannot be parsed at all.
+func FromEnv() (*Config, error) {
+	return FromProfile("")
+}
+
+// fromSource reads the configuration from a variable source and applies defaults
+func fromSource(env source) (*Config, error) {
+	var errs []error
+
+	cfg := &Config{
+		Hedera: HederaConfig{
+			Network:    strings.ToLower(env.get("HEDERA_NETWORK", DefaultNetwork)),
+			AccountID:  strings.TrimSpace(env("HEDERA_ACCOUNT_ID")),
+			PrivateKey: strings.TrimSpace(env("HEDERA_PRIVATE_KEY")),
+			PublicKey:  strings.TrimSpace(env("HEDERA_PUBLIC_KEY")),
+			Signing:    strings.ToLower(env.get("HEDERA_SIGNING", SigningOnline)),
+
+			AdminKey:           strings.TrimSpace(env("HEDERA_ADMIN_KEY")),
+			AdminSignerURL:     strings.TrimSpace(env("HEDERA_ADMIN_SIGNER_URL")),
+			AdminSignerToken:   strings.TrimSpace(env("HEDERA_ADMIN_SIGNER_TOKEN")),
+			AdminSignerCommand: strings.Fields(env("HEDERA_ADMIN_SIGNER_COMMAND")),
+		},
+		Mirror: MirrorConfig{
+			BaseURL:      strings.TrimSuffix(env("MIRROR_NODE_URL"), "/"),
+			GRPCAddress:  strings.TrimSpace(env("MIRROR_NODE_GRPC")),
+			APIKey:       strings.TrimSpace(env("MIRROR_NODE_API_KEY")),
+			APIKeyHeader: env.get("MIRROR_NODE_API_KEY_HEADER", DefaultMirrorAPIKeyHeader),
+		},
+		Registry: RegistryConfig{
+			ZoneFile:         env.get("ZONE_REGISTRY_FILE", DefaultZoneRegistryFile),
+			TopicFile:        env.get("TOPIC_REGISTRY_FILE", DefaultTopicRegistryFile),
+			IngestLedgerFile: env.get("INGEST_LEDGER_FILE", DefaultIngestLedgerFile),
+			AccountFile:      env.get("ACCOUNT_REGISTRY_FILE", DefaultAccountFile),
+			TopicOffsetFile:  env.get("TOPIC_OFFSETS_FILE", DefaultTopicOffsetFile),
+			StoreDSN:         strings.TrimSpace(env("REGISTRY_STORE_DSN")),
+			AnchorDir:        env.get("ANCHOR_DIR", DefaultAnchorDir),
+			SnapshotDir:      env.get("SNAPSHOT_DIR", DefaultSnapshotDir),
+			QuarantineDir:    env.get("QUARANTINE_DIR", DefaultQuarantineDir),
+			TransactionDir:   env.get("TRANSACTION_RECORD_DIR", DefaultTransactionDir),
+			SigningDir:       env.get("SIGNING_DIR", DefaultSigningDir),
+			Integrity:        strings.ToLower(env.get("REGISTRY_INTEGRITY", IntegrityOff)),
+			Tenant:           strings.ToLower(strings.TrimSpace(env("TENANT"))),
+		},
+		Temporal: TemporalConfig{
+			Address:       env.get("TEMPORAL_ADDRESS", DefaultTemporalAddress),
+			Namespace:     env.get("TEMPORAL_NAMESPACE", DefaultTemporalNamespace),
+			Identity:      strings.TrimSpace(env("TEMPORAL_IDENTITY")),
+			APIKey:        strings.TrimSpace(env("TEMPORAL_API_KEY")),
+			TLSCertFile:   strings.TrimSpace(env("TEMPORAL_TLS_CERT")),
+			TLSKeyFile:    strings.TrimSpace(env("TEMPORAL_TLS_KEY")),
+			TLSCAFile:     strings.TrimSpace(env("TEMPORAL_TLS_CA")),
+			TLSServerName: strings.TrimSpace(env("TEMPORAL_TLS_SERVER_NAME")),
+			TaskQueue:     env.get("TEMPORAL_TASK_QUEUE", DefaultTaskQueue),
+			ShardedZones:  env.list("TEMPORAL_SHARDED_ZONES"),
+		},
+		Reports: ReportsConfig{
+			Dir:    env.get("REPORT_DIR", DefaultReportDir),
+			Bucket: strings.TrimSuffix(strings.TrimSpace(env("REPORT_BUCKET")), "/"),
+		},
+		HCS: HCSConfig{
+			RegistryTopic: strings.TrimSpace(env("HCS_REGISTRY_TOPIC")),
+			ReceiptsTopic: strings.TrimSpace(env("HCS_RECEIPTS_TOPIC")),
+			AnchorTopic:   strings.TrimSpace(env("HCS_ANCHOR_TOPIC")),
+			DigestTopic:   strings.TrimSpace(env("HCS_DIGEST_TOPIC")),
+			InfraTopic:    strings.TrimSpace(env("HCS_INFRA_TOPIC")),
+			ContactTopic:  strings.TrimSpace(env("HCS_CONTACT_TOPIC")),
+			AnchoredZones: env.list("HCS_ANCHORED_ZONES"),
+			SubmitKey:     strings.TrimSpace(env("HCS_SUBMIT_KEY")),
+			ProducerID:    env.get("HCS_PRODUCER_ID", strings.TrimSpace(env("TEMPORAL_IDENTITY"))),
+			ProducerKey:   strings.TrimSpace(env("HCS_PRODUCER_KEY")),
+
+			ProducerKeysFile: strings.TrimSpace(env("HCS_PRODUCER_KEYS_FILE")),
+		},
+		Events: EventsConfig{
+			SignatureMode:      strings.ToLower(env.get("EVENT_SIGNATURE_MODE", SignaturesOff)),
+			KeysFile:           strings.TrimSpace(env("EVENT_KEYS_FILE")),
+			UnknownSchema:      strings.ToLower(env.get("EVENT_UNKNOWN_SCHEMA", UnknownSchemaReject)),
+			Nameservers:        strings.ToLower(env.get("NAMESERVER_CAPTURE", NameserversOff)),
+			NSResolver:         strings.TrimSpace(env("NAMESERVER_RESOLVER")),
+			FingerprintKeyFile: strings.TrimSpace(env("REGISTRANT_FINGERPRINT_KEY_FILE")),
+			Watchlist:          env.list("TYPOSQUAT_WATCHLIST"),
+		},
+		Metadata: MetadataConfig{
+			Store:             strings.ToLower(strings.TrimSpace(env("METADATA_STORE"))),
+			ArweaveGateway:    env.get("ARWEAVE_GATEWAY", DefaultArweaveGateway),
+			ArweaveWalletFile: strings.TrimSpace(env("ARWEAVE_WALLET_FILE")),
+			IPFSPinners:       env.list("IPFS_PINNERS"),
+			PinataURL:         env.get("PINATA_API_URL", DefaultPinataURL),
+			PinataJWT:         strings.TrimSpace(env("PINATA_JWT")),
+			Web3StorageURL:    env.get("WEB3STORAGE_URL", DefaultWeb3StorageURL),
+			Web3StorageToken:  strings.TrimSpace(env("WEB3STORAGE_TOKEN")),
+			KuboURL:           env.get("IPFS_API_URL", DefaultKuboURL),
+			Topic:             env.get("METADATA_TOPIC", DefaultMetadataTopic),
+			BrandingFile:      strings.TrimSpace(env("COLLECTION_BRANDING_FILE")),
+			RegistryID:        env.get("COLLECTION_REGISTRY_ID", naming.DefaultRegistryID),
+			ZonePrefix:        env.get("COLLECTION_ZONE_PREFIX", naming.DefaultZonePrefix),
+			NameTemplate:      env.get("COLLECTION_NAME_TEMPLATE", naming.DefaultNameTemplate),
+			SymbolTemplate:    env.get("COLLECTION_SYMBOL_TEMPLATE", naming.DefaultSymbolTemplate),
+		},
+		Claims: ClaimsConfig{
+			KeysFile: strings.TrimSpace(env("CLAIM_KEYS_FILE")),
+		},
+		Transfers: TransfersConfig{
+			AssociationPolicy: strings.ToLower(env.get("ASSOCIATION_POLICY", AssociationWait)),
+		},
+		Archive: ArchiveConfig{
+			StagingDir:        env.get("ARCHIVE_STAGING_DIR", DefaultArchiveStagingDir),
+			S3Endpoint:        strings.TrimSuffix(strings.TrimSpace(env("ARCHIVE_S3_ENDPOINT")), "/"),
+			S3Region:          env.get("ARCHIVE_S3_REGION", DefaultArchiveS3Region),
+			S3AccessKeyID:     strings.TrimSpace(env("ARCHIVE_S3_ACCESS_KEY_ID")),
+			S3SecretAccessKey: strings.TrimSpace(env("ARCHIVE_S3_SECRET_ACCESS_KEY")),
+		},
+		Intake: IntakeConfig{
+			ListenAddr: env.get("INTAKE_LISTEN_ADDR", DefaultIntakeListenAddr),
+			SpoolDir:   env.get("INTAKE_SPOOL_DIR", DefaultIntakeSpoolDir),
+		},
+		API: APIConfig{
+			JWTSecret: strings.TrimSpace(env("API_JWT_SECRET")),
+			StreamURL: strings.TrimSuffix(strings.TrimSpace(env("ACTIVITY_STREAM_URL")), "/"),
+			StreamKey: strings.TrimSpace(env("ACTIVITY_STREAM_KEY")),
+		},
+		Notify: NotifyConfig{
+			Webhooks: env.urls("NOTIFY_WEBHOOKS"),
+		},
+		Email: EmailConfig{
+			To:                 env.list("REPORT_EMAIL_TO"),
+			From:               strings.TrimSpace(env("REPORT_EMAIL_FROM")),
+			Transport:          strings.ToLower(env.get("EMAIL_TRANSPORT", EmailSMTP)),
+			SMTPHost:           strings.TrimSpace(env("SMTP_HOST")),
+			SMTPUsername:       strings.TrimSpace(env("SMTP_USERNAME")),
+			SMTPPassword:       env("SMTP_PASSWORD"),
+			SESRegion:          env.get("SES_REGION", DefaultSESRegion),
+			SESAccessKeyID:     strings.TrimSpace(env("SES_ACCESS_KEY_ID")),
+			SESSecretAccessKey: strings.TrimSpace(env("SES_SECRET_ACCESS_KEY")),
+			SESEndpoint:        strings.TrimSuffix(strings.TrimSpace(env("SES_ENDPOINT")), "/"),
+		},
+		Encryption: EncryptionConfig{
+			Key:                strings.TrimSpace(env("REGISTRY_ENCRYPTION_KEY")),
+			KMSDataKey:         strings.TrimSpace(env("REGISTRY_KMS_DATA_KEY")),
+			KMSRegion:          env.get("KMS_REGION", DefaultKMSRegion),
+			KMSAccessKeyID:     strings.TrimSpace(env("KMS_ACCESS_KEY_ID")),
+			KMSSecretAccessKey: strings.TrimSpace(env("KMS_SECRET_ACCESS_KEY")),
+			KMSEndpoint:        strings.TrimSuffix(strings.TrimSpace(env("KMS_ENDPOINT")), "/"),
+		},
+		Escalation: EscalationConfig{
+			PagerDutyRoutingKey: strings.TrimSpace(env("PAGERDUTY_ROUTING_KEY")),
+			OpsgenieAPIKey:      strings.TrimSpace(env("OPSGENIE_API_KEY")),
+			OpsgenieURL:         strings.TrimSuffix(env.get("OPSGENIE_API_URL", incident.DefaultOpsgenieURL), "/"),
+		},
+		Zones: ZonesConfig{
+			Allowlist: env.zones("ZONE_ALLOWLIST"),
+			Denylist:  env.zones("ZONE_DENYLIST"),
+
+			PolicyFile: strings.TrimSpace(env("ZONE_POLICY_FILE")),
+		},
+	}
+
+	var err error
+	if cfg.Limits.TransactionsPerSecond, err = env.float("HEDERA_TPS"); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Limits.MirrorRequestsPerSecond, err = env.float("MIRROR_RPS"); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Limits.MaxMintsPerRun, err = env.int("MAX_MINTS_PER_RUN", DefaultMaxMintsPerRun); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Limits.BudgetHbar, err = env.float("RUN_BUDGET_HBAR"); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Limits.BudgetUSD, err = env.float("RUN_BUDGET_USD"); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Hedera.SigningDelay, err = env.duration("HEDERA_SIGNING_DELAY", DefaultSigningDelay); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Temporal.WorkerStopTimeout, err = env.duration("WORKER_STOP_TIMEOUT", DefaultWorkerStopTimeout); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Transfers.AssociationTimeout, err = env.duration("ASSOCIATION_TIMEOUT", DefaultAssociationTimeout); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Metadata.IPFSPinTimeout, err = env.duration("IPFS_PIN_TIMEOUT", DefaultIPFSPinTimeout); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Mirror.VisibilityTimeout, err = env.duration("MIRROR_VISIBILITY_TIMEOUT", 0); err != nil {
+		errs = append(errs, err)
+	}
+
+	if cfg.Intake.MaxEvents, err = env.int("INTAKE_MAX_EVENTS", DefaultIntakeMaxEvents); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Events.Redaction, err = redact.ParsePolicy(env("REDACTION_POLICY")); err != nil {
+		errs = append(errs, fmt.Errorf("REDACTION_POLICY: %w", err))
+	}
+	if cfg.Intake.Tokens, err = ParseTokens(env("INTAKE_TOKENS")); err != nil {
+		errs = append(errs, fmt.Errorf("INTAKE_TOKENS: %w", err))
+	}
+	if cfg.API.Keys, err = ParseTokens(env("API_KEYS")); err != nil {
+		errs = append(errs, fmt.Errorf("API_KEYS: %w", err))
+	}
+	if cfg.API.AdminKeys, err = ParseTokens(env("API_ADMIN_KEYS")); err != nil {
+		errs = append(errs, fmt.Errorf("API_ADMIN_KEYS: %w", err))
+	}
+	if cfg.API.RateLimit, err = env.int("API_RATE_LIMIT", DefaultAPIRateLimit); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.API.RateBurst, err = env.int("API_RATE_BURST", DefaultAPIRateBurst); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Notify.FailureThreshold, err = env.int("NOTIFY_FAILURE_THRESHOLD", DefaultFailureThreshold); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Email.SMTPPort, err = env.int("SMTP_PORT", DefaultSMTPPort); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Escalation.After, err = env.int("ESCALATION_THRESHOLD", DefaultEscalationAfter); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Zones.ShardSize, err = env.int("ZONE_SHARD_SIZE", 0); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Events.TyposquatThreshold, err = env.float("TYPOSQUAT_THRESHOLD"); err != nil {
+		errs = append(errs, err)
+	} else if strings.TrimSpace(env("TYPOSQUAT_THRESHOLD")) == "" {
+		cfg.Events.TyposquatThreshold = DefaultTyposquatThreshold
+	}
+
+	if cfg.Events.DGAThreshold, err = env.float("DGA_THRESHOLD"); err != nil {
+		errs = append(errs, err)
+	}
+
+	if cfg.Mirror.Headers, err = ParseHeaders(env("MIRROR_NODE_HEADERS")); err != nil {
+		errs = append(errs, fmt.Errorf("MIRROR_NODE_HEADERS: %w", err))
+	}
+
+	if cfg.Mirror.BaseURL == "" {
+		cfg.Mirror.BaseURL = strings.TrimSuffix(env.get("MIRROR_NODE_URL_"+strings.ToUpper(cfg.Hedera.Network), MirrorNodeURL(cfg.Hedera.Network)), "/")
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if _, ok := mirrorNodeURLs[cfg.Hedera.Network]; ok && (cfg.Registry.Tenant == "" || tenantPattern.MatchString(cfg.Registry.Tenant)) {
+		cfg.scopeRegistry()
+	}
+	return cfg, nil
+}
+
+// tenantPattern is the form of TENANT names, usable in paths, schema names and workflow IDs
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// scopeRegistry moves the registry files and directories into a directory named after the Hedera network and,
+// with TENANT, into one named after the tenant within it, e.g. zone_collections.json to
+// testnet/acme/zone_collections.json, and gives them their own store schema, task queue and workflow IDs.
+// Switching HEDERA_NETWORK then never reuses the collections and topics of another network, and the registries
+// run by one deployment never share state. Each tenant has its own profile, with its own credentials and
+// collection naming.
+func (c *Config) scopeRegistry() {
+	scope := []string{c.Hedera.Network}
+	if c.Registry.Tenant != "" {
+		scope = append(scope, c.Registry.Tenant)
+	}
+	c.Registry.unscoped = make(map[string]string)
+	for _, path := range []*string{
+		&c.Registry.ZoneFile, &c.Registry.TopicFile, &c.Registry.IngestLedgerFile, &c.Registry.AccountFile,
+		&c.Registry.TopicOffsetFile, &c.Registry.AnchorDir, &c.Registry.SnapshotDir, &c.Registry.QuarantineDir,
+		&c.Registry.TransactionDir, &c.Registry.SigningDir, &c.Reports.Dir, &c.Archive.StagingDir, &c.Intake.SpoolDir,
+	} {
+		if *path == "" {
+			continue
+		}
+		unscoped := *path
+		*path = filepath.Join(append(append([]string{filepath.Dir(unscoped)}, scope...), filepath.Base(unscoped))...)
+		// Tenants came with the network scopes, only the files of a single registry can predate them
+		if c.Registry.Tenant == "" {
+			c.Registry.unscoped[*path] = unscoped
+		}
+	}
+	c.Registry.StoreSchema = strings.Join(scope, "_")
+	c.Temporal.TaskQueue += "-" + strings.Join(scope, "-")
+	c.Temporal.WorkflowIDScope = strings.Join(scope, "/")
+}
+
+// CheckUnscoped refuses registry files and directories left where they were before the registry was scoped by
+// network: the ledger would start over next to them, e.g. creating the collections of their zones again. They
+// are to be moved where the configuration now expects them once checked to belong to the network.
+func (c *Config) CheckUnscoped() error {
+	var errs []error
+	for _, path := range slices.Sorted(maps.Keys(c.Registry.unscoped)) {
+		unscoped := c.Registry.unscoped[path]
+		if _, err := os.Stat(unscoped); err != nil {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s predates the scoping of the registry by network, move it to %s if it belongs to %s", unscoped, path, c.Hedera.Network))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks all settings and returns an error listing every problem found.
+// Operator credentials are optional here, use RequireOperator where they are mandatory.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if _, ok := mirrorNodeURLs[c.Hedera.Network]; !ok {
+		errs = append(errs, fmt.Errorf("HEDERA_NETWORK: unknown network %q (expected mainnet, testnet, previewnet or local)", c.Hedera.Network))
+	}
+	if c.Hedera.AccountID != "" {
+		if _, err := entityid.ParseAccount(c.Hedera.AccountID, c.Hedera.Network); err != nil {
+			errs = append(errs, fmt.Errorf("HEDERA_ACCOUNT_ID: %w", err))
+		}
+	}
+	if c.Hedera.PrivateKey != "" {
+		if _, err := hedera.PrivateKeyFromString(c.Hedera.PrivateKey); err != nil {
+			errs = append(errs, fmt.Errorf("HEDERA_PRIVATE_KEY: not a valid private key: %w", err))
+		}
+	}
+	if c.Hedera.PublicKey != "" {
+		publicKey, err := hedera.PublicKeyFromString(c.Hedera.PublicKey)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("HEDERA_PUBLIC_KEY: not a valid public key: %w", err))
+		} else if privateKey, err := hedera.PrivateKeyFromString(c.Hedera.PrivateKey); err == nil && privateKey.PublicKey().StringDer() != publicKey.StringDer() {
+			errs = append(errs, errors.New("HEDERA_PUBLIC_KEY: not the public key of HEDERA_PRIVATE_KEY"))
+		}
+	}
+	switch c.Hedera.Signing {
+	case SigningOnline:
+	case SigningOffline:
+		if c.Hedera.AccountID == "" {
+			errs = append(errs, errors.New("HEDERA_SIGNING: offline requires HEDERA_ACCOUNT_ID, the account paying for the transactions"))
+		}
+		if c.Hedera.PublicKey == "" && c.Hedera.PrivateKey == "" {
+			errs = append(errs, errors.New("HEDERA_SIGNING: offline requires HEDERA_PUBLIC_KEY, the supply key of the collections created"))
+		}
+		if c.Hedera.SigningDelay <= 0 {
+			errs = append(errs, errors.New("HEDERA_SIGNING_DELAY: must be positive"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("HEDERA_SIGNING: unknown mode %q (expected online or offline)", c.Hedera.Signing))
+	}
+	if c.Mirror.VisibilityTimeout < 0 {
+		errs = append(errs, errors.New("MIRROR_VISIBILITY_TIMEOUT: must not be negative"))
+	}
+	if c.Hedera.AdminSignerURL != "" && len(c.Hedera.AdminSignerCommand) > 0 {
+		errs = append(errs, errors.New("HEDERA_ADMIN_SIGNER_URL and HEDERA_ADMIN_SIGNER_COMMAND are exclusive"))
+	}
+	if c.Hedera.AdminSignerURL != "" {
+		if u, err := url.Parse(c.Hedera.AdminSignerURL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("HEDERA_ADMIN_SIGNER_URL: %q is not an absolute URL", c.Hedera.AdminSignerURL))
+		}
+	}
+	switch {
+	case c.Hedera.AdminSigner() && c.Hedera.AdminKey == "":
+		errs = append(errs, errors.New("HEDERA_ADMIN_KEY: required with an admin signer, the public key it signs with"))
+	case !c.Hedera.AdminSigner() && c.Hedera.AdminKey != "":
+		errs = append(errs, errors.New("HEDERA_ADMIN_KEY: requires HEDERA_ADMIN_SIGNER_URL or HEDERA_ADMIN_SIGNER_COMMAND, the signer of the key"))
+	case c.Hedera.AdminKey != "":
+		if _, err := hedera.PublicKeyFromString(c.Hedera.AdminKey); err != nil {
+			errs = append(errs, fmt.Errorf("HEDERA_ADMIN_KEY: not a valid public key: %w", err))
+		}
+	}
+	if u, err := url.Parse(c.Mirror.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		errs = append(errs, fmt.Errorf("MIRROR_NODE_URL: %q is not an absolute URL", c.Mirror.BaseURL))
+	}
+	if c.Mirror.GRPCAddress != "" {
+		if _, _, err := net.SplitHostPort(c.Mirror.GRPCAddress); err != nil {
+			errs = append(errs, fmt.Errorf("MIRROR_NODE_GRPC: %q is not a host:port address", c.Mirror.GRPCAddress))
+		}
+	}
+	if c.Registry.ZoneFile == "" {
+		errs = append(errs, errors.New("ZONE_REGISTRY_FILE: must not be empty"))
+	}
+	if c.Registry.TopicFile == "" {
+		errs = append(errs, errors.New("TOPIC_REGISTRY_FILE: must not be empty"))
+	}
+	if c.Registry.IngestLedgerFile == "" {
+		errs = append(errs, errors.New("INGEST_LEDGER_FILE: must not be empty"))
+	}
+	if c.Registry.AccountFile == "" {
+		errs = append(errs, errors.New("ACCOUNT_REGISTRY_FILE: must not be empty"))
+	}
+	if c.Registry.TopicOffsetFile == "" {
+		errs = append(errs, errors.New("TOPIC_OFFSETS_FILE: must not be empty"))
+	}
+	if c.Registry.AnchorDir == "" {
+		errs = append(errs, errors.New("ANCHOR_DIR: must not be empty"))
+	}
+	if c.Registry.SnapshotDir == "" {
+		errs = append(errs, errors.New("SNAPSHOT_DIR: must not be empty"))
+	}
+	if c.Registry.QuarantineDir == "" {
+		errs = append(errs, errors.New("QUARANTINE_DIR: must not be empty"))
+	}
+	if c.Registry.Tenant != "" && !tenantPattern.MatchString(c.Registry.Tenant) {
+		errs = append(errs, fmt.Errorf("TENANT: %q must be up to 32 lower case letters, digits and hyphens", c.Registry.Tenant))
+	}
+	if c.Registry.TransactionDir == "" {
+		errs = append(errs, errors.New("TRANSACTION_RECORD_DIR: must not be empty"))
+	}
+	if c.HCS.RegistryTopic != "" {
+		if _, err := entityid.ParseTopic(c.HCS.RegistryTopic, c.Hedera.Network); err != nil {
+			errs = append(errs, fmt.Errorf("HCS_REGISTRY_TOPIC: %w", err))
+		}
+	}
+	if c.HCS.SubmitKey != "" {
+		if _, err := hedera.PrivateKeyFromString(c.HCS.SubmitKey); err != nil {
+			errs = append(errs, fmt.Errorf("HCS_SUBMIT_KEY: not a valid private key: %w", err))
+		}
+	}
+	if c.HCS.ProducerKey != "" {
+		if _, err := hedera.PrivateKeyFromStringEd25519(c.HCS.ProducerKey); err != nil {
+			errs = append(errs, fmt.Errorf("HCS_PRODUCER_KEY: not a valid Ed25519 private key: %w", err))
+		}
+		if c.HCS.ProducerID == "" {
+			errs = append(errs, errors.New("HCS_PRODUCER_ID: required with HCS_PRODUCER_KEY, unless TEMPORAL_IDENTITY is set"))
+		}
+	}
+	if len(c.HCS.AnchoredZones) > 0 && c.HCS.AnchorTopic == "" {
+		errs = append(errs, errors.New("HCS_ANCHORED_ZONES: requires HCS_ANCHOR_TOPIC"))
+	}
+	switch c.Registry.Integrity {
+	case IntegrityOff:
+	case IntegrityWarn, IntegrityEnforce:
+		if c.Hedera.PrivateKey == "" {
+			errs = append(errs, fmt.Errorf("REGISTRY_INTEGRITY: %s requires HEDERA_PRIVATE_KEY, the key signing the registry files", c.Registry.Integrity))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("REGISTRY_INTEGRITY: unknown mode %q (expected off, warn or enforce)", c.Registry.Integrity))
+	}
+	switch c.Events.SignatureMode {
+	case SignaturesOff:
+	case SignaturesVerify, SignaturesStrict:
+		if c.Events.KeysFile == "" {
+			errs = append(errs, fmt.Errorf("EVENT_KEYS_FILE: required when EVENT_SIGNATURE_MODE is %s", c.Events.SignatureMode))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("EVENT_SIGNATURE_MODE: unknown mode %q (expected off, verify or strict)", c.Events.SignatureMode))
+	}
+	switch c.Events.UnknownSchema {
+	case UnknownSchemaReject, UnknownSchemaQuarantine:
+	default:
+		errs = append(errs, fmt.Errorf("EVENT_UNKNOWN_SCHEMA: unknown handling %q (expected reject or quarantine)", c.Events.UnknownSchema))
+	}
+	switch c.Events.Nameservers {
+	case NameserversOff, NameserversEvent, NameserversDNS:
+	default:
+		errs = append(errs, fmt.Errorf("NAMESERVER_CAPTURE: unknown source %q (expected off, event or dns)", c.Events.Nameservers))
+	}
+	if c.Events.Redaction.Hashes() && c.Events.FingerprintKeyFile == "" {
+		errs = append(errs, errors.New("REDACTION_POLICY: hashing fields requires REGISTRANT_FINGERPRINT_KEY_FILE, the key of the hashes"))
+	}
+	if c.HCS.ContactTopic != "" && c.Events.FingerprintKeyFile == "" {
+		errs = append(errs, errors.New("HCS_CONTACT_TOPIC: requires REGISTRANT_FINGERPRINT_KEY_FILE, the key hashing the contact handles"))
+	}
+	for _, brand := range c.Events.Watchlist {
+		if err := domain.Label(brand).Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("TYPOSQUAT_WATCHLIST: %q: %w", brand, err))
+		}
+	}
+	if c.Events.TyposquatThreshold <= 0 || c.Events.TyposquatThreshold > 1 {
+		errs = append(errs, errors.New("TYPOSQUAT_THRESHOLD: must be above 0 and at most 1"))
+	}
+	if c.Events.DGAThreshold < 0 || c.Events.DGAThreshold > 1 {
+		errs = append(errs, errors.New("DGA_THRESHOLD: must be between 0 and 1"))
+	}
+	if c.Events.NSResolver != "" {
+		if _, _, err := net.SplitHostPort(c.Events.NSResolver); err != nil {
+			errs = append(errs, fmt.Errorf("NAMESERVER_RESOLVER: %w", err))
+		}
+	}
+	errs = append(errs, c.Metadata.ValidateStore(c.Metadata.Store)...)
+	if c.Metadata.BrandingFile != "" && c.Metadata.Store == "" {
+		// Collection documents are referenced by URI, the token metadata is too small to hold them
+		errs = append(errs, errors.New("COLLECTION_BRANDING_FILE: requires METADATA_STORE"))
+	}
+	if _, err := c.Metadata.Naming(); err != nil {
+		errs = append(errs, fmt.Errorf("COLLECTION_NAME_TEMPLATE and COLLECTION_SYMBOL_TEMPLATE: %w", err))
+	}
+	switch c.Transfers.AssociationPolicy {
+	case AssociationWait, AssociationAuto, AssociationFail:
+	default:
+		errs = append(errs, fmt.Errorf("ASSOCIATION_POLICY: unknown policy %q (expected wait, auto or fail)", c.Transfers.AssociationPolicy))
+	}
+	if c.Archive.StagingDir == "" {
+		errs = append(errs, errors.New("ARCHIVE_STAGING_DIR: must not be empty"))
+	}
+	if c.Archive.S3Endpoint != "" {
+		if u, err := url.Parse(c.Archive.S3Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("ARCHIVE_S3_ENDPOINT: %q is not an absolute URL", c.Archive.S3Endpoint))
+		}
+	}
+	if (c.Archive.S3AccessKeyID == "") != (c.Archive.S3SecretAccessKey == "") {
+		errs = append(errs, errors.New("ARCHIVE_S3_ACCESS_KEY_ID and ARCHIVE_S3_SECRET_ACCESS_KEY: must be set together"))
+	}
+	if c.Reports.Bucket != "" {
+		if _, err := archive.NewBucket(c.Reports.Bucket, archive.S3Options{}); err != nil {
+			errs = append(errs, fmt.Errorf("REPORT_BUCKET: %w", err))
+		}
+	}
+	if _, _, err := net.SplitHostPort(c.Intake.ListenAddr); err != nil {
+		errs = append(errs, fmt.Errorf("INTAKE_LISTEN_ADDR: %q is not a host:port address", c.Intake.ListenAddr))
+	}
+	if c.Intake.SpoolDir == "" {
+		errs = append(errs, errors.New("INTAKE_SPOOL_DIR: must not be empty"))
+	}
+	if c.Intake.MaxEvents <= 0 {
+		errs = append(errs, errors.New("INTAKE_MAX_EVENTS: must be positive"))
+	}
+	for name, key := range c.API.AdminKeys {
+		if _, ok := c.API.Keys[name]; ok {
+			errs = append(errs, fmt.Errorf("API_KEYS and API_ADMIN_KEYS: name %s is listed in both", name))
+		}
+		for other, readKey := range c.API.Keys {
+			if readKey == key {
+				errs = append(errs, fmt.Errorf("API_KEYS and API_ADMIN_KEYS: %s and %s have the same key", other, name))
+			}
+		}
+	}
+	if c.API.JWTSecret != "" && len(c.API.JWTSecret) < 32 {
+		errs = append(errs, errors.New("API_JWT_SECRET: must be at least 32 characters"))
+	}
+	if c.API.RateLimit < 0 {
+		errs = append(errs, errors.New("API_RATE_LIMIT: must not be negative"))
+	}
+	if c.API.RateBurst <= 0 {
+		errs = append(errs, errors.New("API_RATE_BURST: must be positive"))
+	}
+	if c.API.StreamURL != "" {
+		if u, err := url.Parse(c.API.StreamURL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("ACTIVITY_STREAM_URL: %q is not an absolute URL", c.API.StreamURL))
+		}
+	}
+	for _, webhook := range c.Notify.Webhooks {
+		if u, err := url.Parse(webhook); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			errs = append(errs, errors.New("NOTIFY_WEBHOOKS: every webhook must be an http(s) URL"))
+			break
+		}
+	}
+	if c.Notify.FailureThreshold < 0 {
+		errs = append(errs, errors.New("NOTIFY_FAILURE_THRESHOLD: must not be negative"))
+	}
+	if c.Email.Enabled() {
+		errs = append(errs, c.Email.validate()...)
+	}
+	if c.Encryption.Enabled() {
+		errs = append(errs, c.Encryption.validate()...)
+	}
+	if c.Escalation.After < 0 {
+		errs = append(errs, errors.New("ESCALATION_THRESHOLD: must not be negative"))
+	}
+	if c.Escalation.OpsgenieAPIKey != "" {
+		if u, err := url.Parse(c.Escalation.OpsgenieURL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("OPSGENIE_API_URL: %q is not an absolute URL", c.Escalation.OpsgenieURL))
+		}
+	}
+	if c.Limits.TransactionsPerSecond < 0 {
+		errs = append(errs, errors.New("HEDERA_TPS: must not be negative"))
+	}
+	if c.Limits.MirrorRequestsPerSecond < 0 {
+		errs = append(errs, errors.New("MIRROR_RPS: must not be negative"))
+	}
+	if c.Limits.MaxMintsPerRun < 0 {
+		errs = append(errs, errors.New("MAX_MINTS_PER_RUN: must not be negative"))
+	}
+	if c.Limits.BudgetHbar < 0 {
+		errs = append(errs, errors.New("RUN_BUDGET_HBAR: must not be negative"))
+	}
+	if c.Limits.BudgetUSD < 0 {
+		errs = append(errs, errors.New("RUN_BUDGET_USD: must not be negative"))
+	}
+	if c.Limits.BudgetHbar > 0 && c.Limits.BudgetUSD > 0 {
+		errs = append(errs, errors.New("RUN_BUDGET_HBAR and RUN_BUDGET_USD: only one can be set"))
+	}
+	if _, _, err := net.SplitHostPort(c.Temporal.Address); err != nil {
+		errs = append(errs, fmt.Errorf("TEMPORAL_ADDRESS: %q is not a host:port address", c.Temporal.Address))
+	}
+	if (c.Temporal.TLSCertFile == "") != (c.Temporal.TLSKeyFile == "") {
+		errs = append(errs, errors.New("TEMPORAL_TLS_CERT and TEMPORAL_TLS_KEY: must be set together"))
+	}
+	if c.Temporal.APIKey != "" && c.Temporal.TLSCertFile != "" {
+		errs = append(errs, errors.New("TEMPORAL_API_KEY: cannot be combined with mTLS (TEMPORAL_TLS_CERT), choose one"))
+	}
+	if c.Temporal.TaskQueue == "" {
+		errs = append(errs, errors.New("TEMPORAL_TASK_QUEUE: must not be empty"))
+	}
+	if len(c.Zones.Allowlist) > 0 && len(c.Zones.Denylist) > 0 {
+		errs = append(errs, errors.New("ZONE_ALLOWLIST and ZONE_DENYLIST: set one or the other"))
+	}
+	if c.Zones.ShardSize < 0 {
+		errs = append(errs, errors.New("ZONE_SHARD_SIZE: must not be negative"))
+	}
+	if c.Temporal.WorkerStopTimeout < 0 {
+		errs = append(errs, errors.New("WORKER_STOP_TIMEOUT: must not be negative"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// validate checks the sender and the mail service of the reports
+func (e EmailConfig) validate() []error {
+	var errs []error
+	if e.From == "" {
+		errs = append(errs, errors.New("REPORT_EMAIL_FROM: must be set to email reports"))
+	}
+	switch e.Transport {
+	case EmailSMTP:
+		if e.SMTPHost == "" {
+			errs = append(errs, errors.New("SMTP_HOST: must be set to email reports with EMAIL_TRANSPORT=smtp"))
+		}
+		if e.SMTPPort <= 0 || e.SMTPPort > 65535 {
+			errs = append(errs, fmt.Errorf("SMTP_PORT: %d is not a port", e.SMTPPort))
+		}
+	case EmailSES:
+		if e.SESAccessKeyID == "" || e.SESSecretAccessKey == "" {
+			errs = append(errs, errors.New("SES_ACCESS_KEY_ID and SES_SECRET_ACCESS_KEY: must be set to email reports with EMAIL_TRANSPORT=ses"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("EMAIL_TRANSPORT: unknown transport %q (expected smtp or ses)", e.Transport))
+	}
+	return errs
+}
+
+// validate checks the key of the registry files, or the data key and the KMS credentials decrypting it
+func (e EncryptionConfig) validate() []error {
+	var errs []error
+	switch {
+	case e.Key != "" && e.KMSDataKey != "":
+		errs = append(errs, errors.New("REGISTRY_ENCRYPTION_KEY and REGISTRY_KMS_DATA_KEY: only one of them may be set"))
+	case e.Key != "":
+		if _, err := atrest.ParseKey(e.Key); err != nil {
+			errs = append(errs, fmt.Errorf("REGISTRY_ENCRYPTION_KEY: %w", err))
+		}
+	default:
+		if _, err := base64.StdEncoding.DecodeString(e.KMSDataKey); err != nil {
+			errs = append(errs, errors.New("REGISTRY_KMS_DATA_KEY: not base64 encoded"))
+		}
+		if e.KMSAccessKeyID == "" || e.KMSSecretAccessKey == "" {
+			errs = append(errs, errors.New("KMS_ACCESS_KEY_ID and KMS_SECRET_ACCESS_KEY: must be set to decrypt REGISTRY_KMS_DATA_KEY"))
+		}
+	}
+	return errs
+}
+
+// ValidateStore checks the settings a metadata store requires, e.g. of METADATA_STORE or of the policy of a zone
+func (m MetadataConfig) ValidateStore(store string) []error {
+	var errs []error
+	switch store {
+	case "":
+	case MetadataStoreArweave:
+		if m.ArweaveWalletFile == "" {
+			errs = append(errs, errors.New("ARWEAVE_WALLET_FILE: required by the arweave store"))
+		}
+		if u, err := url.Parse(m.ArweaveGateway); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("ARWEAVE_GATEWAY: %q is not an absolute URL", m.ArweaveGateway))
+		}
+	case MetadataStoreIPFS:
+		errs = append(errs, m.validatePinners()...)
+	case MetadataStoreHCS:
+		if m.Topic == "" {
+			errs = append(errs, errors.New("METADATA_TOPIC: required by the hcs store"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("METADATA_STORE: unknown store %q (expected arweave, ipfs or hcs)", store))
+	}
+	return errs
+}
+
+// validatePinners checks the IPFS pinners and their credentials
+func (m MetadataConfig) validatePinners() []error {
+	var errs []error
+	if len(m.IPFSPinners) == 0 {
+		return []error{errors.New("IPFS_PINNERS: required by the ipfs store")}
+	}
+	receiving := false
+	for _, pinner := range m.IPFSPinners {
+		switch pinner {
+		case PinnerPinata:
+			receiving = true
+			if m.PinataJWT == "" {
+				errs = append(errs, errors.New("PINATA_JWT: required by the pinata pinner"))
+			}
+		case PinnerWeb3Storage:
+			if m.Web3StorageToken == "" {
+				errs = append(errs, errors.New("WEB3STORAGE_TOKEN: required by the web3storage pinner"))
+			}
+		case PinnerKubo:
+			receiving = true
+		default:
+			errs = append(errs, fmt.Errorf("IPFS_PINNERS: unknown pinner %q (expected pinata, web3storage or kubo)", pinner))
+		}
+	}
+	// Pinning services fetch documents from the network, somebody has to provide them first
+	if !receiving {
+		errs = append(errs, errors.New("IPFS_PINNERS: requires pinata or kubo to add the documents to IPFS"))
+	}
+	if m.IPFSPinTimeout <= 0 {
+		errs = append(errs, errors.New("IPFS_PIN_TIMEOUT: must be positive"))
+	}
+	return errs
+}
+
+// Naming returns the naming of the zone collections
+func (m MetadataConfig) Naming() (*naming.Naming, error) {
+	return naming.New(m.RegistryID, m.ZonePrefix, m.NameTemplate, m.SymbolTemplate)
+}
+
+// TLS reports whether the Temporal connection uses TLS, which is implied by any TLS setting or an API key
+func (t TemporalConfig) TLS() bool {
+	return t.APIKey != "" || t.TLSCertFile != "" || t.TLSCAFile != "" || t.TLSServerName != ""
+}
+
+// RequireOperator returns ErrMissingOperator unless both operator credentials are set
+func (c *Config) RequireOperator() error {
+	if c.Hedera.AccountID == "" || c.Hedera.PrivateKey == "" {
+		return ErrMissingOperator
+	}
+	return nil
+}
+
+// MirrorNodeURL returns the public mirror node REST API base URL for a network, or an empty string if unknown
+func MirrorNodeURL(network string) string {
+	return mirrorNodeURLs[network]
+}
+
+// ParseHeaders parses comma separated "Name: value" pairs into a map of canonical header names to values
+func ParseHeaders(s string) (map[string]string, error) {
+	var headers map[string]string
+	for _, item := range strings.Split(s, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		name, value, found := strings.Cut(item, ":")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !found || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("%q is not a \"Name: value\" header", strings.TrimSpace(item))
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[http.CanonicalHeaderKey(name)] = value
+	}
+	return headers, nil
+}
+
+// ParseTokens parses comma separated "name=token" pairs into a map of names to tokens.
+// Names and tokens must be unique, so a token identifies the one client it was given to.
+func ParseTokens(s string) (map[string]string, error) {
+	var tokens map[string]string
+	names := make(map[string]string)
+	for i, item := range strings.Split(s, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		name, token, found := strings.Cut(item, "=")
+		name, token = strings.TrimSpace(name), strings.TrimSpace(token)
+		if !found || name == "" || token == "" {
+			// Do not echo the entry, it may be a token
+			return nil, fmt.Errorf("entry %d is not a \"name=token\" pair", i+1)
+		}
+		if _, ok := tokens[name]; ok {
+			return nil, fmt.Errorf("name %s is listed twice", name)
+		}
+		if other, ok := names[token]; ok {
+			return nil, fmt.Errorf("%s and %s have the same token", other, name)
+		}
+		if tokens == nil {
+			tokens = make(map[string]string)
+		}
+		tokens[name] = token
+		names[token] = name
+	}
+	return tokens, nil
+}
+
+// source looks up configuration variables by name, returning an empty string when unset
+type source func(key string) string
+
+// get returns the trimmed value of a variable or the fallback if it is unset or empty
+func (env source) get(key, fallback string) string {
+	if v := strings.TrimSpace(env(key)); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// list parses an optional comma separated variable into a lowercased list
+func (env source) list(key string) []string {
+	return ParseList(env(key))
+}
+
+// zones parses an optional comma separated list of zones, dropping leading dots
+func (env source) zones(key string) []string {
+	zones := env.list(key)
+	for i, zone := range zones {
+		zones[i] = strings.TrimPrefix(zone, ".")
+	}
+	return zones
+}
+
+// urls parses an optional comma separated list of URLs, which unlike other lists keep their case
+func (env source) urls(key string) []string {
+	var urls []string
+	for _, item := range strings.Split(env(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			urls = append(urls, item)
+		}
+	}
+	return urls
+}
+
+// ParseList splits a comma separated list, trimming and lowercasing items and dropping empty ones
+func ParseList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// int parses an optional integer variable, returning the fallback when unset
+func (env source) int(key string, fallback int) (int, error) {
+	v := strings.TrimSpace(env(key))
+	if v == "" {
+		return fallback, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %q is not an integer", key, v)
+	}
+	return i, nil
+}
+
+// float parses an optional float variable, returning 0 when unset
+func (env source) float(key string) (float64, error) {
+	v := strings.TrimSpace(env(key))
+	if v == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %q is not a number", key, v)
+	}
+	return f, nil
+}
+
+// duration parses an optional duration variable (e.g. "45s"), returning the fallback when unset
+func (env source) duration(key string, fallback time.Duration) (time.Duration, error) {
+	v := strings.TrimSpace(env(key))
+	if v == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %q is not a duration (e.g. 30s, 2m)", key, v)
+	}
+	return d, nil
+}
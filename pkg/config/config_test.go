@@ -0,0 +1,911 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/redact"
+)
+
+// clearEnv unsets all variables read by FromEnv for the duration of the test
+func clearEnv(t *testing.T) {
+	for _, key := range []string{
+		"HEDERA_NETWORK", "HEDERA_ACCOUNT_ID", "HEDERA_PRIVATE_KEY", "HEDERA_PUBLIC_KEY", "HEDERA_SIGNING", "HEDERA_SIGNING_DELAY", "HEDERA_ADMIN_KEY",
+		"HEDERA_ADMIN_SIGNER_URL", "HEDERA_ADMIN_SIGNER_TOKEN", "HEDERA_ADMIN_SIGNER_COMMAND", "MIRROR_NODE_URL",
+		"MIRROR_NODE_URL_MAINNET", "MIRROR_NODE_URL_TESTNET", "MIRROR_NODE_GRPC", "MIRROR_NODE_API_KEY", "MIRROR_NODE_API_KEY_HEADER", "MIRROR_NODE_HEADERS",
+		"MIRROR_VISIBILITY_TIMEOUT",
+		"ZONE_REGISTRY_FILE", "TOPIC_REGISTRY_FILE", "INGEST_LEDGER_FILE", "ACCOUNT_REGISTRY_FILE", "TOPIC_OFFSETS_FILE", "HEDERA_TPS", "MIRROR_RPS", "MAX_MINTS_PER_RUN", "RUN_BUDGET_HBAR", "RUN_BUDGET_USD", "TEMPORAL_TASK_QUEUE",
+		"WORKER_STOP_TIMEOUT", "TEMPORAL_SHARDED_ZONES", "REPORT_DIR", "REPORT_BUCKET", "TEMPORAL_ADDRESS", "TEMPORAL_NAMESPACE",
+		"TEMPORAL_IDENTITY", "TEMPORAL_API_KEY", "TEMPORAL_TLS_CERT", "TEMPORAL_TLS_KEY", "TEMPORAL_TLS_CA", "TEMPORAL_TLS_SERVER_NAME",
+		"REGISTRY_STORE_DSN", "HCS_REGISTRY_TOPIC", "HCS_RECEIPTS_TOPIC", "HCS_ANCHOR_TOPIC", "HCS_ANCHORED_ZONES", "HCS_DIGEST_TOPIC", "HCS_INFRA_TOPIC", "HCS_CONTACT_TOPIC", "HCS_SUBMIT_KEY", "HCS_PRODUCER_ID", "HCS_PRODUCER_KEY", "HCS_PRODUCER_KEYS_FILE", "ANCHOR_DIR", "SNAPSHOT_DIR", "EVENT_SIGNATURE_MODE", "EVENT_KEYS_FILE",
+		"EVENT_UNKNOWN_SCHEMA", "QUARANTINE_DIR", "TRANSACTION_RECORD_DIR", "REGISTRY_INTEGRITY", "TENANT", "SIGNING_DIR", "NAMESERVER_CAPTURE", "NAMESERVER_RESOLVER", "REGISTRANT_FINGERPRINT_KEY_FILE",
+		"REDACTION_POLICY", "TYPOSQUAT_WATCHLIST", "TYPOSQUAT_THRESHOLD", "DGA_THRESHOLD",
+		"METADATA_STORE", "ARWEAVE_GATEWAY", "ARWEAVE_WALLET_FILE", "IPFS_PINNERS", "IPFS_PIN_TIMEOUT", "IPFS_API_URL",
+		"PINATA_API_URL", "PINATA_JWT", "WEB3STORAGE_URL", "WEB3STORAGE_TOKEN", "METADATA_TOPIC", "COLLECTION_BRANDING_FILE", "COLLECTION_REGISTRY_ID", "COLLECTION_ZONE_PREFIX",
+		"COLLECTION_NAME_TEMPLATE", "COLLECTION_SYMBOL_TEMPLATE", "CLAIM_KEYS_FILE", "ASSOCIATION_POLICY",
+		"ASSOCIATION_TIMEOUT", "ARCHIVE_STAGING_DIR", "ARCHIVE_S3_ENDPOINT", "ARCHIVE_S3_REGION", "ARCHIVE_S3_ACCESS_KEY_ID",
+		"ARCHIVE_S3_SECRET_ACCESS_KEY", "INTAKE_LISTEN_ADDR", "INTAKE_SPOOL_DIR", "INTAKE_TOKENS", "INTAKE_MAX_EVENTS",
+		"API_KEYS", "API_ADMIN_KEYS", "API_JWT_SECRET", "API_RATE_LIMIT", "API_RATE_BURST",
+		"ACTIVITY_STREAM_URL", "ACTIVITY_STREAM_KEY", "NOTIFY_WEBHOOKS", "NOTIFY_FAILURE_THRESHOLD",
+		"REPORT_EMAIL_TO", "REPORT_EMAIL_FROM", "EMAIL_TRANSPORT", "SMTP_HOST", "SMTP_PORT", "SMTP_USERNAME", "SMTP_PASSWORD",
+		"SES_REGION", "SES_ACCESS_KEY_ID", "SES_SECRET_ACCESS_KEY", "SES_ENDPOINT",
+		"ESCALATION_THRESHOLD", "PAGERDUTY_ROUTING_KEY", "OPSGENIE_API_KEY", "OPSGENIE_API_URL",
+		"REGISTRY_ENCRYPTION_KEY", "REGISTRY_KMS_DATA_KEY", "KMS_REGION", "KMS_ACCESS_KEY_ID", "KMS_SECRET_ACCESS_KEY", "KMS_ENDPOINT",
+		"ZONE_ALLOWLIST", "ZONE_DENYLIST", "ZONE_POLICY_FILE", "ZONE_SHARD_SIZE", "SDL_PROFILE",
+	} {
+		t.Setenv(key, "")
+	}
+	// Never pick up the config file of the user running the tests
+	t.Setenv("SDL_CONFIG", filepath.Join(t.TempDir(), "missing.yaml"))
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	clearEnv(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultNetwork, cfg.Hedera.Network)
+	assert.Equal(t, "https://testnet.mirrornode.hedera.com/api/v1", cfg.Mirror.BaseURL)
+	assert.Equal(t, filepath.Join(DefaultNetwork, DefaultZoneRegistryFile), cfg.Registry.ZoneFile)
+	assert.Equal(t, filepath.Join(DefaultNetwork, DefaultTopicRegistryFile), cfg.Registry.TopicFile)
+	assert.Equal(t, filepath.Join(DefaultNetwork, DefaultIngestLedgerFile), cfg.Registry.IngestLedgerFile)
+	assert.Equal(t, filepath.Join(DefaultNetwork, DefaultAccountFile), cfg.Registry.AccountFile)
+	assert.Equal(t, filepath.Join(DefaultNetwork, DefaultTopicOffsetFile), cfg.Registry.TopicOffsetFile)
+	assert.Equal(t, DefaultTemporalAddress, cfg.Temporal.Address)
+	assert.Equal(t, DefaultTemporalNamespace, cfg.Temporal.Namespace)
+	assert.Empty(t, cfg.Temporal.Identity)
+	assert.False(t, cfg.Temporal.TLS())
+	assert.Equal(t, DefaultTaskQueue+"-"+DefaultNetwork, cfg.Temporal.TaskQueue)
+	assert.Equal(t, DefaultWorkerStopTimeout, cfg.Temporal.WorkerStopTimeout)
+	assert.Equal(t, filepath.Join(DefaultNetwork, DefaultReportDir), cfg.Reports.Dir)
+	assert.Empty(t, cfg.HCS.ReceiptsTopic)
+	assert.Equal(t, filepath.Join(DefaultNetwork, DefaultAnchorDir), cfg.Registry.AnchorDir)
+	assert.Equal(t, filepath.Join(DefaultNetwork, DefaultSnapshotDir), cfg.Registry.SnapshotDir)
+	assert.False(t, cfg.HCS.Anchored("build"))
+	assert.Equal(t, SignaturesOff, cfg.Events.SignatureMode)
+	assert.Empty(t, cfg.Metadata.Store)
+	assert.Equal(t, DefaultArweaveGateway, cfg.Metadata.ArweaveGateway)
+	assert.Equal(t, AssociationWait, cfg.Transfers.AssociationPolicy)
+	assert.Equal(t, DefaultAssociationTimeout, cfg.Transfers.AssociationTimeout)
+	assert.Zero(t, cfg.Limits.TransactionsPerSecond)
+	assert.Equal(t, DefaultMaxMintsPerRun, cfg.Limits.MaxMintsPerRun)
+	assert.ErrorIs(t, cfg.RequireOperator(), ErrMissingOperator)
+}
+
+func TestLoad_FromEnv(t *testing.T) {
+	clearEnv(t)
+	key, err := hedera.PrivateKeyGenerateEd25519()
+	require.NoError(t, err)
+
+	t.Setenv("HEDERA_NETWORK", "MAINNET")
+	t.Setenv("HEDERA_ACCOUNT_ID", "0.0.5332375")
+	t.Setenv("HEDERA_PRIVATE_KEY", key.String())
+	t.Setenv("HEDERA_TPS", "2.5")
+	t.Setenv("TEMPORAL_ADDRESS", "temporal.internal:7233")
+	t.Setenv("TEMPORAL_NAMESPACE", "sdl-prod")
+	t.Setenv("TEMPORAL_IDENTITY", "worker-1")
+	t.Setenv("TEMPORAL_TASK_QUEUE", "custom-queue")
+	t.Setenv("WORKER_STOP_TIMEOUT", "2m")
+	t.Setenv("TEMPORAL_SHARDED_ZONES", "build, DEV,,")
+	t.Setenv("HCS_RECEIPTS_TOPIC", "mint-receipts")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "mainnet", cfg.Hedera.Network)
+	assert.Equal(t, "https://mainnet-public.mirrornode.hedera.com/api/v1", cfg.Mirror.BaseURL)
+	assert.Equal(t, 2.5, cfg.Limits.TransactionsPerSecond)
+	assert.Equal(t, "temporal.internal:7233", cfg.Temporal.Address)
+	assert.Equal(t, "sdl-prod", cfg.Temporal.Namespace)
+	assert.Equal(t, "worker-1", cfg.Temporal.Identity)
+	assert.Equal(t, "custom-queue-mainnet", cfg.Temporal.TaskQueue)
+	assert.Equal(t, 2*time.Minute, cfg.Temporal.WorkerStopTimeout)
+	assert.Equal(t, []string{"build", "dev"}, cfg.Temporal.ShardedZones)
+	assert.Equal(t, "mint-receipts", cfg.HCS.ReceiptsTopic)
+	assert.NoError(t, cfg.RequireOperator())
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("HEDERA_TPS", "fast")
+	t.Setenv("WORKER_STOP_TIMEOUT", "soon")
+	t.Setenv("ASSOCIATION_TIMEOUT", "later")
+	_, err := Load()
+	assert.ErrorContains(t, err, "HEDERA_TPS")
+	assert.ErrorContains(t, err, "WORKER_STOP_TIMEOUT")
+	assert.ErrorContains(t, err, "ASSOCIATION_TIMEOUT")
+
+	clearEnv(t)
+	t.Setenv("HEDERA_NETWORK", "devnet")
+	t.Setenv("HEDERA_ACCOUNT_ID", "not-an-account")
+	t.Setenv("HEDERA_PRIVATE_KEY", "garbage")
+	t.Setenv("TEMPORAL_ADDRESS", "temporal.internal")
+	t.Setenv("TEMPORAL_TLS_CERT", "client.pem")
+	t.Setenv("ASSOCIATION_POLICY", "pray")
+	_, err = Load()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "HEDERA_NETWORK")
+	assert.ErrorContains(t, err, "HEDERA_ACCOUNT_ID")
+	assert.ErrorContains(t, err, "HEDERA_PRIVATE_KEY")
+	assert.ErrorContains(t, err, "MIRROR_NODE_URL")
+	assert.ErrorContains(t, err, "TEMPORAL_ADDRESS")
+	assert.ErrorContains(t, err, "TEMPORAL_TLS_KEY")
+	assert.ErrorContains(t, err, "ASSOCIATION_POLICY")
+}
+
+func TestLoad_TemporalCloud(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("TEMPORAL_ADDRESS", "sdl.a1b2c.tmprl.cloud:7233")
+	t.Setenv("TEMPORAL_API_KEY", "secret")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.Temporal.TLS())
+
+	// API key and mTLS are alternatives
+	t.Setenv("TEMPORAL_TLS_CERT", "client.pem")
+	t.Setenv("TEMPORAL_TLS_KEY", "client.key")
+	_, err = Load()
+	assert.ErrorContains(t, err, "TEMPORAL_API_KEY")
+}
+
+func TestLoad_RegistryIntegrity(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, IntegrityOff, cfg.Registry.Integrity)
+
+	t.Setenv("REGISTRY_INTEGRITY", "Enforce")
+	_, err = Load()
+	assert.ErrorContains(t, err, "REGISTRY_INTEGRITY: enforce requires HEDERA_PRIVATE_KEY")
+
+	key, err := hedera.PrivateKeyGenerateEd25519()
+	require.NoError(t, err)
+	t.Setenv("HEDERA_PRIVATE_KEY", key.String())
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, IntegrityEnforce, cfg.Registry.Integrity)
+
+	t.Setenv("REGISTRY_INTEGRITY", "paranoid")
+	_, err = Load()
+	assert.ErrorContains(t, err, "unknown mode")
+}
+
+func TestLoad_OfflineSigning(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, SigningOnline, cfg.Hedera.Signing)
+	assert.Equal(t, filepath.Join(DefaultNetwork, DefaultSigningDir), cfg.Registry.SigningDir)
+	assert.Equal(t, DefaultSigningDelay, cfg.Hedera.SigningDelay)
+
+	t.Setenv("HEDERA_SIGNING", "Offline")
+	_, err = Load()
+	assert.ErrorContains(t, err, "offline requires HEDERA_ACCOUNT_ID")
+	assert.ErrorContains(t, err, "offline requires HEDERA_PUBLIC_KEY")
+
+	// The worker only needs the public key of the operator key
+	key, err := hedera.PrivateKeyGenerateEd25519()
+	require.NoError(t, err)
+	t.Setenv("HEDERA_ACCOUNT_ID", "0.0.1234")
+	t.Setenv("HEDERA_PUBLIC_KEY", key.PublicKey().String())
+	t.Setenv("HEDERA_SIGNING_DELAY", "30m")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, SigningOffline, cfg.Hedera.Signing)
+	assert.Equal(t, 30*time.Minute, cfg.Hedera.SigningDelay)
+
+	other, err := hedera.PrivateKeyGenerateEd25519()
+	require.NoError(t, err)
+	t.Setenv("HEDERA_PRIVATE_KEY", other.String())
+	_, err = Load()
+	assert.ErrorContains(t, err, "not the public key of HEDERA_PRIVATE_KEY")
+
+	t.Setenv("HEDERA_PRIVATE_KEY", "")
+	t.Setenv("HEDERA_SIGNING", "cold")
+	_, err = Load()
+	assert.ErrorContains(t, err, "unknown mode")
+}
+
+func TestLoad_AdminSigner(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.Hedera.AdminSigner())
+
+	t.Setenv("HEDERA_ADMIN_SIGNER_COMMAND", "ledger-sign --account 0")
+	_, err = Load()
+	assert.ErrorContains(t, err, "HEDERA_ADMIN_KEY: required with an admin signer")
+
+	key, err := hedera.PrivateKeyGenerateEd25519()
+	require.NoError(t, err)
+	t.Setenv("HEDERA_ADMIN_KEY", key.PublicKey().String())
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.Hedera.AdminSigner())
+	assert.Equal(t, []string{"ledger-sign", "--account", "0"}, cfg.Hedera.AdminSignerCommand)
+
+	t.Setenv("HEDERA_ADMIN_SIGNER_URL", "signer.internal")
+	_, err = Load()
+	assert.ErrorContains(t, err, "are exclusive")
+	assert.ErrorContains(t, err, "not an absolute URL")
+
+	t.Setenv("HEDERA_ADMIN_SIGNER_COMMAND", "")
+	t.Setenv("HEDERA_ADMIN_SIGNER_URL", "https://signer.internal/sign")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "https://signer.internal/sign", cfg.Hedera.AdminSignerURL)
+
+	t.Setenv("HEDERA_ADMIN_SIGNER_URL", "")
+	_, err = Load()
+	assert.ErrorContains(t, err, "requires HEDERA_ADMIN_SIGNER_URL or HEDERA_ADMIN_SIGNER_COMMAND")
+}
+
+func TestLoad_Anchoring(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("HCS_ANCHOR_TOPIC", "merkle-anchors")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.HCS.Anchored("build"), "all zones are anchored when none are listed")
+
+	t.Setenv("HCS_ANCHORED_ZONES", "Build")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.HCS.Anchored("build"))
+	assert.False(t, cfg.HCS.Anchored("dev"))
+
+	t.Setenv("HCS_ANCHOR_TOPIC", "")
+	_, err = Load()
+	assert.ErrorContains(t, err, "HCS_ANCHORED_ZONES")
+}
+
+func TestLoad_RegistryTopic(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("HCS_REGISTRY_TOPIC", "0.0.4711")
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "0.0.4711", cfg.HCS.RegistryTopic)
+
+	t.Setenv("HCS_REGISTRY_TOPIC", "topic-registry")
+	_, err = Load()
+	assert.ErrorContains(t, err, "HCS_REGISTRY_TOPIC")
+}
+
+func TestLoad_Producer(t *testing.T) {
+	clearEnv(t)
+	key, err := hedera.PrivateKeyGenerateEd25519()
+	require.NoError(t, err)
+	t.Setenv("HCS_PRODUCER_KEY", key.String())
+	_, err = Load()
+	assert.ErrorContains(t, err, "HCS_PRODUCER_ID")
+
+	t.Setenv("TEMPORAL_IDENTITY", "worker-1")
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "worker-1", cfg.HCS.ProducerID)
+
+	t.Setenv("HCS_PRODUCER_ID", "ingest-eu")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "ingest-eu", cfg.HCS.ProducerID)
+
+	t.Setenv("HCS_PRODUCER_KEY", "garbage")
+	t.Setenv("HCS_SUBMIT_KEY", "garbage")
+	_, err = Load()
+	assert.ErrorContains(t, err, "HCS_PRODUCER_KEY")
+	assert.ErrorContains(t, err, "HCS_SUBMIT_KEY")
+}
+
+func TestLoad_Archive(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(DefaultNetwork, DefaultArchiveStagingDir), cfg.Archive.StagingDir)
+	assert.Equal(t, DefaultArchiveS3Region, cfg.Archive.S3Region)
+	assert.Empty(t, cfg.Archive.S3Endpoint)
+
+	t.Setenv("ARCHIVE_S3_ENDPOINT", "http://minio:9000/")
+	t.Setenv("ARCHIVE_S3_ACCESS_KEY_ID", "minio")
+	_, err = Load()
+	assert.ErrorContains(t, err, "ARCHIVE_S3_SECRET_ACCESS_KEY")
+
+	t.Setenv("ARCHIVE_S3_SECRET_ACCESS_KEY", "secret")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "http://minio:9000", cfg.Archive.S3Endpoint)
+
+	t.Setenv("ARCHIVE_S3_ENDPOINT", "minio:9000")
+	_, err = Load()
+	assert.ErrorContains(t, err, "ARCHIVE_S3_ENDPOINT")
+}
+
+func TestLoad_Intake(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultIntakeListenAddr, cfg.Intake.ListenAddr)
+	assert.Equal(t, DefaultIntakeMaxEvents, cfg.Intake.MaxEvents)
+	assert.Empty(t, cfg.Intake.Tokens)
+
+	t.Setenv("INTAKE_TOKENS", "registry-a = s3cret, registry-b=other")
+	t.Setenv("INTAKE_MAX_EVENTS", "50")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"registry-a": "s3cret", "registry-b": "other"}, cfg.Intake.Tokens)
+	assert.Equal(t, 50, cfg.Intake.MaxEvents)
+
+	for value, problem := range map[string]string{
+		"registry-a":                "name=token",
+		"registry-a=x,registry-a=y": "listed twice",
+		"registry-a=x,registry-b=x": "same token",
+	} {
+		t.Setenv("INTAKE_TOKENS", value)
+		_, err = Load()
+		assert.ErrorContains(t, err, problem, value)
+	}
+
+	t.Setenv("INTAKE_TOKENS", "")
+	t.Setenv("INTAKE_MAX_EVENTS", "0")
+	_, err = Load()
+	assert.ErrorContains(t, err, "INTAKE_MAX_EVENTS")
+	t.Setenv("INTAKE_MAX_EVENTS", "many")
+	_, err = Load()
+	assert.ErrorContains(t, err, "not an integer")
+}
+
+func TestLoad_API(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.API.Keys)
+	assert.Equal(t, DefaultAPIRateLimit, cfg.API.RateLimit)
+	assert.Equal(t, DefaultAPIRateBurst, cfg.API.RateBurst)
+
+	t.Setenv("API_KEYS", "registrar-a=read-key")
+	t.Setenv("API_ADMIN_KEYS", "ops=admin-key")
+	t.Setenv("API_RATE_LIMIT", "0")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"registrar-a": "read-key"}, cfg.API.Keys)
+	assert.Equal(t, map[string]string{"ops": "admin-key"}, cfg.API.AdminKeys)
+	assert.Zero(t, cfg.API.RateLimit)
+
+	for key, value := range map[string]string{
+		"API_ADMIN_KEYS":      "registrar-a=other-key",
+		"API_JWT_SECRET":      "too-short",
+		"API_RATE_LIMIT":      "-1",
+		"API_RATE_BURST":      "0",
+		"ACTIVITY_STREAM_URL": "api:8080",
+	} {
+		t.Setenv(key, value)
+		_, err = Load()
+		assert.ErrorContains(t, err, key)
+		t.Setenv(key, "")
+	}
+	t.Setenv("API_ADMIN_KEYS", "ops=read-key")
+	_, err = Load()
+	assert.ErrorContains(t, err, "same key")
+
+	t.Setenv("API_ADMIN_KEYS", "")
+	t.Setenv("ACTIVITY_STREAM_URL", "http://api:8080/")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "http://api:8080", cfg.API.StreamURL)
+}
+
+func TestLoad_Notify(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.Notify.Enabled())
+	assert.Equal(t, DefaultFailureThreshold, cfg.Notify.FailureThreshold)
+
+	t.Setenv("NOTIFY_WEBHOOKS", " https://hooks.slack.com/services/T0/B0/AbC, https://discord.com/api/webhooks/1/XyZ ,")
+	t.Setenv("NOTIFY_FAILURE_THRESHOLD", "0")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.Notify.Enabled())
+	assert.Equal(t, []string{"https://hooks.slack.com/services/T0/B0/AbC", "https://discord.com/api/webhooks/1/XyZ"}, cfg.Notify.Webhooks)
+	assert.Zero(t, cfg.Notify.FailureThreshold)
+
+	for key, value := range map[string]string{
+		"NOTIFY_WEBHOOKS":          "hooks.slack.com/services/T0/B0/AbC",
+		"NOTIFY_FAILURE_THRESHOLD": "-1",
+	} {
+		t.Setenv(key, value)
+		_, err = Load()
+		assert.ErrorContains(t, err, key)
+		t.Setenv(key, "")
+	}
+}
+
+func TestLoad_Email(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.Email.Enabled())
+	assert.Equal(t, EmailSMTP, cfg.Email.Transport)
+	assert.Equal(t, DefaultSMTPPort, cfg.Email.SMTPPort)
+
+	t.Setenv("REPORT_EMAIL_TO", "Compliance@Registry.example, ops@registry.example")
+	_, err = Load()
+	assert.ErrorContains(t, err, "REPORT_EMAIL_FROM")
+	assert.ErrorContains(t, err, "SMTP_HOST")
+
+	t.Setenv("REPORT_EMAIL_FROM", "Ledger <ledger@registry.example>")
+	t.Setenv("SMTP_HOST", "smtp.registry.example")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.Email.Enabled())
+	assert.Equal(t, []string{"compliance@registry.example", "ops@registry.example"}, cfg.Email.To)
+
+	t.Setenv("EMAIL_TRANSPORT", "SES")
+	_, err = Load()
+	assert.ErrorContains(t, err, "SES_ACCESS_KEY_ID")
+	t.Setenv("SES_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("SES_SECRET_ACCESS_KEY", "secret")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, EmailSES, cfg.Email.Transport)
+	assert.Equal(t, DefaultSESRegion, cfg.Email.SESRegion)
+
+	t.Setenv("EMAIL_TRANSPORT", "pigeon")
+	_, err = Load()
+	assert.ErrorContains(t, err, "EMAIL_TRANSPORT")
+}
+
+func TestLoad_Escalation(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultEscalationAfter, cfg.Escalation.After)
+	assert.False(t, cfg.Escalation.Enabled())
+	assert.Zero(t, cfg.Escalation.Threshold(), "no on-call service")
+
+	t.Setenv("OPSGENIE_API_KEY", "api-key")
+	t.Setenv("OPSGENIE_API_URL", "https://api.eu.opsgenie.com/")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultEscalationAfter, cfg.Escalation.Threshold())
+	assert.Equal(t, "https://api.eu.opsgenie.com", cfg.Escalation.OpsgenieURL)
+
+	t.Setenv("ESCALATION_THRESHOLD", "0")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Zero(t, cfg.Escalation.Threshold())
+
+	for key, value := range map[string]string{
+		"ESCALATION_THRESHOLD": "-1",
+		"OPSGENIE_API_URL":     "api.opsgenie.com",
+	} {
+		t.Setenv(key, value)
+		_, err = Load()
+		assert.ErrorContains(t, err, key)
+		t.Setenv(key, "")
+	}
+}
+
+func TestLoad_MaxMintsPerRun(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("MAX_MINTS_PER_RUN", "0")
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Zero(t, cfg.Limits.MaxMintsPerRun, "0 disables the cap")
+
+	t.Setenv("MAX_MINTS_PER_RUN", "-1")
+	_, err = Load()
+	assert.ErrorContains(t, err, "MAX_MINTS_PER_RUN")
+	t.Setenv("MAX_MINTS_PER_RUN", "lots")
+	_, err = Load()
+	assert.ErrorContains(t, err, "not an integer")
+}
+
+func TestLoad_RunBudget(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Zero(t, cfg.Limits.BudgetTinybar(), "runs are unlimited by default")
+
+	t.Setenv("RUN_BUDGET_HBAR", "12.5")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1_250_000_000), cfg.Limits.BudgetTinybar())
+
+	t.Setenv("RUN_BUDGET_HBAR", "-1")
+	_, err = Load()
+	assert.ErrorContains(t, err, "RUN_BUDGET_HBAR")
+
+	t.Setenv("RUN_BUDGET_HBAR", "")
+	t.Setenv("RUN_BUDGET_USD", "20")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 20.0, cfg.Limits.BudgetUSD)
+	assert.Zero(t, cfg.Limits.BudgetTinybar(), "a budget in USD is converted by the run")
+
+	t.Setenv("RUN_BUDGET_HBAR", "100")
+	_, err = Load()
+	assert.ErrorContains(t, err, "only one can be set")
+}
+
+func TestLoad_SignatureMode(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("EVENT_SIGNATURE_MODE", "Strict")
+	_, err := Load()
+	assert.ErrorContains(t, err, "EVENT_KEYS_FILE")
+
+	t.Setenv("EVENT_KEYS_FILE", "registry-keys.json")
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, SignaturesStrict, cfg.Events.SignatureMode)
+
+	t.Setenv("EVENT_SIGNATURE_MODE", "sometimes")
+	_, err = Load()
+	assert.ErrorContains(t, err, "EVENT_SIGNATURE_MODE")
+}
+
+func TestLoad_UnknownSchema(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, UnknownSchemaReject, cfg.Events.UnknownSchema)
+	assert.Equal(t, filepath.Join(DefaultNetwork, DefaultQuarantineDir), cfg.Registry.QuarantineDir)
+	assert.Equal(t, filepath.Join(DefaultNetwork, DefaultTransactionDir), cfg.Registry.TransactionDir)
+
+	t.Setenv("EVENT_UNKNOWN_SCHEMA", "Quarantine")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, UnknownSchemaQuarantine, cfg.Events.UnknownSchema)
+
+	t.Setenv("EVENT_UNKNOWN_SCHEMA", "ignore")
+	_, err = Load()
+	assert.ErrorContains(t, err, "EVENT_UNKNOWN_SCHEMA")
+}
+
+func TestLoad_Nameservers(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, NameserversOff, cfg.Events.Nameservers)
+
+	t.Setenv("NAMESERVER_CAPTURE", "DNS")
+	t.Setenv("NAMESERVER_RESOLVER", "9.9.9.9:53")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, NameserversDNS, cfg.Events.Nameservers)
+	assert.Equal(t, "9.9.9.9:53", cfg.Events.NSResolver)
+
+	t.Setenv("NAMESERVER_CAPTURE", "whois")
+	t.Setenv("NAMESERVER_RESOLVER", "9.9.9.9")
+	_, err = Load()
+	assert.ErrorContains(t, err, "NAMESERVER_CAPTURE")
+	assert.ErrorContains(t, err, "NAMESERVER_RESOLVER")
+}
+
+func TestLoad_Typosquat(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Events.Watchlist)
+	assert.Equal(t, DefaultTyposquatThreshold, cfg.Events.TyposquatThreshold)
+
+	t.Setenv("TYPOSQUAT_WATCHLIST", "PayPal, example")
+	t.Setenv("TYPOSQUAT_THRESHOLD", "0.9")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"paypal", "example"}, cfg.Events.Watchlist)
+	assert.Equal(t, 0.9, cfg.Events.TyposquatThreshold)
+
+	t.Setenv("TYPOSQUAT_WATCHLIST", "pay.pal")
+	t.Setenv("TYPOSQUAT_THRESHOLD", "1.5")
+	_, err = Load()
+	assert.ErrorContains(t, err, "TYPOSQUAT_WATCHLIST")
+	assert.ErrorContains(t, err, "TYPOSQUAT_THRESHOLD")
+}
+
+func TestLoad_DGAThreshold(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Zero(t, cfg.Events.DGAThreshold)
+
+	t.Setenv("DGA_THRESHOLD", "0.7")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 0.7, cfg.Events.DGAThreshold)
+
+	t.Setenv("DGA_THRESHOLD", "-0.1")
+	_, err = Load()
+	assert.ErrorContains(t, err, "DGA_THRESHOLD")
+}
+
+func TestLoad_Redaction(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("REDACTION_POLICY", "registrant=strip,nameservers=hash")
+	_, err := Load()
+	assert.ErrorContains(t, err, "REGISTRANT_FINGERPRINT_KEY_FILE")
+
+	t.Setenv("REGISTRANT_FINGERPRINT_KEY_FILE", "fingerprint.key")
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, redact.ActionStrip, cfg.Events.Redaction.Action(redact.FieldRegistrant))
+	assert.Equal(t, redact.ActionHash, cfg.Events.Redaction.Action(redact.FieldNameservers))
+
+	t.Setenv("REDACTION_POLICY", "email=strip")
+	_, err = Load()
+	assert.ErrorContains(t, err, "REDACTION_POLICY")
+}
+
+func TestLoad_RegistryScope(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("testnet", DefaultZoneRegistryFile), cfg.Registry.ZoneFile)
+	assert.Equal(t, "testnet", cfg.Registry.StoreSchema)
+	assert.Equal(t, "testnet", cfg.Temporal.WorkflowIDScope)
+
+	t.Setenv("HEDERA_NETWORK", "mainnet")
+	t.Setenv("TENANT", "Acme")
+	t.Setenv("QUARANTINE_DIR", "/var/lib/sdl/quarantine")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "acme", cfg.Registry.Tenant)
+	assert.Equal(t, filepath.Join("mainnet", "acme", DefaultZoneRegistryFile), cfg.Registry.ZoneFile)
+	assert.Equal(t, filepath.Join("mainnet", "acme", DefaultReportDir), cfg.Reports.Dir)
+	assert.Equal(t, "/var/lib/sdl/mainnet/acme/quarantine", cfg.Registry.QuarantineDir)
+	assert.Equal(t, "mainnet_acme", cfg.Registry.StoreSchema)
+	assert.Equal(t, DefaultTaskQueue+"-mainnet-acme", cfg.Temporal.TaskQueue)
+	assert.Equal(t, "mainnet/acme", cfg.Temporal.WorkflowIDScope)
+
+	t.Setenv("TENANT", "acme/../other")
+	_, err = Load()
+	assert.ErrorContains(t, err, "TENANT")
+}
+
+func TestCheckUnscoped(t *testing.T) {
+	clearEnv(t)
+	dir := t.TempDir()
+	t.Setenv("ZONE_REGISTRY_FILE", filepath.Join(dir, "zones.json"))
+	t.Setenv("ANCHOR_DIR", filepath.Join(dir, "anchors"))
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.NoError(t, cfg.CheckUnscoped())
+
+	// A registry file of before the scoping is refused until moved
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "zones.json"), []byte("{}"), 0644))
+	err = cfg.CheckUnscoped()
+	assert.ErrorContains(t, err, "move it to "+filepath.Join(dir, "testnet", "zones.json"))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "testnet"), 0755))
+	require.NoError(t, os.Rename(filepath.Join(dir, "zones.json"), filepath.Join(dir, "testnet", "zones.json")))
+	require.NoError(t, cfg.CheckUnscoped())
+
+	// Tenants have no files of before the scoping
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "anchors"), 0755))
+	assert.ErrorContains(t, cfg.CheckUnscoped(), "anchors")
+	t.Setenv("TENANT", "acme")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.NoError(t, cfg.CheckUnscoped())
+}
+
+func TestLoad_ContactTopic(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("HCS_CONTACT_TOPIC", "contact-events")
+	_, err := Load()
+	assert.ErrorContains(t, err, "HCS_CONTACT_TOPIC")
+
+	t.Setenv("REGISTRANT_FINGERPRINT_KEY_FILE", "fingerprint.key")
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "contact-events", cfg.HCS.ContactTopic)
+}
+
+func TestLoad_MetadataStore(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("METADATA_STORE", "Arweave")
+	_, err := Load()
+	assert.ErrorContains(t, err, "ARWEAVE_WALLET_FILE")
+
+	t.Setenv("ARWEAVE_WALLET_FILE", "arweave-wallet.json")
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, MetadataStoreArweave, cfg.Metadata.Store)
+
+	t.Setenv("METADATA_STORE", "ipfs")
+	_, err = Load()
+	assert.ErrorContains(t, err, "IPFS_PINNERS")
+
+	t.Setenv("IPFS_PINNERS", "web3storage")
+	t.Setenv("WEB3STORAGE_TOKEN", "token")
+	_, err = Load()
+	assert.ErrorContains(t, err, "requires pinata or kubo")
+
+	t.Setenv("IPFS_PINNERS", "kubo,web3storage")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{PinnerKubo, PinnerWeb3Storage}, cfg.Metadata.IPFSPinners)
+	assert.Equal(t, DefaultIPFSPinTimeout, cfg.Metadata.IPFSPinTimeout)
+
+	t.Setenv("IPFS_PINNERS", "pinata,filecoin")
+	_, err = Load()
+	assert.ErrorContains(t, err, "PINATA_JWT")
+	assert.ErrorContains(t, err, "filecoin")
+
+	t.Setenv("METADATA_STORE", "HCS")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, MetadataStoreHCS, cfg.Metadata.Store)
+	assert.Equal(t, DefaultMetadataTopic, cfg.Metadata.Topic)
+
+	t.Setenv("METADATA_STORE", "floppy")
+	_, err = Load()
+	assert.ErrorContains(t, err, "METADATA_STORE")
+
+	t.Setenv("METADATA_STORE", "")
+	t.Setenv("COLLECTION_BRANDING_FILE", "branding.yaml")
+	_, err = Load()
+	assert.ErrorContains(t, err, "COLLECTION_BRANDING_FILE: requires METADATA_STORE")
+}
+
+func TestLoad_CollectionNaming(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	n, err := cfg.Metadata.Naming()
+	require.NoError(t, err)
+	symbol, err := n.Symbol("build")
+	require.NoError(t, err)
+	assert.Equal(t, "APEX-ZONE.BUILD", symbol)
+
+	t.Setenv("COLLECTION_REGISTRY_ID", "acme")
+	t.Setenv("COLLECTION_SYMBOL_TEMPLATE", "{{upper .Registry}}.{{upper .Zone}}")
+	cfg, err = Load()
+	require.NoError(t, err)
+	n, err = cfg.Metadata.Naming()
+	require.NoError(t, err)
+	symbol, err = n.Symbol("build")
+	require.NoError(t, err)
+	assert.Equal(t, "ACME.BUILD", symbol)
+
+	t.Setenv("COLLECTION_SYMBOL_TEMPLATE", "{{.Registry}} {{.Zone}}")
+	_, err = Load()
+	assert.ErrorContains(t, err, "COLLECTION_NAME_TEMPLATE and COLLECTION_SYMBOL_TEMPLATE: symbol of .example")
+}
+
+func TestLoad_Zones(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.Zones.Allowed("build"))
+
+	t.Setenv("ZONE_ALLOWLIST", ".Build, shop")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"build", "shop"}, cfg.Zones.Allowlist)
+	assert.True(t, cfg.Zones.Allowed(".BUILD"))
+	assert.False(t, cfg.Zones.Allowed("bogus"))
+
+	t.Setenv("ZONE_ALLOWLIST", "")
+	t.Setenv("ZONE_DENYLIST", "test")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.Zones.Allowed("build"))
+	assert.False(t, cfg.Zones.Allowed("test"))
+
+	t.Setenv("ZONE_ALLOWLIST", "build")
+	_, err = Load()
+	assert.ErrorContains(t, err, "ZONE_ALLOWLIST and ZONE_DENYLIST: set one or the other")
+}
+
+func TestLoad_ZoneShardSize(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Zero(t, cfg.Zones.ShardSize, "zone collections are not sharded by default")
+
+	t.Setenv("ZONE_SHARD_SIZE", "1000000")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 1000000, cfg.Zones.ShardSize)
+
+	t.Setenv("ZONE_SHARD_SIZE", "-1")
+	_, err = Load()
+	assert.ErrorContains(t, err, "ZONE_SHARD_SIZE: must not be negative")
+}
+
+func TestLoad_MirrorNode(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("HEDERA_NETWORK", "mainnet")
+	t.Setenv("MIRROR_NODE_URL_MAINNET", "https://mirror.internal/api/v1/")
+	t.Setenv("MIRROR_NODE_URL_TESTNET", "https://testnet-mirror.internal/api/v1")
+	t.Setenv("MIRROR_NODE_GRPC", "mirror.internal:5600")
+	t.Setenv("MIRROR_NODE_API_KEY", "secret")
+	t.Setenv("MIRROR_NODE_HEADERS", "x-tenant: sdl, Authorization: Basic c2RsOnNkbA==")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "https://mirror.internal/api/v1", cfg.Mirror.BaseURL)
+	assert.Equal(t, "mirror.internal:5600", cfg.Mirror.GRPCAddress)
+	header := cfg.Mirror.Header()
+	assert.Equal(t, "secret", header.Get("X-Api-Key"))
+	assert.Equal(t, "sdl", header.Get("X-Tenant"))
+	assert.Equal(t, "Basic c2RsOnNkbA==", header.Get("Authorization"))
+	assert.Zero(t, cfg.Mirror.VisibilityTimeout, "mints are not checked on the mirror node by default")
+
+	// MIRROR_NODE_URL wins over the URL of the network
+	t.Setenv("MIRROR_NODE_URL", "http://localhost:5551/api/v1")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:5551/api/v1", cfg.Mirror.BaseURL)
+
+	t.Setenv("MIRROR_VISIBILITY_TIMEOUT", "30s")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, cfg.Mirror.VisibilityTimeout)
+
+	t.Setenv("MIRROR_VISIBILITY_TIMEOUT", "-1s")
+	_, err = Load()
+	assert.ErrorContains(t, err, "MIRROR_VISIBILITY_TIMEOUT: must not be negative")
+
+	t.Setenv("MIRROR_NODE_GRPC", "mirror.internal")
+	_, err = Load()
+	assert.ErrorContains(t, err, "MIRROR_NODE_GRPC")
+
+	t.Setenv("MIRROR_NODE_HEADERS", "x-tenant sdl")
+	_, err = Load()
+	assert.ErrorContains(t, err, "MIRROR_NODE_HEADERS")
+}
+
+func TestParseList(t *testing.T) {
+	assert.Nil(t, ParseList(""))
+	assert.Nil(t, ParseList(" , "))
+	assert.Equal(t, []string{"build", "app"}, ParseList("Build,app"))
+}
+
+func TestLoad_ReportBucket(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Reports.Bucket)
+
+	t.Setenv("REPORT_BUCKET", "gs://registry-reports/runs/")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "gs://registry-reports/runs", cfg.Reports.Bucket)
+
+	t.Setenv("REPORT_BUCKET", "registry-reports")
+	_, err = Load()
+	assert.ErrorContains(t, err, "REPORT_BUCKET")
+}
+
+func TestLoad_Encryption(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.Encryption.Enabled())
+
+	t.Setenv("REGISTRY_ENCRYPTION_KEY", "0011")
+	_, err = Load()
+	assert.ErrorContains(t, err, "REGISTRY_ENCRYPTION_KEY")
+	t.Setenv("REGISTRY_ENCRYPTION_KEY", "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.Encryption.Enabled())
+
+	t.Setenv("REGISTRY_KMS_DATA_KEY", "AQIDBA==")
+	_, err = Load()
+	assert.ErrorContains(t, err, "only one of them")
+	t.Setenv("REGISTRY_ENCRYPTION_KEY", "")
+	_, err = Load()
+	assert.ErrorContains(t, err, "KMS_ACCESS_KEY_ID")
+	t.Setenv("KMS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("KMS_SECRET_ACCESS_KEY", "secret")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultKMSRegion, cfg.Encryption.KMSRegion)
+}
@@ -0,0 +1,430 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigFile is the config file location relative to the home directory
+const DefaultConfigFile = ".sdl/config.yaml"
+
+var (
+	ErrProfileNotFound = errors.New("profile not found")
+)
+
+// File is the CLI config file holding named profiles, e.g. ~/.sdl/config.yaml:
+//
+//	default_profile: testnet-dev
+//	profiles:
+//	  testnet-dev:
+//	    hedera:
+//	      network: testnet
+//	    temporal:
+//	      address: localhost:7233
+//	    flags:
+//	      tail:
+//	        interval: 5s
+type File struct {
+	DefaultProfile string             `yaml:"default_profile"`
+	Profiles       map[string]Profile `yaml:"profiles"`
+}
+
+// Profile bundles the settings of one environment. Every field corresponds to an environment
+// variable, which takes precedence over the profile when set.
+type Profile struct {
+	Hedera struct {
+		Network      string `yaml:"network"`
+		AccountID    string `yaml:"account_id"`
+		PrivateKey   string `yaml:"private_key"`
+		PublicKey    string `yaml:"public_key"`
+		Signing      string `yaml:"signing"`
+		SigningDelay string `yaml:"signing_delay"`
+
+		AdminKey           string `yaml:"admin_key"`
+		AdminSignerURL     string `yaml:"admin_signer_url"`
+		AdminSignerToken   string `yaml:"admin_signer_token"`
+		AdminSignerCommand string `yaml:"admin_signer_command"`
+	} `yaml:"hedera"`
+	Mirror struct {
+		URL          string `yaml:"url"`
+		GRPC         string `yaml:"grpc"`
+		APIKey       string `yaml:"api_key"`
+		APIKeyHeader string `yaml:"api_key_header"`
+		Headers      string `yaml:"headers"`
+
+		VisibilityTimeout string `yaml:"visibility_timeout"`
+	} `yaml:"mirror"`
+	Registry struct {
+		StoreDSN         string `yaml:"store_dsn"`
+		ZoneFile         string `yaml:"zone_file"`
+		TopicFile        string `yaml:"topic_file"`
+		IngestLedgerFile string `yaml:"ingest_ledger_file"`
+		AccountFile      string `yaml:"account_file"`
+		TopicOffsetFile  string `yaml:"topic_offset_file"`
+		AnchorDir        string `yaml:"anchor_dir"`
+		SnapshotDir      string `yaml:"snapshot_dir"`
+		QuarantineDir    string `yaml:"quarantine_dir"`
+		TransactionDir   string `yaml:"transaction_record_dir"`
+		Integrity        string `yaml:"integrity"`
+		SigningDir       string `yaml:"signing_dir"`
+		Tenant           string `yaml:"tenant"`
+	} `yaml:"registry"`
+	Limits struct {
+		TransactionsPerSecond   string `yaml:"hedera_tps"`
+		MirrorRequestsPerSecond string `yaml:"mirror_rps"`
+		MaxMintsPerRun          string `yaml:"max_mints_per_run"`
+		BudgetHbar              string `yaml:"run_budget_hbar"`
+		BudgetUSD               string `yaml:"run_budget_usd"`
+	} `yaml:"limits"`
+	Temporal struct {
+		Address           string `yaml:"address"`
+		Namespace         string `yaml:"namespace"`
+		Identity          string `yaml:"identity"`
+		APIKey            string `yaml:"api_key"`
+		TLSCertFile       string `yaml:"tls_cert"`
+		TLSKeyFile        string `yaml:"tls_key"`
+		TLSCAFile         string `yaml:"tls_ca"`
+		TLSServerName     string `yaml:"tls_server_name"`
+		TaskQueue         string `yaml:"task_queue"`
+		WorkerStopTimeout string `yaml:"worker_stop_timeout"`
+		ShardedZones      string `yaml:"sharded_zones"`
+	} `yaml:"temporal"`
+	Reports struct {
+		Dir    string `yaml:"dir"`
+		Bucket string `yaml:"bucket"`
+	} `yaml:"reports"`
+	HCS struct {
+		RegistryTopic string `yaml:"registry_topic"`
+		ReceiptsTopic string `yaml:"receipts_topic"`
+		AnchorTopic   string `yaml:"anchor_topic"`
+		AnchoredZones string `yaml:"anchored_zones"`
+		DigestTopic   string `yaml:"digest_topic"`
+		InfraTopic    string `yaml:"infra_topic"`
+		ContactTopic  string `yaml:"contact_topic"`
+		SubmitKey     string `yaml:"submit_key"`
+		ProducerID    string `yaml:"producer_id"`
+		ProducerKey   string `yaml:"producer_key"`
+	} `yaml:"hcs"`
+	Events struct {
+		SignatureMode      string `yaml:"signature_mode"`
+		KeysFile           string `yaml:"keys_file"`
+		UnknownSchema      string `yaml:"unknown_schema"`
+		Nameservers        string `yaml:"nameserver_capture"`
+		NSResolver         string `yaml:"nameserver_resolver"`
+		FingerprintKeyFile string `yaml:"registrant_fingerprint_key_file"`
+		Redaction          string `yaml:"redaction_policy"`
+		Watchlist          string `yaml:"typosquat_watchlist"`
+		TyposquatThreshold string `yaml:"typosquat_threshold"`
+		DGAThreshold       string `yaml:"dga_threshold"`
+	} `yaml:"events"`
+	Metadata struct {
+		Store             string `yaml:"store"`
+		ArweaveGateway    string `yaml:"arweave_gateway"`
+		ArweaveWalletFile string `yaml:"arweave_wallet_file"`
+		IPFSPinners       string `yaml:"ipfs_pinners"`
+		IPFSPinTimeout    string `yaml:"ipfs_pin_timeout"`
+		IPFSAPIURL        string `yaml:"ipfs_api_url"`
+		PinataURL         string `yaml:"pinata_api_url"`
+		PinataJWT         string `yaml:"pinata_jwt"`
+		Web3StorageURL    string `yaml:"web3storage_url"`
+		Web3StorageToken  string `yaml:"web3storage_token"`
+		Topic             string `yaml:"topic"`
+		BrandingFile      string `yaml:"branding_file"`
+		RegistryID        string `yaml:"collection_registry_id"`
+		ZonePrefix        string `yaml:"collection_zone_prefix"`
+		NameTemplate      string `yaml:"collection_name_template"`
+		SymbolTemplate    string `yaml:"collection_symbol_template"`
+	} `yaml:"metadata"`
+	Claims struct {
+		KeysFile string `yaml:"keys_file"`
+	} `yaml:"claims"`
+	Transfers struct {
+		AssociationPolicy  string `yaml:"association_policy"`
+		AssociationTimeout string `yaml:"association_timeout"`
+	} `yaml:"transfers"`
+	Archive struct {
+		StagingDir        string `yaml:"staging_dir"`
+		S3Endpoint        string `yaml:"s3_endpoint"`
+		S3Region          string `yaml:"s3_region"`
+		S3AccessKeyID     string `yaml:"s3_access_key_id"`
+		S3SecretAccessKey string `yaml:"s3_secret_access_key"`
+	} `yaml:"archive"`
+	Intake struct {
+		ListenAddr string `yaml:"listen_addr"`
+		SpoolDir   string `yaml:"spool_dir"`
+		Tokens     string `yaml:"tokens"`
+		MaxEvents  string `yaml:"max_events"`
+	} `yaml:"intake"`
+	API struct {
+		Keys      string `yaml:"keys"`
+		AdminKeys string `yaml:"admin_keys"`
+		JWTSecret string `yaml:"jwt_secret"`
+		RateLimit string `yaml:"rate_limit"`
+		RateBurst string `yaml:"rate_burst"`
+		StreamURL string `yaml:"stream_url"`
+		StreamKey string `yaml:"stream_key"`
+	} `yaml:"api"`
+	Notify struct {
+		Webhooks         string `yaml:"webhooks"`
+		FailureThreshold string `yaml:"failure_threshold"`
+	} `yaml:"notify"`
+	Email struct {
+		To                 string `yaml:"to"`
+		From               string `yaml:"from"`
+		Transport          string `yaml:"transport"`
+		SMTPHost           string `yaml:"smtp_host"`
+		SMTPPort           string `yaml:"smtp_port"`
+		SMTPUsername       string `yaml:"smtp_username"`
+		SMTPPassword       string `yaml:"smtp_password"`
+		SESRegion          string `yaml:"ses_region"`
+		SESAccessKeyID     string `yaml:"ses_access_key_id"`
+		SESSecretAccessKey string `yaml:"ses_secret_access_key"`
+		SESEndpoint        string `yaml:"ses_endpoint"`
+	} `yaml:"email"`
+	Escalation struct {
+		Threshold           string `yaml:"threshold"`
+		PagerDutyRoutingKey string `yaml:"pagerduty_routing_key"`
+		OpsgenieAPIKey      string `yaml:"opsgenie_api_key"`
+		OpsgenieURL         string `yaml:"opsgenie_api_url"`
+	} `yaml:"escalation"`
+	Encryption struct {
+		Key                string `yaml:"key"`
+		KMSDataKey         string `yaml:"kms_data_key"`
+		KMSRegion          string `yaml:"kms_region"`
+		KMSAccessKeyID     string `yaml:"kms_access_key_id"`
+		KMSSecretAccessKey string `yaml:"kms_secret_access_key"`
+		KMSEndpoint        string `yaml:"kms_endpoint"`
+	} `yaml:"encryption"`
+	Zones struct {
+		Allowlist string `yaml:"allowlist"`
+		Denylist  string `yaml:"denylist"`
+
+		PolicyFile string `yaml:"policy_file"`
+		ShardSize  string `yaml:"shard_size"`
+	} `yaml:"zones"`
+
+	// Flags holds default CLI flag values by command name, e.g. flags.mintDomains.force
+	Flags map[string]map[string]string `yaml:"flags"`
+}
+
+// env returns the profile settings keyed by the environment variable they stand for
+func (p Profile) env() map[string]string {
+	return map[string]string{
+		"HEDERA_NETWORK":                  p.Hedera.Network,
+		"HEDERA_ACCOUNT_ID":               p.Hedera.AccountID,
+		"HEDERA_PRIVATE_KEY":              p.Hedera.PrivateKey,
+		"HEDERA_PUBLIC_KEY":               p.Hedera.PublicKey,
+		"HEDERA_SIGNING":                  p.Hedera.Signing,
+		"HEDERA_SIGNING_DELAY":            p.Hedera.SigningDelay,
+		"HEDERA_ADMIN_KEY":                p.Hedera.AdminKey,
+		"HEDERA_ADMIN_SIGNER_URL":         p.Hedera.AdminSignerURL,
+		"HEDERA_ADMIN_SIGNER_TOKEN":       p.Hedera.AdminSignerToken,
+		"HEDERA_ADMIN_SIGNER_COMMAND":     p.Hedera.AdminSignerCommand,
+		"MIRROR_NODE_URL":                 p.Mirror.URL,
+		"MIRROR_NODE_GRPC":                p.Mirror.GRPC,
+		"MIRROR_NODE_API_KEY":             p.Mirror.APIKey,
+		"MIRROR_NODE_API_KEY_HEADER":      p.Mirror.APIKeyHeader,
+		"MIRROR_NODE_HEADERS":             p.Mirror.Headers,
+		"MIRROR_VISIBILITY_TIMEOUT":       p.Mirror.VisibilityTimeout,
+		"REGISTRY_STORE_DSN":              p.Registry.StoreDSN,
+		"ZONE_REGISTRY_FILE":              p.Registry.ZoneFile,
+		"TOPIC_REGISTRY_FILE":             p.Registry.TopicFile,
+		"INGEST_LEDGER_FILE":              p.Registry.IngestLedgerFile,
+		"ACCOUNT_REGISTRY_FILE":           p.Registry.AccountFile,
+		"TOPIC_OFFSETS_FILE":              p.Registry.TopicOffsetFile,
+		"HEDERA_TPS":                      p.Limits.TransactionsPerSecond,
+		"MIRROR_RPS":                      p.Limits.MirrorRequestsPerSecond,
+		"MAX_MINTS_PER_RUN":               p.Limits.MaxMintsPerRun,
+		"RUN_BUDGET_HBAR":                 p.Limits.BudgetHbar,
+		"RUN_BUDGET_USD":                  p.Limits.BudgetUSD,
+		"TEMPORAL_ADDRESS":                p.Temporal.Address,
+		"TEMPORAL_NAMESPACE":              p.Temporal.Namespace,
+		"TEMPORAL_IDENTITY":               p.Temporal.Identity,
+		"TEMPORAL_API_KEY":                p.Temporal.APIKey,
+		"TEMPORAL_TLS_CERT":               p.Temporal.TLSCertFile,
+		"TEMPORAL_TLS_KEY":                p.Temporal.TLSKeyFile,
+		"TEMPORAL_TLS_CA":                 p.Temporal.TLSCAFile,
+		"TEMPORAL_TLS_SERVER_NAME":        p.Temporal.TLSServerName,
+		"TEMPORAL_TASK_QUEUE":             p.Temporal.TaskQueue,
+		"WORKER_STOP_TIMEOUT":             p.Temporal.WorkerStopTimeout,
+		"TEMPORAL_SHARDED_ZONES":          p.Temporal.ShardedZones,
+		"REPORT_DIR":                      p.Reports.Dir,
+		"REPORT_BUCKET":                   p.Reports.Bucket,
+		"HCS_REGISTRY_TOPIC":              p.HCS.RegistryTopic,
+		"HCS_RECEIPTS_TOPIC":              p.HCS.ReceiptsTopic,
+		"HCS_ANCHOR_TOPIC":                p.HCS.AnchorTopic,
+		"HCS_DIGEST_TOPIC":                p.HCS.DigestTopic,
+		"HCS_INFRA_TOPIC":                 p.HCS.InfraTopic,
+		"HCS_CONTACT_TOPIC":               p.HCS.ContactTopic,
+		"HCS_ANCHORED_ZONES":              p.HCS.AnchoredZones,
+		"HCS_SUBMIT_KEY":                  p.HCS.SubmitKey,
+		"HCS_PRODUCER_ID":                 p.HCS.ProducerID,
+		"HCS_PRODUCER_KEY":                p.HCS.ProducerKey,
+		"ANCHOR_DIR":                      p.Registry.AnchorDir,
+		"SNAPSHOT_DIR":                    p.Registry.SnapshotDir,
+		"QUARANTINE_DIR":                  p.Registry.QuarantineDir,
+		"TRANSACTION_RECORD_DIR":          p.Registry.TransactionDir,
+		"REGISTRY_INTEGRITY":              p.Registry.Integrity,
+		"SIGNING_DIR":                     p.Registry.SigningDir,
+		"TENANT":                          p.Registry.Tenant,
+		"EVENT_SIGNATURE_MODE":            p.Events.SignatureMode,
+		"EVENT_KEYS_FILE":                 p.Events.KeysFile,
+		"EVENT_UNKNOWN_SCHEMA":            p.Events.UnknownSchema,
+		"NAMESERVER_CAPTURE":              p.Events.Nameservers,
+		"NAMESERVER_RESOLVER":             p.Events.NSResolver,
+		"REGISTRANT_FINGERPRINT_KEY_FILE": p.Events.FingerprintKeyFile,
+		"REDACTION_POLICY":                p.Events.Redaction,
+		"TYPOSQUAT_WATCHLIST":             p.Events.Watchlist,
+		"TYPOSQUAT_THRESHOLD":             p.Events.TyposquatThreshold,
+		"DGA_THRESHOLD":                   p.Events.DGAThreshold,
+		"METADATA_STORE":                  p.Metadata.Store,
+		"ARWEAVE_GATEWAY":                 p.Metadata.ArweaveGateway,
+		"ARWEAVE_WALLET_FILE":             p.Metadata.ArweaveWalletFile,
+		"IPFS_PINNERS":                    p.Metadata.IPFSPinners,
+		"IPFS_PIN_TIMEOUT":                p.Metadata.IPFSPinTimeout,
+		"IPFS_API_URL":                    p.Metadata.IPFSAPIURL,
+		"PINATA_API_URL":                  p.Metadata.PinataURL,
+		"PINATA_JWT":                      p.Metadata.PinataJWT,
+		"WEB3STORAGE_URL":                 p.Metadata.Web3StorageURL,
+		"WEB3STORAGE_TOKEN":               p.Metadata.Web3StorageToken,
+		"METADATA_TOPIC":                  p.Metadata.Topic,
+		"COLLECTION_BRANDING_FILE":        p.Metadata.BrandingFile,
+		"COLLECTION_REGISTRY_ID":          p.Metadata.RegistryID,
+		"COLLECTION_ZONE_PREFIX":          p.Metadata.ZonePrefix,
+		"COLLECTION_NAME_TEMPLATE":        p.Metadata.NameTemplate,
+		"COLLECTION_SYMBOL_TEMPLATE":      p.Metadata.SymbolTemplate,
+		"CLAIM_KEYS_FILE":                 p.Claims.KeysFile,
+		"ASSOCIATION_POLICY":              p.Transfers.AssociationPolicy,
+		"ASSOCIATION_TIMEOUT":             p.Transfers.AssociationTimeout,
+		"ARCHIVE_STAGING_DIR":             p.Archive.StagingDir,
+		"ARCHIVE_S3_ENDPOINT":             p.Archive.S3Endpoint,
+		"ARCHIVE_S3_REGION":               p.Archive.S3Region,
+		"ARCHIVE_S3_ACCESS_KEY_ID":        p.Archive.S3AccessKeyID,
+		"ARCHIVE_S3_SECRET_ACCESS_KEY":    p.Archive.S3SecretAccessKey,
+		"INTAKE_LISTEN_ADDR":              p.Intake.ListenAddr,
+		"INTAKE_SPOOL_DIR":                p.Intake.SpoolDir,
+		"INTAKE_TOKENS":                   p.Intake.Tokens,
+		"INTAKE_MAX_EVENTS":               p.Intake.MaxEvents,
+		"API_KEYS":                        p.API.Keys,
+		"API_ADMIN_KEYS":                  p.API.AdminKeys,
+		"API_JWT_SECRET":                  p.API.JWTSecret,
+		"API_RATE_LIMIT":                  p.API.RateLimit,
+		"API_RATE_BURST":                  p.API.RateBurst,
+		"ACTIVITY_STREAM_URL":             p.API.StreamURL,
+		"ACTIVITY_STREAM_KEY":             p.API.StreamKey,
+		"NOTIFY_WEBHOOKS":                 p.Notify.Webhooks,
+		"NOTIFY_FAILURE_THRESHOLD":        p.Notify.FailureThreshold,
+		"REPORT_EMAIL_TO":                 p.Email.To,
+		"REPORT_EMAIL_FROM":               p.Email.From,
+		"EMAIL_TRANSPORT":                 p.Email.Transport,
+		"SMTP_HOST":                       p.Email.SMTPHost,
+		"SMTP_PORT":                       p.Email.SMTPPort,
+		"SMTP_USERNAME":                   p.Email.SMTPUsername,
+		"SMTP_PASSWORD":                   p.Email.SMTPPassword,
+		"SES_REGION":                      p.Email.SESRegion,
+		"SES_ACCESS_KEY_ID":               p.Email.SESAccessKeyID,
+		"SES_SECRET_ACCESS_KEY":           p.Email.SESSecretAccessKey,
+		"SES_ENDPOINT":                    p.Email.SESEndpoint,
+		"ESCALATION_THRESHOLD":            p.Escalation.Threshold,
+		"PAGERDUTY_ROUTING_KEY":           p.Escalation.PagerDutyRoutingKey,
+		"OPSGENIE_API_KEY":                p.Escalation.OpsgenieAPIKey,
+		"OPSGENIE_API_URL":                p.Escalation.OpsgenieURL,
+		"REGISTRY_ENCRYPTION_KEY":         p.Encryption.Key,
+		"REGISTRY_KMS_DATA_KEY":           p.Encryption.KMSDataKey,
+		"KMS_REGION":                      p.Encryption.KMSRegion,
+		"KMS_ACCESS_KEY_ID":               p.Encryption.KMSAccessKeyID,
+		"KMS_SECRET_ACCESS_KEY":           p.Encryption.KMSSecretAccessKey,
+		"KMS_ENDPOINT":                    p.Encryption.KMSEndpoint,
+		"ZONE_ALLOWLIST":                  p.Zones.Allowlist,
+		"ZONE_DENYLIST":                   p.Zones.Denylist,
+		"ZONE_POLICY_FILE":                p.Zones.PolicyFile,
+		"ZONE_SHARD_SIZE":                 p.Zones.ShardSize,
+	}
+}
+
+// ConfigFilePath returns the location of the config file: SDL_CONFIG if set, otherwise ~/.sdl/config.yaml
+func ConfigFilePath() string {
+	if path := strings.TrimSpace(os.Getenv("SDL_CONFIG")); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, DefaultConfigFile)
+}
+
+// LoadFile reads a config file. A missing file yields an empty File.
+func LoadFile(path string) (*File, error) {
+	file := &File{}
+	if path == "" {
+		return file, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// ProfileNames returns the sorted names of the profiles in the file
+func (f *File) ProfileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FromProfile reads the configuration from the environment, falling back to the named profile of
+// the config file for unset variables, and applies defaults without validating it.
+// An empty name selects SDL_PROFILE, then the default_profile of the file, then no profile at all.
+func FromProfile(name string) (*Config, error) {
+	path := ConfigFilePath()
+	file, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = strings.TrimSpace(os.Getenv("SDL_PROFILE"))
+	}
+	if name == "" {
+		name = file.DefaultProfile
+	}
+
+	var profile Profile
+	if name != "" {
+		var ok bool
+		if profile, ok = file.Profiles[name]; !ok {
+			return nil, fmt.Errorf("%w: %q in %s (available: %s)", ErrProfileNotFound, name, path, strings.Join(file.ProfileNames(), ", "))
+		}
+	}
+
+	profileEnv := profile.env()
+	cfg, err := fromSource(func(key string) string {
+		if v := os.Getenv(key); strings.TrimSpace(v) != "" {
+			return v
+		}
+		return profileEnv[key]
+	})
+	if err != nil {
+		return nil, err
+	}
+	cfg.Profile = name
+	cfg.DefaultFlags = profile.Flags
+	return cfg, nil
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfigFile = `
+default_profile: testnet-dev
+profiles:
+  testnet-dev:
+    hedera:
+      network: testnet
+    temporal:
+      address: localhost:7233
+      task_queue: dev-queue
+    flags:
+      tail:
+        interval: 5s
+  mainnet-prod:
+    hedera:
+      network: mainnet
+    registry:
+      store_dsn: postgres://sdl@db/sdl
+    temporal:
+      address: sdl.a1b2c.tmprl.cloud:7233
+      namespace: sdl.a1b2c
+      worker_stop_timeout: 2m
+`
+
+// writeConfigFile writes a config file and points SDL_CONFIG at it
+func writeConfigFile(t *testing.T, content string) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	t.Setenv("SDL_CONFIG", path)
+}
+
+func TestLoadProfile_Default(t *testing.T) {
+	clearEnv(t)
+	writeConfigFile(t, testConfigFile)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "testnet-dev", cfg.Profile)
+	assert.Equal(t, "dev-queue-testnet", cfg.Temporal.TaskQueue)
+	assert.Equal(t, "5s", cfg.DefaultFlags["tail"]["interval"])
+}
+
+func TestLoadProfile_Named(t *testing.T) {
+	clearEnv(t)
+	writeConfigFile(t, testConfigFile)
+
+	cfg, err := LoadProfile("mainnet-prod")
+	require.NoError(t, err)
+	assert.Equal(t, "mainnet", cfg.Hedera.Network)
+	assert.Equal(t, "https://mainnet-public.mirrornode.hedera.com/api/v1", cfg.Mirror.BaseURL)
+	assert.Equal(t, "postgres://sdl@db/sdl", cfg.Registry.StoreDSN)
+	assert.Equal(t, "sdl.a1b2c", cfg.Temporal.Namespace)
+	assert.Equal(t, "2m0s", cfg.Temporal.WorkerStopTimeout.String())
+	// Settings missing from the profile keep their defaults
+	assert.Equal(t, DefaultTaskQueue+"-mainnet", cfg.Temporal.TaskQueue)
+
+	// SDL_PROFILE selects a profile when none is passed
+	t.Setenv("SDL_PROFILE", "mainnet-prod")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "mainnet-prod", cfg.Profile)
+}
+
+func TestLoadProfile_EnvOverridesProfile(t *testing.T) {
+	clearEnv(t)
+	writeConfigFile(t, testConfigFile)
+	t.Setenv("TEMPORAL_TASK_QUEUE", "from-env")
+
+	cfg, err := LoadProfile("testnet-dev")
+	require.NoError(t, err)
+	assert.Equal(t, "from-env-testnet", cfg.Temporal.TaskQueue)
+}
+
+func TestLoadProfile_Unknown(t *testing.T) {
+	clearEnv(t)
+	writeConfigFile(t, testConfigFile)
+
+	_, err := LoadProfile("staging")
+	assert.ErrorIs(t, err, ErrProfileNotFound)
+	assert.ErrorContains(t, err, "mainnet-prod, testnet-dev")
+
+	// Without a config file only an explicitly requested profile is an error
+	clearEnv(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Profile)
+	_, err = LoadProfile("staging")
+	assert.ErrorIs(t, err, ErrProfileNotFound)
+}
+
+func TestLoadFile_Invalid(t *testing.T) {
+	clearEnv(t)
+	writeConfigFile(t, "profiles: [not, a, map]")
+
+	_, err := Load()
+	assert.ErrorContains(t, err, "failed to parse config file")
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"math"
+	"strings"
+)
+
+// DGAMinLength is the length under which labels are too short to tell whether they were generated, DGAScore
+// returns 0 for them
+const DGAMinLength = 6
+
+// commonBigrams are the most frequent letter pairs of English text, the labels people pick are mostly made of
+// them while generated labels are not
+var commonBigrams = func() map[string]bool {
+	const pairs = "th he in er an re on at en nd ti es or te of ed is it al ar st to nt ng se ha as ou io le ve " +
+		"co me de hi ri ro ic ne ea ra ce li ch ll be ma si om ur ca el ta la ns di fo ho pe ec pr no ct us ac ot " +
+		"il tr ly nc et ut ss so rs un lo wa ge ie wh ee wi em ad ol rt po we na ul ni ts mo ow pa im mi ai sh ir " +
+		"su id os iv ia am fi ci vi pl ig tu ev ld ry mp fe bl ab gh ty op wo sa ay ex ke fr oo av ag if ap gr od " +
+		"bo sp rd do uc bu ei ov by rm ep tt oc fa ef cu rn sc gi da yo cr cl du ga qu ue ff ba ey ls va um pp ua " +
+		"up lu go ht ru ug ds lt pi rc rr eg au ck ew mu br bi pt ak pu ui rg ib tl ny ki rk ys ob mm fu ph og ms " +
+		"ye ud mb ip ub oi rl gu dr hr cc tw ft wn nu af hu nn eo vo rv nf xp gn sm fl iz ok nk kn gs dy hy ks xt"
+	bigrams := make(map[string]bool)
+	for _, pair := range strings.Fields(pairs) {
+		bigrams[pair] = true
+	}
+	return bigrams
+}()
+
+// Entropy returns the Shannon entropy of the characters of a label, in bits per character
+func Entropy(l Label) float64 {
+	s := l.String()
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	var entropy float64
+	n := float64(len([]rune(s)))
+	for _, count := range counts {
+		p := float64(count) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// DGAScore estimates how likely a label is to be algorithmically generated, from 0 (reads like words) to 1
+// (looks random). It weighs the share of letter pairs that are rare in English, the entropy of the label and
+// the share of digits mixed with letters. It is a heuristic meant to rank registrations for abuse analysis,
+// not a verdict. Labels shorter than DGAMinLength score 0.
+func DGAScore(l Label) float64 {
+	s := strings.ToLower(l.String())
+	runes := []rune(s)
+	if len(runes) < DGAMinLength {
+		return 0
+	}
+
+	var rare, letters, digits int
+	for i, r := range runes {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r >= 'a' && r <= 'z':
+			letters++
+		}
+		if i > 0 && !commonBigrams[string(runes[i-1:i+1])] {
+			rare++
+		}
+	}
+	rareShare := float64(rare) / float64(len(runes)-1)
+	// The entropy of a label made of 16 or more equally frequent characters is 4 bits
+	entropyShare := min(Entropy(l)/4, 1)
+	digitShare := 0.0
+	if letters > 0 {
+		digitShare = min(float64(digits)/float64(len(runes))*2, 1)
+	}
+	return 0.5*rareShare + 0.3*entropyShare + 0.2*digitShare
+}
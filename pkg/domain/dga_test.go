@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntropy(t *testing.T) {
+	assert.Equal(t, 0.0, Entropy(""))
+	assert.Equal(t, 0.0, Entropy("aaaa"))
+	assert.Equal(t, 1.0, Entropy("abab"))
+	assert.Equal(t, 2.0, Entropy("abcd"))
+	assert.InDelta(t, 1.92, Entropy("google"), 0.01)
+}
+
+func TestDGAScore(t *testing.T) {
+	for _, label := range []Label{"example", "facebook", "google", "wikipedia", "shopping", "microsoft", "mybusiness"} {
+		assert.Less(t, DGAScore(label), 0.4, label)
+	}
+	for _, label := range []Label{"xkq7zvpw3jhd", "qwxzrtplmvbn", "4f8a9c2e1b7d", "tvzzkqlm"} {
+		assert.Greater(t, DGAScore(label), 0.6, label)
+	}
+
+	// Too short to tell
+	assert.Equal(t, 0.0, DGAScore("xkq7z"))
+	// Case does not matter
+	assert.Equal(t, DGAScore("xkq7zvpw3jhd"), DGAScore("XKQ7ZVPW3JHD"))
+	// Digits mixed with letters raise the score
+	assert.Greater(t, DGAScore("shop2024"), DGAScore("shopping"))
+}
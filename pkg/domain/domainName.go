@@ -2,6 +2,7 @@ package domain
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 
 	"errors"
@@ -15,10 +16,16 @@ const (
 )
 
 var (
-	ErrinvalIdDomainNameLength = errors.New("invalid domain name length. Domain name must be between 1 and 253 characters long")
-	ErrInvalidDomainName       = errors.New("invalid domain name")
+	ErrinvalIdDomainNameLength  = errors.New("invalid domain name length. Domain name must be between 1 and 253 characters long")
+	ErrInvalidDomainName        = errors.New("invalid domain name")
+	ErrZoneMismatch             = errors.New("domain name does not belong to its zone")
+	ErrInvalidADomainNameLength = errors.New("invalid domain name length: the A-label form of a domain name must be at most 253 octets")
+	ErrInvalidWildcard          = errors.New("invalid host name: a wildcard must be the whole first label of a name in a domain")
 )
 
+// WILDCARD_LABEL is the label of wildcard host names, e.g. *.example.com
+const WILDCARD_LABEL = "*"
+
 // A domainname is an alias for a string
 type DomainName string
 
@@ -38,10 +45,16 @@ func NewDomainName(name string) (*DomainName, error) {
 // A domain name is a FQDN (Fully Qualified Domain Name) and can contain letters, digits and hyphens
 // A domain name can be between 1 and 253 characters long
 // A domain consists of valid labels separated by dots
+// IDNs must be given in their A-label form (see ToASCII): U-labels are refused as invalid characters. The
+// lengths of the A-label form are checked first, so that a U-label name too long on the wire is reported as
+// such rather than for its characters, see ValidateALabelLength
 func (d *DomainName) Validate() error {
 	if len(d.String()) > DOMAIN_MAX_LEN || len(d.String()) < DOMAIN_MIN_LEN {
 		return ErrinvalIdDomainNameLength
 	}
+	if err := d.ValidateALabelLength(); err != nil {
+		return err
+	}
 
 	// Verify that each label is valid
 	for _, label := range d.GetLabels() {
@@ -52,12 +65,76 @@ func (d *DomainName) Validate() error {
 	return nil
 }
 
+// NewHostName returns a pointer to a DomainName of a host name or an error if the host name is invalid.
+// It is normalized like NewDomainName, and validated with the relaxed rules of ValidateHost, for the host
+// objects some registries send, e.g. _dmarc.example.com or *.example.com
+func NewHostName(name string) (*DomainName, error) {
+	n := NormalizeString(strings.ToLower(name))
+	d := DomainName(strings.Trim(n, "."))
+	if err := d.ValidateHost(); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// ValidateHost returns an error indicating if the domain name is a valid host name.
+// A host name is validated like a domain name by Validate, except that its labels may contain underscores
+// and its first label may be a wildcard (*) in a domain of at least one label.
+func (d *DomainName) ValidateHost() error {
+	if len(d.String()) > DOMAIN_MAX_LEN || len(d.String()) < DOMAIN_MIN_LEN {
+		return ErrinvalIdDomainNameLength
+	}
+	if err := d.ValidateALabelLength(); err != nil {
+		return err
+	}
+
+	labels := d.GetLabels()
+	for i, label := range labels {
+		if strings.Contains(label.String(), WILDCARD_LABEL) {
+			if i > 0 || label != WILDCARD_LABEL || len(labels) == 1 {
+				return ErrInvalidWildcard
+			}
+			continue
+		}
+		if err := label.ValidateHost(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsWildcard returns true if the first label of the domain name is a wildcard, e.g. *.example.com
+func (d *DomainName) IsWildcard() bool {
+	return d.Label() == WILDCARD_LABEL
+}
+
 // Returns the parent domain of the domain name
 func (d *DomainName) ParentDomain() string {
 	labels := strings.Split(string(*d), ".")
 	return strings.Join(labels[1:], ".")
 }
 
+// CheckZone returns an error wrapping ErrZoneMismatch unless the domain name is registered directly in the zone,
+// i.e. the zone is its parent domain. The zone is normalized like a domain name, dots and case do not matter.
+// The error tells how the domain name and the zone differ.
+func (d *DomainName) CheckZone(zone string) error {
+	z, err := NewDomainName(zone)
+	if err != nil {
+		return fmt.Errorf("%w: invalid zone %q", ErrZoneMismatch, zone)
+	}
+	parent := d.ParentDomain()
+	switch {
+	case parent == z.String():
+		return nil
+	case parent == "":
+		return fmt.Errorf("%w: %s is a single label, not a name in .%s", ErrZoneMismatch, d, z)
+	case d.IsSubdomainOf(*z):
+		return fmt.Errorf("%w: %s is a subdomain of %s, not a name registered in .%s", ErrZoneMismatch, d, parent, z)
+	default:
+		return fmt.Errorf("%w: %s is in .%s, not in .%s", ErrZoneMismatch, d, parent, z)
+	}
+}
+
 // Returns the first label of the domain name
 func (d *DomainName) Label() string {
 	labels := strings.Split(string(*d), ".")
@@ -89,6 +166,40 @@ func (d *DomainName) ToUnicode() (string, error) {
 	return s, nil
 }
 
+// ToASCII returns the A-label form of the domain name, the form it has on the wire, with every U-label
+// converted to punycode
+func (d *DomainName) ToASCII() (string, error) {
+	labels := d.GetLabels()
+	ascii := make([]string, len(labels))
+	for i, label := range labels {
+		a, err := label.ToASCII()
+		if err != nil {
+			return "", err
+		}
+		ascii[i] = a
+	}
+	return strings.Join(ascii, "."), nil
+}
+
+// ValidateALabelLength checks the lengths of the A-label form of the domain name: every A-label must be at
+// most 63 octets and the whole name at most 253. A name whose U-labels pass the length checks can exceed
+// them once expanded to punycode, and is then invalid in the DNS.
+func (d *DomainName) ValidateALabelLength() error {
+	for _, label := range d.GetLabels() {
+		if err := label.ValidateALabelLength(); err != nil {
+			return err
+		}
+	}
+	ascii, err := d.ToASCII()
+	if err != nil {
+		return err
+	}
+	if len(ascii) > DOMAIN_MAX_LEN {
+		return ErrInvalidADomainNameLength
+	}
+	return nil
+}
+
 // IsIDN returns true if the domainname is an IDN. It returns false if it is a non-IDN domain.
 // If the unicode (U-label) string of a domain is different than the ascii (A-label) then we determine we are dealing with an IDN domain.
 func (d *DomainName) IsIDN() (bool, error) {
@@ -108,3 +219,51 @@ func (d *DomainName) GetLabels() []Label {
 	}
 	return l
 }
+
+// canonical returns the form of the domain name that comparisons use: normalized, lowercase, without
+// leading and trailing dots, and with U-labels converted to A-labels
+func (d *DomainName) canonical() string {
+	s := strings.Trim(NormalizeString(strings.ToLower(d.String())), ".")
+	if ascii, err := idna.ToASCII(s); err == nil {
+		return ascii
+	}
+	return s
+}
+
+// canonicalLabels returns the labels of the canonical form of the domain name, none if it is empty
+func (d *DomainName) canonicalLabels() []string {
+	s := d.canonical()
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ".")
+}
+
+// Equals returns true if both domain names are the same name once normalized: case, surrounding dots and
+// whitespace do not matter, and a U-label equals its A-label
+func (d *DomainName) Equals(other DomainName) bool {
+	return d.canonical() == other.canonical()
+}
+
+// IsSubdomainOf returns true if the domain name is below the parent domain, at any depth.
+// A domain name is not a subdomain of itself.
+func (d *DomainName) IsSubdomainOf(parent DomainName) bool {
+	name, p := d.canonical(), parent.canonical()
+	return p != "" && strings.HasSuffix(name, "."+p)
+}
+
+// Depth returns the number of labels of the domain name, e.g. 1 for a TLD and 2 for a name registered in it
+func (d *DomainName) Depth() int {
+	return len(d.canonicalLabels())
+}
+
+// CommonSuffix returns the longest domain both domain names are in or equal to, comparing whole labels,
+// e.g. example.com for www.example.com and mail.example.com. It is empty if they share no label.
+func (d *DomainName) CommonSuffix(other DomainName) DomainName {
+	a, b := d.canonicalLabels(), other.canonicalLabels()
+	n := 0
+	for n < len(a) && n < len(b) && a[len(a)-1-n] == b[len(b)-1-n] {
+		n++
+	}
+	return DomainName(strings.Join(a[len(a)-n:], "."))
+}
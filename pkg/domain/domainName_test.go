@@ -2,6 +2,7 @@ package domain
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -30,6 +31,10 @@ func TestNewDomainName(t *testing.T) {
 		{"empty", "", "", ErrinvalIdDomainNameLength},
 		{"dot only", ".", "", ErrinvalIdDomainNameLength}, // dots will be trimmed
 		{"one character", "a", "a", nil},
+		{"A-label", "xn--cario-rta.com", "xn--cario-rta.com", nil},
+		{"U-label", "cariño.com", "", ErrLabelContainsInvalidCharacter},
+		{"A-label too long", strings.Repeat("a", 56) + "ü.com", "", ErrInvalidALabelLength},
+		{"A-label domain name too long", strings.Repeat(strings.Repeat("a", 55)+"ü.", 4), "", ErrInvalidADomainNameLength},
 		{"domain name too long", "tooooooooooooooolooooooooooooooooooongdooooooooooooooooomainnaaaaaaaaaaaame.tooooooooooooooolooooooooooooooooooongdooooooooooooooooomainnaaaaaaaaaaaame.tooooooooooooooolooooooooooooooooooongdooooooooooooooooomainnaaaaaaaaaaaame.tooooooooooooooolooooooooooooooooooongdooooooooooooooooomainnaaaaaaaaaaaame.", "", ErrinvalIdDomainNameLength},
 	}
 
@@ -65,6 +70,28 @@ func TestDomainName_ParentDomain(t *testing.T) {
 	}
 }
 
+func TestDomainName_CheckZone(t *testing.T) {
+	d, err := NewDomainName("Example.Build")
+	require.NoError(t, err)
+	for _, zone := range []string{"build", ".BUILD", "build."} {
+		assert.NoError(t, d.CheckZone(zone), zone)
+	}
+
+	for name, test := range map[string]struct{ domain, zone, msg string }{
+		"other zone": {"example.shop", "build", "example.shop is in .shop, not in .build"},
+		"subdomain":  {"www.example.build", "build", "subdomain of example.build"},
+		"label":      {"example", "build", "single label"},
+		"no zone":    {"example.build", "", "invalid zone"},
+		"suffix":     {"example.rebuild", "build", "example.rebuild is in .rebuild"},
+	} {
+		d, err := NewDomainName(test.domain)
+		require.NoError(t, err, name)
+		err = d.CheckZone(test.zone)
+		assert.ErrorIs(t, err, ErrZoneMismatch, name)
+		assert.ErrorContains(t, err, test.msg, name)
+	}
+}
+
 func TestDomainName_Label(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -167,3 +194,177 @@ func TestDomainName_IsIDN(t *testing.T) {
 	}
 
 }
+
+func TestDomainName_Equals(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected bool
+	}{
+		{"same", "example.com", "example.com", true},
+		{"case", "Example.COM", "example.com", true},
+		{"dots", ".example.com.", "example.com", true},
+		{"whitespace", " example.com\n", "example.com", true},
+		{"U-label and A-label", "點看.com", "xn--c1yn36f.com", true},
+		{"other name", "example.com", "example.net", false},
+		{"subdomain", "www.example.com", "example.com", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a, b := DomainName(test.a), DomainName(test.b)
+			assert.Equal(t, test.expected, a.Equals(b))
+			assert.Equal(t, test.expected, b.Equals(a))
+		})
+	}
+}
+
+func TestDomainName_IsSubdomainOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		domain   string
+		parent   string
+		expected bool
+	}{
+		{"name in zone", "example.build", "build", true},
+		{"deeper", "www.sub.example.build", "example.build", true},
+		{"case and dots", "WWW.Example.Build", ".example.build.", true},
+		{"itself", "example.build", "example.build", false},
+		{"label suffix", "example.rebuild", "build", false},
+		{"parent", "build", "example.build", false},
+		{"empty parent", "example.build", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := DomainName(test.domain)
+			assert.Equal(t, test.expected, d.IsSubdomainOf(DomainName(test.parent)))
+		})
+	}
+}
+
+func TestDomainName_Depth(t *testing.T) {
+	for domain, expected := range map[string]int{
+		"":                 0,
+		"build":            1,
+		"example.build":    2,
+		"www.example.com.": 3,
+	} {
+		d := DomainName(domain)
+		assert.Equal(t, expected, d.Depth(), domain)
+	}
+}
+
+func TestDomainName_CommonSuffix(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected DomainName
+	}{
+		{"siblings", "www.example.com", "mail.example.com", "example.com"},
+		{"same zone", "example.com", "other.com", "com"},
+		{"ancestor", "www.example.com", "Example.COM", "example.com"},
+		{"equal", "example.com", "example.com", "example.com"},
+		{"whole labels", "example.rebuild", "example.build", ""},
+		{"nothing in common", "example.com", "example.net", ""},
+		{"empty", "", "example.com", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a, b := DomainName(test.a), DomainName(test.b)
+			assert.Equal(t, test.expected, a.CommonSuffix(b))
+			assert.Equal(t, test.expected, b.CommonSuffix(a))
+		})
+	}
+}
+
+func TestNewHostName(t *testing.T) {
+	tests := []struct {
+		testname      string
+		name          string
+		expected      string
+		expectedError error
+	}{
+		{"domain name", "Example.com.", "example.com", nil},
+		{"underscore label", "_dmarc.example.com", "_dmarc.example.com", nil},
+		{"underscores", "_443._tcp.Mail.example.com", "_443._tcp.mail.example.com", nil},
+		{"wildcard", "*.example.com", "*.example.com", nil},
+		{"wildcard with underscore", "*._domainkey.example.com", "*._domainkey.example.com", nil},
+		{"wildcard only", "*", "", ErrInvalidWildcard},
+		{"inner wildcard", "www.*.example.com", "", ErrInvalidWildcard},
+		{"partial wildcard", "w*.example.com", "", ErrInvalidWildcard},
+		{"invalid character", "exa$mple.com", "", ErrLabelContainsInvalidCharacter},
+		{"empty label", "_dmarc..com", "", ErrInvalidLabelLength},
+		{"U-label", "ns1.cariño.com", "", ErrLabelContainsInvalidCharacter},
+		{"A-label too long", "ns1." + strings.Repeat("a", 56) + "ü.com", "", ErrInvalidALabelLength},
+		{"A-label host name too long", strings.Repeat(strings.Repeat("a", 55)+"ü.", 4), "", ErrInvalidADomainNameLength},
+		{"empty", "", "", ErrinvalIdDomainNameLength},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testname, func(t *testing.T) {
+			d, err := NewHostName(test.name)
+			require.Equal(t, test.expectedError, err, "error mismatch")
+			if err == nil {
+				assert.Equal(t, test.expected, d.String(), "host name mismatch")
+			}
+		})
+	}
+
+	// The strict rules still refuse host names
+	for _, name := range []string{"_dmarc.example.com", "*.example.com"} {
+		_, err := NewDomainName(name)
+		assert.ErrorIs(t, err, ErrLabelContainsInvalidCharacter, name)
+	}
+}
+
+func TestDomainName_IsWildcard(t *testing.T) {
+	wildcard, err := NewHostName("*.example.com")
+	require.NoError(t, err)
+	assert.True(t, wildcard.IsWildcard())
+
+	host, err := NewHostName("_dmarc.example.com")
+	require.NoError(t, err)
+	assert.False(t, host.IsWildcard())
+}
+
+func TestDomainName_ToASCII(t *testing.T) {
+	d := DomainName("點看.com")
+	ascii, err := d.ToASCII()
+	require.NoError(t, err)
+	assert.Equal(t, "xn--c1yn36f.com", ascii)
+
+	d = DomainName("example.com")
+	ascii, err = d.ToASCII()
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", ascii)
+}
+
+func TestDomainName_ValidateALabelLength(t *testing.T) {
+	// 57 characters expand to an A-label of 63 octets, 58 to 64
+	longest, tooLong := strings.Repeat("ü", 57), strings.Repeat("ü", 58)
+	// Four labels of 57 characters are 231 characters, but 255 octets on the wire
+	fqdn := strings.Join([]string{longest, longest, longest, longest}, ".")
+	require.LessOrEqual(t, len([]rune(fqdn)), DOMAIN_MAX_LEN)
+
+	tests := []struct {
+		name     string
+		domain   string
+		expected error
+	}{
+		{"ascii", "example.com", nil},
+		{"IDN", "點看.com", nil},
+		{"longest A-label", longest + ".com", nil},
+		{"A-label too long", tooLong + ".com", ErrInvalidALabelLength},
+		{"A-label domain name too long", fqdn, ErrInvalidADomainNameLength},
+		{"A-label domain name within limits", strings.Join([]string{longest, longest, longest, "com"}, "."), nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := DomainName(test.domain)
+			assert.Equal(t, test.expected, d.ValidateALabelLength())
+		})
+	}
+}
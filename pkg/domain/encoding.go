@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// MarshalText implements the encoding.TextMarshaler interface for DomainName
+func (d DomainName) MarshalText() ([]byte, error) {
+	return []byte(d), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for DomainName, e.g. for config files.
+// The text is normalized and validated like NewDomainName does.
+func (d *DomainName) UnmarshalText(text []byte) error {
+	name, err := NewDomainName(string(text))
+	if err != nil {
+		return err
+	}
+	*d = *name
+	return nil
+}
+
+// Value implements the driver.Valuer interface for DomainName, it is stored as text
+func (d DomainName) Value() (driver.Value, error) {
+	return string(d), nil
+}
+
+// Scan implements the sql.Scanner interface for DomainName. The stored text is validated, a column that
+// may be NULL is scanned into a sql.Null[DomainName].
+func (d *DomainName) Scan(src any) error {
+	s, err := scanText(src, "DomainName")
+	if err != nil {
+		return err
+	}
+	return d.UnmarshalText([]byte(s))
+}
+
+// NewLabel returns the label of the given string, lowercased and without surrounding whitespace, or an error
+// if the label is invalid
+func NewLabel(s string) (Label, error) {
+	l := Label(strings.ToLower(strings.TrimSpace(s)))
+	if err := l.Validate(); err != nil {
+		return "", err
+	}
+	return l, nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for Label
+func (t Label) MarshalText() ([]byte, error) {
+	return []byte(t), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for Label, the label is validated
+func (t *Label) UnmarshalText(text []byte) error {
+	l, err := NewLabel(string(text))
+	if err != nil {
+		return err
+	}
+	*t = l
+	return nil
+}
+
+// Value implements the driver.Valuer interface for Label, it is stored as text
+func (t Label) Value() (driver.Value, error) {
+	return string(t), nil
+}
+
+// Scan implements the sql.Scanner interface for Label, the stored text is validated
+func (t *Label) Scan(src any) error {
+	s, err := scanText(src, "Label")
+	if err != nil {
+		return err
+	}
+	return t.UnmarshalText([]byte(s))
+}
+
+// scanText returns the text of a value read from a database column
+func scanText(src any, typeName string) (string, error) {
+	switch v := src.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case nil:
+		return "", fmt.Errorf("cannot scan NULL into %s", typeName)
+	default:
+		return "", fmt.Errorf("cannot scan %T into %s", src, typeName)
+	}
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// Compile time checks of the interfaces
+var (
+	_ sql.Scanner   = (*DomainName)(nil)
+	_ driver.Valuer = DomainName("")
+	_ sql.Scanner   = (*Label)(nil)
+	_ driver.Valuer = Label("")
+)
+
+func TestDomainName_Text(t *testing.T) {
+	var d DomainName
+	require.NoError(t, d.UnmarshalText([]byte(" Example.Build. ")))
+	assert.Equal(t, DomainName("example.build"), d)
+
+	text, err := d.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "example.build", string(text))
+
+	assert.ErrorIs(t, d.UnmarshalText([]byte("example_build")), ErrLabelContainsInvalidCharacter)
+	assert.ErrorIs(t, d.UnmarshalText([]byte("")), ErrinvalIdDomainNameLength)
+}
+
+func TestDomainName_YAML(t *testing.T) {
+	var config struct {
+		Domains []DomainName `yaml:"domains"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte("domains: [Example.Build, shop.]"), &config))
+	assert.Equal(t, []DomainName{"example.build", "shop"}, config.Domains)
+
+	assert.Error(t, yaml.Unmarshal([]byte("domains: [example!build]"), &config))
+}
+
+func TestDomainName_SQL(t *testing.T) {
+	value, err := DomainName("example.build").Value()
+	require.NoError(t, err)
+	assert.Equal(t, "example.build", value)
+
+	tests := []struct {
+		name     string
+		src      any
+		expected DomainName
+		err      string
+	}{
+		{"string", "example.build", "example.build", ""},
+		{"bytes", []byte("EXAMPLE.build"), "example.build", ""},
+		{"invalid", "example..build", "", "invalid label length"},
+		{"null", nil, "", "cannot scan NULL into DomainName"},
+		{"other type", 42, "", "cannot scan int into DomainName"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var d DomainName
+			err := d.Scan(test.src)
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, d)
+		})
+	}
+
+	var null sql.Null[DomainName]
+	require.NoError(t, null.Scan(nil))
+	assert.False(t, null.Valid)
+	require.NoError(t, null.Scan("example.build"))
+	assert.Equal(t, sql.Null[DomainName]{V: "example.build", Valid: true}, null)
+}
+
+func TestLabel_Text(t *testing.T) {
+	var l Label
+	require.NoError(t, json.Unmarshal([]byte(`" Example "`), &l))
+	assert.Equal(t, Label("example"), l)
+
+	data, err := json.Marshal(l)
+	require.NoError(t, err)
+	assert.Equal(t, `"example"`, string(data))
+
+	assert.ErrorIs(t, l.UnmarshalText([]byte("-example")), ErrInvalidLabelDash)
+	assert.ErrorIs(t, l.UnmarshalText([]byte("exa.mple")), ErrLabelContainsInvalidCharacter)
+}
+
+func TestLabel_SQL(t *testing.T) {
+	value, err := Label("example").Value()
+	require.NoError(t, err)
+	assert.Equal(t, "example", value)
+
+	var l Label
+	require.NoError(t, l.Scan([]byte("Example")))
+	assert.Equal(t, Label("example"), l)
+	assert.ErrorIs(t, l.Scan("ab--cd"), ErrInvalidLabelDoubleDash)
+	assert.ErrorContains(t, l.Scan(nil), "cannot scan NULL into Label")
+}
+
+func TestNewLabel(t *testing.T) {
+	l, err := NewLabel("Build")
+	require.NoError(t, err)
+	assert.Equal(t, Label("build"), l)
+
+	_, err = NewLabel("")
+	assert.ErrorIs(t, err, ErrInvalidLabelLength)
+}
@@ -22,6 +22,7 @@ var (
 	ErrInvalidLabelDoubleDash        = errors.New("invalid label: each non-IDN label cannot contain two consecutive hyphens")
 	ErrInvalidLabelIDN               = errors.New("invalid label: each IDN label must be convertible to Unicode")
 	ErrLabelContainsInvalidCharacter = errors.New("invalid label: invalid character")
+	ErrInvalidALabelLength           = errors.New("invalid label length: the A-label of each label must be at most 63 octets")
 )
 
 // Validate checks if the value is valid
@@ -30,6 +31,15 @@ var (
 // contains two consecutive hyphens (unless it is an IDN label), is an invalid IDN label,
 // or contains invalid characters.
 func (t Label) Validate() error {
+	return t.validate(false)
+}
+
+// ValidateHost checks the label like Validate, but also allows underscores as in host names, e.g. _dmarc or _443
+func (t Label) ValidateHost() error {
+	return t.validate(true)
+}
+
+func (t Label) validate(allowUnderscore bool) error {
 	// It is too short or too long
 	if len(t) > LABEL_MAX_LEN || len(t) < LABEL_MIN_LEN {
 		return ErrInvalidLabelLength
@@ -50,7 +60,7 @@ func (t Label) Validate() error {
 		}
 	}
 	// It contains invalid characters
-	invalidChar := t.findInvalidLabelCharacters()
+	invalidChar := t.findInvalidLabelCharacters(allowUnderscore)
 	if invalidChar != "" {
 		return ErrLabelContainsInvalidCharacter
 	}
@@ -67,10 +77,33 @@ func (t Label) ToUnicode() (string, error) {
 	return idna.Lookup.ToUnicode(t.String())
 }
 
+// ToASCII returns the A-label of the label: a U-label converted to punycode with the xn-- prefix, an ASCII
+// label as is
+func (t Label) ToASCII() (string, error) {
+	s, err := idna.Punycode.ToASCII(t.String())
+	if err != nil {
+		return "", ErrInvalidLabelIDN
+	}
+	return s, nil
+}
+
+// ValidateALabelLength checks that the A-label of the label is at most 63 octets. A U-label within the
+// limit can exceed it once expanded to punycode, and is then invalid in the DNS.
+func (t Label) ValidateALabelLength() error {
+	a, err := t.ToASCII()
+	if err != nil {
+		return err
+	}
+	if len(a) > LABEL_MAX_LEN {
+		return ErrInvalidALabelLength
+	}
+	return nil
+}
+
 // Helper function to find any invalid characters in a label. It will return the first invalid character or an empty string if the label has no invalid characters
 // A label is a section of a FQDN separated by a dot
-// A label can contain letters, digits and hyphens
-func (l *Label) findInvalidLabelCharacters() string {
+// A label can contain letters, digits and hyphens, and underscores if allowUnderscore is set
+func (l *Label) findInvalidLabelCharacters(allowUnderscore bool) string {
 	for _, char := range l.String() {
 		// If it's not ASCII, it's invalid
 		if !IsASCII(string(char)) {
@@ -79,7 +112,7 @@ func (l *Label) findInvalidLabelCharacters() string {
 		// If it's not a letter, digit or hyphen, it's invalid
 		if !(unicode.IsLetter(char)) {
 			if !(unicode.IsDigit(char)) {
-				if !(string(char) == "-") {
+				if !(string(char) == "-") && !(allowUnderscore && char == '_') {
 					return string(char)
 				}
 			}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -34,7 +35,7 @@ func TestLabel_FindInvalidLabelCharacters(t *testing.T) {
 
 	for _, test := range tests {
 		l := Label(test.label)
-		result := l.findInvalidLabelCharacters()
+		result := l.findInvalidLabelCharacters(false)
 		if result != test.expectedInvalid {
 			t.Errorf("Expected findInvalidLabelCharacters(%s) to be %s, but got %s", test.label, test.expectedInvalid, result)
 		}
@@ -74,6 +75,27 @@ func TestLabel_IsValidLabel(t *testing.T) {
 	}
 }
 
+func TestLabel_ValidateHost(t *testing.T) {
+	tests := []struct {
+		label    string
+		expected error
+	}{
+		{"_dmarc", nil},
+		{"_443", nil},
+		{"abc_123", nil},
+		{"abc-123", nil},
+		{"abc$123", ErrLabelContainsInvalidCharacter},
+		{"*", ErrLabelContainsInvalidCharacter},
+		{"-abc", ErrInvalidLabelDash},
+		{"", ErrInvalidLabelLength},
+	}
+
+	for _, test := range tests {
+		l := Label(test.label)
+		require.Equal(t, test.expected, l.ValidateHost(), "ValidateHost(%s)", test.label)
+	}
+}
+
 func TestLabel_ToUnicode(t *testing.T) {
 	tests := []struct {
 		testname string
@@ -91,3 +113,28 @@ func TestLabel_ToUnicode(t *testing.T) {
 		require.Equal(t, test.expected, result, "Expected ToUnicode(%s) to be %s, but got %s", test.label, test.expected, result)
 	}
 }
+
+func TestLabel_ToASCII(t *testing.T) {
+	tests := []struct {
+		label    string
+		expected string
+	}{
+		{"abc123", "abc123"},
+		{"cariño", "xn--cario-rta"},
+		{"xn--cario-rta", "xn--cario-rta"},
+	}
+
+	for _, test := range tests {
+		result, err := Label(test.label).ToASCII()
+		require.NoError(t, err, test.label)
+		require.Equal(t, test.expected, result, test.label)
+	}
+}
+
+func TestLabel_ValidateALabelLength(t *testing.T) {
+	require.NoError(t, Label("cariño").ValidateALabelLength())
+	// 60 characters, whose A-label is 66 octets
+	long := Label(strings.Repeat("ü", 60))
+	require.NoError(t, Label(strings.Repeat("u", 60)).ValidateALabelLength())
+	require.Equal(t, ErrInvalidALabelLength, long.ValidateALabelLength())
+}
@@ -0,0 +1,89 @@
+package domain
+
+// Levenshtein returns the edit distance between two strings: the number of characters inserted, deleted or
+// substituted to turn one into the other
+func Levenshtein(a, b string) int {
+	return int(editDistance([]rune(a), []rune(b), func(x, y rune) float64 { return 1 }))
+}
+
+// Similarity returns how similar two labels are, from 0 (nothing in common) to 1 (equal): one minus their
+// Levenshtein distance relative to the longest of them
+func Similarity(a, b Label) float64 {
+	return similarity([]rune(a.String()), []rune(b.String()), func(x, y rune) float64 { return 1 })
+}
+
+// KeyboardSimilarity returns how similar two labels are like Similarity, but substituting a character by a
+// key next to it on a QWERTY keyboard only counts half, as typos and typosquats often do. It is at least
+// their Similarity.
+func KeyboardSimilarity(a, b Label) float64 {
+	return similarity([]rune(a.String()), []rune(b.String()), func(x, y rune) float64 {
+		if keyboardAdjacent(x, y) {
+			return 0.5
+		}
+		return 1
+	})
+}
+
+// similarity returns one minus the edit distance of two strings relative to the longest of them
+func similarity(a, b []rune, substitution func(x, y rune) float64) float64 {
+	longest := max(len(a), len(b))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - editDistance(a, b, substitution)/float64(longest)
+}
+
+// editDistance returns the Levenshtein distance of two strings with the given cost of substituting two
+// different characters, inserts and deletes cost 1
+func editDistance(a, b []rune, substitution func(x, y rune) float64) float64 {
+	previous := make([]float64, len(b)+1)
+	current := make([]float64, len(b)+1)
+	for j := range previous {
+		previous[j] = float64(j)
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = float64(i)
+		for j := 1; j <= len(b); j++ {
+			cost := 0.0
+			if a[i-1] != b[j-1] {
+				cost = substitution(a[i-1], b[j-1])
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// keyboardRows are the rows of a QWERTY keyboard, keys are adjacent to their neighbours in the row and to
+// the keys above and below them, shifted by the stagger of the rows
+var keyboardRows = []string{"1234567890-", "qwertyuiop", "asdfghjkl", "zxcvbnm"}
+
+// keyboardPositions maps every key of keyboardRows to its row and column
+var keyboardPositions = func() map[rune][2]int {
+	positions := make(map[rune][2]int)
+	for row, keys := range keyboardRows {
+		for col, key := range keys {
+			positions[key] = [2]int{row, col}
+		}
+	}
+	return positions
+}()
+
+// keyboardAdjacent reports whether two keys are next to each other on a QWERTY keyboard
+func keyboardAdjacent(x, y rune) bool {
+	px, okx := keyboardPositions[x]
+	py, oky := keyboardPositions[y]
+	if !okx || !oky || x == y {
+		return false
+	}
+	switch px[0] - py[0] {
+	case 0:
+		return px[1]-py[1] == 1 || py[1]-px[1] == 1
+	case 1: // x is on the row below y, rows are staggered to the right going down
+		return px[1] == py[1] || px[1] == py[1]-1
+	case -1:
+		return py[1] == px[1] || py[1] == px[1]-1
+	}
+	return false
+}
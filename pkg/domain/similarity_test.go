@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"example", "example", 0},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"google", "gooogle", 1},
+		{"paypal", "paypa1", 1},
+		{"apple", "aplpe", 2},
+		{"cariño", "carino", 1},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, Levenshtein(test.a, test.b), "%s %s", test.a, test.b)
+		assert.Equal(t, test.expected, Levenshtein(test.b, test.a), "%s %s", test.b, test.a)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, Similarity("example", "example"))
+	assert.Equal(t, 1.0, Similarity("", ""))
+	assert.Equal(t, 0.0, Similarity("abc", "xyz"))
+	assert.InDelta(t, 5.0/6, Similarity("paypal", "paypa1"), 1e-9)
+	assert.InDelta(t, 6.0/7, Similarity("google", "gooogle"), 1e-9)
+}
+
+func TestKeyboardSimilarity(t *testing.T) {
+	// o and p are neighbours, o and m are not
+	assert.InDelta(t, 1-0.5/6, KeyboardSimilarity("google", "gpogle"), 1e-9)
+	assert.InDelta(t, 5.0/6, KeyboardSimilarity("google", "gmogle"), 1e-9)
+
+	for _, pair := range [][2]Label{{"paypal", "paypa1"}, {"amazon", "anazon"}, {"example", "exanple"}, {"bank", "vank"}} {
+		keyboard, plain := KeyboardSimilarity(pair[0], pair[1]), Similarity(pair[0], pair[1])
+		assert.GreaterOrEqual(t, keyboard, plain, pair)
+		assert.Equal(t, keyboard, KeyboardSimilarity(pair[1], pair[0]), pair)
+	}
+}
+
+func TestKeyboardAdjacent(t *testing.T) {
+	for _, pair := range []string{"qw", "wq", "as", "qa", "wa", "az", "sz", "q1", "q2", "gh", "bn", "ty", "p0", "p-", "lo", "lp", "mk", "mj"} {
+		x, y := []rune(pair)[0], []rune(pair)[1]
+		assert.True(t, keyboardAdjacent(x, y), pair)
+		assert.True(t, keyboardAdjacent(y, x), pair)
+	}
+	for _, pair := range []string{"qe", "qs", "xa", "zd", "ml", "aa", "a_", "1a"} {
+		x, y := []rune(pair)[0], []rune(pair)[1]
+		assert.False(t, keyboardAdjacent(x, y), pair)
+	}
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+var (
+	ErrInvalidZone         = errors.New("invalid zone")
+	ErrZoneNotPublicSuffix = errors.New("zone is not an ICANN public suffix")
+)
+
+// Zone is a zone domains are registered in, e.g. build or co.uk, always normalized: lowercase, without
+// leading or trailing dots. Use NewZone to get one from untrusted input.
+type Zone string
+
+// NewZone returns the normalized zone of a string, or an error wrapping ErrInvalidZone if it is not a valid
+// zone: its labels must be valid, and its top-level label must not be all digits
+func NewZone(s string) (Zone, error) {
+	name := DomainName(strings.Trim(NormalizeString(strings.ToLower(s)), "."))
+	if err := name.Validate(); err != nil {
+		return "", fmt.Errorf("%w %q: %w", ErrInvalidZone, s, err)
+	}
+	labels := name.GetLabels()
+	if tld := labels[len(labels)-1].String(); strings.Trim(tld, "0123456789") == "" {
+		return "", fmt.Errorf("%w %q: the top-level label cannot be all digits", ErrInvalidZone, s)
+	}
+	return Zone(name), nil
+}
+
+// String returns the zone as a string
+func (z Zone) String() string {
+	return string(z)
+}
+
+// IsTLD returns true if the zone is a top-level domain, a single label
+func (z Zone) IsTLD() bool {
+	return z != "" && !strings.Contains(string(z), ".")
+}
+
+// CheckPublicSuffix returns an error wrapping ErrZoneNotPublicSuffix unless the zone is a public suffix of
+// the ICANN section of the Public Suffix List compiled into this build, e.g. build or co.uk
+func (z Zone) CheckPublicSuffix() error {
+	suffix, icann := publicsuffix.PublicSuffix(string(z))
+	if !icann || suffix != string(z) {
+		return fmt.Errorf("%w: .%s", ErrZoneNotPublicSuffix, z)
+	}
+	return nil
+}
+
+// Contains returns true if the domain name is registered directly in the zone, see DomainName.CheckZone
+func (z Zone) Contains(d DomainName) bool {
+	return d.CheckZone(string(z)) == nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for Zone
+func (z Zone) MarshalText() ([]byte, error) {
+	return []byte(z), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for Zone, the zone is normalized and
+// validated. It also applies to the keys of JSON objects, so files written with other cases or dots are
+// read as the same zone. Empty text is the zero Zone, which MarshalText writes for structs without a zone.
+func (z *Zone) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*z = ""
+		return nil
+	}
+	zone, err := NewZone(string(text))
+	if err != nil {
+		return err
+	}
+	*z = zone
+	return nil
+}
+
+// Value implements the driver.Valuer interface for Zone, it is stored as text
+func (z Zone) Value() (driver.Value, error) {
+	return string(z), nil
+}
+
+// Scan implements the sql.Scanner interface for Zone, the stored text is normalized and validated
+func (z *Zone) Scan(src any) error {
+	s, err := scanText(src, "Zone")
+	if err != nil {
+		return err
+	}
+	return z.UnmarshalText([]byte(s))
+}
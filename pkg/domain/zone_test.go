@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewZone(t *testing.T) {
+	tests := []struct {
+		name     string
+		zone     string
+		expected Zone
+		err      string
+	}{
+		{"tld", "build", "build", ""},
+		{"case", "BUILD", "build", ""},
+		{"dots", ".build.", "build", ""},
+		{"whitespace", " build\n", "build", ""},
+		{"second level", "Co.UK", "co.uk", ""},
+		{"IDN", "xn--p1ai", "xn--p1ai", ""},
+		{"empty", "", "", "invalid domain name length"},
+		{"invalid character", "bu_ild", "", "invalid character"},
+		{"empty label", "co..uk", "", "invalid label length"},
+		{"numeric tld", "123", "", "all digits"},
+		{"numeric tld of second level", "example.42", "", "all digits"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			zone, err := NewZone(test.zone)
+			if test.err != "" {
+				assert.ErrorIs(t, err, ErrInvalidZone)
+				assert.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, zone)
+		})
+	}
+}
+
+func TestZone_IsTLD(t *testing.T) {
+	assert.True(t, Zone("build").IsTLD())
+	assert.False(t, Zone("co.uk").IsTLD())
+	assert.False(t, Zone("").IsTLD())
+}
+
+func TestZone_CheckPublicSuffix(t *testing.T) {
+	for _, zone := range []Zone{"com", "build", "co.uk"} {
+		assert.NoError(t, zone.CheckPublicSuffix(), zone)
+	}
+	for _, zone := range []Zone{"example.com", "notatld", "localhost"} {
+		assert.ErrorIs(t, zone.CheckPublicSuffix(), ErrZoneNotPublicSuffix, zone)
+	}
+}
+
+func TestZone_Contains(t *testing.T) {
+	assert.True(t, Zone("build").Contains("example.build"))
+	assert.False(t, Zone("build").Contains("www.example.build"))
+	assert.False(t, Zone("build").Contains("example.shop"))
+}
+
+func TestZone_JSON(t *testing.T) {
+	// Zones are normalized as map keys too, so files written with other cases are read as the same zone
+	var registry map[Zone]string
+	require.NoError(t, json.Unmarshal([]byte(`{"Build": "0.0.1", ".shop": "0.0.2"}`), &registry))
+	assert.Equal(t, map[Zone]string{"build": "0.0.1", "shop": "0.0.2"}, registry)
+
+	data, err := json.Marshal(registry)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"build": "0.0.1", "shop": "0.0.2"}`, string(data))
+
+	var zone Zone
+	assert.ErrorIs(t, json.Unmarshal([]byte(`"bu ild"`), &zone), ErrInvalidZone)
+
+	// The zero Zone of structs without a zone reads back as the zero Zone
+	zone = "build"
+	require.NoError(t, json.Unmarshal([]byte(`""`), &zone))
+	assert.Equal(t, Zone(""), zone)
+}
+
+func TestZone_SQL(t *testing.T) {
+	value, err := Zone("build").Value()
+	require.NoError(t, err)
+	assert.Equal(t, "build", value)
+
+	var zone Zone
+	require.NoError(t, zone.Scan([]byte("BUILD")))
+	assert.Equal(t, Zone("build"), zone)
+	assert.ErrorContains(t, zone.Scan(nil), "cannot scan NULL into Zone")
+}
@@ -0,0 +1,178 @@
+// Package domainlist reads lists of registered domains, either one domain per line or CSV,
+// used to bootstrap the ledger of zones that predate event logging.
+package domainlist
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+)
+
+var (
+	ErrNoZone = errors.New("domain has no zone")
+
+	// ErrStop may be returned by the callback of Read to stop reading without error
+	ErrStop = errors.New("stop reading")
+)
+
+// Header names recognised in the first row of a CSV list
+var (
+	domainColumns     = []string{"domain", "domain_name", "name"}
+	registeredColumns = []string{"registered_at", "registration_time", "created", "created_at"}
+	registrarColumns  = []string{"registrar", "registrar_id"}
+)
+
+// timeLayouts are the accepted formats of registration times
+var timeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"}
+
+// Entry is one domain of a list
+type Entry struct {
+	Line         int       // 1-based line of the entry in the list
+	Domain       string    // Normalized domain name
+	Zone         string    // Parent domain of Domain
+	RegisteredAt time.Time // Zero if the list does not say
+	RegistrarID  string
+	Err          error // Set when the entry is invalid, the other fields may then be incomplete
+}
+
+// IsCSV reports whether a list file is read as CSV, based on its extension
+func IsCSV(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".csv")
+}
+
+// Read calls fn for every entry of a list, in order. Plain lists hold one domain per line,
+// CSV lists hold the domain in the first column, optionally followed by the registration time
+// and the registrar, or in the columns named by a header row. Blank lines and lines starting
+// with # are skipped. Invalid entries are passed to fn with Err set.
+func Read(r io.Reader, isCSV bool, fn func(Entry) error) error {
+	var err error
+	if isCSV {
+		err = readCSV(r, fn)
+	} else {
+		err = readPlain(r, fn)
+	}
+	if errors.Is(err, ErrStop) {
+		return nil
+	}
+	return err
+}
+
+// readPlain reads a list of one domain per line
+func readPlain(r io.Reader, fn func(Entry) error) error {
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if err := fn(newEntry(line, text, "", "")); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// readCSV reads a CSV list, with or without header row
+func readCSV(r io.Reader, fn func(Entry) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	domainCol, registeredCol, registrarCol := 0, 1, 2
+	first := true
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line, _ := reader.FieldPos(0)
+
+		if first {
+			first = false
+			if columnIndex(record, domainColumns) >= 0 {
+				domainCol = columnIndex(record, domainColumns)
+				registeredCol = columnIndex(record, registeredColumns)
+				registrarCol = columnIndex(record, registrarColumns)
+				continue
+			}
+		}
+
+		entry := newEntry(line, field(record, domainCol), field(record, registeredCol), field(record, registrarCol))
+		if entry.Domain == "" && entry.Err == nil {
+			continue // Blank line
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+}
+
+// newEntry validates the fields of a list entry
+func newEntry(line int, name, registeredAt, registrar string) Entry {
+	entry := Entry{Line: line, RegistrarID: registrar}
+	if name == "" {
+		return entry
+	}
+	dn, err := domain.NewDomainName(name)
+	if err != nil {
+		entry.Domain = name
+		entry.Err = fmt.Errorf("line %d: %q: %w", line, name, err)
+		return entry
+	}
+	entry.Domain = dn.String()
+	entry.Zone = dn.ParentDomain()
+	if entry.Zone == "" {
+		entry.Err = fmt.Errorf("line %d: %q: %w", line, name, ErrNoZone)
+		return entry
+	}
+	if registeredAt != "" {
+		if entry.RegisteredAt, err = parseTime(registeredAt); err != nil {
+			entry.Err = fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return entry
+}
+
+// parseTime parses a registration time in any of the accepted layouts
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a time (e.g. 2025-08-01 or 2025-08-01T12:00:00Z)", s)
+}
+
+// columnIndex returns the index of the first header cell matching one of names, or -1
+func columnIndex(header []string, names []string) int {
+	for i, cell := range header {
+		cell = strings.ToLower(strings.TrimSpace(cell))
+		for _, name := range names {
+			if cell == name {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// field returns the trimmed value of a column, or an empty string if the record has no such column
+func field(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
@@ -0,0 +1,70 @@
+package domainlist
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readAll collects all entries of a list
+func readAll(t *testing.T, list string, isCSV bool) []Entry {
+	var entries []Entry
+	require.NoError(t, Read(strings.NewReader(list), isCSV, func(e Entry) error {
+		entries = append(entries, e)
+		return nil
+	}))
+	return entries
+}
+
+func TestRead_Plain(t *testing.T) {
+	entries := readAll(t, "# backlog of .build\nExample.BUILD\n\n  other.build  \nbuild\nbad_domain!.build\n", false)
+	require.Len(t, entries, 4)
+
+	assert.Equal(t, Entry{Line: 2, Domain: "example.build", Zone: "build"}, entries[0])
+	assert.Equal(t, 4, entries[1].Line)
+	assert.Equal(t, "other.build", entries[1].Domain)
+	assert.ErrorIs(t, entries[2].Err, ErrNoZone)
+	assert.Error(t, entries[3].Err)
+}
+
+func TestRead_CSVWithoutHeader(t *testing.T) {
+	entries := readAll(t, "example.build,2025-08-01,registrar-1\nother.build\n", true)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "example.build", entries[0].Domain)
+	assert.Equal(t, time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC), entries[0].RegisteredAt)
+	assert.Equal(t, "registrar-1", entries[0].RegistrarID)
+	assert.True(t, entries[1].RegisteredAt.IsZero())
+}
+
+func TestRead_CSVWithHeader(t *testing.T) {
+	entries := readAll(t, "registrar,Domain,created_at\nregistrar-1,example.build,2025-08-01T12:00:00Z\nregistrar-2,other.build,yesterday\n", true)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, 2, entries[0].Line)
+	assert.Equal(t, "example.build", entries[0].Domain)
+	assert.Equal(t, "registrar-1", entries[0].RegistrarID)
+	assert.Equal(t, time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC), entries[0].RegisteredAt)
+	assert.ErrorContains(t, entries[1].Err, "line 3")
+}
+
+func TestRead_Stop(t *testing.T) {
+	var domains []string
+	err := Read(strings.NewReader("a.build\nb.build\nc.build\n"), false, func(e Entry) error {
+		domains = append(domains, e.Domain)
+		if len(domains) == 2 {
+			return ErrStop
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.build", "b.build"}, domains)
+}
+
+func TestIsCSV(t *testing.T) {
+	assert.True(t, IsCSV("backlog.CSV"))
+	assert.False(t, IsCSV("backlog.txt"))
+}
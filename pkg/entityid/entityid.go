@@ -0,0 +1,137 @@
+package entityid
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+)
+
+var (
+	ErrEmptyEntityID   = errors.New("empty entity id")
+	ErrChecksumInvalid = errors.New("entity id checksum does not match the configured network")
+	ErrUnknownNetwork  = errors.New("unknown hedera network")
+)
+
+// ledgerClient returns a client that only carries the ledger ID of the given network.
+// It never opens a connection and is used purely for checksum calculation and validation.
+// For networks without a well known ledger ID (e.g. "local") it returns nil, which disables checksum handling.
+func ledgerClient(network string) (*hedera.Client, error) {
+	switch strings.ToLower(strings.TrimSpace(network)) {
+	case "", string(hedera.NetworkNameTestnet):
+		return clientWithLedger(hedera.NewLedgerIDTestnet()), nil
+	case string(hedera.NetworkNameMainnet):
+		return clientWithLedger(hedera.NewLedgerIDMainnet()), nil
+	case string(hedera.NetworkNamePreviewnet):
+		return clientWithLedger(hedera.NewLedgerIDPreviewnet()), nil
+	case "local", "localhost":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownNetwork, network)
+	}
+}
+
+func clientWithLedger(id *hedera.LedgerID) *hedera.Client {
+	client := &hedera.Client{}
+	client.SetLedgerID(*id)
+	return client
+}
+
+// hasChecksum reports whether an entity ID string carries a checksum suffix (e.g. "0.0.123-vfmkw")
+func hasChecksum(s string) bool {
+	return strings.Contains(s, "-")
+}
+
+// ParseToken parses "shard.realm.num" with an optional checksum suffix into a hedera.TokenID.
+// When a checksum is present it must be valid for the given network, otherwise ErrChecksumInvalid is returned.
+func ParseToken(s, network string) (hedera.TokenID, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return hedera.TokenID{}, ErrEmptyEntityID
+	}
+	id, err := hedera.TokenIDFromString(s)
+	if err != nil {
+		return hedera.TokenID{}, fmt.Errorf("invalid token id %q: %w", s, err)
+	}
+	if !hasChecksum(s) {
+		return id, nil
+	}
+	client, err := ledgerClient(network)
+	if err != nil {
+		return hedera.TokenID{}, err
+	}
+	if err := id.ValidateChecksum(client); err != nil {
+		return hedera.TokenID{}, fmt.Errorf("%w: token %s on %s: %v", ErrChecksumInvalid, s, network, err)
+	}
+	return id, nil
+}
+
+// ParseAccount parses "shard.realm.num" with an optional checksum suffix into a hedera.AccountID.
+// When a checksum is present it must be valid for the given network, otherwise ErrChecksumInvalid is returned.
+func ParseAccount(s, network string) (hedera.AccountID, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return hedera.AccountID{}, ErrEmptyEntityID
+	}
+	id, err := hedera.AccountIDFromString(s)
+	if err != nil {
+		return hedera.AccountID{}, fmt.Errorf("invalid account id %q: %w", s, err)
+	}
+	if !hasChecksum(s) {
+		return id, nil
+	}
+	client, err := ledgerClient(network)
+	if err != nil {
+		return hedera.AccountID{}, err
+	}
+	if err := id.ValidateChecksum(client); err != nil {
+		return hedera.AccountID{}, fmt.Errorf("%w: account %s on %s: %v", ErrChecksumInvalid, s, network, err)
+	}
+	return id, nil
+}
+
+// ParseTopic parses "shard.realm.num" with an optional checksum suffix into a hedera.TopicID.
+// When a checksum is present it must be valid for the given network, otherwise ErrChecksumInvalid is returned.
+func ParseTopic(s, network string) (hedera.TopicID, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return hedera.TopicID{}, ErrEmptyEntityID
+	}
+	id, err := hedera.TopicIDFromString(s)
+	if err != nil {
+		return hedera.TopicID{}, fmt.Errorf("invalid topic id %q: %w", s, err)
+	}
+	if !hasChecksum(s) {
+		return id, nil
+	}
+	client, err := ledgerClient(network)
+	if err != nil {
+		return hedera.TopicID{}, err
+	}
+	if err := id.ValidateChecksum(client); err != nil {
+		return hedera.TopicID{}, fmt.Errorf("%w: topic %s on %s: %v", ErrChecksumInvalid, s, network, err)
+	}
+	return id, nil
+}
+
+// WithChecksum renders an entity ID ("shard.realm.num") with the checksum for the given network appended.
+// Any existing checksum is replaced. If the checksum cannot be computed the ID is returned unchanged,
+// so it is always safe to use in output.
+func WithChecksum(s, network string) string {
+	base := strings.SplitN(strings.TrimSpace(s), "-", 2)[0]
+	client, err := ledgerClient(network)
+	if err != nil || client == nil {
+		return s
+	}
+	// Token, account and topic checksums are computed identically from "shard.realm.num"
+	id, err := hedera.TokenIDFromString(base)
+	if err != nil {
+		return s
+	}
+	withChecksum, err := id.ToStringWithChecksum(*client)
+	if err != nil {
+		return s
+	}
+	return withChecksum
+}
@@ -0,0 +1,80 @@
+package entityid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithChecksum(t *testing.T) {
+	testnet := WithChecksum("0.0.123", "testnet")
+	mainnet := WithChecksum("0.0.123", "mainnet")
+
+	assert.Regexp(t, `^0\.0\.123-[a-z]{5}$`, testnet)
+	assert.Regexp(t, `^0\.0\.123-[a-z]{5}$`, mainnet)
+	assert.NotEqual(t, testnet, mainnet, "checksums must differ per network")
+
+	// An existing checksum is replaced, not duplicated
+	assert.Equal(t, testnet, WithChecksum(mainnet, "testnet"))
+	// Networks without a ledger ID and malformed IDs are rendered unchanged
+	assert.Equal(t, "0.0.123", WithChecksum("0.0.123", "local"))
+	assert.Equal(t, "not-an-id", WithChecksum("not-an-id", "testnet"))
+}
+
+func TestParseToken(t *testing.T) {
+	testnet := WithChecksum("0.0.6879870", "testnet")
+	mainnet := WithChecksum("0.0.6879870", "mainnet")
+
+	tests := []struct {
+		name        string
+		input       string
+		network     string
+		expectedNum uint64
+		expectedErr error
+	}{
+		{"plain", "0.0.6879870", "testnet", 6879870, nil},
+		{"padded", " 0.0.6879870 ", "testnet", 6879870, nil},
+		{"valid checksum", testnet, "testnet", 6879870, nil},
+		{"checksum from other network", mainnet, "testnet", 0, ErrChecksumInvalid},
+		{"empty", "", "testnet", 0, ErrEmptyEntityID},
+		{"unknown network", testnet, "devnet", 0, ErrUnknownNetwork},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := ParseToken(tt.input, tt.network)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedNum, id.Token)
+		})
+	}
+
+	_, err := ParseToken("0.0", "testnet")
+	assert.Error(t, err)
+}
+
+func TestParseAccount(t *testing.T) {
+	id, err := ParseAccount(WithChecksum("0.0.5332375", "testnet"), "testnet")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5332375), id.Account)
+
+	_, err = ParseAccount(WithChecksum("0.0.5332375", "mainnet"), "testnet")
+	assert.ErrorIs(t, err, ErrChecksumInvalid)
+}
+
+func TestParseTopic(t *testing.T) {
+	id, err := ParseTopic(WithChecksum("0.0.6880130", "previewnet"), "previewnet")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(6880130), id.Topic)
+
+	_, err = ParseTopic(WithChecksum("0.0.6880130", "previewnet"), "mainnet")
+	assert.ErrorIs(t, err, ErrChecksumInvalid)
+
+	// Checksums cannot be validated on a local network, so they are accepted as-is
+	_, err = ParseTopic("0.0.6880130-abcde", "local")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,48 @@
+// Package eventhash computes the canonical hash of registry events, linking every minted NFT to the exact
+// event it was minted for. Events are canonicalized before hashing, so the hash does not depend on key order,
+// whitespace or escaping choices of the registry that wrote the log.
+package eventhash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// Algorithm identifies the canonicalization and digest
+const Algorithm = "sha256-canonical-json"
+
+// Canonicalize re-encodes a JSON document with object keys sorted, no insignificant whitespace
+// and no HTML escaping. Numbers keep their literal representation.
+func Canonicalize(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("invalid event JSON: %w", err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("invalid event JSON: trailing data")
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	// Maps are encoded with sorted keys
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Sum returns the hex SHA-256 of the canonical form of a JSON event
+func Sum(event []byte) (string, error) {
+	canonical, err := Canonicalize(event)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,34 @@
+package eventhash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalize(t *testing.T) {
+	canonical, err := Canonicalize([]byte(`{ "z": "build", "o": "a<b>.build", "n": 1.50, "nested": {"b": true, "a": null} }`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"n":1.50,"nested":{"a":null,"b":true},"o":"a<b>.build","z":"build"}`, string(canonical))
+}
+
+func TestSum_IndependentOfFormatting(t *testing.T) {
+	a, err := Sum([]byte(`{"o":"example.build","z":"build"}`))
+	require.NoError(t, err)
+	b, err := Sum([]byte("{\n  \"z\": \"build\",\n  \"o\": \"example.build\"\n}"))
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+	assert.Len(t, a, 64)
+
+	c, err := Sum([]byte(`{"o":"other.build","z":"build"}`))
+	require.NoError(t, err)
+	assert.NotEqual(t, a, c)
+}
+
+func TestSum_Invalid(t *testing.T) {
+	_, err := Sum([]byte(`{"o":`))
+	assert.Error(t, err)
+	_, err = Sum([]byte(`{} {}`))
+	assert.Error(t, err)
+}
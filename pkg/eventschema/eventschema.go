@@ -0,0 +1,232 @@
+// Package eventschema decodes registry events by the version of the event schema they declare, so the
+// "registry-event" format can evolve while logs of every version remain readable. Events declare their version
+// in the "v" member; events without it are version 1, the format registries have written from the start.
+package eventschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// VersionField is the member of the event object declaring its schema version
+const VersionField = "v"
+
+// Version1 is the original event schema, assumed for events that declare no version
+const Version1 = 1
+
+// Types of the objects events are about, in the "t" member. Events without type are domain events.
+const (
+	TypeDomain  = "domain"
+	TypeHost    = "host"    // A nameserver, the object of the event is its host name
+	TypeContact = "contact" // A contact, the object of the event is its handle, personal data that must not be published
+)
+
+var (
+	ErrUnknownVersion = errors.New("unknown event schema version")
+	ErrInvalidEvent   = errors.New("invalid event")
+)
+
+// Event is a registry event decoded from any schema version
+type Event struct {
+	Version     int      // Schema version the event was decoded with
+	Initiator   string   // Entity that initiated the event
+	RegistrarID string   // Sponsoring registrar
+	Registrant  string   // Handle of the registrant contact, personal data that must not be published
+	Type        string   // Type of object, e.g. domain
+	DomainName  string   // Object the event is about
+	Action      string   // What happened, e.g. create
+	Timestamp   string   // When it happened, as written by the registry
+	Zone        string   // Zone of the domain
+	Nameservers []string // Nameservers the domain was delegated to, if the registry included them
+	Addresses   []string // IP addresses of a host, if the registry included them
+}
+
+// ObjectType returns the type of the object of the event, TypeDomain when the event declares none
+func (e Event) ObjectType() string {
+	if t := strings.ToLower(strings.TrimSpace(e.Type)); t != "" {
+		return t
+	}
+	return TypeDomain
+}
+
+// Decoder decodes the event object of one schema version and validates it against that version
+type Decoder func(data []byte) (Event, error)
+
+// Registry maps schema versions to their decoders
+type Registry struct {
+	decoders map[int]Decoder
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{decoders: make(map[int]Decoder)}
+}
+
+// Default returns a registry of all versions of the event schema this build can decode
+func Default() *Registry {
+	r := NewRegistry()
+	if err := r.Register(Version1, DecodeV1); err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Register adds the decoder of a schema version. A version can only be registered once.
+func (r *Registry) Register(version int, decoder Decoder) error {
+	if version < 1 {
+		return fmt.Errorf("invalid schema version %d", version)
+	}
+	if _, dup := r.decoders[version]; dup {
+		return fmt.Errorf("schema version %d is already registered", version)
+	}
+	r.decoders[version] = decoder
+	return nil
+}
+
+// Versions returns the registered schema versions in ascending order
+func (r *Registry) Versions() []int {
+	versions := make([]int, 0, len(r.decoders))
+	for v := range r.decoders {
+		versions = append(versions, v)
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// Decode decodes an event object with the decoder of the version it declares. An event of a version
+// without decoder fails with ErrUnknownVersion, an event not matching its version with ErrInvalidEvent.
+func (r *Registry) Decode(data []byte) (Event, error) {
+	version, err := DeclaredVersion(data)
+	if err != nil {
+		return Event{}, err
+	}
+	decoder, ok := r.decoders[version]
+	if !ok {
+		return Event{Version: version}, fmt.Errorf("%w %d (known: %v)", ErrUnknownVersion, version, r.Versions())
+	}
+	event, err := decoder(data)
+	if err != nil {
+		return Event{Version: version}, err
+	}
+	event.Version = version
+	return event, nil
+}
+
+// DeclaredVersion returns the schema version declared by an event object, Version1 if it declares none
+func DeclaredVersion(data []byte) (int, error) {
+	var header map[string]json.RawMessage
+	if err := json.Unmarshal(data, &header); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	raw, ok := header[VersionField]
+	if !ok || bytes.Equal(raw, []byte("null")) {
+		return Version1, nil
+	}
+	var version int
+	if err := json.Unmarshal(raw, &version); err != nil || version < 1 {
+		return 0, fmt.Errorf("%w: schema version %s is not a positive integer", ErrInvalidEvent, raw)
+	}
+	return version, nil
+}
+
+// eventV1 is the event object of schema version 1
+type eventV1 struct {
+	Initiator   string   `json:"i"`
+	RegistrarID string   `json:"r"`
+	Registrant  string   `json:"rg"`
+	Type        string   `json:"t"`
+	DomainName  string   `json:"o"`
+	Action      string   `json:"e"`
+	Timestamp   string   `json:"s"`
+	Zone        string   `json:"z"`
+	Nameservers []string `json:"ns"`
+	Addresses   []string `json:"ip"`
+}
+
+// DecodeV1 decodes an event of schema version 1. The object (o) and its zone are required, the registrant (rg)
+// and the nameservers of a domain (ns) are optional. The object of host events is the host name, normalized as
+// nameservers are, with its IP addresses (ip) optional. The object of contact events is the contact handle.
+func DecodeV1(data []byte) (Event, error) {
+	var e eventV1
+	if err := json.Unmarshal(data, &e); err != nil {
+		return Event{}, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	if e.DomainName == "" {
+		return Event{}, fmt.Errorf("%w: no domain (o)", ErrInvalidEvent)
+	}
+	if e.Zone == "" {
+		return Event{}, fmt.Errorf("%w: no zone (z) for %s", ErrInvalidEvent, e.DomainName)
+	}
+	nameservers, err := NormalizeNameservers(e.Nameservers)
+	if err != nil {
+		return Event{}, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	addresses, err := NormalizeAddresses(e.Addresses)
+	if err != nil {
+		return Event{}, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	switch (Event{Type: e.Type}).ObjectType() {
+	case TypeHost:
+		if e.DomainName, err = NormalizeHost(e.DomainName); err != nil {
+			return Event{}, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+		}
+	case TypeContact:
+		e.DomainName = strings.TrimSpace(e.DomainName)
+	}
+	return Event{
+		Initiator:   e.Initiator,
+		RegistrarID: e.RegistrarID,
+		Registrant:  strings.TrimSpace(e.Registrant),
+		Type:        e.Type,
+		DomainName:  e.DomainName,
+		Action:      e.Action,
+		Timestamp:   e.Timestamp,
+		Zone:        e.Zone,
+		Nameservers: nameservers,
+		Addresses:   addresses,
+	}, nil
+}
+
+// NormalizeNameservers lowercases nameserver host names, strips their trailing dot and sorts them without
+// repeats, so the nameservers of a domain compare equal however they were written
+func NormalizeNameservers(nameservers []string) ([]string, error) {
+	var normalized []string
+	for _, ns := range nameservers {
+		host, err := NormalizeHost(ns)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, host)
+	}
+	sort.Strings(normalized)
+	return slices.Compact(normalized), nil
+}
+
+// NormalizeHost lowercases the host name of a nameserver and strips its trailing dot
+func NormalizeHost(name string) (string, error) {
+	host := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+	if host == "" || strings.ContainsAny(host, " /:@") || !strings.Contains(host, ".") {
+		return "", fmt.Errorf("invalid nameserver %q", name)
+	}
+	return host, nil
+}
+
+// NormalizeAddresses writes IP addresses in their canonical form and sorts them without repeats
+func NormalizeAddresses(addresses []string) ([]string, error) {
+	var normalized []string
+	for _, address := range addresses {
+		ip, err := netip.ParseAddr(strings.TrimSpace(address))
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP address %q", address)
+		}
+		normalized = append(normalized, ip.Unmap().String())
+	}
+	sort.Strings(normalized)
+	return slices.Compact(normalized), nil
+}
@@ -0,0 +1,94 @@
+package eventschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const event = `{"i":"registrar-1","r":"registrar-1","t":"domain","o":"example.build","e":"create","s":"2025-08-01T12:00:00Z","z":"build"}`
+
+func TestDecode_V1(t *testing.T) {
+	r := Default()
+	assert.Equal(t, []int{Version1}, r.Versions())
+
+	for _, data := range []string{event, `{"v":1,"o":"example.build","z":"build","e":"create"}`} {
+		e, err := r.Decode([]byte(data))
+		require.NoError(t, err, data)
+		assert.Equal(t, Version1, e.Version)
+		assert.Equal(t, "example.build", e.DomainName)
+		assert.Equal(t, "build", e.Zone)
+		assert.Equal(t, "create", e.Action)
+	}
+}
+
+func TestDecode_Nameservers(t *testing.T) {
+	e, err := Default().Decode([]byte(`{"o":"example.build","z":"build","ns":["NS2.example.net.","ns1.example.net","ns2.example.net"]}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ns1.example.net", "ns2.example.net"}, e.Nameservers)
+	assert.Empty(t, e.Registrant)
+
+	_, err = Default().Decode([]byte(`{"o":"example.build","z":"build","ns":["localhost"]}`))
+	assert.ErrorIs(t, err, ErrInvalidEvent)
+}
+
+func TestDecode_Host(t *testing.T) {
+	e, err := Default().Decode([]byte(`{"t":"host","o":"NS1.Example.build.","z":"build","e":"update","ip":["192.0.2.1","2001:DB8::1","::ffff:192.0.2.1"]}`))
+	require.NoError(t, err)
+	assert.Equal(t, TypeHost, e.ObjectType())
+	assert.Equal(t, "ns1.example.build", e.DomainName)
+	assert.Equal(t, []string{"192.0.2.1", "2001:db8::1"}, e.Addresses)
+
+	_, err = Default().Decode([]byte(`{"t":"host","o":"localhost","z":"build"}`))
+	assert.ErrorIs(t, err, ErrInvalidEvent)
+	_, err = Default().Decode([]byte(`{"t":"host","o":"ns1.example.build","z":"build","ip":["192.0.2.256"]}`))
+	assert.ErrorIs(t, err, ErrInvalidEvent)
+
+	e, err = Default().Decode([]byte(event))
+	require.NoError(t, err)
+	assert.Equal(t, TypeDomain, e.ObjectType())
+	assert.Equal(t, TypeDomain, Event{}.ObjectType())
+}
+
+func TestDecode_Contact(t *testing.T) {
+	e, err := Default().Decode([]byte(`{"t":"contact","o":" C-1234 ","z":"build","e":"update","s":"2025-08-01T12:00:00Z"}`))
+	require.NoError(t, err)
+	assert.Equal(t, TypeContact, e.ObjectType())
+	assert.Equal(t, "C-1234", e.DomainName)
+	assert.Equal(t, "update", e.Action)
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	r := Default()
+	for _, data := range []string{
+		`not json`,
+		`{"z":"build"}`,
+		`{"o":"example.build"}`,
+		`{"o":5,"z":"build"}`,
+		`{"v":"one","o":"example.build","z":"build"}`,
+		`{"v":0,"o":"example.build","z":"build"}`,
+	} {
+		_, err := r.Decode([]byte(data))
+		assert.ErrorIs(t, err, ErrInvalidEvent, data)
+	}
+}
+
+func TestDecode_UnknownVersion(t *testing.T) {
+	r := Default()
+	e, err := r.Decode([]byte(`{"v":2,"domain":"example.build"}`))
+	assert.ErrorIs(t, err, ErrUnknownVersion)
+	assert.Equal(t, 2, e.Version)
+
+	// Registering a decoder makes the version readable
+	require.NoError(t, r.Register(2, func(data []byte) (Event, error) {
+		return Event{DomainName: "example.build", Zone: "build"}, nil
+	}))
+	e, err = r.Decode([]byte(`{"v":2,"domain":"example.build"}`))
+	require.NoError(t, err)
+	assert.Equal(t, 2, e.Version)
+	assert.Equal(t, []int{1, 2}, r.Versions())
+
+	assert.Error(t, r.Register(2, DecodeV1))
+	assert.Error(t, r.Register(0, DecodeV1))
+}
@@ -0,0 +1,230 @@
+// Package eventsig verifies registry-signed events. Events carry a detached JWS (RFC 7515, appendix F)
+// over the exact bytes of the event object, signed with a registry key from a JSON Web Key Set (RFC 7517).
+// Ed25519 (EdDSA) and ECDSA P-256 (ES256) keys are supported.
+package eventsig
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Signature algorithms
+const (
+	AlgEdDSA = "EdDSA"
+	AlgES256 = "ES256"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid event signature")
+	ErrUnknownKey       = errors.New("unknown signing key")
+)
+
+// KeySet holds the public keys of the registries whose events are accepted, by key ID
+type KeySet struct {
+	keys map[string]crypto.PublicKey
+}
+
+// jwk is a JSON Web Key, restricted to the members of the supported key types
+type jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	Kid string `json:"kid"`
+	X   string `json:"x"`
+	Y   string `json:"y,omitempty"`
+}
+
+// LoadKeySet reads a JSON Web Key Set file
+func LoadKeySet(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key set: %w", err)
+	}
+	return ParseKeySet(data)
+}
+
+// ParseKeySet parses a JSON Web Key Set. Every key must have a unique key ID.
+func ParseKeySet(data []byte) (*KeySet, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse key set: %w", err)
+	}
+	ks := &KeySet{keys: make(map[string]crypto.PublicKey)}
+	for i, k := range set.Keys {
+		if k.Kid == "" {
+			return nil, fmt.Errorf("key %d has no kid", i)
+		}
+		if _, dup := ks.keys[k.Kid]; dup {
+			return nil, fmt.Errorf("key %s is listed twice", k.Kid)
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", k.Kid, err)
+		}
+		ks.keys[k.Kid] = key
+	}
+	if len(ks.keys) == 0 {
+		return nil, errors.New("key set holds no keys")
+	}
+	return ks, nil
+}
+
+// KeyIDs returns the sorted IDs of the keys of the set
+func (ks *KeySet) KeyIDs() []string {
+	ids := make([]string, 0, len(ks.keys))
+	for id := range ks.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// publicKey decodes a JSON Web Key
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("invalid x: %w", err)
+	}
+	switch {
+	case k.Kty == "OKP" && k.Crv == "Ed25519":
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 public key length")
+		}
+		return ed25519.PublicKey(x), nil
+	case k.Kty == "EC" && k.Crv == "P-256":
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y: %w", err)
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("point is not on P-256")
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s %s (expected OKP Ed25519 or EC P-256)", k.Kty, k.Crv)
+	}
+}
+
+// PublicJWK returns the JSON Web Key of a public key, as listed in the key sets of verifiers.
+// The key must be an ed25519.PublicKey or an *ecdsa.PublicKey on P-256.
+func PublicJWK(kid string, key crypto.PublicKey) ([]byte, error) {
+	b64 := base64.RawURLEncoding.EncodeToString
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		return json.Marshal(jwk{Kty: "OKP", Crv: "Ed25519", Kid: kid, X: b64(key)})
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return nil, errors.New("unsupported ECDSA curve, expected P-256")
+		}
+		return json.Marshal(jwk{Kty: "EC", Crv: "P-256", Kid: kid, X: b64(key.X.FillBytes(make([]byte, 32))), Y: b64(key.Y.FillBytes(make([]byte, 32)))})
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+// header is the protected header of a signature
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// Verify checks a detached JWS ("<header>..<signature>") over payload and returns the ID of the signing key
+func (ks *KeySet) Verify(payload []byte, jws string) (string, error) {
+	protected, detached, signature, ok := splitJWS(jws)
+	if !ok || detached != "" {
+		return "", fmt.Errorf("%w: not a detached compact JWS", ErrInvalidSignature)
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(protected)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid header encoding", ErrInvalidSignature)
+	}
+	var h header
+	if err := json.Unmarshal(headerJSON, &h); err != nil {
+		return "", fmt.Errorf("%w: invalid header", ErrInvalidSignature)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid signature encoding", ErrInvalidSignature)
+	}
+	key, found := ks.keys[h.Kid]
+	if !found {
+		return h.Kid, fmt.Errorf("%w %q", ErrUnknownKey, h.Kid)
+	}
+
+	input := []byte(protected + "." + base64.RawURLEncoding.EncodeToString(payload))
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		if h.Alg != AlgEdDSA || !ed25519.Verify(key, input, sig) {
+			return h.Kid, ErrInvalidSignature
+		}
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(input)
+		if h.Alg != AlgES256 || len(sig) != 64 ||
+			!ecdsa.Verify(key, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])) {
+			return h.Kid, ErrInvalidSignature
+		}
+	}
+	return h.Kid, nil
+}
+
+// Sign returns a detached JWS over payload, as produced by registries signing their events.
+// The key must be an ed25519.PrivateKey or an *ecdsa.PrivateKey on P-256.
+func Sign(payload []byte, kid string, key crypto.Signer) (string, error) {
+	var alg string
+	switch key := key.(type) {
+	case ed25519.PrivateKey:
+		alg = AlgEdDSA
+	case *ecdsa.PrivateKey:
+		if key.Curve != elliptic.P256() {
+			return "", errors.New("unsupported ECDSA curve, expected P-256")
+		}
+		alg = AlgES256
+	default:
+		return "", fmt.Errorf("unsupported key type %T", key)
+	}
+	headerJSON, err := json.Marshal(header{Alg: alg, Kid: kid})
+	if err != nil {
+		return "", err
+	}
+	protected := base64.RawURLEncoding.EncodeToString(headerJSON)
+	input := []byte(protected + "." + base64.RawURLEncoding.EncodeToString(payload))
+
+	var sig []byte
+	switch key := key.(type) {
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(key, input)
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256(input)
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			return "", err
+		}
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	}
+	return protected + ".." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// splitJWS splits a compact JWS into its three parts
+func splitJWS(jws string) (protected, payload, signature string, ok bool) {
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
@@ -0,0 +1,107 @@
+package eventsig
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const event = `{"i":"registrar-1","r":"registrar-1","t":"domain","o":"example.build","e":"create","s":"2025-08-01T12:00:00Z","z":"build"}`
+
+// testKeys returns an Ed25519 and a P-256 key and the key set holding their public keys
+func testKeys(t *testing.T) (ed25519.PrivateKey, *ecdsa.PrivateKey, *KeySet) {
+	pub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwks := fmt.Sprintf(`{"keys":[
+		{"kty":"OKP","crv":"Ed25519","kid":"registry-ed","x":%q},
+		{"kty":"EC","crv":"P-256","kid":"registry-ec","x":%q,"y":%q}
+	]}`, b64(pub), b64(ecKey.X.FillBytes(make([]byte, 32))), b64(ecKey.Y.FillBytes(make([]byte, 32))))
+	ks, err := ParseKeySet([]byte(jwks))
+	require.NoError(t, err)
+	return edKey, ecKey, ks
+}
+
+func TestSignVerify(t *testing.T) {
+	edKey, ecKey, ks := testKeys(t)
+	assert.Equal(t, []string{"registry-ec", "registry-ed"}, ks.KeyIDs())
+
+	sig, err := Sign([]byte(event), "registry-ed", edKey)
+	require.NoError(t, err)
+	kid, err := ks.Verify([]byte(event), sig)
+	require.NoError(t, err)
+	assert.Equal(t, "registry-ed", kid)
+
+	sig, err = Sign([]byte(event), "registry-ec", ecKey)
+	require.NoError(t, err)
+	kid, err = ks.Verify([]byte(event), sig)
+	require.NoError(t, err)
+	assert.Equal(t, "registry-ec", kid)
+}
+
+func TestPublicJWK(t *testing.T) {
+	edKey, ecKey, _ := testKeys(t)
+	edJWK, err := PublicJWK("producer-ed", edKey.Public())
+	require.NoError(t, err)
+	ecJWK, err := PublicJWK("producer-ec", ecKey.Public())
+	require.NoError(t, err)
+	ks, err := ParseKeySet([]byte(fmt.Sprintf(`{"keys":[%s,%s]}`, edJWK, ecJWK)))
+	require.NoError(t, err)
+
+	sig, err := Sign([]byte(event), "producer-ec", ecKey)
+	require.NoError(t, err)
+	kid, err := ks.Verify([]byte(event), sig)
+	require.NoError(t, err)
+	assert.Equal(t, "producer-ec", kid)
+}
+
+func TestVerify_Rejects(t *testing.T) {
+	edKey, ecKey, ks := testKeys(t)
+	sig, err := Sign([]byte(event), "registry-ed", edKey)
+	require.NoError(t, err)
+
+	// Tampered event
+	_, err = ks.Verify([]byte(event+" "), sig)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// Signed with another key than the one named
+	wrongKey, err := Sign([]byte(event), "registry-ed", ed25519.NewKeyFromSeed(make([]byte, 32)))
+	require.NoError(t, err)
+	_, err = ks.Verify([]byte(event), wrongKey)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// Key not in the set
+	unknown, err := Sign([]byte(event), "other-registry", ecKey)
+	require.NoError(t, err)
+	_, err = ks.Verify([]byte(event), unknown)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	// Not detached, or not a JWS at all
+	_, err = ks.Verify([]byte(event), "a.b.c")
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	_, err = ks.Verify([]byte(event), "garbage")
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestParseKeySet_Invalid(t *testing.T) {
+	for name, jwks := range map[string]string{
+		"empty":       `{"keys":[]}`,
+		"no kid":      `{"keys":[{"kty":"OKP","crv":"Ed25519","x":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"}]}`,
+		"unsupported": `{"keys":[{"kty":"RSA","kid":"k","n":"AQAB","e":"AQAB"}]}`,
+		"bad length":  `{"keys":[{"kty":"OKP","crv":"Ed25519","kid":"k","x":"AAAA"}]}`,
+		"not json":    `keys`,
+	} {
+		_, err := ParseKeySet([]byte(jwks))
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,143 @@
+// Package export writes the NFTs of zone collections in formats suitable for BI tooling and regulator reporting.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Supported export formats
+const (
+	FormatCSV     = "csv"
+	FormatJSON    = "json"
+	FormatParquet = "parquet"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown export format (expected csv, json or parquet)")
+)
+
+// NFT is one exported domain NFT of a zone collection
+type NFT struct {
+	Zone           string    `json:"zone" parquet:"zone"`
+	Domain         string    `json:"domain" parquet:"domain"`
+	TokenID        string    `json:"token_id" parquet:"token_id"`
+	SerialNumber   int64     `json:"serial_number" parquet:"serial_number"`
+	OwnerAccountID string    `json:"owner_account_id" parquet:"owner_account_id"`
+	Metadata       string    `json:"metadata" parquet:"metadata"` // Decoded metadata
+	CreatedAt      time.Time `json:"created_at" parquet:"created_at,timestamp"`
+	Deleted        bool      `json:"deleted" parquet:"deleted"` // Burned or wiped
+}
+
+// csvHeader lists the CSV columns, in the order of the NFT fields
+var csvHeader = []string{"zone", "domain", "token_id", "serial_number", "owner_account_id", "metadata", "created_at", "deleted"}
+
+// Writer writes NFTs to an underlying io.Writer in one of the export formats.
+// Close must be called to complete the output; it does not close the underlying writer.
+type Writer interface {
+	Write(nft NFT) error
+	Close() error
+}
+
+// NewWriter returns a Writer producing the given format
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return nil, err
+		}
+		return &csvWriter{w: cw}, nil
+	case FormatJSON:
+		return &jsonWriter{w: w}, nil
+	case FormatParquet:
+		return &parquetWriter{w: parquet.NewGenericWriter[NFT](w)}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
+
+// FormatFromPath returns the export format matching the extension of a path, or an empty string
+func FormatFromPath(path string) string {
+	switch ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), ".")); ext {
+	case FormatCSV, FormatJSON, FormatParquet:
+		return ext
+	default:
+		return ""
+	}
+}
+
+// csvWriter writes one row per NFT after a header row
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) Write(nft NFT) error {
+	return c.w.Write([]string{
+		nft.Zone,
+		nft.Domain,
+		nft.TokenID,
+		strconv.FormatInt(nft.SerialNumber, 10),
+		nft.OwnerAccountID,
+		nft.Metadata,
+		nft.CreatedAt.UTC().Format(time.RFC3339Nano),
+		strconv.FormatBool(nft.Deleted),
+	})
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonWriter streams a JSON array, one NFT per line
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonWriter) Write(nft NFT) error {
+	data, err := json.Marshal(nft)
+	if err != nil {
+		return err
+	}
+	prefix := ",\n  "
+	if j.count == 0 {
+		prefix = "[\n  "
+	}
+	j.count++
+	_, err = fmt.Fprintf(j.w, "%s%s", prefix, data)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	closing := "\n]\n"
+	if j.count == 0 {
+		closing = "[]\n"
+	}
+	_, err := io.WriteString(j.w, closing)
+	return err
+}
+
+// parquetWriter buffers row groups and writes the file footer on Close
+type parquetWriter struct {
+	w *parquet.GenericWriter[NFT]
+}
+
+func (p *parquetWriter) Write(nft NFT) error {
+	_, err := p.w.Write([]NFT{nft})
+	return err
+}
+
+func (p *parquetWriter) Close() error {
+	return p.w.Close()
+}
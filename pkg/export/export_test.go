@@ -0,0 +1,77 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNFTs = []NFT{
+	{Zone: "build", Domain: "example.build", TokenID: "0.0.6879870", SerialNumber: 1, OwnerAccountID: "0.0.5332375",
+		Metadata: "example", CreatedAt: time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)},
+	{Zone: "build", Domain: "burned.build", TokenID: "0.0.6879870", SerialNumber: 2, Metadata: "burned",
+		CreatedAt: time.Date(2025, 8, 2, 12, 0, 0, 0, time.UTC), Deleted: true},
+}
+
+// writeAll exports the test NFTs in the given format
+func writeAll(t *testing.T, format string, nfts []NFT) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, format)
+	require.NoError(t, err)
+	for _, nft := range nfts {
+		require.NoError(t, w.Write(nft))
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestCSV(t *testing.T) {
+	records, err := csv.NewReader(bytes.NewReader(writeAll(t, FormatCSV, testNFTs))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, csvHeader, records[0])
+	assert.Equal(t, []string{"build", "example.build", "0.0.6879870", "1", "0.0.5332375", "example", "2025-08-01T12:00:00Z", "false"}, records[1])
+	assert.Equal(t, "true", records[2][7])
+}
+
+func TestJSON(t *testing.T) {
+	var decoded []NFT
+	require.NoError(t, json.Unmarshal(writeAll(t, FormatJSON, testNFTs), &decoded))
+	assert.Equal(t, testNFTs, decoded)
+
+	// An empty export is still a valid document
+	require.NoError(t, json.Unmarshal(writeAll(t, FormatJSON, nil), &decoded))
+	assert.Empty(t, decoded)
+}
+
+func TestParquet(t *testing.T) {
+	data := writeAll(t, FormatParquet, testNFTs)
+
+	rows, err := parquet.Read[NFT](bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.Len(t, rows, len(testNFTs))
+	for i := range rows {
+		assert.Equal(t, testNFTs[i].Domain, rows[i].Domain)
+		assert.Equal(t, testNFTs[i].SerialNumber, rows[i].SerialNumber)
+		assert.True(t, testNFTs[i].CreatedAt.Equal(rows[i].CreatedAt))
+		assert.Equal(t, testNFTs[i].Deleted, rows[i].Deleted)
+	}
+}
+
+func TestNewWriter_UnknownFormat(t *testing.T) {
+	_, err := NewWriter(&bytes.Buffer{}, "xlsx")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestFormatFromPath(t *testing.T) {
+	assert.Equal(t, FormatCSV, FormatFromPath("build.csv"))
+	assert.Equal(t, FormatParquet, FormatFromPath("/tmp/build.PARQUET"))
+	assert.Empty(t, FormatFromPath("build.xlsx"))
+	assert.Empty(t, FormatFromPath("build"))
+}
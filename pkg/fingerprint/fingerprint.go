@@ -0,0 +1,65 @@
+// Package fingerprint derives privacy-preserving fingerprints of registrant identifiers. A fingerprint is an
+// HMAC-SHA256 of the identifier under a key held by the registry: records of the same registrant carry the
+// same fingerprint and can be linked by anybody, but only holders of the key can tell whose records they are.
+package fingerprint
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Algorithm prefixes fingerprints, so fingerprints of another scheme can never be mistaken for them
+const Algorithm = "hmac-sha256"
+
+// MinKeySize is the minimum size of a fingerprint key, in bytes
+const MinKeySize = 32
+
+// Key is a fingerprint key
+type Key struct {
+	secret []byte
+	id     string
+}
+
+// LoadKey reads a key file holding a hex encoded key of at least MinKeySize bytes, e.g. from
+// "openssl rand -hex 32"
+func LoadKey(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fingerprint key: %w", err)
+	}
+	return ParseKey(string(data))
+}
+
+// ParseKey parses a hex encoded key
+func ParseKey(s string) (*Key, error) {
+	secret, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, errors.New("invalid fingerprint key: not hex encoded")
+	}
+	if len(secret) < MinKeySize {
+		return nil, fmt.Errorf("invalid fingerprint key: %d bytes, at least %d required", len(secret), MinKeySize)
+	}
+	// The key ID tells which key a fingerprint was made with, so keys can be rotated
+	sum := sha256.Sum256(secret)
+	return &Key{secret: secret, id: hex.EncodeToString(sum[:4])}, nil
+}
+
+// ID returns the identifier of the key, derived from it
+func (k *Key) ID() string {
+	return k.id
+}
+
+// Sum returns the fingerprint of an identifier of the given kind, e.g. a registrant handle, as
+// hmac-sha256:<key ID>:<hex MAC>. The kind is part of the MAC, so identifiers of different kinds never link.
+func (k *Key) Sum(kind, identifier string) string {
+	mac := hmac.New(sha256.New, k.secret)
+	mac.Write([]byte(kind))
+	mac.Write([]byte{0})
+	mac.Write([]byte(strings.TrimSpace(identifier)))
+	return fmt.Sprintf("%s:%s:%s", Algorithm, k.id, hex.EncodeToString(mac.Sum(nil)))
+}
@@ -0,0 +1,48 @@
+package fingerprint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+func TestSum(t *testing.T) {
+	key, err := ParseKey(testKey + "\n")
+	require.NoError(t, err)
+
+	fp := key.Sum("registrant", "C-1234")
+	assert.True(t, strings.HasPrefix(fp, Algorithm+":"+key.ID()+":"), fp)
+	assert.Len(t, strings.TrimPrefix(fp, Algorithm+":"+key.ID()+":"), 64)
+	assert.Equal(t, fp, key.Sum("registrant", " C-1234 "))
+	assert.NotEqual(t, fp, key.Sum("registrant", "C-1235"))
+	assert.NotEqual(t, fp, key.Sum("registrar", "C-1234"))
+
+	other, err := ParseKey(strings.Repeat("ff", 32))
+	require.NoError(t, err)
+	assert.NotEqual(t, key.ID(), other.ID())
+	assert.NotEqual(t, fp, other.Sum("registrant", "C-1234"))
+}
+
+func TestParseKey_Invalid(t *testing.T) {
+	_, err := ParseKey("not hex")
+	assert.ErrorContains(t, err, "hex")
+	_, err = ParseKey("00112233")
+	assert.ErrorContains(t, err, "at least 32")
+}
+
+func TestLoadKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fingerprint.key")
+	require.NoError(t, os.WriteFile(path, []byte(testKey), 0600))
+	key, err := LoadKey(path)
+	require.NoError(t, err)
+	assert.Len(t, key.ID(), 8)
+
+	_, err = LoadKey(filepath.Join(t.TempDir(), "missing.key"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,255 @@
+// Package hcs consumes Hedera Consensus Service topics through the gRPC streaming API of the mirror node.
+//
+// The SDK's TopicMessageQuery hands messages to a callback and retries on its own, which gives callers
+// no way to bound, cancel or resume a subscription. A Consumer owns the stream instead: it reconnects
+// after transient failures, resuming after the consensus timestamp of the last delivered message, and
+// stops when the query's end time or limit is reached or its context is cancelled.
+package hcs
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/hiero-ledger/hiero-sdk-go/v2/proto/mirror"
+	"github.com/hiero-ledger/hiero-sdk-go/v2/proto/services"
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Defaults of a Consumer
+const (
+	DefaultMinBackoff    = 250 * time.Millisecond
+	DefaultMaxBackoff    = 30 * time.Second
+	DefaultMaxReconnects = 10
+)
+
+// Message is a message of a topic, as delivered by the mirror node
+type Message struct {
+	TopicID        string
+	SequenceNumber uint64
+	ConsensusTime  time.Time
+	Contents       []byte
+	RunningHash    []byte
+	PayerAccountID string // Payer of the first chunk, only known for chunked messages
+	ChunkNumber    int    // Position of the chunk in a fragmented message, 1 for unfragmented messages
+	ChunkTotal     int    // Number of chunks of the message, 1 for unfragmented messages
+}
+
+// Position is the last message delivered by a consumer, where a later consumption resumes
+type Position struct {
+	SequenceNumber uint64    `json:"sequence_number"`
+	ConsensusTime  time.Time `json:"consensus_time"`
+}
+
+// Query selects the messages of a topic to consume
+type Query struct {
+	TopicID hedera.TopicID
+	Start   time.Time // First consensus time included, the time Consume is called if zero
+	End     time.Time // Consensus time the stream stops at (excluded), streams indefinitely if zero
+	Limit   uint64    // Number of messages after which the stream stops, unlimited if zero
+	// After resumes a previous consumption: messages up to its sequence number are skipped and the
+	// stream starts after its consensus time
+	After Position
+}
+
+// Consumer reads topic messages from the gRPC API of a mirror node
+type Consumer struct {
+	Client        mirror.ConsensusServiceClient
+	Metadata      map[string]string // Sent with every subscription, e.g. the API key of the mirror node
+	MinBackoff    time.Duration     // Delay before the first reconnect, doubled after every failed attempt
+	MaxBackoff    time.Duration
+	MaxReconnects int // Consecutive failed reconnects tolerated before giving up
+	// OnReconnect is called before every reconnect with the attempt number, the failure and the resume position
+	OnReconnect func(attempt int, err error, from Position)
+}
+
+// Dial connects to the gRPC API of a mirror node. Port 443 is spoken over TLS, as by the public mirror nodes.
+func Dial(address string) (*grpc.ClientConn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mirror node address %q: %w", address, err)
+	}
+	creds := insecure.NewCredentials()
+	if port == "443" {
+		creds = credentials.NewTLS(&tls.Config{ServerName: host, MinVersion: tls.VersionTLS12})
+	}
+	return grpc.NewClient(address, grpc.WithTransportCredentials(creds))
+}
+
+// NewConsumer returns a Consumer reading from a mirror node connection, with default backoff
+func NewConsumer(conn grpc.ClientConnInterface) *Consumer {
+	return &Consumer{
+		Client:        mirror.NewConsensusServiceClient(conn),
+		MinBackoff:    DefaultMinBackoff,
+		MaxBackoff:    DefaultMaxBackoff,
+		MaxReconnects: DefaultMaxReconnects,
+	}
+}
+
+// Consume streams the messages selected by the query to handle, in consensus order and each message
+// once, reconnecting after transient failures. It returns the position of the last handled message
+// with a nil error once the end time or the limit is reached, or with the error that stopped it:
+// the context's, handle's, or a permanent or repeated failure of the mirror node.
+func (c *Consumer) Consume(ctx context.Context, q Query, handle func(Message) error) (Position, error) {
+	pos := q.After
+	start := q.Start
+	if start.IsZero() {
+		start = time.Now()
+	}
+	if len(c.Metadata) > 0 {
+		pairs := make([]string, 0, 2*len(c.Metadata))
+		for name, value := range c.Metadata {
+			pairs = append(pairs, strings.ToLower(name), value)
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, pairs...)
+	}
+
+	var received uint64
+	backoff, failures := c.MinBackoff, 0
+	for {
+		from := start
+		if !pos.ConsensusTime.IsZero() && !pos.ConsensusTime.Before(start) {
+			from = pos.ConsensusTime.Add(time.Nanosecond)
+		}
+		query := &mirror.ConsensusTopicQuery{
+			TopicID: &services.TopicID{
+				ShardNum: int64(q.TopicID.Shard),
+				RealmNum: int64(q.TopicID.Realm),
+				TopicNum: int64(q.TopicID.Topic),
+			},
+			ConsensusStartTime: timestamp(from),
+		}
+		if !q.End.IsZero() {
+			if !from.Before(q.End) {
+				return pos, nil
+			}
+			query.ConsensusEndTime = timestamp(q.End)
+		}
+		if q.Limit > 0 {
+			query.Limit = q.Limit - received
+		}
+
+		delivered, err := c.stream(ctx, q.TopicID.String(), query, &pos, handle)
+		received += delivered
+		if q.Limit > 0 && received >= q.Limit {
+			return pos, nil
+		}
+		switch {
+		case err == nil:
+			return pos, nil
+		case ctx.Err() != nil:
+			return pos, ctx.Err()
+		case errors.As(err, new(handlerError)):
+			return pos, errors.Unwrap(err)
+		case !retryable(err):
+			return pos, fmt.Errorf("subscription to %s failed: %w", q.TopicID, err)
+		}
+
+		if delivered > 0 {
+			// The stream made progress, so the failure is not a repeated one
+			failures, backoff = 0, c.MinBackoff
+		}
+		if failures++; c.MaxReconnects > 0 && failures > c.MaxReconnects {
+			return pos, fmt.Errorf("subscription to %s failed after %d reconnects: %w", q.TopicID, c.MaxReconnects, err)
+		}
+		if c.OnReconnect != nil {
+			c.OnReconnect(failures, err, pos)
+		}
+		select {
+		case <-ctx.Done():
+			return pos, ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; c.MaxBackoff > 0 && backoff > c.MaxBackoff {
+			backoff = c.MaxBackoff
+		}
+	}
+}
+
+// handlerError marks errors returned by the message handler, which stop the consumption as they are
+type handlerError struct{ err error }
+
+func (e handlerError) Error() string { return e.err.Error() }
+func (e handlerError) Unwrap() error { return e.err }
+
+// stream runs one subscription until it ends, fails or the handler fails, advancing pos for every handled
+// message. A stream completed by the mirror node returns a nil error.
+func (c *Consumer) stream(ctx context.Context, topicID string, query *mirror.ConsensusTopicQuery, pos *Position, handle func(Message) error) (uint64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.Client.SubscribeTopic(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	var delivered uint64
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return delivered, nil
+		}
+		if err != nil {
+			return delivered, err
+		}
+		msg := message(topicID, resp)
+		if msg.SequenceNumber <= pos.SequenceNumber {
+			// Already handled before a reconnect
+			continue
+		}
+		if err := handle(msg); err != nil {
+			return delivered, handlerError{err}
+		}
+		pos.SequenceNumber, pos.ConsensusTime = msg.SequenceNumber, msg.ConsensusTime
+		delivered++
+		if query.Limit > 0 && delivered >= query.Limit {
+			return delivered, nil
+		}
+	}
+}
+
+// retryable reports whether a subscription failure is transient
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Internal, codes.Unknown, codes.Aborted, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// message converts a mirror node response
+func message(topicID string, resp *mirror.ConsensusTopicResponse) Message {
+	msg := Message{
+		TopicID:        topicID,
+		SequenceNumber: resp.GetSequenceNumber(),
+		Contents:       resp.GetMessage(),
+		RunningHash:    resp.GetRunningHash(),
+		ChunkNumber:    1,
+		ChunkTotal:     1,
+	}
+	if ts := resp.GetConsensusTimestamp(); ts != nil {
+		msg.ConsensusTime = time.Unix(ts.GetSeconds(), int64(ts.GetNanos())).UTC()
+	}
+	if chunk := resp.GetChunkInfo(); chunk != nil {
+		msg.ChunkNumber, msg.ChunkTotal = int(chunk.GetNumber()), int(chunk.GetTotal())
+		if account := chunk.GetInitialTransactionID().GetAccountID(); account != nil {
+			msg.PayerAccountID = fmt.Sprintf("%d.%d.%d", account.GetShardNum(), account.GetRealmNum(), account.GetAccountNum())
+		}
+	}
+	return msg
+}
+
+// timestamp converts a time to its protobuf representation
+func timestamp(t time.Time) *services.Timestamp {
+	return &services.Timestamp{Seconds: t.Unix(), Nanos: int32(t.Nanosecond())}
+}
@@ -0,0 +1,172 @@
+package hcs
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hiero-ledger/hiero-sdk-go/v2/proto/mirror"
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var testTopic = hedera.TopicID{Topic: 1234}
+
+// fakeMirror serves the messages of a topic, one per second from t0, and fails every stream with failCode
+// after failAfter messages
+type fakeMirror struct {
+	mirror.UnimplementedConsensusServiceServer
+
+	mu        sync.Mutex
+	messages  int
+	failAfter int
+	failCode  codes.Code
+	queries   []*mirror.ConsensusTopicQuery
+	apiKeys   []string
+}
+
+var t0 = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func (m *fakeMirror) SubscribeTopic(q *mirror.ConsensusTopicQuery, stream mirror.ConsensusService_SubscribeTopicServer) error {
+	m.mu.Lock()
+	m.queries = append(m.queries, q)
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	m.apiKeys = append(m.apiKeys, md.Get("x-api-key")...)
+	m.mu.Unlock()
+
+	start := time.Unix(q.ConsensusStartTime.Seconds, int64(q.ConsensusStartTime.Nanos))
+	sent := uint64(0)
+	for seq := 1; seq <= m.messages; seq++ {
+		at := t0.Add(time.Duration(seq) * time.Second)
+		if at.Before(start) {
+			continue
+		}
+		if q.ConsensusEndTime != nil && !at.Before(time.Unix(q.ConsensusEndTime.Seconds, int64(q.ConsensusEndTime.Nanos))) {
+			return nil
+		}
+		if m.failCode != codes.OK && int(sent) == m.failAfter {
+			return status.Error(m.failCode, "stream reset")
+		}
+		if err := stream.Send(&mirror.ConsensusTopicResponse{
+			ConsensusTimestamp: timestamp(at),
+			Message:            []byte{byte(seq)},
+			SequenceNumber:     uint64(seq),
+		}); err != nil {
+			return err
+		}
+		if sent++; q.Limit > 0 && sent == q.Limit {
+			return nil
+		}
+	}
+	// Caught up: wait for new messages until the client goes away
+	<-stream.Context().Done()
+	return nil
+}
+
+func testConsumer(t *testing.T, m *fakeMirror) *Consumer {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	mirror.RegisterConsensusServiceServer(server, m)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	c := NewConsumer(conn)
+	c.MinBackoff = time.Millisecond
+	return c
+}
+
+func collect(got *[]uint64) func(Message) error {
+	return func(msg Message) error {
+		*got = append(*got, msg.SequenceNumber)
+		return nil
+	}
+}
+
+func TestConsume_ReconnectsAndResumes(t *testing.T) {
+	m := &fakeMirror{messages: 7, failAfter: 3, failCode: codes.Unavailable}
+	c := testConsumer(t, m)
+	var reconnects []Position
+	c.OnReconnect = func(_ int, _ error, from Position) { reconnects = append(reconnects, from) }
+	c.Metadata = map[string]string{"X-Api-Key": "secret"}
+
+	var got []uint64
+	pos, err := c.Consume(context.Background(), Query{TopicID: testTopic, Start: t0, End: t0.Add(6 * time.Second)}, collect(&got))
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5}, got)
+	assert.Equal(t, Position{SequenceNumber: 5, ConsensusTime: t0.Add(5 * time.Second)}, pos)
+	require.Len(t, reconnects, 1)
+	assert.Equal(t, uint64(3), reconnects[0].SequenceNumber)
+	// The second stream starts right after the last delivered message
+	assert.Equal(t, timestamp(t0.Add(3*time.Second+time.Nanosecond)).String(), m.queries[1].ConsensusStartTime.String())
+	assert.Equal(t, []string{"secret", "secret"}, m.apiKeys)
+}
+
+func TestConsume_Limit(t *testing.T) {
+	m := &fakeMirror{messages: 10, failAfter: 2, failCode: codes.Internal}
+	var got []uint64
+	pos, err := testConsumer(t, m).Consume(context.Background(), Query{TopicID: testTopic, Start: t0, Limit: 5}, collect(&got))
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5}, got)
+	assert.Equal(t, uint64(5), pos.SequenceNumber)
+	assert.Equal(t, uint64(1), m.queries[2].Limit, "reconnects only ask for the remaining messages")
+}
+
+func TestConsume_Resume(t *testing.T) {
+	m := &fakeMirror{messages: 4}
+	var got []uint64
+	after := Position{SequenceNumber: 2, ConsensusTime: t0.Add(2 * time.Second)}
+	_, err := testConsumer(t, m).Consume(context.Background(), Query{TopicID: testTopic, Start: t0, Limit: 2, After: after}, collect(&got))
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{3, 4}, got)
+}
+
+func TestConsume_Stops(t *testing.T) {
+	// Cancellation while waiting for new messages
+	ctx, cancel := context.WithCancel(context.Background())
+	var got []uint64
+	handle := func(msg Message) error {
+		if got = append(got, msg.SequenceNumber); len(got) == 2 {
+			cancel()
+		}
+		return nil
+	}
+	pos, err := testConsumer(t, &fakeMirror{messages: 2}).Consume(ctx, Query{TopicID: testTopic, Start: t0}, handle)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, uint64(2), pos.SequenceNumber)
+
+	// Handler failures are returned as they are
+	errFull := errors.New("full")
+	_, err = testConsumer(t, &fakeMirror{messages: 3}).Consume(context.Background(), Query{TopicID: testTopic, Start: t0},
+		func(Message) error { return errFull })
+	assert.Equal(t, errFull, err)
+
+	// Permanent failures are not retried
+	m := &fakeMirror{messages: 3, failAfter: 1, failCode: codes.NotFound}
+	_, err = testConsumer(t, m).Consume(context.Background(), Query{TopicID: testTopic, Start: t0}, collect(new([]uint64)))
+	assert.Equal(t, codes.NotFound, status.Code(errors.Unwrap(err)))
+	assert.Len(t, m.queries, 1)
+
+	// Repeated failures give up
+	m = &fakeMirror{messages: 3, failAfter: 0, failCode: codes.Unavailable}
+	c := testConsumer(t, m)
+	c.MaxReconnects = 2
+	_, err = c.Consume(context.Background(), Query{TopicID: testTopic, Start: t0}, collect(new([]uint64)))
+	assert.ErrorContains(t, err, "after 2 reconnects")
+	assert.Len(t, m.queries, 3)
+}
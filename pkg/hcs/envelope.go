@@ -0,0 +1,78 @@
+package hcs
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/eventsig"
+)
+
+// EnvelopeVersion is the version of the envelope format
+const EnvelopeVersion = 1
+
+// Envelope attributes a message published to an audit topic to the producer that published it. The submit
+// key of a topic is shared by every worker, so it cannot tell producers apart: each producer signs the
+// payload with its own key instead, as a detached JWS (see pkg/eventsig) whose key ID is the producer ID.
+type Envelope struct {
+	Version   int             `json:"envelope"`
+	Producer  string          `json:"producer"`
+	Payload   json.RawMessage `json:"payload"`
+	Signature string          `json:"sig"`
+}
+
+// Producer signs the messages published by one worker
+type Producer struct {
+	ID  string
+	Key crypto.Signer // ed25519.PrivateKey or *ecdsa.PrivateKey on P-256
+}
+
+// Seal wraps a JSON payload in an envelope signed by the producer
+func (p Producer) Seal(payload []byte) ([]byte, error) {
+	if p.ID == "" {
+		return nil, errors.New("producer has no ID")
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, payload); err != nil {
+		return nil, fmt.Errorf("payload is not JSON: %w", err)
+	}
+	sig, err := eventsig.Sign(compact.Bytes(), p.ID, p.Key)
+	if err != nil {
+		return nil, err
+	}
+	// The payload is written as signed: json.Marshal would escape its &, < and > for HTML
+	var sealed bytes.Buffer
+	enc := json.NewEncoder(&sealed)
+	enc.SetEscapeHTML(false)
+	env := Envelope{Version: EnvelopeVersion, Producer: p.ID, Payload: compact.Bytes(), Signature: sig}
+	if err := enc.Encode(env); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(sealed.Bytes(), []byte("\n")), nil
+}
+
+// Open unwraps a message. Messages that are not enveloped, such as those published before envelopes
+// were introduced, are returned as the payload of an envelope without producer, and ok false.
+func Open(message []byte) (env Envelope, ok bool) {
+	if err := json.Unmarshal(message, &env); err != nil || env.Version == 0 || len(env.Payload) == 0 {
+		return Envelope{Payload: message}, false
+	}
+	return env, true
+}
+
+// Verify checks the envelope is signed by its producer, with the key of the producer in the key set
+func (e Envelope) Verify(keys *eventsig.KeySet) error {
+	if e.Producer == "" {
+		return fmt.Errorf("%w: the message has no envelope", eventsig.ErrInvalidSignature)
+	}
+	kid, err := keys.Verify(e.Payload, e.Signature)
+	if err != nil {
+		return err
+	}
+	if kid != e.Producer {
+		return fmt.Errorf("%w: signed by %s on behalf of %s", eventsig.ErrInvalidSignature, kid, e.Producer)
+	}
+	return nil
+}
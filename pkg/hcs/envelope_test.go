@@ -0,0 +1,67 @@
+package hcs
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/eventsig"
+)
+
+func testProducer(t *testing.T, id string) (Producer, *eventsig.KeySet) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	jwk, err := eventsig.PublicJWK(id, key.Public())
+	require.NoError(t, err)
+	keys, err := eventsig.ParseKeySet([]byte(fmt.Sprintf(`{"keys":[%s]}`, jwk)))
+	require.NoError(t, err)
+	return Producer{ID: id, Key: key}, keys
+}
+
+func TestEnvelope(t *testing.T) {
+	producer, keys := testProducer(t, "worker-1")
+	message, err := producer.Seal([]byte(`{"v": 1, "zone": "build"}`))
+	require.NoError(t, err)
+
+	env, ok := Open(message)
+	require.True(t, ok)
+	assert.Equal(t, "worker-1", env.Producer)
+	assert.JSONEq(t, `{"v":1,"zone":"build"}`, string(env.Payload))
+	assert.NoError(t, env.Verify(keys))
+
+	// A tampered payload
+	forged := env
+	forged.Payload = []byte(`{"v":1,"zone":"dev"}`)
+	assert.ErrorIs(t, forged.Verify(keys), eventsig.ErrInvalidSignature)
+
+	// Another producer signing on behalf of worker-1
+	rogue, rogueKeys := testProducer(t, "worker-2")
+	message, err = rogue.Seal([]byte(`{"v":1}`))
+	require.NoError(t, err)
+	env, _ = Open(message)
+	assert.NoError(t, env.Verify(rogueKeys))
+	assert.Error(t, env.Verify(keys), "a producer missing from the key set")
+	env.Producer = "worker-1"
+	assert.ErrorIs(t, env.Verify(rogueKeys), eventsig.ErrInvalidSignature)
+	_, err = producer.Seal([]byte("not json"))
+	assert.Error(t, err)
+
+	// Payloads with characters json.Marshal escapes for HTML are written as signed
+	message, err = producer.Seal([]byte(`{"registrar": "Smith & Sons <sales>"}`))
+	require.NoError(t, err)
+	env, ok = Open(message)
+	require.True(t, ok)
+	assert.Equal(t, `{"registrar":"Smith & Sons <sales>"}`, string(env.Payload))
+	assert.NoError(t, env.Verify(keys))
+
+	// Plain messages are passed through
+	env, ok = Open([]byte(`{"v":1,"zone":"build"}`))
+	assert.False(t, ok)
+	assert.Equal(t, `{"v":1,"zone":"build"}`, string(env.Payload))
+	assert.Error(t, env.Verify(keys))
+}
@@ -0,0 +1,153 @@
+// Package icann parses the monthly registry transaction reports registry operators file with ICANN
+// (Registry Agreement, Specification 3). A report is a CSV file with one row per registrar and a final
+// Totals row, named <tld>-transactions-<yyyymm>-en.csv.
+package icann
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Columns of the transaction report counting domains added and deleted during the month
+var (
+	addColumns = []string{
+		"net-adds-1-yr", "net-adds-2-yr", "net-adds-3-yr", "net-adds-4-yr", "net-adds-5-yr",
+		"net-adds-6-yr", "net-adds-7-yr", "net-adds-8-yr", "net-adds-9-yr", "net-adds-10-yr",
+	}
+	deleteColumns = []string{"deleted-domains-grace", "deleted-domains-nograce"}
+)
+
+// reportName matches the file names of transaction reports
+var reportName = regexp.MustCompile(`^([a-z0-9-]+)-transactions-(\d{6})-en\.csv$`)
+
+// RegistrarActivity is the row of one registrar in a transaction report
+type RegistrarActivity struct {
+	Registrar    string `json:"registrar"`
+	IANAID       string `json:"iana_id"`
+	TotalDomains int    `json:"total_domains"`
+	Adds         int    `json:"adds"`    // Sum of the net-adds-N-yr columns
+	Deletes      int    `json:"deletes"` // Deletes in and after the add grace period
+}
+
+// TransactionReport is a parsed monthly transaction report
+type TransactionReport struct {
+	Registrars   []RegistrarActivity `json:"registrars"`
+	TotalDomains int                 `json:"total_domains"`
+	Adds         int                 `json:"adds"`
+	Deletes      int                 `json:"deletes"`
+}
+
+// ParseFileName returns the TLD and month of a transaction report from its file name
+func ParseFileName(path string) (tld string, month time.Time, err error) {
+	m := reportName.FindStringSubmatch(strings.ToLower(filepath.Base(path)))
+	if m == nil {
+		return "", time.Time{}, fmt.Errorf("%s is not named <tld>-transactions-<yyyymm>-en.csv", filepath.Base(path))
+	}
+	month, err = time.Parse("200601", m[2])
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("invalid month in %s: %w", filepath.Base(path), err)
+	}
+	return m[1], month, nil
+}
+
+// Parse reads a transaction report. The totals are summed from the registrar rows and checked against the
+// Totals row when the report has one.
+func Parse(r io.Reader) (TransactionReport, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return TransactionReport{}, errors.New("empty transaction report")
+	}
+	if err != nil {
+		return TransactionReport{}, fmt.Errorf("invalid transaction report: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range append([]string{"registrar-name", "iana-id", "total-domains"}, append(addColumns, deleteColumns...)...) {
+		if _, ok := columns[name]; !ok {
+			return TransactionReport{}, fmt.Errorf("transaction report has no %s column", name)
+		}
+	}
+
+	var report TransactionReport
+	var totals *RegistrarActivity
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return TransactionReport{}, fmt.Errorf("invalid transaction report: %w", err)
+		}
+		row, err := parseRow(record, columns)
+		if err != nil {
+			return TransactionReport{}, fmt.Errorf("line %d: %w", line, err)
+		}
+		if strings.EqualFold(row.Registrar, "totals") {
+			totals = &row
+			continue
+		}
+		report.Registrars = append(report.Registrars, row)
+		report.TotalDomains += row.TotalDomains
+		report.Adds += row.Adds
+		report.Deletes += row.Deletes
+	}
+	if totals != nil && (totals.TotalDomains != report.TotalDomains || totals.Adds != report.Adds || totals.Deletes != report.Deletes) {
+		return TransactionReport{}, fmt.Errorf("totals row (%d domains, %d adds, %d deletes) does not match the registrar rows (%d domains, %d adds, %d deletes)",
+			totals.TotalDomains, totals.Adds, totals.Deletes, report.TotalDomains, report.Adds, report.Deletes)
+	}
+	return report, nil
+}
+
+// parseRow reads the counts of one row of the report
+func parseRow(record []string, columns map[string]int) (RegistrarActivity, error) {
+	field := func(name string) string {
+		if i := columns[name]; i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	count := func(name string) (int, error) {
+		value := field(name)
+		if value == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("%s: %q is not a count", name, value)
+		}
+		return n, nil
+	}
+
+	row := RegistrarActivity{Registrar: field("registrar-name"), IANAID: field("iana-id")}
+	var err error
+	if row.TotalDomains, err = count("total-domains"); err != nil {
+		return row, err
+	}
+	for _, name := range addColumns {
+		n, err := count(name)
+		if err != nil {
+			return row, err
+		}
+		row.Adds += n
+	}
+	for _, name := range deleteColumns {
+		n, err := count(name)
+		if err != nil {
+			return row, err
+		}
+		row.Deletes += n
+	}
+	return row, nil
+}
@@ -0,0 +1,52 @@
+package icann
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const header = "registrar-name,iana-id,total-domains,total-nameservers,net-adds-1-yr,net-adds-2-yr,net-adds-3-yr,net-adds-4-yr,net-adds-5-yr,net-adds-6-yr,net-adds-7-yr,net-adds-8-yr,net-adds-9-yr,net-adds-10-yr,net-renews-1-yr,deleted-domains-grace,deleted-domains-nograce\n"
+
+func TestParse(t *testing.T) {
+	report, err := Parse(strings.NewReader(header +
+		"Example Registrar,1000,120,4,10,2,0,0,1,0,0,0,0,0,5,1,3\n" +
+		"\"Other, Inc.\",2000,30,2,4,,,,,,,,,,1,0,1\n" +
+		"Totals,,150,6,14,2,0,0,1,0,0,0,0,0,6,1,4\n"))
+	require.NoError(t, err)
+	require.Len(t, report.Registrars, 2)
+	assert.Equal(t, RegistrarActivity{Registrar: "Example Registrar", IANAID: "1000", TotalDomains: 120, Adds: 13, Deletes: 4}, report.Registrars[0])
+	assert.Equal(t, "Other, Inc.", report.Registrars[1].Registrar)
+	assert.Equal(t, 150, report.TotalDomains)
+	assert.Equal(t, 17, report.Adds)
+	assert.Equal(t, 5, report.Deletes)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse(strings.NewReader(""))
+	assert.ErrorContains(t, err, "empty")
+
+	_, err = Parse(strings.NewReader("registrar-name,iana-id,total-domains\n"))
+	assert.ErrorContains(t, err, "net-adds-1-yr")
+
+	_, err = Parse(strings.NewReader(header + "Example Registrar,1000,ten,4,10,2,0,0,1,0,0,0,0,0,5,1,3\n"))
+	assert.ErrorContains(t, err, "line 2: total-domains")
+
+	_, err = Parse(strings.NewReader(header +
+		"Example Registrar,1000,120,4,10,2,0,0,1,0,0,0,0,0,5,1,3\n" +
+		"Totals,,120,4,11,2,0,0,1,0,0,0,0,0,5,1,3\n"))
+	assert.ErrorContains(t, err, "totals row")
+}
+
+func TestParseFileName(t *testing.T) {
+	tld, month, err := ParseFileName("reports/build-transactions-202508-en.csv")
+	require.NoError(t, err)
+	assert.Equal(t, "build", tld)
+	assert.Equal(t, time.Date(2025, time.August, 1, 0, 0, 0, 0, time.UTC), month)
+
+	_, _, err = ParseFileName("build-activity-202508-en.csv")
+	assert.Error(t, err)
+}
@@ -0,0 +1,154 @@
+// Package incident opens and resolves incidents in PagerDuty (Events API v2) and Opsgenie (Alert API),
+// so the operators on call are paged when a zone keeps failing.
+package incident
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Default API endpoints
+const (
+	DefaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+	DefaultOpsgenieURL  = "https://api.opsgenie.com"
+)
+
+// Incident is an incident to open
+type Incident struct {
+	Key       string         // Deduplicates the incident, and resolves it
+	Summary   string         // One line, shown in the page
+	Source    string         // Where the problem is, e.g. the workflow of the zone
+	Component string         // The zone
+	Details   map[string]any // Context shown with the incident
+}
+
+// Pager opens and resolves incidents
+type Pager interface {
+	Open(ctx context.Context, i Incident) error
+	Resolve(ctx context.Context, key string) error
+	String() string
+}
+
+// PagerDuty opens incidents with events of the Events API v2 of a service integration
+type PagerDuty struct {
+	RoutingKey string // Integration key of the service
+	URL        string // DefaultPagerDutyURL if empty
+	HTTPClient *http.Client
+}
+
+func (p PagerDuty) String() string { return "PagerDuty" }
+
+// Open triggers an alert, a second trigger with the same key is grouped into the same incident
+func (p PagerDuty) Open(ctx context.Context, i Incident) error {
+	return p.send(ctx, map[string]any{
+		"routing_key":  p.RoutingKey,
+		"event_action": "trigger",
+		"dedup_key":    i.Key,
+		"payload": map[string]any{
+			"summary":        truncate(i.Summary, 1024),
+			"source":         i.Source,
+			"severity":       "error",
+			"component":      i.Component,
+			"custom_details": i.Details,
+		},
+	})
+}
+
+// Resolve resolves the incident of the key
+func (p PagerDuty) Resolve(ctx context.Context, key string) error {
+	return p.send(ctx, map[string]any{
+		"routing_key":  p.RoutingKey,
+		"event_action": "resolve",
+		"dedup_key":    key,
+	})
+}
+
+func (p PagerDuty) send(ctx context.Context, event map[string]any) error {
+	target := p.URL
+	if target == "" {
+		target = DefaultPagerDutyURL
+	}
+	return post(ctx, p.HTTPClient, target, nil, event)
+}
+
+// Opsgenie opens incidents as alerts of the Alert API, identified by their alias
+type Opsgenie struct {
+	APIKey     string // Key of an API integration
+	URL        string // DefaultOpsgenieURL if empty, https://api.eu.opsgenie.com for the EU instance
+	HTTPClient *http.Client
+}
+
+func (o Opsgenie) String() string { return "Opsgenie" }
+
+// Open creates an alert, Opsgenie counts a second alert with the same alias as a repeat of the open one
+func (o Opsgenie) Open(ctx context.Context, i Incident) error {
+	details := make(map[string]string, len(i.Details))
+	for k, v := range i.Details {
+		details[k] = fmt.Sprint(v) // Opsgenie only takes string details
+	}
+	return o.send(ctx, "/v2/alerts", map[string]any{
+		"message":     truncate(i.Summary, 130),
+		"alias":       truncate(i.Key, 512),
+		"description": i.Summary,
+		"source":      i.Source,
+		"entity":      i.Component,
+		"details":     details,
+		"priority":    "P2",
+	})
+}
+
+// Resolve closes the alert of the key
+func (o Opsgenie) Resolve(ctx context.Context, key string) error {
+	return o.send(ctx, "/v2/alerts/"+url.PathEscape(truncate(key, 512))+"/close?identifierType=alias", map[string]any{})
+}
+
+func (o Opsgenie) send(ctx context.Context, path string, body map[string]any) error {
+	base := strings.TrimSuffix(o.URL, "/")
+	if base == "" {
+		base = DefaultOpsgenieURL
+	}
+	return post(ctx, o.HTTPClient, base+path, http.Header{"Authorization": {"GenieKey " + o.APIKey}}, body)
+}
+
+// post sends a JSON document, both services accept it with 202 Accepted
+func post(ctx context.Context, client *http.Client, target string, header http.Header, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: %s: %s", req.URL.Redacted(), resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// truncate cuts s to at most n bytes, the limits of the fields of the services
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,86 @@
+package incident
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testIncident() Incident {
+	return Incident{
+		Key:       "run_zone_build_escalation_1",
+		Summary:   ".build: 5 consecutive mint failures, zone paused",
+		Source:    "run_zone_build",
+		Component: "build",
+		Details:   map[string]any{"consecutive_failures": 5, "last_error": "INSUFFICIENT_PAYER_BALANCE"},
+	}
+}
+
+func TestPagerDuty(t *testing.T) {
+	var events []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		events = append(events, event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	pd := PagerDuty{RoutingKey: "routing-key", URL: server.URL}
+	require.NoError(t, pd.Open(context.Background(), testIncident()))
+	require.NoError(t, pd.Resolve(context.Background(), "run_zone_build_escalation_1"))
+
+	require.Len(t, events, 2)
+	assert.Equal(t, "trigger", events[0]["event_action"])
+	assert.Equal(t, "routing-key", events[0]["routing_key"])
+	assert.Equal(t, "run_zone_build_escalation_1", events[0]["dedup_key"])
+	payload := events[0]["payload"].(map[string]any)
+	assert.Equal(t, "error", payload["severity"])
+	assert.Equal(t, "build", payload["component"])
+	assert.Equal(t, float64(5), payload["custom_details"].(map[string]any)["consecutive_failures"])
+	assert.Equal(t, "resolve", events[1]["event_action"])
+	assert.Equal(t, "run_zone_build_escalation_1", events[1]["dedup_key"])
+}
+
+func TestOpsgenie(t *testing.T) {
+	var paths []string
+	var alert map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GenieKey api-key", r.Header.Get("Authorization"))
+		paths = append(paths, r.URL.RequestURI())
+		if r.URL.Path == "/v2/alerts" {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&alert))
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	og := Opsgenie{APIKey: "api-key", URL: server.URL + "/"}
+	require.NoError(t, og.Open(context.Background(), testIncident()))
+	require.NoError(t, og.Resolve(context.Background(), "run_zone_build_escalation_1"))
+
+	assert.Equal(t, []string{"/v2/alerts", "/v2/alerts/run_zone_build_escalation_1/close?identifierType=alias"}, paths)
+	assert.Equal(t, "run_zone_build_escalation_1", alert["alias"])
+	assert.Equal(t, "5", alert["details"].(map[string]any)["consecutive_failures"])
+}
+
+func TestPost_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":"invalid event","errors":["Invalid routing key"]}`))
+	}))
+	defer server.Close()
+
+	err := PagerDuty{RoutingKey: "wrong", URL: server.URL}.Open(context.Background(), testIncident())
+	assert.ErrorContains(t, err, "Invalid routing key")
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "abc", truncate("abc", 5))
+	assert.Equal(t, "ab", truncate("abc", 2))
+}
@@ -0,0 +1,188 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	netmail "net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMessage() Message {
+	return Message{
+		From:    "Shadow Domain Ledger <ledger@registry.example>",
+		To:      []string{"compliance@registry.example", "Ops <ops@registry.example>"},
+		Subject: "Ingest run completed – events.log",
+		Body:    "Ingest of events.log completed\n.build: 3 minted",
+		Attachments: []Attachment{
+			{Name: "report.json", ContentType: "application/json", Data: []byte(`{"outcome":"completed"}`)},
+		},
+	}
+}
+
+func TestMessage_Bytes(t *testing.T) {
+	data, err := testMessage().Bytes(time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	msg, err := netmail.ReadMessage(strings.NewReader(string(data)))
+	require.NoError(t, err)
+	assert.Equal(t, "Fri, 01 Aug 2025 12:00:00 +0000", msg.Header.Get("Date"))
+	assert.Contains(t, msg.Header.Get("Message-ID"), "@registry.example>")
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Ingest run completed – events.log", subject)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+
+	text, err := parts.NextPart()
+	require.NoError(t, err)
+	body, err := io.ReadAll(base64Reader(text))
+	require.NoError(t, err)
+	assert.Equal(t, "Ingest of events.log completed\n.build: 3 minted", string(body))
+
+	attachment, err := parts.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "report.json", attachment.FileName())
+	content, err := io.ReadAll(base64Reader(attachment))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"outcome":"completed"}`, string(content))
+
+	_, err = parts.NextPart()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestMessage_Invalid(t *testing.T) {
+	m := testMessage()
+	m.To = nil
+	_, err := m.Bytes(time.Now())
+	assert.ErrorContains(t, err, "no recipient")
+
+	m = testMessage()
+	m.From = "not an address"
+	_, err = m.Bytes(time.Now())
+	assert.ErrorContains(t, err, "invalid sender")
+}
+
+func TestSMTP_Send(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	type received struct {
+		from string
+		to   []string
+		data string
+	}
+	done := make(chan received, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var r received
+		in := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 test ESMTP")
+		for {
+			line, err := in.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimSpace(line)
+			switch {
+			case strings.HasPrefix(line, "EHLO"):
+				reply("250 test")
+			case strings.HasPrefix(line, "MAIL FROM:"):
+				r.from = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")
+				reply("250 OK")
+			case strings.HasPrefix(line, "RCPT TO:"):
+				r.to = append(r.to, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+				reply("250 OK")
+			case line == "DATA":
+				reply("354 Go ahead")
+				var data strings.Builder
+				for {
+					l, err := in.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				r.data = data.String()
+				reply("250 Queued")
+			case line == "QUIT":
+				reply("221 Bye")
+				done <- r
+				return
+			default:
+				reply("502 Unknown")
+			}
+		}
+	}()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	err = SMTP{Host: "127.0.0.1", Port: port, Timeout: 5 * time.Second}.Send(context.Background(), testMessage())
+	require.NoError(t, err)
+
+	r := <-done
+	assert.Equal(t, "ledger@registry.example", r.from)
+	assert.Equal(t, []string{"compliance@registry.example", "ops@registry.example"}, r.to)
+	assert.Contains(t, r.data, "Content-Disposition: attachment; filename=report.json")
+}
+
+func TestSES_Send(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/email/outbound-emails", r.URL.Path)
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"),
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20250801/eu-west-1/ses/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature="))
+		var request struct {
+			FromEmailAddress string
+			Destination      struct{ ToAddresses []string }
+			Content          struct{ Raw struct{ Data []byte } }
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "ledger@registry.example", request.FromEmailAddress)
+		assert.Equal(t, []string{"compliance@registry.example", "ops@registry.example"}, request.Destination.ToAddresses)
+		assert.Contains(t, string(request.Content.Raw.Data), "Subject: ")
+		w.Write([]byte(`{"MessageId":"0100"}`))
+	}))
+	defer server.Close()
+
+	ses := SES{
+		Region:          "eu-west-1",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "secret",
+		Endpoint:        server.URL,
+		now:             func() time.Time { return time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC) },
+	}
+	require.NoError(t, ses.Send(context.Background(), testMessage()))
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message":"Email address is not verified."}`))
+	}))
+	defer failing.Close()
+	ses.Endpoint = failing.URL
+	assert.ErrorContains(t, ses.Send(context.Background(), testMessage()), "not verified")
+}
+
+// base64Reader decodes a base64 encoded MIME part
+func base64Reader(r io.Reader) io.Reader {
+	return base64.NewDecoder(base64.StdEncoding, r)
+}
@@ -0,0 +1,89 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTP sends messages through an SMTP server. Port 465 is spoken over TLS, other ports upgrade the
+// connection with STARTTLS when the server offers it.
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string // Messages are sent without authentication if empty
+	Password string
+	Timeout  time.Duration // Of the whole exchange with the server, 30 seconds if zero
+}
+
+// Send delivers a message to the SMTP server
+func (s SMTP) Send(ctx context.Context, m Message) error {
+	data, err := m.Bytes(time.Now())
+	if err != nil {
+		return err
+	}
+	from, to, err := m.addresses()
+	if err != nil {
+		return err
+	}
+
+	timeout := s.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	tlsConfig := &tls.Config{ServerName: s.Host}
+	if s.Port == 465 {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	c, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		return fmt.Errorf("SMTP server %s: %w", addr, err)
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok && s.Port != 465 {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("SMTP server %s: STARTTLS: %w", addr, err)
+		}
+	}
+	if s.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return fmt.Errorf("SMTP server %s: %w", addr, err)
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return fmt.Errorf("SMTP server %s refused sender %s: %w", addr, from, err)
+	}
+	for _, recipient := range to {
+		if err := c.Rcpt(recipient); err != nil {
+			return fmt.Errorf("SMTP server %s refused recipient %s: %w", addr, recipient, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP server %s: %w", addr, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("SMTP server %s: %w", addr, err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP server %s refused the message: %w", addr, err)
+	}
+	return c.Quit()
+}
@@ -0,0 +1,132 @@
+// Package merkle computes Merkle trees over event hashes following RFC 6962 (Certificate Transparency):
+// leaves and interior nodes are hashed with SHA-256 under distinct prefixes, so a leaf can never be
+// passed off as an interior node, and trees of any size are supported without padding.
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Algorithm identifies the tree construction in anchors
+const Algorithm = "rfc6962-sha256"
+
+// Hash is a SHA-256 digest
+type Hash [sha256.Size]byte
+
+// String returns the hex encoding of the hash
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// ParseHash decodes a hex encoded SHA-256 digest
+func ParseHash(s string) (Hash, error) {
+	var h Hash
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return h, fmt.Errorf("invalid hash %q: %w", s, err)
+	}
+	if len(b) != len(h) {
+		return h, fmt.Errorf("invalid hash %q: %d bytes, expected %d", s, len(b), len(h))
+	}
+	copy(h[:], b)
+	return h, nil
+}
+
+// LeafHash returns the hash of a leaf holding data
+func LeafHash(data []byte) Hash {
+	return sha256.Sum256(append([]byte{0x00}, data...))
+}
+
+// nodeHash returns the hash of an interior node
+func nodeHash(left, right Hash) Hash {
+	buf := make([]byte, 0, 1+2*len(left))
+	buf = append(buf, 0x01)
+	buf = append(buf, left[:]...)
+	buf = append(buf, right[:]...)
+	return sha256.Sum256(buf)
+}
+
+// Root returns the root of the tree over the given leaves, in order.
+// The root of an empty tree is the hash of the empty string.
+func Root(leaves [][]byte) Hash {
+	if len(leaves) == 0 {
+		return sha256.Sum256(nil)
+	}
+	hashes := make([]Hash, len(leaves))
+	for i, leaf := range leaves {
+		hashes[i] = LeafHash(leaf)
+	}
+	return subtreeRoot(hashes)
+}
+
+// subtreeRoot returns the root over leaf hashes, splitting at the largest power of two below their count
+func subtreeRoot(hashes []Hash) Hash {
+	if len(hashes) == 1 {
+		return hashes[0]
+	}
+	k := splitPoint(len(hashes))
+	return nodeHash(subtreeRoot(hashes[:k]), subtreeRoot(hashes[k:]))
+}
+
+// Proof returns the audit path proving that the leaf at index is included in the tree over leaves:
+// the sibling hashes from the leaf up to the root
+func Proof(leaves [][]byte, index int) ([]Hash, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, fmt.Errorf("leaf %d is not in a tree of %d leaves", index, len(leaves))
+	}
+	hashes := make([]Hash, len(leaves))
+	for i, leaf := range leaves {
+		hashes[i] = LeafHash(leaf)
+	}
+	return subtreePath(hashes, index), nil
+}
+
+// subtreePath returns the audit path of a leaf within a subtree
+func subtreePath(hashes []Hash, index int) []Hash {
+	if len(hashes) == 1 {
+		return nil
+	}
+	k := splitPoint(len(hashes))
+	if index < k {
+		return append(subtreePath(hashes[:k], index), subtreeRoot(hashes[k:]))
+	}
+	return append(subtreePath(hashes[k:], index-k), subtreeRoot(hashes[:k]))
+}
+
+// VerifyInclusion checks an audit path proving that data is the leaf at index of a tree of size leaves with
+// the given root, following RFC 9162 section 2.1.3.2
+func VerifyInclusion(data []byte, index, size int, path []Hash, root Hash) bool {
+	if index < 0 || index >= size {
+		return false
+	}
+	fn, sn := index, size-1
+	r := LeafHash(data)
+	for _, p := range path {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			r = nodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = nodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && r == root
+}
+
+// splitPoint returns the largest power of two smaller than n, for n > 1
+func splitPoint(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
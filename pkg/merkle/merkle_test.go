@@ -0,0 +1,91 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func leaves(data ...string) [][]byte {
+	out := make([][]byte, len(data))
+	for i, d := range data {
+		out[i] = []byte(d)
+	}
+	return out
+}
+
+func TestRoot_Empty(t *testing.T) {
+	assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", Root(nil).String())
+}
+
+func TestRoot_Single(t *testing.T) {
+	assert.Equal(t, LeafHash([]byte("a")), Root(leaves("a")))
+}
+
+func TestRoot_Structure(t *testing.T) {
+	a, b, c := LeafHash([]byte("a")), LeafHash([]byte("b")), LeafHash([]byte("c"))
+
+	assert.Equal(t, nodeHash(a, b), Root(leaves("a", "b")))
+	// Three leaves split into a full subtree of two and the remaining leaf
+	assert.Equal(t, nodeHash(nodeHash(a, b), c), Root(leaves("a", "b", "c")))
+}
+
+func TestRoot_OrderMatters(t *testing.T) {
+	assert.NotEqual(t, Root(leaves("a", "b")), Root(leaves("b", "a")))
+}
+
+func TestLeafHash_DomainSeparation(t *testing.T) {
+	// A leaf is not hashed like the raw data, so interior nodes cannot be forged as leaves
+	assert.NotEqual(t, Hash(sha256.Sum256([]byte("a"))), LeafHash([]byte("a")))
+}
+
+func TestProof_VerifiesEveryLeaf(t *testing.T) {
+	for size := 1; size <= 17; size++ {
+		data := make([][]byte, size)
+		for i := range data {
+			data[i] = []byte{byte(i)}
+		}
+		root := Root(data)
+		for i := range data {
+			path, err := Proof(data, i)
+			require.NoError(t, err)
+			assert.True(t, VerifyInclusion(data[i], i, size, path, root), "leaf %d of %d", i, size)
+
+			// The proof is bound to the leaf, its position and the root
+			assert.False(t, VerifyInclusion([]byte("other"), i, size, path, root))
+			if size > 1 {
+				assert.False(t, VerifyInclusion(data[i], (i+1)%size, size, path, root))
+			}
+			assert.False(t, VerifyInclusion(data[i], i, size, path, LeafHash([]byte("root"))))
+		}
+	}
+}
+
+func TestProof_OutOfRange(t *testing.T) {
+	_, err := Proof(leaves("a", "b"), 2)
+	assert.Error(t, err)
+	_, err = Proof(nil, 0)
+	assert.Error(t, err)
+	assert.False(t, VerifyInclusion([]byte("a"), 0, 0, nil, Root(nil)))
+}
+
+func TestParseHash(t *testing.T) {
+	h := LeafHash([]byte("a"))
+	parsed, err := ParseHash(h.String())
+	require.NoError(t, err)
+	assert.Equal(t, h, parsed)
+
+	_, err = ParseHash("abcd")
+	assert.Error(t, err)
+	_, err = ParseHash("zz")
+	assert.Error(t, err)
+}
+
+func TestSplitPoint(t *testing.T) {
+	assert.Equal(t, 1, splitPoint(2))
+	assert.Equal(t, 2, splitPoint(3))
+	assert.Equal(t, 4, splitPoint(5))
+	assert.Equal(t, 4, splitPoint(8))
+}
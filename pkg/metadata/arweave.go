@@ -0,0 +1,360 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultArweaveGateway is the public gateway documents are uploaded through
+const DefaultArweaveGateway = "https://arweave.net"
+
+// MaxArweaveDocumentSize is the largest document uploaded in a single transaction, without chunked upload
+const MaxArweaveDocumentSize = 256 * 1024
+
+// Chunk sizes of the Arweave data root
+const (
+	arweaveMaxChunkSize = 256 * 1024
+	arweaveMinChunkSize = 32 * 1024
+)
+
+var b64 = base64.RawURLEncoding
+
+// ArweaveWallet is an Arweave wallet: an RSA key, as stored in the JWK files exported by Arweave wallets
+type ArweaveWallet struct {
+	key *rsa.PrivateKey
+}
+
+// LoadArweaveWallet reads an Arweave wallet JWK file
+func LoadArweaveWallet(path string) (*ArweaveWallet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Arweave wallet: %w", err)
+	}
+	return ParseArweaveWallet(data)
+}
+
+// ParseArweaveWallet parses the JWK of an Arweave wallet
+func ParseArweaveWallet(data []byte) (*ArweaveWallet, error) {
+	var jwk struct {
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		D   string `json:"d"`
+		P   string `json:"p"`
+		Q   string `json:"q"`
+	}
+	if err := json.Unmarshal(data, &jwk); err != nil {
+		return nil, fmt.Errorf("failed to parse Arweave wallet: %w", err)
+	}
+	if jwk.Kty != "RSA" {
+		return nil, fmt.Errorf("unsupported Arweave wallet key type %q", jwk.Kty)
+	}
+	var ints [5]*big.Int
+	for i, s := range []string{jwk.N, jwk.E, jwk.D, jwk.P, jwk.Q} {
+		raw, err := b64.DecodeString(s)
+		if err != nil || len(raw) == 0 {
+			return nil, errors.New("invalid Arweave wallet: the private key is incomplete")
+		}
+		ints[i] = new(big.Int).SetBytes(raw)
+	}
+	if !ints[1].IsInt64() {
+		return nil, errors.New("invalid Arweave wallet: public exponent too large")
+	}
+	key := &rsa.PrivateKey{
+		PublicKey: rsa.PublicKey{N: ints[0], E: int(ints[1].Int64())},
+		D:         ints[2],
+		Primes:    []*big.Int{ints[3], ints[4]},
+	}
+	if err := key.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid Arweave wallet: %w", err)
+	}
+	key.Precompute()
+	return &ArweaveWallet{key: key}, nil
+}
+
+// NewArweaveWallet wraps an RSA key as a wallet
+func NewArweaveWallet(key *rsa.PrivateKey) *ArweaveWallet {
+	return &ArweaveWallet{key: key}
+}
+
+// owner returns the public modulus, which identifies the wallet in transactions
+func (w *ArweaveWallet) owner() []byte {
+	return w.key.N.Bytes()
+}
+
+// Address returns the address of the wallet: the base64url SHA-256 of its public modulus
+func (w *ArweaveWallet) Address() string {
+	sum := sha256.Sum256(w.owner())
+	return b64.EncodeToString(sum[:])
+}
+
+// ArweaveStore uploads documents to Arweave, paying for permanent storage from a wallet.
+// Documents are referenced as ar://<transaction ID>.
+type ArweaveStore struct {
+	Gateway    string // Base URL of the gateway, e.g. DefaultArweaveGateway
+	Wallet     *ArweaveWallet
+	HTTPClient *http.Client
+}
+
+// NewArweaveStore returns a store uploading through gateway, DefaultArweaveGateway if empty
+func NewArweaveStore(gateway string, wallet *ArweaveWallet) *ArweaveStore {
+	if gateway == "" {
+		gateway = DefaultArweaveGateway
+	}
+	return &ArweaveStore{Gateway: strings.TrimRight(gateway, "/"), Wallet: wallet, HTTPClient: http.DefaultClient}
+}
+
+// ArweaveTransaction is a format 2 Arweave transaction carrying its data, as posted to /tx
+type ArweaveTransaction struct {
+	Format    int          `json:"format"`
+	ID        string       `json:"id"`
+	LastTx    string       `json:"last_tx"`
+	Owner     string       `json:"owner"`
+	Tags      []arweaveTag `json:"tags"`
+	Target    string       `json:"target"`
+	Quantity  string       `json:"quantity"`
+	Data      string       `json:"data"`
+	DataSize  string       `json:"data_size"`
+	DataRoot  string       `json:"data_root"`
+	Reward    string       `json:"reward"`
+	Signature string       `json:"signature"`
+}
+
+// arweaveTag is a tag with its name and value base64url encoded
+type arweaveTag struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Put uploads a document and returns its ar:// URI once the gateway accepted the transaction.
+// The document is retrievable from the gateway right away and permanent once the transaction is mined.
+func (s *ArweaveStore) Put(ctx context.Context, data []byte, tags []Tag) (string, error) {
+	if len(data) == 0 {
+		return "", errors.New("empty metadata document")
+	}
+	if len(data) > MaxArweaveDocumentSize {
+		return "", fmt.Errorf("%w: %d bytes, Arweave uploads are limited to %d", ErrTooLarge, len(data), MaxArweaveDocumentSize)
+	}
+	anchor, err := s.getText(ctx, "/tx_anchor")
+	if err != nil {
+		return "", fmt.Errorf("failed to get transaction anchor: %w", err)
+	}
+	reward, err := s.getText(ctx, "/price/"+strconv.Itoa(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("failed to get storage price: %w", err)
+	}
+
+	tx, err := s.Wallet.NewTransaction(data, tags, anchor, reward)
+	if err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(tx)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal transaction: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.Gateway+"/tx", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to post transaction: %w", err)
+	}
+	defer resp.Body.Close()
+	// 208 means the gateway already has the transaction
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAlreadyReported {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("gateway refused transaction %s: status %d: %s", tx.ID, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return "ar://" + tx.ID, nil
+}
+
+// Balance returns the balance of the wallet in winston
+func (s *ArweaveStore) Balance(ctx context.Context) (*big.Int, error) {
+	text, err := s.getText(ctx, "/wallet/"+s.Wallet.Address()+"/balance")
+	if err != nil {
+		return nil, err
+	}
+	balance, ok := new(big.Int).SetString(text, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid balance %q", text)
+	}
+	return balance, nil
+}
+
+// getText returns the trimmed plain text response of a gateway endpoint
+func (s *ArweaveStore) getText(ctx context.Context, path string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.Gateway+path, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s returned status %d", path, resp.StatusCode)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// NewTransaction builds and signs a format 2 transaction storing data, with anchor as last_tx and reward in winston
+func (w *ArweaveWallet) NewTransaction(data []byte, tags []Tag, anchor, reward string) (ArweaveTransaction, error) {
+	lastTx, err := b64.DecodeString(anchor)
+	if err != nil {
+		return ArweaveTransaction{}, fmt.Errorf("invalid transaction anchor: %w", err)
+	}
+	if _, ok := new(big.Int).SetString(reward, 10); !ok {
+		return ArweaveTransaction{}, fmt.Errorf("invalid reward %q", reward)
+	}
+	root := dataRoot(data)
+	size := strconv.Itoa(len(data))
+
+	tx := ArweaveTransaction{
+		Format:   2,
+		LastTx:   anchor,
+		Owner:    b64.EncodeToString(w.owner()),
+		Tags:     []arweaveTag{},
+		Quantity: "0",
+		Data:     b64.EncodeToString(data),
+		DataSize: size,
+		DataRoot: b64.EncodeToString(root),
+		Reward:   reward,
+	}
+	tagList := make([]interface{}, 0, len(tags))
+	for _, t := range tags {
+		tx.Tags = append(tx.Tags, arweaveTag{Name: b64.EncodeToString([]byte(t.Name)), Value: b64.EncodeToString([]byte(t.Value))})
+		tagList = append(tagList, []interface{}{[]byte(t.Name), []byte(t.Value)})
+	}
+
+	message := deepHash([]interface{}{
+		[]byte("2"),
+		w.owner(),
+		[]byte{}, // target
+		[]byte(tx.Quantity),
+		[]byte(reward),
+		lastTx,
+		tagList,
+		[]byte(size),
+		root,
+	})
+	digest := sha256.Sum256(message)
+	signature, err := rsa.SignPSS(rand.Reader, w.key, crypto.SHA256, digest[:], &rsa.PSSOptions{SaltLength: 32})
+	if err != nil {
+		return ArweaveTransaction{}, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	id := sha256.Sum256(signature)
+	tx.Signature = b64.EncodeToString(signature)
+	tx.ID = b64.EncodeToString(id[:])
+	return tx, nil
+}
+
+// deepHash is the SHA-384 based hash Arweave signs, over nested lists of byte strings
+func deepHash(value interface{}) []byte {
+	switch v := value.(type) {
+	case []byte:
+		tag := sha512.Sum384([]byte("blob" + strconv.Itoa(len(v))))
+		data := sha512.Sum384(v)
+		sum := sha512.Sum384(append(tag[:], data[:]...))
+		return sum[:]
+	case []interface{}:
+		acc := sha512.Sum384([]byte("list" + strconv.Itoa(len(v))))
+		for _, item := range v {
+			acc = sha512.Sum384(append(acc[:], deepHash(item)...))
+		}
+		return acc[:]
+	default:
+		panic(fmt.Sprintf("deepHash: unsupported type %T", value))
+	}
+}
+
+// arweaveNode is a node of the Merkle tree over the chunks of transaction data
+type arweaveNode struct {
+	id           []byte
+	maxByteRange int
+}
+
+// dataRoot returns the root of the Merkle tree over the chunks of data, identifying the data in a transaction
+func dataRoot(data []byte) []byte {
+	var nodes []arweaveNode
+	cursor := 0
+	rest := data
+	for len(rest) >= arweaveMaxChunkSize {
+		size := arweaveMaxChunkSize
+		// Split the remainder evenly rather than leaving a chunk below the minimum size
+		if next := len(rest) - arweaveMaxChunkSize; next > 0 && next < arweaveMinChunkSize {
+			size = (len(rest) + 1) / 2
+		}
+		cursor += size
+		nodes = append(nodes, arweaveLeaf(rest[:size], cursor))
+		rest = rest[size:]
+	}
+	nodes = append(nodes, arweaveLeaf(rest, cursor+len(rest)))
+
+	for len(nodes) > 1 {
+		var layer []arweaveNode
+		for i := 0; i < len(nodes); i += 2 {
+			if i+1 == len(nodes) {
+				layer = append(layer, nodes[i])
+				continue
+			}
+			left, right := nodes[i], nodes[i+1]
+			layer = append(layer, arweaveNode{
+				id:           sha256Concat(sha256Sum(left.id), sha256Sum(right.id), sha256Sum(noteBytes(left.maxByteRange))),
+				maxByteRange: right.maxByteRange,
+			})
+		}
+		nodes = layer
+	}
+	return nodes[0].id
+}
+
+// arweaveLeaf returns the leaf of a chunk ending at maxByteRange
+func arweaveLeaf(chunk []byte, maxByteRange int) arweaveNode {
+	dataHash := sha256Sum(chunk)
+	return arweaveNode{
+		id:           sha256Concat(sha256Sum(dataHash), sha256Sum(noteBytes(maxByteRange))),
+		maxByteRange: maxByteRange,
+	}
+}
+
+// noteBytes encodes an offset as a 32 byte big-endian integer
+func noteBytes(n int) []byte {
+	note := make([]byte, 32)
+	big.NewInt(int64(n)).FillBytes(note)
+	return note
+}
+
+func sha256Sum(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+func sha256Concat(parts ...[]byte) []byte {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write(p)
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,148 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/naming"
+)
+
+// CollectionDocument is the metadata document of a zone collection, following HIP-766.
+// The URI of the document is stored in the token metadata of the collection.
+type CollectionDocument struct {
+	Description   string             `json:"description"`
+	Creator       string             `json:"creator,omitempty"`
+	Website       string             `json:"website,omitempty"`
+	LightLogo     string             `json:"lightLogo,omitempty"`
+	LightLogoType string             `json:"lightLogoType,omitempty"`
+	DarkLogo      string             `json:"darkLogo,omitempty"`
+	DarkLogoType  string             `json:"darkLogoType,omitempty"`
+	Properties    CollectionProperty `json:"properties"`
+}
+
+// CollectionProperty are the ledger details of a zone collection
+type CollectionProperty struct {
+	Zone string `json:"zone"`
+}
+
+// Marshal returns the JSON encoding of the document
+func (d CollectionDocument) Marshal() ([]byte, error) {
+	return json.Marshal(d)
+}
+
+// Tags returns the tags identifying the document in a store
+func (d CollectionDocument) Tags() []Tag {
+	return []Tag{
+		{Name: "Content-Type", Value: "application/json"},
+		{Name: "App-Name", Value: "shadow-domain-ledger"},
+		{Name: "Zone", Value: d.Properties.Zone},
+		{Name: "Type", Value: "collection"},
+	}
+}
+
+// Branding is the presentation of a zone collection in wallets and marketplaces
+type Branding struct {
+	Description string `yaml:"description"`
+	Creator     string `yaml:"creator"`
+	Website     string `yaml:"website"`   // External URL of the zone
+	Logo        string `yaml:"logo"`      // URI of the logo, e.g. ipfs://<cid>
+	LogoType    string `yaml:"logo_type"` // MIME type of the logo, e.g. image/png
+	DarkLogo    string `yaml:"dark_logo"` // URI of the logo for dark backgrounds
+	// Symbol replaces the token symbol of COLLECTION_SYMBOL_TEMPLATE. Symbols are set at creation only.
+	Symbol string `yaml:"symbol"`
+}
+
+// BrandingFile holds the branding of the zone collections: the defaults apply to zones not listed,
+// and fill in the fields a listed zone leaves empty
+type BrandingFile struct {
+	Defaults Branding            `yaml:"defaults"`
+	Zones    map[string]Branding `yaml:"zones"`
+}
+
+// LoadBrandingFile reads and validates a YAML branding file
+func LoadBrandingFile(path string) (*BrandingFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file BrandingFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if file.Defaults.Symbol != "" {
+		return nil, fmt.Errorf("%s: defaults: symbols must be set per zone", path)
+	}
+	if err := file.Defaults.validate(); err != nil {
+		return nil, fmt.Errorf("%s: defaults: %w", path, err)
+	}
+	for zone, branding := range file.Zones {
+		if err := branding.validate(); err != nil {
+			return nil, fmt.Errorf("%s: zone %s: %w", path, zone, err)
+		}
+	}
+	return &file, nil
+}
+
+// For returns the branding of a zone, with the defaults filled in
+func (f *BrandingFile) For(zone string) Branding {
+	branding := f.Zones[strings.TrimPrefix(strings.ToLower(zone), ".")]
+	defaults := f.Defaults
+	for _, field := range []struct{ value, fallback *string }{
+		{&branding.Description, &defaults.Description},
+		{&branding.Creator, &defaults.Creator},
+		{&branding.Website, &defaults.Website},
+		{&branding.Logo, &defaults.Logo},
+		{&branding.LogoType, &defaults.LogoType},
+		{&branding.DarkLogo, &defaults.DarkLogo},
+	} {
+		if *field.value == "" {
+			*field.value = *field.fallback
+		}
+	}
+	return branding
+}
+
+// IsZero reports whether the branding sets nothing
+func (b Branding) IsZero() bool {
+	return b == Branding{}
+}
+
+// Document returns the collection document of a zone with this branding
+func (b Branding) Document(zone string) CollectionDocument {
+	doc := CollectionDocument{
+		Description:   b.Description,
+		Creator:       b.Creator,
+		Website:       b.Website,
+		LightLogo:     b.Logo,
+		LightLogoType: b.LogoType,
+		DarkLogo:      b.DarkLogo,
+		Properties:    CollectionProperty{Zone: zone},
+	}
+	if b.DarkLogo != "" {
+		doc.DarkLogoType = b.LogoType
+	}
+	return doc
+}
+
+// validate checks the URIs and the symbol of a branding
+func (b Branding) validate() error {
+	for name, value := range map[string]string{"website": b.Website, "logo": b.Logo, "dark_logo": b.DarkLogo} {
+		if value == "" {
+			continue
+		}
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" {
+			return fmt.Errorf("%s: %q is not an absolute URI", name, value)
+		}
+	}
+	if b.Symbol != "" {
+		if err := naming.ValidateSymbol(b.Symbol); err != nil {
+			return fmt.Errorf("symbol: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package metadata
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeBrandingFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "branding.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadBrandingFile(t *testing.T) {
+	path := writeBrandingFile(t, `
+defaults:
+  description: Registrations of the shadow domain ledger
+  website: https://ledger.example
+  logo: ipfs://bafkreidefault
+  logo_type: image/png
+zones:
+  build:
+    description: Registrations of .build
+    logo: ipfs://bafkreibuild
+    symbol: BUILD
+`)
+	file, err := LoadBrandingFile(path)
+	require.NoError(t, err)
+
+	build := file.For(".BUILD")
+	assert.Equal(t, "Registrations of .build", build.Description)
+	assert.Equal(t, "ipfs://bafkreibuild", build.Logo)
+	assert.Equal(t, "image/png", build.LogoType)
+	assert.Equal(t, "https://ledger.example", build.Website)
+	assert.Equal(t, "BUILD", build.Symbol)
+
+	other := file.For("com")
+	assert.Equal(t, "ipfs://bafkreidefault", other.Logo)
+	assert.Empty(t, other.Symbol)
+
+	data, err := build.Document("build").Marshal()
+	require.NoError(t, err)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, "ipfs://bafkreibuild", doc["lightLogo"])
+	assert.Equal(t, "https://ledger.example", doc["website"])
+	assert.Equal(t, map[string]any{"zone": "build"}, doc["properties"])
+	assert.NotContains(t, doc, "darkLogo")
+}
+
+func TestLoadBrandingFile_Invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"relative logo":    "zones:\n  build:\n    logo: logo.png\n",
+		"default symbol":   "defaults:\n  symbol: SDL\n",
+		"padded symbol":    "zones:\n  build:\n    symbol: ' BUILD'\n",
+		"symbol charset":   "zones:\n  build:\n    symbol: BUILD/ZONE\n",
+		"malformed":        "zones: [",
+		"relative website": "defaults:\n  website: ledger.example\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadBrandingFile(writeBrandingFile(t, content))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// MaxHCSDocumentSize is the largest document stored on HCS, sent in at most 100 messages
+const MaxHCSDocumentSize = 100 * HCSChunkSize
+
+// HCSChunkSize is the part of a document carried by one message. Base64 encoded and framed, a chunk stays
+// below the 1024 bytes of a single HCS message, so the SDK does not split messages any further.
+const HCSChunkSize = 640
+
+// DefaultHCSMaxScan is the number of topic messages a resolver reads looking for the chunks of a document
+const DefaultHCSMaxScan = 1000
+
+// hcsChunkType identifies the messages carrying document chunks among the other messages of a topic
+const hcsChunkType = "sdl-doc"
+
+// ErrDocumentNotFound is returned when the chunks of a document cannot be found on its topic
+var ErrDocumentNotFound = errors.New("metadata document not found")
+
+// HCSChunk is the message carrying one part of a document stored on HCS. Every chunk holds the hash of
+// the whole document, so the document can be reassembled even when other messages interleave.
+type HCSChunk struct {
+	Type  string `json:"t"`
+	Hash  string `json:"h"` // sha256 of the document, hex encoded
+	Index int    `json:"i"` // Position of the chunk, from 0
+	Total int    `json:"n"` // Number of chunks of the document
+	Data  []byte `json:"d"` // Part of the document, base64 encoded in JSON
+}
+
+// HCSStore stores documents on an HCS topic, as a series of chunk messages. Documents are content-addressed:
+// they are referenced as hcs://<topic>/<sequence number of the first chunk>/<sha256 of the document>.
+type HCSStore struct {
+	TopicID string
+	// Submit sends a message to the topic and returns its sequence number
+	Submit func(ctx context.Context, message []byte) (uint64, error)
+}
+
+// Put sends the chunks of a document to the topic, in order, and returns its hcs:// URI
+func (s *HCSStore) Put(ctx context.Context, data []byte, tags []Tag) (string, error) {
+	if len(data) > MaxHCSDocumentSize {
+		return "", fmt.Errorf("%w: %d bytes, HCS documents are limited to %d", ErrTooLarge, len(data), MaxHCSDocumentSize)
+	}
+	chunks := SplitHCSDocument(data)
+	var first uint64
+	for _, chunk := range chunks {
+		message, err := json.Marshal(chunk)
+		if err != nil {
+			return "", err
+		}
+		sequence, err := s.Submit(ctx, message)
+		if err != nil {
+			return "", fmt.Errorf("failed to submit chunk %d of %d: %w", chunk.Index+1, chunk.Total, err)
+		}
+		if chunk.Index == 0 {
+			first = sequence
+		}
+	}
+	return HCSURI(s.TopicID, first, chunks[0].Hash), nil
+}
+
+// SplitHCSDocument splits a document into its chunk messages
+func SplitHCSDocument(data []byte) []HCSChunk {
+	digest := sha256.Sum256(data)
+	hash := hex.EncodeToString(digest[:])
+	total := (len(data) + HCSChunkSize - 1) / HCSChunkSize
+	if total == 0 {
+		total = 1
+	}
+	chunks := make([]HCSChunk, 0, total)
+	for i := 0; i < total; i++ {
+		end := min((i+1)*HCSChunkSize, len(data))
+		chunks = append(chunks, HCSChunk{Type: hcsChunkType, Hash: hash, Index: i, Total: total, Data: data[i*HCSChunkSize : end]})
+	}
+	return chunks
+}
+
+// HCSURI returns the URI of a document stored on HCS
+func HCSURI(topicID string, sequence uint64, hash string) string {
+	return fmt.Sprintf("hcs://%s/%d/%s", topicID, sequence, hash)
+}
+
+// ParseHCSURI splits the URI of a document stored on HCS into its topic, first sequence number and hash
+func ParseHCSURI(uri string) (topicID string, sequence uint64, hash string, err error) {
+	rest, ok := strings.CutPrefix(uri, "hcs://")
+	parts := strings.Split(rest, "/")
+	if !ok || len(parts) != 3 || parts[0] == "" {
+		return "", 0, "", fmt.Errorf("invalid HCS document URI %q", uri)
+	}
+	if sequence, err = strconv.ParseUint(parts[1], 10, 64); err != nil || sequence == 0 {
+		return "", 0, "", fmt.Errorf("invalid HCS document URI %q: bad sequence number", uri)
+	}
+	if raw, err := hex.DecodeString(parts[2]); err != nil || len(raw) != sha256.Size {
+		return "", 0, "", fmt.Errorf("invalid HCS document URI %q: bad document hash", uri)
+	}
+	return parts[0], sequence, parts[2], nil
+}
+
+// HCSResolver reassembles documents stored on HCS from the topic messages served by the mirror node REST API
+type HCSResolver struct {
+	MirrorURL  string      // Base URL of the mirror node REST API, including /api/v1
+	Header     http.Header // Sent with every request, e.g. the API key of the mirror node
+	HTTPClient *http.Client
+	MaxScan    int // Messages read before giving up, DefaultHCSMaxScan if zero
+}
+
+// NewHCSResolver returns a resolver reading from the mirror node at mirrorURL
+func NewHCSResolver(mirrorURL string, header http.Header) *HCSResolver {
+	return &HCSResolver{MirrorURL: strings.TrimRight(mirrorURL, "/"), Header: header, HTTPClient: http.DefaultClient, MaxScan: DefaultHCSMaxScan}
+}
+
+// Resolve returns the document referenced by an hcs:// URI. The chunks are collected from the sequence number
+// of the URI on, and the reassembled document is checked against the hash of the URI.
+func (r *HCSResolver) Resolve(ctx context.Context, uri string) ([]byte, error) {
+	topicID, sequence, hash, err := ParseHCSURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	return r.find(ctx, topicID, sequence, hash)
+}
+
+// ResolveHash returns the document with the given hash stored on a topic, scanning the topic from its first message
+func (r *HCSResolver) ResolveHash(ctx context.Context, topicID, hash string) ([]byte, error) {
+	return r.find(ctx, topicID, 1, strings.ToLower(hash))
+}
+
+// find collects the chunks of a document from the messages of a topic, starting at a sequence number
+func (r *HCSResolver) find(ctx context.Context, topicID string, from uint64, hash string) ([]byte, error) {
+	maxScan := r.MaxScan
+	if maxScan <= 0 {
+		maxScan = DefaultHCSMaxScan
+	}
+	var chunks [][]byte
+	var have []bool
+	var err error
+	found, scanned := 0, 0
+	path := fmt.Sprintf("/topics/%s/messages?limit=100&order=asc&sequencenumber=gte:%d", topicID, from)
+	for path != "" && scanned < maxScan {
+		var page struct {
+			Messages []struct {
+				Message        string `json:"message"`
+				SequenceNumber uint64 `json:"sequence_number"`
+			} `json:"messages"`
+			Links struct {
+				Next string `json:"next"`
+			} `json:"links"`
+		}
+		if err := r.get(ctx, path, &page); err != nil {
+			return nil, err
+		}
+		for _, message := range page.Messages {
+			if scanned++; scanned > maxScan {
+				break
+			}
+			raw, err := base64.StdEncoding.DecodeString(message.Message)
+			if err != nil {
+				continue
+			}
+			var chunk HCSChunk
+			if json.Unmarshal(raw, &chunk) != nil || chunk.Type != hcsChunkType || chunk.Hash != hash {
+				continue
+			}
+			if chunk.Total <= 0 || chunk.Total*HCSChunkSize > MaxHCSDocumentSize || chunk.Index < 0 || chunk.Index >= chunk.Total {
+				return nil, fmt.Errorf("invalid chunk in message %d of %s", message.SequenceNumber, topicID)
+			}
+			if chunks == nil {
+				chunks, have = make([][]byte, chunk.Total), make([]bool, chunk.Total)
+			}
+			if chunk.Total != len(chunks) {
+				return nil, fmt.Errorf("chunk in message %d of %s does not match the earlier chunks", message.SequenceNumber, topicID)
+			}
+			if !have[chunk.Index] {
+				// Chunks sent again by a retried upload are ignored
+				chunks[chunk.Index], have[chunk.Index] = chunk.Data, true
+				found++
+			}
+			if found == len(chunks) {
+				return r.assemble(chunks, hash)
+			}
+		}
+		path = ""
+		if page.Links.Next != "" {
+			if path, err = r.nextPath(page.Links.Next); err != nil {
+				return nil, fmt.Errorf("invalid pagination link: %w", err)
+			}
+		}
+	}
+	if chunks == nil {
+		return nil, fmt.Errorf("%w: no chunk of %s on %s", ErrDocumentNotFound, hash, topicID)
+	}
+	return nil, fmt.Errorf("%w: %d of %d chunks of %s on %s", ErrDocumentNotFound, found, len(chunks), hash, topicID)
+}
+
+// assemble joins the chunks of a document and checks its hash
+func (r *HCSResolver) assemble(chunks [][]byte, hash string) ([]byte, error) {
+	data := bytes.Join(chunks, nil)
+	digest := sha256.Sum256(data)
+	if hex.EncodeToString(digest[:]) != hash {
+		return nil, fmt.Errorf("document %s does not match its hash", hash)
+	}
+	return data, nil
+}
+
+// nextPath converts a pagination link of the mirror node into a path relative to MirrorURL
+func (r *HCSResolver) nextPath(next string) (string, error) {
+	nextURL, err := url.Parse(next)
+	if err != nil {
+		return "", err
+	}
+	base, err := url.Parse(r.MirrorURL)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(nextURL.RequestURI(), base.Path), nil
+}
+
+// get queries the mirror node
+func (r *HCSResolver) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.MirrorURL+path, nil)
+	if err != nil {
+		return err
+	}
+	for name, values := range r.Header {
+		req.Header[name] = values
+	}
+	client := r.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query mirror node: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("mirror node returned status %d for %s", resp.StatusCode, path)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode mirror node response: %w", err)
+	}
+	return nil
+}
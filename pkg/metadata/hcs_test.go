@@ -0,0 +1,106 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTopic records submitted messages and serves them like the mirror node, two per page
+type fakeTopic struct {
+	messages [][]byte
+	apiKeys  []string
+}
+
+func (f *fakeTopic) submit(_ context.Context, message []byte) (uint64, error) {
+	f.messages = append(f.messages, message)
+	return uint64(len(f.messages)), nil
+}
+
+func (f *fakeTopic) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.apiKeys = append(f.apiKeys, r.Header.Get("X-Api-Key"))
+	var from int
+	fmt.Sscanf(r.URL.Query().Get("sequencenumber"), "gte:%d", &from)
+	type message struct {
+		Message        string `json:"message"`
+		SequenceNumber int    `json:"sequence_number"`
+	}
+	var page struct {
+		Messages []message `json:"messages"`
+		Links    struct {
+			Next string `json:"next"`
+		} `json:"links"`
+	}
+	for seq := from; seq <= len(f.messages) && len(page.Messages) < 2; seq++ {
+		page.Messages = append(page.Messages, message{base64.StdEncoding.EncodeToString(f.messages[seq-1]), seq})
+	}
+	if next := from + len(page.Messages); next <= len(f.messages) {
+		page.Links.Next = fmt.Sprintf("/api/v1/topics/0.0.7/messages?limit=100&order=asc&sequencenumber=gte:%d", next)
+	}
+	json.NewEncoder(w).Encode(page)
+}
+
+func TestHCSStore(t *testing.T) {
+	topic := &fakeTopic{}
+	topic.submit(context.Background(), []byte("unrelated"))
+	server := httptest.NewServer(http.StripPrefix("/api/v1", topic))
+	defer server.Close()
+
+	store := &HCSStore{TopicID: "0.0.7", Submit: topic.submit}
+	doc := bytes.Repeat([]byte("0123456789"), 2*HCSChunkSize/10+5) // 3 chunks
+	uri, err := store.Put(context.Background(), doc, nil)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(uri, "hcs://0.0.7/2/"), uri)
+	assert.LessOrEqual(t, len(uri), 100)
+	require.Len(t, topic.messages, 4)
+	for _, message := range topic.messages {
+		assert.LessOrEqual(t, len(message), 1024)
+	}
+
+	header := http.Header{}
+	header.Set("X-Api-Key", "secret")
+	resolver := NewHCSResolver(server.URL+"/api/v1", header)
+	got, err := resolver.Resolve(context.Background(), uri)
+	require.NoError(t, err)
+	assert.Equal(t, doc, got)
+	assert.Equal(t, "secret", topic.apiKeys[0])
+
+	// By hash alone, scanning the topic
+	_, _, hash, err := ParseHCSURI(uri)
+	require.NoError(t, err)
+	got, err = resolver.ResolveHash(context.Background(), "0.0.7", hash)
+	require.NoError(t, err)
+	assert.Equal(t, doc, got)
+
+	// A missing chunk
+	topic.messages = topic.messages[:3]
+	_, err = resolver.Resolve(context.Background(), uri)
+	assert.ErrorIs(t, err, ErrDocumentNotFound)
+	assert.ErrorContains(t, err, "2 of 3 chunks")
+
+	_, err = store.Put(context.Background(), make([]byte, MaxHCSDocumentSize+1), nil)
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestParseHCSURI(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+	topic, seq, got, err := ParseHCSURI("hcs://0.0.7/42/" + hash)
+	require.NoError(t, err)
+	assert.Equal(t, "0.0.7", topic)
+	assert.Equal(t, uint64(42), seq)
+	assert.Equal(t, hash, got)
+
+	for _, uri := range []string{"ipfs://bafy", "hcs://0.0.7/" + hash, "hcs://0.0.7/0/" + hash, "hcs://0.0.7/1/abcd"} {
+		_, _, _, err := ParseHCSURI(uri)
+		assert.Error(t, err, uri)
+	}
+}
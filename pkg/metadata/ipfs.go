@@ -0,0 +1,189 @@
+package metadata
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base32"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxIPFSDocumentSize is the largest document stored as a single raw IPFS block, whose CID can be computed locally
+const MaxIPFSDocumentSize = 256 * 1024
+
+// Defaults of the pin retries and verification
+const (
+	DefaultPinAttempts  = 3
+	DefaultPinTimeout   = time.Minute
+	DefaultPollInterval = 2 * time.Second
+)
+
+// PinStatus is the state of a pin at a pinning backend, as defined by the IPFS Pinning Service API
+type PinStatus string
+
+const (
+	PinQueued  PinStatus = "queued"
+	PinPinning PinStatus = "pinning"
+	PinPinned  PinStatus = "pinned"
+	PinFailed  PinStatus = "failed"
+	PinMissing PinStatus = "missing" // The backend does not know the CID
+)
+
+// MetadataPinner keeps metadata documents pinned on IPFS, so the CIDs referenced by NFTs remain retrievable.
+// Backends receiving the data add it to IPFS themselves, the others fetch it from the network by its CID.
+type MetadataPinner interface {
+	Name() string
+	Pin(ctx context.Context, cid string, data []byte, name string) error
+	Status(ctx context.Context, cid string) (PinStatus, error)
+}
+
+// CID returns the CIDv1 of data stored as a single raw block (sha2-256, base32), the CID IPFS nodes
+// assign to documents up to MaxIPFSDocumentSize added with CID version 1
+func CID(data []byte) (string, error) {
+	if len(data) > MaxIPFSDocumentSize {
+		return "", fmt.Errorf("%w: %d bytes, IPFS documents are limited to %d", ErrTooLarge, len(data), MaxIPFSDocumentSize)
+	}
+	digest := sha256.Sum256(data)
+	// version 1, raw codec, sha2-256 multihash of 32 bytes
+	raw := append([]byte{0x01, 0x55, 0x12, 0x20}, digest[:]...)
+	return "b" + strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw)), nil
+}
+
+// IPFSStore stores documents on IPFS by pinning them with every pinner, and only returns their ipfs:// URI
+// once every pinner reports the document as pinned. Failed pins are retried with exponential backoff.
+type IPFSStore struct {
+	Pinners      []MetadataPinner
+	PinAttempts  int           // Attempts per pinner, including re-pins of failed pins
+	RetryDelay   time.Duration // Delay before the first retry, doubled on every retry
+	PinTimeout   time.Duration // Time allowed for all pins to complete
+	PollInterval time.Duration // Interval between pin status checks
+
+	// OnPoll, if set, is called with every pin status checked, e.g. to heartbeat while waiting
+	OnPoll func(pinner string, status PinStatus)
+}
+
+// NewIPFSStore returns a store pinning with the given pinners, waiting up to timeout for the pins to complete
+func NewIPFSStore(pinners []MetadataPinner, timeout time.Duration) *IPFSStore {
+	if timeout <= 0 {
+		timeout = DefaultPinTimeout
+	}
+	return &IPFSStore{
+		Pinners:      pinners,
+		PinAttempts:  DefaultPinAttempts,
+		RetryDelay:   time.Second,
+		PinTimeout:   timeout,
+		PollInterval: DefaultPollInterval,
+	}
+}
+
+// Put pins a document with every pinner and returns its ipfs:// URI once all pins are verified.
+// The Domain tag, if any, names the pins.
+func (s *IPFSStore) Put(ctx context.Context, data []byte, tags []Tag) (string, error) {
+	if len(s.Pinners) == 0 {
+		return "", fmt.Errorf("no pinners configured")
+	}
+	cid, err := CID(data)
+	if err != nil {
+		return "", err
+	}
+	name := cid
+	for _, t := range tags {
+		if t.Name == "Domain" && t.Value != "" {
+			name = t.Value
+		}
+	}
+
+	attempts := make(map[string]int)
+	for _, p := range s.Pinners {
+		if err := s.pin(ctx, p, cid, data, name, attempts); err != nil {
+			return "", err
+		}
+	}
+	if err := s.verify(ctx, cid, data, name, attempts); err != nil {
+		return "", err
+	}
+	return "ipfs://" + cid, nil
+}
+
+// pin requests a pin, retrying with backoff until the attempts of the pinner are used up
+func (s *IPFSStore) pin(ctx context.Context, p MetadataPinner, cid string, data []byte, name string, attempts map[string]int) error {
+	delay := s.RetryDelay
+	for {
+		attempts[p.Name()]++
+		err := p.Pin(ctx, cid, data, name)
+		if err == nil {
+			return nil
+		}
+		if attempts[p.Name()] >= s.PinAttempts {
+			return fmt.Errorf("%s: failed to pin %s after %d attempts: %w", p.Name(), cid, attempts[p.Name()], err)
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+		delay *= 2
+	}
+}
+
+// verify polls the pin status of every pinner until all report the document as pinned, re-pinning failed
+// or missing pins while attempts are left
+func (s *IPFSStore) verify(ctx context.Context, cid string, data []byte, name string, attempts map[string]int) error {
+	deadline := time.Now().Add(s.PinTimeout)
+	pending := append([]MetadataPinner(nil), s.Pinners...)
+	statuses := make(map[string]string)
+	for {
+		var next []MetadataPinner
+		for _, p := range pending {
+			status, err := p.Status(ctx, cid)
+			if err != nil {
+				// Status checks are retried until the deadline, the pin itself may be fine
+				statuses[p.Name()] = err.Error()
+				next = append(next, p)
+				continue
+			}
+			statuses[p.Name()] = string(status)
+			if s.OnPoll != nil {
+				s.OnPoll(p.Name(), status)
+			}
+			switch status {
+			case PinPinned:
+			case PinFailed, PinMissing:
+				if attempts[p.Name()] >= s.PinAttempts {
+					return fmt.Errorf("%s: pin of %s is %s after %d attempts", p.Name(), cid, status, attempts[p.Name()])
+				}
+				if err := s.pin(ctx, p, cid, data, name, attempts); err != nil {
+					return err
+				}
+				next = append(next, p)
+			default:
+				next = append(next, p)
+			}
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			var states []string
+			for _, p := range next {
+				states = append(states, fmt.Sprintf("%s: %s", p.Name(), statuses[p.Name()]))
+			}
+			return fmt.Errorf("%s not pinned within %s (%s)", cid, s.PinTimeout, strings.Join(states, ", "))
+		}
+		pending = next
+		if err := sleep(ctx, s.PollInterval); err != nil {
+			return err
+		}
+	}
+}
+
+// sleep waits for d, returning early with the context error when ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
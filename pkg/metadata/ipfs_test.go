@@ -0,0 +1,187 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePinner fails its first pins and reports the given statuses in turn, then pinned
+type fakePinner struct {
+	failPins int
+	statuses []PinStatus
+	pins     int
+}
+
+func (p *fakePinner) Name() string { return "fake" }
+
+func (p *fakePinner) Pin(context.Context, string, []byte, string) error {
+	p.pins++
+	if p.pins <= p.failPins {
+		return errors.New("unavailable")
+	}
+	return nil
+}
+
+func (p *fakePinner) Status(context.Context, string) (PinStatus, error) {
+	if len(p.statuses) == 0 {
+		return PinPinned, nil
+	}
+	status := p.statuses[0]
+	p.statuses = p.statuses[1:]
+	return status, nil
+}
+
+func testIPFSStore(pinners ...MetadataPinner) *IPFSStore {
+	s := NewIPFSStore(pinners, time.Second)
+	s.RetryDelay = time.Millisecond
+	s.PollInterval = time.Millisecond
+	return s
+}
+
+func TestCID(t *testing.T) {
+	cid, err := CID([]byte("hello world"))
+	require.NoError(t, err)
+	assert.Equal(t, "bafkreifzjut3te2nhyekklss27nh3k72ysco7y32koao5eei66wof36n5e", cid)
+
+	_, err = CID(make([]byte, MaxIPFSDocumentSize+1))
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestIPFSStore_Put(t *testing.T) {
+	cid, _ := CID([]byte("{}"))
+
+	// Pins are retried and verified, failed pins are pinned again
+	pinner := &fakePinner{failPins: 1, statuses: []PinStatus{PinQueued, PinFailed, PinPinning}}
+	var polled []PinStatus
+	store := testIPFSStore(pinner)
+	store.OnPoll = func(_ string, status PinStatus) { polled = append(polled, status) }
+	uri, err := store.Put(context.Background(), []byte("{}"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ipfs://"+cid, uri)
+	assert.Equal(t, 3, pinner.pins)
+	assert.Equal(t, []PinStatus{PinQueued, PinFailed, PinPinning, PinPinned}, polled)
+
+	// Attempts are shared between pinning and re-pinning
+	pinner = &fakePinner{failPins: 2, statuses: []PinStatus{PinFailed}}
+	_, err = testIPFSStore(pinner).Put(context.Background(), []byte("{}"), nil)
+	assert.ErrorContains(t, err, "after 3 attempts")
+
+	// Pins still queued at the deadline fail the upload
+	pinner = &fakePinner{statuses: make([]PinStatus, 10000)}
+	for i := range pinner.statuses {
+		pinner.statuses[i] = PinQueued
+	}
+	store = testIPFSStore(pinner)
+	store.PinTimeout = 20 * time.Millisecond
+	_, err = store.Put(context.Background(), []byte("{}"), nil)
+	assert.ErrorContains(t, err, "not pinned within")
+}
+
+func TestPinataPinner(t *testing.T) {
+	data := []byte(`{"name":"example.build"}`)
+	cid, _ := CID(data)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/pinning/pinFileToIPFS":
+			file, _, err := r.FormFile("file")
+			require.NoError(t, err)
+			content, _ := io.ReadAll(file)
+			assert.Equal(t, data, content)
+			assert.Equal(t, `{"cidVersion":1}`, r.FormValue("pinataOptions"))
+			got, _ := CID(content)
+			fmt.Fprintf(w, `{"IpfsHash":%q}`, got)
+		case "/data/pinList":
+			count := 0
+			if r.URL.Query().Get("hashContains") == cid {
+				count = 1
+			}
+			fmt.Fprintf(w, `{"count":%d}`, count)
+		}
+	}))
+	defer server.Close()
+
+	p := NewPinataPinner(server.URL, "secret")
+	require.NoError(t, p.Pin(context.Background(), cid, data, "example.build"))
+	status, err := p.Status(context.Background(), cid)
+	require.NoError(t, err)
+	assert.Equal(t, PinPinned, status)
+	status, err = p.Status(context.Background(), "bafyother")
+	require.NoError(t, err)
+	assert.Equal(t, PinMissing, status)
+
+	assert.ErrorContains(t, p.Pin(context.Background(), "bafyother", data, "example.build"), "instead of")
+}
+
+func TestPinningServicePinner(t *testing.T) {
+	pins := map[string]PinStatus{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		switch r.Method {
+		case http.MethodPost:
+			var req struct{ CID, Name string }
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			pins[req.CID] = PinQueued
+			fmt.Fprint(w, `{"requestid":"1","status":"queued"}`)
+		case http.MethodGet:
+			status, ok := pins[r.URL.Query().Get("cid")]
+			if !ok {
+				fmt.Fprint(w, `{"count":0,"results":[]}`)
+				return
+			}
+			fmt.Fprintf(w, `{"count":1,"results":[{"requestid":"1","status":%q}]}`, status)
+		}
+	}))
+	defer server.Close()
+
+	p := NewWeb3StoragePinner(server.URL, "token")
+	assert.Equal(t, "web3storage", p.Name())
+	status, err := p.Status(context.Background(), "bafyexample")
+	require.NoError(t, err)
+	assert.Equal(t, PinMissing, status)
+	require.NoError(t, p.Pin(context.Background(), "bafyexample", nil, "example.build"))
+	status, err = p.Status(context.Background(), "bafyexample")
+	require.NoError(t, err)
+	assert.Equal(t, PinQueued, status)
+}
+
+func TestKuboPinner(t *testing.T) {
+	data := []byte(`{"name":"example.build"}`)
+	cid, _ := CID(data)
+	pinned := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/add":
+			assert.Equal(t, "1", r.URL.Query().Get("cid-version"))
+			pinned = true
+			fmt.Fprintf(w, `{"Name":"example.build.json","Hash":%q}`, cid)
+		case "/api/v0/pin/ls":
+			if !pinned {
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprintf(w, `{"Message":"path '%s' is not pinned","Code":0,"Type":"error"}`, cid)
+				return
+			}
+			fmt.Fprintf(w, `{"Keys":{%q:{"Type":"recursive"}}}`, cid)
+		}
+	}))
+	defer server.Close()
+
+	p := NewKuboPinner(server.URL)
+	status, err := p.Status(context.Background(), cid)
+	require.NoError(t, err)
+	assert.Equal(t, PinMissing, status)
+	require.NoError(t, p.Pin(context.Background(), cid, data, "example.build"))
+	status, err = p.Status(context.Background(), cid)
+	require.NoError(t, err)
+	assert.Equal(t, PinPinned, status)
+}
@@ -0,0 +1,102 @@
+// Package metadata stores the metadata documents of domain NFTs off-chain. On-chain NFT metadata is limited
+// to 100 bytes, so the full document of a registration lives in a Store and is referenced by its URI.
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Format is the metadata standard the documents follow
+const Format = "HIP412@2.0.0"
+
+// ErrTooLarge is returned for documents exceeding the size a store accepts
+var ErrTooLarge = errors.New("metadata document too large")
+
+// Store keeps metadata documents and returns the URI they can be retrieved from.
+// Tags are indexed by stores that support them, so documents can be looked up without the registry.
+type Store interface {
+	Put(ctx context.Context, data []byte, tags []Tag) (string, error)
+}
+
+// Tag is a name/value pair attached to a stored document
+type Tag struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Document is the metadata document of a domain NFT, following HIP-412
+type Document struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Format      string      `json:"format"`
+	Attributes  []Attribute `json:"attributes,omitempty"`
+	Properties  Properties  `json:"properties"`
+}
+
+// Attribute is a trait of the NFT, as displayed by wallets and marketplaces
+type Attribute struct {
+	TraitType string `json:"trait_type"`
+	Value     string `json:"value"`
+}
+
+// TraitNameserver is the trait of the nameservers of a domain, one attribute per nameserver
+const TraitNameserver = "nameserver"
+
+// TraitGeneratedLabel tags domains whose label looks algorithmically generated, for abuse analytics
+const TraitGeneratedLabel = "generated_label"
+
+// Properties are the registration details of a domain
+type Properties struct {
+	Domain       string     `json:"domain"`
+	Zone         string     `json:"zone"`
+	RegisteredAt *time.Time `json:"registered_at,omitempty"`
+	Registrar    string     `json:"registrar,omitempty"`
+	EventHash    string     `json:"event_hash,omitempty"`             // Canonical hash of the registry event, see pkg/eventhash
+	Nameservers  []string   `json:"nameservers,omitempty"`            // Delegation of the domain at registration time
+	Registrant   string     `json:"registrant_fingerprint,omitempty"` // Keyed hash of the registrant, see pkg/fingerprint
+	Redacted     []Redacted `json:"redacted,omitempty"`               // Fields withheld by the redaction policy of the registry
+	DGAScore     float64    `json:"dga_score,omitempty"`              // Set when the label looks algorithmically generated
+	Previous     *Lineage   `json:"previous,omitempty"`               // NFT of the earlier registration of a re-registered domain
+}
+
+// Lineage is the NFT of an earlier registration of the domain, linked by a re-registration
+type Lineage struct {
+	TokenID      string `json:"token_id"`
+	SerialNumber int64  `json:"serial_number"`
+	EventHash    string `json:"event_hash,omitempty"` // Hash of the event the earlier NFT was minted for, possibly truncated
+}
+
+// Redacted names a field withheld from the document and how: stripped or hashed
+type Redacted struct {
+	Field  string `json:"field"`
+	Action string `json:"action"`
+}
+
+// Marshal returns the JSON encoding of the document
+func (d Document) Marshal() ([]byte, error) {
+	if d.Format == "" {
+		d.Format = Format
+	}
+	return json.Marshal(d)
+}
+
+// Tags returns the tags identifying the document in a store
+func (d Document) Tags() []Tag {
+	tags := []Tag{
+		{Name: "Content-Type", Value: "application/json"},
+		{Name: "App-Name", Value: "shadow-domain-ledger"},
+		{Name: "Domain", Value: d.Properties.Domain},
+		{Name: "Zone", Value: d.Properties.Zone},
+	}
+	if d.Properties.EventHash != "" {
+		tags = append(tags, Tag{Name: "Event-Hash", Value: d.Properties.EventHash})
+	}
+	if previous := d.Properties.Previous; previous != nil {
+		tags = append(tags, Tag{Name: "Previous-NFT", Value: fmt.Sprintf("%s/%d", previous.TokenID, previous.SerialNumber)})
+	}
+	return tags
+}
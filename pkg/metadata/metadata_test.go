@@ -0,0 +1,165 @@
+package metadata
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testWallet generates a wallet, with a key smaller than real Arweave wallets to keep the tests fast
+func testWallet(t *testing.T) *ArweaveWallet {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return NewArweaveWallet(key)
+}
+
+// walletJWK encodes a wallet as the JWK files of Arweave wallets
+func walletJWK(w *ArweaveWallet) []byte {
+	enc := func(i *big.Int) string { return b64.EncodeToString(i.Bytes()) }
+	data, _ := json.Marshal(map[string]string{
+		"kty": "RSA",
+		"n":   enc(w.key.N),
+		"e":   enc(big.NewInt(int64(w.key.E))),
+		"d":   enc(w.key.D),
+		"p":   enc(w.key.Primes[0]),
+		"q":   enc(w.key.Primes[1]),
+	})
+	return data
+}
+
+func TestDocument(t *testing.T) {
+	doc := Document{Name: "example.build", Properties: Properties{Domain: "example.build", Zone: "build", EventHash: "ab12"}}
+	data, err := doc.Marshal()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"format":"HIP412@2.0.0"`)
+	assert.NotContains(t, string(data), "registered_at")
+	assert.Contains(t, doc.Tags(), Tag{Name: "Event-Hash", Value: "ab12"})
+	assert.NotContains(t, string(data), "attributes")
+
+	doc.Attributes = []Attribute{{TraitType: TraitNameserver, Value: "ns1.example.net"}}
+	data, err = doc.Marshal()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"attributes":[{"trait_type":"nameserver","value":"ns1.example.net"}]`)
+	assert.NotContains(t, string(data), "previous")
+
+	doc.Properties.Previous = &Lineage{TokenID: "0.0.1234", SerialNumber: 7}
+	data, err = doc.Marshal()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"previous":{"token_id":"0.0.1234","serial_number":7}`)
+	assert.Contains(t, doc.Tags(), Tag{Name: "Previous-NFT", Value: "0.0.1234/7"})
+}
+
+func TestParseArweaveWallet(t *testing.T) {
+	w := testWallet(t)
+	parsed, err := ParseArweaveWallet(walletJWK(w))
+	require.NoError(t, err)
+	assert.Equal(t, w.Address(), parsed.Address())
+	assert.Len(t, w.Address(), 43)
+
+	_, err = ParseArweaveWallet([]byte(`{"kty":"EC"}`))
+	assert.Error(t, err)
+	_, err = ParseArweaveWallet([]byte(`{"kty":"RSA","n":"AQAB","e":"AQAB"}`))
+	assert.Error(t, err)
+}
+
+func TestDataRoot(t *testing.T) {
+	// A single chunk: the root is its leaf
+	data := []byte(`{"name":"example.build"}`)
+	leaf := sha256Concat(sha256Sum(sha256Sum(data)), sha256Sum(noteBytes(len(data))))
+	assert.Equal(t, leaf, dataRoot(data))
+
+	// A remainder below the minimum chunk size is balanced over the last two chunks
+	data = make([]byte, arweaveMaxChunkSize+1000)
+	half := (len(data) + 1) / 2
+	left := arweaveLeaf(data[:half], half)
+	right := arweaveLeaf(data[half:], len(data))
+	branch := sha256Concat(sha256Sum(left.id), sha256Sum(right.id), sha256Sum(noteBytes(half)))
+	assert.Equal(t, branch, dataRoot(data))
+
+	// An odd node is carried up to the next layer
+	data = make([]byte, 2*arweaveMaxChunkSize+arweaveMinChunkSize)
+	a := arweaveLeaf(data[:arweaveMaxChunkSize], arweaveMaxChunkSize)
+	b := arweaveLeaf(data[arweaveMaxChunkSize:2*arweaveMaxChunkSize], 2*arweaveMaxChunkSize)
+	c := arweaveLeaf(data[2*arweaveMaxChunkSize:], len(data))
+	ab := sha256Concat(sha256Sum(a.id), sha256Sum(b.id), sha256Sum(noteBytes(a.maxByteRange)))
+	root := sha256Concat(sha256Sum(ab), sha256Sum(c.id), sha256Sum(noteBytes(b.maxByteRange)))
+	assert.Equal(t, root, dataRoot(data))
+}
+
+func TestArweaveStore_Put(t *testing.T) {
+	wallet := testWallet(t)
+	anchor := b64.EncodeToString(make([]byte, 48))
+	var posted ArweaveTransaction
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/tx_anchor":
+			fmt.Fprint(w, anchor)
+		case strings.HasPrefix(r.URL.Path, "/price/"):
+			fmt.Fprint(w, "123456")
+		case r.Method == http.MethodPost && r.URL.Path == "/tx":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&posted))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	store := NewArweaveStore(server.URL+"/", wallet)
+	data := []byte(`{"name":"example.build"}`)
+	uri, err := store.Put(context.Background(), data, []Tag{{Name: "Zone", Value: "build"}})
+	require.NoError(t, err)
+	assert.Equal(t, "ar://"+posted.ID, uri)
+
+	assert.Equal(t, 2, posted.Format)
+	assert.Equal(t, anchor, posted.LastTx)
+	assert.Equal(t, "123456", posted.Reward)
+	assert.Equal(t, b64.EncodeToString(data), posted.Data)
+	assert.Equal(t, []arweaveTag{{Name: b64.EncodeToString([]byte("Zone")), Value: b64.EncodeToString([]byte("build"))}}, posted.Tags)
+
+	// The signature covers the deep hash of the transaction and the ID is its SHA-256
+	signature, err := b64.DecodeString(posted.Signature)
+	require.NoError(t, err)
+	id := sha256.Sum256(signature)
+	assert.Equal(t, b64.EncodeToString(id[:]), posted.ID)
+	lastTx, _ := b64.DecodeString(anchor)
+	message := deepHash([]interface{}{
+		[]byte("2"), wallet.owner(), []byte{}, []byte("0"), []byte("123456"), lastTx,
+		[]interface{}{[]interface{}{[]byte("Zone"), []byte("build")}},
+		[]byte(posted.DataSize), dataRoot(data),
+	})
+	digest := sha256.Sum256(message)
+	assert.NoError(t, rsa.VerifyPSS(&wallet.key.PublicKey, crypto.SHA256, digest[:], signature, nil))
+
+	_, err = store.Put(context.Background(), make([]byte, MaxArweaveDocumentSize+1), nil)
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestArweaveStore_Refused(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tx_anchor":
+			fmt.Fprint(w, b64.EncodeToString(make([]byte, 48)))
+		case "/tx":
+			http.Error(w, "Insufficient funds", http.StatusPaymentRequired)
+		default:
+			fmt.Fprint(w, "1")
+		}
+	}))
+	defer server.Close()
+
+	_, err := NewArweaveStore(server.URL, testWallet(t)).Put(context.Background(), []byte("{}"), nil)
+	assert.ErrorContains(t, err, "Insufficient funds")
+}
@@ -0,0 +1,223 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Default endpoints of the pinning backends
+const (
+	DefaultPinataURL      = "https://api.pinata.cloud"
+	DefaultWeb3StorageURL = "https://api.web3.storage"
+	DefaultKuboURL        = "http://localhost:5001"
+)
+
+// PinataPinner pins documents with Pinata, uploading the data
+type PinataPinner struct {
+	BaseURL    string
+	JWT        string // API key JWT
+	HTTPClient *http.Client
+}
+
+// NewPinataPinner returns a Pinata pinner, using DefaultPinataURL if baseURL is empty
+func NewPinataPinner(baseURL, jwt string) *PinataPinner {
+	return &PinataPinner{BaseURL: baseURLOr(baseURL, DefaultPinataURL), JWT: jwt, HTTPClient: http.DefaultClient}
+}
+
+func (p *PinataPinner) Name() string { return "pinata" }
+
+// Pin uploads the document, Pinata pins what it receives
+func (p *PinataPinner) Pin(ctx context.Context, cid string, data []byte, name string) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, err := form.CreateFormFile("file", name+".json")
+	if err != nil {
+		return err
+	}
+	file.Write(data)
+	form.WriteField("pinataMetadata", fmt.Sprintf(`{"name":%q}`, name))
+	form.WriteField("pinataOptions", `{"cidVersion":1}`)
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	var response struct {
+		IpfsHash string `json:"IpfsHash"`
+	}
+	if err := doJSON(ctx, p.HTTPClient, http.MethodPost, p.BaseURL+"/pinning/pinFileToIPFS", form.FormDataContentType(), &body, p.JWT, &response); err != nil {
+		return err
+	}
+	if response.IpfsHash != cid {
+		return fmt.Errorf("pinned as %s instead of %s", response.IpfsHash, cid)
+	}
+	return nil
+}
+
+// Status reports the document as pinned once it is listed among the active pins
+func (p *PinataPinner) Status(ctx context.Context, cid string) (PinStatus, error) {
+	var response struct {
+		Count int `json:"count"`
+	}
+	path := "/data/pinList?status=pinned&hashContains=" + url.QueryEscape(cid)
+	if err := doJSON(ctx, p.HTTPClient, http.MethodGet, p.BaseURL+path, "", nil, p.JWT, &response); err != nil {
+		return "", err
+	}
+	if response.Count == 0 {
+		return PinMissing, nil
+	}
+	return PinPinned, nil
+}
+
+// PinningServicePinner pins documents with a provider of the IPFS Pinning Service API, such as web3.storage.
+// The provider fetches the data from the network, so it must be used along with a pinner receiving the data.
+type PinningServicePinner struct {
+	Label      string // Name of the provider
+	Endpoint   string
+	Token      string // Access token
+	HTTPClient *http.Client
+}
+
+// NewPinningServicePinner returns a pinner of the Pinning Service API at endpoint
+func NewPinningServicePinner(label, endpoint, token string) *PinningServicePinner {
+	return &PinningServicePinner{Label: label, Endpoint: strings.TrimRight(endpoint, "/"), Token: token, HTTPClient: http.DefaultClient}
+}
+
+// NewWeb3StoragePinner returns a pinner of web3.storage, using DefaultWeb3StorageURL if endpoint is empty
+func NewWeb3StoragePinner(endpoint, token string) *PinningServicePinner {
+	return NewPinningServicePinner("web3storage", baseURLOr(endpoint, DefaultWeb3StorageURL), token)
+}
+
+func (p *PinningServicePinner) Name() string { return p.Label }
+
+// pinStatusResponse is a pin status object of the Pinning Service API
+type pinStatusResponse struct {
+	RequestID string    `json:"requestid"`
+	Status    PinStatus `json:"status"`
+}
+
+// Pin requests a pin of the CID, the data is fetched from the network
+func (p *PinningServicePinner) Pin(ctx context.Context, cid string, _ []byte, name string) error {
+	body, err := json.Marshal(map[string]string{"cid": cid, "name": name})
+	if err != nil {
+		return err
+	}
+	var response pinStatusResponse
+	return doJSON(ctx, p.HTTPClient, http.MethodPost, p.Endpoint+"/pins", "application/json", bytes.NewReader(body), p.Token, &response)
+}
+
+// Status returns the status of the most recent pin request of the CID
+func (p *PinningServicePinner) Status(ctx context.Context, cid string) (PinStatus, error) {
+	var response struct {
+		Count   int                 `json:"count"`
+		Results []pinStatusResponse `json:"results"`
+	}
+	path := "/pins?limit=1&status=queued,pinning,pinned,failed&cid=" + url.QueryEscape(cid)
+	if err := doJSON(ctx, p.HTTPClient, http.MethodGet, p.Endpoint+path, "", nil, p.Token, &response); err != nil {
+		return "", err
+	}
+	if len(response.Results) == 0 {
+		return PinMissing, nil
+	}
+	return response.Results[0].Status, nil
+}
+
+// KuboPinner pins documents on a self-hosted IPFS node through the Kubo RPC API, adding the data
+type KuboPinner struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// NewKuboPinner returns a pinner of the node at baseURL, DefaultKuboURL if empty
+func NewKuboPinner(baseURL string) *KuboPinner {
+	return &KuboPinner{BaseURL: baseURLOr(baseURL, DefaultKuboURL), HTTPClient: http.DefaultClient}
+}
+
+func (p *KuboPinner) Name() string { return "kubo" }
+
+// Pin adds the document to the node with a recursive pin
+func (p *KuboPinner) Pin(ctx context.Context, cid string, data []byte, name string) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, err := form.CreateFormFile("file", name+".json")
+	if err != nil {
+		return err
+	}
+	file.Write(data)
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	var response struct {
+		Hash string `json:"Hash"`
+	}
+	if err := doJSON(ctx, p.HTTPClient, http.MethodPost, p.BaseURL+"/api/v0/add?cid-version=1&pin=true", form.FormDataContentType(), &body, "", &response); err != nil {
+		return err
+	}
+	if response.Hash != cid {
+		return fmt.Errorf("added as %s instead of %s", response.Hash, cid)
+	}
+	return nil
+}
+
+// Status reports whether the node holds a recursive pin of the CID
+func (p *KuboPinner) Status(ctx context.Context, cid string) (PinStatus, error) {
+	var response struct {
+		Keys map[string]struct {
+			Type string `json:"Type"`
+		} `json:"Keys"`
+	}
+	err := doJSON(ctx, p.HTTPClient, http.MethodPost, p.BaseURL+"/api/v0/pin/ls?type=recursive&arg="+url.QueryEscape(cid), "", nil, "", &response)
+	if err != nil {
+		// Kubo answers an error rather than an empty list for CIDs that are not pinned
+		if strings.Contains(err.Error(), "not pinned") {
+			return PinMissing, nil
+		}
+		return "", err
+	}
+	if _, ok := response.Keys[cid]; !ok {
+		return PinMissing, nil
+	}
+	return PinPinned, nil
+}
+
+// doJSON sends a request with an optional bearer token and decodes the JSON response into out
+func doJSON(ctx context.Context, client *http.Client, method, url, contentType string, body io.Reader, token string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: status %d: %s", method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: invalid response: %w", method, req.URL.Path, err)
+	}
+	return nil
+}
+
+// baseURLOr returns baseURL without trailing slash, or fallback if empty
+func baseURLOr(baseURL, fallback string) string {
+	if baseURL == "" {
+		return fallback
+	}
+	return strings.TrimRight(baseURL, "/")
+}
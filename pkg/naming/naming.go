@@ -0,0 +1,185 @@
+// Package naming renders the token names and symbols of zone collections from Go templates, so every registry
+// running the ledger names its collections its own way. Names identify collections on the mirror node, symbols
+// are what wallets display: both are checked against the limits of Hedera before a collection is created.
+package naming
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Defaults of the naming of zone collections, e.g. "APEX Domain Ledger Zone - .BUILD" and "APEX-ZONE.BUILD"
+const (
+	DefaultRegistryID     = "APEX"
+	DefaultZonePrefix     = "ZONE"
+	DefaultNameTemplate   = "{{upper .Registry}} Domain Ledger Zone - .{{upper .Zone}}"
+	DefaultSymbolTemplate = "{{upper .Registry}}-{{upper .Prefix}}.{{upper .Zone}}"
+)
+
+// Limits of Hedera on token names and symbols, in bytes
+const (
+	MaxNameSize   = 100
+	MaxSymbolSize = 100
+)
+
+// sampleZone is rendered to check templates when they are parsed
+const sampleZone = "example"
+
+// Data is what name and symbol templates are executed with
+type Data struct {
+	Registry string // Identifier of the registry, e.g. APEX
+	Prefix   string // Prefix of zone collections, e.g. ZONE
+	Zone     string // Zone of the collection, lower case without leading dot, e.g. build
+}
+
+var funcs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// Naming renders the names and symbols of zone collections
+type Naming struct {
+	registry string
+	prefix   string
+	name     *template.Template
+	symbol   *template.Template
+}
+
+// New parses the name and symbol templates, and checks they render a valid name and symbol
+func New(registry, prefix, nameTemplate, symbolTemplate string) (*Naming, error) {
+	name, err := template.New("name").Funcs(funcs).Parse(nameTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("name template: %w", err)
+	}
+	symbol, err := template.New("symbol").Funcs(funcs).Parse(symbolTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("symbol template: %w", err)
+	}
+	n := &Naming{registry: registry, prefix: prefix, name: name, symbol: symbol}
+	// Long zones are truncated to fit, but the templates must fit as they are with a short one
+	if sample, err := n.render(n.name, sampleZone); err != nil {
+		return nil, fmt.Errorf("name template: %w", err)
+	} else if err := ValidateName(sample); err != nil {
+		return nil, fmt.Errorf("name of .%s: %w", sampleZone, err)
+	}
+	if sample, err := n.render(n.symbol, sampleZone); err != nil {
+		return nil, fmt.Errorf("symbol template: %w", err)
+	} else if err := ValidateSymbol(sample); err != nil {
+		return nil, fmt.Errorf("symbol of .%s: %w", sampleZone, err)
+	}
+	return n, nil
+}
+
+// Default returns the naming of collections created before names were configurable
+func Default() *Naming {
+	n, err := New(DefaultRegistryID, DefaultZonePrefix, DefaultNameTemplate, DefaultSymbolTemplate)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// Name returns the token name of the collection of a zone, truncated by Fit when the zone is too long for it
+func (n *Naming) Name(zone string) (string, error) {
+	name, err := n.render(n.name, zone)
+	if err != nil {
+		return "", fmt.Errorf("name template: %w", err)
+	}
+	if err := ValidateName(Fit(name, MaxNameSize)); err != nil {
+		return "", fmt.Errorf("name of .%s: %w", zone, err)
+	}
+	return Fit(name, MaxNameSize), nil
+}
+
+// Symbol returns the token symbol of the collection of a zone, truncated by Fit when the zone is too long for it
+func (n *Naming) Symbol(zone string) (string, error) {
+	symbol, err := n.render(n.symbol, zone)
+	if err != nil {
+		return "", fmt.Errorf("symbol template: %w", err)
+	}
+	symbol = Fit(symbol, MaxSymbolSize)
+	if err := ValidateSymbol(symbol); err != nil {
+		return "", fmt.Errorf("symbol of .%s: %w", zone, err)
+	}
+	return symbol, nil
+}
+
+// Fit returns a name or symbol of at most size bytes. Longer ones are truncated deterministically, at a
+// character boundary, and end with "-" and the first 8 hex digits of the SHA-256 of the whole, so zones that
+// only differ past the cut keep different names. The zone registry maps truncated names back to their zone.
+func Fit(s string, size int) string {
+	if len(s) <= size {
+		return s
+	}
+	sum := sha256.Sum256([]byte(s))
+	suffix := "-" + hex.EncodeToString(sum[:4])
+	cut := max(size-len(suffix), 0)
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return strings.TrimRight(s[:cut], " ") + suffix
+}
+
+// Shard returns the name or symbol of a shard of a zone collection, for zones too large for one collection:
+// the first shard keeps the name of the collection, the next ones get their number, e.g. "APEX-ZONE.COM-2".
+// The name is truncated by Fit to keep the number within the limits, the same for names and symbols.
+func Shard(nameOrSymbol string, shard int) string {
+	if shard <= 1 {
+		return nameOrSymbol
+	}
+	suffix := "-" + strconv.Itoa(shard)
+	return Fit(nameOrSymbol, MaxSymbolSize-len(suffix)) + suffix
+}
+
+func (n *Naming) render(t *template.Template, zone string) (string, error) {
+	var b strings.Builder
+	data := Data{Registry: n.registry, Prefix: n.prefix, Zone: strings.TrimPrefix(strings.ToLower(zone), ".")}
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// ValidateName checks a token name: printable UTF-8 of at most MaxNameSize bytes, without surrounding spaces
+func ValidateName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("empty")
+	case len(name) > MaxNameSize:
+		return fmt.Errorf("%q is longer than %d bytes", name, MaxNameSize)
+	case !utf8.ValidString(name):
+		return fmt.Errorf("%q is not valid UTF-8", name)
+	case name != strings.TrimSpace(name):
+		return fmt.Errorf("%q has leading or trailing spaces", name)
+	}
+	for _, r := range name {
+		if !unicode.IsPrint(r) {
+			return fmt.Errorf("%q contains the unprintable character %U", name, r)
+		}
+	}
+	return nil
+}
+
+// ValidateSymbol checks a token symbol: at most MaxSymbolSize ASCII letters, digits, dots, hyphens and
+// underscores, which every wallet displays alike
+func ValidateSymbol(symbol string) error {
+	if symbol == "" {
+		return errors.New("empty")
+	}
+	if len(symbol) > MaxSymbolSize {
+		return fmt.Errorf("%q is longer than %d bytes", symbol, MaxSymbolSize)
+	}
+	for _, r := range symbol {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_') {
+			return fmt.Errorf("%q contains %q, only letters, digits, '.', '-' and '_' are allowed", symbol, r)
+		}
+	}
+	return nil
+}
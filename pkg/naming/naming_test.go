@@ -0,0 +1,99 @@
+package naming
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	n := Default()
+	name, err := n.Name(".build")
+	require.NoError(t, err)
+	assert.Equal(t, "APEX Domain Ledger Zone - .BUILD", name)
+	symbol, err := n.Symbol("build")
+	require.NoError(t, err)
+	assert.Equal(t, "APEX-ZONE.BUILD", symbol)
+}
+
+func TestShard(t *testing.T) {
+	assert.Equal(t, "APEX-ZONE.COM", Shard("APEX-ZONE.COM", 0))
+	assert.Equal(t, "APEX-ZONE.COM", Shard("APEX-ZONE.COM", 1))
+	assert.Equal(t, "APEX-ZONE.COM-2", Shard("APEX-ZONE.COM", 2))
+	assert.Equal(t, "APEX Domain Ledger Zone - .COM-12", Shard("APEX Domain Ledger Zone - .COM", 12))
+	assert.NoError(t, ValidateSymbol(Shard("APEX-ZONE.COM", 3)))
+}
+
+func TestFit(t *testing.T) {
+	assert.Equal(t, "APEX-ZONE.COM", Fit("APEX-ZONE.COM", MaxSymbolSize))
+
+	long := "APEX-ZONE." + strings.Repeat("XN--LABEL.", 12)
+	fitted := Fit(long, MaxSymbolSize)
+	assert.Len(t, fitted, MaxSymbolSize)
+	assert.True(t, strings.HasPrefix(fitted, long[:MaxSymbolSize-9]))
+	assert.Equal(t, fitted, Fit(long, MaxSymbolSize), "truncation is deterministic")
+	assert.NotEqual(t, fitted, Fit(long+"X", MaxSymbolSize), "zones differing past the cut keep different symbols")
+	assert.NoError(t, ValidateSymbol(fitted))
+
+	// Multi-byte characters are not cut
+	name := Fit(strings.Repeat("é", 60), MaxNameSize)
+	assert.LessOrEqual(t, len(name), MaxNameSize)
+	assert.NoError(t, ValidateName(name))
+}
+
+func TestNaming_LongZone(t *testing.T) {
+	n := Default()
+	zone := strings.Repeat("xn--label.", 11) + "com"
+
+	name, err := n.Name(zone)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(name), MaxNameSize)
+	symbol, err := n.Symbol(zone)
+	require.NoError(t, err)
+	assert.Len(t, symbol, MaxSymbolSize)
+
+	shard := Shard(symbol, 12)
+	assert.Len(t, shard, MaxSymbolSize)
+	assert.True(t, strings.HasSuffix(shard, "-12"))
+	assert.NoError(t, ValidateSymbol(shard))
+}
+
+func TestNew_Templates(t *testing.T) {
+	n, err := New("Acme", "tld", "{{.Registry}} registrations in .{{.Zone}}", "{{upper .Prefix}}_{{upper .Zone}}")
+	require.NoError(t, err)
+	name, err := n.Name("BUILD")
+	require.NoError(t, err)
+	assert.Equal(t, "Acme registrations in .build", name)
+	symbol, err := n.Symbol("build")
+	require.NoError(t, err)
+	assert.Equal(t, "TLD_BUILD", symbol)
+
+	// Symbols are checked for every zone, IDN zones keep their ACE form
+	symbol, err = n.Symbol("xn--p1ai")
+	require.NoError(t, err)
+	assert.Equal(t, "TLD_XN--P1AI", symbol)
+}
+
+func TestNew_Invalid(t *testing.T) {
+	for msg, templates := range map[string][2]string{
+		"name template":       {"{{.Registry", DefaultSymbolTemplate},
+		"symbol template":     {DefaultNameTemplate, "{{.Owner}}"},
+		"only letters":        {DefaultNameTemplate, "{{.Registry}} {{.Zone}}"},
+		"longer than 100":     {DefaultNameTemplate + strings.Repeat("0123456789", 10), DefaultSymbolTemplate},
+		"leading or trailing": {" {{.Zone}}", DefaultSymbolTemplate},
+		"empty":               {DefaultNameTemplate, "{{if false}}x{{end}}"},
+	} {
+		_, err := New(DefaultRegistryID, DefaultZonePrefix, templates[0], templates[1])
+		assert.ErrorContains(t, err, msg)
+	}
+}
+
+func TestValidateSymbol(t *testing.T) {
+	assert.NoError(t, ValidateSymbol("APEX-ZONE.BUILD"))
+	assert.Error(t, ValidateSymbol("ZONE.ЯБ"))
+	assert.Error(t, ValidateSymbol("ZONE BUILD"))
+	assert.NoError(t, ValidateName("Zone .рф"))
+	assert.Error(t, ValidateName("Zone\n.build"))
+}
@@ -0,0 +1,170 @@
+// Package redact applies the redaction policy of personal data to registration records before they are
+// written on-chain or to HCS, where they can never be erased. Every field classified as personal data is kept,
+// stripped or replaced by a keyed hash, and every redaction is recorded, so readers of a record know which of
+// its fields were withheld.
+package redact
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Actions of a policy
+const (
+	ActionKeep  = "keep"  // The field is written as it is
+	ActionStrip = "strip" // The field is left out
+	ActionHash  = "hash"  // The field is replaced by a keyed hash, which still links equal values
+)
+
+// Fields classified as personal data
+const (
+	FieldRegistrar    = "registrar"     // Sponsoring registrar
+	FieldRegisteredAt = "registered_at" // Time of the registration
+	FieldNameservers  = "nameservers"   // Delegation of the domain, may name the registrant
+	FieldRegistrant   = "registrant"    // Fingerprint of the registrant, still pseudonymous personal data
+)
+
+// fieldActions lists the fields of a policy and the actions they support. Times cannot be hashed
+// meaningfully, and registrant fingerprints are keyed hashes already.
+var fieldActions = map[string][]string{
+	FieldRegistrar:    {ActionKeep, ActionStrip, ActionHash},
+	FieldRegisteredAt: {ActionKeep, ActionStrip},
+	FieldNameservers:  {ActionKeep, ActionStrip, ActionHash},
+	FieldRegistrant:   {ActionKeep, ActionStrip},
+}
+
+// Policy maps fields to their action. Fields not in the policy are kept.
+type Policy map[string]string
+
+// ParsePolicy parses comma separated field=action pairs, e.g. "nameservers=hash,registrant=strip"
+func ParsePolicy(s string) (Policy, error) {
+	policy := make(Policy)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		field, action, ok := strings.Cut(pair, "=")
+		field, action = strings.ToLower(strings.TrimSpace(field)), strings.ToLower(strings.TrimSpace(action))
+		if !ok || field == "" || action == "" {
+			return nil, fmt.Errorf("invalid pair %q, expected field=action", pair)
+		}
+		actions, known := fieldActions[field]
+		if !known {
+			return nil, fmt.Errorf("unknown field %q (expected one of %s)", field, strings.Join(Fields(), ", "))
+		}
+		if !slices.Contains(actions, action) {
+			return nil, fmt.Errorf("field %s cannot be redacted with %q (expected %s)", field, action, strings.Join(actions, ", "))
+		}
+		if _, dup := policy[field]; dup {
+			return nil, fmt.Errorf("field %s is listed twice", field)
+		}
+		policy[field] = action
+	}
+	return policy, nil
+}
+
+// Fields returns the fields a policy can redact, sorted
+func Fields() []string {
+	fields := make([]string, 0, len(fieldActions))
+	for field := range fieldActions {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Action returns the action of a field
+func (p Policy) Action(field string) string {
+	if action, ok := p[field]; ok {
+		return action
+	}
+	return ActionKeep
+}
+
+// Hashes reports whether the policy hashes any field, which requires a key
+func (p Policy) Hashes() bool {
+	for _, action := range p {
+		if action == ActionHash {
+			return true
+		}
+	}
+	return false
+}
+
+// Redaction records a field of a record that was redacted, without its value
+type Redaction struct {
+	Field  string `json:"field"`
+	Action string `json:"action"`
+}
+
+// Redactor applies a policy to the fields of one record and records what it redacted
+type Redactor struct {
+	policy     Policy
+	hash       func(field, value string) string
+	Redactions []Redaction
+}
+
+// NewRedactor returns a redactor of one record. hash computes the keyed hash of a value of a field and
+// may be nil if the policy hashes no field.
+func (p Policy) NewRedactor(hash func(field, value string) string) *Redactor {
+	return &Redactor{policy: p, hash: hash}
+}
+
+// String returns a field as the policy allows it to be written: the value, empty or its hash
+func (r *Redactor) String(field, value string) string {
+	if value == "" {
+		return value
+	}
+	switch r.policy.Action(field) {
+	case ActionStrip:
+		r.record(field, ActionStrip)
+		return ""
+	case ActionHash:
+		r.record(field, ActionHash)
+		return r.hash(field, value)
+	}
+	return value
+}
+
+// Strings returns a list field as the policy allows it to be written: the values, nil or their hashes
+func (r *Redactor) Strings(field string, values []string) []string {
+	if len(values) == 0 {
+		return values
+	}
+	switch r.policy.Action(field) {
+	case ActionStrip:
+		r.record(field, ActionStrip)
+		return nil
+	case ActionHash:
+		r.record(field, ActionHash)
+		hashed := make([]string, len(values))
+		for i, v := range values {
+			hashed[i] = r.hash(field, v)
+		}
+		return hashed
+	}
+	return values
+}
+
+// Strip reports whether a field that is present must be left out, recording the redaction if so.
+// It is used for fields that cannot be hashed.
+func (r *Redactor) Strip(field string, present bool) bool {
+	if !present || r.policy.Action(field) != ActionStrip {
+		return false
+	}
+	r.record(field, ActionStrip)
+	return true
+}
+
+// record adds a redaction, once per field
+func (r *Redactor) record(field, action string) {
+	for _, red := range r.Redactions {
+		if red.Field == field {
+			return
+		}
+	}
+	r.Redactions = append(r.Redactions, Redaction{Field: field, Action: action})
+}
@@ -0,0 +1,57 @@
+package redact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy(" Nameservers=HASH, registrant=strip,,")
+	require.NoError(t, err)
+	assert.Equal(t, Policy{FieldNameservers: ActionHash, FieldRegistrant: ActionStrip}, policy)
+	assert.Equal(t, ActionKeep, policy.Action(FieldRegistrar))
+	assert.True(t, policy.Hashes())
+
+	empty, err := ParsePolicy("")
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+	assert.False(t, empty.Hashes())
+
+	for s, msg := range map[string]string{
+		"nameservers":                    "expected field=action",
+		"email=strip":                    "unknown field",
+		"registered_at=hash":             "cannot be redacted",
+		"registrar=strip,registrar=hash": "listed twice",
+		"registrant=obfuscate":           "cannot be redacted",
+	} {
+		_, err := ParsePolicy(s)
+		assert.ErrorContains(t, err, msg, s)
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	policy := Policy{FieldNameservers: ActionHash, FieldRegistrar: ActionStrip, FieldRegisteredAt: ActionStrip}
+	r := policy.NewRedactor(func(field, value string) string { return field + "#" + value })
+
+	assert.Equal(t, "", r.String(FieldRegistrar, "1000"))
+	assert.Equal(t, []string{"nameservers#ns1.example.net", "nameservers#ns2.example.net"},
+		r.Strings(FieldNameservers, []string{"ns1.example.net", "ns2.example.net"}))
+	assert.Equal(t, "C-1", r.String(FieldRegistrant, "C-1"))
+	assert.True(t, r.Strip(FieldRegisteredAt, true))
+	assert.False(t, r.Strip(FieldRegistrant, true))
+
+	assert.Equal(t, []Redaction{
+		{Field: FieldRegistrar, Action: ActionStrip},
+		{Field: FieldNameservers, Action: ActionHash},
+		{Field: FieldRegisteredAt, Action: ActionStrip},
+	}, r.Redactions)
+
+	// Absent fields are not redacted
+	r = policy.NewRedactor(nil)
+	assert.Equal(t, "", r.String(FieldRegistrar, ""))
+	assert.Nil(t, r.Strings(FieldNameservers, nil))
+	assert.False(t, r.Strip(FieldRegisteredAt, false))
+	assert.Empty(t, r.Redactions)
+}
@@ -0,0 +1,175 @@
+// Package signer signs Hedera transactions with keys kept out of the workers: on a remote signing daemon, or
+// behind a program talking to a hardware wallet such as a Ledger device. Signers are asked to sign the body
+// bytes of the transaction for every node, and every signature they return is checked against their public key.
+package signer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+)
+
+// ErrInvalidSignature is returned for signatures that do not verify against the public key of the signer
+var ErrInvalidSignature = errors.New("the signer returned an invalid signature")
+
+// Signer signs messages with a key whose public key it knows
+type Signer interface {
+	PublicKey() hedera.PublicKey
+	Sign(ctx context.Context, message []byte) ([]byte, error)
+}
+
+// SignTransaction signs a frozen transaction with a signer, checking every signature it returns. Other keys,
+// such as the operator key, should sign first: the SDK signs the transaction again when signatures are added
+// after those of the signer, and would ask it again.
+func SignTransaction(ctx context.Context, s Signer, tx hedera.TransactionInterface) error {
+	publicKey := s.PublicKey()
+	var signErr error
+	_, err := hedera.TransactionSignWth(tx, publicKey, func(message []byte) []byte {
+		if signErr != nil {
+			return nil
+		}
+		signature, err := s.Sign(ctx, message)
+		if err == nil && !publicKey.Verify(message, signature) {
+			err = ErrInvalidSignature
+		}
+		signErr = err
+		return signature
+	})
+	if err != nil {
+		return err
+	}
+	// Transactions are signed when they are built, build them now to get the errors of the signer
+	if _, err := hedera.TransactionToBytes(tx); err != nil {
+		return err
+	}
+	return signErr
+}
+
+// Key signs with a private key held in memory, the way transactions are signed without an external signer
+type Key struct {
+	key hedera.PrivateKey
+}
+
+// NewKey returns a signer of the given private key
+func NewKey(key hedera.PrivateKey) *Key {
+	return &Key{key: key}
+}
+
+// PublicKey returns the public key of the private key
+func (k *Key) PublicKey() hedera.PublicKey {
+	return k.key.PublicKey()
+}
+
+// Sign signs a message with the private key
+func (k *Key) Sign(ctx context.Context, message []byte) ([]byte, error) {
+	return k.key.Sign(message), nil
+}
+
+// Remote signs with a remote signing daemon. The message is posted as JSON {"public_key": ..., "message": ...},
+// the DER encoded public key and the Base64 encoded message, and the daemon answers {"signature": ...}, the
+// Base64 encoded signature.
+type Remote struct {
+	url       string
+	token     string
+	publicKey hedera.PublicKey
+	client    *http.Client
+}
+
+// NewRemote returns a signer posting to the signing daemon at the given URL, with the token as bearer token
+// if set
+func NewRemote(url, token string, publicKey hedera.PublicKey) *Remote {
+	return &Remote{url: url, token: token, publicKey: publicKey, client: &http.Client{Timeout: 2 * time.Minute}}
+}
+
+// PublicKey returns the public key of the key the daemon signs with
+func (r *Remote) PublicKey() hedera.PublicKey {
+	return r.publicKey
+}
+
+// Sign asks the daemon to sign a message
+func (r *Remote) Sign(ctx context.Context, message []byte) ([]byte, error) {
+	body, err := json.Marshal(map[string]string{
+		"public_key": r.publicKey.StringDer(),
+		"message":    base64.StdEncoding.EncodeToString(message),
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("signing daemon: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("signing daemon: %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	var response struct {
+		Signature string `json:"signature"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("signing daemon: invalid response: %w", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(response.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("signing daemon: invalid signature: %w", err)
+	}
+	return signature, nil
+}
+
+// Command signs by running a program, e.g. one driving a hardware wallet. The program gets the hex encoded
+// message on its standard input and writes the hex encoded signature to its standard output; it may take as
+// long as the holder of the device needs to confirm.
+type Command struct {
+	argv      []string
+	publicKey hedera.PublicKey
+}
+
+// NewCommand returns a signer running the given program with its arguments
+func NewCommand(argv []string, publicKey hedera.PublicKey) *Command {
+	return &Command{argv: argv, publicKey: publicKey}
+}
+
+// PublicKey returns the public key of the key the program signs with
+func (c *Command) PublicKey() hedera.PublicKey {
+	return c.publicKey
+}
+
+// Sign runs the program to sign a message
+func (c *Command) Sign(ctx context.Context, message []byte) ([]byte, error) {
+	if len(c.argv) == 0 {
+		return nil, errors.New("signing command: no program")
+	}
+	cmd := exec.CommandContext(ctx, c.argv[0], c.argv[1:]...)
+	cmd.Stdin = strings.NewReader(hex.EncodeToString(message) + "\n")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("signing command %s: %w: %s", c.argv[0], err, strings.TrimSpace(stderr.String()))
+	}
+	signature, err := hex.DecodeString(strings.TrimSpace(string(out)))
+	if err != nil {
+		return nil, fmt.Errorf("signing command %s: invalid signature: %w", c.argv[0], err)
+	}
+	return signature, nil
+}
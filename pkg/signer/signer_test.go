@@ -0,0 +1,130 @@
+package signer
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// frozenTransaction returns a transaction frozen for two nodes, without signatures
+func frozenTransaction(t *testing.T) *hedera.TokenUpdateTransaction {
+	t.Helper()
+	tx := hedera.NewTokenUpdateTransaction().
+		SetTokenID(hedera.TokenID{Token: 99}).
+		SetTokenMetadata([]byte("ipfs://branding")).
+		SetNodeAccountIDs([]hedera.AccountID{{Account: 3}, {Account: 4}}).
+		SetTransactionID(hedera.NewTransactionIDWithValidStart(hedera.AccountID{Account: 1234}, time.Now()))
+	_, err := tx.FreezeWith(hedera.ClientForTestnet())
+	require.NoError(t, err)
+	return tx
+}
+
+// signaturesBy counts the node transactions signed by a key
+func signaturesBy(t *testing.T, tx *hedera.TokenUpdateTransaction, key hedera.PublicKey) int {
+	t.Helper()
+	signatures, err := tx.GetSignatures()
+	require.NoError(t, err)
+	n := 0
+	for _, keys := range signatures {
+		for signer := range keys {
+			if signer.StringDer() == key.StringDer() {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestSignTransaction_Key(t *testing.T) {
+	key, err := hedera.PrivateKeyGenerateEd25519()
+	require.NoError(t, err)
+	tx := frozenTransaction(t)
+
+	require.NoError(t, SignTransaction(context.Background(), NewKey(key), tx))
+	assert.Equal(t, 2, signaturesBy(t, tx, key.PublicKey()), "every node transaction is signed")
+}
+
+func TestRemote(t *testing.T) {
+	key, err := hedera.PrivateKeyGenerateEd25519()
+	require.NoError(t, err)
+	other, err := hedera.PrivateKeyGenerateEd25519()
+	require.NoError(t, err)
+
+	signWith := key
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var request struct {
+			PublicKey string `json:"public_key"`
+			Message   string `json:"message"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, key.PublicKey().StringDer(), request.PublicKey)
+		message, err := base64.StdEncoding.DecodeString(request.Message)
+		require.NoError(t, err)
+		json.NewEncoder(w).Encode(map[string]string{"signature": base64.StdEncoding.EncodeToString(signWith.Sign(message))})
+	}))
+	defer server.Close()
+
+	tx := frozenTransaction(t)
+	require.NoError(t, SignTransaction(context.Background(), NewRemote(server.URL, "secret", key.PublicKey()), tx))
+	assert.Equal(t, 2, signaturesBy(t, tx, key.PublicKey()))
+
+	// A daemon signing with another key is caught
+	signWith = other
+	err = SignTransaction(context.Background(), NewRemote(server.URL, "secret", key.PublicKey()), frozenTransaction(t))
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	_, err = NewRemote(server.URL, "wrong", key.PublicKey()).Sign(context.Background(), []byte("message"))
+	assert.ErrorContains(t, err, "401")
+}
+
+// TestHelperSigner is not a test: it is the program run by TestCommand, signing its input with the key in
+// SIGNER_TEST_KEY like a program driving a hardware wallet would
+func TestHelperSigner(t *testing.T) {
+	if os.Getenv("SIGNER_TEST_KEY") == "" {
+		return
+	}
+	key, err := hedera.PrivateKeyFromString(os.Getenv("SIGNER_TEST_KEY"))
+	if err != nil {
+		os.Exit(2)
+	}
+	input, _ := io.ReadAll(os.Stdin)
+	message, err := hex.DecodeString(strings.TrimSpace(string(input)))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "not hex")
+		os.Exit(1)
+	}
+	fmt.Println(hex.EncodeToString(key.Sign(message)))
+	os.Exit(0)
+}
+
+func TestCommand(t *testing.T) {
+	key, err := hedera.PrivateKeyGenerateEd25519()
+	require.NoError(t, err)
+	t.Setenv("SIGNER_TEST_KEY", key.String())
+	argv := []string{os.Args[0], "-test.run=^TestHelperSigner$"}
+
+	tx := frozenTransaction(t)
+	require.NoError(t, SignTransaction(context.Background(), NewCommand(argv, key.PublicKey()), tx))
+	assert.Equal(t, 2, signaturesBy(t, tx, key.PublicKey()))
+
+	t.Setenv("SIGNER_TEST_KEY", "not a key")
+	_, err = NewCommand(argv, key.PublicKey()).Sign(context.Background(), []byte("message"))
+	assert.ErrorContains(t, err, "exit status 2")
+}
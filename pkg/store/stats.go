@@ -0,0 +1,195 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+)
+
+// ZoneRun is a row of the zone_runs table: the outcome of the domains of a zone in an ingest run
+type ZoneRun struct {
+	WorkflowID  string      `json:"workflow_id"`
+	RunID       string      `json:"run_id"`
+	Zone        domain.Zone `json:"zone"`
+	Outcome     string      `json:"outcome"` // Outcome of the run
+	FinishedAt  time.Time   `json:"finished_at"`
+	Minted      int         `json:"minted"`
+	Burned      int         `json:"burned"`
+	Skipped     int         `json:"skipped"`
+	Failed      int         `json:"failed"`
+	FeesTinybar int64       `json:"fees_tinybar"`
+}
+
+// RecordZoneRun records the outcome of a zone in a run, replacing the one recorded before for the same run
+func (s *Store) RecordZoneRun(ctx context.Context, r ZoneRun) error {
+	_, err := s.db.ExecContext(ctx, `
+INSERT INTO zone_runs (workflow_id, run_id, zone, outcome, finished_at, minted, burned, skipped, failed, fees_tinybar)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (workflow_id, run_id, zone) DO UPDATE SET
+	outcome = EXCLUDED.outcome,
+	finished_at = EXCLUDED.finished_at,
+	minted = EXCLUDED.minted,
+	burned = EXCLUDED.burned,
+	skipped = EXCLUDED.skipped,
+	failed = EXCLUDED.failed,
+	fees_tinybar = EXCLUDED.fees_tinybar`,
+		r.WorkflowID, r.RunID, r.Zone, r.Outcome, r.FinishedAt.UTC(), r.Minted, r.Burned, r.Skipped, r.Failed, r.FeesTinybar)
+	if err != nil {
+		return fmt.Errorf("failed to record run %s of .%s: %w", r.WorkflowID, r.Zone, err)
+	}
+	return nil
+}
+
+// StatsQuery selects the period and zone of aggregate statistics. Zero fields do not filter.
+type StatsQuery struct {
+	Zone  domain.Zone
+	Since time.Time // Inclusive
+	Until time.Time // Exclusive
+}
+
+// conditions returns the conditions of the query on the zone and time columns of a table
+func (q StatsQuery) conditions(timeColumn string) *conditions {
+	c := &conditions{}
+	if q.Zone != "" {
+		c.add("zone = ?", q.Zone)
+	}
+	if !q.Since.IsZero() {
+		c.add(timeColumn+" >= ?", q.Since.UTC())
+	}
+	if !q.Until.IsZero() {
+		c.add(timeColumn+" < ?", q.Until.UTC())
+	}
+	return c
+}
+
+// DailyMints counts the domains of a zone minted on a day (UTC)
+type DailyMints struct {
+	Day    time.Time   `json:"day"`
+	Zone   domain.Zone `json:"zone"`
+	Minted int64       `json:"minted"`
+}
+
+// MintsPerDay counts the domains minted per day and zone, by day and zone. Domains minted again after their
+// burn count on the day of their last mint.
+func (s *Store) MintsPerDay(ctx context.Context, q StatsQuery) ([]DailyMints, error) {
+	where, args := q.conditions("minted_at").where()
+	rows, err := s.db.QueryContext(ctx, `
+SELECT date_trunc('day', minted_at AT TIME ZONE 'UTC') AS day, zone, COUNT(*)
+FROM domains`+where+`
+GROUP BY day, zone ORDER BY day, zone`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count mints: %w", err)
+	}
+	defer rows.Close()
+
+	var days []DailyMints
+	for rows.Next() {
+		var d DailyMints
+		if err := rows.Scan(&d.Day, &d.Zone, &d.Minted); err != nil {
+			return nil, fmt.Errorf("failed to count mints: %w", err)
+		}
+		d.Day = time.Date(d.Day.Year(), d.Day.Month(), d.Day.Day(), 0, 0, 0, 0, time.UTC)
+		days = append(days, d)
+	}
+	return days, rows.Err()
+}
+
+// ZoneFailures counts the domains of a zone processed by the runs of a period, and those that failed
+type ZoneFailures struct {
+	Zone      domain.Zone `json:"zone"`
+	Runs      int64       `json:"runs"`
+	Processed int64       `json:"processed"` // Minted, burned and failed, skipped domains excluded
+	Failed    int64       `json:"failed"`
+	Rate      float64     `json:"failure_rate"` // Failed of processed, zero if none was processed
+}
+
+// FailureRates returns the failure rate of every zone in the runs finished in the period, by zone
+func (s *Store) FailureRates(ctx context.Context, q StatsQuery) ([]ZoneFailures, error) {
+	where, args := q.conditions("finished_at").where()
+	rows, err := s.db.QueryContext(ctx, `
+SELECT zone, COUNT(*), COALESCE(SUM(minted + burned + failed), 0), COALESCE(SUM(failed), 0)
+FROM zone_runs`+where+`
+GROUP BY zone ORDER BY zone`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count failures: %w", err)
+	}
+	defer rows.Close()
+
+	var zones []ZoneFailures
+	for rows.Next() {
+		var z ZoneFailures
+		if err := rows.Scan(&z.Zone, &z.Runs, &z.Processed, &z.Failed); err != nil {
+			return nil, fmt.Errorf("failed to count failures: %w", err)
+		}
+		if z.Processed > 0 {
+			z.Rate = float64(z.Failed) / float64(z.Processed)
+		}
+		zones = append(zones, z)
+	}
+	return zones, rows.Err()
+}
+
+// DailyFees sums the fees of the mints and burns of a zone in the runs finished on a day (UTC)
+type DailyFees struct {
+	Day         time.Time   `json:"day"`
+	Zone        domain.Zone `json:"zone"`
+	FeesTinybar int64       `json:"fees_tinybar"`
+}
+
+// FeesPerDay sums the fees of the runs finished per day and zone, by day and zone
+func (s *Store) FeesPerDay(ctx context.Context, q StatsQuery) ([]DailyFees, error) {
+	where, args := q.conditions("finished_at").where()
+	rows, err := s.db.QueryContext(ctx, `
+SELECT date_trunc('day', finished_at AT TIME ZONE 'UTC') AS day, zone, SUM(fees_tinybar)
+FROM zone_runs`+where+`
+GROUP BY day, zone ORDER BY day, zone`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum fees: %w", err)
+	}
+	defer rows.Close()
+
+	var days []DailyFees
+	for rows.Next() {
+		var d DailyFees
+		if err := rows.Scan(&d.Day, &d.Zone, &d.FeesTinybar); err != nil {
+			return nil, fmt.Errorf("failed to sum fees: %w", err)
+		}
+		d.Day = time.Date(d.Day.Year(), d.Day.Month(), d.Day.Day(), 0, 0, 0, 0, time.UTC)
+		days = append(days, d)
+	}
+	return days, rows.Err()
+}
+
+// RegistrarCount counts the domains minted for a registrar
+type RegistrarCount struct {
+	Registrar string `json:"registrar"`
+	Minted    int64  `json:"minted"`
+}
+
+// TopRegistrars returns the registrars with the most domains minted in the period, at most limit of them.
+// Domains of unknown registrars are not counted.
+func (s *Store) TopRegistrars(ctx context.Context, q StatsQuery, limit int) ([]RegistrarCount, error) {
+	c := q.conditions("minted_at")
+	c.add("registrar <> ''")
+	where, args := c.where()
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+SELECT registrar, COUNT(*) AS minted
+FROM domains%s
+GROUP BY registrar ORDER BY minted DESC, registrar LIMIT %d`, where, limit), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count registrars: %w", err)
+	}
+	defer rows.Close()
+
+	var registrars []RegistrarCount
+	for rows.Next() {
+		var r RegistrarCount
+		if err := rows.Scan(&r.Registrar, &r.Minted); err != nil {
+			return nil, fmt.Errorf("failed to count registrars: %w", err)
+		}
+		registrars = append(registrars, r)
+	}
+	return registrars, rows.Err()
+}
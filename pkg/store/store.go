@@ -0,0 +1,398 @@
+// Package store is the relational registry store (REGISTRY_STORE_DSN): the domains table records the outcome
+// of every mint and burn, so the ledger can be queried without scraping the mirror node.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+)
+
+// Status of a domain in the domains table
+const (
+	StatusMinted = "minted"
+	StatusBurned = "burned"
+)
+
+// ErrNotFound is returned for domains that were never minted
+var ErrNotFound = errors.New("domain not found in store")
+
+// ErrNetworkMismatch is returned by ClaimNetwork for stores recording the ledger of another network
+var ErrNetworkMismatch = errors.New("registry store belongs to another network")
+
+// Domain is a row of the domains table: the NFT of a domain and its current status
+type Domain struct {
+	Name            string      `json:"name"`
+	Zone            domain.Zone `json:"zone"`
+	TokenID         string      `json:"token_id"`
+	Serial          int64       `json:"serial"`
+	MintTransaction string      `json:"mint_transaction"`
+	BurnTransaction string      `json:"burn_transaction,omitempty"`
+	Status          string      `json:"status"`
+	Registrar       string      `json:"registrar,omitempty"`   // Sponsoring registrar of the registration, if known
+	FeeTinybar      int64       `json:"fee_tinybar,omitempty"` // Fee of the mint
+	MintedAt        time.Time   `json:"minted_at"`             // Consensus time of the mint
+	BurnedAt        *time.Time  `json:"burned_at,omitempty"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+}
+
+// schema creates the tables of the store, it can be applied to an existing store
+const schema = `
+CREATE TABLE IF NOT EXISTS domains (
+	name             TEXT PRIMARY KEY,
+	zone             TEXT NOT NULL,
+	token_id         TEXT NOT NULL,
+	serial           BIGINT NOT NULL,
+	mint_transaction TEXT NOT NULL,
+	burn_transaction TEXT NOT NULL DEFAULT '',
+	status           TEXT NOT NULL,
+	minted_at        TIMESTAMPTZ NOT NULL,
+	burned_at        TIMESTAMPTZ,
+	updated_at       TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS domains_zone_minted_at ON domains (zone, minted_at, name);
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS registrar TEXT NOT NULL DEFAULT '';
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS fee_tinybar BIGINT NOT NULL DEFAULT 0;
+CREATE TABLE IF NOT EXISTS zone_runs (
+	workflow_id  TEXT NOT NULL,
+	run_id       TEXT NOT NULL,
+	zone         TEXT NOT NULL,
+	outcome      TEXT NOT NULL,
+	finished_at  TIMESTAMPTZ NOT NULL,
+	minted       INTEGER NOT NULL,
+	burned       INTEGER NOT NULL,
+	skipped      INTEGER NOT NULL,
+	failed       INTEGER NOT NULL,
+	fees_tinybar BIGINT NOT NULL,
+	PRIMARY KEY (workflow_id, run_id, zone)
+);
+CREATE INDEX IF NOT EXISTS zone_runs_finished_at ON zone_runs (finished_at);
+CREATE TABLE IF NOT EXISTS ledger_settings (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+`
+
+// Store is the relational registry store
+type Store struct {
+	db     *sql.DB
+	schema string // Schema of the tables, the default schema of the connection if empty
+}
+
+// Open connects to the PostgreSQL database of the given DSN (e.g. "postgres://sdl@db.internal/sdl")
+// and creates the tables that do not exist yet
+func Open(ctx context.Context, dsn string) (*Store, error) {
+	return OpenSchema(ctx, dsn, "")
+}
+
+// OpenSchema opens the store with its tables in a schema of the database, created if it does not exist yet,
+// so the ledgers of several registries can share a database. Open uses the default schema.
+func OpenSchema(ctx context.Context, dsn, schema string) (*Store, error) {
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open registry store: %w", err)
+	}
+	if schema != "" {
+		connConfig.RuntimeParams["search_path"] = pgx.Identifier{schema}.Sanitize()
+	}
+	db := stdlib.OpenDB(*connConfig)
+	s := &Store{db: db, schema: schema}
+	if err := s.Migrate(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// New returns a store on an open database, whose tables must exist (see Migrate)
+func New(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Migrate creates the schema and tables of the store that do not exist yet
+func (s *Store) Migrate(ctx context.Context) error {
+	if s.schema != "" {
+		if _, err := s.db.ExecContext(ctx, "CREATE SCHEMA IF NOT EXISTS "+pgx.Identifier{s.schema}.Sanitize()); err != nil {
+			return fmt.Errorf("failed to create registry store schema %s: %w", s.schema, err)
+		}
+	}
+	if _, err := s.db.ExecContext(ctx, schema); err != nil {
+		return fmt.Errorf("failed to create registry store tables: %w", err)
+	}
+	return nil
+}
+
+// ClaimNetwork records the Hedera network of the ledger in a store that records none yet, and refuses a store
+// recording another one with an error wrapping ErrNetworkMismatch, so the domains of one network are never
+// mixed with those of another
+func (s *Store) ClaimNetwork(ctx context.Context, network string) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO ledger_settings (key, value) VALUES ('network', $1) ON CONFLICT (key) DO NOTHING`, network)
+	if err != nil {
+		return fmt.Errorf("failed to record the network of the registry store: %w", err)
+	}
+	var recorded string
+	if err := s.db.QueryRowContext(ctx, `SELECT value FROM ledger_settings WHERE key = 'network'`).Scan(&recorded); err != nil {
+		return fmt.Errorf("failed to read the network of the registry store: %w", err)
+	}
+	if recorded != network {
+		return fmt.Errorf("%w: it records the ledger of %s, not of %s", ErrNetworkMismatch, recorded, network)
+	}
+	return nil
+}
+
+// Close closes the database of the store
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// RecordMint records the mint of a domain. A domain minted again after its burn replaces its burned NFT.
+func (s *Store) RecordMint(ctx context.Context, d Domain) error {
+	_, err := s.db.ExecContext(ctx, `
+INSERT INTO domains (name, zone, token_id, serial, mint_transaction, status, registrar, fee_tinybar, minted_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (name) DO UPDATE SET
+	zone = EXCLUDED.zone,
+	token_id = EXCLUDED.token_id,
+	serial = EXCLUDED.serial,
+	mint_transaction = EXCLUDED.mint_transaction,
+	burn_transaction = '',
+	status = EXCLUDED.status,
+	registrar = EXCLUDED.registrar,
+	fee_tinybar = EXCLUDED.fee_tinybar,
+	minted_at = EXCLUDED.minted_at,
+	burned_at = NULL,
+	updated_at = EXCLUDED.updated_at`,
+		d.Name, d.Zone, d.TokenID, d.Serial, d.MintTransaction, StatusMinted, d.Registrar, d.FeeTinybar, d.MintedAt.UTC(),
+		time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to record mint of %s: %w", d.Name, err)
+	}
+	return nil
+}
+
+// RecordBurn records the burn of the NFT of a domain, ErrNotFound if its mint was not recorded
+func (s *Store) RecordBurn(ctx context.Context, name, transactionID string, burnedAt time.Time) error {
+	result, err := s.db.ExecContext(ctx, `
+UPDATE domains SET status = $2, burn_transaction = $3, burned_at = $4, updated_at = $5 WHERE name = $1`,
+		name, StatusBurned, transactionID, burnedAt.UTC(), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to record burn of %s: %w", name, err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%s: %w", name, ErrNotFound)
+	}
+	return nil
+}
+
+// RecordMigration records that the NFT of a minted domain was minted again into another collection, e.g. when
+// the collection of its zone is migrated. ErrNotFound if the domain has no minted NFT recorded.
+func (s *Store) RecordMigration(ctx context.Context, name, tokenID string, serial int64, transactionID string) error {
+	result, err := s.db.ExecContext(ctx, `
+UPDATE domains SET token_id = $2, serial = $3, mint_transaction = $4, updated_at = $5 WHERE name = $1 AND status = $6`,
+		name, tokenID, serial, transactionID, time.Now().UTC(), StatusMinted)
+	if err != nil {
+		return fmt.Errorf("failed to record migration of %s: %w", name, err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%s: %w", name, ErrNotFound)
+	}
+	return nil
+}
+
+// Domain returns the row of a domain, ErrNotFound if it was never minted
+func (s *Store) Domain(ctx context.Context, name string) (Domain, error) {
+	var d Domain
+	var burnedAt sql.NullTime
+	err := s.db.QueryRowContext(ctx, `
+SELECT name, zone, token_id, serial, mint_transaction, burn_transaction, status, registrar, fee_tinybar, minted_at,
+	burned_at, updated_at
+FROM domains WHERE name = $1`, name).Scan(
+		&d.Name, &d.Zone, &d.TokenID, &d.Serial, &d.MintTransaction, &d.BurnTransaction, &d.Status,
+		&d.Registrar, &d.FeeTinybar, &d.MintedAt, &burnedAt, &d.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Domain{}, fmt.Errorf("%s: %w", name, ErrNotFound)
+	}
+	if err != nil {
+		return Domain{}, fmt.Errorf("failed to look up %s: %w", name, err)
+	}
+	if burnedAt.Valid {
+		d.BurnedAt = &burnedAt.Time
+	}
+	return d, nil
+}
+
+// CountDomains returns the number of domains in the store, burned ones included
+func (s *Store) CountDomains(ctx context.Context) (int64, error) {
+	var count int64
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM domains").Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count domains: %w", err)
+	}
+	return count, nil
+}
+
+// ErrInvalidCursor is returned for cursors that were not returned by ListDomains
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the position of a page of ListDomains: the last domain of the previous page, in the order of
+// the domains by mint time and name
+type Cursor struct {
+	MintedAt time.Time
+	Name     string
+}
+
+// String encodes the cursor as an opaque token
+func (c Cursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.MintedAt.UTC().Format(time.RFC3339Nano) + "/" + c.Name))
+}
+
+// ParseCursor decodes a cursor encoded by Cursor.String
+func ParseCursor(s string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	mintedAt, name, found := strings.Cut(string(data), "/")
+	if !found || name == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, mintedAt)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return Cursor{MintedAt: t, Name: name}, nil
+}
+
+// Query selects the domains listed by ListDomains. Zero fields do not filter.
+type Query struct {
+	Zone        domain.Zone
+	Status      string    // StatusMinted or StatusBurned
+	MintedAfter time.Time // Only domains minted after this time
+	After       *Cursor   // Only domains after the last one of the previous page
+	Limit       int       // Domains of the page, all of them if zero
+}
+
+// conditions builds a WHERE clause from conditions with "?" placeholders
+type conditions struct {
+	list []string
+	args []any
+}
+
+// add appends a condition, numbering its placeholders after those of the previous ones
+func (c *conditions) add(condition string, values ...any) {
+	for _, v := range values {
+		c.args = append(c.args, v)
+		condition = strings.Replace(condition, "?", fmt.Sprintf("$%d", len(c.args)), 1)
+	}
+	c.list = append(c.list, condition)
+}
+
+// where returns the WHERE clause of the conditions and its arguments, empty without conditions
+func (c *conditions) where() (string, []any) {
+	if len(c.list) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(c.list, " AND "), c.args
+}
+
+// where returns the WHERE clause of the query and its arguments
+func (q Query) where() (string, []any) {
+	var c conditions
+	if q.Zone != "" {
+		c.add("zone = ?", q.Zone)
+	}
+	if q.Status != "" {
+		c.add("status = ?", q.Status)
+	}
+	if !q.MintedAfter.IsZero() {
+		c.add("minted_at > ?", q.MintedAfter.UTC())
+	}
+	if q.After != nil {
+		c.add("(minted_at, name) > (?, ?)", q.After.MintedAt.UTC(), q.After.Name)
+	}
+	return c.where()
+}
+
+// ListDomains returns the domains selected by the query by mint time and name, and the cursor of the next page,
+// nil on the last page
+func (s *Store) ListDomains(ctx context.Context, q Query) ([]Domain, *Cursor, error) {
+	where, args := q.where()
+	query := `
+SELECT name, zone, token_id, serial, mint_transaction, burn_transaction, status, registrar, fee_tinybar, minted_at,
+	burned_at, updated_at
+FROM domains` + where + " ORDER BY minted_at, name"
+	if q.Limit > 0 {
+		// One more row tells whether there is a next page
+		query += fmt.Sprintf(" LIMIT %d", q.Limit+1)
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list domains: %w", err)
+	}
+	defer rows.Close()
+
+	var domains []Domain
+	for rows.Next() {
+		var d Domain
+		var burnedAt sql.NullTime
+		if err := rows.Scan(&d.Name, &d.Zone, &d.TokenID, &d.Serial, &d.MintTransaction, &d.BurnTransaction, &d.Status,
+			&d.Registrar, &d.FeeTinybar, &d.MintedAt, &burnedAt, &d.UpdatedAt); err != nil {
+			return nil, nil, fmt.Errorf("failed to list domains: %w", err)
+		}
+		if burnedAt.Valid {
+			d.BurnedAt = &burnedAt.Time
+		}
+		domains = append(domains, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to list domains: %w", err)
+	}
+
+	if q.Limit > 0 && len(domains) > q.Limit {
+		domains = domains[:q.Limit]
+		last := domains[len(domains)-1]
+		return domains, &Cursor{MintedAt: last.MintedAt, Name: last.Name}, nil
+	}
+	return domains, nil, nil
+}
+
+// ZoneCount counts the domains of a zone in the store
+type ZoneCount struct {
+	Minted int64 `json:"minted"` // Domains whose NFT is held, burned ones excluded
+	Burned int64 `json:"burned"`
+}
+
+// ZoneCounts counts the domains of every zone in the store
+func (s *Store) ZoneCounts(ctx context.Context) (map[domain.Zone]ZoneCount, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT zone, status, COUNT(*) FROM domains GROUP BY zone, status")
+	if err != nil {
+		return nil, fmt.Errorf("failed to count domains: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[domain.Zone]ZoneCount)
+	for rows.Next() {
+		var zone domain.Zone
+		var status string
+		var n int64
+		if err := rows.Scan(&zone, &status, &n); err != nil {
+			return nil, fmt.Errorf("failed to count domains: %w", err)
+		}
+		count := counts[zone]
+		switch status {
+		case StatusMinted:
+			count.Minted += n
+		case StatusBurned:
+			count.Burned += n
+		}
+		counts[zone] = count
+	}
+	return counts, rows.Err()
+}
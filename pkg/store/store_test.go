@@ -0,0 +1,255 @@
+package store
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openTestStore opens the PostgreSQL database of STORE_TEST_DSN with an empty domains table,
+// skipping the test when it is not set
+func openTestStore(t *testing.T) *Store {
+	dsn := os.Getenv("STORE_TEST_DSN")
+	if dsn == "" {
+		t.Skip("STORE_TEST_DSN is not set")
+	}
+	ctx := context.Background()
+	s, err := Open(ctx, dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	_, err = s.db.ExecContext(ctx, "TRUNCATE domains")
+	require.NoError(t, err)
+	return s
+}
+
+func TestStore_MintAndBurn(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	mintedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	_, err := s.Domain(ctx, "example.build")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, s.RecordBurn(ctx, "example.build", "0.0.2@1.2", mintedAt), ErrNotFound)
+
+	require.NoError(t, s.RecordMint(ctx, Domain{
+		Name: "example.build", Zone: "build", TokenID: "0.0.100", Serial: 7,
+		MintTransaction: "0.0.2@1700000000.000000001", MintedAt: mintedAt,
+	}))
+	d, err := s.Domain(ctx, "example.build")
+	require.NoError(t, err)
+	assert.Equal(t, StatusMinted, d.Status)
+	assert.Equal(t, int64(7), d.Serial)
+	assert.True(t, d.MintedAt.Equal(mintedAt))
+	assert.Nil(t, d.BurnedAt)
+
+	burnedAt := mintedAt.Add(time.Hour)
+	require.NoError(t, s.RecordBurn(ctx, "example.build", "0.0.2@1700003600.000000001", burnedAt))
+	d, err = s.Domain(ctx, "example.build")
+	require.NoError(t, err)
+	assert.Equal(t, StatusBurned, d.Status)
+	require.NotNil(t, d.BurnedAt)
+	assert.True(t, d.BurnedAt.Equal(burnedAt))
+
+	// Minted again after its burn
+	require.NoError(t, s.RecordMint(ctx, Domain{
+		Name: "example.build", Zone: "build", TokenID: "0.0.100", Serial: 9,
+		MintTransaction: "0.0.2@1700007200.000000001", MintedAt: burnedAt.Add(time.Hour),
+	}))
+	d, err = s.Domain(ctx, "example.build")
+	require.NoError(t, err)
+	assert.Equal(t, StatusMinted, d.Status)
+	assert.Equal(t, int64(9), d.Serial)
+	assert.Empty(t, d.BurnTransaction)
+	assert.Nil(t, d.BurnedAt)
+
+	count, err := s.CountDomains(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestStore_Schema(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	tenant, err := OpenSchema(ctx, os.Getenv("STORE_TEST_DSN"), "tenant-test")
+	require.NoError(t, err)
+	t.Cleanup(func() { tenant.Close() })
+	_, err = tenant.db.ExecContext(ctx, "TRUNCATE domains")
+	require.NoError(t, err)
+
+	require.NoError(t, tenant.RecordMint(ctx, Domain{
+		Name: "example.build", Zone: "build", TokenID: "0.0.100", Serial: 1,
+		MintTransaction: "0.0.2@1700000000.000000001", MintedAt: time.Now(),
+	}))
+	_, err = tenant.Domain(ctx, "example.build")
+	require.NoError(t, err)
+	// The default schema does not see the domains of the tenant
+	_, err = s.Domain(ctx, "example.build")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestStore_ClaimNetwork(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	_, err := s.db.ExecContext(ctx, "DELETE FROM ledger_settings")
+	require.NoError(t, err)
+
+	require.NoError(t, s.ClaimNetwork(ctx, "testnet"))
+	require.NoError(t, s.ClaimNetwork(ctx, "testnet"))
+	assert.ErrorIs(t, s.ClaimNetwork(ctx, "mainnet"), ErrNetworkMismatch)
+}
+
+func TestStore_RecordMigration(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	mintedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.ErrorIs(t, s.RecordMigration(ctx, "example.build", "0.0.200", 1, "0.0.2@1.2"), ErrNotFound)
+	require.NoError(t, s.RecordMint(ctx, Domain{
+		Name: "example.build", Zone: "build", TokenID: "0.0.100", Serial: 7, Registrar: "1234",
+		MintTransaction: "0.0.2@1700000000.000000001", MintedAt: mintedAt,
+	}))
+
+	require.NoError(t, s.RecordMigration(ctx, "example.build", "0.0.200", 3, "0.0.2@1700003600.000000001"))
+	d, err := s.Domain(ctx, "example.build")
+	require.NoError(t, err)
+	assert.Equal(t, "0.0.200", d.TokenID)
+	assert.Equal(t, int64(3), d.Serial)
+	assert.Equal(t, "0.0.2@1700003600.000000001", d.MintTransaction)
+	assert.Equal(t, "1234", d.Registrar, "the registration is kept")
+	assert.True(t, d.MintedAt.Equal(mintedAt))
+
+	// Burned domains are not migrated
+	require.NoError(t, s.RecordBurn(ctx, "example.build", "0.0.2@1700007200.000000001", mintedAt.Add(2*time.Hour)))
+	assert.ErrorIs(t, s.RecordMigration(ctx, "example.build", "0.0.300", 1, "0.0.2@1700010800.000000001"), ErrNotFound)
+}
+
+func TestStore_ListDomains(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, name := range []string{"a.build", "b.build", "c.build", "d.app"} {
+		require.NoError(t, s.RecordMint(ctx, Domain{
+			Name: name, Zone: domain.Zone(name[2:]), TokenID: "0.0.100", Serial: int64(i + 1),
+			MintTransaction: "0.0.2@1.1", MintedAt: start.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+	require.NoError(t, s.RecordBurn(ctx, "b.build", "0.0.2@2.2", start.Add(time.Hour)))
+
+	page, next, err := s.ListDomains(ctx, Query{Zone: "build", Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, "a.build", page[0].Name)
+	require.NotNil(t, next)
+	page, next, err = s.ListDomains(ctx, Query{Zone: "build", Limit: 2, After: next})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, "c.build", page[0].Name)
+	assert.Nil(t, next)
+
+	page, _, err = s.ListDomains(ctx, Query{Zone: "build", Status: StatusBurned})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, "b.build", page[0].Name)
+
+	page, _, err = s.ListDomains(ctx, Query{MintedAfter: start.Add(time.Minute)})
+	require.NoError(t, err)
+	assert.Len(t, page, 2)
+
+	counts, err := s.ZoneCounts(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ZoneCount{Minted: 2, Burned: 1}, counts["build"])
+	assert.Equal(t, ZoneCount{Minted: 1}, counts["app"])
+}
+
+func TestCursor_RoundTrip(t *testing.T) {
+	c := Cursor{MintedAt: time.Date(2026, 3, 1, 12, 0, 0, 123456789, time.UTC), Name: "example.build"}
+	parsed, err := ParseCursor(c.String())
+	require.NoError(t, err)
+	assert.True(t, parsed.MintedAt.Equal(c.MintedAt))
+	assert.Equal(t, c.Name, parsed.Name)
+
+	for _, invalid := range []string{"", "!!", "bm90LWEtY3Vyc29y", Cursor{Name: ""}.String()} {
+		_, err := ParseCursor(invalid)
+		assert.ErrorIs(t, err, ErrInvalidCursor, invalid)
+	}
+}
+
+func TestQuery_Where(t *testing.T) {
+	where, args := Query{}.where()
+	assert.Empty(t, where)
+	assert.Empty(t, args)
+
+	after := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	where, args = Query{
+		Zone:        "build",
+		Status:      StatusMinted,
+		MintedAfter: after,
+		After:       &Cursor{MintedAt: after.Add(time.Hour), Name: "example.build"},
+	}.where()
+	assert.Equal(t, " WHERE zone = $1 AND status = $2 AND minted_at > $3 AND (minted_at, name) > ($4, $5)", where)
+	assert.Equal(t, []any{domain.Zone("build"), StatusMinted, after, after.Add(time.Hour), "example.build"}, args)
+}
+
+func TestStatsQuery_Conditions(t *testing.T) {
+	where, args := StatsQuery{}.conditions("minted_at").where()
+	assert.Empty(t, where)
+	assert.Empty(t, args)
+
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := StatsQuery{Zone: "build", Since: since, Until: since.AddDate(0, 1, 0)}.conditions("finished_at")
+	c.add("registrar <> ''")
+	where, args = c.where()
+	assert.Equal(t, " WHERE zone = $1 AND finished_at >= $2 AND finished_at < $3 AND registrar <> ''", where)
+	assert.Equal(t, []any{domain.Zone("build"), since, since.AddDate(0, 1, 0)}, args)
+}
+
+func TestStore_Stats(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	_, err := s.db.ExecContext(ctx, "TRUNCATE zone_runs")
+	require.NoError(t, err)
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i, name := range []string{"a.build", "b.build", "c.build", "d.app"} {
+		registrar := "registrar-1"
+		if i == 2 {
+			registrar = "registrar-2"
+		}
+		require.NoError(t, s.RecordMint(ctx, Domain{
+			Name: name, Zone: domain.Zone(name[2:]), TokenID: "0.0.100", Serial: int64(i + 1), Registrar: registrar,
+			MintTransaction: "0.0.2@1.1", MintedAt: day.Add(time.Duration(i*10) * time.Hour),
+		}))
+	}
+	require.NoError(t, s.RecordZoneRun(ctx, ZoneRun{
+		WorkflowID: "ingest-1", RunID: "r1", Zone: "build", Outcome: "completed", FinishedAt: day.Add(time.Hour),
+		Minted: 3, Failed: 1, FeesTinybar: 1500,
+	}))
+	require.NoError(t, s.RecordZoneRun(ctx, ZoneRun{
+		WorkflowID: "ingest-2", RunID: "r1", Zone: "build", Outcome: "completed", FinishedAt: day.Add(30 * time.Hour),
+		Minted: 0, Skipped: 3, FeesTinybar: 0,
+	}))
+
+	mints, err := s.MintsPerDay(ctx, StatsQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, []DailyMints{
+		{Day: day, Zone: "build", Minted: 2},
+		{Day: day.AddDate(0, 0, 1), Zone: "app", Minted: 1},
+		{Day: day.AddDate(0, 0, 1), Zone: "build", Minted: 1},
+	}, mints)
+
+	failures, err := s.FailureRates(ctx, StatsQuery{Zone: "build"})
+	require.NoError(t, err)
+	assert.Equal(t, []ZoneFailures{{Zone: "build", Runs: 2, Processed: 4, Failed: 1, Rate: 0.25}}, failures)
+
+	fees, err := s.FeesPerDay(ctx, StatsQuery{Until: day.AddDate(0, 0, 1)})
+	require.NoError(t, err)
+	assert.Equal(t, []DailyFees{{Day: day, Zone: "build", FeesTinybar: 1500}}, fees)
+
+	registrars, err := s.TopRegistrars(ctx, StatsQuery{}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []RegistrarCount{{Registrar: "registrar-1", Minted: 3}}, registrars)
+}
@@ -0,0 +1,161 @@
+// Package zonepolicy reads the processing policy of zones: what is done with each action of a registry event,
+// how the domains of a zone are batched and paced, and where their metadata documents are stored. Policies let
+// a sandbox zone behave differently from a production one on the same workers.
+package zonepolicy
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+)
+
+// What is done with the events of an action
+const (
+	ActionMint   = "mint"   // The domain is minted
+	ActionBurn   = "burn"   // The NFT of the domain is burned
+	ActionIgnore = "ignore" // The event is counted and passed over
+)
+
+// EventCreate is the action of registrations, assumed for events without an action, e.g. domain list imports
+const EventCreate = "create"
+
+// What is done when a domain is registered again while the collection holds an NFT of an earlier registration
+const (
+	ReregistrationReuse  = "reuse"  // The live NFT is kept, a burned one is replaced by a new serial linked to it
+	ReregistrationLink   = "link"   // A new serial linked to the earlier NFT, which is burned first when live
+	ReregistrationRemint = "remint" // A new serial without link, the earlier NFT is burned first when live
+)
+
+// StoreNone disables the upload of metadata documents for a zone, whatever METADATA_STORE is
+const StoreNone = "none"
+
+// Policy is the processing policy of a zone. The zero policy mints every event, as zones did before policies.
+type Policy struct {
+	// Actions maps event actions (create, delete, ...) to mint, burn or ignore. Actions not listed are ignored,
+	// every event is minted when no action is listed.
+	Actions map[string]string `yaml:"actions" json:"actions,omitempty"`
+	// BatchSize is the number of domains whose events are anchored under one Merkle root in anchored zones,
+	// all domains of a run if zero
+	BatchSize int `yaml:"batch_size" json:"batch_size,omitempty"`
+	// TransactionsPerSecond caps the mints and burns of the zone, on top of HEDERA_TPS, unlimited if zero
+	TransactionsPerSecond float64 `yaml:"tps" json:"tps,omitempty"`
+	// MetadataStore replaces METADATA_STORE for the zone: arweave, ipfs, hcs or none
+	MetadataStore string `yaml:"metadata_store" json:"metadata_store,omitempty"`
+	// Reregistration is what is done when a domain is registered again: reuse, link or remint, reuse if empty
+	Reregistration string `yaml:"reregistration" json:"reregistration,omitempty"`
+}
+
+// File holds the policies of the zones: the defaults apply to zones not listed, and fill in the settings a
+// listed zone leaves unset
+type File struct {
+	Defaults Policy            `yaml:"defaults"`
+	Zones    map[string]Policy `yaml:"zones"`
+}
+
+// Load reads and validates a YAML policy file
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file File
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := file.Defaults.normalize(); err != nil {
+		return nil, fmt.Errorf("%s: defaults: %w", path, err)
+	}
+	zones := make(map[string]Policy, len(file.Zones))
+	for zone, policy := range file.Zones {
+		if err := policy.normalize(); err != nil {
+			return nil, fmt.Errorf("%s: zone %s: %w", path, zone, err)
+		}
+		zones[strings.TrimPrefix(strings.ToLower(zone), ".")] = policy
+	}
+	file.Zones = zones
+	return &file, nil
+}
+
+// For returns the policy of a zone, with the defaults filled in
+func (f *File) For(zone string) Policy {
+	policy := f.Zones[strings.TrimPrefix(strings.ToLower(zone), ".")]
+	if len(policy.Actions) == 0 {
+		policy.Actions = f.Defaults.Actions
+	}
+	if policy.BatchSize == 0 {
+		policy.BatchSize = f.Defaults.BatchSize
+	}
+	if policy.TransactionsPerSecond == 0 {
+		policy.TransactionsPerSecond = f.Defaults.TransactionsPerSecond
+	}
+	if policy.MetadataStore == "" {
+		policy.MetadataStore = f.Defaults.MetadataStore
+	}
+	if policy.Reregistration == "" {
+		policy.Reregistration = f.Defaults.Reregistration
+	}
+	return policy
+}
+
+// Action returns what is done with an event of the given action
+func (p Policy) Action(eventAction string) string {
+	if len(p.Actions) == 0 {
+		return ActionMint
+	}
+	eventAction = strings.ToLower(strings.TrimSpace(eventAction))
+	if eventAction == "" {
+		eventAction = EventCreate
+	}
+	if action, ok := p.Actions[eventAction]; ok {
+		return action
+	}
+	return ActionIgnore
+}
+
+// ReregistrationMode returns what is done when a domain is registered again
+func (p Policy) ReregistrationMode() string {
+	if p.Reregistration == "" {
+		return ReregistrationReuse
+	}
+	return p.Reregistration
+}
+
+// normalize lowercases the policy and checks its settings
+func (p *Policy) normalize() error {
+	actions := make(map[string]string, len(p.Actions))
+	for event, action := range p.Actions {
+		event, action = strings.ToLower(strings.TrimSpace(event)), strings.ToLower(strings.TrimSpace(action))
+		switch action {
+		case ActionMint, ActionBurn, ActionIgnore:
+		default:
+			return fmt.Errorf("actions: %s: unknown action %q (expected mint, burn or ignore)", event, action)
+		}
+		actions[event] = action
+	}
+	if len(actions) > 0 {
+		p.Actions = actions
+	}
+	if p.BatchSize < 0 {
+		return fmt.Errorf("batch_size: must not be negative")
+	}
+	if p.TransactionsPerSecond < 0 {
+		return fmt.Errorf("tps: must not be negative")
+	}
+	p.MetadataStore = strings.ToLower(strings.TrimSpace(p.MetadataStore))
+	switch p.MetadataStore {
+	case "", StoreNone, config.MetadataStoreArweave, config.MetadataStoreIPFS, config.MetadataStoreHCS:
+	default:
+		return fmt.Errorf("metadata_store: unknown store %q (expected arweave, ipfs, hcs or none)", p.MetadataStore)
+	}
+	p.Reregistration = strings.ToLower(strings.TrimSpace(p.Reregistration))
+	switch p.Reregistration {
+	case "", ReregistrationReuse, ReregistrationLink, ReregistrationRemint:
+	default:
+		return fmt.Errorf("reregistration: unknown policy %q (expected reuse, link or remint)", p.Reregistration)
+	}
+	return nil
+}
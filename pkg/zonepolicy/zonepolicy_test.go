@@ -0,0 +1,75 @@
+package zonepolicy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePolicyFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "zones.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	file, err := Load(writePolicyFile(t, `
+defaults:
+  actions:
+    create: mint
+    Delete: BURN
+  tps: 5
+  metadata_store: arweave
+  reregistration: Link
+zones:
+  .Sandbox:
+    actions:
+      create: mint
+    batch_size: 10
+    metadata_store: none
+    reregistration: remint
+`))
+	require.NoError(t, err)
+
+	sandbox := file.For("sandbox")
+	assert.Equal(t, ActionMint, sandbox.Action("create"))
+	assert.Equal(t, ActionIgnore, sandbox.Action("delete"))
+	assert.Equal(t, 10, sandbox.BatchSize)
+	assert.Equal(t, 5.0, sandbox.TransactionsPerSecond)
+	assert.Equal(t, StoreNone, sandbox.MetadataStore)
+	assert.Equal(t, ReregistrationRemint, sandbox.ReregistrationMode())
+
+	build := file.For(".build")
+	assert.Equal(t, ActionBurn, build.Action("delete"))
+	assert.Equal(t, ActionMint, build.Action(""), "events without an action are registrations")
+	assert.Equal(t, ActionIgnore, build.Action("transfer"))
+	assert.Equal(t, 0, build.BatchSize)
+	assert.Equal(t, "arweave", build.MetadataStore)
+	assert.Equal(t, ReregistrationLink, build.ReregistrationMode())
+}
+
+func TestPolicy_Zero(t *testing.T) {
+	var policy Policy
+	assert.Equal(t, ActionMint, policy.Action("create"))
+	assert.Equal(t, ActionMint, policy.Action("delete"))
+	assert.Equal(t, ReregistrationReuse, policy.ReregistrationMode())
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"action":     "defaults:\n  actions:\n    delete: destroy\n",
+		"batch":      "zones:\n  build:\n    batch_size: -1\n",
+		"tps":        "zones:\n  build:\n    tps: -2\n",
+		"store":      "zones:\n  build:\n    metadata_store: s3\n",
+		"reregister": "zones:\n  build:\n    reregistration: unfreeze\n",
+		"malformed":  "zones: [",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(writePolicyFile(t, content))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package temporal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/entityid"
+)
+
+// Kinds of external identities mapped to Hedera accounts
+const (
+	AccountKindRegistrar  = "registrar"  // Registrar, by IANA ID
+	AccountKindRegistrant = "registrant" // Registrant, by contact handle
+)
+
+// ErrTypeNoAccountMapping is the application error type returned when an external identity is not mapped to an account
+const ErrTypeNoAccountMapping = "NoAccountMapping"
+
+// ErrNoAccountMapping is the cause of the errors returned when an external identity is not mapped to an account
+var ErrNoAccountMapping = errors.New("no account mapping")
+
+// AccountMapping maps an external identity to the Hedera account receiving its NFTs
+type AccountMapping struct {
+	Kind       string    `json:"kind"`        // AccountKindRegistrar or AccountKindRegistrant
+	ExternalID string    `json:"external_id"` // IANA ID of a registrar, handle of a registrant
+	AccountID  string    `json:"account_id"`
+	Note       string    `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// AccountRegistry holds the account mappings, keyed by kind and external ID
+type AccountRegistry struct {
+	Mappings    map[string]AccountMapping `json:"mappings"` // "kind:external_id" -> mapping
+	LastUpdated time.Time                 `json:"last_updated"`
+	Network     string                    `json:"network,omitempty"` // Hedera network of the accounts, see checkRegistryNetwork
+}
+
+// accountMappingKey returns the registry key of an external identity, validating its kind
+func accountMappingKey(kind, externalID string) (string, error) {
+	switch kind {
+	case AccountKindRegistrar, AccountKindRegistrant:
+	default:
+		return "", fmt.Errorf("unknown identity kind %q (expected %s or %s)", kind, AccountKindRegistrar, AccountKindRegistrant)
+	}
+	externalID = strings.TrimSpace(externalID)
+	if externalID == "" {
+		return "", errors.New("empty external ID")
+	}
+	return kind + ":" + externalID, nil
+}
+
+// PutAccountMappingActivity creates or updates the mapping of an external identity to an account
+func (a *Activities) PutAccountMappingActivity(ctx context.Context, mapping AccountMapping) (AccountMapping, error) {
+	key, err := accountMappingKey(mapping.Kind, mapping.ExternalID)
+	if err != nil {
+		return AccountMapping{}, err
+	}
+	account, err := entityid.ParseAccount(mapping.AccountID, a.network())
+	if err != nil {
+		return AccountMapping{}, fmt.Errorf("invalid account: %w", err)
+	}
+	mapping.ExternalID = strings.TrimSpace(mapping.ExternalID)
+	mapping.AccountID = account.String()
+
+	a.accountsMu.Lock()
+	defer a.accountsMu.Unlock()
+	registry, err := a.loadAccountRegistry()
+	if err != nil {
+		return AccountMapping{}, fmt.Errorf("failed to load account registry: %w", err)
+	}
+	now := time.Now().UTC()
+	mapping.CreatedAt, mapping.UpdatedAt = now, now
+	if existing, ok := registry.Mappings[key]; ok {
+		mapping.CreatedAt = existing.CreatedAt
+	}
+	registry.Mappings[key] = mapping
+	if err := a.saveAccountRegistry(registry); err != nil {
+		return AccountMapping{}, err
+	}
+	fmt.Printf("Mapped %s %s to %s\n", mapping.Kind, mapping.ExternalID, a.displayID(mapping.AccountID))
+	return mapping, nil
+}
+
+// GetAccountMappingActivity returns the mapping of an external identity, or ErrNoAccountMapping
+func (a *Activities) GetAccountMappingActivity(ctx context.Context, kind, externalID string) (AccountMapping, error) {
+	key, err := accountMappingKey(kind, externalID)
+	if err != nil {
+		return AccountMapping{}, err
+	}
+	registry, err := a.loadAccountRegistry()
+	if err != nil {
+		return AccountMapping{}, fmt.Errorf("failed to load account registry: %w", err)
+	}
+	mapping, ok := registry.Mappings[key]
+	if !ok {
+		// Retrying does not help until somebody adds the mapping
+		return AccountMapping{}, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("%s %s is not mapped to an account", kind, strings.TrimSpace(externalID)), ErrTypeNoAccountMapping, ErrNoAccountMapping)
+	}
+	return mapping, nil
+}
+
+// ListAccountMappingsActivity returns the mappings of a kind, or all mappings if kind is empty, sorted by kind and external ID
+func (a *Activities) ListAccountMappingsActivity(ctx context.Context, kind string) ([]AccountMapping, error) {
+	registry, err := a.loadAccountRegistry()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load account registry: %w", err)
+	}
+	mappings := make([]AccountMapping, 0, len(registry.Mappings))
+	for _, mapping := range registry.Mappings {
+		if kind == "" || mapping.Kind == kind {
+			mappings = append(mappings, mapping)
+		}
+	}
+	sort.Slice(mappings, func(i, j int) bool {
+		if mappings[i].Kind != mappings[j].Kind {
+			return mappings[i].Kind < mappings[j].Kind
+		}
+		return mappings[i].ExternalID < mappings[j].ExternalID
+	})
+	return mappings, nil
+}
+
+// DeleteAccountMappingActivity removes the mapping of an external identity, or returns ErrNoAccountMapping
+func (a *Activities) DeleteAccountMappingActivity(ctx context.Context, kind, externalID string) error {
+	key, err := accountMappingKey(kind, externalID)
+	if err != nil {
+		return err
+	}
+	a.accountsMu.Lock()
+	defer a.accountsMu.Unlock()
+	registry, err := a.loadAccountRegistry()
+	if err != nil {
+		return fmt.Errorf("failed to load account registry: %w", err)
+	}
+	if _, ok := registry.Mappings[key]; !ok {
+		return fmt.Errorf("%s %s: %w", kind, strings.TrimSpace(externalID), ErrNoAccountMapping)
+	}
+	delete(registry.Mappings, key)
+	return a.saveAccountRegistry(registry)
+}
+
+// loadAccountRegistry loads the account registry from its JSON file, which may not exist yet
+func (a *Activities) loadAccountRegistry() (*AccountRegistry, error) {
+	data, err := a.readRegistryFile(a.Config.Registry.AccountFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &AccountRegistry{
+				Mappings:    make(map[string]AccountMapping),
+				LastUpdated: time.Now(),
+			}, nil
+		}
+		return nil, err
+	}
+
+	var registry AccountRegistry
+	if err := json.Unmarshal(data, &registry); err != nil {
+		return nil, err
+	}
+	if err := a.checkRegistryNetwork(a.Config.Registry.AccountFile, registry.Network); err != nil {
+		return nil, err
+	}
+	if registry.Mappings == nil {
+		registry.Mappings = make(map[string]AccountMapping)
+	}
+	return &registry, nil
+}
+
+// saveAccountRegistry saves the account registry to its JSON file
+func (a *Activities) saveAccountRegistry(registry *AccountRegistry) error {
+	registry.LastUpdated = time.Now()
+	registry.Network = a.network()
+	data, err := json.MarshalIndent(registry, "", "  ")
+	if err != nil {
+		return err
+	}
+	return a.writeRegistryFile(a.Config.Registry.AccountFile, data)
+}
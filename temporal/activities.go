@@ -5,24 +5,32 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"net/url"
 	"os"
-	"strconv"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/atrest"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/compressed"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
 	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
-)
-
-const (
-	RegistryIDPrefix = "APEX" // Prefix for our Registry e.g. "APEX" would result in zones named "APEX-<ZonePrefix>"
-	ZonePrefix       = "ZONE" // Suffix for zone collections e.g. "ZONE" would result in "<RegistryIDPrefix>-<ZonePrefix>.<zone>"
-
-	// Hedera Mirror Node API endpoints (testnet)
-	MirrorNodeBaseURL = "https://testnet.mirrornode.hedera.com/api/v1"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/entityid"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/eventhash"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/eventschema"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/eventsig"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/fingerprint"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/metadata"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/naming"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/store"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"golang.org/x/time/rate"
 )
 
 // Mirror Node API response structures
@@ -31,6 +39,9 @@ type MirrorNodeNFT struct {
 	SerialNumber int64  `json:"serial_number"`
 	Metadata     string `json:"metadata"`
 	CreatedAt    string `json:"created_timestamp"`
+	ModifiedAt   string `json:"modified_timestamp"` // Last transfer, burn or wipe
+	AccountID    string `json:"account_id"`         // Current owner
+	Deleted      bool   `json:"deleted"`
 }
 
 type MirrorNodeNFTsResponse struct {
@@ -41,41 +52,156 @@ type MirrorNodeNFTsResponse struct {
 }
 
 // Activities struct holds our activity implementations.
-type Activities struct{}
+type Activities struct {
+	Config *config.Config
+
+	txLimiter     *rate.Limiter // throttles Hedera transactions
+	mirrorLimiter *rate.Limiter // throttles mirror node requests
+	ledgerMu      sync.Mutex    // serializes read-modify-write cycles of the ingest ledger, see lockIngestLedger
+	zonesMu       sync.Mutex    // serializes read-modify-write cycles of the zone registry, see lockZoneRegistry
+	accountsMu    sync.Mutex    // serializes read-modify-write cycles of the account registry
+	topicsMu      sync.Mutex    // serializes read-modify-write cycles of the topic registry, see lockTopicRegistry
+	offsetsMu     sync.Mutex    // serializes read-modify-write cycles of the topic offsets
+
+	storeMu sync.Mutex   // guards the lazy connection to the registry store
+	store   *store.Store // registry store of REGISTRY_STORE_DSN, see domainStore
+
+	keyMu         sync.Mutex  // guards the lazy decryption of the registry key
+	encryptionKey *atrest.Key // key the registry files are encrypted with, see registryKey
+}
 
-// tokenIDFromString parses "shard.realm.num" (optionally with checksum suffix) into a hedera.TokenID.
-func tokenIDFromString(s string) (hedera.TokenID, error) {
-	base := strings.TrimSpace(s)
-	if base == "" {
-		return hedera.TokenID{}, fmt.Errorf("empty token id")
+// NewActivities returns Activities configured with the given Config
+func NewActivities(cfg *config.Config) *Activities {
+	return &Activities{
+		Config:        cfg,
+		txLimiter:     newLimiter(cfg.Limits.TransactionsPerSecond),
+		mirrorLimiter: newLimiter(cfg.Limits.MirrorRequestsPerSecond),
 	}
-	base = strings.SplitN(base, "-", 2)[0]
-	parts := strings.Split(base, ".")
-	if len(parts) != 3 {
-		return hedera.TokenID{}, fmt.Errorf("invalid token id format: %s", s)
+}
+
+// newLimiter returns a limiter allowing perSecond events per second, or an unlimited one if perSecond is 0
+func newLimiter(perSecond float64) *rate.Limiter {
+	if perSecond <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
 	}
-	shard, err := strconv.ParseUint(parts[0], 10, 64)
+	return rate.NewLimiter(rate.Limit(perSecond), 1)
+}
+
+// network returns the configured Hedera network
+func (a *Activities) network() string {
+	return a.Config.Hedera.Network
+}
+
+// newHederaClient creates a Hedera client for the configured network
+func (a *Activities) newHederaClient() (*hedera.Client, error) {
+	client, err := hedera.ClientForName(a.network())
 	if err != nil {
-		return hedera.TokenID{}, fmt.Errorf("invalid shard: %w", err)
+		return nil, fmt.Errorf("invalid HEDERA_NETWORK: %w", err)
+	}
+	if a.Config.Mirror.GRPCAddress != "" {
+		client.SetMirrorNetwork([]string{a.Config.Mirror.GRPCAddress})
 	}
-	realm, err := strconv.ParseUint(parts[1], 10, 64)
+	return client, nil
+}
+
+// operatorCredentials parses the configured Hedera operator account and key
+func (a *Activities) operatorCredentials() (hedera.AccountID, hedera.PrivateKey, error) {
+	if err := a.Config.RequireOperator(); err != nil {
+		return hedera.AccountID{}, hedera.PrivateKey{}, err
+	}
+	accountID, err := entityid.ParseAccount(a.Config.Hedera.AccountID, a.network())
 	if err != nil {
-		return hedera.TokenID{}, fmt.Errorf("invalid realm: %w", err)
+		return hedera.AccountID{}, hedera.PrivateKey{}, fmt.Errorf("invalid HEDERA_ACCOUNT_ID: %w", err)
 	}
-	num, err := strconv.ParseUint(parts[2], 10, 64)
+	privateKey, err := hedera.PrivateKeyFromString(a.Config.Hedera.PrivateKey)
 	if err != nil {
-		return hedera.TokenID{}, fmt.Errorf("invalid token number: %w", err)
+		return hedera.AccountID{}, hedera.PrivateKey{}, fmt.Errorf("invalid HEDERA_PRIVATE_KEY: %w", err)
 	}
-	return hedera.TokenID{
-		Shard: shard,
-		Realm: realm,
-		Token: num,
-	}, nil
+	return accountID, privateKey, nil
+}
+
+// operatorAccount parses the configured operator account and the public key of the operator key, which is
+// all the workers know of the operator when signing offline
+func (a *Activities) operatorAccount() (hedera.AccountID, hedera.PublicKey, error) {
+	if a.Config.Hedera.Signing != config.SigningOffline {
+		accountID, privateKey, err := a.operatorCredentials()
+		return accountID, privateKey.PublicKey(), err
+	}
+	accountID, err := entityid.ParseAccount(a.Config.Hedera.AccountID, a.network())
+	if err != nil {
+		return hedera.AccountID{}, hedera.PublicKey{}, fmt.Errorf("invalid HEDERA_ACCOUNT_ID: %w", err)
+	}
+	if a.Config.Hedera.PublicKey == "" {
+		privateKey, err := hedera.PrivateKeyFromString(a.Config.Hedera.PrivateKey)
+		if err != nil {
+			return hedera.AccountID{}, hedera.PublicKey{}, fmt.Errorf("invalid HEDERA_PRIVATE_KEY: %w", err)
+		}
+		return accountID, privateKey.PublicKey(), nil
+	}
+	publicKey, err := hedera.PublicKeyFromString(a.Config.Hedera.PublicKey)
+	if err != nil {
+		return hedera.AccountID{}, hedera.PublicKey{}, fmt.Errorf("invalid HEDERA_PUBLIC_KEY: %w", err)
+	}
+	return accountID, publicKey, nil
+}
+
+// signingClient returns the client of the mints and collection creations: with the operator set, or without
+// when signing offline, the transactions then being frozen for the operator account and exported
+func (a *Activities) signingClient() (*hedera.Client, error) {
+	client, err := a.newHederaClient()
+	if err != nil || a.Config.Hedera.Signing == config.SigningOffline {
+		return client, err
+	}
+	accountID, privateKey, err := a.operatorCredentials()
+	if err != nil {
+		return nil, err
+	}
+	client.SetOperator(accountID, privateKey)
+	return client, nil
+}
+
+// tokenIDFromString parses "shard.realm.num" (optionally with checksum suffix) into a hedera.TokenID.
+// A checksum, when present, is validated against the configured network.
+func (a *Activities) tokenIDFromString(s string) (hedera.TokenID, error) {
+	return entityid.ParseToken(s, a.network())
+}
+
+// displayID renders an entity ID with the checksum of the configured network for console output
+func (a *Activities) displayID(id string) string {
+	return entityid.WithChecksum(id, a.network())
+}
+
+// heartbeat records an activity heartbeat when called from within an activity
+func heartbeat(ctx context.Context, details ...interface{}) {
+	if activity.IsActivity(ctx) {
+		activity.RecordHeartbeat(ctx, details...)
+	}
+}
+
+// workerStopping reports whether the worker running this activity has begun a graceful shutdown
+func workerStopping(ctx context.Context) bool {
+	if !activity.IsActivity(ctx) {
+		return false
+	}
+	select {
+	case <-activity.GetWorkerStopChannel(ctx):
+		return true
+	default:
+		return false
+	}
+}
+
+// errWorkerShutdown is returned by activities that refuse to start a Hedera transaction
+// because the worker is draining. It is retryable so another worker picks the task up.
+func errWorkerShutdown(operation string) error {
+	return temporal.NewApplicationError(
+		fmt.Sprintf("worker is shutting down, not submitting %s", operation), ErrTypeWorkerShutdown)
 }
 
 // ReadFileActivity reads a file from disk and returns its lines.
+// Files ending in .gz, .zst or .bz2 are decompressed while they are read.
 func (a *Activities) ReadFileActivity(ctx context.Context, filePath string) ([]string, error) {
-	file, err := os.Open(filePath)
+	file, err := compressed.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
@@ -89,135 +215,433 @@ func (a *Activities) ReadFileActivity(ctx context.Context, filePath string) ([]s
 	return lines, scanner.Err()
 }
 
-// ParseAndFilterEventsActivity filters for domain "create" events.
+// ParseAndFilterEventsActivity filters for domain events, the events of other objects are left out.
+// When event signatures are verified, events with an invalid signature are refused, and so are
+// unsigned events in strict mode. Events are decoded by the schema version they declare; events of an
+// unknown version are refused or quarantined, as set by EVENT_UNKNOWN_SCHEMA. Events whose domain is
+// not a name of their zone, and events that cannot be parsed, are quarantined.
 func (a *Activities) ParseAndFilterEventsActivity(ctx context.Context, lines []string) ([]MintingInfo, error) {
 	var mintingInfos []MintingInfo
 
-	for _, line := range lines {
-		if !strings.HasPrefix(line, `"registry-event"`) {
+	parser, err := a.eventParser()
+	if err != nil {
+		return nil, err
+	}
+	for i, line := range lines {
+		info, quarantined, err := parser.parse(line, i+1)
+		switch {
+		case errors.Is(err, errNotAnEvent):
 			continue // Skip malformed lines
+		case errors.Is(err, errNotADomainEvent):
+			continue // Host and contact events are parsed by their own activities
+		case quarantined != nil:
+			if err := a.quarantineEvent(ctx, *quarantined); err != nil {
+				return nil, err
+			}
+			fmt.Printf("Quarantined event on line %d: %s\n", i+1, quarantined.Reason)
+		case err != nil:
+			fmt.Printf("Refusing event on line %d: %v\n", i+1, err)
+		default:
+			mintingInfos = append(mintingInfos, info)
 		}
+	}
+	return mintingInfos, nil
+}
+
+// errNotAnEvent is returned by eventParser.parse for lines of the log that are not registry events
+var errNotAnEvent = errors.New("not a registry event")
 
-		// The log lines are not perfectly formatted JSON, so we fix them
-		jsonString := "{" + line + "}"
+// eventParser turns the lines of registry event logs into minting infos
+type eventParser struct {
+	a              *Activities
+	keys           *eventsig.KeySet // Registry public keys, nil unless event signatures are verified
+	fingerprintKey *fingerprint.Key // Key of registrant fingerprints, nil unless they are recorded
+}
+
+// eventParser returns the parser of the events of the current configuration
+func (a *Activities) eventParser() (*eventParser, error) {
+	keys, err := a.eventKeys()
+	if err != nil {
+		return nil, err
+	}
+	fingerprintKey, err := a.fingerprintKey()
+	if err != nil {
+		return nil, err
+	}
+	return &eventParser{a: a, keys: keys, fingerprintKey: fingerprintKey}, nil
+}
+
+// errNotADomainEvent is returned by eventParser.parse for events about other objects than domains, e.g. contacts
+var errNotADomainEvent = errors.New("not a domain event")
+
+// decodedEvent is a registry event decoded from its line, with its signature verified
+type decodedEvent struct {
+	eventschema.Event
+	JSON      string // The line as a JSON object
+	EventHash string
+	SignedBy  string
+}
+
+// parse parses the line of a domain event. An event that is refused is returned as an error, one that has to be
+// quarantined as the quarantined event.
+func (p *eventParser) parse(line string, lineNumber int) (MintingInfo, *QuarantinedEvent, error) {
+	decoded, quarantined, err := p.decode(line, lineNumber)
+	if err != nil || quarantined != nil {
+		return MintingInfo{}, quarantined, err
+	}
+	// Host and contact events are recorded by their own activities, they are not registrations
+	if decoded.ObjectType() != eventschema.TypeDomain {
+		return MintingInfo{}, nil, errNotADomainEvent
+	}
+	event := decoded.Event
+	quarantine := func(cause string, err error) (MintingInfo, *QuarantinedEvent, error) {
+		return MintingInfo{}, &QuarantinedEvent{
+			Line:          line,
+			LineNumber:    lineNumber,
+			EventHash:     decoded.EventHash,
+			SchemaVersion: event.Version,
+			Cause:         cause,
+			Reason:        err.Error(),
+			Domain:        event.DomainName,
+			Zone:          event.Zone,
+		}, nil
+	}
+
+	// The domain is minted into the collection of the zone the event names, so they have to agree. A name that is
+	// not valid cannot be checked against its zone.
+	name, err := domain.NewDomainName(event.DomainName)
+	if err != nil {
+		return quarantine(QuarantineParseError, fmt.Errorf("invalid domain %q: %w", event.DomainName, err))
+	}
+	if err := name.CheckZone(event.Zone); err != nil {
+		return quarantine(QuarantineZoneMismatch, err)
+	}
+	zone, err := domain.NewZone(event.Zone)
+	if err != nil {
+		return quarantine(QuarantineZoneMismatch, err)
+	}
 
-		var event RegistryEvent
-		if err := json.Unmarshal([]byte(jsonString), &event); err != nil {
-			// Log error but continue processing other lines
-			fmt.Printf("could not unmarshal line: %s, error: %v\n", jsonString, err)
-			continue
+	// What is done with the action of the event is up to the policy of its zone
+	info := MintingInfo{
+		DomainName:       event.DomainName,
+		RegistrationTime: time.Now(),
+		RegistrarID:      event.RegistrarID,
+		Zone:             zone,
+		FullEventJSON:    decoded.JSON,
+		LineNumber:       lineNumber,
+		SignedBy:         decoded.SignedBy,
+		EventHash:        decoded.EventHash,
+		Action:           event.Action,
+	}
+	if p.a.Config.Events.Nameservers != config.NameserversOff {
+		info.Nameservers = event.Nameservers
+	}
+	// Only the fingerprint of the registrant leaves the activity
+	if p.fingerprintKey != nil && event.Registrant != "" {
+		info.RegistrantFingerprint = p.fingerprintKey.Sum(FingerprintRegistrant, event.Registrant)
+	}
+	// Registrations resembling a watched brand are flagged in the report of the run, not refused
+	if info.Typosquat = p.a.typosquatMatch(info); info.Typosquat != nil {
+		fmt.Printf("Registration of %s on line %d resembles %s (%.2f)\n", info.DomainName, lineNumber, info.Typosquat.Brand, info.Typosquat.Score)
+	}
+	// Labels that look generated are tagged for abuse analysis, in the metadata of the NFT and the report
+	if info.Generated = p.a.generatedLabel(info); info.Generated != nil {
+		fmt.Printf("Label of %s on line %d looks generated (%.2f)\n", info.DomainName, lineNumber, info.Generated.Score)
+	}
+	return info, nil, nil
+}
+
+// decode decodes the line of an event of any object and verifies its signature. An event that is refused is
+// returned as an error, one that has to be quarantined as the quarantined event.
+func (p *eventParser) decode(line string, lineNumber int) (decodedEvent, *QuarantinedEvent, error) {
+	if !strings.HasPrefix(line, `"registry-event"`) {
+		return decodedEvent{}, nil, errNotAnEvent
+	}
+	// Events that could not be hashed are quarantined under the hash of their line
+	quarantine := func(cause, eventHash string, event eventschema.Event, err error) (decodedEvent, *QuarantinedEvent, error) {
+		if eventHash == "" {
+			eventHash = lineHash(line)
 		}
+		return decodedEvent{}, &QuarantinedEvent{
+			Line:          line,
+			LineNumber:    lineNumber,
+			EventHash:     eventHash,
+			SchemaVersion: event.Version,
+			Cause:         cause,
+			Reason:        err.Error(),
+			Domain:        event.DomainName,
+			Zone:          event.Zone,
+		}, nil
+	}
+
+	// The log lines are not perfectly formatted JSON, so we fix them
+	jsonString := "{" + line + "}"
 
-		// We only care about 'create' events for minting
-		// TODO: add explicit filtering when event schema provides an action/type field.
-		info := MintingInfo{
-			DomainName:       event.Event.DomainName,
-			RegistrationTime: time.Now(),
-			RegistrarID:      event.Event.RegistrarID,
-			Zone:             event.Event.Zone,
-			FullEventJSON:    jsonString,
+	// The event itself is decoded by its schema version below
+	var envelope struct {
+		Signature string `json:"sig"`
+	}
+	if err := json.Unmarshal([]byte(jsonString), &envelope); err != nil {
+		return quarantine(QuarantineParseError, "", eventschema.Event{}, fmt.Errorf("could not unmarshal line: %w", err))
+	}
+
+	signedBy, err := p.a.verifyEventSignature(p.keys, jsonString, envelope.Signature)
+	if err != nil {
+		return decodedEvent{}, nil, err
+	}
+
+	// Link the NFT to the exact source event
+	payload, err := registryEventPayload(jsonString)
+	if err != nil {
+		return quarantine(QuarantineParseError, "", eventschema.Event{}, fmt.Errorf("could not extract event: %w", err))
+	}
+	eventHash, err := eventhash.Sum(payload)
+	if err != nil {
+		return quarantine(QuarantineParseError, "", eventschema.Event{}, fmt.Errorf("could not hash event: %w", err))
+	}
+
+	event, err := eventSchemas.Decode(payload)
+	switch {
+	case errors.Is(err, eventschema.ErrUnknownVersion):
+		if p.a.Config.Events.UnknownSchema == config.UnknownSchemaQuarantine {
+			return quarantine(QuarantineUnknownSchema, eventHash, event, err)
 		}
-		mintingInfos = append(mintingInfos, info)
+		return decodedEvent{}, nil, err
+	case err != nil:
+		return quarantine(QuarantineParseError, eventHash, event, err)
 	}
-	return mintingInfos, nil
+	return decodedEvent{Event: event, JSON: jsonString, EventHash: eventHash, SignedBy: signedBy}, nil, nil
 }
 
 // MintNFTActivity connects to Hedera and mints the NFT in the specified zone collection.
-func (a *Activities) MintNFTActivity(ctx context.Context, info MintingInfo, zoneCollection ZoneCollectionInfo) error {
+func (a *Activities) MintNFTActivity(ctx context.Context, info MintingInfo, zoneCollection ZoneCollectionInfo) (MintResult, error) {
 	fmt.Printf("Minting NFT for domain: %s in .%s zone collection\n", info.DomainName, info.Zone)
 
 	// --- Check if domain is already minted ---
-	fmt.Printf("Checking if domain %s is already minted in collection %s...\n", info.DomainName, zoneCollection.TokenID)
-	alreadyMinted, existingNFT, err := a.isDomainAlreadyMinted(info.DomainName, zoneCollection)
+	// Prechecked domains are only searched again when the mint is retried, the attempt before may have minted it
+	var alreadyMinted bool
+	var existingNFT MirrorNodeNFT
+	var err error
+	if !info.Prechecked || activity.GetInfo(ctx).Attempt > 1 {
+		fmt.Printf("Checking if domain %s is already minted in collection %s...\n", info.DomainName, zoneCollection.TokenID)
+		alreadyMinted, existingNFT, err = a.isDomainAlreadyMinted(ctx, info.DomainName, zoneCollection)
+	}
 	if err != nil {
 		fmt.Printf("Warning: Could not check mirror node for existing domain: %v. Proceeding with minting.\n", err)
 	} else if alreadyMinted {
 		fmt.Printf("Domain %s already minted as serial %d in collection %s (created %s). Skipping duplicate mint.\n",
 			info.DomainName, existingNFT.SerialNumber, existingNFT.TokenID, existingNFT.CreatedAt)
-		return nil // Return success since the domain is already minted
+		// Return success since the domain is already minted
+		return MintResult{
+			Domain:       info.DomainName,
+			TokenID:      existingNFT.TokenID,
+			SerialNumber: existingNFT.SerialNumber,
+			Duplicate:    true,
+		}, nil
 	}
 	fmt.Printf("No existing NFT found for domain %s, proceeding with mint.\n", info.DomainName)
 
-	// --- Load Hedera Credentials ---
-	accountID, err := hedera.AccountIDFromString(os.Getenv("HEDERA_ACCOUNT_ID"))
-	if err != nil {
-		return fmt.Errorf("invalid HEDERA_ACCOUNT_ID: %w", err)
-	}
-	privateKey, err := hedera.PrivateKeyFromString(os.Getenv("HEDERA_PRIVATE_KEY"))
+	// --- Create Hedera Client ---
+	client, err := a.signingClient()
 	if err != nil {
-		return fmt.Errorf("invalid HEDERA_PRIVATE_KEY: %w", err)
+		return MintResult{}, err
 	}
 
 	// --- Parse the zone collection token ID ---
-	tokenID, err := tokenIDFromString(zoneCollection.TokenID)
+	tokenID, err := a.tokenIDFromString(zoneCollection.TokenID)
 	if err != nil {
-		return fmt.Errorf("invalid zone collection token ID: %w", err)
+		return MintResult{}, fmt.Errorf("invalid zone collection token ID: %w", err)
 	}
 
-	// --- Create Hedera Client ---
-	client := hedera.ClientForTestnet()
-	client.SetOperator(accountID, privateKey)
-
 	// --- Prepare Metadata ---
-	// For production, upload this to IPFS/Arweave and use the CID here.
-	// For now, we'll use just the domain label since the zone is provided by the collection context
+	// The on-chain metadata is the domain label, since the zone is provided by the collection context,
+	// and the hash of the source event. The full document goes to the metadata store, if any.
 	dn, err := domain.NewDomainName(info.DomainName)
 	if err != nil {
-		return fmt.Errorf("failed to create domain name: %w", err)
+		return MintResult{}, fmt.Errorf("failed to create domain name: %w", err)
+	}
+	metadata := NFTMetadata(dn.Label(), info.EventHash)
+	fmt.Printf("Using metadata: '%s' for domain %s in .%s collection\n", metadata, info.DomainName, info.Zone)
+
+	if a.Config.Events.Nameservers == config.NameserversDNS && len(info.Nameservers) == 0 {
+		info.Nameservers = a.lookupNameservers(ctx, info.DomainName)
+	}
+	// Nothing below may publish personal data the redaction policy withholds
+	if info, err = a.redactMintingInfo(info); err != nil {
+		return MintResult{}, fmt.Errorf("failed to redact registration: %w", err)
+	}
+	if len(info.Redacted) > 0 {
+		fmt.Printf("Redacted %v of %s\n", info.Redacted, info.DomainName)
+	}
+
+	// The policy of the zone may store its documents elsewhere
+	storeName := a.Config.Metadata.Store
+	if info.MetadataStore != "" {
+		storeName = info.MetadataStore
+	}
+	store, err := a.openMetadataStore(ctx, storeName)
+	if err != nil {
+		return MintResult{}, fmt.Errorf("failed to open metadata store: %w", err)
+	}
+	var metadataURI string
+	if store != nil {
+		if metadataURI, err = a.uploadMetadataDocument(ctx, store, info); err != nil {
+			return MintResult{}, err
+		}
+		fmt.Printf("Uploaded metadata document of %s to %s\n", info.DomainName, metadataURI)
 	}
-	metadata := []byte(dn.Label())
-	fmt.Printf("Using metadata: '%s' (label only) for domain %s in .%s collection\n", dn.Label(), info.DomainName, info.Zone)
 
 	// --- Mint Transaction ---
 	mintTx := hedera.NewTokenMintTransaction().
 		SetTokenID(tokenID).
-		SetMetadata(metadata).
+		SetMetadata([]byte(metadata)).
 		SetMaxTransactionFee(hedera.NewHbar(20)) // Set a high max fee for assurance
+	if info.EventHash != "" {
+		mintTx.SetTransactionMemo(EventMemo(info.RunID, info.EventHash))
+	}
+
+	// Signing offline, the mint is submitted by SubmitSignedTransactionActivity once signed
+	if a.Config.Hedera.Signing == config.SigningOffline {
+		pending, err := a.exportTransaction(ctx, mintTx, PendingTransaction{
+			Type:        TransactionTokenMint,
+			Zone:        info.Zone.String(),
+			Domain:      info.DomainName,
+			TokenID:     zoneCollection.TokenID,
+			Summary:     fmt.Sprintf("Mint %s into collection %s with metadata %q", info.DomainName, a.displayID(zoneCollection.TokenID), metadata),
+			Mint:        &info,
+			MetadataURI: metadataURI,
+		})
+		if err != nil {
+			return MintResult{}, err
+		}
+		return MintResult{Domain: info.DomainName, TokenID: zoneCollection.TokenID, Pending: &pending}, nil
+	}
+
+	// The transaction ID is derived from the domain and event, so the network rejects the mint when an
+	// earlier attempt of the activity submitted it already
+	payer, _, err := a.operatorAccount()
+	if err != nil {
+		return MintResult{}, err
+	}
+	txID, dedup := dedupTransactionID(ctx, payer, zoneCollection.TokenID, info.DomainName, info.EventHash)
+	if dedup {
+		mintTx.SetTransactionID(txID).SetTransactionValidDuration(dedupValidDuration)
+	}
 
 	// Sign and execute
+	if err := a.txLimiter.Wait(ctx); err != nil {
+		return MintResult{}, err
+	}
+	// Once submitted, the mint must be seen through to its receipt even while the worker drains,
+	// so only refuse to start it when a shutdown is already in progress.
+	if workerStopping(ctx) {
+		return MintResult{}, errWorkerShutdown("mint for " + info.DomainName)
+	}
 	txResponse, err := mintTx.Execute(client)
+	if dedup && isStatus(err, hedera.StatusDuplicateTransaction) {
+		return a.completeSubmittedMint(ctx, client, txID, info, zoneCollection.TokenID, metadataURI, mintTx.GetTransactionMemo())
+	}
 	if err != nil {
-		return fmt.Errorf("transaction execution failed: %w", err)
+		return MintResult{}, fmt.Errorf("transaction execution failed: %w", err)
 	}
+	heartbeat(ctx, "submitted", txResponse.TransactionID.String())
 
-	// Get the receipt to confirm success
-	receipt, err := txResponse.GetReceipt(client)
+	// Get the record to confirm success, it also carries the consensus time of the mint
+	record, err := txResponse.GetRecord(client)
+	if err != nil {
+		return MintResult{}, fmt.Errorf("failed to get transaction record: %w", err)
+	}
+	return a.completeMint(ctx, info, zoneCollection.TokenID, metadataURI, record), nil
+}
+
+// completeSubmittedMint completes a mint an earlier attempt of MintNFTActivity submitted with the same
+// transaction ID, from the record of the transaction
+func (a *Activities) completeSubmittedMint(ctx context.Context, client *hedera.Client, txID hedera.TransactionID, info MintingInfo, tokenID, metadataURI, memo string) (MintResult, error) {
+	record, err := hedera.NewTransactionRecordQuery().SetTransactionID(txID).Execute(client)
 	if err != nil {
-		return fmt.Errorf("failed to get transaction receipt: %w", err)
+		return MintResult{}, fmt.Errorf("failed to get the record of the mint submitted by an earlier attempt: %w", err)
 	}
+	// Another domain could only share the transaction ID by a hash collision
+	if record.TransactionMemo != memo || len(record.Receipt.SerialNumbers) == 0 {
+		return MintResult{}, fmt.Errorf("transaction %s is not the mint of %s", txID, info.DomainName)
+	}
+	fmt.Printf("Mint of %s was submitted by an earlier attempt as %s\n", info.DomainName, txID)
+	return a.completeMint(ctx, info, tokenID, metadataURI, record), nil
+}
+
+// completeMint records the mint of a domain once it reached consensus, whether it was submitted by
+// MintNFTActivity or, signed offline, by SubmitSignedTransactionActivity
+func (a *Activities) completeMint(ctx context.Context, info MintingInfo, tokenID, metadataURI string, record hedera.TransactionRecord) MintResult {
+	receipt := record.Receipt
+	txRecord := newTransactionRecord(TransactionTokenMint, info.Zone.String(), tokenID, record)
+	txRecord.Domain = info.DomainName
+	a.saveTransactionRecord(ctx, txRecord)
 
 	fmt.Printf("Successfully minted NFT for %s in .%s collection (token ID: %s). New serial: %d\n",
-		info.DomainName, info.Zone, zoneCollection.TokenID, receipt.SerialNumbers[0])
+		info.DomainName, info.Zone, a.displayID(tokenID), receipt.SerialNumbers[0])
 
 	fmt.Printf("Domain %s is now recorded on Hedera blockchain and will be detected by mirror node queries\n", info.DomainName)
 
-	return nil
+	result := MintResult{
+		Domain:        info.DomainName,
+		TokenID:       tokenID,
+		SerialNumber:  receipt.SerialNumbers[0],
+		TransactionID: record.TransactionID.String(),
+		ConsensusAt:   record.ConsensusTimestamp,
+		MetadataURI:   metadataURI,
+		Nameservers:   info.Nameservers,
+		Registrant:    info.RegistrantFingerprint,
+		Redacted:      info.Redacted,
+		FeeTinybar:    record.TransactionFee.AsTinybar(),
+		Previous:      info.Previous,
+	}
+	a.storeMint(ctx, info, result)
+	a.publishLedgerEvent(ctx, LedgerEvent{
+		Type:          LedgerEventMint,
+		Domain:        result.Domain,
+		Zone:          info.Zone.String(),
+		TokenID:       result.TokenID,
+		SerialNumber:  result.SerialNumber,
+		TransactionID: result.TransactionID,
+		At:            result.ConsensusAt,
+	})
+	return result
 }
 
 // LookupOrCreateZoneCollectionActivity looks up an existing NFT collection for a zone,
 // or creates a new one if it doesn't exist. Uses a registry file to track collections.
-func (a *Activities) LookupOrCreateZoneCollectionActivity(ctx context.Context, zone string) (ZoneCollectionInfo, error) {
+// The zones of a run are processed in parallel: the zone registry is locked until the collection is
+// recorded, so their lookups neither drop each other's entries nor create a collection twice.
+func (a *Activities) LookupOrCreateZoneCollectionActivity(ctx context.Context, zoneName string) (ZoneCollectionInfo, error) {
+	z, err := domain.NewZone(zoneName)
+	if err != nil {
+		return ZoneCollectionInfo{}, temporal.NewNonRetryableApplicationError(err.Error(), ErrTypeInvalidZone, err)
+	}
+	zone := z.String()
 	fmt.Printf("Looking up or creating NFT collection for zone: .%s\n", zone)
 
-	// Load the zone registry
+	unlock, err := a.lockZoneRegistry()
+	if err != nil {
+		return ZoneCollectionInfo{}, err
+	}
+	defer unlock()
+
+	// Load the zone registry. A registry that cannot be loaded is never replaced by an empty one: its collections
+	// would be created again, and the registry overwritten on save.
 	registry, err := a.loadZoneRegistry()
 	if err != nil {
-		fmt.Printf("Warning: Could not load zone registry: %v. Will check for existing collections anyway.\n", err)
-		registry = &ZoneRegistry{
-			Collections: make(map[string]ZoneCollectionInfo),
-			LastUpdated: time.Now(),
-		}
+		return ZoneCollectionInfo{}, registryLoadError("zone registry", err)
 	}
 
 	// Check if we already have this zone in our registry
-	if collection, exists := registry.Collections[zone]; exists {
-		fmt.Printf("Found existing NFT collection for .%s zone in registry: %s\n", zone, collection.TokenID)
+	if collection, exists := registry.Collections[z]; exists {
+		fmt.Printf("Found existing NFT collection for .%s zone in registry: %s\n", zone, a.displayID(collection.TokenID))
 		// Validate that the token still exists on Hedera
 		if a.validateTokenExists(collection.TokenID) {
-			return collection, nil
+			return a.shardZoneCollection(ctx, collection)
 		} else {
 			fmt.Printf("Warning: Token %s for zone .%s no longer exists on Hedera. Removing from registry.\n", collection.TokenID, zone)
-			delete(registry.Collections, zone)
+			delete(registry.Collections, z)
 		}
 	}
 
@@ -225,63 +649,111 @@ func (a *Activities) LookupOrCreateZoneCollectionActivity(ctx context.Context, z
 	fmt.Printf("Searching Hedera for existing .%s zone collections...\n", zone)
 	existingCollection, found := a.searchForZoneCollection(zone)
 	if found {
-		fmt.Printf("Found existing .%s collection on Hedera: %s\n", zone, existingCollection.TokenID)
+		fmt.Printf("Found existing .%s collection on Hedera: %s\n", zone, a.displayID(existingCollection.TokenID))
 		// Add to registry for future lookups
-		registry.Collections[zone] = existingCollection
-		a.saveZoneRegistry(registry)
+		registry.Collections[z] = existingCollection
+		if err := a.saveZoneRegistry(registry); err != nil {
+			return ZoneCollectionInfo{}, fmt.Errorf("failed to record collection %s of .%s in the zone registry: %w", existingCollection.TokenID, zone, err)
+		}
 		return existingCollection, nil
 	}
 
-	// No existing collection found, create a new one
+	// No existing collection found, create a new one unless the zone is not to be ingested
+	if !a.Config.Zones.Allowed(zone) {
+		return ZoneCollectionInfo{}, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("zone .%s is not allowed by ZONE_ALLOWLIST or ZONE_DENYLIST, refusing to create its collection", zone), ErrTypeZoneNotAllowed, nil)
+	}
 	fmt.Printf("No existing collection found for .%s zone, creating new collection...\n", zone)
 	newCollection, err := a.CreateNFTCollectionActivity(ctx, zone)
 	if err != nil {
 		return ZoneCollectionInfo{}, err
 	}
+	if newCollection.Pending != nil {
+		return newCollection, nil // Registered by SubmitSignedTransactionActivity once created
+	}
 
 	// Add the new collection to the registry
-	registry.Collections[zone] = newCollection
+	registry.Collections[z] = newCollection
 	registry.LastUpdated = time.Now()
-	a.saveZoneRegistry(registry)
+	if err := a.saveZoneRegistry(registry); err != nil {
+		return ZoneCollectionInfo{}, a.collectionNotRecordedError(newCollection, err)
+	}
 
 	return newCollection, nil
 }
 
+// ListZoneCollectionsActivity returns the collections of the zone registry, sorted by zone
+func (a *Activities) ListZoneCollectionsActivity(ctx context.Context) ([]ZoneCollectionInfo, error) {
+	registry, err := a.loadZoneRegistry()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load zone registry: %w", err)
+	}
+	collections := make([]ZoneCollectionInfo, 0, len(registry.Collections))
+	for _, collection := range registry.Collections {
+		collections = append(collections, collection)
+	}
+	sort.Slice(collections, func(i, j int) bool { return collections[i].Zone < collections[j].Zone })
+	return collections, nil
+}
+
 // loadZoneRegistry loads the zone registry from a JSON file
 func (a *Activities) loadZoneRegistry() (*ZoneRegistry, error) {
-	data, err := os.ReadFile(ZoneRegistryFile)
+	data, err := a.readRegistryFile(a.Config.Registry.ZoneFile)
 	if err != nil {
 		if os.IsNotExist(err) {
+			if err := a.checkRegistryFile(a.Config.Registry.ZoneFile, nil); err != nil {
+				return nil, err
+			}
 			return &ZoneRegistry{
-				Collections: make(map[string]ZoneCollectionInfo),
+				Collections: make(map[domain.Zone]ZoneCollectionInfo),
 				LastUpdated: time.Now(),
 			}, nil
 		}
 		return nil, err
 	}
+	if err := a.checkRegistryFile(a.Config.Registry.ZoneFile, data); err != nil {
+		return nil, err
+	}
 
 	var registry ZoneRegistry
 	err = json.Unmarshal(data, &registry)
 	if err != nil {
 		return nil, err
 	}
+	if err := a.checkRegistryNetwork(a.Config.Registry.ZoneFile, registry.Network); err != nil {
+		return nil, err
+	}
 
 	return &registry, nil
 }
 
-// saveZoneRegistry saves the zone registry to a JSON file
+// saveZoneRegistry saves the zone registry to a JSON file. The file is replaced in one rename, so readers
+// see the registry before or after the update, e.g. of the collection of a migrated zone, and never in between.
+// It is signed before it is replaced, the signature verifying both.
 func (a *Activities) saveZoneRegistry(registry *ZoneRegistry) error {
+	registry.Network = a.network()
 	data, err := json.MarshalIndent(registry, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(ZoneRegistryFile, data, 0644)
+	sealed, err := a.sealRegistryFile(data)
+	if err != nil {
+		return err
+	}
+	path := a.Config.Registry.ZoneFile
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := a.signRegistryFile(path, data); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, sealed)
 }
 
 // validateTokenExists checks if a token ID still exists on Hedera
 func (a *Activities) validateTokenExists(tokenID string) bool {
 	// For now, just validate the format. In production, you could query Hedera mirror node
-	_, err := tokenIDFromString(tokenID)
+	_, err := a.tokenIDFromString(tokenID)
 	return err == nil
 }
 
@@ -299,7 +771,7 @@ func (a *Activities) searchForZoneCollection(zone string) (ZoneCollectionInfo, b
 
 // isDomainAlreadyMinted checks if a domain has already been minted by querying Hedera mirror nodes
 // Uses smart pagination with early termination to avoid loading all NFTs
-func (a *Activities) isDomainAlreadyMinted(domainName string, zoneCollection ZoneCollectionInfo) (bool, MirrorNodeNFT, error) {
+func (a *Activities) isDomainAlreadyMinted(ctx context.Context, domainName string, zoneCollection ZoneCollectionInfo) (bool, MirrorNodeNFT, error) {
 	// Parse the domain name for comparison
 	dn, err := domain.NewDomainName(domainName)
 	if err != nil {
@@ -308,12 +780,18 @@ func (a *Activities) isDomainAlreadyMinted(domainName string, zoneCollection Zon
 	expectedLabel := dn.Label()
 	fmt.Printf("Checking for existing domain label: '%s' in collection %s\n", expectedLabel, zoneCollection.TokenID)
 
-	// Use smart search with early termination
-	foundNFT, found, err := a.searchForDomainInCollection(zoneCollection.TokenID, expectedLabel)
+	// Use smart search with early termination, in every shard of the zone
+	foundNFT, found, err := a.searchForDomainInShards(ctx, zoneCollection.TokenIDs(), expectedLabel)
 	if err != nil {
 		return false, MirrorNodeNFT{}, fmt.Errorf("failed to search collection: %w", err)
 	}
 
+	// The most recent NFT of the domain is found first, a burned one was deleted and may be minted again
+	if found && foundNFT.Deleted {
+		fmt.Printf("NFT of domain %s was burned: Serial %d in collection %s\n",
+			domainName, foundNFT.SerialNumber, foundNFT.TokenID)
+		return false, MirrorNodeNFT{}, nil
+	}
 	if found {
 		fmt.Printf("Found existing NFT for domain %s: Serial %d in collection %s\n",
 			domainName, foundNFT.SerialNumber, foundNFT.TokenID)
@@ -325,20 +803,28 @@ func (a *Activities) isDomainAlreadyMinted(domainName string, zoneCollection Zon
 }
 
 // searchForDomainInCollection performs an efficient search with early termination
-func (a *Activities) searchForDomainInCollection(tokenID, expectedLabel string) (MirrorNodeNFT, bool, error) {
+func (a *Activities) searchForDomainInCollection(ctx context.Context, tokenID, expectedLabel string) (MirrorNodeNFT, bool, error) {
 	const maxPagesToCheck = 50 // Limit search scope to prevent excessive API calls
 	const pageSize = 100       // Reasonable page size
 
 	client := &http.Client{Timeout: 30 * time.Second}
 
 	// Start with newest NFTs first (more likely to find recent duplicates)
-	nextURL := fmt.Sprintf("%s/tokens/%s/nfts?limit=%d&order=desc", MirrorNodeBaseURL, tokenID, pageSize)
+	nextURL := fmt.Sprintf("%s/tokens/%s/nfts?limit=%d&order=desc", a.Config.Mirror.BaseURL, tokenID, pageSize)
 	pagesChecked := 0
 
 	for nextURL != "" && pagesChecked < maxPagesToCheck {
 		fmt.Printf("Searching page %d of collection %s...\n", pagesChecked+1, tokenID)
+		heartbeat(ctx, "searching", pagesChecked+1)
 
-		resp, err := client.Get(nextURL)
+		if err := a.mirrorLimiter.Wait(ctx); err != nil {
+			return MirrorNodeNFT{}, false, err
+		}
+		req, err := a.newMirrorRequest(ctx, nextURL)
+		if err != nil {
+			return MirrorNodeNFT{}, false, err
+		}
+		resp, err := client.Do(req)
 		if err != nil {
 			return MirrorNodeNFT{}, false, fmt.Errorf("failed to query mirror node: %w", err)
 		}
@@ -376,7 +862,7 @@ func (a *Activities) searchForDomainInCollection(tokenID, expectedLabel string)
 			fmt.Printf("  NFT %d: Serial %d, Metadata: '%s'\n", i+1, nft.SerialNumber, decodedMetadata)
 
 			// Early termination: found a match!
-			if decodedMetadata == expectedLabel || actualMetadata == expectedLabel {
+			if label, _ := ParseNFTMetadata(decodedMetadata); label == expectedLabel || actualMetadata == expectedLabel {
 				fmt.Printf("✓ Found match! Label '%s' exists as serial %d\n", expectedLabel, nft.SerialNumber)
 				return nft, true, nil
 			}
@@ -385,12 +871,12 @@ func (a *Activities) searchForDomainInCollection(tokenID, expectedLabel string)
 		// Prepare for next page
 		pagesChecked++
 		if response.Links.Next != "" && pagesChecked < maxPagesToCheck {
-			parsedURL, err := url.Parse(response.Links.Next)
+			nextPath, err := a.mirrorNextPath(response.Links.Next)
 			if err != nil {
 				fmt.Printf("Warning: Could not parse next URL, stopping pagination\n")
 				break
 			}
-			nextURL = fmt.Sprintf("%s%s", MirrorNodeBaseURL, parsedURL.RequestURI())
+			nextURL = a.Config.Mirror.BaseURL + nextPath
 		} else {
 			nextURL = ""
 		}
@@ -404,14 +890,21 @@ func (a *Activities) searchForDomainInCollection(tokenID, expectedLabel string)
 }
 
 // queryCollectionNFTs queries the Hedera mirror node for all NFTs in a collection
-func (a *Activities) queryCollectionNFTs(tokenID string) ([]MirrorNodeNFT, error) {
+func (a *Activities) queryCollectionNFTs(ctx context.Context, tokenID string) ([]MirrorNodeNFT, error) {
 	var allNFTs []MirrorNodeNFT
-	nextURL := fmt.Sprintf("%s/tokens/%s/nfts?limit=100", MirrorNodeBaseURL, tokenID)
+	nextURL := fmt.Sprintf("%s/tokens/%s/nfts?limit=100", a.Config.Mirror.BaseURL, tokenID)
 
 	client := &http.Client{Timeout: 30 * time.Second}
 
 	for nextURL != "" {
-		resp, err := client.Get(nextURL)
+		if err := a.mirrorLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		req, err := a.newMirrorRequest(ctx, nextURL)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
 		if err != nil {
 			return nil, fmt.Errorf("failed to query mirror node: %w", err)
 		}
@@ -436,11 +929,11 @@ func (a *Activities) queryCollectionNFTs(tokenID string) ([]MirrorNodeNFT, error
 		// Check for pagination
 		if response.Links.Next != "" {
 			// Parse the next URL - it comes as a full URL from mirror node
-			parsedURL, err := url.Parse(response.Links.Next)
+			nextPath, err := a.mirrorNextPath(response.Links.Next)
 			if err != nil {
 				break // Stop pagination on URL parse error
 			}
-			nextURL = fmt.Sprintf("%s%s", MirrorNodeBaseURL, parsedURL.RequestURI())
+			nextURL = a.Config.Mirror.BaseURL + nextPath
 		} else {
 			nextURL = ""
 		}
@@ -451,9 +944,9 @@ func (a *Activities) queryCollectionNFTs(tokenID string) ([]MirrorNodeNFT, error
 
 // CheckCollectionNFTsActivity provides information about minted domains by querying mirror nodes
 func (a *Activities) CheckCollectionNFTsActivity(ctx context.Context, tokenID string) error {
-	fmt.Printf("=== Checking NFTs in Collection %s ===\n", tokenID)
+	fmt.Printf("=== Checking NFTs in Collection %s ===\n", a.displayID(tokenID))
 
-	nfts, err := a.queryCollectionNFTs(tokenID)
+	nfts, err := a.queryCollectionNFTs(ctx, tokenID)
 	if err != nil {
 		fmt.Printf("Error querying collection NFTs: %v\n", err)
 		return err
@@ -485,27 +978,97 @@ func (a *Activities) DebugEnvironmentActivity(ctx context.Context) error {
 	return nil
 }
 
+// Application error types of zone collections
+const (
+	ErrTypeInvalidNaming  = "InvalidNaming"  // The name or symbol of the collection would be rejected by Hedera
+	ErrTypeZoneNotAllowed = "ZoneNotAllowed" // The zone is not to be ingested, its collection is not created
+	ErrTypeInvalidZone    = "InvalidZone"    // The zone is not a valid zone name
+	// The collection was created on Hedera, but could not be recorded in the zone registry
+	ErrTypeCollectionNotRecorded = "CollectionNotRecorded"
+)
+
+// collectionNotRecordedError returns the error of a collection created on Hedera that could not be recorded in
+// the zone registry. It is not retried: the retry would not find the collection and create another one for the
+// zone, so the error names the token for the operator to record.
+func (a *Activities) collectionNotRecordedError(collection ZoneCollectionInfo, err error) error {
+	return temporal.NewNonRetryableApplicationError(fmt.Sprintf(
+		"collection %s of .%s was created but could not be recorded in %s, record it there before the zone is ingested again: %v",
+		collection.TokenID, collection.Zone, a.Config.Registry.ZoneFile, err), ErrTypeCollectionNotRecorded, err)
+}
+
+// zoneCollectionName returns the token name of the NFT collection of a zone, e.g. "APEX Domain Ledger Zone - .BUILD"
+func (a *Activities) zoneCollectionName(zone string) (string, error) {
+	n, err := a.Config.Metadata.Naming()
+	if err != nil {
+		return "", err
+	}
+	return n.Name(zone)
+}
+
+// zoneCollectionSymbol returns the token symbol of the NFT collection of a zone, e.g. "APEX-ZONE.BUILD",
+// unless its branding sets one
+func (a *Activities) zoneCollectionSymbol(zone string, branding metadata.Branding) (string, error) {
+	if branding.Symbol != "" {
+		return branding.Symbol, nil
+	}
+	n, err := a.Config.Metadata.Naming()
+	if err != nil {
+		return "", err
+	}
+	return n.Symbol(zone)
+}
+
 // CreateNFTCollectionActivity creates a new NFT collection for a specific zone on Hedera
 func (a *Activities) CreateNFTCollectionActivity(ctx context.Context, zone string) (ZoneCollectionInfo, error) {
+	return a.createNFTCollection(ctx, zone, ZoneCollectionInfo{})
+}
+
+// createNFTCollection creates a new NFT collection for a zone, the next shard of the zone when sharded carries
+// the earlier shards: the collection is then named after its shard number, and inherits the shards and shard size
+func (a *Activities) createNFTCollection(ctx context.Context, zone string, sharded ZoneCollectionInfo) (ZoneCollectionInfo, error) {
 	fmt.Printf("Creating NFT collection for zone: .%s\n", zone)
 
-	// --- Load Hedera Credentials ---
-	accountID, err := hedera.AccountIDFromString(os.Getenv("HEDERA_ACCOUNT_ID"))
+	// --- Load Hedera Credentials, only the public key when signing offline ---
+	accountID, publicKey, err := a.operatorAccount()
 	if err != nil {
-		return ZoneCollectionInfo{}, fmt.Errorf("invalid HEDERA_ACCOUNT_ID: %w", err)
+		return ZoneCollectionInfo{}, err
 	}
-	privateKey, err := hedera.PrivateKeyFromString(os.Getenv("HEDERA_PRIVATE_KEY"))
+
+	// --- Create Hedera Client ---
+	client, err := a.signingClient()
 	if err != nil {
-		return ZoneCollectionInfo{}, fmt.Errorf("invalid HEDERA_PRIVATE_KEY: %w", err)
+		return ZoneCollectionInfo{}, err
 	}
 
-	// --- Create Hedera Client ---
-	client := hedera.ClientForTestnet()
-	client.SetOperator(accountID, privateKey)
+	// --- Apply the branding of the zone, if any ---
+	branding, err := a.zoneBranding(zone)
+	if err != nil {
+		return ZoneCollectionInfo{}, err
+	}
+	var metadataURI string
+	if !branding.IsZero() {
+		if metadataURI, err = a.uploadCollectionDocument(ctx, zone, branding); err != nil {
+			return ZoneCollectionInfo{}, err
+		}
+	}
 
 	// --- Create the NFT collection for this zone ---
-	tokenName := fmt.Sprintf("%s Domain Ledger Zone - .%s", strings.ToUpper(RegistryIDPrefix), strings.ToUpper(zone))
-	tokenSymbol := fmt.Sprintf("%s-%s.%s", strings.ToUpper(RegistryIDPrefix), strings.ToUpper(ZonePrefix), strings.ToUpper(zone))
+	tokenName, err := a.zoneCollectionName(zone)
+	if err != nil {
+		return ZoneCollectionInfo{}, temporal.NewNonRetryableApplicationError(err.Error(), ErrTypeInvalidNaming, err)
+	}
+	tokenSymbol, err := a.zoneCollectionSymbol(zone, branding)
+	if err != nil {
+		return ZoneCollectionInfo{}, temporal.NewNonRetryableApplicationError(err.Error(), ErrTypeInvalidNaming, err)
+	}
+	if sharded.Shard > 1 {
+		tokenName, tokenSymbol = naming.Shard(tokenName, sharded.Shard), naming.Shard(tokenSymbol, sharded.Shard)
+		err := errors.Join(naming.ValidateName(tokenName), naming.ValidateSymbol(tokenSymbol))
+		if err != nil {
+			return ZoneCollectionInfo{}, temporal.NewNonRetryableApplicationError(
+				fmt.Sprintf("shard %d of .%s: %v", sharded.Shard, zone, err), ErrTypeInvalidNaming, err)
+		}
+	}
 
 	tokenCreateTx := hedera.NewTokenCreateTransaction().
 		SetTokenName(tokenName).
@@ -515,37 +1078,82 @@ func (a *Activities) CreateNFTCollectionActivity(ctx context.Context, zone strin
 		SetInitialSupply(0).
 		SetTreasuryAccountID(accountID).
 		SetSupplyType(hedera.TokenSupplyTypeInfinite).
-		SetSupplyKey(privateKey).
+		SetSupplyKey(publicKey).
+		// The metadata key lets the branding of the collection be updated later
+		SetMetadataKey(publicKey).
 		SetMaxTransactionFee(hedera.NewHbar(30))
+	if metadataURI != "" {
+		tokenCreateTx.SetTokenMetadata([]byte(metadataURI))
+	}
+	// With an admin signer, the collection gets its key as admin and wipe key: the supply key can be rotated
+	// and NFTs taken back from their holders, with the high-privilege key kept off the workers
+	admin, err := a.adminSigner()
+	if err != nil {
+		return ZoneCollectionInfo{}, err
+	}
+	if admin != nil {
+		tokenCreateTx.SetAdminKey(admin.PublicKey()).SetWipeKey(admin.PublicKey())
+	}
+	collection := ZoneCollectionInfo{
+		Zone:        domain.Zone(zone),
+		TokenName:   tokenName,
+		TokenSymbol: tokenSymbol,
+		CreatedBy:   accountID.String(),
+		MetadataURI: metadataURI,
+		Shard:       sharded.Shard,
+		ShardSize:   sharded.ShardSize,
+		Shards:      sharded.Shards,
+	}
+
+	// Signing offline, the collection is created by SubmitSignedTransactionActivity once signed
+	if a.Config.Hedera.Signing == config.SigningOffline {
+		pending, err := a.exportTransaction(ctx, tokenCreateTx, PendingTransaction{
+			Type:       TransactionTokenCreation,
+			Zone:       zone,
+			Summary:    fmt.Sprintf("Create collection %q (%s) of .%s, treasury %s", tokenName, tokenSymbol, zone, accountID),
+			Collection: &collection,
+		}, admin)
+		if err != nil {
+			return ZoneCollectionInfo{}, err
+		}
+		collection.Pending = &pending
+		return collection, nil
+	}
 
 	// Execute the transaction
+	if err := a.txLimiter.Wait(ctx); err != nil {
+		return ZoneCollectionInfo{}, err
+	}
+	if workerStopping(ctx) {
+		return ZoneCollectionInfo{}, errWorkerShutdown("collection create for ." + zone)
+	}
+	if admin != nil {
+		if err := signAdministrative(ctx, tokenCreateTx, client, admin); err != nil {
+			return ZoneCollectionInfo{}, err
+		}
+	}
 	txResponse, err := tokenCreateTx.Execute(client)
 	if err != nil {
 		return ZoneCollectionInfo{}, fmt.Errorf("failed to execute token create transaction: %w", err)
 	}
 
-	// Get the receipt
-	receipt, err := txResponse.GetReceipt(client)
+	// Get the record, kept with the mints of the collection
+	record, err := txResponse.GetRecord(client)
 	if err != nil {
-		return ZoneCollectionInfo{}, fmt.Errorf("failed to get token create receipt: %w", err)
+		return ZoneCollectionInfo{}, fmt.Errorf("failed to get token create record: %w", err)
 	}
 
-	if receipt.TokenID == nil {
+	if record.Receipt.TokenID == nil {
 		return ZoneCollectionInfo{}, fmt.Errorf("token creation failed: no token ID in receipt")
 	}
 
-	tokenID := receipt.TokenID.String()
-	fmt.Printf("Successfully created NFT collection for .%s zone with token ID: %s\n", zone, tokenID)
+	collection.TokenID = record.Receipt.TokenID.String()
+	collection.CreatedAt = time.Now()
+	a.saveTransactionRecord(ctx, newTransactionRecord(TransactionTokenCreation, zone, collection.TokenID, record))
+	fmt.Printf("Successfully created NFT collection for .%s zone with token ID: %s\n", zone, a.displayID(collection.TokenID))
 	fmt.Printf("Collection will be automatically tracked in registry for future reuse\n")
 
-	return ZoneCollectionInfo{
-		Zone:        zone,
-		TokenID:     tokenID,
-		TokenName:   tokenName,
-		TokenSymbol: tokenSymbol,
-		CreatedAt:   time.Now(),
-		CreatedBy:   accountID.String(),
-	}, nil
+	return collection, nil
 }
 
 // ============================================================================
@@ -557,17 +1165,16 @@ func (a *Activities) CreateTopicActivity(ctx context.Context, topicName, descrip
 	fmt.Printf("Creating HCS topic: %s\n", topicName)
 
 	// --- Load Hedera Credentials ---
-	accountID, err := hedera.AccountIDFromString(os.Getenv("HEDERA_ACCOUNT_ID"))
+	accountID, privateKey, err := a.operatorCredentials()
 	if err != nil {
-		return TopicInfo{}, fmt.Errorf("invalid HEDERA_ACCOUNT_ID: %w", err)
-	}
-	privateKey, err := hedera.PrivateKeyFromString(os.Getenv("HEDERA_PRIVATE_KEY"))
-	if err != nil {
-		return TopicInfo{}, fmt.Errorf("invalid HEDERA_PRIVATE_KEY: %w", err)
+		return TopicInfo{}, err
 	}
 
 	// --- Create Hedera Client ---
-	client := hedera.ClientForTestnet()
+	client, err := a.newHederaClient()
+	if err != nil {
+		return TopicInfo{}, err
+	}
 	client.SetOperator(accountID, privateKey)
 
 	// --- Create Topic Transaction ---
@@ -580,12 +1187,21 @@ func (a *Activities) CreateTopicActivity(ctx context.Context, topicName, descrip
 		topicCreateTx.SetAdminKey(privateKey.PublicKey())
 	}
 
-	// Optionally set submit key (restricts who can submit messages)
+	// Optionally set submit key (restricts who can submit messages): HCS_SUBMIT_KEY if set, the operator key otherwise
+	submitKey := privateKey.PublicKey()
+	if key, err := a.submitKey(); err != nil {
+		return TopicInfo{}, err
+	} else if key != nil {
+		submitKey = key.PublicKey()
+	}
 	if enableSubmitKey {
-		topicCreateTx.SetSubmitKey(privateKey.PublicKey())
+		topicCreateTx.SetSubmitKey(submitKey)
 	}
 
 	// Execute the transaction
+	if err := a.txLimiter.Wait(ctx); err != nil {
+		return TopicInfo{}, err
+	}
 	txResponse, err := topicCreateTx.Execute(client)
 	if err != nil {
 		return TopicInfo{}, fmt.Errorf("failed to execute topic create transaction: %w", err)
@@ -602,7 +1218,7 @@ func (a *Activities) CreateTopicActivity(ctx context.Context, topicName, descrip
 	}
 
 	topicID := receipt.TopicID.String()
-	fmt.Printf("Successfully created HCS topic '%s' with ID: %s\n", topicName, topicID)
+	fmt.Printf("Successfully created HCS topic '%s' with ID: %s\n", topicName, a.displayID(topicID))
 
 	topicInfo := TopicInfo{
 		TopicID:     topicID,
@@ -616,7 +1232,7 @@ func (a *Activities) CreateTopicActivity(ctx context.Context, topicName, descrip
 		topicInfo.AdminKey = privateKey.PublicKey().String()
 	}
 	if enableSubmitKey {
-		topicInfo.SubmitKey = privateKey.PublicKey().String()
+		topicInfo.SubmitKey = submitKey.String()
 	}
 
 	// Store in topic registry for future use
@@ -625,31 +1241,40 @@ func (a *Activities) CreateTopicActivity(ctx context.Context, topicName, descrip
 		fmt.Printf("Warning: Could not register topic in registry: %v\n", err)
 	}
 
+	// Record the topic on the registry topic, so other workers find it
+	if a.Config.HCS.RegistryTopic != "" && !a.isRegistryTopic(topicID) {
+		return a.publishTopic(ctx, topicInfo)
+	}
 	return topicInfo, nil
 }
 
-// SendMessageToTopicActivity sends a message to an HCS topic
+// SendMessageToTopicActivity sends a message to an HCS topic. Messages are signed with HCS_SUBMIT_KEY when it is
+// set, for topics whose submit key is not the operator key.
 func (a *Activities) SendMessageToTopicActivity(ctx context.Context, topicID, message string) (TopicMessage, error) {
 	fmt.Printf("Sending message to topic %s: %s\n", topicID, message)
 
 	// --- Load Hedera Credentials ---
-	accountID, err := hedera.AccountIDFromString(os.Getenv("HEDERA_ACCOUNT_ID"))
+	accountID, privateKey, err := a.operatorCredentials()
 	if err != nil {
-		return TopicMessage{}, fmt.Errorf("invalid HEDERA_ACCOUNT_ID: %w", err)
+		return TopicMessage{}, err
 	}
-	privateKey, err := hedera.PrivateKeyFromString(os.Getenv("HEDERA_PRIVATE_KEY"))
+	submitKey, err := a.submitKey()
 	if err != nil {
-		return TopicMessage{}, fmt.Errorf("invalid HEDERA_PRIVATE_KEY: %w", err)
+		return TopicMessage{}, err
 	}
 
 	// --- Parse Topic ID ---
-	hederaTopicID, err := hedera.TopicIDFromString(topicID)
+	hederaTopicID, err := entityid.ParseTopic(topicID, a.network())
 	if err != nil {
 		return TopicMessage{}, fmt.Errorf("invalid topic ID: %w", err)
 	}
 
 	// --- Create Hedera Client ---
-	client := hedera.ClientForTestnet()
+	client, err := a.newHederaClient()
+	if err != nil {
+		return TopicMessage{}, err
+	}
+	defer client.Close()
 	client.SetOperator(accountID, privateKey)
 
 	// --- Send Message Transaction ---
@@ -657,20 +1282,34 @@ func (a *Activities) SendMessageToTopicActivity(ctx context.Context, topicID, me
 		SetTopicID(hederaTopicID).
 		SetMessage([]byte(message)).
 		SetMaxTransactionFee(hedera.NewHbar(5))
+	if submitKey != nil {
+		if _, err := messageTx.FreezeWith(client); err != nil {
+			return TopicMessage{}, fmt.Errorf("failed to freeze message submit transaction: %w", err)
+		}
+		messageTx.Sign(*submitKey)
+	}
 
 	// Execute the transaction
+	if err := a.txLimiter.Wait(ctx); err != nil {
+		return TopicMessage{}, err
+	}
+	if workerStopping(ctx) {
+		return TopicMessage{}, errWorkerShutdown("message to topic " + topicID)
+	}
 	txResponse, err := messageTx.Execute(client)
-	if err != nil {
-		return TopicMessage{}, fmt.Errorf("failed to execute message submit transaction: %w", err)
+	var receipt hedera.TransactionReceipt
+	if err == nil {
+		receipt, err = txResponse.GetReceipt(client)
+	}
+	if isStatus(err, hedera.StatusInvalidSignature) {
+		return TopicMessage{}, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("the submit key of topic %s is neither the operator key nor HCS_SUBMIT_KEY", a.displayID(topicID)), ErrTypeSubmitKey, err)
 	}
-
-	// Get the receipt
-	receipt, err := txResponse.GetReceipt(client)
 	if err != nil {
-		return TopicMessage{}, fmt.Errorf("failed to get message submit receipt: %w", err)
+		return TopicMessage{}, fmt.Errorf("failed to submit message: %w", err)
 	}
 
-	fmt.Printf("Successfully sent message to topic %s. Sequence number: %d\n", topicID, receipt.TopicSequenceNumber)
+	fmt.Printf("Successfully sent message to topic %s. Sequence number: %d\n", a.displayID(topicID), receipt.TopicSequenceNumber)
 
 	return TopicMessage{
 		TopicID:        topicID,
@@ -682,89 +1321,25 @@ func (a *Activities) SendMessageToTopicActivity(ctx context.Context, topicID, me
 	}, nil
 }
 
-// SubscribeToTopicActivity subscribes to an HCS topic and reads messages
-func (a *Activities) SubscribeToTopicActivity(ctx context.Context, subscription TopicSubscriptionInfo) ([]TopicMessage, error) {
-	fmt.Printf("Subscribing to topic %s\n", subscription.TopicID)
-
-	// --- Parse Topic ID ---
-	hederaTopicID, err := hedera.TopicIDFromString(subscription.TopicID)
-	if err != nil {
-		return nil, fmt.Errorf("invalid topic ID: %w", err)
-	}
-
-	// --- Create Hedera Client ---
-	client := hedera.ClientForTestnet()
-
-	var messages []TopicMessage
-	messageCount := 0
-
-	// Create subscription query
-	query := hedera.NewTopicMessageQuery().
-		SetTopicID(hederaTopicID).
-		SetMaxAttempts(3)
-
-	// Set start time if specified
-	if !subscription.StartTime.IsZero() {
-		query.SetStartTime(subscription.StartTime)
-	}
-
-	// Set end time if specified
-	if !subscription.EndTime.IsZero() {
-		query.SetEndTime(subscription.EndTime)
-	}
-
-	// Set limit if specified
-	limit := subscription.Limit
-	if limit == 0 {
-		limit = 100 // Default limit to prevent runaway subscriptions
-	}
-
-	fmt.Printf("Starting subscription with limit: %d messages\n", limit)
-
-	// Subscribe and handle messages
-	_, err = query.Subscribe(client, func(message hedera.TopicMessage) {
-		messageCount++
-		fmt.Printf("Received message %d: Sequence %d at %s\n",
-			messageCount, message.SequenceNumber, message.ConsensusTimestamp.Format(time.RFC3339))
-
-		topicMsg := TopicMessage{
-			TopicID:        subscription.TopicID,
-			SequenceNumber: message.SequenceNumber,
-			ConsensusTime:  message.ConsensusTimestamp,
-			Message:        string(message.Contents),
-			RunningHash:    fmt.Sprintf("%x", message.RunningHash), // Convert bytes to hex string
-		}
-		messages = append(messages, topicMsg)
-
-		// Stop if we've reached the limit
-		if messageCount >= limit {
-			fmt.Printf("Reached message limit (%d), stopping subscription\n", limit)
-			return
-		}
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to subscribe to topic: %w", err)
-	}
-
-	fmt.Printf("Subscription completed. Received %d messages\n", len(messages))
-	return messages, nil
-}
-
 // LookupOrCreateTopicActivity looks up an existing topic or creates a new one
 func (a *Activities) LookupOrCreateTopicActivity(ctx context.Context, topicName, description string, enableAdminKey, enableSubmitKey bool) (TopicInfo, error) {
 	fmt.Printf("Looking up or creating HCS topic: %s\n", topicName)
 
-	// Load the topic registry
-	registry, err := a.loadTopicRegistry()
-	if err != nil {
+	// Look the topic up in the topic registry, and on the registry topic if there is one
+	topicInfo, exists, err := a.lookupTopic(ctx, topicName)
+	switch {
+	case err != nil && a.Config.HCS.RegistryTopic != "":
+		// Creating the topic without knowing the registry topic could duplicate it
+		return TopicInfo{}, err
+	case err != nil:
 		fmt.Printf("Warning: Could not load topic registry: %v. Will create new topic.\n", err)
-	} else {
-		// Check if we already have this topic in our registry
-		if topicInfo, exists := registry.Topics[topicName]; exists {
-			fmt.Printf("Found existing topic '%s' in registry: %s\n", topicName, topicInfo.TopicID)
-			return topicInfo, nil
+	case exists:
+		fmt.Printf("Found existing topic '%s' in registry: %s\n", topicName, a.displayID(topicInfo.TopicID))
+		if a.Config.HCS.RegistryTopic != "" && topicInfo.RegistrySequence == 0 && !a.isRegistryTopic(topicInfo.TopicID) {
+			// Created before HCS_REGISTRY_TOPIC was set, or its publication failed
+			return a.publishTopic(ctx, topicInfo)
 		}
+		return topicInfo, nil
 	}
 
 	// No existing topic found, create a new one
@@ -774,12 +1349,11 @@ func (a *Activities) LookupOrCreateTopicActivity(ctx context.Context, topicName,
 
 // GetTopicInfoActivity retrieves information about a topic from the registry
 func (a *Activities) GetTopicInfoActivity(ctx context.Context, topicName string) (TopicInfo, error) {
-	registry, err := a.loadTopicRegistry()
+	topicInfo, exists, err := a.lookupTopic(ctx, topicName)
 	if err != nil {
-		return TopicInfo{}, fmt.Errorf("failed to load topic registry: %w", err)
+		return TopicInfo{}, err
 	}
-
-	if topicInfo, exists := registry.Topics[topicName]; exists {
+	if exists {
 		return topicInfo, nil
 	}
 
@@ -788,9 +1362,12 @@ func (a *Activities) GetTopicInfoActivity(ctx context.Context, topicName string)
 
 // loadTopicRegistry loads the topic registry from a JSON file
 func (a *Activities) loadTopicRegistry() (*TopicRegistry, error) {
-	data, err := os.ReadFile(TopicRegistryFile)
+	data, err := a.readRegistryFile(a.Config.Registry.TopicFile)
 	if err != nil {
 		if os.IsNotExist(err) {
+			if err := a.checkRegistryFile(a.Config.Registry.TopicFile, nil); err != nil {
+				return nil, err
+			}
 			return &TopicRegistry{
 				Topics:      make(map[string]TopicInfo),
 				LastUpdated: time.Now(),
@@ -798,12 +1375,18 @@ func (a *Activities) loadTopicRegistry() (*TopicRegistry, error) {
 		}
 		return nil, err
 	}
+	if err := a.checkRegistryFile(a.Config.Registry.TopicFile, data); err != nil {
+		return nil, err
+	}
 
 	var registry TopicRegistry
 	err = json.Unmarshal(data, &registry)
 	if err != nil {
 		return nil, err
 	}
+	if err := a.checkRegistryNetwork(a.Config.Registry.TopicFile, registry.Network); err != nil {
+		return nil, err
+	}
 
 	return &registry, nil
 }
@@ -811,15 +1394,27 @@ func (a *Activities) loadTopicRegistry() (*TopicRegistry, error) {
 // saveTopicRegistry saves the topic registry to a JSON file
 func (a *Activities) saveTopicRegistry(registry *TopicRegistry) error {
 	registry.LastUpdated = time.Now()
+	registry.Network = a.network()
 	data, err := json.MarshalIndent(registry, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(TopicRegistryFile, data, 0644)
+	if err := os.MkdirAll(filepath.Dir(a.Config.Registry.TopicFile), 0755); err != nil {
+		return err
+	}
+	if err := a.signRegistryFile(a.Config.Registry.TopicFile, data); err != nil {
+		return err
+	}
+	return a.writeRegistryFile(a.Config.Registry.TopicFile, data)
 }
 
 // registerTopic adds a topic to the registry
 func (a *Activities) registerTopic(topicInfo TopicInfo) error {
+	unlock, err := a.lockTopicRegistry()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 	registry, err := a.loadTopicRegistry()
 	if err != nil {
 		return err
@@ -841,12 +1436,15 @@ func (a *Activities) CheckTopicRegistryActivity(ctx context.Context) error {
 
 	fmt.Printf("Total registered topics: %d\n", len(registry.Topics))
 	fmt.Printf("Registry last updated: %s\n", registry.LastUpdated.Format(time.RFC3339))
+	if registry.RegistryTopic != "" {
+		fmt.Printf("Synced with registry topic %s up to record %d\n", a.displayID(registry.RegistryTopic), registry.RegistrySequence)
+	}
 
 	if len(registry.Topics) > 0 {
 		fmt.Println("Registered topics:")
 		for name, info := range registry.Topics {
 			fmt.Printf("  - %s: %s (created %s)\n",
-				name, info.TopicID, info.CreatedAt.Format(time.RFC3339))
+				name, a.displayID(info.TopicID), info.CreatedAt.Format(time.RFC3339))
 		}
 	}
 
@@ -0,0 +1,47 @@
+package temporal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+)
+
+func TestEventParser_Parse(t *testing.T) {
+	p, err := (&Activities{Config: &config.Config{}}).eventParser()
+	require.NoError(t, err)
+
+	info, quarantined, err := p.parse(`"registry-event":{"t":"domain","o":"example.build","z":"Build","e":"create"}`, 1)
+	require.NoError(t, err)
+	require.Nil(t, quarantined)
+	assert.Equal(t, "example.build", info.DomainName)
+	assert.Equal(t, domain.Zone("build"), info.Zone)
+	assert.Equal(t, 1, info.LineNumber)
+
+	tests := []struct {
+		name  string
+		line  string
+		cause string
+	}{
+		{"zone mismatch", `"registry-event":{"t":"domain","o":"example.shop","z":"build","e":"create"}`, QuarantineZoneMismatch},
+		{"name below the zone", `"registry-event":{"t":"domain","o":"www.example.build","z":"build","e":"create"}`, QuarantineZoneMismatch},
+		{"invalid domain name", `"registry-event":{"t":"domain","o":"exa mple.build","z":"build","e":"create"}`, QuarantineParseError},
+		{"invalid domain name of another zone", `"registry-event":{"t":"domain","o":"-example.shop","z":"build","e":"create"}`, QuarantineParseError},
+		{"not JSON", `"registry-event":{"t":"domain"`, QuarantineParseError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, quarantined, err := p.parse(tt.line, 2)
+			require.NoError(t, err)
+			require.NotNil(t, quarantined)
+			assert.Equal(t, tt.cause, quarantined.Cause)
+			assert.Equal(t, 2, quarantined.LineNumber)
+		})
+	}
+
+	_, _, err = p.parse("# comment", 3)
+	assert.ErrorIs(t, err, errNotAnEvent)
+}
@@ -0,0 +1,160 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/signer"
+)
+
+// Application error types of administrative transactions
+const (
+	ErrTypeNoAdminSigner = "NoAdminSigner" // No external signer is configured for the administrative transactions
+	ErrTypeNoAdminKey    = "NoAdminKey"    // The collection was created without an admin key, its keys cannot be updated
+	ErrTypeInvalidKey    = "InvalidKey"    // The key to set is not a valid public key
+)
+
+// SupplyKeyRotation is the outcome of updating the supply key of a zone collection
+type SupplyKeyRotation struct {
+	Zone          string `json:"zone"`
+	TokenID       string `json:"token_id"`
+	SupplyKey     string `json:"supply_key"`     // DER encoded public key now minting the collection
+	TransactionID string `json:"transaction_id"` // Token update setting the key
+}
+
+// adminSigner returns the external signer of the administrative transactions, nil when none is configured and
+// the collections are created without admin and wipe keys
+func (a *Activities) adminSigner() (signer.Signer, error) {
+	if !a.Config.Hedera.AdminSigner() {
+		return nil, nil
+	}
+	publicKey, err := hedera.PublicKeyFromString(a.Config.Hedera.AdminKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HEDERA_ADMIN_KEY: %w", err)
+	}
+	if a.Config.Hedera.AdminSignerURL != "" {
+		return signer.NewRemote(a.Config.Hedera.AdminSignerURL, a.Config.Hedera.AdminSignerToken, publicKey), nil
+	}
+	return signer.NewCommand(a.Config.Hedera.AdminSignerCommand, publicKey), nil
+}
+
+// signAdministrative freezes a transaction for the operator of the client and signs it with the operator key,
+// then with the admin signer. The operator signs first: the SDK signs again when signatures are added after
+// those of the external signer, which would ask the signer, and its holder, once more.
+func signAdministrative(ctx context.Context, tx hedera.TransactionInterface, client *hedera.Client, admin signer.Signer) error {
+	if _, err := hedera.TransactionFreezeWith(tx, client); err != nil {
+		return fmt.Errorf("failed to freeze transaction: %w", err)
+	}
+	if _, err := hedera.TransactionSignWithOperator(tx, client); err != nil {
+		return fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	heartbeat(ctx, "waiting for the admin signer")
+	if err := signer.SignTransaction(ctx, admin, tx); err != nil {
+		return fmt.Errorf("admin signer: %w", err)
+	}
+	return nil
+}
+
+// UpdateSupplyKeyActivity sets the supply key of a zone collection to the given public key, e.g. when the hot
+// key of the workers is rotated. The update is signed by the admin signer, with the admin key the collection
+// was created with; collections created without one fail with ErrTypeNoAdminKey.
+func (a *Activities) UpdateSupplyKeyActivity(ctx context.Context, zone, publicKey string) (SupplyKeyRotation, error) {
+	result := SupplyKeyRotation{Zone: zone}
+	supplyKey, err := hedera.PublicKeyFromString(publicKey)
+	if err != nil {
+		return result, temporal.NewNonRetryableApplicationError(fmt.Sprintf("invalid supply key: %v", err), ErrTypeInvalidKey, err)
+	}
+	result.SupplyKey = supplyKey.StringDer()
+	admin, err := a.adminSigner()
+	if err != nil {
+		return result, err
+	}
+	if admin == nil {
+		return result, temporal.NewNonRetryableApplicationError(
+			"key updates require HEDERA_ADMIN_SIGNER_URL or HEDERA_ADMIN_SIGNER_COMMAND", ErrTypeNoAdminSigner, nil)
+	}
+	if result.TokenID, err = a.resolveZoneCollection(ctx, zone, ""); err != nil {
+		return result, err
+	}
+	token, err := a.tokenIDFromString(result.TokenID)
+	if err != nil {
+		return result, err
+	}
+	operatorID, privateKey, err := a.operatorCredentials()
+	if err != nil {
+		return result, err
+	}
+	client, err := a.newHederaClient()
+	if err != nil {
+		return result, err
+	}
+	defer client.Close()
+	client.SetOperator(operatorID, privateKey)
+
+	updateTx := hedera.NewTokenUpdateTransaction().
+		SetTokenID(token).
+		SetSupplyKey(supplyKey)
+	if err := signAdministrative(ctx, updateTx, client, admin); err != nil {
+		return result, err
+	}
+	if err := a.txLimiter.Wait(ctx); err != nil {
+		return result, err
+	}
+	if workerStopping(ctx) {
+		return result, errWorkerShutdown("supply key update of ." + zone)
+	}
+	txResponse, err := updateTx.Execute(client)
+	if err == nil {
+		_, err = txResponse.GetReceipt(client)
+	}
+	if isStatus(err, hedera.StatusTokenIsImmutable) {
+		return result, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("%s was created without an admin key, its supply key cannot be updated", result.TokenID), ErrTypeNoAdminKey, err)
+	}
+	if isStatus(err, hedera.StatusInvalidSignature) {
+		return result, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("the admin key of %s is not HEDERA_ADMIN_KEY", result.TokenID), ErrTypeNoAdminKey, err)
+	}
+	if err != nil {
+		return result, fmt.Errorf("token update failed: %w", err)
+	}
+	result.TransactionID = txResponse.TransactionID.String()
+	fmt.Printf("Updated the supply key of collection %s of .%s to %s\n", a.displayID(result.TokenID), zone, supplyKey)
+	return result, nil
+}
+
+// RotateSupplyKeyWorkflow sets the supply key of an existing zone collection, signed by the admin signer
+func RotateSupplyKeyWorkflow(ctx workflow.Context, zone, publicKey string) (SupplyKeyRotation, error) {
+	options := defaultActivityOptions()
+	// The holder of the admin key may have to confirm on a hardware wallet
+	options.StartToCloseTimeout = 15 * time.Minute
+	ctx = workflow.WithActivityOptions(ctx, options)
+	var result SupplyKeyRotation
+	err := workflow.ExecuteActivity(ctx, "UpdateSupplyKeyActivity", zone, publicKey).Get(ctx, &result)
+	return result, err
+}
+
+// wipeNFT wipes an NFT from the account holding it with the wipe key of the collection, signed by the admin
+// signer. Wiping burns the NFT like a burn of the treasury does.
+func (a *Activities) wipeNFT(ctx context.Context, client *hedera.Client, admin signer.Signer, tokenID hedera.TokenID, holder string, serial int64, memo string) (hedera.TransactionResponse, error) {
+	holderID, err := hedera.AccountIDFromString(holder)
+	if err != nil {
+		return hedera.TransactionResponse{}, fmt.Errorf("invalid holder %s: %w", holder, err)
+	}
+	wipeTx := hedera.NewTokenWipeTransaction().
+		SetTokenID(tokenID).
+		SetAccountID(holderID).
+		SetSerialNumbers([]int64{serial})
+	if memo != "" {
+		wipeTx.SetTransactionMemo(memo)
+	}
+	if err := signAdministrative(ctx, wipeTx, client, admin); err != nil {
+		return hedera.TransactionResponse{}, err
+	}
+	return wipeTx.Execute(client)
+}
@@ -0,0 +1,180 @@
+package temporal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/merkle"
+)
+
+// AnchorMessageVersion is the version of the anchor message format
+const AnchorMessageVersion = 1
+
+// AnchoredEvent is a leaf of the Merkle tree of an anchored batch
+type AnchoredEvent struct {
+	Line      int    `json:"line"`
+	Domain    string `json:"domain"`
+	EventHash string `json:"event_hash"` // Hex canonical hash of the event, the data of the leaf
+}
+
+// BatchAnchor is the Merkle tree of a batch of events and where its root was anchored.
+// The tree is fully determined by its events, in order.
+type BatchAnchor struct {
+	BatchID        string          `json:"batch_id"` // The zone workflow that minted the batch
+	Zone           string          `json:"zone"`
+	Algorithm      string          `json:"algorithm"`
+	Root           string          `json:"root"`
+	Events         []AnchoredEvent `json:"events"`
+	TopicID        string          `json:"topic_id,omitempty"`
+	SequenceNumber uint64          `json:"sequence_number,omitempty"` // Zero until the root is anchored
+	ConsensusTime  time.Time       `json:"consensus_time,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// AnchorMessage is the message anchoring the Merkle root of a batch to HCS
+type AnchorMessage struct {
+	Version   int    `json:"v"`
+	Type      string `json:"type"` // Always "merkle_root"
+	BatchID   string `json:"batch"`
+	Zone      string `json:"zone"`
+	Algorithm string `json:"alg"`
+	Root      string `json:"root"`
+	Leaves    int    `json:"leaves"`
+}
+
+// EventHash returns the canonical hash of the registry event a domain was minted for.
+// Domains without an event, e.g. from an imported domain list, hash their name.
+func EventHash(info MintingInfo) string {
+	if info.EventHash != "" {
+		return info.EventHash
+	}
+	return DomainHash(info.DomainName)
+}
+
+// NewBatchAnchor builds the Merkle tree over the events of the given domains, in order
+func NewBatchAnchor(batchID, zone string, domains []MintingInfo) (BatchAnchor, error) {
+	anchor := BatchAnchor{BatchID: batchID, Zone: zone, Algorithm: merkle.Algorithm}
+	for _, info := range domains {
+		anchor.Events = append(anchor.Events, AnchoredEvent{Line: info.LineNumber, Domain: info.DomainName, EventHash: EventHash(info)})
+	}
+	root, err := anchor.computeRoot()
+	if err != nil {
+		return anchor, err
+	}
+	anchor.Root = root.String()
+	return anchor, nil
+}
+
+// computeRoot recomputes the Merkle root over the events of the anchor
+func (b BatchAnchor) computeRoot() (merkle.Hash, error) {
+	leaves := make([][]byte, len(b.Events))
+	for i, event := range b.Events {
+		h, err := merkle.ParseHash(event.EventHash)
+		if err != nil {
+			return merkle.Hash{}, fmt.Errorf("event on line %d: %w", event.Line, err)
+		}
+		leaves[i] = h[:]
+	}
+	return merkle.Root(leaves), nil
+}
+
+// AnchorPath returns the path the Merkle tree of a batch is stored at in the given directory
+func AnchorPath(dir, batchID string) string {
+	return filepath.Join(dir, batchID+".json")
+}
+
+// AnchorBatchActivity stores the Merkle tree of a batch in the anchor directory and anchors its root to the
+// anchor topic, creating the topic on first use. Only the root is published, the tree stays in the registry store.
+// A batch whose root is already anchored is not anchored again.
+func (a *Activities) AnchorBatchActivity(ctx context.Context, topicName string, anchor BatchAnchor) (BatchAnchor, error) {
+	root, err := anchor.computeRoot()
+	if err != nil {
+		return anchor, err
+	}
+	if root.String() != anchor.Root {
+		return anchor, fmt.Errorf("root %s does not match the %d events of batch %s", anchor.Root, len(anchor.Events), anchor.BatchID)
+	}
+
+	path := AnchorPath(a.Config.Registry.AnchorDir, anchor.BatchID)
+	if stored, err := a.loadAnchor(path); err == nil && stored.Root == anchor.Root && stored.SequenceNumber != 0 {
+		fmt.Printf("Batch %s is already anchored as message %d of topic %s\n", anchor.BatchID, stored.SequenceNumber, stored.TopicID)
+		return stored, nil
+	}
+
+	// Store the tree before anchoring its root, so an anchored root always has its tree
+	anchor.CreatedAt = time.Now().UTC()
+	if err := a.saveAnchor(path, anchor); err != nil {
+		return anchor, err
+	}
+
+	topic, err := a.LookupOrCreateTopicActivity(ctx, topicName, "Merkle roots of the shadow domain ledger", true, true)
+	if err != nil {
+		return anchor, fmt.Errorf("failed to look up anchor topic: %w", err)
+	}
+	message, err := json.Marshal(AnchorMessage{
+		Version:   AnchorMessageVersion,
+		Type:      "merkle_root",
+		BatchID:   anchor.BatchID,
+		Zone:      anchor.Zone,
+		Algorithm: anchor.Algorithm,
+		Root:      anchor.Root,
+		Leaves:    len(anchor.Events),
+	})
+	if err != nil {
+		return anchor, fmt.Errorf("failed to marshal anchor message: %w", err)
+	}
+	sealed, err := a.sealAuditMessage(message)
+	if err != nil {
+		return anchor, err
+	}
+	sent, err := a.SendMessageToTopicActivity(ctx, topic.TopicID, sealed)
+	if err != nil {
+		return anchor, err
+	}
+
+	anchor.TopicID = sent.TopicID
+	anchor.SequenceNumber = sent.SequenceNumber
+	anchor.ConsensusTime = sent.ConsensusTime
+	if err := a.saveAnchor(path, anchor); err != nil {
+		return anchor, err
+	}
+	fmt.Printf("Anchored root %s of %d events of batch %s as message %d of topic %s\n",
+		anchor.Root, len(anchor.Events), anchor.BatchID, anchor.SequenceNumber, a.displayID(anchor.TopicID))
+	return anchor, nil
+}
+
+// loadAnchor reads a stored batch anchor
+func (a *Activities) loadAnchor(path string) (BatchAnchor, error) {
+	var anchor BatchAnchor
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return anchor, err
+	}
+	if err := json.Unmarshal(data, &anchor); err != nil {
+		return anchor, fmt.Errorf("failed to parse anchor %s: %w", path, err)
+	}
+	if anchor.BatchID == "" {
+		return anchor, errors.New("not a batch anchor: " + path)
+	}
+	return anchor, nil
+}
+
+// saveAnchor writes a batch anchor to the anchor directory
+func (a *Activities) saveAnchor(path string, anchor BatchAnchor) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create anchor directory: %w", err)
+	}
+	data, err := json.MarshalIndent(anchor, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal anchor: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write anchor: %w", err)
+	}
+	return nil
+}
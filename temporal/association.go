@@ -0,0 +1,175 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/entityid"
+)
+
+// ErrTypeNotAssociated is the application error type returned when the destination of a transfer is not associated with the token
+const ErrTypeNotAssociated = "TokenNotAssociated"
+
+// associationPollInterval is the interval between association checks while waiting for the account holder
+const associationPollInterval = time.Minute
+
+// AssociationOptions tell transfer workflows how to handle destination accounts not associated with the token
+type AssociationOptions struct {
+	Policy  string        // config.AssociationWait, config.AssociationAuto or config.AssociationFail
+	Timeout time.Duration // How long to wait for the account holder to associate the token
+}
+
+// TokenAssociation is the association state of an account with a token
+type TokenAssociation struct {
+	AccountID string `json:"account_id"`
+	TokenID   string `json:"token_id"`
+	// The account is associated, or has a free automatic association slot that a transfer will use
+	Ready  bool   `json:"ready"`
+	Detail string `json:"detail"`
+	// The account is controlled by the operator key, so the operator can associate it
+	OperatorControlled bool `json:"operator_controlled"`
+}
+
+// CheckTokenAssociationActivity checks on the mirror node whether an account can receive an NFT of a token:
+// it must be associated with the token, or have automatic association slots left
+func (a *Activities) CheckTokenAssociationActivity(ctx context.Context, accountID, tokenID string) (TokenAssociation, error) {
+	result := TokenAssociation{AccountID: accountID, TokenID: tokenID}
+	var account MirrorNodeAccount
+	if err := a.mirrorGet(ctx, "/accounts/"+accountID, &account); err != nil {
+		return result, err
+	}
+	if _, privateKey, err := a.operatorCredentials(); err == nil && account.Key != nil {
+		result.OperatorControlled = strings.EqualFold(account.Key.Key, privateKey.PublicKey().StringRaw())
+	}
+
+	automatic := 0
+	path := fmt.Sprintf("/accounts/%s/tokens?limit=100", accountID)
+	for path != "" {
+		var response MirrorNodeTokenRelationshipsResponse
+		if err := a.mirrorGet(ctx, path, &response); err != nil {
+			return result, err
+		}
+		for _, token := range response.Tokens {
+			if token.TokenID == tokenID {
+				result.Ready = true
+				result.Detail = fmt.Sprintf("%s is associated with %s", accountID, a.displayID(tokenID))
+				return result, nil
+			}
+			if token.AutomaticAssociation {
+				automatic++
+			}
+		}
+		path = ""
+		if response.Links.Next != "" {
+			var err error
+			if path, err = a.mirrorNextPath(response.Links.Next); err != nil {
+				return result, fmt.Errorf("invalid pagination link: %w", err)
+			}
+		}
+	}
+
+	switch max := account.MaxAutomaticTokenAssociations; {
+	case max < 0:
+		result.Ready = true
+		result.Detail = fmt.Sprintf("%s associates tokens automatically", accountID)
+	case automatic < max:
+		result.Ready = true
+		result.Detail = fmt.Sprintf("%s has %d of %d automatic association slots left", accountID, max-automatic, max)
+	default:
+		result.Detail = fmt.Sprintf("%s is not associated with %s and has no automatic association slots left", accountID, a.displayID(tokenID))
+	}
+	return result, nil
+}
+
+// AssociateTokenActivity associates an account controlled by the operator key with a token
+func (a *Activities) AssociateTokenActivity(ctx context.Context, accountID, tokenID string) (string, error) {
+	operatorID, privateKey, err := a.operatorCredentials()
+	if err != nil {
+		return "", err
+	}
+	account, err := entityid.ParseAccount(accountID, a.network())
+	if err != nil {
+		return "", err
+	}
+	token, err := a.tokenIDFromString(tokenID)
+	if err != nil {
+		return "", err
+	}
+	client, err := a.newHederaClient()
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+	client.SetOperator(operatorID, privateKey)
+
+	if err := a.txLimiter.Wait(ctx); err != nil {
+		return "", err
+	}
+	if workerStopping(ctx) {
+		return "", errWorkerShutdown(fmt.Sprintf("association of %s with %s", accountID, tokenID))
+	}
+	txResponse, err := hedera.NewTokenAssociateTransaction().
+		SetAccountID(account).
+		SetTokenIDs(token).
+		Execute(client)
+	if err == nil {
+		_, err = txResponse.GetReceipt(client)
+	}
+	if isStatus(err, hedera.StatusTokenAlreadyAssociatedToAccount) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("association failed: %w", err)
+	}
+	fmt.Printf("Associated %s with %s\n", accountID, a.displayID(tokenID))
+	return txResponse.TransactionID.String(), nil
+}
+
+// ensureTokenAssociation makes sure an account can receive an NFT of a token before it is transferred to it,
+// following the association policy: it waits for the account holder to associate the token, associates it
+// with the operator key when the account is controlled by it, or fails with an actionable error.
+// onWait is called with the association state every time the workflow waits.
+func ensureTokenAssociation(ctx workflow.Context, accountID, tokenID string, options AssociationOptions, onWait func(TokenAssociation)) error {
+	logger := workflow.GetLogger(ctx)
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = config.DefaultAssociationTimeout
+	}
+	deadline := workflow.Now(ctx).Add(timeout)
+	for {
+		var association TokenAssociation
+		if err := workflow.ExecuteActivity(ctx, "CheckTokenAssociationActivity", accountID, tokenID).Get(ctx, &association); err != nil {
+			return err
+		}
+		if association.Ready {
+			return nil
+		}
+		if options.Policy == config.AssociationAuto && association.OperatorControlled {
+			logger.Info("Associating token", "account", accountID, "token", tokenID)
+			return workflow.ExecuteActivity(ctx, "AssociateTokenActivity", accountID, tokenID).Get(ctx, nil)
+		}
+
+		instructions := fmt.Sprintf("%s: sign a TokenAssociateTransaction for %s with the key of %s (e.g. in a wallet), or enable automatic token associations on the account",
+			association.Detail, tokenID, accountID)
+		if options.Policy == config.AssociationFail {
+			return temporal.NewNonRetryableApplicationError(instructions, ErrTypeNotAssociated, nil)
+		}
+		if !workflow.Now(ctx).Before(deadline) {
+			return temporal.NewNonRetryableApplicationError(fmt.Sprintf("gave up after %s: %s", timeout, instructions), ErrTypeNotAssociated, nil)
+		}
+		association.Detail = instructions
+		if onWait != nil {
+			onWait(association)
+		}
+		if err := workflow.Sleep(ctx, associationPollInterval); err != nil {
+			return err
+		}
+	}
+}
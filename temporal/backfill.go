@@ -0,0 +1,391 @@
+package temporal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/archive"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+)
+
+// ErrTypeInvalidBackfill is the application error type of backfill requests that can never succeed
+const ErrTypeInvalidBackfill = "InvalidBackfill"
+
+// backfillFilesPerRun bounds the history of one BackfillWorkflow run, after which it continues as new
+const backfillFilesPerRun = 100
+
+// Outcomes of the files of a backfill or a multi-file ingest
+const (
+	FileIngested  = "ingested"  // Ingested by this run
+	FileDuplicate = "duplicate" // Skipped, the content was already ingested or appears earlier in the run
+	FileFailed    = "failed"    // Could not be fetched or ingested
+)
+
+// BackfillRequest is the input of BackfillWorkflow
+type BackfillRequest struct {
+	Source          string             // Archive location: a local directory or s3://bucket/prefix
+	From            time.Time          // First day of the range
+	To              time.Time          // Last day of the range, included
+	ContinueOnError bool               // Go on with the next file when one fails, instead of stopping the backfill
+	ZoneTaskQueues  map[string]string  // zone -> task queue for sharded zones, other zones use the parent's queue
+	HCS             config.HCSConfig   // HCS topics the mints are published to
+	Zones           config.ZonesConfig // Zones ingested, the domains of other zones are refused before their collection is created
+	MaxMints        int                // Domains each file may mint at most, unlimited if zero (MAX_MINTS_PER_RUN)
+	BudgetTinybar   int64              // Fees each file may spend before its run pauses, unlimited if zero (RUN_BUDGET_HBAR)
+	BudgetUSD       float64            // Budget in US dollars, converted at the exchange rate of the network when BudgetTinybar is zero (RUN_BUDGET_USD)
+	Notify          bool               // Announce the runs of the files on the webhooks of NOTIFY_WEBHOOKS
+	FailureAlert    int                // Failed domains of a file that raise an alert on the webhooks, none if zero (NOTIFY_FAILURE_THRESHOLD)
+	EmailReport     bool               // Email the report of every file to REPORT_EMAIL_TO
+	ArchiveReport   bool               // Upload the report of every file to REPORT_BUCKET
+	EscalateAfter   int                // Consecutive failures of a zone that page the operators and pause it, never if zero (ESCALATION_THRESHOLD)
+
+	// Scope of the workflow IDs of the ingests, as of this workflow (TemporalConfig.WorkflowIDScope)
+	WorkflowIDScope string
+
+	// How long minted NFTs may take to show on the mirror node, not checked if zero (MIRROR_VISIBILITY_TIMEOUT)
+	VisibilityTimeout time.Duration
+
+	// Carried over when the workflow continues as new
+	Files   []archive.File  // Files of the range in chronological order, listed by the first run
+	Next    int             // Index in Files of the next file to backfill
+	Summary BackfillSummary // Outcome of the files backfilled so far
+}
+
+// BackfillFile is a file of the archive that is ready to be ingested
+type BackfillFile struct {
+	Name        string
+	Path        string // Local path, in ARCHIVE_STAGING_DIR for object storage archives
+	ContentHash string
+}
+
+// BackfillFileResult is the outcome of one file of a backfill
+type BackfillFileResult struct {
+	Name        string    `json:"name"`
+	Date        time.Time `json:"date"`
+	ContentHash string    `json:"content_hash,omitempty"`
+	Outcome     string    `json:"outcome"`
+	WorkflowID  string    `json:"workflow_id,omitempty"` // Ingest run of the content, by this backfill or an earlier one
+	Error       string    `json:"error,omitempty"`
+}
+
+// BackfillSummary is the consolidated report of a backfill, written to REPORT_DIR
+type BackfillSummary struct {
+	WorkflowID string               `json:"workflow_id"`
+	Source     string               `json:"source"`
+	From       time.Time            `json:"from"`
+	To         time.Time            `json:"to"`
+	StartedAt  time.Time            `json:"started_at"`
+	FinishedAt time.Time            `json:"finished_at"`
+	Total      int                  `json:"total"` // Files in the range
+	Ingested   int                  `json:"ingested"`
+	Duplicates int                  `json:"duplicates"`
+	Failed     int                  `json:"failed"`
+	Stopped    bool                 `json:"stopped,omitempty"` // A failure stopped the backfill before the end of the range
+	Canceled   bool                 `json:"canceled,omitempty"`
+	Files      []BackfillFileResult `json:"files"`
+	Report     string               `json:"report,omitempty"` // Path of the written report
+}
+
+// add records the outcome of a file in the summary
+func (s *BackfillSummary) add(result BackfillFileResult) {
+	switch result.Outcome {
+	case FileIngested:
+		s.Ingested++
+	case FileDuplicate:
+		s.Duplicates++
+	case FileFailed:
+		s.Failed++
+	}
+	s.Files = append(s.Files, result)
+}
+
+// BackfillWorkflow ingests the archived event logs of a date range, oldest first, so months of history can be
+// loaded with a single run. Dedup is strict: content that the ingest ledger records as fully ingested, or that
+// appears earlier in the run under another name, is skipped, and each file is ingested by an
+// IngestFileWorkflow child with the ID of its content. Files are ingested one at a time, so events are applied
+// in chronological order; the first failure stops the backfill unless ContinueOnError is set. The summary of
+// all files is written to REPORT_DIR and returned.
+func BackfillWorkflow(ctx workflow.Context, req BackfillRequest) (BackfillSummary, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting backfill workflow", "source", req.Source, "from", req.From, "to", req.To, "next", req.Next)
+
+	if req.From.IsZero() || req.To.IsZero() || req.To.Before(req.From) {
+		return BackfillSummary{}, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("invalid backfill range %s to %s", req.From.Format(time.DateOnly), req.To.Format(time.DateOnly)),
+			ErrTypeInvalidBackfill, nil)
+	}
+	ctx = workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	if err := workflow.SetQueryHandler(ctx, ProgressQuery, func() (BackfillSummary, error) {
+		return req.Summary, nil
+	}); err != nil {
+		return req.Summary, err
+	}
+
+	// The first run lists the range, later runs continue with the list it carried over
+	if req.Summary.StartedAt.IsZero() {
+		req.Summary = BackfillSummary{
+			WorkflowID: workflow.GetInfo(ctx).WorkflowExecution.ID,
+			Source:     req.Source,
+			From:       req.From,
+			To:         req.To,
+			StartedAt:  workflow.Now(ctx),
+		}
+		if err := workflow.ExecuteActivity(ctx, "ListArchiveActivity", req.Source, req.From, req.To).Get(ctx, &req.Files); err != nil {
+			logger.Error("Failed to list archive", "error", err)
+			return req.Summary, err
+		}
+		req.Summary.Total = len(req.Files)
+		logger.Info("Listed archive", "files", len(req.Files))
+	}
+
+	// Downloads of large objects outlive the default timeout, with heartbeats
+	fetchOptions := defaultActivityOptions()
+	fetchOptions.StartToCloseTimeout = time.Hour
+	fetchOptions.HeartbeatTimeout = 2 * time.Minute
+	fetchCtx := workflow.WithActivityOptions(ctx, fetchOptions)
+
+	// Content hash -> name of the first file of the range with that content, failed files may be retried
+	seen := make(map[string]string, len(req.Summary.Files))
+	for _, result := range req.Summary.Files {
+		if result.ContentHash != "" && result.Outcome != FileFailed {
+			if _, ok := seen[result.ContentHash]; !ok {
+				seen[result.ContentHash] = result.Name
+			}
+		}
+	}
+
+	for run := 0; req.Next < len(req.Files); run++ {
+		if run == backfillFilesPerRun {
+			// Keep the history of a long backfill bounded
+			logger.Info("Continuing backfill as new", "next", req.Next)
+			return req.Summary, workflow.NewContinueAsNewError(ctx, BackfillWorkflow, req)
+		}
+
+		file := req.Files[req.Next]
+		result := backfillFile(ctx, fetchCtx, req, file, seen)
+		req.Summary.add(result)
+		req.Next++
+		if result.ContentHash != "" && result.Outcome != FileFailed {
+			if _, ok := seen[result.ContentHash]; !ok {
+				seen[result.ContentHash] = file.Name
+			}
+		}
+
+		if ctx.Err() != nil {
+			logger.Info("Backfill workflow canceled", "file", file.Name)
+			req.Summary.Canceled = true
+			writeBackfillReport(ctx, &req.Summary)
+			return req.Summary, temporal.NewCanceledError()
+		}
+		if result.Outcome == FileFailed && !req.ContinueOnError {
+			logger.Error("Stopping backfill on failed file", "file", file.Name, "error", result.Error)
+			req.Summary.Stopped = true
+			break
+		}
+	}
+
+	writeBackfillReport(ctx, &req.Summary)
+	logger.Info("Completed backfill workflow", "ingested", req.Summary.Ingested,
+		"duplicates", req.Summary.Duplicates, "failed", req.Summary.Failed)
+	return req.Summary, nil
+}
+
+// backfillFile fetches and ingests one file of a backfill, unless its content was already ingested
+func backfillFile(ctx, fetchCtx workflow.Context, req BackfillRequest, file archive.File, seen map[string]string) BackfillFileResult {
+	logger := workflow.GetLogger(ctx)
+	result := BackfillFileResult{Name: file.Name, Date: file.Date}
+
+	var fetched BackfillFile
+	if err := workflow.ExecuteActivity(fetchCtx, "FetchArchiveFileActivity", req.Source, file.Name).Get(ctx, &fetched); err != nil {
+		logger.Error("Failed to fetch archived file", "file", file.Name, "error", err)
+		result.Outcome, result.Error = FileFailed, err.Error()
+		return result
+	}
+	result.ContentHash = fetched.ContentHash
+
+	if earlier, ok := seen[fetched.ContentHash]; ok {
+		logger.Info("Skipping duplicate content", "file", file.Name, "sameAs", earlier)
+		result.Outcome, result.WorkflowID = FileDuplicate, ScopedWorkflowID(req.WorkflowIDScope, IngestWorkflowID(fetched.ContentHash))
+		return result
+	}
+	var previous IngestedFileInfo
+	if err := workflow.ExecuteActivity(ctx, "LookupIngestedFileActivity", fetched.ContentHash).Get(ctx, &previous); err != nil {
+		logger.Error("Failed to check ingest ledger", "file", file.Name, "error", err)
+		result.Outcome, result.Error = FileFailed, err.Error()
+		return result
+	}
+	if previous.Outcome == IngestOutcomeCompleted {
+		logger.Info("Skipping already ingested content", "file", file.Name, "workflowID", previous.WorkflowID)
+		result.Outcome, result.WorkflowID = FileDuplicate, previous.WorkflowID
+		return result
+	}
+
+	childOptions := workflow.ChildWorkflowOptions{
+		WorkflowID:            ScopedWorkflowID(req.WorkflowIDScope, IngestWorkflowID(fetched.ContentHash)),
+		WorkflowIDReusePolicy: enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE_FAILED_ONLY,
+		// On cancellation, wait for the ingest to checkpoint and report its run
+		WaitForCancellation: true,
+	}
+	result.WorkflowID = childOptions.WorkflowID
+	logger.Info("Ingesting archived file", "file", file.Name, "path", fetched.Path, "workflowID", childOptions.WorkflowID)
+	childCtx := workflow.WithChildOptions(ctx, childOptions)
+	err := workflow.ExecuteChildWorkflow(childCtx, IngestFileWorkflow, IngestRequest{
+		FilePath:       fetched.Path,
+		ContentHash:    fetched.ContentHash,
+		ZoneTaskQueues: req.ZoneTaskQueues,
+		HCS:            req.HCS,
+		Zones:          req.Zones,
+		MaxMints:       req.MaxMints,
+		BudgetTinybar:  req.BudgetTinybar,
+		BudgetUSD:      req.BudgetUSD,
+		Notify:         req.Notify,
+		FailureAlert:   req.FailureAlert,
+		EmailReport:    req.EmailReport,
+		ArchiveReport:  req.ArchiveReport,
+		EscalateAfter:  req.EscalateAfter,
+
+		VisibilityTimeout: req.VisibilityTimeout,
+	}).Get(ctx, nil)
+	switch {
+	case temporal.IsWorkflowExecutionAlreadyStartedError(err):
+		// The ID of an ingest is taken by a run of the same content, by another backfill or by mintDomains
+		logger.Info("Skipping content ingested by another run", "file", file.Name, "workflowID", childOptions.WorkflowID)
+		result.Outcome = FileDuplicate
+	case err != nil:
+		logger.Error("Failed to ingest archived file", "file", file.Name, "error", err)
+		result.Outcome, result.Error = FileFailed, err.Error()
+	default:
+		result.Outcome = FileIngested
+	}
+	return result
+}
+
+// writeBackfillReport writes the summary of a backfill to REPORT_DIR, also when the backfill was canceled
+func writeBackfillReport(ctx workflow.Context, summary *BackfillSummary) {
+	summary.FinishedAt = workflow.Now(ctx)
+	reportCtx, _ := workflow.NewDisconnectedContext(ctx)
+	if err := workflow.ExecuteActivity(reportCtx, "WriteBackfillReportActivity", *summary).Get(reportCtx, &summary.Report); err != nil {
+		workflow.GetLogger(ctx).Error("Failed to write backfill report", "error", err)
+	}
+}
+
+// archiveSource opens an archive location with the object storage settings of the configuration
+func (a *Activities) archiveSource(location string) (archive.Source, error) {
+	return archive.New(location, a.s3Options())
+}
+
+// s3Options returns the object storage settings of the configuration, ARCHIVE_S3_*
+func (a *Activities) s3Options() archive.S3Options {
+	return archive.S3Options{
+		Endpoint:        a.Config.Archive.S3Endpoint,
+		Region:          a.Config.Archive.S3Region,
+		AccessKeyID:     a.Config.Archive.S3AccessKeyID,
+		SecretAccessKey: a.Config.Archive.S3SecretAccessKey,
+	}
+}
+
+// ListArchiveActivity returns the files of an archive dated within a range of days, in chronological order
+func (a *Activities) ListArchiveActivity(ctx context.Context, location string, from, to time.Time) ([]archive.File, error) {
+	source, err := a.archiveSource(location)
+	if err != nil {
+		return nil, temporal.NewNonRetryableApplicationError(fmt.Sprintf("invalid archive %s", location), ErrTypeInvalidBackfill, err)
+	}
+	files, err := source.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archive %s: %w", source, err)
+	}
+	return archive.InRange(files, from, to), nil
+}
+
+// FetchArchiveFileActivity makes a file of an archive available to the ingest and hashes its content.
+// Files of a local archive are ingested in place; objects are downloaded to ARCHIVE_STAGING_DIR, named after
+// their content hash so a download is kept across retries and backfills.
+func (a *Activities) FetchArchiveFileActivity(ctx context.Context, location, name string) (BackfillFile, error) {
+	source, err := a.archiveSource(location)
+	if err != nil {
+		return BackfillFile{}, temporal.NewNonRetryableApplicationError(fmt.Sprintf("invalid archive %s", location), ErrTypeInvalidBackfill, err)
+	}
+	if dir, ok := source.(archive.Dir); ok {
+		filePath := dir.Path(name)
+		contentHash, err := HashFile(filePath)
+		if err != nil {
+			return BackfillFile{}, err
+		}
+		return BackfillFile{Name: name, Path: filePath, ContentHash: contentHash}, nil
+	}
+
+	if err := os.MkdirAll(a.Config.Archive.StagingDir, 0755); err != nil {
+		return BackfillFile{}, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	r, err := source.Open(ctx, name)
+	if err != nil {
+		return BackfillFile{}, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	defer r.Close()
+	tmp, err := os.CreateTemp(a.Config.Archive.StagingDir, ".download-*")
+	if err != nil {
+		return BackfillFile{}, fmt.Errorf("failed to create staging file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h, heartbeatWriter{ctx}), r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return BackfillFile{}, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+
+	contentHash := hex.EncodeToString(h.Sum(nil))
+	filePath := filepath.Join(a.Config.Archive.StagingDir, contentHash+"-"+path.Base(name))
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return BackfillFile{}, fmt.Errorf("failed to stage %s: %w", name, err)
+	}
+	fmt.Printf("Downloaded %s from %s to %s\n", name, source, filePath)
+	return BackfillFile{Name: name, Path: filePath, ContentHash: contentHash}, nil
+}
+
+// heartbeatWriter records a heartbeat for every chunk of a download
+type heartbeatWriter struct {
+	ctx context.Context
+}
+
+func (w heartbeatWriter) Write(p []byte) (int, error) {
+	heartbeat(w.ctx)
+	return len(p), nil
+}
+
+// WriteBackfillReportActivity writes the summary of a backfill to the report directory and returns its path
+func (a *Activities) WriteBackfillReportActivity(ctx context.Context, summary BackfillSummary) (string, error) {
+	if err := os.MkdirAll(a.Config.Reports.Dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal backfill report: %w", err)
+	}
+
+	// The workflow ID embeds the archive location, which may contain slashes
+	reportPath := filepath.Join(a.Config.Reports.Dir, fmt.Sprintf("backfill_%s_%s_%s.json",
+		summary.From.Format(time.DateOnly), summary.To.Format(time.DateOnly), summary.StartedAt.UTC().Format("20060102T150405Z")))
+	if err := os.WriteFile(reportPath, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write backfill report: %w", err)
+	}
+	fmt.Printf("Wrote backfill report to %s\n", reportPath)
+	return reportPath, nil
+}
@@ -0,0 +1,150 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/metadata"
+)
+
+// Application error types of collection branding
+const (
+	ErrTypeNoBranding    = "NoBranding"    // The zone has no branding to apply
+	ErrTypeNoMetadataKey = "NoMetadataKey" // The collection was created without a metadata key
+)
+
+// CollectionBrandingResult is the outcome of branding a zone collection
+type CollectionBrandingResult struct {
+	Zone          string `json:"zone"`
+	TokenID       string `json:"token_id"`
+	MetadataURI   string `json:"metadata_uri"`   // URI of the collection document, stored in the token metadata
+	TransactionID string `json:"transaction_id"` // Token update setting the metadata
+}
+
+// zoneBranding returns the branding of a zone collection, zero when COLLECTION_BRANDING_FILE is unset
+func (a *Activities) zoneBranding(zone string) (metadata.Branding, error) {
+	if a.Config.Metadata.BrandingFile == "" {
+		return metadata.Branding{}, nil
+	}
+	file, err := metadata.LoadBrandingFile(a.Config.Metadata.BrandingFile)
+	if err != nil {
+		return metadata.Branding{}, fmt.Errorf("failed to load branding: %w", err)
+	}
+	return file.For(zone), nil
+}
+
+// uploadCollectionDocument uploads the collection document of a zone and returns its URI
+func (a *Activities) uploadCollectionDocument(ctx context.Context, zone string, branding metadata.Branding) (string, error) {
+	store, err := a.metadataStore(ctx)
+	if err != nil {
+		return "", err
+	}
+	if store == nil {
+		return "", errors.New("collection branding requires METADATA_STORE")
+	}
+	doc := branding.Document(zone)
+	data, err := doc.Marshal()
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal collection document: %w", err)
+	}
+	uri, err := store.Put(ctx, data, doc.Tags())
+	if err != nil {
+		return "", fmt.Errorf("failed to upload collection document: %w", err)
+	}
+	if len(uri) > maxNFTMetadataSize {
+		return "", fmt.Errorf("collection document URI %s exceeds the %d bytes of token metadata", uri, maxNFTMetadataSize)
+	}
+	return uri, nil
+}
+
+// UpdateCollectionBrandingActivity uploads the collection document of a zone with its current branding and
+// points the token metadata of the zone collection to it. The token must have the operator key as metadata key,
+// which collections get at creation.
+func (a *Activities) UpdateCollectionBrandingActivity(ctx context.Context, zone string) (CollectionBrandingResult, error) {
+	result := CollectionBrandingResult{Zone: zone}
+	branding, err := a.zoneBranding(zone)
+	if err != nil {
+		return result, err
+	}
+	if branding.IsZero() {
+		return result, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("no branding for .%s in COLLECTION_BRANDING_FILE", zone), ErrTypeNoBranding, nil)
+	}
+	if result.TokenID, err = a.resolveZoneCollection(ctx, zone, ""); err != nil {
+		return result, err
+	}
+	token, err := a.tokenIDFromString(result.TokenID)
+	if err != nil {
+		return result, err
+	}
+	operatorID, privateKey, err := a.operatorCredentials()
+	if err != nil {
+		return result, err
+	}
+	if result.MetadataURI, err = a.uploadCollectionDocument(ctx, zone, branding); err != nil {
+		return result, err
+	}
+
+	client, err := a.newHederaClient()
+	if err != nil {
+		return result, err
+	}
+	defer client.Close()
+	client.SetOperator(operatorID, privateKey)
+
+	if err := a.txLimiter.Wait(ctx); err != nil {
+		return result, err
+	}
+	if workerStopping(ctx) {
+		return result, errWorkerShutdown("branding of ." + zone)
+	}
+	txResponse, err := hedera.NewTokenUpdateTransaction().
+		SetTokenID(token).
+		SetTokenMetadata([]byte(result.MetadataURI)).
+		Execute(client)
+	if err == nil {
+		_, err = txResponse.GetReceipt(client)
+	}
+	if isStatus(err, hedera.StatusTokenHasNoMetadataKey) {
+		return result, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("%s was created without a metadata key, its branding can only be set at creation", result.TokenID), ErrTypeNoMetadataKey, err)
+	}
+	if err != nil {
+		return result, fmt.Errorf("token update failed: %w", err)
+	}
+	result.TransactionID = txResponse.TransactionID.String()
+	fmt.Printf("Branded collection %s of .%s with %s\n", a.displayID(result.TokenID), zone, result.MetadataURI)
+
+	unlock, err := a.lockZoneRegistry()
+	if err != nil {
+		fmt.Printf("Warning: failed to record branding in zone registry: %v\n", err)
+		return result, nil
+	}
+	defer unlock()
+	if registry, err := a.loadZoneRegistry(); err == nil {
+		if collection, ok := registry.Collections[domain.Zone(zone)]; ok && collection.TokenID == result.TokenID {
+			collection.MetadataURI = result.MetadataURI
+			registry.Collections[domain.Zone(zone)] = collection
+			registry.LastUpdated = time.Now()
+			if err := a.saveZoneRegistry(registry); err != nil {
+				fmt.Printf("Warning: failed to record branding in zone registry: %v\n", err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// BrandCollectionWorkflow applies the current branding of COLLECTION_BRANDING_FILE to an existing zone collection
+func BrandCollectionWorkflow(ctx workflow.Context, zone string) (CollectionBrandingResult, error) {
+	ctx = workflow.WithActivityOptions(ctx, defaultActivityOptions())
+	var result CollectionBrandingResult
+	err := workflow.ExecuteActivity(ctx, "UpdateCollectionBrandingActivity", zone).Get(ctx, &result)
+	return result, err
+}
@@ -0,0 +1,177 @@
+package temporal
+
+import (
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Signals of the fee budget of a run (RUN_BUDGET_HBAR)
+const (
+	// BudgetSignal raises or lifts the budget of an ingest run or domain list import, resuming it when paused.
+	// Its payload is a BudgetRaise.
+	BudgetSignal = "budget"
+	// FeesSignal reports the fee of a mint or burn of a zone to its ingest run, in tinybar
+	FeesSignal = "fees"
+	// PauseSignal and ContinueSignal pause and continue a zone whose run has spent its budget
+	PauseSignal    = "pause"
+	ContinueSignal = "continue"
+)
+
+// BudgetRaise is the payload of BudgetSignal
+type BudgetRaise struct {
+	AddTinybar int64 `json:"add_tinybar"` // Added to the budget
+	Unlimited  bool  `json:"unlimited"`   // Lifts the budget instead
+}
+
+// apply returns the budget raised, 0 being unlimited
+func (r BudgetRaise) apply(budget int64) int64 {
+	if r.Unlimited || budget == 0 {
+		return 0
+	}
+	return budget + r.AddTinybar
+}
+
+// budgetSpent reports whether the fees reached a budget, 0 being unlimited
+func budgetSpent(fees, budget int64) bool {
+	return budget > 0 && fees >= budget
+}
+
+// resolveBudget returns the budget of a run in tinybar, converting a budget in US dollars at the current exchange
+// rate of the network. A budget in tinybar takes precedence, 0 is unlimited.
+func resolveBudget(ctx workflow.Context, budgetTinybar int64, budgetUSD float64) (int64, error) {
+	if budgetTinybar > 0 || budgetUSD <= 0 {
+		return budgetTinybar, nil
+	}
+	var rate ExchangeRate
+	if err := workflow.ExecuteActivity(ctx, "ExchangeRateActivity").Get(ctx, &rate); err != nil {
+		return 0, err
+	}
+	// A budget too small to buy a tinybar is still a budget
+	budget := max(rate.Tinybar(budgetUSD), 1)
+	workflow.GetLogger(ctx).Info("Converted budget", "budgetUSD", budgetUSD, "budgetTinybar", budget,
+		"centEquivalent", rate.CentEquivalent, "hbarEquivalent", rate.HbarEquivalent)
+	return budget, nil
+}
+
+// watchBudget adds up the fees signaled by the zones of an ingest run and pauses the zones that are still running
+// once the budget is spent, until a BudgetSignal raises the budget above the fees or lifts it. Mints in flight
+// when the budget is reached still complete, so the budget may be exceeded by their fees.
+func watchBudget(ctx workflow.Context, progress *IngestProgress) {
+	logger := workflow.GetLogger(ctx)
+	fees := workflow.GetSignalChannel(ctx, FeesSignal)
+	raises := workflow.GetSignalChannel(ctx, BudgetSignal)
+	paused := false
+	workflow.Go(ctx, func(ctx workflow.Context) {
+		for {
+			selector := workflow.NewSelector(ctx)
+			selector.AddReceive(fees, func(c workflow.ReceiveChannel, more bool) {
+				var fee int64
+				c.Receive(ctx, &fee)
+				progress.FeesTinybar += fee
+			})
+			selector.AddReceive(raises, func(c workflow.ReceiveChannel, more bool) {
+				var raise BudgetRaise
+				c.Receive(ctx, &raise)
+				progress.BudgetTinybar = raise.apply(progress.BudgetTinybar)
+				logger.Info("Budget raised", "budgetTinybar", progress.BudgetTinybar, "feesTinybar", progress.FeesTinybar)
+			})
+			selector.Select(ctx)
+
+			spent := budgetSpent(progress.FeesTinybar, progress.BudgetTinybar)
+			if spent == paused {
+				continue
+			}
+			paused = spent
+			signal, stage := ContinueSignal, StageMinting
+			if paused {
+				signal, stage = PauseSignal, StagePaused
+				logger.Warn("Budget spent, pausing the zones", "budgetTinybar", progress.BudgetTinybar, "feesTinybar", progress.FeesTinybar)
+			}
+			if progress.Stage == StageMinting || progress.Stage == StagePaused {
+				progress.Stage = stage
+			}
+			for _, zone := range progress.Zones {
+				if !zone.Done {
+					// Zones that finished meanwhile cannot be signaled, which is harmless
+					workflow.SignalExternalWorkflow(ctx, zone.WorkflowID, "", signal, nil)
+				}
+			}
+		}
+	})
+}
+
+// followPauses pauses the zone of a ProcessZoneWorkflow on PauseSignal and continues it on ContinueSignal
+func followPauses(ctx workflow.Context, progress *ZoneProgress) {
+	pauses := workflow.GetSignalChannel(ctx, PauseSignal)
+	continues := workflow.GetSignalChannel(ctx, ContinueSignal)
+	workflow.Go(ctx, func(ctx workflow.Context) {
+		for {
+			selector := workflow.NewSelector(ctx)
+			selector.AddReceive(pauses, func(c workflow.ReceiveChannel, more bool) {
+				c.Receive(ctx, nil)
+				progress.Paused = true
+			})
+			selector.AddReceive(continues, func(c workflow.ReceiveChannel, more bool) {
+				c.Receive(ctx, nil)
+				progress.Paused = false
+			})
+			selector.Select(ctx)
+		}
+	})
+}
+
+// applyPendingPauses applies the PauseSignal and ContinueSignal received but not handled yet by followPauses,
+// which a ProcessZoneWorkflow continuing as new would lose. The run sends them alternately, so the one received
+// more often was sent last.
+func applyPendingPauses(ctx workflow.Context, progress *ZoneProgress) {
+	pauses, continues := 0, 0
+	for workflow.GetSignalChannel(ctx, PauseSignal).ReceiveAsync(nil) {
+		pauses++
+	}
+	for workflow.GetSignalChannel(ctx, ContinueSignal).ReceiveAsync(nil) {
+		continues++
+	}
+	switch {
+	case pauses > continues:
+		progress.Paused = true
+	case continues > pauses:
+		progress.Paused = false
+	}
+}
+
+// reportFee signals the fee of a mint or burn of a zone to its parent run
+func reportFee(ctx workflow.Context, fee int64) {
+	parent := workflow.GetInfo(ctx).ParentWorkflowExecution
+	if parent == nil || fee == 0 {
+		return
+	}
+	if err := workflow.SignalExternalWorkflow(ctx, parent.ID, "", FeesSignal, fee).Get(ctx, nil); err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to report fee to the run", "feeTinybar", fee, "error", err)
+	}
+}
+
+// awaitBudget blocks a run whose fees reached its budget until a BudgetSignal raises the budget above the fees
+// or lifts it. Signals received while the run was minting are applied first.
+func awaitBudget(ctx workflow.Context, fees int64, budget *int64) error {
+	raises := workflow.GetSignalChannel(ctx, BudgetSignal)
+	var raise BudgetRaise
+	for raises.ReceiveAsync(&raise) {
+		*budget = raise.apply(*budget)
+	}
+	if budgetSpent(fees, *budget) {
+		workflow.GetLogger(ctx).Warn("Budget spent, pausing the run", "budgetTinybar", *budget, "feesTinybar", fees)
+	}
+	for budgetSpent(fees, *budget) {
+		selector := workflow.NewSelector(ctx)
+		selector.AddReceive(raises, func(c workflow.ReceiveChannel, more bool) {
+			c.Receive(ctx, &raise)
+			*budget = raise.apply(*budget)
+		})
+		selector.AddReceive(ctx.Done(), func(workflow.ReceiveChannel, bool) {})
+		selector.Select(ctx)
+		if ctx.Err() != nil {
+			return temporal.NewCanceledError()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,375 @@
+package temporal
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/entityid"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/eventsig"
+)
+
+// ErrTypeClaimRejected is the application error type of claims that cannot succeed by retrying
+const ErrTypeClaimRejected = "ClaimRejected"
+
+// ClaimQuery is the name of the query answered by ClaimDomainWorkflow with its ClaimStatus
+const ClaimQuery = "claim"
+
+// Ways for a registrant to prove control of a domain
+const (
+	ClaimProofDNS         = "dns"         // A TXT record with the challenge of the claim
+	ClaimProofAttestation = "attestation" // A statement signed by the registrar of the domain
+)
+
+// Stages of a claim
+const (
+	ClaimStageLocating     = "locating"
+	ClaimStageAwaitProof   = "awaiting proof"
+	ClaimStageAssociation  = "awaiting association"
+	ClaimStageTransferring = "transferring"
+	ClaimStageClaimed      = "claimed"
+	ClaimStageFailed       = "failed"
+)
+
+// DefaultClaimTimeout is how long a claim waits for its DNS challenge when the request does not say
+const DefaultClaimTimeout = 72 * time.Hour
+
+// claimPollInterval is the interval between lookups of the DNS challenge
+const claimPollInterval = 5 * time.Minute
+
+// ClaimRequest is the input of ClaimDomainWorkflow
+type ClaimRequest struct {
+	Domain      string
+	AccountID   string        // Hedera account of the registrant, receiving the NFT
+	Registrant  string        // Handle of the registrant, resolved through the account registry when AccountID is empty
+	Method      string        // ClaimProofDNS or ClaimProofAttestation
+	Attestation string        // Detached JWS of the registrar over ClaimAttestationPayload, for ClaimProofAttestation
+	Timeout     time.Duration // How long to wait for the DNS challenge, DefaultClaimTimeout if zero
+	Association AssociationOptions
+}
+
+// ClaimStatus is the state of a claim, returned by ClaimDomainWorkflow and its claim query
+type ClaimStatus struct {
+	Domain          string `json:"domain"`
+	AccountID       string `json:"account_id"`
+	Registrant      string `json:"registrant,omitempty"`
+	Method          string `json:"method"`
+	Stage           string `json:"stage"`
+	TokenID         string `json:"token_id,omitempty"`
+	SerialNumber    int64  `json:"serial_number,omitempty"`
+	ChallengeRecord string `json:"challenge_record,omitempty"` // Name of the TXT record proving control
+	ChallengeValue  string `json:"challenge_value,omitempty"`  // Value the TXT record must hold
+	ProofDetail     string `json:"proof_detail,omitempty"`
+	Association     string `json:"association,omitempty"`    // What the registrant must do to associate the token
+	TransactionID   string `json:"transaction_id,omitempty"` // Transfer of the NFT to the registrant
+	Error           string `json:"error,omitempty"`
+}
+
+// DomainNFT is the NFT of a domain and its current owner
+type DomainNFT struct {
+	Domain            string `json:"domain"`
+	TokenID           string `json:"token_id"`
+	SerialNumber      int64  `json:"serial_number"`
+	OwnerAccountID    string `json:"owner_account_id"`
+	TreasuryAccountID string `json:"treasury_account_id"`
+}
+
+// ChallengeRecord returns the name of the TXT record holding the claim challenge of a domain
+func ChallengeRecord(domainName string) string {
+	return "_sdl-claim." + domainName
+}
+
+// ClaimAttestationPayload returns the bytes a registrar signs to attest that an account holder registered a domain
+func ClaimAttestationPayload(domainName, accountID string) []byte {
+	payload, _ := json.Marshal(struct {
+		Account string `json:"account"`
+		Domain  string `json:"domain"`
+	}{accountID, domainName})
+	return payload
+}
+
+// ClaimDomainWorkflow hands the NFT of a domain over to its registrant: the registrant proves control of the
+// domain with a DNS TXT challenge or a registrar attestation, and the treasury transfers the NFT to their account.
+// The account must be associated with the zone collection, see ensureTokenAssociation.
+func ClaimDomainWorkflow(ctx workflow.Context, req ClaimRequest) (ClaimStatus, error) {
+	logger := workflow.GetLogger(ctx)
+	ctx = workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	status := ClaimStatus{Domain: req.Domain, AccountID: req.AccountID, Registrant: req.Registrant, Method: req.Method, Stage: ClaimStageLocating}
+	if err := workflow.SetQueryHandler(ctx, ClaimQuery, func() (ClaimStatus, error) {
+		return status, nil
+	}); err != nil {
+		return status, err
+	}
+	fail := func(err error) (ClaimStatus, error) {
+		status.Stage = ClaimStageFailed
+		status.Error = err.Error()
+		return status, err
+	}
+
+	if req.AccountID == "" {
+		var mapping AccountMapping
+		if err := workflow.ExecuteActivity(ctx, "GetAccountMappingActivity", AccountKindRegistrant, req.Registrant).Get(ctx, &mapping); err != nil {
+			return fail(err)
+		}
+		req.AccountID, status.AccountID = mapping.AccountID, mapping.AccountID
+	}
+
+	var nft DomainNFT
+	if err := workflow.ExecuteActivity(ctx, "LocateDomainNFTActivity", req.Domain).Get(ctx, &nft); err != nil {
+		return fail(err)
+	}
+	status.TokenID, status.SerialNumber = nft.TokenID, nft.SerialNumber
+	switch nft.OwnerAccountID {
+	case req.AccountID:
+		status.Stage = ClaimStageClaimed
+		status.ProofDetail = "the account already holds the NFT"
+		return status, nil
+	case nft.TreasuryAccountID:
+	default:
+		return fail(fmt.Errorf("%s was already claimed by %s", req.Domain, nft.OwnerAccountID))
+	}
+
+	status.Stage = ClaimStageAwaitProof
+	switch req.Method {
+	case ClaimProofDNS:
+		// The challenge is random, so control of the record is proven for this claim only
+		var challenge string
+		if err := workflow.SideEffect(ctx, func(workflow.Context) interface{} {
+			b := make([]byte, 16)
+			rand.Read(b)
+			return hex.EncodeToString(b)
+		}).Get(&challenge); err != nil {
+			return fail(err)
+		}
+		status.ChallengeRecord = ChallengeRecord(req.Domain)
+		status.ChallengeValue = "sdl-claim=" + challenge
+
+		timeout := req.Timeout
+		if timeout <= 0 {
+			timeout = DefaultClaimTimeout
+		}
+		deadline := workflow.Now(ctx).Add(timeout)
+		for {
+			var found bool
+			if err := workflow.ExecuteActivity(ctx, "CheckDNSChallengeActivity", status.ChallengeRecord, status.ChallengeValue).Get(ctx, &found); err != nil {
+				logger.Warn("DNS challenge lookup failed", "record", status.ChallengeRecord, "error", err)
+			}
+			if found {
+				status.ProofDetail = fmt.Sprintf("TXT record %s holds the challenge", status.ChallengeRecord)
+				break
+			}
+			if !workflow.Now(ctx).Before(deadline) {
+				return fail(temporal.NewNonRetryableApplicationError(
+					fmt.Sprintf("the challenge was not published in %s within %s", status.ChallengeRecord, timeout), ErrTypeClaimRejected, nil))
+			}
+			if err := workflow.Sleep(ctx, claimPollInterval); err != nil {
+				return fail(err)
+			}
+		}
+	case ClaimProofAttestation:
+		var keyID string
+		if err := workflow.ExecuteActivity(ctx, "VerifyClaimAttestationActivity", req.Domain, req.AccountID, req.Attestation).Get(ctx, &keyID); err != nil {
+			return fail(err)
+		}
+		status.ProofDetail = "attested by registrar key " + keyID
+	default:
+		return fail(fmt.Errorf("unknown proof method %q (expected %s or %s)", req.Method, ClaimProofDNS, ClaimProofAttestation))
+	}
+
+	// Transfers to accounts that cannot hold the token would fail with TOKEN_NOT_ASSOCIATED_TO_ACCOUNT
+	status.Stage = ClaimStageAssociation
+	if err := ensureTokenAssociation(ctx, req.AccountID, nft.TokenID, req.Association, func(association TokenAssociation) {
+		status.Association = association.Detail
+	}); err != nil {
+		return fail(err)
+	}
+	status.Association = ""
+
+	// The transfer must be seen through once started
+	status.Stage = ClaimStageTransferring
+	uncancelableCtx, _ := workflow.NewDisconnectedContext(ctx)
+	if err := workflow.ExecuteActivity(uncancelableCtx, "TransferNFTActivity", nft, req.AccountID).Get(uncancelableCtx, &status.TransactionID); err != nil {
+		return fail(err)
+	}
+	status.Stage = ClaimStageClaimed
+	logger.Info("Domain claimed", "domain", req.Domain, "account", req.AccountID, "transaction", status.TransactionID)
+	return status, nil
+}
+
+// LocateDomainNFTActivity finds the NFT of a domain in its zone collection, with its owner and the collection treasury
+func (a *Activities) LocateDomainNFTActivity(ctx context.Context, domainName string) (DomainNFT, error) {
+	dn, err := domain.NewDomainName(domainName)
+	if err != nil {
+		return DomainNFT{}, temporal.NewNonRetryableApplicationError(fmt.Sprintf("invalid domain name: %v", err), ErrTypeClaimRejected, err)
+	}
+	zone := dn.ParentDomain()
+	if zone == "" {
+		return DomainNFT{}, temporal.NewNonRetryableApplicationError(dn.String()+" is a zone, not a domain within a zone", ErrTypeClaimRejected, nil)
+	}
+	tokenIDs, err := a.zoneTokenIDs(ctx, zone)
+	if err != nil {
+		return DomainNFT{}, fmt.Errorf("no collection for .%s: %w", zone, err)
+	}
+	nft, found, err := a.searchForDomainInShards(ctx, tokenIDs, dn.Label())
+	if err != nil {
+		return DomainNFT{}, err
+	}
+	if !found {
+		return DomainNFT{}, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("%s has no NFT in %s", dn.String(), a.displayID(tokenIDs[0])), ErrTypeClaimRejected, nil)
+	}
+	tokenID := nft.TokenID
+	var token MirrorNodeToken
+	if err := a.mirrorGet(ctx, "/tokens/"+tokenID, &token); err != nil {
+		return DomainNFT{}, err
+	}
+	return DomainNFT{
+		Domain:            dn.String(),
+		TokenID:           tokenID,
+		SerialNumber:      nft.SerialNumber,
+		OwnerAccountID:    nft.AccountID,
+		TreasuryAccountID: token.TreasuryAccountID,
+	}, nil
+}
+
+// CheckDNSChallengeActivity reports whether a TXT record holds the challenge value. A missing record is not an error.
+func (a *Activities) CheckDNSChallengeActivity(ctx context.Context, record, value string) (bool, error) {
+	records, err := net.DefaultResolver.LookupTXT(ctx, record)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look up %s: %w", record, err)
+	}
+	for _, txt := range records {
+		if strings.TrimSpace(txt) == value {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// VerifyClaimAttestationActivity checks a registrar attestation of a claim against the registrar keys
+// and returns the ID of the signing key
+func (a *Activities) VerifyClaimAttestationActivity(ctx context.Context, domainName, accountID, attestation string) (string, error) {
+	if a.Config.Claims.KeysFile == "" {
+		return "", temporal.NewNonRetryableApplicationError("registrar attestations are not accepted (CLAIM_KEYS_FILE is not set)", ErrTypeClaimRejected, nil)
+	}
+	keys, err := eventsig.LoadKeySet(a.Config.Claims.KeysFile)
+	if err != nil {
+		return "", err
+	}
+	keyID, err := keys.Verify(ClaimAttestationPayload(domainName, accountID), strings.TrimSpace(attestation))
+	if err != nil {
+		return "", temporal.NewNonRetryableApplicationError(fmt.Sprintf("invalid attestation: %v", err), ErrTypeClaimRejected, err)
+	}
+	return keyID, nil
+}
+
+// TransferNFTActivity transfers the NFT of a domain from the treasury to an account and returns the transaction ID.
+// An NFT already held by the account is not transferred again.
+func (a *Activities) TransferNFTActivity(ctx context.Context, nft DomainNFT, toAccountID string) (string, error) {
+	operatorID, privateKey, err := a.operatorCredentials()
+	if err != nil {
+		return "", err
+	}
+	to, err := entityid.ParseAccount(toAccountID, a.network())
+	if err != nil {
+		return "", temporal.NewNonRetryableApplicationError(fmt.Sprintf("invalid account: %v", err), ErrTypeClaimRejected, err)
+	}
+	tokenID, err := a.tokenIDFromString(nft.TokenID)
+	if err != nil {
+		return "", err
+	}
+
+	// A retry of a transfer that went through finds the NFT with its new owner
+	var current MirrorNodeNFT
+	if err := a.mirrorGet(ctx, fmt.Sprintf("/tokens/%s/nfts/%d", nft.TokenID, nft.SerialNumber), &current); err != nil {
+		return "", err
+	}
+	if current.AccountID == to.String() {
+		return "", nil
+	}
+	if current.AccountID != operatorID.String() {
+		return "", temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("serial %d of %s is held by %s, not the treasury", nft.SerialNumber, a.displayID(nft.TokenID), current.AccountID), ErrTypeClaimRejected, nil)
+	}
+
+	client, err := a.newHederaClient()
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+	client.SetOperator(operatorID, privateKey)
+
+	if err := a.txLimiter.Wait(ctx); err != nil {
+		return "", err
+	}
+	if workerStopping(ctx) {
+		return "", errWorkerShutdown(fmt.Sprintf("transfer of %s", nft.Domain))
+	}
+	txResponse, err := hedera.NewTransferTransaction().
+		AddNftTransfer(hedera.NftID{TokenID: tokenID, SerialNumber: nft.SerialNumber}, operatorID, to).
+		SetTransactionMemo(claimMemo(nft.Domain)).
+		Execute(client)
+	if err == nil {
+		heartbeat(ctx, "submitted", txResponse.TransactionID.String())
+		_, err = txResponse.GetReceipt(client)
+	}
+	if err != nil {
+		if isStatus(err, hedera.StatusTokenNotAssociatedToAccount) {
+			return "", temporal.NewNonRetryableApplicationError(
+				fmt.Sprintf("%s is not associated with %s: associate the token with the account, then claim again", to, a.displayID(nft.TokenID)), ErrTypeClaimRejected, err)
+		}
+		return "", fmt.Errorf("transfer failed: %w", err)
+	}
+	fmt.Printf("Transferred %s (serial %d of %s) to %s\n", nft.Domain, nft.SerialNumber, a.displayID(nft.TokenID), to)
+	_, zone, _ := strings.Cut(nft.Domain, ".")
+	a.publishLedgerEvent(ctx, LedgerEvent{
+		Type:          LedgerEventTransfer,
+		Domain:        nft.Domain,
+		Zone:          zone,
+		TokenID:       nft.TokenID,
+		SerialNumber:  nft.SerialNumber,
+		TransactionID: txResponse.TransactionID.String(),
+		At:            time.Now(),
+		Account:       to.String(),
+	})
+	return txResponse.TransactionID.String(), nil
+}
+
+// claimMemo returns the memo of the transfer of a claimed NFT to its registrant, by which treasury audits tell
+// claims from other transfers out of the treasury
+func claimMemo(domainName string) string {
+	memo := "sdl claim " + domainName
+	if len(memo) > maxMemoSize {
+		memo = memo[:maxMemoSize]
+	}
+	return memo
+}
+
+// isStatus reports whether a Hedera precheck or receipt error has the given status
+func isStatus(err error, status hedera.Status) bool {
+	var precheck hedera.ErrHederaPreCheckStatus
+	if errors.As(err, &precheck) {
+		return precheck.Status == status
+	}
+	var receipt hedera.ErrHederaReceiptStatus
+	if errors.As(err, &receipt) {
+		return receipt.Status == status
+	}
+	return false
+}
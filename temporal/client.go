@@ -0,0 +1,75 @@
+package temporal
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.temporal.io/sdk/client"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+)
+
+// ClientOptions returns the Temporal client options for the configured cluster,
+// including API key or mTLS authentication for Temporal Cloud
+func ClientOptions(cfg *config.Config) (client.Options, error) {
+	t := cfg.Temporal
+	options := client.Options{
+		HostPort:  t.Address,
+		Namespace: t.Namespace,
+		Identity:  t.Identity,
+	}
+	if !t.TLS() {
+		return options, nil
+	}
+
+	tlsConfig := &tls.Config{ServerName: t.TLSServerName}
+	if t.TLSCAFile != "" {
+		pem, err := os.ReadFile(t.TLSCAFile)
+		if err != nil {
+			return options, fmt.Errorf("failed to read TEMPORAL_TLS_CA: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return options, errors.New("TEMPORAL_TLS_CA: no certificates found")
+		}
+	}
+	options.ConnectionOptions.TLS = tlsConfig
+
+	switch {
+	case t.APIKey != "":
+		options.Credentials = client.NewAPIKeyStaticCredentials(t.APIKey)
+	case t.TLSCertFile != "":
+		cert, err := tls.LoadX509KeyPair(t.TLSCertFile, t.TLSKeyFile)
+		if err != nil {
+			return options, fmt.Errorf("failed to load TEMPORAL_TLS_CERT/TEMPORAL_TLS_KEY: %w", err)
+		}
+		options.Credentials = client.NewMTLSCredentials(cert)
+	}
+	return options, nil
+}
+
+// Dial connects to the configured Temporal cluster
+func Dial(cfg *config.Config) (client.Client, error) {
+	options, err := ClientOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return client.Dial(options)
+}
+
+// authMode describes how the connection to Temporal is authenticated
+func authMode(t config.TemporalConfig) string {
+	switch {
+	case t.APIKey != "":
+		return "API key"
+	case t.TLSCertFile != "":
+		return "mTLS"
+	case t.TLS():
+		return "TLS"
+	default:
+		return "plaintext"
+	}
+}
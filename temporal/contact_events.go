@@ -0,0 +1,142 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/eventschema"
+)
+
+// ContactRecordVersion is the version of the contact record format
+const ContactRecordVersion = 1
+
+// Actions of contact events, others are not recorded
+const (
+	ContactCreated = "create"
+	ContactUpdated = "update"
+)
+
+// ContactEvent is a contact created or updated at the registry, identified by the fingerprint of its handle
+type ContactEvent struct {
+	Handle      string `json:"handle_fp"` // Fingerprint of the handle, never the handle itself
+	Zone        string `json:"zone"`      // Zone of the registry that sent the event
+	Action      string `json:"action"`
+	RegistrarID string `json:"registrar,omitempty"`
+	Timestamp   string `json:"timestamp,omitempty"` // As written by the registry
+	LineNumber  int    `json:"line"`
+}
+
+// ContactRecord is the message recording a contact event on the contact topic. It holds no personal data: the
+// handle is fingerprinted, and the hash of the event is left out, as the few fields of a contact event could be
+// guessed back from it.
+type ContactRecord struct {
+	Version   int    `json:"v"`
+	Type      string `json:"type"` // Always "contact"
+	Action    string `json:"action"`
+	Handle    string `json:"handle_fp"`
+	Zone      string `json:"zone"`
+	Registrar string `json:"registrar,omitempty"`
+	Timestamp string `json:"timestamp,omitempty"`
+}
+
+// ParseContactEventsActivity parses the contact events of event log lines, with their signature verified as for
+// domain events, and replaces their handles by fingerprints under REGISTRANT_FINGERPRINT_KEY_FILE: the handles
+// never leave the activity. A contact shares its fingerprint with the registrations it is the registrant of.
+// Lines that are not contact events are left out, and so are contact events that are not a create or update.
+func (a *Activities) ParseContactEventsActivity(ctx context.Context, lines []string) ([]ContactEvent, error) {
+	parser, err := a.eventParser()
+	if err != nil {
+		return nil, err
+	}
+	if parser.fingerprintKey == nil {
+		return nil, errors.New("contact events require REGISTRANT_FINGERPRINT_KEY_FILE, the key hashing their handles")
+	}
+	var events []ContactEvent
+	for i, line := range lines {
+		decoded, quarantined, err := parser.decode(line, i+1)
+		if err != nil || quarantined != nil || decoded.ObjectType() != eventschema.TypeContact {
+			continue
+		}
+		zone, err := domain.NewZone(decoded.Zone)
+		if err != nil {
+			fmt.Printf("Skipping contact event on line %d: %v\n", i+1, err)
+			continue
+		}
+		switch decoded.Action {
+		case ContactCreated, ContactUpdated:
+		default:
+			fmt.Printf("Skipping contact event on line %d: unknown action %q\n", i+1, decoded.Action)
+			continue
+		}
+		events = append(events, ContactEvent{
+			Handle:      parser.fingerprintKey.Sum(FingerprintRegistrant, decoded.DomainName),
+			Zone:        zone.String(),
+			Action:      decoded.Action,
+			RegistrarID: decoded.RegistrarID,
+			Timestamp:   decoded.Timestamp,
+			LineNumber:  i + 1,
+		})
+	}
+	return events, nil
+}
+
+// PublishContactEventsActivity records contact events on the contact topic, creating the topic on first use.
+// As for host events, only the operator can submit to the topic, and the records are enveloped with
+// HCS_PRODUCER_KEY set.
+func (a *Activities) PublishContactEventsActivity(ctx context.Context, topicName string, events []ContactEvent) (int, error) {
+	topic, err := a.LookupOrCreateTopicActivity(ctx, topicName, "Contacts of the shadow domain ledger, by fingerprint", true, true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up contact topic: %w", err)
+	}
+	records := make([]any, len(events))
+	for i, event := range events {
+		records[i] = ContactRecord{
+			Version:   ContactRecordVersion,
+			Type:      eventschema.TypeContact,
+			Action:    event.Action,
+			Handle:    event.Handle,
+			Zone:      event.Zone,
+			Registrar: event.RegistrarID,
+			Timestamp: event.Timestamp,
+		}
+	}
+	recorded, err := a.publishAuditRecords(ctx, topic.TopicID, "contact records", records)
+	if err != nil {
+		return recorded, err
+	}
+	fmt.Printf("Recorded %d contact events on topic %s\n", len(events), a.displayID(topic.TopicID))
+	return recorded, nil
+}
+
+// recordContactEvents records the contact events of event log lines on the contact topic, when HCS_CONTACT_TOPIC
+// is set. firstLine and resumeFrom are as for recordHostEvents. Failures only warn, the registrations are minted
+// regardless.
+func recordContactEvents(ctx workflow.Context, hcs config.HCSConfig, zones config.ZonesConfig, lines []string, firstLine int, resumeFrom map[string]int) {
+	if hcs.ContactTopic == "" || len(lines) == 0 {
+		return
+	}
+	logger := workflow.GetLogger(ctx)
+	var parsed []ContactEvent
+	if err := workflow.ExecuteActivity(ctx, "ParseContactEventsActivity", lines).Get(ctx, &parsed); err != nil {
+		logger.Warn("Failed to parse contact events", "error", err)
+		return
+	}
+	var events []ContactEvent
+	for _, event := range parsed {
+		event.LineNumber += firstLine - 1
+		if zones.Allowed(event.Zone) && event.LineNumber > resumeFrom[event.Zone] {
+			events = append(events, event)
+		}
+	}
+	if len(events) == 0 {
+		return
+	}
+	if err := workflow.ExecuteActivity(ctx, "PublishContactEventsActivity", hcs.ContactTopic, events).Get(ctx, nil); err != nil {
+		logger.Warn("Failed to record contact events", "count", len(events), "error", err)
+	}
+}
@@ -0,0 +1,56 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/store"
+)
+
+// ErrNoRegistryStore is returned by the statistics computed in the registry store when none is configured
+var ErrNoRegistryStore = errors.New("statistics need the registry store, REGISTRY_STORE_DSN is not set")
+
+// statsStore returns the registry store, ErrNoRegistryStore if none is configured
+func (a *Activities) statsStore(ctx context.Context) (*store.Store, error) {
+	s, err := a.domainStore(ctx)
+	if err == nil && s == nil {
+		err = ErrNoRegistryStore
+	}
+	return s, err
+}
+
+// MintsPerDayActivity counts the domains minted per day and zone in the registry store
+func (a *Activities) MintsPerDayActivity(ctx context.Context, q store.StatsQuery) ([]store.DailyMints, error) {
+	s, err := a.statsStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.MintsPerDay(ctx, q)
+}
+
+// FailureRatesActivity returns the failure rate of every zone in the runs recorded in the registry store
+func (a *Activities) FailureRatesActivity(ctx context.Context, q store.StatsQuery) ([]store.ZoneFailures, error) {
+	s, err := a.statsStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.FailureRates(ctx, q)
+}
+
+// FeesPerDayActivity sums the fees of the runs recorded in the registry store per day and zone
+func (a *Activities) FeesPerDayActivity(ctx context.Context, q store.StatsQuery) ([]store.DailyFees, error) {
+	s, err := a.statsStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.FeesPerDay(ctx, q)
+}
+
+// TopRegistrarsActivity returns the registrars with the most domains minted, at most limit of them
+func (a *Activities) TopRegistrarsActivity(ctx context.Context, q store.StatsQuery, limit int) ([]store.RegistrarCount, error) {
+	s, err := a.statsStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.TopRegistrars(ctx, q, limit)
+}
@@ -0,0 +1,45 @@
+package temporal
+
+import (
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+)
+
+// GeneratedLabel tags a domain whose label looks algorithmically generated, its DGA score reaching DGA_THRESHOLD
+type GeneratedLabel struct {
+	Domain      string  `json:"domain"`
+	Zone        string  `json:"zone"`
+	LineNumber  int     `json:"line_number"`
+	RegistrarID string  `json:"registrar_id,omitempty"`
+	Score       float64 `json:"score"` // See domain.DGAScore, from 0 to 1
+}
+
+// generatedLabel returns the tag of a domain whose label scores DGA_THRESHOLD or more, or nil if it scores less
+// or the check is off
+func (a *Activities) generatedLabel(info MintingInfo) *GeneratedLabel {
+	if a.Config.Events.DGAThreshold <= 0 {
+		return nil
+	}
+	name := domain.DomainName(info.DomainName)
+	score := domain.DGAScore(domain.Label(name.Label()))
+	if score < a.Config.Events.DGAThreshold {
+		return nil
+	}
+	return &GeneratedLabel{
+		Domain:      info.DomainName,
+		Zone:        info.Zone.String(),
+		LineNumber:  info.LineNumber,
+		RegistrarID: info.RegistrarID,
+		Score:       score,
+	}
+}
+
+// generatedLabels returns the domains tagged by generatedLabel, in the order of the file
+func generatedLabels(infos []MintingInfo) []GeneratedLabel {
+	var tagged []GeneratedLabel
+	for _, info := range infos {
+		if info.Generated != nil {
+			tagged = append(tagged, *info.Generated)
+		}
+	}
+	return tagged
+}
@@ -0,0 +1,369 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"go.temporal.io/sdk/client"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/entityid"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/metadata"
+)
+
+// minOperatorBalance is the balance below which the operator account is reported as unhealthy
+var minOperatorBalance = hedera.NewHbar(1)
+
+// CheckResult is the outcome of a single self-check
+type CheckResult struct {
+	Name    string `json:"name"`
+	OK      bool   `json:"ok"`
+	Skipped bool   `json:"skipped,omitempty"` // The check does not apply, it neither passes nor fails
+	Detail  string `json:"detail"`
+}
+
+// String renders the result as a single status line
+func (r CheckResult) String() string {
+	status := "✓"
+	switch {
+	case r.Skipped:
+		status = "-"
+	case !r.OK:
+		status = "✗"
+	}
+	return fmt.Sprintf("%s %-20s %s", status, r.Name, r.Detail)
+}
+
+// AllPassed returns true if every check that was not skipped succeeded
+func AllPassed(results []CheckResult) bool {
+	for _, r := range results {
+		if !r.OK && !r.Skipped {
+			return false
+		}
+	}
+	return true
+}
+
+// SelfCheck verifies that the environment is ready to run workflows: Temporal connectivity,
+// Hedera credentials, operator balance, mirror node reachability and registry file health.
+// temporalClient may be nil when dialing failed, in which case dialErr is reported.
+func SelfCheck(ctx context.Context, cfg *config.Config, temporalClient client.Client, dialErr error) []CheckResult {
+	a := NewActivities(cfg)
+
+	results := []CheckResult{a.checkTemporal(ctx, temporalClient, dialErr)}
+	credentials := a.checkCredentials()
+	results = append(results, credentials)
+	if credentials.OK {
+		results = append(results, a.checkOperatorBalance())
+	}
+	results = append(results, a.checkMirrorNode(ctx))
+	results = append(results, a.checkZoneRegistry(), a.checkTopicRegistry(), a.checkIngestLedger(), a.checkAccountRegistry(), a.checkEventKeys())
+	results = append(results, a.checkRegistryStore(ctx))
+	results = append(results, a.checkMetadataStore(ctx), a.checkBranding())
+	return results
+}
+
+// checkTemporal verifies the Temporal frontend is reachable and healthy
+func (a *Activities) checkTemporal(ctx context.Context, temporalClient client.Client, dialErr error) CheckResult {
+	result := CheckResult{Name: "temporal"}
+	if temporalClient == nil {
+		result.Detail = fmt.Sprintf("unable to connect to %s: %v", a.Config.Temporal.Address, dialErr)
+		return result
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if _, err := temporalClient.CheckHealth(ctx, &client.CheckHealthRequest{}); err != nil {
+		result.Detail = fmt.Sprintf("health check failed: %v", err)
+		return result
+	}
+	result.OK = true
+	result.Detail = fmt.Sprintf("connected to %s (%s), namespace %s, task queue %s",
+		a.Config.Temporal.Address, authMode(a.Config.Temporal), a.Config.Temporal.Namespace, a.Config.Temporal.TaskQueue)
+	return result
+}
+
+// checkCredentials verifies the operator credentials are present and well formed
+func (a *Activities) checkCredentials() CheckResult {
+	result := CheckResult{Name: "hedera credentials"}
+	accountID, privateKey, err := a.operatorCredentials()
+	if err != nil {
+		result.Detail = err.Error()
+		return result
+	}
+	result.OK = true
+	result.Detail = fmt.Sprintf("operator %s on %s (public key %s…)",
+		a.displayID(accountID.String()), a.network(), privateKey.PublicKey().String()[:16])
+	return result
+}
+
+// checkOperatorBalance verifies the operator account exists and can pay for transactions
+func (a *Activities) checkOperatorBalance() CheckResult {
+	result := CheckResult{Name: "operator balance"}
+	accountID, _, err := a.operatorCredentials()
+	if err != nil {
+		result.Detail = err.Error()
+		return result
+	}
+	client, err := a.newHederaClient()
+	if err != nil {
+		result.Detail = err.Error()
+		return result
+	}
+	defer client.Close()
+
+	balance, err := hedera.NewAccountBalanceQuery().SetAccountID(accountID).Execute(client)
+	if err != nil {
+		result.Detail = fmt.Sprintf("balance query failed: %v", err)
+		return result
+	}
+	result.Detail = balance.Hbars.String()
+	if balance.Hbars.AsTinybar() < minOperatorBalance.AsTinybar() {
+		result.Detail += fmt.Sprintf(" (below minimum of %s)", minOperatorBalance.String())
+		return result
+	}
+	result.OK = true
+	return result
+}
+
+// checkMirrorNode verifies the mirror node REST API is reachable
+func (a *Activities) checkMirrorNode(ctx context.Context) CheckResult {
+	result := CheckResult{Name: "mirror node"}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := a.newMirrorRequest(ctx, a.Config.Mirror.BaseURL+"/blocks?limit=1")
+	if err != nil {
+		result.Detail = err.Error()
+		return result
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		result.Detail = fmt.Sprintf("unreachable: %v", err)
+		return result
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		result.Detail = fmt.Sprintf("%s rejected the request with status %d, check MIRROR_NODE_API_KEY and MIRROR_NODE_HEADERS", a.Config.Mirror.BaseURL, resp.StatusCode)
+		return result
+	case resp.StatusCode != http.StatusOK:
+		result.Detail = fmt.Sprintf("%s returned status %d", a.Config.Mirror.BaseURL, resp.StatusCode)
+		return result
+	}
+	result.OK = true
+	result.Detail = a.Config.Mirror.BaseURL
+	if a.Config.Mirror.APIKey != "" {
+		result.Detail += " (with API key)"
+	}
+	if a.Config.Mirror.GRPCAddress != "" {
+		result.Detail += ", gRPC " + a.Config.Mirror.GRPCAddress
+	}
+	return result
+}
+
+// checkZoneRegistry verifies the zone registry file can be loaded and holds valid token IDs
+func (a *Activities) checkZoneRegistry() CheckResult {
+	result := CheckResult{Name: "zone registry"}
+	registry, err := a.loadZoneRegistry()
+	if err != nil {
+		result.Detail = fmt.Sprintf("%s: %v", a.Config.Registry.ZoneFile, err)
+		return result
+	}
+	for zone, collection := range registry.Collections {
+		if _, err := a.tokenIDFromString(collection.TokenID); err != nil {
+			result.Detail = fmt.Sprintf("%s: zone .%s: %v", a.Config.Registry.ZoneFile, zone, err)
+			return result
+		}
+	}
+	result.OK = true
+	result.Detail = fmt.Sprintf("%s: %d collections", a.Config.Registry.ZoneFile, len(registry.Collections))
+	return result
+}
+
+// checkTopicRegistry verifies the topic registry file can be loaded and holds valid topic IDs
+func (a *Activities) checkTopicRegistry() CheckResult {
+	result := CheckResult{Name: "topic registry"}
+	registry, err := a.loadTopicRegistry()
+	if err != nil {
+		result.Detail = fmt.Sprintf("%s: %v", a.Config.Registry.TopicFile, err)
+		return result
+	}
+	for name, topic := range registry.Topics {
+		if _, err := entityid.ParseTopic(topic.TopicID, a.network()); err != nil {
+			result.Detail = fmt.Sprintf("%s: topic %s: %v", a.Config.Registry.TopicFile, name, err)
+			return result
+		}
+	}
+	result.OK = true
+	result.Detail = fmt.Sprintf("%s: %d topics", a.Config.Registry.TopicFile, len(registry.Topics))
+	if a.Config.HCS.RegistryTopic != "" {
+		unpublished := 0
+		for _, topic := range registry.Topics {
+			if topic.RegistrySequence == 0 && !a.isRegistryTopic(topic.TopicID) {
+				unpublished++
+			}
+		}
+		result.Detail += fmt.Sprintf(", synced with %s up to record %d", a.displayID(a.Config.HCS.RegistryTopic), registry.RegistrySequence)
+		if unpublished > 0 {
+			result.Detail += fmt.Sprintf(", %d not on the registry topic (run wfstart topics publish)", unpublished)
+		}
+	}
+	return result
+}
+
+// checkIngestLedger verifies the ingest ledger file can be loaded
+func (a *Activities) checkIngestLedger() CheckResult {
+	result := CheckResult{Name: "ingest ledger"}
+	ledger, err := a.loadIngestLedger()
+	if err != nil {
+		result.Detail = fmt.Sprintf("%s: %v", a.Config.Registry.IngestLedgerFile, err)
+		return result
+	}
+	result.OK = true
+	result.Detail = fmt.Sprintf("%s: %d files", a.Config.Registry.IngestLedgerFile, len(ledger.Files))
+	return result
+}
+
+// checkAccountRegistry verifies the account registry file can be loaded
+func (a *Activities) checkAccountRegistry() CheckResult {
+	result := CheckResult{Name: "account registry"}
+	registry, err := a.loadAccountRegistry()
+	if err != nil {
+		result.Detail = fmt.Sprintf("%s: %v", a.Config.Registry.AccountFile, err)
+		return result
+	}
+	result.OK = true
+	result.Detail = fmt.Sprintf("%s: %d mappings", a.Config.Registry.AccountFile, len(registry.Mappings))
+	return result
+}
+
+// checkRegistryStore verifies the registry store accepts connections when REGISTRY_STORE_DSN is set
+func (a *Activities) checkRegistryStore(ctx context.Context) CheckResult {
+	result := CheckResult{Name: "registry store"}
+	s, err := a.domainStore(ctx)
+	switch {
+	case err != nil:
+		result.Detail = err.Error()
+	case s == nil:
+		result.Skipped = true
+		result.Detail = "mints are not recorded in a database (REGISTRY_STORE_DSN is not set)"
+	default:
+		count, err := s.CountDomains(ctx)
+		if err != nil {
+			result.Detail = err.Error()
+			return result
+		}
+		result.OK = true
+		result.Detail = fmt.Sprintf("%d domains", count)
+	}
+	return result
+}
+
+// checkEventKeys verifies the registry public keys load when event signatures are verified
+func (a *Activities) checkEventKeys() CheckResult {
+	result := CheckResult{Name: "event keys"}
+	keys, err := a.eventKeys()
+	switch {
+	case err != nil:
+		result.Detail = err.Error()
+	case keys == nil:
+		result.Skipped = true
+		result.Detail = "event signatures are not verified (EVENT_SIGNATURE_MODE=off)"
+	default:
+		result.OK = true
+		result.Detail = fmt.Sprintf("%s mode, keys %s", a.Config.Events.SignatureMode, strings.Join(keys.KeyIDs(), ", "))
+	}
+	return result
+}
+
+// checkBranding verifies the branding file of the zone collections loads when set
+func (a *Activities) checkBranding() CheckResult {
+	result := CheckResult{Name: "collection branding"}
+	if a.Config.Metadata.BrandingFile == "" {
+		result.Skipped = true
+		result.Detail = "collections are created without branding (COLLECTION_BRANDING_FILE unset)"
+		return result
+	}
+	file, err := metadata.LoadBrandingFile(a.Config.Metadata.BrandingFile)
+	if err != nil {
+		result.Detail = err.Error()
+		return result
+	}
+	result.OK = true
+	result.Detail = fmt.Sprintf("%s: %d zones", a.Config.Metadata.BrandingFile, len(file.Zones))
+	return result
+}
+
+// checkMetadataStore verifies the metadata store is usable, reporting the balance of the paying wallet
+func (a *Activities) checkMetadataStore(ctx context.Context) CheckResult {
+	result := CheckResult{Name: "metadata store"}
+	if a.Config.Metadata.Store == config.MetadataStoreHCS {
+		// Looking the topic up through the store would create it
+		return a.checkMetadataTopic(ctx)
+	}
+	store, err := a.metadataStore(ctx)
+	switch {
+	case err != nil:
+		result.Detail = err.Error()
+		return result
+	case store == nil:
+		result.Skipped = true
+		result.Detail = "metadata documents are not uploaded (METADATA_STORE is not set)"
+		return result
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if ipfs, ok := store.(*metadata.IPFSStore); ok {
+		return a.checkPinners(ctx, ipfs)
+	}
+	arweave := store.(*metadata.ArweaveStore)
+	balance, err := arweave.Balance(ctx)
+	if err != nil {
+		result.Detail = fmt.Sprintf("balance of wallet %s: %v", arweave.Wallet.Address(), err)
+		return result
+	}
+	result.Detail = fmt.Sprintf("arweave via %s, wallet %s holds %s winston", arweave.Gateway, arweave.Wallet.Address(), balance)
+	if balance.Sign() == 0 {
+		result.Detail += " (cannot pay for uploads)"
+		return result
+	}
+	result.OK = true
+	return result
+}
+
+// checkMetadataTopic verifies the topic of the HCS metadata store can be found, if it exists yet
+func (a *Activities) checkMetadataTopic(ctx context.Context) CheckResult {
+	result := CheckResult{Name: "metadata store"}
+	topic, exists, err := a.lookupTopic(ctx, a.Config.Metadata.Topic)
+	switch {
+	case err != nil:
+		result.Detail = err.Error()
+	case !exists:
+		result.OK = true
+		result.Detail = fmt.Sprintf("hcs, topic '%s' is created on first use", a.Config.Metadata.Topic)
+	default:
+		result.OK = true
+		result.Detail = fmt.Sprintf("hcs, topic '%s' (%s)", a.Config.Metadata.Topic, a.displayID(topic.TopicID))
+	}
+	return result
+}
+
+// checkPinners verifies every IPFS pinner answers pin status requests, which also checks its credentials
+func (a *Activities) checkPinners(ctx context.Context, store *metadata.IPFSStore) CheckResult {
+	result := CheckResult{Name: "metadata store"}
+	probe, _ := metadata.CID([]byte("{}"))
+	for _, p := range store.Pinners {
+		if _, err := p.Status(ctx, probe); err != nil {
+			result.Detail = fmt.Sprintf("ipfs pinner %s: %v", p.Name(), err)
+			return result
+		}
+	}
+	result.OK = true
+	result.Detail = fmt.Sprintf("ipfs, pinned with %s, verified within %s",
+		strings.Join(a.Config.Metadata.IPFSPinners, ", "), a.Config.Metadata.IPFSPinTimeout)
+	return result
+}
@@ -0,0 +1,210 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+)
+
+// Kinds of the entries of a domain history
+const (
+	HistoryMint           = "mint"            // An NFT of the domain was minted
+	HistoryMetadataUpdate = "metadata_update" // The metadata of an NFT of the domain was updated
+	HistoryTransfer       = "transfer"        // An NFT of the domain changed hands
+	HistoryBurn           = "burn"            // An NFT of the domain was burned, or wiped from its holder
+	HistoryReceipt        = "receipt"         // The mint receipt of an NFT of the domain was published to HCS
+	HistoryAnchor         = "anchor"          // The event of the domain was anchored to HCS under a Merkle root
+)
+
+// DomainHistoryEntry is an entry of the history of a domain, a transaction of one of its NFTs or an HCS message
+type DomainHistoryEntry struct {
+	At             time.Time `json:"at"` // Consensus time
+	Kind           string    `json:"kind"`
+	TokenID        string    `json:"token_id,omitempty"`
+	SerialNumber   int64     `json:"serial_number,omitempty"`
+	TransactionID  string    `json:"transaction_id,omitempty"`  // Transactions, in the notation of the mirror node
+	From           string    `json:"from,omitempty"`            // Sender of transfers and wipes
+	To             string    `json:"to,omitempty"`              // Receiver of transfers
+	TopicID        string    `json:"topic_id,omitempty"`        // HCS messages
+	SequenceNumber uint64    `json:"sequence_number,omitempty"` // HCS messages
+	Producer       string    `json:"producer,omitempty"`        // Key ID of the worker that enveloped a receipt
+	BatchID        string    `json:"batch_id,omitempty"`        // Anchors
+}
+
+// DomainHistory is the chronological history of a domain on the ledger
+type DomainHistory struct {
+	Domain  string               `json:"domain"`
+	Zone    string               `json:"zone"`
+	NFTs    []string             `json:"nfts"` // Every NFT the domain had, as token/serial, oldest first
+	Entries []DomainHistoryEntry `json:"entries"`
+}
+
+// DomainHistoryActivity stitches together the history of a domain: the mints, metadata updates, transfers and
+// burns of every NFT the domain had in the collection of its zone, in any of its shards, from the mirror node,
+// the mint receipts of the receipts topic and the anchors of the anchor directory. ErrDomainNotMinted is
+// returned for domains the collection never held an NFT of.
+func (a *Activities) DomainHistoryActivity(ctx context.Context, domainName string) (DomainHistory, error) {
+	dn, err := domain.NewDomainName(domainName)
+	if err != nil {
+		return DomainHistory{}, fmt.Errorf("invalid domain name: %w", err)
+	}
+	if dn.ParentDomain() == "" {
+		return DomainHistory{}, fmt.Errorf("%s is a zone, not a domain within a zone", dn.String())
+	}
+	history := DomainHistory{Domain: dn.String(), Zone: dn.ParentDomain()}
+
+	collections, err := a.ListZoneCollectionsActivity(ctx)
+	if err != nil {
+		return history, err
+	}
+	var tokenIDs []string
+	for _, collection := range collections {
+		if collection.Zone.String() == history.Zone {
+			tokenIDs = collection.TokenIDs()
+		}
+	}
+	if len(tokenIDs) == 0 {
+		return history, fmt.Errorf("%s: %w, .%s has no collection", history.Domain, ErrDomainNotMinted, history.Zone)
+	}
+
+	// Every NFT of the domain, burned or not, a re-registered domain has several
+	type nftRef struct {
+		tokenID string
+		serial  int64
+	}
+	var nfts []nftRef
+	for _, tokenID := range tokenIDs {
+		listed, err := a.queryCollectionNFTs(ctx, tokenID)
+		if err != nil {
+			return history, fmt.Errorf("failed to list the NFTs of %s: %w", tokenID, err)
+		}
+		for _, nft := range listed {
+			if nftDomainName(nft, history.Zone) == history.Domain {
+				nfts = append(nfts, nftRef{tokenID, nft.SerialNumber})
+			}
+		}
+	}
+	if len(nfts) == 0 {
+		return history, fmt.Errorf("%s: %w", history.Domain, ErrDomainNotMinted)
+	}
+
+	var receiptsTopic string
+	if a.Config.HCS.ReceiptsTopic != "" {
+		topic, err := a.GetTopicInfoActivity(ctx, a.Config.HCS.ReceiptsTopic)
+		if err != nil {
+			return history, fmt.Errorf("failed to look up the receipts topic: %w", err)
+		}
+		receiptsTopic = topic.TopicID
+	}
+	for _, nft := range nfts {
+		entries, mintedAt, err := a.nftHistory(ctx, nft.tokenID, nft.serial)
+		if err != nil {
+			return history, err
+		}
+		history.Entries = append(history.Entries, entries...)
+		if receiptsTopic == "" || mintedAt == "" {
+			continue
+		}
+		found, err := a.searchMintReceipt(ctx, receiptsTopic, mintedAt, nft.tokenID, nft.serial)
+		if err != nil {
+			return history, fmt.Errorf("failed to search the receipts topic: %w", err)
+		}
+		if found != nil {
+			history.Entries = append(history.Entries, DomainHistoryEntry{
+				At:             found.ConsensusTime,
+				Kind:           HistoryReceipt,
+				TokenID:        nft.tokenID,
+				SerialNumber:   nft.serial,
+				TopicID:        receiptsTopic,
+				SequenceNumber: found.SequenceNumber,
+				Producer:       found.Producer,
+			})
+		}
+		heartbeat(ctx, indexKey(nft.tokenID, nft.serial))
+	}
+
+	// The anchors of the events of the domain, kept with their Merkle trees in the anchor directory
+	paths, err := filepath.Glob(filepath.Join(a.Config.Registry.AnchorDir, "*.json"))
+	if err != nil {
+		return history, err
+	}
+	for _, path := range paths {
+		anchor, err := a.loadAnchor(path)
+		if err != nil || anchor.SequenceNumber == 0 {
+			continue // Not an anchor, or its root was never anchored
+		}
+		for _, event := range anchor.Events {
+			if event.Domain == history.Domain {
+				history.Entries = append(history.Entries, DomainHistoryEntry{
+					At:             anchor.ConsensusTime,
+					Kind:           HistoryAnchor,
+					TopicID:        anchor.TopicID,
+					SequenceNumber: anchor.SequenceNumber,
+					BatchID:        anchor.BatchID,
+				})
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(history.Entries, func(i, j int) bool { return history.Entries[i].At.Before(history.Entries[j].At) })
+	seen := make(map[string]bool)
+	for _, entry := range history.Entries {
+		key := indexKey(entry.TokenID, entry.SerialNumber)
+		if entry.Kind == HistoryMint && !seen[key] {
+			history.NFTs = append(history.NFTs, key)
+			seen[key] = true
+		}
+	}
+	return history, nil
+}
+
+// nftHistory returns the transactions of an NFT as history entries, and the mirror node timestamp of its mint
+func (a *Activities) nftHistory(ctx context.Context, tokenID string, serial int64) ([]DomainHistoryEntry, string, error) {
+	var entries []DomainHistoryEntry
+	var mintedAt string
+	path := fmt.Sprintf("/tokens/%s/nfts/%d/transactions?order=asc&limit=100", tokenID, serial)
+	for path != "" {
+		var response MirrorNodeNFTTransactionsResponse
+		if err := a.mirrorGet(ctx, path, &response); err != nil {
+			return nil, "", fmt.Errorf("failed to read the transactions of %s: %w", indexKey(tokenID, serial), err)
+		}
+		for _, tx := range response.Transactions {
+			entry := DomainHistoryEntry{
+				At:            parseMirrorTimestamp(tx.ConsensusTimestamp),
+				TokenID:       tokenID,
+				SerialNumber:  serial,
+				TransactionID: tx.TransactionID,
+			}
+			switch tx.Type {
+			case TransactionTokenMint:
+				entry.Kind = HistoryMint
+				mintedAt = tx.ConsensusTimestamp
+			case TransactionTokenUpdateNFTs:
+				entry.Kind = HistoryMetadataUpdate
+			case TransactionCryptoTransfer:
+				entry.Kind, entry.From, entry.To = HistoryTransfer, tx.SenderAccountID, tx.ReceiverAccountID
+			case TransactionTokenBurn:
+				entry.Kind = HistoryBurn
+			case TransactionTokenWipe:
+				entry.Kind, entry.From = HistoryBurn, tx.SenderAccountID
+			default:
+				continue // Approvals and the like leave the NFT where it is
+			}
+			entries = append(entries, entry)
+		}
+
+		path = ""
+		if response.Links.Next != "" {
+			var err error
+			if path, err = a.mirrorNextPath(response.Links.Next); err != nil {
+				return nil, "", fmt.Errorf("invalid pagination link: %w", err)
+			}
+		}
+	}
+	return entries, mintedAt, nil
+}
@@ -0,0 +1,306 @@
+package temporal
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/store"
+)
+
+// ErrDomainNotMinted is returned by LookupDomainActivity for domains the ledger holds no NFT of
+var ErrDomainNotMinted = errors.New("domain not minted")
+
+// Sources of a DomainRecord
+const (
+	DomainSourceStore  = "store"  // The registry store of REGISTRY_STORE_DSN
+	DomainSourceMirror = "mirror" // The mirror node
+)
+
+// DomainRecord is the ledger entry of a domain: its NFT, mint transaction and current status
+type DomainRecord struct {
+	Domain            string     `json:"domain"`
+	Zone              string     `json:"zone"`
+	TokenID           string     `json:"token_id"`
+	SerialNumber      int64      `json:"serial_number"`
+	MintTransactionID string     `json:"mint_transaction_id"` // In the notation of the mirror node
+	ConsensusAt       time.Time  `json:"consensus_at"`        // Consensus time of the mint
+	Status            string     `json:"status"`              // store.StatusMinted or store.StatusBurned
+	BurnTransactionID string     `json:"burn_transaction_id,omitempty"`
+	BurnedAt          *time.Time `json:"burned_at,omitempty"`
+	Source            string     `json:"source"`
+}
+
+// LookupDomainActivity returns the ledger entry of a domain from the registry store, or from the mirror node
+// when no store is configured or the store does not know the domain, e.g. as it was minted before the store
+// was. ErrDomainNotMinted is returned for domains without an NFT.
+func (a *Activities) LookupDomainActivity(ctx context.Context, domainName string) (DomainRecord, error) {
+	dn, err := domain.NewDomainName(domainName)
+	if err != nil {
+		return DomainRecord{}, fmt.Errorf("invalid domain name: %w", err)
+	}
+	if dn.ParentDomain() == "" {
+		return DomainRecord{}, fmt.Errorf("%s is a zone, not a domain within a zone", dn.String())
+	}
+
+	s, err := a.domainStore(ctx)
+	if err != nil {
+		return DomainRecord{}, err
+	}
+	if s != nil {
+		d, err := s.Domain(ctx, dn.String())
+		switch {
+		case err == nil:
+			return storeDomainRecord(d), nil
+		case !errors.Is(err, store.ErrNotFound):
+			return DomainRecord{}, err
+		}
+	}
+	return a.lookupDomainOnMirror(ctx, dn)
+}
+
+// storeDomainRecord converts a row of the registry store
+func storeDomainRecord(d store.Domain) DomainRecord {
+	return DomainRecord{
+		Domain:            d.Name,
+		Zone:              d.Zone.String(),
+		TokenID:           d.TokenID,
+		SerialNumber:      d.Serial,
+		MintTransactionID: MirrorTransactionID(d.MintTransaction),
+		ConsensusAt:       d.MintedAt.UTC(),
+		Status:            d.Status,
+		BurnTransactionID: MirrorTransactionID(d.BurnTransaction),
+		BurnedAt:          d.BurnedAt,
+		Source:            DomainSourceStore,
+	}
+}
+
+// lookupDomainOnMirror finds the most recent NFT of a domain in the collection of its zone, in any of its shards,
+// and its history
+func (a *Activities) lookupDomainOnMirror(ctx context.Context, dn *domain.DomainName) (DomainRecord, error) {
+	record := DomainRecord{Domain: dn.String(), Zone: dn.ParentDomain(), Source: DomainSourceMirror}
+	collections, err := a.ListZoneCollectionsActivity(ctx)
+	if err != nil {
+		return DomainRecord{}, err
+	}
+	var tokenIDs []string
+	for _, collection := range collections {
+		if collection.Zone.String() == record.Zone {
+			tokenIDs = collection.TokenIDs()
+		}
+	}
+	if len(tokenIDs) == 0 {
+		return DomainRecord{}, fmt.Errorf("%s: %w, .%s has no collection", dn.String(), ErrDomainNotMinted, record.Zone)
+	}
+
+	nft, found, err := a.searchForDomainInShards(ctx, tokenIDs, dn.Label())
+	if err != nil {
+		return DomainRecord{}, err
+	}
+	if !found {
+		return DomainRecord{}, fmt.Errorf("%s: %w", dn.String(), ErrDomainNotMinted)
+	}
+	record.TokenID = nft.TokenID
+	record.SerialNumber = nft.SerialNumber
+	record.Status = store.StatusMinted
+
+	var history MirrorNodeNFTTransactionsResponse
+	path := fmt.Sprintf("/tokens/%s/nfts/%d/transactions?order=asc", record.TokenID, nft.SerialNumber)
+	if err := a.mirrorGet(ctx, path, &history); err != nil {
+		return DomainRecord{}, err
+	}
+	for _, tx := range history.Transactions {
+		switch tx.Type {
+		case TransactionTokenMint:
+			record.MintTransactionID = tx.TransactionID
+			record.ConsensusAt = parseMirrorTimestamp(tx.ConsensusTimestamp)
+		case TransactionTokenBurn, TransactionTokenWipe:
+			burnedAt := parseMirrorTimestamp(tx.ConsensusTimestamp)
+			record.BurnTransactionID = tx.TransactionID
+			record.BurnedAt = &burnedAt
+		}
+	}
+	if nft.Deleted {
+		record.Status = store.StatusBurned
+	}
+	return record, nil
+}
+
+// ErrInvalidCursor is returned by ListZoneDomainsActivity for cursors it did not return
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ZoneSummary is a zone of the zone registry with its collection, and its domain counts when they are known
+type ZoneSummary struct {
+	Zone      string           `json:"zone"`
+	TokenID   string           `json:"token_id"`
+	TokenName string           `json:"token_name"`
+	CreatedAt time.Time        `json:"created_at"`
+	Domains   *store.ZoneCount `json:"domains,omitempty"` // From the registry store, if configured
+}
+
+// ListZonesActivity returns the zones of the zone registry, with their domain counts from the registry store
+func (a *Activities) ListZonesActivity(ctx context.Context) ([]ZoneSummary, error) {
+	collections, err := a.ListZoneCollectionsActivity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var counts map[domain.Zone]store.ZoneCount
+	s, err := a.domainStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if s != nil {
+		if counts, err = s.ZoneCounts(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	zones := make([]ZoneSummary, 0, len(collections))
+	for _, collection := range collections {
+		zone := ZoneSummary{
+			Zone:      collection.Zone.String(),
+			TokenID:   collection.TokenID,
+			TokenName: collection.TokenName,
+			CreatedAt: collection.CreatedAt,
+		}
+		if counts != nil {
+			count := counts[collection.Zone]
+			zone.Domains = &count
+		}
+		zones = append(zones, zone)
+	}
+	return zones, nil
+}
+
+// ZoneDomainsQuery selects the domains of a zone listed by ListZoneDomainsActivity
+type ZoneDomainsQuery struct {
+	Zone         domain.Zone
+	Status       string    // store.StatusMinted or store.StatusBurned, all domains if empty
+	CreatedAfter time.Time // Only domains minted after this time, if set
+	Cursor       string    // Next of the previous page, empty for the first page
+	Limit        int
+}
+
+// DomainPage is a page of ListZoneDomainsActivity
+type DomainPage struct {
+	Domains []DomainRecord `json:"domains"`
+	Next    string         `json:"next,omitempty"` // Cursor of the next page, empty on the last page
+	Source  string         `json:"source"`
+}
+
+// Prefixes of the cursors of the sources of ListZoneDomainsActivity, a cursor only continues its own source
+const (
+	storeCursorPrefix  = "s."
+	mirrorCursorPrefix = "m."
+)
+
+// ListZoneDomainsActivity returns a page of the domains of a zone, in the order they were minted. Domains are
+// listed from the registry store, or from the NFTs of the zone collection on the mirror node when no store is
+// configured. The mirror node pages by serial and knows no mint transactions; its pages are filtered after they
+// are fetched, so they may hold fewer domains than the limit without being the last.
+func (a *Activities) ListZoneDomainsActivity(ctx context.Context, q ZoneDomainsQuery) (DomainPage, error) {
+	s, err := a.domainStore(ctx)
+	if err != nil {
+		return DomainPage{}, err
+	}
+	if s == nil {
+		return a.listZoneDomainsOnMirror(ctx, q)
+	}
+
+	query := store.Query{Zone: q.Zone, Status: q.Status, MintedAfter: q.CreatedAfter, Limit: q.Limit}
+	if q.Cursor != "" {
+		encoded, found := strings.CutPrefix(q.Cursor, storeCursorPrefix)
+		if !found {
+			return DomainPage{}, ErrInvalidCursor
+		}
+		cursor, err := store.ParseCursor(encoded)
+		if err != nil {
+			return DomainPage{}, ErrInvalidCursor
+		}
+		query.After = &cursor
+	}
+	domains, next, err := s.ListDomains(ctx, query)
+	if err != nil {
+		return DomainPage{}, err
+	}
+	page := DomainPage{Domains: make([]DomainRecord, 0, len(domains)), Source: DomainSourceStore}
+	for _, d := range domains {
+		page.Domains = append(page.Domains, storeDomainRecord(d))
+	}
+	if next != nil {
+		page.Next = storeCursorPrefix + next.String()
+	}
+	return page, nil
+}
+
+// listZoneDomainsOnMirror lists a page of the NFTs of the collection of a zone by serial
+func (a *Activities) listZoneDomainsOnMirror(ctx context.Context, q ZoneDomainsQuery) (DomainPage, error) {
+	page := DomainPage{Domains: []DomainRecord{}, Source: DomainSourceMirror}
+	var afterSerial int64
+	if q.Cursor != "" {
+		encoded, found := strings.CutPrefix(q.Cursor, mirrorCursorPrefix)
+		serial, err := strconv.ParseInt(encoded, 10, 64)
+		if !found || err != nil || serial <= 0 {
+			return DomainPage{}, ErrInvalidCursor
+		}
+		afterSerial = serial
+	}
+
+	collections, err := a.ListZoneCollectionsActivity(ctx)
+	if err != nil {
+		return DomainPage{}, err
+	}
+	var tokenID string
+	for _, collection := range collections {
+		if collection.Zone == q.Zone {
+			tokenID = collection.TokenID
+		}
+	}
+	if tokenID == "" {
+		return page, nil // The zone has no collection, hence no domains
+	}
+
+	path := fmt.Sprintf("/tokens/%s/nfts?order=asc", tokenID)
+	if q.Limit > 0 {
+		path += fmt.Sprintf("&limit=%d", q.Limit)
+	}
+	if afterSerial > 0 {
+		path += fmt.Sprintf("&serialnumber=gt:%d", afterSerial)
+	}
+	var response MirrorNodeNFTsResponse
+	if err := a.mirrorGet(ctx, path, &response); err != nil && !errors.Is(err, errMirrorNotFound) {
+		return DomainPage{}, err
+	}
+	for _, nft := range response.NFTs {
+		record := DomainRecord{
+			Zone:         q.Zone.String(),
+			TokenID:      tokenID,
+			SerialNumber: nft.SerialNumber,
+			ConsensusAt:  parseMirrorTimestamp(nft.CreatedAt),
+			Status:       store.StatusMinted,
+			Source:       DomainSourceMirror,
+		}
+		if nft.Deleted {
+			record.Status = store.StatusBurned
+		}
+		metadata := nft.Metadata
+		if decoded, err := base64.StdEncoding.DecodeString(metadata); err == nil {
+			metadata = string(decoded)
+		}
+		if label, _ := ParseNFTMetadata(metadata); label != "" {
+			record.Domain = label + "." + q.Zone.String()
+		}
+		if (q.Status == "" || record.Status == q.Status) && record.ConsensusAt.After(q.CreatedAfter) {
+			page.Domains = append(page.Domains, record)
+		}
+	}
+	if response.Links.Next != "" && len(response.NFTs) > 0 {
+		page.Next = mirrorCursorPrefix + strconv.FormatInt(response.NFTs[len(response.NFTs)-1].SerialNumber, 10)
+	}
+	return page, nil
+}
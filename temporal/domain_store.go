@@ -0,0 +1,113 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/store"
+)
+
+// domainStore returns the registry store of REGISTRY_STORE_DSN, connecting on first use, or nil when no store
+// is configured. A failed connection is attempted again by the next call, a store recording the ledger of
+// another network is refused.
+func (a *Activities) domainStore(ctx context.Context) (*store.Store, error) {
+	if a.Config.Registry.StoreDSN == "" {
+		return nil, nil
+	}
+	a.storeMu.Lock()
+	defer a.storeMu.Unlock()
+	if a.store == nil {
+		s, err := store.OpenSchema(ctx, a.Config.Registry.StoreDSN, a.Config.Registry.StoreSchema)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.ClaimNetwork(ctx, a.network()); err != nil {
+			s.Close()
+			return nil, err
+		}
+		a.store = s
+	}
+	return a.store, nil
+}
+
+// storeMint records a mint in the registry store, if any. The mint is final, so a mint that cannot be recorded
+// is reported without failing the activity.
+func (a *Activities) storeMint(ctx context.Context, info MintingInfo, result MintResult) {
+	s, err := a.domainStore(ctx)
+	if err == nil && s != nil {
+		err = s.RecordMint(ctx, store.Domain{
+			Name:            result.Domain,
+			Zone:            info.Zone,
+			TokenID:         result.TokenID,
+			Serial:          result.SerialNumber,
+			MintTransaction: result.TransactionID,
+			Registrar:       info.RegistrarID,
+			FeeTinybar:      result.FeeTinybar,
+			MintedAt:        result.ConsensusAt,
+		})
+	}
+	if err != nil {
+		fmt.Printf("Warning: failed to record the mint of %s in the registry store: %v\n", result.Domain, err)
+	}
+}
+
+// storeBurn records a burn in the registry store, if any. Like storeMint, it does not fail the activity.
+func (a *Activities) storeBurn(ctx context.Context, name, transactionID string, burnedAt time.Time) {
+	s, err := a.domainStore(ctx)
+	if err == nil && s != nil {
+		err = s.RecordBurn(ctx, name, transactionID, burnedAt)
+	}
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		fmt.Printf("Warning: burned %s, whose mint predates the registry store\n", name)
+	case err != nil:
+		fmt.Printf("Warning: failed to record the burn of %s in the registry store: %v\n", name, err)
+	}
+}
+
+// storeMigration records a domain minted again into the new collection of its zone, if there is a registry
+// store. Like storeMint, it does not fail the activity.
+func (a *Activities) storeMigration(ctx context.Context, name, tokenID string, serial int64, transactionID string) {
+	s, err := a.domainStore(ctx)
+	if err == nil && s != nil {
+		err = s.RecordMigration(ctx, name, tokenID, serial, transactionID)
+	}
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		fmt.Printf("Warning: migrated %s, whose mint is not in the registry store\n", name)
+	case err != nil:
+		fmt.Printf("Warning: failed to record the migration of %s in the registry store: %v\n", name, err)
+	}
+}
+
+// storeRunReport records the outcome of every zone of a run in the registry store, if any, for the dashboard
+// statistics. Like storeMint, it only reports failures.
+func (a *Activities) storeRunReport(ctx context.Context, report RunReport) {
+	s, err := a.domainStore(ctx)
+	if err != nil || s == nil {
+		if err != nil {
+			fmt.Printf("Warning: failed to record run %s in the registry store: %v\n", report.WorkflowID, err)
+		}
+		return
+	}
+	for _, zone := range report.Zones {
+		err := s.RecordZoneRun(ctx, store.ZoneRun{
+			WorkflowID:  report.WorkflowID,
+			RunID:       report.RunID,
+			Zone:        domain.Zone(zone.Zone),
+			Outcome:     report.Outcome,
+			FinishedAt:  report.FinishedAt,
+			Minted:      zone.Minted,
+			Burned:      zone.Burned,
+			Skipped:     zone.Skipped,
+			Failed:      zone.Failed,
+			FeesTinybar: zone.FeesTinybar,
+		})
+		if err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package temporal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/mail"
+)
+
+// ErrEmailNotConfigured is returned by the email activities when REPORT_EMAIL_TO is not set
+var ErrEmailNotConfigured = errors.New("reports are not emailed, REPORT_EMAIL_TO is not set")
+
+// mailer returns the sender of EMAIL_TRANSPORT
+func (a *Activities) mailer() (mail.Sender, error) {
+	e := a.Config.Email
+	if !e.Enabled() {
+		return nil, ErrEmailNotConfigured
+	}
+	switch e.Transport {
+	case config.EmailSES:
+		return mail.SES{
+			Region:          e.SESRegion,
+			AccessKeyID:     e.SESAccessKeyID,
+			SecretAccessKey: e.SESSecretAccessKey,
+			Endpoint:        e.SESEndpoint,
+		}, nil
+	default:
+		return mail.SMTP{Host: e.SMTPHost, Port: e.SMTPPort, Username: e.SMTPUsername, Password: e.SMTPPassword}, nil
+	}
+}
+
+// sendEmail emails a message to the distribution list of REPORT_EMAIL_TO
+func (a *Activities) sendEmail(ctx context.Context, subject, body string, attachments []mail.Attachment) error {
+	sender, err := a.mailer()
+	if err != nil {
+		return err
+	}
+	m := mail.Message{
+		From:        a.Config.Email.From,
+		To:          a.Config.Email.To,
+		Subject:     subject,
+		Body:        body,
+		Attachments: attachments,
+	}
+	if err := sender.Send(ctx, m); err != nil {
+		return fmt.Errorf("failed to email %q: %w", subject, err)
+	}
+	fmt.Printf("Emailed %q to %s\n", subject, strings.Join(m.To, ", "))
+	return nil
+}
+
+// EmailRunReportActivity emails the summary of the report of an ingest run to REPORT_EMAIL_TO, with the
+// report attached as JSON
+func (a *Activities) EmailRunReportActivity(ctx context.Context, report RunReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal run report: %w", err)
+	}
+	subject := fmt.Sprintf("Ingest of %s %s", filepath.Base(report.FilePath), report.Outcome)
+	if report.Partial {
+		subject += " (partial)"
+	}
+	attachment := mail.Attachment{
+		Name:        filepath.Base(RunReportPath("", report.WorkflowID, report.RunID)),
+		ContentType: "application/json",
+		Data:        data,
+	}
+	return a.sendEmail(ctx, subject, runSummary(report)+"\n", []mail.Attachment{attachment})
+}
+
+// EmailReconciliationsActivity emails ICANN reconciliations to REPORT_EMAIL_TO: the counts of every report
+// against the ledger and its discrepancies, with the reconciliations attached as JSON
+func (a *Activities) EmailReconciliationsActivity(ctx context.Context, recs []ICANNReconciliation) error {
+	var body strings.Builder
+	var attachments []mail.Attachment
+	discrepancies := 0
+	for _, rec := range recs {
+		fmt.Fprintf(&body, ".%s %s (%s): adds %d/%d, deletes %d/%d, total %d/%d (report/ledger)\n", rec.Zone, rec.Month, rec.ReportFile,
+			rec.Report.Adds, rec.LedgerAdds, rec.Report.Deletes, rec.LedgerDeletes, rec.Report.TotalDomains, rec.LedgerTotal)
+		for _, d := range rec.Discrepancies {
+			fmt.Fprintf(&body, "  %s differs by %+d: report %d, ledger %d\n", d.Metric, d.Difference, d.Report, d.Ledger)
+		}
+		discrepancies += len(rec.Discrepancies)
+
+		data, err := json.MarshalIndent(rec, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal reconciliation: %w", err)
+		}
+		attachments = append(attachments, mail.Attachment{
+			Name:        filepath.Base(rec.ReportPath),
+			ContentType: "application/json",
+			Data:        data,
+		})
+	}
+	subject := fmt.Sprintf("ICANN reconciliation of %d reports: no discrepancies", len(recs))
+	if discrepancies > 0 {
+		subject = fmt.Sprintf("ICANN reconciliation of %d reports: %d discrepancies", len(recs), discrepancies)
+	}
+	return a.sendEmail(ctx, subject, body.String(), attachments)
+}
@@ -0,0 +1,131 @@
+package temporal
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"go.temporal.io/sdk/activity"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/eventsig"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/hcs"
+)
+
+// ErrTypeSubmitKey is the application error type of messages refused for a topic's submit key
+const ErrTypeSubmitKey = "SubmitKeyMismatch"
+
+// ErrUntrustedMessage is returned for the audit messages a producer key set refuses: plain messages, and envelopes
+// not signed by the key of their producer
+var ErrUntrustedMessage = errors.New("audit message not signed by a trusted producer")
+
+// producer returns the identity signing the envelopes of audit messages, nil when HCS_PRODUCER_KEY is unset
+func producer(cfg *config.Config) (*hcs.Producer, error) {
+	if cfg.HCS.ProducerKey == "" {
+		return nil, nil
+	}
+	key, err := hedera.PrivateKeyFromStringEd25519(cfg.HCS.ProducerKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HCS_PRODUCER_KEY: %w", err)
+	}
+	if cfg.HCS.ProducerID == "" {
+		return nil, errors.New("HCS_PRODUCER_ID is not set")
+	}
+	return &hcs.Producer{ID: cfg.HCS.ProducerID, Key: ed25519.NewKeyFromSeed(key.BytesRaw())}, nil
+}
+
+// ProducerJWK returns the JSON Web Key of the public producer key, which consumers add to their key set
+// to verify the envelopes of this worker
+func ProducerJWK(cfg *config.Config) ([]byte, error) {
+	p, err := producer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errors.New("HCS_PRODUCER_KEY is not set")
+	}
+	return eventsig.PublicJWK(p.ID, p.Key.Public())
+}
+
+// sealAuditMessage wraps a JSON message of an audit topic in an envelope signed by the producer.
+// Without HCS_PRODUCER_KEY, the message is published as it is.
+func (a *Activities) sealAuditMessage(message []byte) (string, error) {
+	p, err := producer(a.Config)
+	if err != nil || p == nil {
+		return string(message), err
+	}
+	sealed, err := p.Seal(message)
+	if err != nil {
+		return "", fmt.Errorf("failed to seal message: %w", err)
+	}
+	return string(sealed), nil
+}
+
+// publishAuditRecords sends JSON records to an audit topic in order, sealed by sealAuditMessage. The records sent
+// are heartbeated, so a retried attempt continues after them instead of sending them again.
+func (a *Activities) publishAuditRecords(ctx context.Context, topicID, what string, records []any) (int, error) {
+	sent := 0
+	if activity.IsActivity(ctx) && activity.HasHeartbeatDetails(ctx) {
+		if err := activity.GetHeartbeatDetails(ctx, &sent); err == nil {
+			fmt.Printf("Resuming the %s after %d sent\n", what, sent)
+		}
+	}
+	for ; sent < len(records); sent++ {
+		if workerStopping(ctx) {
+			return sent, errWorkerShutdown(what)
+		}
+		record, err := json.Marshal(records[sent])
+		if err != nil {
+			return sent, fmt.Errorf("failed to marshal record %d of the %s: %w", sent+1, what, err)
+		}
+		message, err := a.sealAuditMessage(record)
+		if err != nil {
+			return sent, err
+		}
+		if _, err := a.SendMessageToTopicActivity(ctx, topicID, message); err != nil {
+			return sent, fmt.Errorf("failed to send record %d of the %s: %w", sent+1, what, err)
+		}
+		heartbeat(ctx, sent+1)
+	}
+	return sent, nil
+}
+
+// producerKeys loads the key set of the trusted producers, or returns nil when HCS_PRODUCER_KEYS_FILE is unset
+func (a *Activities) producerKeys() (*eventsig.KeySet, error) {
+	if a.Config.HCS.ProducerKeysFile == "" {
+		return nil, nil
+	}
+	keys, err := eventsig.LoadKeySet(a.Config.HCS.ProducerKeysFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HCS_PRODUCER_KEYS_FILE: %w", err)
+	}
+	return keys, nil
+}
+
+// openAuditMessage unwraps a message of an audit topic into its payload and producer, empty for plain messages.
+// With a key set of trusted producers, only envelopes signed by the key of their producer are opened: anyone
+// holding the submit key of the topic can publish, so other messages return ErrUntrustedMessage.
+func openAuditMessage(keys *eventsig.KeySet, data []byte) ([]byte, string, error) {
+	env, _ := hcs.Open(data)
+	if keys != nil {
+		if err := env.Verify(keys); err != nil {
+			return nil, env.Producer, fmt.Errorf("%w: %v", ErrUntrustedMessage, err)
+		}
+	}
+	return env.Payload, env.Producer, nil
+}
+
+// submitKey returns HCS_SUBMIT_KEY, nil when it is unset and the operator key submits to every topic
+func (a *Activities) submitKey() (*hedera.PrivateKey, error) {
+	if a.Config.HCS.SubmitKey == "" {
+		return nil, nil
+	}
+	key, err := hedera.PrivateKeyFromString(a.Config.HCS.SubmitKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HCS_SUBMIT_KEY: %w", err)
+	}
+	return &key, nil
+}
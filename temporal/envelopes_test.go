@@ -0,0 +1,39 @@
+package temporal
+
+import (
+	"fmt"
+	"testing"
+
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/eventsig"
+)
+
+func TestOpenAuditMessage(t *testing.T) {
+	key, err := hedera.PrivateKeyGenerateEd25519()
+	require.NoError(t, err)
+	a := &Activities{Config: &config.Config{HCS: config.HCSConfig{ProducerID: "worker-1", ProducerKey: key.StringRaw()}}}
+	jwk, err := ProducerJWK(a.Config)
+	require.NoError(t, err)
+	keys, err := eventsig.ParseKeySet([]byte(fmt.Sprintf(`{"keys":[%s]}`, jwk)))
+	require.NoError(t, err)
+
+	// Characters json.Marshal escapes for HTML must not break the signature
+	message, err := a.sealAuditMessage([]byte(`{"registrar":"Smith & Sons <sales>"}`))
+	require.NoError(t, err)
+	payload, producer, err := openAuditMessage(keys, []byte(message))
+	require.NoError(t, err)
+	assert.Equal(t, "worker-1", producer)
+	assert.Equal(t, `{"registrar":"Smith & Sons <sales>"}`, string(payload))
+
+	// Plain messages are only opened without a key set
+	_, _, err = openAuditMessage(keys, []byte(`{"registrar":"Smith & Sons"}`))
+	assert.ErrorIs(t, err, ErrUntrustedMessage)
+	payload, producer, err = openAuditMessage(nil, []byte(`{"registrar":"Smith & Sons"}`))
+	require.NoError(t, err)
+	assert.Empty(t, producer)
+	assert.Equal(t, `{"registrar":"Smith & Sons"}`, string(payload))
+}
@@ -0,0 +1,133 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/incident"
+)
+
+// ResumeZoneSignal resumes a zone paused by an escalation, once an operator has dealt with the incident
+const ResumeZoneSignal = "resume_zone"
+
+// Escalation is an incident opened for a zone whose mints or burns keep failing (ESCALATION_THRESHOLD)
+type Escalation struct {
+	Key                 string        `json:"key"` // Identifies the incident in the on-call services
+	Zone                string        `json:"zone"`
+	WorkflowID          string        `json:"workflow_id"`               // ProcessZoneWorkflow of the zone, which ResumeZoneSignal resumes
+	RunWorkflowID       string        `json:"run_workflow_id,omitempty"` // The ingest run of the zone
+	ConsecutiveFailures int           `json:"consecutive_failures"`
+	Minted              int           `json:"minted"` // Of the zone in the run so far
+	Failed              int           `json:"failed"`
+	RecentFailures      []MintFailure `json:"recent_failures,omitempty"`
+}
+
+// pagers returns the on-call services of PAGERDUTY_ROUTING_KEY and OPSGENIE_API_KEY
+func (a *Activities) pagers() []incident.Pager {
+	var pagers []incident.Pager
+	if key := a.Config.Escalation.PagerDutyRoutingKey; key != "" {
+		pagers = append(pagers, incident.PagerDuty{RoutingKey: key})
+	}
+	if key := a.Config.Escalation.OpsgenieAPIKey; key != "" {
+		pagers = append(pagers, incident.Opsgenie{APIKey: key, URL: a.Config.Escalation.OpsgenieURL})
+	}
+	return pagers
+}
+
+// OpenIncidentActivity opens the incident of an escalation in every on-call service. It only fails when no
+// service accepted it; the services deduplicate the incident by its key, so retries do not page twice.
+func (a *Activities) OpenIncidentActivity(ctx context.Context, e Escalation) error {
+	i := incident.Incident{
+		Key:       e.Key,
+		Summary:   fmt.Sprintf(".%s: %d consecutive mints or burns failed, the zone is paused until an operator resumes it", e.Zone, e.ConsecutiveFailures),
+		Source:    e.WorkflowID,
+		Component: e.Zone,
+		Details: map[string]any{
+			"zone":                 e.Zone,
+			"zone_workflow_id":     e.WorkflowID,
+			"run_workflow_id":      e.RunWorkflowID,
+			"consecutive_failures": e.ConsecutiveFailures,
+			"minted":               e.Minted,
+			"failed":               e.Failed,
+			"resume":               "wfstart resume-zone " + e.WorkflowID,
+		},
+	}
+	if n := len(e.RecentFailures); n > 0 {
+		last := e.RecentFailures[n-1]
+		i.Details["last_domain"] = last.Domain
+		i.Details["last_error"] = last.Error
+	}
+	return a.eachPager(func(p incident.Pager) error { return p.Open(ctx, i) }, "open the incident of .%s", e.Zone)
+}
+
+// ResolveIncidentActivity resolves the incident of an escalation in every on-call service
+func (a *Activities) ResolveIncidentActivity(ctx context.Context, key string) error {
+	return a.eachPager(func(p incident.Pager) error { return p.Resolve(ctx, key) }, "resolve incident %s", key)
+}
+
+// eachPager calls every on-call service, reporting those that fail, and fails when all of them did
+func (a *Activities) eachPager(call func(incident.Pager) error, format string, args ...any) error {
+	pagers := a.pagers()
+	if len(pagers) == 0 {
+		return errors.New("no on-call service, set PAGERDUTY_ROUTING_KEY or OPSGENIE_API_KEY")
+	}
+	var errs []error
+	for _, p := range pagers {
+		if err := call(p); err != nil {
+			fmt.Printf("Warning: failed to %s in %s: %v\n", fmt.Sprintf(format, args...), p, err)
+			errs = append(errs, fmt.Errorf("%s: %w", p, err))
+		}
+	}
+	if len(errs) == len(pagers) {
+		return fmt.Errorf("failed to %s: %w", fmt.Sprintf(format, args...), errors.Join(errs...))
+	}
+	return nil
+}
+
+// escalate opens an incident for a zone whose last mints or burns failed, and pauses the zone until
+// ResumeZoneSignal. The zone is paused even when the incident could not be opened, the failures are then
+// visible in its progress and logs. Resuming resolves the incident.
+func escalate(ctx workflow.Context, progress *ZoneProgress, failures int) error {
+	logger := workflow.GetLogger(ctx)
+	progress.Escalations++
+	e := Escalation{
+		Key:                 fmt.Sprintf("%s_escalation_%d", progress.WorkflowID, progress.Escalations),
+		Zone:                progress.Zone,
+		WorkflowID:          progress.WorkflowID,
+		ConsecutiveFailures: failures,
+		Minted:              progress.Minted,
+		Failed:              progress.Failed,
+		RecentFailures:      progress.RecentFailures,
+	}
+	if parent := workflow.GetInfo(ctx).ParentWorkflowExecution; parent != nil {
+		e.RunWorkflowID = parent.ID
+	}
+	logger.Warn("Mints keep failing, pausing the zone", "zone", e.Zone, "consecutiveFailures", failures, "incident", e.Key)
+	if err := workflow.ExecuteActivity(ctx, "OpenIncidentActivity", e).Get(ctx, nil); err != nil {
+		logger.Error("Failed to open incident", "zone", e.Zone, "incident", e.Key, "error", err)
+	}
+
+	progress.Escalated = true
+	resumes := workflow.GetSignalChannel(ctx, ResumeZoneSignal)
+	for resumes.ReceiveAsync(nil) {
+		// Resumes sent before this escalation do not resume it
+	}
+	selector := workflow.NewSelector(ctx)
+	selector.AddReceive(resumes, func(c workflow.ReceiveChannel, more bool) { c.Receive(ctx, nil) })
+	selector.AddReceive(ctx.Done(), func(workflow.ReceiveChannel, bool) {})
+	selector.Select(ctx)
+	if ctx.Err() != nil {
+		return temporal.NewCanceledError()
+	}
+	progress.Escalated = false
+	logger.Info("Zone resumed by an operator", "zone", e.Zone, "incident", e.Key)
+
+	if err := workflow.ExecuteActivity(ctx, "ResolveIncidentActivity", e.Key).Get(ctx, nil); err != nil {
+		logger.Warn("Failed to resolve incident", "zone", e.Zone, "incident", e.Key, "error", err)
+	}
+	return nil
+}
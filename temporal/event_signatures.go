@@ -0,0 +1,52 @@
+package temporal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/eventsig"
+)
+
+// ErrUnsignedEvent is returned for events without a signature in strict signature mode
+var ErrUnsignedEvent = errors.New("event is not signed")
+
+// eventKeys loads the registry public keys, or returns nil when signatures are not verified
+func (a *Activities) eventKeys() (*eventsig.KeySet, error) {
+	if a.Config.Events.SignatureMode == config.SignaturesOff || a.Config.Events.SignatureMode == "" {
+		return nil, nil
+	}
+	return eventsig.LoadKeySet(a.Config.Events.KeysFile)
+}
+
+// verifyEventSignature checks the signature of an event line ({"registry-event":{...},"sig":"..."}) against
+// the registry keys and returns the ID of the signing key, or an empty string for accepted unsigned events.
+// The signature covers the exact bytes of the registry-event object.
+func (a *Activities) verifyEventSignature(keys *eventsig.KeySet, jsonString, signature string) (string, error) {
+	if keys == nil {
+		return "", nil
+	}
+	if signature == "" {
+		if a.Config.Events.SignatureMode == config.SignaturesStrict {
+			return "", ErrUnsignedEvent
+		}
+		return "", nil
+	}
+	payload, err := registryEventPayload(jsonString)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract signed event: %w", err)
+	}
+	return keys.Verify(payload, signature)
+}
+
+// registryEventPayload returns the exact bytes of the registry-event object of an event line
+func registryEventPayload(jsonString string) ([]byte, error) {
+	var raw struct {
+		Event json.RawMessage `json:"registry-event"`
+	}
+	if err := json.Unmarshal([]byte(jsonString), &raw); err != nil {
+		return nil, err
+	}
+	return raw.Event, nil
+}
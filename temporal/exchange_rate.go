@@ -0,0 +1,55 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+)
+
+// ExchangeRate is the HBAR/USD exchange rate of the network, set by its exchange rate file (0.0.112) and used
+// by the network to price fees: HbarEquivalent HBAR are worth CentEquivalent US cents
+type ExchangeRate struct {
+	CentEquivalent int64     `json:"cent_equivalent"`
+	HbarEquivalent int64     `json:"hbar_equivalent"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// MirrorNodeExchangeRate is the response of /network/exchangerate
+type MirrorNodeExchangeRate struct {
+	CurrentRate struct {
+		CentEquivalent int64 `json:"cent_equivalent"`
+		HbarEquivalent int64 `json:"hbar_equivalent"`
+		ExpirationTime int64 `json:"expiration_time"` // Unix seconds
+	} `json:"current_rate"`
+}
+
+// USD converts an amount of tinybar to US dollars
+func (r ExchangeRate) USD(tinybar int64) float64 {
+	return float64(tinybar) * float64(r.CentEquivalent) / float64(r.HbarEquivalent) / config.TinybarsPerHbar / 100
+}
+
+// Tinybar converts an amount of US dollars to tinybar, rounded down
+func (r ExchangeRate) Tinybar(usd float64) int64 {
+	return int64(math.Floor(usd * 100 * float64(r.HbarEquivalent) / float64(r.CentEquivalent) * config.TinybarsPerHbar))
+}
+
+// ExchangeRateActivity returns the current HBAR/USD exchange rate of the network from the mirror node
+func (a *Activities) ExchangeRateActivity(ctx context.Context) (ExchangeRate, error) {
+	var response MirrorNodeExchangeRate
+	if err := a.mirrorGet(ctx, "/network/exchangerate", &response); err != nil {
+		return ExchangeRate{}, fmt.Errorf("failed to fetch exchange rate: %w", err)
+	}
+	current := response.CurrentRate
+	if current.CentEquivalent <= 0 || current.HbarEquivalent <= 0 {
+		return ExchangeRate{}, errors.New("mirror node returned an invalid exchange rate")
+	}
+	return ExchangeRate{
+		CentEquivalent: current.CentEquivalent,
+		HbarEquivalent: current.HbarEquivalent,
+		ExpiresAt:      time.Unix(current.ExpirationTime, 0).UTC(),
+	}, nil
+}
@@ -0,0 +1,142 @@
+package temporal
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/export"
+)
+
+// CollectionExportRequest describes an export of the NFTs of a zone collection
+type CollectionExportRequest struct {
+	Zone       string `json:"zone"`
+	TokenID    string `json:"token_id,omitempty"` // Optional, defaults to the zone registry, then a mirror node lookup by name
+	Format     string `json:"format"`             // export.FormatCSV, export.FormatJSON or export.FormatParquet
+	OutputPath string `json:"output_path"`
+}
+
+// CollectionExportResult summarizes a finished export
+type CollectionExportResult struct {
+	TokenID    string `json:"token_id"`
+	OutputPath string `json:"output_path"`
+	Count      int    `json:"count"`
+}
+
+// ExportCollectionActivity streams all NFTs of a zone collection from the mirror node, page by page,
+// decodes their metadata and writes them to a CSV, JSON or Parquet file.
+// The file only appears at OutputPath once the export is complete.
+func (a *Activities) ExportCollectionActivity(ctx context.Context, req CollectionExportRequest) (CollectionExportResult, error) {
+	tokenID, err := a.resolveZoneCollection(ctx, req.Zone, req.TokenID)
+	if err != nil {
+		return CollectionExportResult{}, err
+	}
+	result := CollectionExportResult{TokenID: tokenID, OutputPath: req.OutputPath}
+
+	// Write to a temporary file next to the output, so a failed export leaves nothing behind
+	tmp, err := os.CreateTemp(filepath.Dir(req.OutputPath), "."+filepath.Base(req.OutputPath)+".*")
+	if err != nil {
+		return result, fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	w, err := export.NewWriter(tmp, req.Format)
+	if err != nil {
+		return result, err
+	}
+
+	fmt.Printf("Exporting .%s collection %s to %s (%s)\n", req.Zone, a.displayID(tokenID), req.OutputPath, req.Format)
+	path := fmt.Sprintf("/tokens/%s/nfts?limit=100&order=asc", tokenID)
+	for path != "" {
+		var response MirrorNodeNFTsResponse
+		if err := a.mirrorGet(ctx, path, &response); err != nil {
+			return result, err
+		}
+		for _, nft := range response.NFTs {
+			if err := w.Write(a.exportedNFT(req.Zone, nft)); err != nil {
+				return result, fmt.Errorf("failed to write serial %d: %w", nft.SerialNumber, err)
+			}
+			result.Count++
+		}
+		heartbeat(ctx, result.Count)
+		fmt.Printf("Exported %d NFTs\n", result.Count)
+
+		path = ""
+		if response.Links.Next != "" {
+			if path, err = a.mirrorNextPath(response.Links.Next); err != nil {
+				return result, fmt.Errorf("invalid pagination link: %w", err)
+			}
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return result, fmt.Errorf("failed to finish export: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return result, err
+	}
+	if err := os.Rename(tmp.Name(), req.OutputPath); err != nil {
+		return result, fmt.Errorf("failed to move export into place: %w", err)
+	}
+	return result, nil
+}
+
+// resolveZoneCollection returns the token ID of a zone collection: the given one, the one in the
+// zone registry, or the one found on the mirror node by its token name
+func (a *Activities) resolveZoneCollection(ctx context.Context, zone, tokenID string) (string, error) {
+	if tokenID != "" {
+		id, err := a.tokenIDFromString(tokenID)
+		if err != nil {
+			return "", err
+		}
+		return id.String(), nil
+	}
+	if registry, err := a.loadZoneRegistry(); err == nil {
+		if collection, ok := registry.Collections[domain.Zone(zone)]; ok {
+			return collection.TokenID, nil
+		}
+	}
+	token, check := a.verifyCollection(ctx, zone, "")
+	if !check.OK {
+		return "", errors.New(check.Detail)
+	}
+	return token.TokenID, nil
+}
+
+// exportedNFT converts a mirror node NFT of a zone collection into an export row
+func (a *Activities) exportedNFT(zone string, nft MirrorNodeNFT) export.NFT {
+	metadata := nft.Metadata
+	if decoded, err := base64.StdEncoding.DecodeString(metadata); err == nil {
+		metadata = string(decoded)
+	}
+	label, _ := ParseNFTMetadata(metadata)
+	return export.NFT{
+		Zone:           zone,
+		Domain:         label + "." + zone,
+		TokenID:        nft.TokenID,
+		SerialNumber:   nft.SerialNumber,
+		OwnerAccountID: nft.AccountID,
+		Metadata:       metadata,
+		CreatedAt:      parseMirrorTimestamp(nft.CreatedAt),
+		Deleted:        nft.Deleted,
+	}
+}
+
+// parseMirrorTimestamp parses a mirror node timestamp ("seconds.nanoseconds"), returning the zero time if malformed
+func parseMirrorTimestamp(s string) time.Time {
+	secs, nanos, _ := strings.Cut(s, ".")
+	sec, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	nsec, _ := strconv.ParseInt(nanos, 10, 64)
+	return time.Unix(sec, nsec).UTC()
+}
@@ -0,0 +1,39 @@
+//go:build unix
+
+package temporal
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testnet", "zones.json.lock")
+	unlock, err := lockFile(path)
+	require.NoError(t, err)
+
+	// Locks are taken per open file, so a second lock waits even in the same process, as another process would
+	locked := make(chan func())
+	go func() {
+		unlock, err := lockFile(path)
+		assert.NoError(t, err)
+		locked <- unlock
+	}()
+	select {
+	case <-locked:
+		t.Fatal("the file was locked twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case unlock := <-locked:
+		unlock()
+	case <-time.After(5 * time.Second):
+		t.Fatal("the file was not locked once released")
+	}
+}
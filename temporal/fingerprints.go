@@ -0,0 +1,18 @@
+package temporal
+
+import (
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/fingerprint"
+)
+
+// Kinds of identifiers fingerprinted
+const (
+	FingerprintRegistrant = "registrant"
+)
+
+// fingerprintKey loads the key of registrant fingerprints, or returns nil when none are recorded
+func (a *Activities) fingerprintKey() (*fingerprint.Key, error) {
+	if a.Config.Events.FingerprintKeyFile == "" {
+		return nil, nil
+	}
+	return fingerprint.LoadKey(a.Config.Events.FingerprintKeyFile)
+}
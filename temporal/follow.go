@@ -0,0 +1,384 @@
+package temporal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/compressed"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+)
+
+// ErrTypeInvalidFollow is the application error type of follow requests that can never succeed
+const ErrTypeInvalidFollow = "InvalidFollow"
+
+const (
+	// DefaultFollowPollInterval is how often a followed file is checked for new lines when the request does not say
+	DefaultFollowPollInterval = 10 * time.Second
+	// DefaultFollowBatchSize is the maximum number of lines read per poll when the request does not say
+	DefaultFollowBatchSize = 1000
+)
+
+// followPollsPerRun bounds the history of one FollowFileWorkflow run, after which it continues as new
+const followPollsPerRun = 100
+
+// fingerprintSize is the number of leading bytes that identify a followed file across renames
+const fingerprintSize = 1024
+
+// FileCursor is the position of a FollowFileWorkflow in the file it follows.
+// The file is identified by the fingerprint of its first bytes, so a rotation is noticed
+// whether the file was renamed and recreated or truncated in place.
+type FileCursor struct {
+	Offset      int64  `json:"offset"`      // Byte offset after the last line read
+	Line        int    `json:"line"`        // Lines read from the current file
+	Fingerprint string `json:"fingerprint"` // Hex of the first bytes of the current file, at most fingerprintSize
+	Generation  int    `json:"generation"`  // Rotations seen since the workflow started
+}
+
+// FollowBatch is the result of ReadNewLinesActivity
+type FollowBatch struct {
+	Lines      []string
+	FirstLine  int        // Line number of Lines[0] in the file it was read from
+	Generation int        // Generation of the file the lines were read from
+	Cursor     FileCursor // Position after the batch
+	More       bool       // More complete lines are ready to be read
+}
+
+// FollowRequest is the input of FollowFileWorkflow
+type FollowRequest struct {
+	FilePath       string
+	PollInterval   time.Duration      // DefaultFollowPollInterval if zero
+	BatchSize      int                // Lines per batch, DefaultFollowBatchSize if zero
+	FromEnd        bool               // Only ingest lines appended after the workflow started
+	ZoneTaskQueues map[string]string  // zone -> task queue for sharded zones, other zones use the parent's queue
+	HCS            config.HCSConfig   // HCS topics the mints are published to
+	Zones          config.ZonesConfig // Zones ingested, the domains of other zones are refused before their collection is created
+
+	// How long minted NFTs may take to show on the mirror node, not checked if zero (MIRROR_VISIBILITY_TIMEOUT)
+	VisibilityTimeout time.Duration
+
+	// Carried over when the workflow continues as new
+	Started bool
+	Cursor  FileCursor
+	Minted  int
+	Burned  int
+	Skipped int
+	Ignored int
+	Failed  int
+}
+
+// FollowProgress is returned by the progress query of FollowFileWorkflow
+type FollowProgress struct {
+	FilePath   string     `json:"file_path"`
+	Cursor     FileCursor `json:"cursor"`
+	Minted     int        `json:"minted"`
+	Burned     int        `json:"burned"`
+	Skipped    int        `json:"skipped"`
+	Ignored    int        `json:"ignored"`
+	Failed     int        `json:"failed"`
+	LastPollAt time.Time  `json:"last_poll_at"`
+}
+
+// FollowFileWorkflow ingests a growing log file continuously, like tail -f: new complete lines are read every
+// poll interval and their events minted by a ProcessZoneWorkflow child per zone. When the file is rotated,
+// the rest of the old file is read first if it can still be found next to the new one. The workflow runs
+// until it is canceled; its cursor is carried over when it continues as new.
+func FollowFileWorkflow(ctx workflow.Context, req FollowRequest) error {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting follow workflow", "filePath", req.FilePath, "cursor", req.Cursor)
+
+	if req.FilePath == "" || compressed.IsCompressed(req.FilePath) {
+		return temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("cannot follow %q, a growing uncompressed file is required", req.FilePath), ErrTypeInvalidFollow, nil)
+	}
+	if req.PollInterval <= 0 {
+		req.PollInterval = DefaultFollowPollInterval
+	}
+	if req.BatchSize <= 0 {
+		req.BatchSize = DefaultFollowBatchSize
+	}
+	ctx = workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	progress := FollowProgress{FilePath: req.FilePath}
+	if err := workflow.SetQueryHandler(ctx, ProgressQuery, func() (FollowProgress, error) {
+		progress.Cursor, progress.Minted, progress.Skipped, progress.Failed = req.Cursor, req.Minted, req.Skipped, req.Failed
+		progress.Burned, progress.Ignored = req.Burned, req.Ignored
+		return progress, nil
+	}); err != nil {
+		return err
+	}
+
+	if !req.Started {
+		if req.FromEnd {
+			if err := workflow.ExecuteActivity(ctx, "FileCursorAtEndActivity", req.FilePath).Get(ctx, &req.Cursor); err != nil {
+				logger.Error("Failed to find the end of the file", "error", err)
+				return err
+			}
+		}
+		req.Started = true
+	}
+
+	workflowID := workflow.GetInfo(ctx).WorkflowExecution.ID
+	for poll := 0; poll < followPollsPerRun; poll++ {
+		var batch FollowBatch
+		err := workflow.ExecuteActivity(ctx, "ReadNewLinesActivity", req.FilePath, req.Cursor, req.BatchSize).Get(ctx, &batch)
+		if err != nil {
+			logger.Error("Failed to read followed file", "error", err)
+			return err
+		}
+		progress.LastPollAt = workflow.Now(ctx)
+		if batch.Cursor.Generation != req.Cursor.Generation {
+			logger.Info("Followed file was rotated", "generation", batch.Cursor.Generation)
+		}
+
+		if len(batch.Lines) > 0 {
+			var mintingInfos []MintingInfo
+			if err := workflow.ExecuteActivity(ctx, "ParseAndFilterEventsActivity", batch.Lines).Get(ctx, &mintingInfos); err != nil {
+				logger.Error("Failed to parse events", "error", err)
+				return err
+			}
+			for i := range mintingInfos {
+				mintingInfos[i].LineNumber += batch.FirstLine - 1
+			}
+			recordHostEvents(ctx, req.HCS, req.Zones, batch.Lines, batch.FirstLine, nil)
+			recordContactEvents(ctx, req.HCS, req.Zones, batch.Lines, batch.FirstLine, nil)
+			var suppressed int
+			if mintingInfos, suppressed = suppressDuplicates(mintingInfos); suppressed > 0 {
+				logger.Warn("Suppressed events repeated in the batch", "count", suppressed)
+			}
+			if len(mintingInfos) > 0 {
+				prefix := fmt.Sprintf("%s_gen_%d", workflowID, batch.Generation)
+				if err := followBatch(ctx, prefix, &req, mintingInfos); err != nil {
+					return err
+				}
+			}
+		}
+		req.Cursor = batch.Cursor
+
+		if !batch.More {
+			if err := workflow.Sleep(ctx, req.PollInterval); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Keep the history of a long-running follow bounded
+	logger.Info("Continuing follow workflow as new", "cursor", req.Cursor)
+	return workflow.NewContinueAsNewError(ctx, FollowFileWorkflow, req)
+}
+
+// followBatch mints the events read in one poll, a child workflow per zone, and adds the outcome to req
+func followBatch(ctx workflow.Context, prefix string, req *FollowRequest, mintingInfos []MintingInfo) error {
+	logger := workflow.GetLogger(ctx)
+
+	zoneGroups, zones, refused := groupByZone(mintingInfos, req.Zones)
+	for zone, count := range refused {
+		logger.Warn("Refusing domains of a zone not allowed", "zone", zone, "domainCount", count)
+	}
+
+	firstLine := mintingInfos[0].LineNumber
+	children := make([]workflow.ChildWorkflowFuture, len(zones))
+	for i, zone := range zones {
+		childOptions := workflow.ChildWorkflowOptions{
+			WorkflowID:          fmt.Sprintf("%s_line_%d_zone_%s", prefix, firstLine, zone),
+			WaitForCancellation: true,
+		}
+		if queue, sharded := req.ZoneTaskQueues[zone]; sharded {
+			childOptions.TaskQueue = queue
+		}
+		logger.Info("Processing followed events", "zone", zone, "firstLine", firstLine, "domainCount", len(zoneGroups[zone]))
+		zoneBatch := ZoneBatch{
+			Zone:              zone,
+			Domains:           zoneGroups[zone],
+			VisibilityTimeout: req.VisibilityTimeout,
+		}
+		zoneBatchTopics(&zoneBatch, req.HCS)
+		childCtx := workflow.WithChildOptions(ctx, childOptions)
+		children[i] = workflow.ExecuteChildWorkflow(childCtx, ProcessZoneWorkflow, zoneBatch)
+	}
+
+	for i, child := range children {
+		var progress ZoneProgress
+		err := child.Get(ctx, &progress)
+		var canceledErr *temporal.CanceledError
+		if errors.As(err, &canceledErr) && canceledErr.HasDetails() {
+			_ = canceledErr.Details(&progress)
+		}
+		req.Minted += progress.Minted
+		req.Burned += progress.Burned
+		req.Skipped += progress.Skipped
+		req.Ignored += progress.Ignored
+		req.Failed += progress.Failed
+		if err != nil {
+			logger.Error("Failed to process followed events", "zone", zones[i], "error", err)
+		}
+	}
+	if ctx.Err() != nil {
+		return temporal.NewCanceledError()
+	}
+	return nil
+}
+
+// FileCursorAtEndActivity returns a cursor after the last complete line of a file, to follow only new lines
+func (a *Activities) FileCursorAtEndActivity(ctx context.Context, filePath string) (FileCursor, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return FileCursor{}, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	fingerprint, err := readFingerprint(file)
+	if err != nil {
+		return FileCursor{}, err
+	}
+	cursor := FileCursor{Fingerprint: fingerprint}
+	r := bufio.NewReader(file)
+	for {
+		line, err := r.ReadString('\n')
+		if err == io.EOF {
+			return cursor, nil // A partial last line is read once it is complete
+		}
+		if err != nil {
+			return FileCursor{}, fmt.Errorf("failed to read file: %w", err)
+		}
+		cursor.Offset += int64(len(line))
+		cursor.Line++
+	}
+}
+
+// ReadNewLinesActivity reads up to maxLines complete lines appended to a followed file after the cursor.
+// When the file was rotated, the rest of the old file is read first if a file with its fingerprint is still in
+// the same directory, e.g. events.log.1; then the new file is read from its start. Rotated files that were
+// compressed cannot be found, and the lines they got after the previous poll are not ingested.
+func (a *Activities) ReadNewLinesActivity(ctx context.Context, filePath string, cursor FileCursor, maxLines int) (FollowBatch, error) {
+	file, err := os.Open(filePath)
+	if os.IsNotExist(err) {
+		// Between the rename and the creation of the new file of a rotation
+		return FollowBatch{FirstLine: cursor.Line + 1, Generation: cursor.Generation, Cursor: cursor}, nil
+	}
+	if err != nil {
+		return FollowBatch{}, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return FollowBatch{}, fmt.Errorf("failed to stat file: %w", err)
+	}
+	fingerprint, err := readFingerprint(file)
+	if err != nil {
+		return FollowBatch{}, err
+	}
+
+	if cursor.Fingerprint != "" && (info.Size() < cursor.Offset || !strings.HasPrefix(fingerprint, cursor.Fingerprint)) {
+		// Rotated: finish the old file if it is still around
+		if rotated := findRotatedFile(filePath, cursor); rotated != "" {
+			old, err := os.Open(rotated)
+			if err != nil {
+				return FollowBatch{}, fmt.Errorf("failed to open rotated file: %w", err)
+			}
+			defer old.Close()
+			batch, err := readLines(old, cursor, maxLines, true)
+			if err != nil {
+				return FollowBatch{}, err
+			}
+			if len(batch.Lines) > 0 {
+				fmt.Printf("Read %d lines of rotated file %s\n", len(batch.Lines), rotated)
+				batch.More = true // The new file is read next
+				return batch, nil
+			}
+		} else {
+			fmt.Printf("Rotated file of %s not found, lines appended to it after offset %d are not ingested\n", filePath, cursor.Offset)
+		}
+		cursor = FileCursor{Fingerprint: fingerprint, Generation: cursor.Generation + 1}
+	}
+	cursor.Fingerprint = fingerprint
+
+	return readLines(file, cursor, maxLines, false)
+}
+
+// readLines reads up to maxLines lines after the cursor. A partial last line is only read from a finished file,
+// in a file that is still written it is read once it is complete.
+func readLines(file *os.File, cursor FileCursor, maxLines int, finished bool) (FollowBatch, error) {
+	batch := FollowBatch{FirstLine: cursor.Line + 1, Generation: cursor.Generation}
+	if _, err := file.Seek(cursor.Offset, io.SeekStart); err != nil {
+		return FollowBatch{}, fmt.Errorf("failed to seek file: %w", err)
+	}
+	r := bufio.NewReader(file)
+	for len(batch.Lines) < maxLines {
+		line, err := r.ReadString('\n')
+		if err == io.EOF && (!finished || line == "") {
+			break
+		}
+		if err != nil && err != io.EOF {
+			return FollowBatch{}, fmt.Errorf("failed to read file: %w", err)
+		}
+		cursor.Offset += int64(len(line))
+		cursor.Line++
+		batch.Lines = append(batch.Lines, strings.TrimRight(line, "\r\n"))
+	}
+	if len(batch.Lines) == maxLines {
+		_, err := r.ReadString('\n')
+		batch.More = err == nil
+	}
+	batch.Cursor = cursor
+	return batch, nil
+}
+
+// readFingerprint returns the hex of the first fingerprintSize bytes of a file, fewer if the file is shorter
+func readFingerprint(file *os.File) (string, error) {
+	buf := make([]byte, fingerprintSize)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to seek file: %w", err)
+	}
+	return hex.EncodeToString(buf[:n]), nil
+}
+
+// findRotatedFile returns the file next to a followed file that starts with the fingerprint of the cursor
+// and holds at least the bytes already read, or "" if there is none
+func findRotatedFile(filePath string, cursor FileCursor) string {
+	want, err := hex.DecodeString(cursor.Fingerprint)
+	if err != nil || len(want) == 0 {
+		return ""
+	}
+	dir := filepath.Dir(filePath)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		candidate := filepath.Join(dir, entry.Name())
+		if entry.IsDir() || filepath.Clean(candidate) == filepath.Clean(filePath) || compressed.IsCompressed(candidate) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.Size() < cursor.Offset {
+			continue
+		}
+		file, err := os.Open(candidate)
+		if err != nil {
+			continue
+		}
+		head := make([]byte, len(want))
+		_, err = io.ReadFull(file, head)
+		file.Close()
+		if err == nil && bytes.Equal(head, want) {
+			return candidate
+		}
+	}
+	return ""
+}
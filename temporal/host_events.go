@@ -0,0 +1,165 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/eventschema"
+)
+
+// HostRecordVersion is the version of the host record format
+const HostRecordVersion = 1
+
+// Actions of host events, others are not recorded
+const (
+	HostCreated = "create"
+	HostUpdated = "update"
+	HostDeleted = "delete"
+)
+
+// HostEvent is a nameserver created, updated or deleted at the registry
+type HostEvent struct {
+	Host        string   `json:"host"`
+	Zone        string   `json:"zone"` // Zone of the registry that sent the event, the host may be outside of it
+	Action      string   `json:"action"`
+	RegistrarID string   `json:"registrar,omitempty"`
+	Addresses   []string `json:"addresses,omitempty"`
+	Timestamp   string   `json:"timestamp,omitempty"` // As written by the registry
+	EventHash   string   `json:"event_hash"`
+	LineNumber  int      `json:"line"`
+}
+
+// HostRecord is the message recording a host event on the infrastructure topic of its zone
+type HostRecord struct {
+	Version   int      `json:"v"`
+	Type      string   `json:"type"` // Always "host"
+	Action    string   `json:"action"`
+	Host      string   `json:"host"`
+	Zone      string   `json:"zone"`
+	Addresses []string `json:"ip,omitempty"`
+	Registrar string   `json:"registrar,omitempty"`
+	Timestamp string   `json:"timestamp,omitempty"`
+	EventHash string   `json:"event_hash"` // Canonical hash of the registry event, see pkg/eventhash
+}
+
+// InfraTopicName returns the topic registry name of the infrastructure topic of a zone
+func InfraTopicName(prefix, zone string) string {
+	return prefix + "-" + zone
+}
+
+// ParseHostEventsActivity parses the host events of event log lines, with their signature verified as for
+// domain events. Host names are normalized and validated as host names, see domain.NewHostName. Lines that are
+// not host events are left out, and so are host events that cannot be decoded, whose host name is invalid or
+// whose action is not a create, update or delete: ParseAndFilterEventsActivity quarantines the former.
+func (a *Activities) ParseHostEventsActivity(ctx context.Context, lines []string) ([]HostEvent, error) {
+	parser, err := a.eventParser()
+	if err != nil {
+		return nil, err
+	}
+	var events []HostEvent
+	for i, line := range lines {
+		decoded, quarantined, err := parser.decode(line, i+1)
+		if err != nil || quarantined != nil || decoded.ObjectType() != eventschema.TypeHost {
+			continue
+		}
+		zone, err := domain.NewZone(decoded.Zone)
+		if err != nil {
+			fmt.Printf("Skipping host event on line %d: %v\n", i+1, err)
+			continue
+		}
+		host, err := domain.NewHostName(decoded.DomainName)
+		if err != nil {
+			fmt.Printf("Skipping host event on line %d: invalid host name %q: %v\n", i+1, decoded.DomainName, err)
+			continue
+		}
+		switch decoded.Action {
+		case HostCreated, HostUpdated, HostDeleted:
+		default:
+			fmt.Printf("Skipping host event on line %d: unknown action %q\n", i+1, decoded.Action)
+			continue
+		}
+		events = append(events, HostEvent{
+			Host:        host.String(),
+			Zone:        zone.String(),
+			Action:      decoded.Action,
+			RegistrarID: decoded.RegistrarID,
+			Addresses:   decoded.Addresses,
+			Timestamp:   decoded.Timestamp,
+			EventHash:   decoded.EventHash,
+			LineNumber:  i + 1,
+		})
+	}
+	return events, nil
+}
+
+// PublishHostEventsActivity records the host events of a zone on its infrastructure topic, creating the topic on
+// first use. As for receipts, only the operator can submit to the topic, and the records are enveloped with
+// HCS_PRODUCER_KEY set. A retried attempt continues after the events recorded, see publishAuditRecords.
+func (a *Activities) PublishHostEventsActivity(ctx context.Context, topicName, zone string, events []HostEvent) (int, error) {
+	topic, err := a.LookupOrCreateTopicActivity(ctx, topicName, fmt.Sprintf("Nameservers of .%s in the shadow domain ledger", zone), true, true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up infrastructure topic: %w", err)
+	}
+	records := make([]any, len(events))
+	for i, event := range events {
+		records[i] = HostRecord{
+			Version:   HostRecordVersion,
+			Type:      eventschema.TypeHost,
+			Action:    event.Action,
+			Host:      event.Host,
+			Zone:      event.Zone,
+			Addresses: event.Addresses,
+			Registrar: event.RegistrarID,
+			Timestamp: event.Timestamp,
+			EventHash: event.EventHash,
+		}
+	}
+	recorded, err := a.publishAuditRecords(ctx, topic.TopicID, "host records of ."+zone, records)
+	if err != nil {
+		return recorded, err
+	}
+	fmt.Printf("Recorded %d host events of .%s on topic %s\n", len(events), zone, a.displayID(topic.TopicID))
+	return recorded, nil
+}
+
+// recordHostEvents records the host events of event log lines on the infrastructure topics of their zones, when
+// HCS_INFRA_TOPIC is set. firstLine is the line number of the first line, host events of a zone on or before its
+// line in resumeFrom were recorded by an earlier run. Failures only warn, the registrations are minted regardless.
+func recordHostEvents(ctx workflow.Context, hcs config.HCSConfig, zones config.ZonesConfig, lines []string, firstLine int, resumeFrom map[string]int) {
+	if hcs.InfraTopic == "" || len(lines) == 0 {
+		return
+	}
+	logger := workflow.GetLogger(ctx)
+	var events []HostEvent
+	if err := workflow.ExecuteActivity(ctx, "ParseHostEventsActivity", lines).Get(ctx, &events); err != nil {
+		logger.Warn("Failed to parse host events", "error", err)
+		return
+	}
+	byZone := make(map[string][]HostEvent)
+	for _, event := range events {
+		event.LineNumber += firstLine - 1
+		if zones.Allowed(event.Zone) && event.LineNumber > resumeFrom[event.Zone] {
+			byZone[event.Zone] = append(byZone[event.Zone], event)
+		}
+	}
+	// In order, so replays schedule the same activities
+	zoneNames := make([]string, 0, len(byZone))
+	for zone := range byZone {
+		zoneNames = append(zoneNames, zone)
+	}
+	sort.Strings(zoneNames)
+	for _, zone := range zoneNames {
+		err := workflow.ExecuteActivity(ctx, "PublishHostEventsActivity", InfraTopicName(hcs.InfraTopic, zone), zone, byZone[zone]).Get(ctx, nil)
+		if err != nil {
+			logger.Warn("Failed to record host events", "zone", zone, "count", len(byZone[zone]), "error", err)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
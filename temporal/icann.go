@@ -0,0 +1,155 @@
+package temporal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/icann"
+)
+
+// Metrics compared between an ICANN transaction report and the ledger
+const (
+	MetricAdds         = "adds"
+	MetricDeletes      = "deletes"
+	MetricTotalDomains = "total_domains"
+)
+
+// Discrepancy is a count on which an ICANN transaction report and the ledger disagree
+type Discrepancy struct {
+	Metric     string `json:"metric"`
+	Report     int    `json:"report"`
+	Ledger     int    `json:"ledger"`
+	Difference int    `json:"difference"` // Ledger minus report
+}
+
+// ICANNReconciliation compares the monthly transaction report of a TLD with the ledger of its zone
+type ICANNReconciliation struct {
+	ReportFile    string                  `json:"report_file"`
+	Zone          string                  `json:"zone"`
+	Month         string                  `json:"month"` // yyyy-mm
+	TokenID       string                  `json:"token_id"`
+	Report        icann.TransactionReport `json:"report"`
+	LedgerAdds    int                     `json:"ledger_adds"`    // NFTs minted during the month
+	LedgerDeletes int                     `json:"ledger_deletes"` // NFTs burned during the month
+	LedgerTotal   int                     `json:"ledger_total"`   // NFTs held at the end of the month
+	Discrepancies []Discrepancy           `json:"discrepancies"`
+	CheckedAt     time.Time               `json:"checked_at"`
+	ReportPath    string                  `json:"report_path,omitempty"` // Where this reconciliation was written
+}
+
+// ReconcileICANNReportActivity parses an ICANN monthly transaction report and compares its adds, deletes and
+// total domains with the mints and burns of the zone collection during that month on the mirror node. The
+// zone and month default to those in the report file name. The reconciliation is written to the report
+// directory as icann_<zone>_<yyyymm>.json, replacing an earlier one of the same month.
+func (a *Activities) ReconcileICANNReportActivity(ctx context.Context, path, zone string, month time.Time) (ICANNReconciliation, error) {
+	if zone == "" || month.IsZero() {
+		tld, fileMonth, err := icann.ParseFileName(path)
+		if err != nil {
+			return ICANNReconciliation{}, fmt.Errorf("%w; pass the zone and month explicitly", err)
+		}
+		if zone == "" {
+			zone = tld
+		}
+		if month.IsZero() {
+			month = fileMonth
+		}
+	}
+	month = time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return ICANNReconciliation{}, err
+	}
+	report, err := icann.Parse(file)
+	file.Close()
+	if err != nil {
+		return ICANNReconciliation{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	registry, err := a.loadZoneRegistry()
+	if err != nil {
+		return ICANNReconciliation{}, fmt.Errorf("failed to load zone registry: %w", err)
+	}
+	collection, ok := registry.Collections[domain.Zone(zone)]
+	if !ok {
+		return ICANNReconciliation{}, fmt.Errorf("no collection for zone %s in the zone registry", zone)
+	}
+
+	rec := ICANNReconciliation{
+		ReportFile: path,
+		Zone:       zone,
+		Month:      month.Format("2006-01"),
+		TokenID:    collection.TokenID,
+		Report:     report,
+	}
+	if err := a.countMonthNFTs(ctx, &rec, month, month.AddDate(0, 1, 0)); err != nil {
+		return rec, fmt.Errorf("failed to count the NFTs of %s: %w", collection.TokenID, err)
+	}
+	for _, d := range []Discrepancy{
+		{Metric: MetricAdds, Report: report.Adds, Ledger: rec.LedgerAdds},
+		{Metric: MetricDeletes, Report: report.Deletes, Ledger: rec.LedgerDeletes},
+		{Metric: MetricTotalDomains, Report: report.TotalDomains, Ledger: rec.LedgerTotal},
+	} {
+		if d.Ledger != d.Report {
+			d.Difference = d.Ledger - d.Report
+			rec.Discrepancies = append(rec.Discrepancies, d)
+		}
+	}
+	rec.CheckedAt = time.Now().UTC()
+
+	if err := os.MkdirAll(a.Config.Reports.Dir, 0755); err != nil {
+		return rec, fmt.Errorf("failed to create report directory: %w", err)
+	}
+	rec.ReportPath = filepath.Join(a.Config.Reports.Dir, fmt.Sprintf("icann_%s_%s.json", zone, month.Format("200601")))
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return rec, fmt.Errorf("failed to marshal reconciliation: %w", err)
+	}
+	if err := os.WriteFile(rec.ReportPath, data, 0644); err != nil {
+		return rec, fmt.Errorf("failed to write reconciliation: %w", err)
+	}
+	fmt.Printf("Wrote reconciliation of %s to %s\n", path, rec.ReportPath)
+	return rec, nil
+}
+
+// countMonthNFTs counts the NFTs of a collection minted and burned in [start, end) and held at end.
+// A burned NFT carries the time of its burn as its last modification.
+func (a *Activities) countMonthNFTs(ctx context.Context, rec *ICANNReconciliation, start, end time.Time) error {
+	path := fmt.Sprintf("/tokens/%s/nfts?limit=100&order=asc", rec.TokenID)
+	for path != "" {
+		var response MirrorNodeNFTsResponse
+		if err := a.mirrorGet(ctx, path, &response); err != nil {
+			return err
+		}
+		for _, nft := range response.NFTs {
+			created := parseMirrorTimestamp(nft.CreatedAt)
+			if !created.Before(end) {
+				continue
+			}
+			if !created.Before(start) {
+				rec.LedgerAdds++
+			}
+			burned := nft.Deleted && parseMirrorTimestamp(nft.ModifiedAt).Before(end)
+			if !burned {
+				rec.LedgerTotal++
+			} else if !parseMirrorTimestamp(nft.ModifiedAt).Before(start) {
+				rec.LedgerDeletes++
+			}
+		}
+		heartbeat(ctx, rec.Zone, rec.LedgerTotal)
+
+		path = ""
+		if response.Links.Next != "" {
+			var err error
+			if path, err = a.mirrorNextPath(response.Links.Next); err != nil {
+				return fmt.Errorf("invalid pagination link: %w", err)
+			}
+		}
+	}
+	return nil
+}
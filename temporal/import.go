@@ -0,0 +1,251 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/compressed"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domainlist"
+)
+
+// DefaultImportBatchSize is the number of domains minted per batch when the import request does not say
+const DefaultImportBatchSize = 100
+
+// importBatchesPerRun bounds the history of one ImportDomainListWorkflow run, after which it continues as new
+const importBatchesPerRun = 50
+
+// DomainListImportRequest is the input of ImportDomainListWorkflow
+type DomainListImportRequest struct {
+	FilePath       string             // Plain list of domains, one per line, or CSV if the file ends in .csv
+	BatchSize      int                // Domains per batch, DefaultImportBatchSize if zero
+	BatchInterval  time.Duration      // Pause between batches, to spread the mints over time
+	ZoneTaskQueues map[string]string  // zone -> task queue for sharded zones, other zones use the parent's queue
+	HCS            config.HCSConfig   // HCS topics the mints are published to
+	Zones          config.ZonesConfig // Zones ingested, the domains of other zones are refused before their collection is created
+	MaxMints       int                // Domains the whole import may mint at most, unlimited if zero (MAX_MINTS_PER_RUN)
+
+	// How long minted NFTs may take to show on the mirror node, not checked if zero (MIRROR_VISIBILITY_TIMEOUT)
+	VisibilityTimeout time.Duration
+
+	// Carried over when the workflow continues as new
+	AfterLine     int // Lines up to this one were imported by earlier runs
+	Minted        int
+	Skipped       int
+	Failed        int
+	Invalid       int
+	FeesTinybar   int64
+	BudgetTinybar int64   // Fees the import may spend before it pauses, unlimited if zero (RUN_BUDGET_HBAR)
+	BudgetUSD     float64 // Budget in US dollars, converted by the first run when BudgetTinybar is zero (RUN_BUDGET_USD)
+}
+
+// DomainListBatch is the result of ReadDomainListActivity
+type DomainListBatch struct {
+	Domains  []MintingInfo
+	LastLine int  // Line of the last entry read, the cursor of the next batch
+	Invalid  int  // Invalid entries skipped in this batch
+	Done     bool // The end of the list was reached
+}
+
+// DomainListImportProgress is returned by the progress query of ImportDomainListWorkflow
+type DomainListImportProgress struct {
+	FilePath  string `json:"file_path"`
+	AfterLine int    `json:"after_line"`
+	Minted    int    `json:"minted"`
+	Skipped   int    `json:"skipped"`
+	Failed    int    `json:"failed"`
+	Invalid   int    `json:"invalid"`
+
+	FeesTinybar   int64 `json:"fees_tinybar"`
+	BudgetTinybar int64 `json:"budget_tinybar,omitempty"`
+	Paused        bool  `json:"paused,omitempty"` // The budget is spent, see BudgetSignal
+}
+
+// ReadDomainListActivity reads the next batch of at most limit domains after the given line of a domain list.
+// Invalid entries are logged and counted but do not fail the import. Compressed lists are decompressed while read.
+func (a *Activities) ReadDomainListActivity(ctx context.Context, filePath string, afterLine, limit int) (DomainListBatch, error) {
+	file, err := compressed.Open(filePath)
+	if err != nil {
+		return DomainListBatch{}, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	batch := DomainListBatch{LastLine: afterLine, Done: true}
+	err = domainlist.Read(file, domainlist.IsCSV(compressed.TrimExt(filePath)), func(entry domainlist.Entry) error {
+		if entry.Line <= afterLine {
+			return nil
+		}
+		if len(batch.Domains) == limit {
+			batch.Done = false
+			return domainlist.ErrStop
+		}
+		batch.LastLine = entry.Line
+		if entry.Err != nil {
+			fmt.Printf("Skipping invalid entry: %v\n", entry.Err)
+			batch.Invalid++
+			return nil
+		}
+		zone, err := domain.NewZone(entry.Zone)
+		if err != nil {
+			fmt.Printf("Skipping invalid entry on line %d: %v\n", entry.Line, err)
+			batch.Invalid++
+			return nil
+		}
+		batch.Domains = append(batch.Domains, MintingInfo{
+			DomainName:       entry.Domain,
+			RegistrationTime: entry.RegisteredAt,
+			RegistrarID:      entry.RegistrarID,
+			Zone:             zone,
+			LineNumber:       entry.Line,
+		})
+		return nil
+	})
+	if err != nil {
+		return DomainListBatch{}, fmt.Errorf("failed to read domain list: %w", err)
+	}
+	return batch, nil
+}
+
+// ImportDomainListWorkflow mints the domains of a list of existing registrations, so a registry can bootstrap
+// the ledger of a zone that predates event logging. The list is minted in batches, pausing between batches;
+// the domains of each batch are minted by a ProcessZoneWorkflow child per zone. Domains that are already
+// minted are skipped, so an interrupted import can simply be started again.
+func ImportDomainListWorkflow(ctx workflow.Context, req DomainListImportRequest) error {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting domain list import workflow", "filePath", req.FilePath, "afterLine", req.AfterLine)
+
+	ctx = workflow.WithActivityOptions(ctx, defaultActivityOptions())
+	if req.BatchSize <= 0 {
+		req.BatchSize = DefaultImportBatchSize
+	}
+
+	if err := workflow.SetQueryHandler(ctx, ProgressQuery, func() (DomainListImportProgress, error) {
+		return DomainListImportProgress{
+			FilePath:  req.FilePath,
+			AfterLine: req.AfterLine,
+			Minted:    req.Minted,
+			Skipped:   req.Skipped,
+			Failed:    req.Failed,
+			Invalid:   req.Invalid,
+
+			FeesTinybar:   req.FeesTinybar,
+			BudgetTinybar: req.BudgetTinybar,
+			Paused:        budgetSpent(req.FeesTinybar, req.BudgetTinybar),
+		}, nil
+	}); err != nil {
+		return err
+	}
+
+	// A budget in US dollars is converted once, later runs carry it in tinybar
+	budget, err := resolveBudget(ctx, req.BudgetTinybar, req.BudgetUSD)
+	if err != nil {
+		logger.Error("Failed to convert the budget of the import", "error", err)
+		return err
+	}
+	req.BudgetTinybar, req.BudgetUSD = budget, 0
+
+	workflowID := workflow.GetInfo(ctx).WorkflowExecution.ID
+	for i := 0; i < importBatchesPerRun; i++ {
+		// The budget is checked between batches, the fees of a batch may exceed what is left of it
+		if err := awaitBudget(ctx, req.FeesTinybar, &req.BudgetTinybar); err != nil {
+			return err
+		}
+
+		var batch DomainListBatch
+		err := workflow.ExecuteActivity(ctx, "ReadDomainListActivity", req.FilePath, req.AfterLine, req.BatchSize).Get(ctx, &batch)
+		if err != nil {
+			logger.Error("Failed to read domain list", "error", err)
+			return err
+		}
+		req.Invalid += batch.Invalid
+
+		if len(batch.Domains) > 0 {
+			if err := importBatch(ctx, workflowID, &req, batch); err != nil {
+				return err
+			}
+		}
+		req.AfterLine = batch.LastLine
+
+		if batch.Done {
+			logger.Info("Completed domain list import workflow",
+				"minted", req.Minted, "skipped", req.Skipped, "failed", req.Failed, "invalid", req.Invalid)
+			return nil
+		}
+		if req.BatchInterval > 0 {
+			if err := workflow.Sleep(ctx, req.BatchInterval); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Keep the history of a long import bounded
+	logger.Info("Continuing domain list import as new", "afterLine", req.AfterLine)
+	return workflow.NewContinueAsNewError(ctx, ImportDomainListWorkflow, req)
+}
+
+// importBatch mints one batch of a domain list import, a child workflow per zone, and adds the outcome to req
+func importBatch(ctx workflow.Context, workflowID string, req *DomainListImportRequest, batch DomainListBatch) error {
+	logger := workflow.GetLogger(ctx)
+
+	zoneGroups, zones, refused := groupByZone(batch.Domains, req.Zones)
+	for zone, count := range refused {
+		logger.Warn("Refusing domains of a zone not allowed", "zone", zone, "domainCount", count)
+	}
+
+	// Stop the import before the batch that could take it past the cap, the progress query reports what was minted
+	pending := req.Minted
+	for _, zone := range zones {
+		pending += len(zoneGroups[zone])
+	}
+	if err := checkMintCap(pending, req.MaxMints); err != nil {
+		logger.Error("Refusing to mint more domains than the cap", "minted", req.Minted, "pending", pending, "maxMints", req.MaxMints)
+		return err
+	}
+
+	firstLine := batch.Domains[0].LineNumber
+	children := make([]workflow.ChildWorkflowFuture, len(zones))
+	for i, zone := range zones {
+		childOptions := workflow.ChildWorkflowOptions{
+			WorkflowID:          fmt.Sprintf("%s_line_%d_zone_%s", workflowID, firstLine, zone),
+			WaitForCancellation: true,
+		}
+		if queue, sharded := req.ZoneTaskQueues[zone]; sharded {
+			childOptions.TaskQueue = queue
+		}
+		logger.Info("Importing zone batch", "zone", zone, "firstLine", firstLine, "domainCount", len(zoneGroups[zone]))
+		zoneBatch := ZoneBatch{
+			Zone:              zone,
+			Domains:           zoneGroups[zone],
+			VisibilityTimeout: req.VisibilityTimeout,
+		}
+		zoneBatchTopics(&zoneBatch, req.HCS)
+		childCtx := workflow.WithChildOptions(ctx, childOptions)
+		children[i] = workflow.ExecuteChildWorkflow(childCtx, ProcessZoneWorkflow, zoneBatch)
+	}
+
+	for i, child := range children {
+		var progress ZoneProgress
+		err := child.Get(ctx, &progress)
+		var canceledErr *temporal.CanceledError
+		if errors.As(err, &canceledErr) && canceledErr.HasDetails() {
+			_ = canceledErr.Details(&progress)
+		}
+		req.Minted += progress.Minted
+		req.Skipped += progress.Skipped
+		req.Failed += progress.Failed
+		req.FeesTinybar += progress.FeesTinybar
+		if err != nil {
+			logger.Error("Failed to import zone batch", "zone", zones[i], "error", err)
+		}
+	}
+	if ctx.Err() != nil {
+		return temporal.NewCanceledError()
+	}
+	return nil
+}
@@ -0,0 +1,319 @@
+package temporal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+)
+
+// batchFilesPerRun bounds the history of one IngestBatchWorkflow run, after which it continues as new
+const batchFilesPerRun = 100
+
+// BatchFile is a file of an IngestBatchRequest
+type BatchFile struct {
+	FilePath    string
+	Date        time.Time // Day of the file, from its name, zero if the name carries no date
+	ContentHash string    // SHA-256 of the file content, also the basis of the ingest workflow ID
+}
+
+// IngestBatchRequest is the input of IngestBatchWorkflow
+type IngestBatchRequest struct {
+	Dir             string             // Directory the files were listed from
+	Files           []BatchFile        // Files in the order they are ingested, oldest first
+	ContinueOnError bool               // Go on with the next file when one fails, instead of stopping the batch
+	ZoneTaskQueues  map[string]string  // zone -> task queue for sharded zones, other zones use the parent's queue
+	HCS             config.HCSConfig   // HCS topics the mints are published to
+	Zones           config.ZonesConfig // Zones ingested, the domains of other zones are refused before their collection is created
+	MaxMints        int                // Domains each file may mint at most, unlimited if zero (MAX_MINTS_PER_RUN)
+	BudgetTinybar   int64              // Fees each file may spend before its run pauses, unlimited if zero (RUN_BUDGET_HBAR)
+	BudgetUSD       float64            // Budget in US dollars, converted at the exchange rate of the network when BudgetTinybar is zero (RUN_BUDGET_USD)
+	Notify          bool               // Announce the runs of the files on the webhooks of NOTIFY_WEBHOOKS
+	FailureAlert    int                // Failed domains of a file that raise an alert on the webhooks, none if zero (NOTIFY_FAILURE_THRESHOLD)
+	EmailReport     bool               // Email the report of every file to REPORT_EMAIL_TO
+	ArchiveReport   bool               // Upload the report of every file to REPORT_BUCKET
+	EscalateAfter   int                // Consecutive failures of a zone that page the operators and pause it, never if zero (ESCALATION_THRESHOLD)
+
+	// Scope of the workflow IDs of the ingests, as of this workflow (TemporalConfig.WorkflowIDScope)
+	WorkflowIDScope string
+
+	// How long minted NFTs may take to show on the mirror node, not checked if zero (MIRROR_VISIBILITY_TIMEOUT)
+	VisibilityTimeout time.Duration
+
+	// Carried over when the workflow continues as new
+	Next    int          // Index in Files of the next file to ingest
+	Summary BatchSummary // Outcome of the files ingested so far
+}
+
+// BatchFileResult is the outcome of one file of a batch
+type BatchFileResult struct {
+	FilePath    string    `json:"file_path"`
+	Date        time.Time `json:"date"`
+	ContentHash string    `json:"content_hash"`
+	Outcome     string    `json:"outcome"`
+	WorkflowID  string    `json:"workflow_id,omitempty"` // Ingest run of the content, by this batch or an earlier one
+	RunID       string    `json:"run_id,omitempty"`      // Set for the files ingested by this batch
+	Error       string    `json:"error,omitempty"`
+}
+
+// BatchTotals sums the run reports of the files ingested by a batch
+type BatchTotals struct {
+	Events      int   `json:"events"`
+	Minted      int   `json:"minted"`
+	Burned      int   `json:"burned"`
+	Skipped     int   `json:"skipped"`
+	Ignored     int   `json:"ignored"`
+	Failed      int   `json:"failed"` // Domains that failed, in files that completed or failed
+	FeesTinybar int64 `json:"fees_tinybar"`
+}
+
+// add adds the counts of a zone to the totals
+func (t *BatchTotals) add(zone ZoneProgress) {
+	t.Minted += zone.Minted
+	t.Burned += zone.Burned
+	t.Skipped += zone.Skipped
+	t.Ignored += zone.Ignored
+	t.Failed += zone.Failed
+	t.FeesTinybar += zone.FeesTinybar
+}
+
+// BatchSummary is the consolidated report of a batch, written to REPORT_DIR
+type BatchSummary struct {
+	WorkflowID string                 `json:"workflow_id"`
+	Dir        string                 `json:"dir"`
+	StartedAt  time.Time              `json:"started_at"`
+	FinishedAt time.Time              `json:"finished_at"`
+	Total      int                    `json:"total"` // Files of the batch
+	Ingested   int                    `json:"ingested"`
+	Duplicates int                    `json:"duplicates"`
+	Failed     int                    `json:"failed"`
+	Stopped    bool                   `json:"stopped,omitempty"` // A failure stopped the batch before its last file
+	Canceled   bool                   `json:"canceled,omitempty"`
+	Files      []BatchFileResult      `json:"files"`
+	Totals     BatchTotals            `json:"totals"`                    // Of all the files ingested by the batch
+	Zones      map[string]BatchTotals `json:"zones,omitempty"`           // The totals by zone, without events
+	Missing    []string               `json:"missing_reports,omitempty"` // Ingested files whose run report was not found
+	Report     string                 `json:"report,omitempty"`          // Path of the written report
+}
+
+// add records the outcome of a file in the summary
+func (s *BatchSummary) add(result BatchFileResult) {
+	switch result.Outcome {
+	case FileIngested:
+		s.Ingested++
+	case FileDuplicate:
+		s.Duplicates++
+	case FileFailed:
+		s.Failed++
+	}
+	s.Files = append(s.Files, result)
+}
+
+// IngestBatchWorkflow ingests the event log files of a directory one at a time, in the order of the request,
+// so the events of a month of daily files are applied in chronological order. As for a backfill, content that
+// the ingest ledger records as fully ingested, or that repeats an earlier file of the batch, is skipped, and the
+// first failure stops the batch unless ContinueOnError is set. The run reports of the ingested files are
+// consolidated into one report, written to REPORT_DIR and returned.
+func IngestBatchWorkflow(ctx workflow.Context, req IngestBatchRequest) (BatchSummary, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting batch ingestion workflow", "dir", req.Dir, "files", len(req.Files), "next", req.Next)
+
+	ctx = workflow.WithActivityOptions(ctx, defaultActivityOptions())
+	if err := workflow.SetQueryHandler(ctx, ProgressQuery, func() (BatchSummary, error) {
+		return req.Summary, nil
+	}); err != nil {
+		return req.Summary, err
+	}
+	if req.Summary.StartedAt.IsZero() {
+		req.Summary = BatchSummary{
+			WorkflowID: workflow.GetInfo(ctx).WorkflowExecution.ID,
+			Dir:        req.Dir,
+			StartedAt:  workflow.Now(ctx),
+			Total:      len(req.Files),
+		}
+	}
+
+	// Content hash -> path of the first file of the batch with that content, failed files may be retried
+	seen := make(map[string]string, len(req.Summary.Files))
+	for _, result := range req.Summary.Files {
+		if result.Outcome != FileFailed {
+			if _, ok := seen[result.ContentHash]; !ok {
+				seen[result.ContentHash] = result.FilePath
+			}
+		}
+	}
+
+	for run := 0; req.Next < len(req.Files); run++ {
+		if run == batchFilesPerRun {
+			// Keep the history of a large batch bounded
+			logger.Info("Continuing batch as new", "next", req.Next)
+			return req.Summary, workflow.NewContinueAsNewError(ctx, IngestBatchWorkflow, req)
+		}
+
+		file := req.Files[req.Next]
+		result := ingestBatchFile(ctx, req, file, seen)
+		req.Summary.add(result)
+		req.Next++
+		if _, ok := seen[file.ContentHash]; !ok && result.Outcome != FileFailed {
+			seen[file.ContentHash] = file.FilePath
+		}
+
+		if ctx.Err() != nil {
+			logger.Info("Batch ingestion workflow canceled", "file", file.FilePath)
+			req.Summary.Canceled = true
+			writeBatchReport(ctx, &req.Summary)
+			return req.Summary, temporal.NewCanceledError()
+		}
+		if result.Outcome == FileFailed && !req.ContinueOnError {
+			logger.Error("Stopping batch on failed file", "file", file.FilePath, "error", result.Error)
+			req.Summary.Stopped = true
+			break
+		}
+	}
+
+	writeBatchReport(ctx, &req.Summary)
+	logger.Info("Completed batch ingestion workflow", "ingested", req.Summary.Ingested,
+		"duplicates", req.Summary.Duplicates, "failed", req.Summary.Failed)
+	return req.Summary, nil
+}
+
+// ingestBatchFile ingests one file of a batch with an IngestFileWorkflow child, unless its content was already ingested
+func ingestBatchFile(ctx workflow.Context, req IngestBatchRequest, file BatchFile, seen map[string]string) BatchFileResult {
+	logger := workflow.GetLogger(ctx)
+	result := BatchFileResult{FilePath: file.FilePath, Date: file.Date, ContentHash: file.ContentHash}
+	workflowID := ScopedWorkflowID(req.WorkflowIDScope, IngestWorkflowID(file.ContentHash))
+
+	if earlier, ok := seen[file.ContentHash]; ok {
+		logger.Info("Skipping duplicate content", "file", file.FilePath, "sameAs", earlier)
+		result.Outcome, result.WorkflowID = FileDuplicate, workflowID
+		return result
+	}
+	var previous IngestedFileInfo
+	if err := workflow.ExecuteActivity(ctx, "LookupIngestedFileActivity", file.ContentHash).Get(ctx, &previous); err != nil {
+		logger.Error("Failed to check ingest ledger", "file", file.FilePath, "error", err)
+		result.Outcome, result.Error = FileFailed, err.Error()
+		return result
+	}
+	if previous.Outcome == IngestOutcomeCompleted {
+		logger.Info("Skipping already ingested content", "file", file.FilePath, "workflowID", previous.WorkflowID)
+		result.Outcome, result.WorkflowID = FileDuplicate, previous.WorkflowID
+		return result
+	}
+
+	childOptions := workflow.ChildWorkflowOptions{
+		WorkflowID:            workflowID,
+		WorkflowIDReusePolicy: enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE_FAILED_ONLY,
+		// On cancellation, wait for the ingest to checkpoint and report its run
+		WaitForCancellation: true,
+	}
+	result.WorkflowID = workflowID
+	logger.Info("Ingesting file", "file", file.FilePath, "workflowID", workflowID)
+	childCtx := workflow.WithChildOptions(ctx, childOptions)
+	child := workflow.ExecuteChildWorkflow(childCtx, IngestFileWorkflow, IngestRequest{
+		FilePath:       file.FilePath,
+		ContentHash:    file.ContentHash,
+		ZoneTaskQueues: req.ZoneTaskQueues,
+		HCS:            req.HCS,
+		Zones:          req.Zones,
+		MaxMints:       req.MaxMints,
+		BudgetTinybar:  req.BudgetTinybar,
+		BudgetUSD:      req.BudgetUSD,
+		Notify:         req.Notify,
+		FailureAlert:   req.FailureAlert,
+		EmailReport:    req.EmailReport,
+		ArchiveReport:  req.ArchiveReport,
+		EscalateAfter:  req.EscalateAfter,
+
+		VisibilityTimeout: req.VisibilityTimeout,
+	})
+	// The run ID names the run report of the file, consolidated at the end
+	var execution workflow.Execution
+	if err := child.GetChildWorkflowExecution().Get(ctx, &execution); err == nil {
+		result.RunID = execution.RunID
+	}
+	err := child.Get(ctx, nil)
+	switch {
+	case temporal.IsWorkflowExecutionAlreadyStartedError(err):
+		// The ID of the ingest is taken by a run of the same content, e.g. by another command
+		logger.Info("Skipping content ingested by another run", "file", file.FilePath, "workflowID", workflowID)
+		result.Outcome = FileDuplicate
+	case err != nil:
+		logger.Error("Failed to ingest file", "file", file.FilePath, "error", err)
+		result.Outcome, result.Error = FileFailed, err.Error()
+	default:
+		result.Outcome = FileIngested
+	}
+	return result
+}
+
+// writeBatchReport consolidates and writes the report of a batch, also when the batch was canceled
+func writeBatchReport(ctx workflow.Context, summary *BatchSummary) {
+	summary.FinishedAt = workflow.Now(ctx)
+	reportCtx, _ := workflow.NewDisconnectedContext(ctx)
+	var written BatchSummary
+	if err := workflow.ExecuteActivity(reportCtx, "WriteBatchReportActivity", *summary).Get(reportCtx, &written); err != nil {
+		workflow.GetLogger(ctx).Error("Failed to write batch report", "error", err)
+		return
+	}
+	*summary = written
+}
+
+// WriteBatchReportActivity sums the run reports of the files ingested by a batch, including the files that failed
+// part way, writes the consolidated report to the report directory and returns it. Files whose run report
+// cannot be read are listed as missing.
+func (a *Activities) WriteBatchReportActivity(ctx context.Context, summary BatchSummary) (BatchSummary, error) {
+	if err := os.MkdirAll(a.Config.Reports.Dir, 0755); err != nil {
+		return summary, fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	summary.Totals, summary.Zones, summary.Missing = BatchTotals{}, nil, nil
+	for _, file := range summary.Files {
+		if file.RunID == "" || file.Outcome == FileDuplicate {
+			continue
+		}
+		var report RunReport
+		data, err := os.ReadFile(RunReportPath(a.Config.Reports.Dir, file.WorkflowID, file.RunID))
+		if err == nil {
+			err = json.Unmarshal(data, &report)
+		}
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				fmt.Printf("Warning: run report of %s unreadable: %v\n", file.FilePath, err)
+			}
+			summary.Missing = append(summary.Missing, file.FilePath)
+			continue
+		}
+		summary.Totals.Events += report.TotalEvents
+		for _, zone := range report.Zones {
+			if summary.Zones == nil {
+				summary.Zones = make(map[string]BatchTotals)
+			}
+			totals := summary.Zones[zone.Zone]
+			totals.add(zone)
+			summary.Zones[zone.Zone] = totals
+			summary.Totals.add(zone)
+		}
+	}
+
+	// The workflow ID embeds the directory, which contains slashes
+	reportPath := filepath.Join(a.Config.Reports.Dir, fmt.Sprintf("batch_%s.json", summary.StartedAt.UTC().Format("20060102T150405Z")))
+	summary.Report = reportPath
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return summary, fmt.Errorf("failed to marshal batch report: %w", err)
+	}
+	if err := os.WriteFile(reportPath, data, 0644); err != nil {
+		return summary, fmt.Errorf("failed to write batch report: %w", err)
+	}
+	fmt.Printf("Wrote batch report to %s\n", reportPath)
+	return summary, nil
+}
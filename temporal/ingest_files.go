@@ -0,0 +1,189 @@
+package temporal
+
+import (
+	"time"
+
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+)
+
+// DefaultIngestParallelism is the number of files ingested at the same time when the request does not say
+const DefaultIngestParallelism = 4
+
+// IngestFile is a file of an IngestFilesRequest
+type IngestFile struct {
+	FilePath    string
+	ContentHash string // SHA-256 of the file content, also the basis of the ingest workflow ID
+}
+
+// IngestFilesRequest is the input of IngestFilesWorkflow
+type IngestFilesRequest struct {
+	Files          []IngestFile
+	Parallelism    int                // Files ingested at the same time, DefaultIngestParallelism if zero
+	Force          bool               // Ingest content again even if the ingest ledger records it as fully ingested
+	ZoneTaskQueues map[string]string  // zone -> task queue for sharded zones, other zones use the parent's queue
+	HCS            config.HCSConfig   // HCS topics the mints are published to
+	Zones          config.ZonesConfig // Zones ingested, the domains of other zones are refused before their collection is created
+	MaxMints       int                // Domains each file may mint at most, unlimited if zero (MAX_MINTS_PER_RUN)
+	BudgetTinybar  int64              // Fees each file may spend before its run pauses, unlimited if zero (RUN_BUDGET_HBAR)
+	BudgetUSD      float64            // Budget in US dollars, converted at the exchange rate of the network when BudgetTinybar is zero (RUN_BUDGET_USD)
+	Notify         bool               // Announce the runs of the files on the webhooks of NOTIFY_WEBHOOKS
+	FailureAlert   int                // Failed domains of a file that raise an alert on the webhooks, none if zero (NOTIFY_FAILURE_THRESHOLD)
+	EmailReport    bool               // Email the report of every file to REPORT_EMAIL_TO
+	ArchiveReport  bool               // Upload the report of every file to REPORT_BUCKET
+	EscalateAfter  int                // Consecutive failures of a zone that page the operators and pause it, never if zero (ESCALATION_THRESHOLD)
+
+	// Scope of the workflow IDs of the ingests, as of this workflow (TemporalConfig.WorkflowIDScope)
+	WorkflowIDScope string
+
+	// How long minted NFTs may take to show on the mirror node, not checked if zero (MIRROR_VISIBILITY_TIMEOUT)
+	VisibilityTimeout time.Duration
+}
+
+// IngestFileResult is the outcome of one file of an IngestFilesWorkflow
+type IngestFileResult struct {
+	FilePath    string `json:"file_path"`
+	ContentHash string `json:"content_hash"`
+	Outcome     string `json:"outcome,omitempty"`     // Empty for files that were not started before a cancellation
+	WorkflowID  string `json:"workflow_id,omitempty"` // Ingest run of the content, by this workflow or an earlier one
+	Error       string `json:"error,omitempty"`
+}
+
+// IngestFilesResult is the result of IngestFilesWorkflow, and its progress
+type IngestFilesResult struct {
+	Files      []IngestFileResult `json:"files"`
+	Running    int                `json:"running"`
+	Ingested   int                `json:"ingested"`
+	Duplicates int                `json:"duplicates"`
+	Failed     int                `json:"failed"`
+}
+
+// IngestFilesWorkflow ingests several files with one command, e.g. the files matching a glob. Each file is
+// ingested by an IngestFileWorkflow child with the ID of its content, at most Parallelism at a time. Files
+// whose content is fully ingested, or repeats an earlier file of the request, are skipped unless forced.
+// A failed file does not stop the others; the outcome of every file is returned.
+func IngestFilesWorkflow(ctx workflow.Context, req IngestFilesRequest) (IngestFilesResult, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting multi-file ingestion workflow", "files", len(req.Files), "parallelism", req.Parallelism)
+
+	ctx = workflow.WithActivityOptions(ctx, defaultActivityOptions())
+	if req.Parallelism <= 0 {
+		req.Parallelism = DefaultIngestParallelism
+	}
+
+	result := IngestFilesResult{Files: make([]IngestFileResult, len(req.Files))}
+	for i, file := range req.Files {
+		result.Files[i] = IngestFileResult{FilePath: file.FilePath, ContentHash: file.ContentHash}
+	}
+	if err := workflow.SetQueryHandler(ctx, ProgressQuery, func() (IngestFilesResult, error) {
+		return result, nil
+	}); err != nil {
+		return result, err
+	}
+
+	finish := func(i int, outcome, workflowID string, err error) {
+		result.Files[i].Outcome, result.Files[i].WorkflowID = outcome, workflowID
+		switch outcome {
+		case FileIngested:
+			result.Ingested++
+		case FileDuplicate:
+			result.Duplicates++
+		case FileFailed:
+			result.Failed++
+			result.Files[i].Error = err.Error()
+		}
+	}
+
+	selector := workflow.NewSelector(ctx)
+	seen := make(map[string]string)
+	for i, file := range req.Files {
+		if earlier, ok := seen[file.ContentHash]; ok {
+			logger.Info("Skipping duplicate content", "file", file.FilePath, "sameAs", earlier)
+			finish(i, FileDuplicate, "", nil)
+			continue
+		}
+		seen[file.ContentHash] = file.FilePath
+
+		childOptions := workflow.ChildWorkflowOptions{
+			WorkflowID:            ScopedWorkflowID(req.WorkflowIDScope, IngestWorkflowID(file.ContentHash)),
+			WorkflowIDReusePolicy: enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE_FAILED_ONLY,
+			// On cancellation, wait for the ingest to checkpoint and report its run
+			WaitForCancellation: true,
+		}
+		if req.Force {
+			childOptions.WorkflowID = ScopedWorkflowID(req.WorkflowIDScope, ForcedIngestWorkflowID(file.ContentHash, workflow.Now(ctx)))
+			childOptions.WorkflowIDReusePolicy = enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE
+		} else {
+			var previous IngestedFileInfo
+			if err := workflow.ExecuteActivity(ctx, "LookupIngestedFileActivity", file.ContentHash).Get(ctx, &previous); err != nil {
+				logger.Error("Failed to check ingest ledger", "file", file.FilePath, "error", err)
+				finish(i, FileFailed, "", err)
+				continue
+			}
+			if previous.Outcome == IngestOutcomeCompleted {
+				logger.Info("Skipping already ingested content", "file", file.FilePath, "workflowID", previous.WorkflowID)
+				finish(i, FileDuplicate, previous.WorkflowID, nil)
+				continue
+			}
+		}
+
+		// Wait for a slot
+		for result.Running >= req.Parallelism {
+			selector.Select(ctx)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		logger.Info("Ingesting file", "file", file.FilePath, "workflowID", childOptions.WorkflowID)
+		childCtx := workflow.WithChildOptions(ctx, childOptions)
+		child := workflow.ExecuteChildWorkflow(childCtx, IngestFileWorkflow, IngestRequest{
+			FilePath:       file.FilePath,
+			ContentHash:    file.ContentHash,
+			ZoneTaskQueues: req.ZoneTaskQueues,
+			HCS:            req.HCS,
+			Zones:          req.Zones,
+			MaxMints:       req.MaxMints,
+			BudgetTinybar:  req.BudgetTinybar,
+			BudgetUSD:      req.BudgetUSD,
+			Notify:         req.Notify,
+			FailureAlert:   req.FailureAlert,
+			EmailReport:    req.EmailReport,
+			ArchiveReport:  req.ArchiveReport,
+			EscalateAfter:  req.EscalateAfter,
+
+			VisibilityTimeout: req.VisibilityTimeout,
+		})
+		result.Running++
+		workflowID := childOptions.WorkflowID
+		selector.AddFuture(child, func(f workflow.Future) {
+			result.Running--
+			err := f.Get(ctx, nil)
+			switch {
+			case temporal.IsWorkflowExecutionAlreadyStartedError(err):
+				// The ID of the ingest is taken by a run of the same content, e.g. by another command
+				logger.Info("Skipping content ingested by another run", "file", req.Files[i].FilePath, "workflowID", workflowID)
+				finish(i, FileDuplicate, workflowID, nil)
+			case err != nil:
+				logger.Error("Failed to ingest file", "file", req.Files[i].FilePath, "error", err)
+				finish(i, FileFailed, workflowID, err)
+			default:
+				finish(i, FileIngested, workflowID, nil)
+			}
+		})
+	}
+	for result.Running > 0 {
+		selector.Select(ctx)
+	}
+
+	if ctx.Err() != nil {
+		logger.Info("Multi-file ingestion workflow canceled")
+		return result, temporal.NewCanceledError()
+	}
+	logger.Info("Completed multi-file ingestion workflow", "ingested", result.Ingested,
+		"duplicates", result.Duplicates, "failed", result.Failed)
+	return result, nil
+}
@@ -0,0 +1,158 @@
+package temporal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+)
+
+// ErrTypeNoIngestRun is the type of the error returned when the ingest ledger records no run of a workflow
+const ErrTypeNoIngestRun = "NoIngestRun"
+
+// RecordIngestActivity records the state of an ingest run in the ingest ledger.
+// The file size is filled in from disk when the run starts.
+func (a *Activities) RecordIngestActivity(ctx context.Context, record IngestedFileInfo) error {
+	unlock, err := a.lockIngestLedger()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	ledger, err := a.loadIngestLedger()
+	if err != nil {
+		return fmt.Errorf("failed to load ingest ledger: %w", err)
+	}
+
+	if existing, ok := ledger.Files[record.ContentHash]; ok {
+		if existing.RunID == record.RunID {
+			// Keep what was recorded when the run started
+			record.Size = existing.Size
+			record.StartedAt = existing.StartedAt
+		}
+		// A resumed run continues from the cursor of the run it resumes
+		if record.Cursor == nil {
+			record.Cursor = existing.Cursor
+		}
+	}
+	if record.Size == 0 {
+		if stat, err := os.Stat(record.FilePath); err == nil {
+			record.Size = stat.Size()
+		}
+	}
+	if record.StartedAt.IsZero() {
+		record.StartedAt = time.Now()
+	}
+	if record.Outcome != IngestOutcomeRunning {
+		record.FinishedAt = time.Now()
+	}
+
+	ledger.Files[record.ContentHash] = record
+	fmt.Printf("Recorded ingest of %s (%s) as %s\n", record.FilePath, record.ContentHash, record.Outcome)
+	return a.saveIngestLedger(ledger)
+}
+
+// SaveIngestCursorActivity advances the resume cursor of a zone in an ingest run to the given line
+func (a *Activities) SaveIngestCursorActivity(ctx context.Context, contentHash, zone string, line int) error {
+	unlock, err := a.lockIngestLedger()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	ledger, err := a.loadIngestLedger()
+	if err != nil {
+		return fmt.Errorf("failed to load ingest ledger: %w", err)
+	}
+	record, ok := ledger.Files[contentHash]
+	if !ok {
+		return fmt.Errorf("no ingest run recorded for content %s", contentHash)
+	}
+	if record.Cursor == nil {
+		record.Cursor = make(map[string]int)
+	}
+	if line <= record.Cursor[zone] {
+		return nil // Never move the cursor backwards
+	}
+	record.Cursor[zone] = line
+	ledger.Files[contentHash] = record
+	return a.saveIngestLedger(ledger)
+}
+
+// FindIngestRunActivity returns the ingest run recorded for the given workflow ID
+func (a *Activities) FindIngestRunActivity(ctx context.Context, workflowID string) (IngestedFileInfo, error) {
+	ledger, err := a.loadIngestLedger()
+	if err != nil {
+		return IngestedFileInfo{}, fmt.Errorf("failed to load ingest ledger: %w", err)
+	}
+	for _, record := range ledger.Files {
+		if record.WorkflowID == workflowID {
+			return record, nil
+		}
+	}
+	return IngestedFileInfo{}, temporal.NewNonRetryableApplicationError(
+		fmt.Sprintf("no ingest run recorded for workflow %s", workflowID), ErrTypeNoIngestRun, nil)
+}
+
+// LookupIngestedFileActivity returns the latest ingest run recorded for the given content hash.
+// An empty Outcome means the content has never been ingested.
+func (a *Activities) LookupIngestedFileActivity(ctx context.Context, contentHash string) (IngestedFileInfo, error) {
+	ledger, err := a.loadIngestLedger()
+	if err != nil {
+		return IngestedFileInfo{}, fmt.Errorf("failed to load ingest ledger: %w", err)
+	}
+	return ledger.Files[contentHash], nil
+}
+
+// ListIngestRunsActivity returns the ingest runs recorded in the ledger, most recently started first
+func (a *Activities) ListIngestRunsActivity(ctx context.Context) ([]IngestedFileInfo, error) {
+	ledger, err := a.loadIngestLedger()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ingest ledger: %w", err)
+	}
+	runs := make([]IngestedFileInfo, 0, len(ledger.Files))
+	for _, record := range ledger.Files {
+		runs = append(runs, record)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].StartedAt.After(runs[j].StartedAt) })
+	return runs, nil
+}
+
+// loadIngestLedger loads the ingest ledger from a JSON file
+func (a *Activities) loadIngestLedger() (*IngestLedger, error) {
+	data, err := a.readRegistryFile(a.Config.Registry.IngestLedgerFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &IngestLedger{
+				Files:       make(map[string]IngestedFileInfo),
+				LastUpdated: time.Now(),
+			}, nil
+		}
+		return nil, err
+	}
+
+	var ledger IngestLedger
+	err = json.Unmarshal(data, &ledger)
+	if err != nil {
+		return nil, err
+	}
+	if ledger.Files == nil {
+		ledger.Files = make(map[string]IngestedFileInfo)
+	}
+
+	return &ledger, nil
+}
+
+// saveIngestLedger saves the ingest ledger to a JSON file
+func (a *Activities) saveIngestLedger(ledger *IngestLedger) error {
+	ledger.LastUpdated = time.Now()
+	data, err := json.MarshalIndent(ledger, "", "  ")
+	if err != nil {
+		return err
+	}
+	return a.writeRegistryFile(a.Config.Registry.IngestLedgerFile, data)
+}
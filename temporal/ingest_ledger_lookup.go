@@ -0,0 +1,51 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+)
+
+// IngestLedgerQuery selects the ingest run looked up by LookupIngestRunWorkflow: the run of WorkflowID when set,
+// otherwise the latest run of ContentHash
+type IngestLedgerQuery struct {
+	ContentHash string `json:"content_hash,omitempty"`
+	WorkflowID  string `json:"workflow_id,omitempty"`
+}
+
+// LookupIngestRunWorkflow looks up an ingest run in the ingest ledger. The ledger is a file of the workers, so
+// clients look runs up through a worker rather than reading a ledger of their own host, which may be empty.
+func LookupIngestRunWorkflow(ctx workflow.Context, query IngestLedgerQuery) (IngestedFileInfo, error) {
+	ctx = workflow.WithActivityOptions(ctx, defaultActivityOptions())
+	var run IngestedFileInfo
+	if query.WorkflowID != "" {
+		err := workflow.ExecuteActivity(ctx, "FindIngestRunActivity", query.WorkflowID).Get(ctx, &run)
+		return run, err
+	}
+	err := workflow.ExecuteActivity(ctx, "LookupIngestedFileActivity", query.ContentHash).Get(ctx, &run)
+	return run, err
+}
+
+// LookupIngestRun runs a LookupIngestRunWorkflow and returns the ingest run it found. An empty Outcome means the
+// content has never been ingested.
+func LookupIngestRun(ctx context.Context, c client.Client, t config.TemporalConfig, query IngestLedgerQuery) (IngestedFileInfo, error) {
+	we, err := c.ExecuteWorkflow(ctx, IngestLedgerLookupWorkflowOptions(t, query), LookupIngestRunWorkflow, query)
+	if err != nil {
+		return IngestedFileInfo{}, err
+	}
+	var run IngestedFileInfo
+	if err := we.Get(ctx, &run); err != nil {
+		// Report what the activity said, not the workflow and activity errors wrapping it
+		var appErr *temporal.ApplicationError
+		if errors.As(err, &appErr) {
+			return IngestedFileInfo{}, errors.New(appErr.Message())
+		}
+		return IngestedFileInfo{}, err
+	}
+	return run, nil
+}
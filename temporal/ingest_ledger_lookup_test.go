@@ -0,0 +1,60 @@
+package temporal
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+)
+
+func TestLookupIngestRunWorkflow(t *testing.T) {
+	a := &Activities{Config: &config.Config{Registry: config.RegistryConfig{
+		IngestLedgerFile: filepath.Join(t.TempDir(), "ingested_files.json"),
+	}}}
+	require.NoError(t, a.RecordIngestActivity(context.Background(), IngestedFileInfo{
+		ContentHash: "c0ffee",
+		FilePath:    "events.log",
+		WorkflowID:  "domain-ingest-workflow_c0ffee",
+		RunID:       "run",
+		Outcome:     IngestOutcomeCompleted,
+	}))
+
+	lookup := func(query IngestLedgerQuery) (IngestedFileInfo, error) {
+		var suite testsuite.WorkflowTestSuite
+		env := suite.NewTestWorkflowEnvironment()
+		env.RegisterActivity(a)
+		env.ExecuteWorkflow(LookupIngestRunWorkflow, query)
+		require.True(t, env.IsWorkflowCompleted())
+		var run IngestedFileInfo
+		if err := env.GetWorkflowError(); err != nil {
+			return run, err
+		}
+		require.NoError(t, env.GetWorkflowResult(&run))
+		return run, nil
+	}
+
+	run, err := lookup(IngestLedgerQuery{ContentHash: "c0ffee"})
+	require.NoError(t, err)
+	assert.Equal(t, "domain-ingest-workflow_c0ffee", run.WorkflowID)
+	assert.Equal(t, IngestOutcomeCompleted, run.Outcome)
+
+	run, err = lookup(IngestLedgerQuery{ContentHash: "beef"})
+	require.NoError(t, err)
+	assert.Empty(t, run.Outcome, "content never ingested")
+
+	run, err = lookup(IngestLedgerQuery{WorkflowID: "domain-ingest-workflow_c0ffee"})
+	require.NoError(t, err)
+	assert.Equal(t, "c0ffee", run.ContentHash)
+
+	_, err = lookup(IngestLedgerQuery{WorkflowID: "unknown"})
+	var appErr *temporal.ApplicationError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, ErrTypeNoIngestRun, appErr.Type())
+	assert.True(t, appErr.NonRetryable())
+}
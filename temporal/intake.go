@@ -0,0 +1,149 @@
+package temporal
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/eventschema"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/eventsig"
+)
+
+// IntakeRejection is an event pushed to the intake that was refused
+type IntakeRejection struct {
+	Index int    `json:"index"` // Position of the event in the request
+	Error string `json:"error"`
+}
+
+// IntakeValidation is the result of ValidateIntakeEventsActivity
+type IntakeValidation struct {
+	Lines    []string          // Event log lines of the accepted events, in request order
+	Rejected []IntakeRejection // Events that cannot be ingested
+}
+
+// ValidateIntakeEventsActivity checks events pushed to the intake ({"registry-event":{...},"sig":"..."}) and turns
+// them into event log lines, as read from files. Events must match the schema version they declare, name a valid
+// domain of their zone and pass signature verification when it is enabled. The bytes of signed events are kept
+// as they are, so their signatures still verify when the lines are ingested.
+func (a *Activities) ValidateIntakeEventsActivity(ctx context.Context, events []json.RawMessage) (IntakeValidation, error) {
+	keys, err := a.eventKeys()
+	if err != nil {
+		return IntakeValidation{}, err
+	}
+	var result IntakeValidation
+	for i, raw := range events {
+		line, err := a.intakeLine(keys, raw)
+		if err != nil {
+			result.Rejected = append(result.Rejected, IntakeRejection{Index: i, Error: err.Error()})
+			continue
+		}
+		result.Lines = append(result.Lines, line)
+	}
+	return result, nil
+}
+
+// intakeLine validates one pushed event and returns its event log line
+func (a *Activities) intakeLine(keys *eventsig.KeySet, raw json.RawMessage) (string, error) {
+	var pushed struct {
+		Event     json.RawMessage `json:"registry-event"`
+		Signature string          `json:"sig"`
+	}
+	if err := json.Unmarshal(raw, &pushed); err != nil {
+		return "", fmt.Errorf("not an event object: %w", err)
+	}
+	if len(pushed.Event) == 0 || bytes.Equal(pushed.Event, []byte("null")) {
+		return "", errors.New("no registry-event")
+	}
+
+	payload := pushed.Event
+	if bytes.ContainsAny(payload, "\r\n") {
+		if pushed.Signature != "" {
+			return "", errors.New("signed events must not contain line breaks, their bytes cannot be changed")
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, payload); err != nil {
+			return "", fmt.Errorf("invalid registry-event: %w", err)
+		}
+		payload = compact.Bytes()
+	}
+
+	// Events of unknown schema versions are refused, the registry can push them again once they are known
+	event, err := eventSchemas.Decode(payload)
+	if err != nil {
+		return "", err
+	}
+	switch event.ObjectType() {
+	case eventschema.TypeDomain:
+		name, err := domain.NewDomainName(event.DomainName)
+		if err != nil {
+			return "", fmt.Errorf("invalid domain %q: %w", event.DomainName, err)
+		}
+		if err := name.CheckZone(event.Zone); err != nil {
+			return "", err
+		}
+	case eventschema.TypeHost, eventschema.TypeContact:
+		// Nameservers can be out of the zone and contacts are not in the DNS, only the zone is checked
+		if _, err := domain.NewZone(event.Zone); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unknown object type %q", event.Type)
+	}
+
+	line := `"registry-event":` + string(payload)
+	if pushed.Signature != "" {
+		sig, _ := json.Marshal(pushed.Signature)
+		line += `,"sig":` + string(sig)
+	}
+	if _, err := a.verifyEventSignature(keys, "{"+line+"}", pushed.Signature); err != nil {
+		return "", fmt.Errorf("signature refused: %w", err)
+	}
+	return line, nil
+}
+
+// SpoolIntakeActivity writes the lines of a pushed batch to INTAKE_SPOOL_DIR for the workers to ingest. The file
+// is named after its content hash, so a batch that is pushed again maps to the same file and ingest workflow.
+func (a *Activities) SpoolIntakeActivity(ctx context.Context, source string, lines []string) (IngestFile, error) {
+	content := []byte(strings.Join(lines, "\n") + "\n")
+	sum := sha256.Sum256(content)
+	contentHash := hex.EncodeToString(sum[:])
+	// The path is absolute, as the workers ingesting the file may run in another directory
+	spoolDir, err := filepath.Abs(a.Config.Intake.SpoolDir)
+	if err != nil {
+		return IngestFile{}, fmt.Errorf("invalid spool directory: %w", err)
+	}
+	filePath := filepath.Join(spoolDir, contentHash+".log")
+	if _, err := os.Stat(filePath); err == nil {
+		return IngestFile{FilePath: filePath, ContentHash: contentHash}, nil
+	}
+
+	if err := os.MkdirAll(spoolDir, 0755); err != nil {
+		return IngestFile{}, fmt.Errorf("failed to create spool directory: %w", err)
+	}
+	// Write to a temporary file first, so an ingest never reads a partial batch
+	tmp, err := os.CreateTemp(spoolDir, ".batch-*")
+	if err != nil {
+		return IngestFile{}, fmt.Errorf("failed to create spool file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return IngestFile{}, fmt.Errorf("failed to write spool file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return IngestFile{}, fmt.Errorf("failed to write spool file: %w", err)
+	}
+	fmt.Printf("Spooled %d events pushed by %s to %s\n", len(lines), source, filePath)
+	return IngestFile{FilePath: filePath, ContentHash: contentHash}, nil
+}
@@ -0,0 +1,78 @@
+package temporal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+)
+
+// Types of ledger events
+const (
+	LedgerEventMint     = "mint"
+	LedgerEventBurn     = "burn"
+	LedgerEventTransfer = "transfer"
+)
+
+// ledgerEventTimeout bounds the push of an event, a slow API server must not hold up mints
+const ledgerEventTimeout = 5 * time.Second
+
+// LedgerEvent is a mint, burn or transfer pushed to the activity stream of the API server as it happens
+type LedgerEvent struct {
+	Type          string    `json:"type"`
+	Domain        string    `json:"domain"`
+	Zone          string    `json:"zone"`
+	TokenID       string    `json:"token_id"`
+	SerialNumber  int64     `json:"serial_number"`
+	TransactionID string    `json:"transaction_id"`        // In the notation of the mirror node
+	At            time.Time `json:"at"`                    // Consensus time, or when the worker saw a transfer succeed
+	Account       string    `json:"account,omitempty"`     // Recipient of a transfer
+	WorkflowID    string    `json:"workflow_id,omitempty"` // The run that submitted the transaction
+}
+
+// publishLedgerEvent pushes an event to the activity stream of ACTIVITY_STREAM_URL, if set. Like the records of
+// the transaction, an event that cannot be pushed is reported without failing the activity that submitted it.
+func (a *Activities) publishLedgerEvent(ctx context.Context, event LedgerEvent) {
+	if a.Config.API.StreamURL == "" {
+		return
+	}
+	event.TransactionID = MirrorTransactionID(event.TransactionID)
+	event.At = event.At.UTC()
+	if activity.IsActivity(ctx) {
+		event.WorkflowID = activity.GetInfo(ctx).WorkflowExecution.ID
+	}
+	if err := a.pushLedgerEvent(ctx, event); err != nil {
+		fmt.Printf("Warning: failed to push the %s of %s to the activity stream: %v\n", event.Type, event.Domain, err)
+	}
+}
+
+// pushLedgerEvent posts an event to the API server with the admin key of ACTIVITY_STREAM_KEY
+func (a *Activities) pushLedgerEvent(ctx context.Context, event LedgerEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, ledgerEventTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.Config.API.StreamURL+"/v1/activity", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.Config.API.StreamKey != "" {
+		req.Header.Set("Authorization", "Bearer "+a.Config.API.StreamKey)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("API server answered %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/metadata"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/zonepolicy"
+)
+
+// metadataStore returns the store of METADATA_STORE metadata documents are uploaded to, or nil when uploads
+// are disabled
+func (a *Activities) metadataStore(ctx context.Context) (metadata.Store, error) {
+	return a.openMetadataStore(ctx, a.Config.Metadata.Store)
+}
+
+// openMetadataStore returns the named metadata store, or nil for none.
+// IPFS stores heartbeat while waiting for pins to complete, HCS stores after every chunk sent.
+func (a *Activities) openMetadataStore(ctx context.Context, name string) (metadata.Store, error) {
+	switch name {
+	case "", zonepolicy.StoreNone:
+		return nil, nil
+	case config.MetadataStoreArweave:
+		wallet, err := metadata.LoadArweaveWallet(a.Config.Metadata.ArweaveWalletFile)
+		if err != nil {
+			return nil, err
+		}
+		return metadata.NewArweaveStore(a.Config.Metadata.ArweaveGateway, wallet), nil
+	case config.MetadataStoreIPFS:
+		pinners, err := a.metadataPinners()
+		if err != nil {
+			return nil, err
+		}
+		store := metadata.NewIPFSStore(pinners, a.Config.Metadata.IPFSPinTimeout)
+		store.OnPoll = func(pinner string, status metadata.PinStatus) {
+			heartbeat(ctx, pinner, string(status))
+		}
+		return store, nil
+	case config.MetadataStoreHCS:
+		topic, err := a.LookupOrCreateTopicActivity(ctx, a.Config.Metadata.Topic, "Metadata documents of the shadow domain ledger", true, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up metadata topic: %w", err)
+		}
+		return &metadata.HCSStore{
+			TopicID: topic.TopicID,
+			Submit: func(ctx context.Context, message []byte) (uint64, error) {
+				sent, err := a.SendMessageToTopicActivity(ctx, topic.TopicID, string(message))
+				if err != nil {
+					return 0, err
+				}
+				heartbeat(ctx, topic.TopicID, sent.SequenceNumber)
+				return sent.SequenceNumber, nil
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown metadata store %q", name)
+	}
+}
+
+// ResolveMetadataDocumentActivity returns the metadata document stored on HCS under an hcs:// URI,
+// reassembled from its chunks on the mirror node and checked against its hash
+func (a *Activities) ResolveMetadataDocumentActivity(ctx context.Context, uri string) (string, error) {
+	resolver := metadata.NewHCSResolver(a.Config.Mirror.BaseURL, a.Config.Mirror.Header())
+	if err := a.mirrorLimiter.Wait(ctx); err != nil {
+		return "", err
+	}
+	data, err := resolver.Resolve(ctx, uri)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// metadataPinners returns the configured IPFS pinners, in order
+func (a *Activities) metadataPinners() ([]metadata.MetadataPinner, error) {
+	m := a.Config.Metadata
+	var pinners []metadata.MetadataPinner
+	for _, name := range m.IPFSPinners {
+		switch name {
+		case config.PinnerPinata:
+			pinners = append(pinners, metadata.NewPinataPinner(m.PinataURL, m.PinataJWT))
+		case config.PinnerWeb3Storage:
+			pinners = append(pinners, metadata.NewWeb3StoragePinner(m.Web3StorageURL, m.Web3StorageToken))
+		case config.PinnerKubo:
+			pinners = append(pinners, metadata.NewKuboPinner(m.KuboURL))
+		default:
+			return nil, fmt.Errorf("unknown IPFS pinner %q", name)
+		}
+	}
+	return pinners, nil
+}
+
+// NewMetadataDocument returns the metadata document of the NFT of a domain
+func NewMetadataDocument(info MintingInfo) metadata.Document {
+	doc := metadata.Document{
+		Name:        info.DomainName,
+		Description: fmt.Sprintf("Registration of %s in the .%s zone of the shadow domain ledger", info.DomainName, info.Zone),
+		Properties: metadata.Properties{
+			Domain:      info.DomainName,
+			Zone:        info.Zone.String(),
+			Registrar:   info.RegistrarID,
+			EventHash:   info.EventHash,
+			Nameservers: info.Nameservers,
+			Registrant:  info.RegistrantFingerprint,
+			Previous:    info.Previous,
+		},
+	}
+	for _, r := range info.Redacted {
+		doc.Properties.Redacted = append(doc.Properties.Redacted, metadata.Redacted{Field: r.Field, Action: r.Action})
+	}
+	for _, ns := range info.Nameservers {
+		doc.Attributes = append(doc.Attributes, metadata.Attribute{TraitType: metadata.TraitNameserver, Value: ns})
+	}
+	if info.Generated != nil {
+		doc.Properties.DGAScore = info.Generated.Score
+		doc.Attributes = append(doc.Attributes, metadata.Attribute{TraitType: metadata.TraitGeneratedLabel, Value: "true"})
+	}
+	if !info.RegistrationTime.IsZero() {
+		registeredAt := info.RegistrationTime.UTC()
+		doc.Properties.RegisteredAt = &registeredAt
+	}
+	return doc
+}
+
+// uploadMetadataDocument uploads the metadata document of a domain and returns its URI
+func (a *Activities) uploadMetadataDocument(ctx context.Context, store metadata.Store, info MintingInfo) (string, error) {
+	doc := NewMetadataDocument(info)
+	data, err := doc.Marshal()
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal metadata document: %w", err)
+	}
+	uri, err := store.Put(ctx, data, doc.Tags())
+	if err != nil {
+		return "", fmt.Errorf("failed to upload metadata document: %w", err)
+	}
+	return uri, nil
+}
@@ -0,0 +1,487 @@
+package temporal
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+)
+
+// ErrTypeInvalidMigration is the application error type of collection migrations that cannot be carried out
+const ErrTypeInvalidMigration = "InvalidMigration"
+
+// Ways of retiring the old collection of a migrated zone
+const (
+	RetireBurn   = "burn"   // Burn the NFTs of the old collection still in the treasury, the default
+	RetireDelete = "delete" // Delete the old token with the admin signer, its NFTs can no longer be transferred
+	RetireKeep   = "keep"   // Leave the old collection as it is
+)
+
+// maxNFTsPerTransaction is the most NFTs Hedera mints or burns in one transaction
+const maxNFTsPerTransaction = 10
+
+// migrationPageSize is the number of NFTs of the old collection listed at a time
+const migrationPageSize = 100
+
+// migrationPagesPerRun bounds the pages a MigrateCollectionWorkflow run goes through before it continues as new
+const migrationPagesPerRun = 50
+
+// migrationSettleDelay is how long a migration waits after switching the registry before it looks for NFTs
+// minted into the old collection meanwhile, so the mirror node has imported the last of them
+const migrationSettleDelay = time.Minute
+
+// CollectionMigrationRequest asks to migrate the collection of a zone to a new token
+type CollectionMigrationRequest struct {
+	Zone   string `json:"zone"`
+	Retire string `json:"retire,omitempty"` // RetireBurn, RetireDelete or RetireKeep, RetireBurn if empty
+
+	// Progress carried over when the workflow continues as new
+	From        ZoneCollectionInfo        `json:"from"`
+	To          ZoneCollectionInfo        `json:"to"`
+	AfterSerial int64                     `json:"after_serial,omitempty"` // Last serial of the old collection gone through
+	Switched    bool                      `json:"switched,omitempty"`     // The registry has the new collection
+	Retiring    bool                      `json:"retiring,omitempty"`     // Every NFT is re-minted, the old collection is being retired
+	Result      CollectionMigrationResult `json:"result"`
+}
+
+// CollectionMigrationResult is the outcome of migrating the collection of a zone
+type CollectionMigrationResult struct {
+	Zone        string        `json:"zone"`
+	FromTokenID string        `json:"from_token_id"`
+	ToTokenID   string        `json:"to_token_id"`
+	Reminted    int           `json:"reminted"`               // NFTs minted into the new collection
+	HeldOutside []MigratedNFT `json:"held_outside,omitempty"` // Re-minted into the treasury, to be transferred to their holders
+	Retire      string        `json:"retire"`
+	Burned      int           `json:"burned,omitempty"` // NFTs of the old collection burned
+	Deleted     bool          `json:"deleted,omitempty"`
+}
+
+// MigratedNFT is an NFT of the old collection minted again into the new one
+type MigratedNFT struct {
+	Domain     string `json:"domain"`
+	FromSerial int64  `json:"from_serial"`
+	ToSerial   int64  `json:"to_serial"`
+	Holder     string `json:"holder,omitempty"` // The holder of the old NFT, when outside the treasury
+}
+
+// MigrationPage is a page of the NFTs of a collection that are not burned, in serial order
+type MigrationPage struct {
+	NFTs       []MirrorNodeNFT `json:"nfts"`
+	LastSerial int64           `json:"last_serial"` // Last serial of the page, burned ones included
+	Done       bool            `json:"done"`        // No NFTs after the page
+}
+
+// migrationMemo returns the memo of the mints re-minting a range of serials of an old collection
+func migrationMemo(fromTokenID string, first, last int64) string {
+	return fmt.Sprintf("sdl migrate %s serials %d-%d", fromTokenID, first, last)
+}
+
+// PrepareCollectionMigrationActivity returns the registered collection of a zone to migrate. Migrations submit
+// their transactions online, they are refused when signing offline.
+func (a *Activities) PrepareCollectionMigrationActivity(ctx context.Context, zone string) (ZoneCollectionInfo, error) {
+	z, err := domain.NewZone(zone)
+	if err != nil {
+		return ZoneCollectionInfo{}, temporal.NewNonRetryableApplicationError(err.Error(), ErrTypeInvalidZone, err)
+	}
+	if a.Config.Hedera.Signing == config.SigningOffline {
+		return ZoneCollectionInfo{}, temporal.NewNonRetryableApplicationError(
+			"collection migrations cannot be signed offline", ErrTypeInvalidMigration, nil)
+	}
+	registry, err := a.loadZoneRegistry()
+	if err != nil {
+		return ZoneCollectionInfo{}, fmt.Errorf("failed to load zone registry: %w", err)
+	}
+	collection, ok := registry.Collections[z]
+	if !ok || collection.TokenID == "" {
+		return ZoneCollectionInfo{}, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf(".%s has no collection in the zone registry", z), ErrTypeInvalidMigration, nil)
+	}
+	if len(collection.Shards) > 0 {
+		return ZoneCollectionInfo{}, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf(".%s is sharded across %d collections, sharded zones cannot be migrated", z, len(collection.Shards)+1), ErrTypeInvalidMigration, nil)
+	}
+	return collection, nil
+}
+
+// ListMigrationNFTsActivity returns the NFTs of a collection after the given serial that are not burned
+func (a *Activities) ListMigrationNFTsActivity(ctx context.Context, tokenID string, afterSerial int64) (MigrationPage, error) {
+	page := MigrationPage{LastSerial: afterSerial}
+	var response MirrorNodeNFTsResponse
+	path := fmt.Sprintf("/tokens/%s/nfts?order=asc&limit=%d&serialnumber=gt:%d", tokenID, migrationPageSize, afterSerial)
+	err := a.mirrorGet(ctx, path, &response)
+	if errors.Is(err, errMirrorNotFound) {
+		page.Done = true
+		return page, nil
+	}
+	if err != nil {
+		return page, err
+	}
+	for _, nft := range response.NFTs {
+		page.LastSerial = max(page.LastSerial, nft.SerialNumber)
+		if !nft.Deleted {
+			page.NFTs = append(page.NFTs, nft)
+		}
+	}
+	page.Done = response.Links.Next == "" || len(response.NFTs) == 0
+	return page, nil
+}
+
+// RemintNFTsActivity mints NFTs of the old collection of a zone into its new collection, at most
+// maxNFTsPerTransaction of them, with their original metadata. Like MintNFTActivity, the mint has a transaction
+// ID derived from what it mints, so the network rejects it when an earlier attempt submitted it already.
+func (a *Activities) RemintNFTsActivity(ctx context.Context, from, to ZoneCollectionInfo, nfts []MirrorNodeNFT) ([]MigratedNFT, error) {
+	if len(nfts) == 0 {
+		return nil, nil
+	}
+	if len(nfts) > maxNFTsPerTransaction {
+		return nil, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("%d NFTs to mint, at most %d are minted at a time", len(nfts), maxNFTsPerTransaction), ErrTypeInvalidMigration, nil)
+	}
+	tokenID, err := a.tokenIDFromString(to.TokenID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid collection token ID: %w", err)
+	}
+	accountID, privateKey, err := a.operatorCredentials()
+	if err != nil {
+		return nil, err
+	}
+	client, err := a.newHederaClient()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	client.SetOperator(accountID, privateKey)
+
+	metadatas := make([][]byte, len(nfts))
+	for i, nft := range nfts {
+		if metadatas[i], err = base64.StdEncoding.DecodeString(strings.TrimSpace(nft.Metadata)); err != nil {
+			metadatas[i] = []byte(nft.Metadata)
+		}
+	}
+	first, last := nfts[0].SerialNumber, nfts[len(nfts)-1].SerialNumber
+	mintTx := hedera.NewTokenMintTransaction().
+		SetTokenID(tokenID).
+		SetMetadatas(metadatas).
+		SetTransactionMemo(migrationMemo(from.TokenID, first, last)).
+		SetMaxTransactionFee(hedera.NewHbar(20))
+	txID, dedup := dedupTransactionID(ctx, accountID, to.TokenID, from.TokenID, strconv.FormatInt(first, 10), strconv.FormatInt(last, 10))
+	if dedup {
+		mintTx.SetTransactionID(txID).SetTransactionValidDuration(dedupValidDuration)
+	}
+
+	if err := a.txLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	if workerStopping(ctx) {
+		return nil, errWorkerShutdown(fmt.Sprintf("migration of serials %d-%d of %s", first, last, from.TokenID))
+	}
+	var record hedera.TransactionRecord
+	txResponse, err := mintTx.Execute(client)
+	switch {
+	case dedup && isStatus(err, hedera.StatusDuplicateTransaction):
+		record, err = hedera.NewTransactionRecordQuery().SetTransactionID(txID).Execute(client)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the record of the mint submitted by an earlier attempt: %w", err)
+		}
+		if record.TransactionMemo != mintTx.GetTransactionMemo() {
+			return nil, fmt.Errorf("transaction %s is not the mint of serials %d-%d of %s", txID, first, last, from.TokenID)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("transaction execution failed: %w", err)
+	default:
+		heartbeat(ctx, "submitted", txResponse.TransactionID.String())
+		if record, err = txResponse.GetRecord(client); err != nil {
+			return nil, fmt.Errorf("failed to get transaction record: %w", err)
+		}
+	}
+	serials := record.Receipt.SerialNumbers
+	if len(serials) != len(nfts) {
+		return nil, fmt.Errorf("mint %s returned %d serials for %d NFTs", record.TransactionID, len(serials), len(nfts))
+	}
+	a.saveTransactionRecord(ctx, newTransactionRecord(TransactionTokenMint, to.Zone.String(), to.TokenID, record))
+
+	migrated := make([]MigratedNFT, len(nfts))
+	for i, nft := range nfts {
+		label, _ := ParseNFTMetadata(string(metadatas[i]))
+		migrated[i] = MigratedNFT{Domain: label + "." + to.Zone.String(), FromSerial: nft.SerialNumber, ToSerial: serials[i]}
+		if nft.AccountID != accountID.String() {
+			migrated[i].Holder = nft.AccountID
+		}
+		a.storeMigration(ctx, migrated[i].Domain, to.TokenID, serials[i], record.TransactionID.String())
+	}
+	fmt.Printf("Minted serials %d-%d of %s into collection %s of .%s\n", first, last, a.displayID(from.TokenID), a.displayID(to.TokenID), to.Zone)
+	return migrated, nil
+}
+
+// SwitchZoneCollectionActivity replaces the collection of a zone in the zone registry with its new collection,
+// in a single write of the registry. The registry must still have the old collection: a zone whose collection
+// changed during the migration is not switched. The registry is locked from the check to the write, so a
+// lookup of the zone running meanwhile cannot save the old collection back over the switch.
+func (a *Activities) SwitchZoneCollectionActivity(ctx context.Context, from, to ZoneCollectionInfo) error {
+	unlock, err := a.lockZoneRegistry()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	registry, err := a.loadZoneRegistry()
+	if err != nil {
+		return fmt.Errorf("failed to load zone registry: %w", err)
+	}
+	current := registry.Collections[from.Zone]
+	if current.TokenID == to.TokenID {
+		return nil // Switched by an earlier attempt
+	}
+	if current.TokenID != from.TokenID {
+		return temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("the collection of .%s changed to %s during its migration from %s", from.Zone, current.TokenID, from.TokenID),
+			ErrTypeInvalidMigration, nil)
+	}
+	to.MigratedFrom = append(append([]string(nil), current.MigratedFrom...), from.TokenID)
+	registry.Collections[from.Zone] = to
+	registry.LastUpdated = time.Now()
+	if err := a.saveZoneRegistry(registry); err != nil {
+		return fmt.Errorf("failed to save zone registry: %w", err)
+	}
+	fmt.Printf("Switched the collection of .%s from %s to %s\n", from.Zone, a.displayID(from.TokenID), a.displayID(to.TokenID))
+	return nil
+}
+
+// BurnMigratedNFTsActivity burns NFTs of the old collection of a migrated zone, at most maxNFTsPerTransaction of
+// them. The NFTs must be in the treasury, and are recorded as migrated rather than burned: their domains live on
+// in the new collection.
+func (a *Activities) BurnMigratedNFTsActivity(ctx context.Context, from ZoneCollectionInfo, serials []int64) (int, error) {
+	if len(serials) == 0 {
+		return 0, nil
+	}
+	tokenID, err := a.tokenIDFromString(from.TokenID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid collection token ID: %w", err)
+	}
+	accountID, privateKey, err := a.operatorCredentials()
+	if err != nil {
+		return 0, err
+	}
+	client, err := a.newHederaClient()
+	if err != nil {
+		return 0, err
+	}
+	defer client.Close()
+	client.SetOperator(accountID, privateKey)
+
+	if err := a.txLimiter.Wait(ctx); err != nil {
+		return 0, err
+	}
+	if workerStopping(ctx) {
+		return 0, errWorkerShutdown("burn of the migrated collection " + from.TokenID)
+	}
+	txResponse, err := hedera.NewTokenBurnTransaction().
+		SetTokenID(tokenID).
+		SetSerialNumbers(serials).
+		SetTransactionMemo(fmt.Sprintf("sdl migrated .%s", from.Zone)).
+		Execute(client)
+	if err != nil {
+		return 0, fmt.Errorf("transaction execution failed: %w", err)
+	}
+	heartbeat(ctx, "submitted", txResponse.TransactionID.String())
+	record, err := txResponse.GetRecord(client)
+	// An earlier attempt burned them already, or they left the treasury since they were listed
+	if isStatus(err, hedera.StatusTreasuryMustOwnBurnedNft) {
+		fmt.Printf("Serials %d-%d of %s are burned already\n", serials[0], serials[len(serials)-1], a.displayID(from.TokenID))
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get transaction record: %w", err)
+	}
+	txRecord := newTransactionRecord(TransactionTokenBurn, from.Zone.String(), from.TokenID, record)
+	txRecord.Serials = serials
+	a.saveTransactionRecord(ctx, txRecord)
+	fmt.Printf("Burned %d migrated NFTs of %s\n", len(serials), a.displayID(from.TokenID))
+	return len(serials), nil
+}
+
+// DeleteCollectionActivity deletes the old collection of a migrated zone, signed by the admin signer with the
+// admin key the collection was created with
+func (a *Activities) DeleteCollectionActivity(ctx context.Context, from ZoneCollectionInfo) error {
+	admin, err := a.adminSigner()
+	if err != nil {
+		return err
+	}
+	if admin == nil {
+		return temporal.NewNonRetryableApplicationError(
+			"deleting a collection requires HEDERA_ADMIN_SIGNER_URL or HEDERA_ADMIN_SIGNER_COMMAND", ErrTypeNoAdminSigner, nil)
+	}
+	tokenID, err := a.tokenIDFromString(from.TokenID)
+	if err != nil {
+		return fmt.Errorf("invalid collection token ID: %w", err)
+	}
+	accountID, privateKey, err := a.operatorCredentials()
+	if err != nil {
+		return err
+	}
+	client, err := a.newHederaClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	client.SetOperator(accountID, privateKey)
+
+	deleteTx := hedera.NewTokenDeleteTransaction().SetTokenID(tokenID)
+	if err := signAdministrative(ctx, deleteTx, client, admin); err != nil {
+		return err
+	}
+	if err := a.txLimiter.Wait(ctx); err != nil {
+		return err
+	}
+	if workerStopping(ctx) {
+		return errWorkerShutdown("delete of the migrated collection " + from.TokenID)
+	}
+	txResponse, err := deleteTx.Execute(client)
+	if err == nil {
+		_, err = txResponse.GetReceipt(client)
+	}
+	switch {
+	case isStatus(err, hedera.StatusTokenWasDeleted):
+		return nil // Deleted by an earlier attempt
+	case isStatus(err, hedera.StatusTokenIsImmutable):
+		return temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("%s was created without an admin key, it cannot be deleted", from.TokenID), ErrTypeNoAdminKey, err)
+	case isStatus(err, hedera.StatusInvalidSignature):
+		return temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("the admin key of %s is not HEDERA_ADMIN_KEY", from.TokenID), ErrTypeNoAdminKey, err)
+	case err != nil:
+		return fmt.Errorf("token delete failed: %w", err)
+	}
+	fmt.Printf("Deleted the migrated collection %s of .%s\n", a.displayID(from.TokenID), from.Zone)
+	return nil
+}
+
+// MigrateCollectionWorkflow moves a zone to a new collection, e.g. after its keys were compromised or to apply a
+// new policy: it creates a new token with the current keys, naming and branding, mints every NFT of the old
+// collection that is not burned into it with its original metadata, switches the zone registry to it and
+// retires the old collection. NFTs held outside the treasury are minted into the treasury and reported, for
+// their holders to be given the new NFT.
+//
+// NFTs minted into the old collection until the switch are caught up after it, ingests of the zone should still
+// be stopped during a migration: a mint that started with the old collection may complete after the catch-up.
+func MigrateCollectionWorkflow(ctx workflow.Context, req CollectionMigrationRequest) (CollectionMigrationResult, error) {
+	logger := workflow.GetLogger(ctx)
+	if req.Retire == "" {
+		req.Retire = RetireBurn
+	}
+	switch req.Retire {
+	case RetireBurn, RetireDelete, RetireKeep:
+	default:
+		return req.Result, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("invalid retirement %q, expected %s, %s or %s", req.Retire, RetireBurn, RetireDelete, RetireKeep), ErrTypeInvalidMigration, nil)
+	}
+	ctx = workflow.WithActivityOptions(ctx, defaultActivityOptions())
+	// The holder of the admin key may have to confirm administrative transactions on a hardware wallet
+	adminOptions := defaultActivityOptions()
+	adminOptions.StartToCloseTimeout = 15 * time.Minute
+	adminCtx := workflow.WithActivityOptions(ctx, adminOptions)
+
+	if req.To.TokenID == "" {
+		if err := workflow.ExecuteActivity(ctx, "PrepareCollectionMigrationActivity", req.Zone).Get(ctx, &req.From); err != nil {
+			return req.Result, err
+		}
+		if err := workflow.ExecuteActivity(adminCtx, "CreateNFTCollectionActivity", req.From.Zone.String()).Get(ctx, &req.To); err != nil {
+			return req.Result, err
+		}
+		req.Result = CollectionMigrationResult{
+			Zone:        req.From.Zone.String(),
+			FromTokenID: req.From.TokenID,
+			ToTokenID:   req.To.TokenID,
+			Retire:      req.Retire,
+		}
+		logger.Info("Migrating zone collection", "zone", req.Zone, "from", req.From.TokenID, "to", req.To.TokenID)
+	}
+
+	finish := func() (CollectionMigrationResult, error) {
+		logger.Info("Migrated zone collection", "zone", req.Zone, "from", req.From.TokenID, "to", req.To.TokenID,
+			"reminted", req.Result.Reminted, "heldOutside", len(req.Result.HeldOutside), "burned", req.Result.Burned)
+		return req.Result, nil
+	}
+	for i := 0; i < migrationPagesPerRun; i++ {
+		if req.Retiring && req.Retire != RetireBurn {
+			if req.Retire == RetireDelete {
+				if err := workflow.ExecuteActivity(adminCtx, "DeleteCollectionActivity", req.From).Get(ctx, nil); err != nil {
+					return req.Result, err
+				}
+				req.Result.Deleted = true
+			}
+			return finish()
+		}
+
+		var page MigrationPage
+		if err := workflow.ExecuteActivity(ctx, "ListMigrationNFTsActivity", req.From.TokenID, req.AfterSerial).Get(ctx, &page); err != nil {
+			return req.Result, err
+		}
+		if req.Retiring {
+			// Only the NFTs in the treasury can be burned, the others are left to their holders
+			var serials []int64
+			for _, nft := range page.NFTs {
+				if nft.AccountID == req.From.CreatedBy {
+					serials = append(serials, nft.SerialNumber)
+				}
+			}
+			for start := 0; start < len(serials); start += maxNFTsPerTransaction {
+				var burned int
+				chunk := serials[start:min(start+maxNFTsPerTransaction, len(serials))]
+				if err := workflow.ExecuteActivity(ctx, "BurnMigratedNFTsActivity", req.From, chunk).Get(ctx, &burned); err != nil {
+					return req.Result, err
+				}
+				req.Result.Burned += burned
+			}
+		} else {
+			for start := 0; start < len(page.NFTs); start += maxNFTsPerTransaction {
+				var migrated []MigratedNFT
+				chunk := page.NFTs[start:min(start+maxNFTsPerTransaction, len(page.NFTs))]
+				if err := workflow.ExecuteActivity(ctx, "RemintNFTsActivity", req.From, req.To, chunk).Get(ctx, &migrated); err != nil {
+					return req.Result, err
+				}
+				req.Result.Reminted += len(migrated)
+				for _, nft := range migrated {
+					if nft.Holder != "" {
+						req.Result.HeldOutside = append(req.Result.HeldOutside, nft)
+					}
+				}
+			}
+		}
+		req.AfterSerial = page.LastSerial
+		if !page.Done {
+			continue
+		}
+
+		switch {
+		case req.Retiring:
+			return finish()
+		case req.Switched:
+			// Every NFT is in the new collection, the old one is retired from its first serial
+			req.Retiring, req.AfterSerial = true, 0
+		default:
+			if err := workflow.ExecuteActivity(ctx, "SwitchZoneCollectionActivity", req.From, req.To).Get(ctx, nil); err != nil {
+				return req.Result, err
+			}
+			req.Switched = true
+			logger.Info("Switched the zone registry to the new collection", "zone", req.Zone, "tokenID", req.To.TokenID)
+			// Then catch up with the NFTs minted into the old collection until the switch
+			if err := workflow.Sleep(ctx, migrationSettleDelay); err != nil {
+				return req.Result, err
+			}
+		}
+	}
+
+	// Keep the history of the migration of a large collection bounded
+	logger.Info("Continuing collection migration as new", "zone", req.Zone, "afterSerial", req.AfterSerial)
+	return req.Result, workflow.NewContinueAsNewError(ctx, MigrateCollectionWorkflow, req)
+}
@@ -0,0 +1,73 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// ErrTypeMintNotVisible is the application error type of mints the mirror node did not show in time
+const ErrTypeMintNotVisible = "MintNotVisible"
+
+// visibilityPollInterval is how often the mirror node is asked for the NFT of a mint
+const visibilityPollInterval = 2 * time.Second
+
+// MintVisibility is the outcome of a successful VerifyMintVisibleActivity
+type MintVisibility struct {
+	TokenID      string        `json:"token_id"`
+	SerialNumber int64         `json:"serial_number"`
+	Lag          time.Duration `json:"lag"` // From the consensus time of the mint to the NFT showing on the mirror node
+}
+
+// VerifyMintVisibleActivity polls the mirror node until the NFT of a mint shows, for at most timeout. The receipt
+// of a mint may succeed before the mirror node has imported it, and the API, exports and verifications of the
+// downstream consumers only see the mirror node. NFTs not visible in time fail with ErrTypeMintNotVisible.
+func (a *Activities) VerifyMintVisibleActivity(ctx context.Context, mint MintResult, timeout time.Duration) (MintVisibility, error) {
+	visibility := MintVisibility{TokenID: mint.TokenID, SerialNumber: mint.SerialNumber}
+	path := fmt.Sprintf("/tokens/%s/nfts/%d", mint.TokenID, mint.SerialNumber)
+	deadline := time.Now().Add(timeout)
+	for {
+		var nft MirrorNodeNFT
+		err := a.mirrorGet(ctx, path, &nft)
+		if err == nil {
+			if !mint.ConsensusAt.IsZero() {
+				visibility.Lag = time.Since(mint.ConsensusAt)
+			}
+			return visibility, nil
+		}
+		if !errors.Is(err, errMirrorNotFound) {
+			fmt.Printf("Warning: failed to look up NFT %d of %s on mirror node: %v\n", mint.SerialNumber, a.displayID(mint.TokenID), err)
+		}
+		if time.Now().After(deadline) {
+			return visibility, temporal.NewNonRetryableApplicationError(
+				fmt.Sprintf("NFT %d of %s (%s) not visible on the mirror node %s after the mint", mint.SerialNumber, mint.TokenID, mint.Domain, timeout),
+				ErrTypeMintNotVisible, err)
+		}
+		heartbeat(ctx, mint.SerialNumber)
+		select {
+		case <-ctx.Done():
+			return visibility, ctx.Err()
+		case <-time.After(visibilityPollInterval):
+		}
+	}
+}
+
+// verifyMintVisible waits for the NFT of a mint to show on the mirror node, reporting whether it did
+func verifyMintVisible(ctx workflow.Context, mint MintResult, timeout time.Duration) bool {
+	options := defaultActivityOptions()
+	options.StartToCloseTimeout = timeout + time.Minute
+	options.HeartbeatTimeout = time.Minute
+	ctx = workflow.WithActivityOptions(ctx, options)
+	var visibility MintVisibility
+	if err := workflow.ExecuteActivity(ctx, "VerifyMintVisibleActivity", mint, timeout).Get(ctx, &visibility); err != nil {
+		workflow.GetLogger(ctx).Warn("Minted NFT not visible on the mirror node", "domain", mint.Domain, "tokenID", mint.TokenID,
+			"serial", mint.SerialNumber, "error", err)
+		return false
+	}
+	workflow.GetLogger(ctx).Debug("Minted NFT visible on the mirror node", "domain", mint.Domain, "serial", mint.SerialNumber, "lag", visibility.Lag)
+	return true
+}
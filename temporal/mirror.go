@@ -0,0 +1,173 @@
+package temporal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// errMirrorNotFound is returned by mirrorGet when the mirror node answers 404
+var errMirrorNotFound = errors.New("not found on mirror node")
+
+// MirrorNodeToken is a token as returned by /tokens and /tokens/{id}
+type MirrorNodeToken struct {
+	TokenID           string `json:"token_id"`
+	Name              string `json:"name"`
+	Symbol            string `json:"symbol"`
+	Type              string `json:"type"`
+	TreasuryAccountID string `json:"treasury_account_id"`
+	CreatedTimestamp  string `json:"created_timestamp"`
+}
+
+type MirrorNodeTokensResponse struct {
+	Tokens []MirrorNodeToken `json:"tokens"`
+	Links  struct {
+		Next string `json:"next"`
+	} `json:"links"`
+}
+
+// MirrorNodeNFTTransaction is an entry of the transaction history of an NFT
+type MirrorNodeNFTTransaction struct {
+	TransactionID      string `json:"transaction_id"`
+	Type               string `json:"type"`
+	ConsensusTimestamp string `json:"consensus_timestamp"`
+	ReceiverAccountID  string `json:"receiver_account_id"`
+	SenderAccountID    string `json:"sender_account_id"`
+}
+
+type MirrorNodeNFTTransactionsResponse struct {
+	Transactions []MirrorNodeNFTTransaction `json:"transactions"`
+	Links        struct {
+		Next string `json:"next"`
+	} `json:"links"`
+}
+
+// MirrorNodeTransaction is a transaction as returned by /transactions/{id}
+type MirrorNodeTransaction struct {
+	TransactionID      string `json:"transaction_id"`
+	Name               string `json:"name"`
+	Result             string `json:"result"`
+	ConsensusTimestamp string `json:"consensus_timestamp"`
+	MemoBase64         string `json:"memo_base64"`
+	ChargedTxFee       int64  `json:"charged_tx_fee"` // In tinybar
+	EntityID           string `json:"entity_id"`      // The token of token transactions
+}
+
+type MirrorNodeTransactionsResponse struct {
+	Transactions []MirrorNodeTransaction `json:"transactions"`
+	Links        struct {
+		Next string `json:"next"`
+	} `json:"links"`
+}
+
+// MirrorNodeTopicMessage is a message as returned by /topics/{id}/messages
+type MirrorNodeTopicMessage struct {
+	ConsensusTimestamp string `json:"consensus_timestamp"`
+	Message            string `json:"message"` // Base64 encoded
+	PayerAccountID     string `json:"payer_account_id"`
+	RunningHash        string `json:"running_hash"` // Base64 encoded
+	SequenceNumber     uint64 `json:"sequence_number"`
+	TopicID            string `json:"topic_id"`
+}
+
+type MirrorNodeTopicMessagesResponse struct {
+	Messages []MirrorNodeTopicMessage `json:"messages"`
+	Links    struct {
+		Next string `json:"next"`
+	} `json:"links"`
+}
+
+// MirrorNodeAccount is an account as returned by /accounts/{id}
+type MirrorNodeAccount struct {
+	Account string `json:"account"`
+	Key     *struct {
+		Type string `json:"_type"`
+		Key  string `json:"key"` // Hex encoded raw public key
+	} `json:"key"`
+	MaxAutomaticTokenAssociations int `json:"max_automatic_token_associations"` // -1 for unlimited
+}
+
+// MirrorNodeTokenRelationship is an association of an account with a token, as returned by /accounts/{id}/tokens
+type MirrorNodeTokenRelationship struct {
+	TokenID              string `json:"token_id"`
+	AutomaticAssociation bool   `json:"automatic_association"`
+	Balance              int64  `json:"balance"`
+}
+
+type MirrorNodeTokenRelationshipsResponse struct {
+	Tokens []MirrorNodeTokenRelationship `json:"tokens"`
+	Links  struct {
+		Next string `json:"next"`
+	} `json:"links"`
+}
+
+// mirrorGet fetches a mirror node REST API path (e.g. "/tokens/0.0.123") and decodes the JSON response into out
+func (a *Activities) mirrorGet(ctx context.Context, path string, out interface{}) error {
+	if err := a.mirrorLimiter.Wait(ctx); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	req, err := a.newMirrorRequest(ctx, a.Config.Mirror.BaseURL+path)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query mirror node: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s: %w", path, errMirrorNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("mirror node returned status %d for %s", resp.StatusCode, path)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode mirror node response: %w", err)
+	}
+	return nil
+}
+
+// newMirrorRequest returns a GET request to the mirror node carrying the configured API key and headers
+func (a *Activities) newMirrorRequest(ctx context.Context, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range a.Config.Mirror.Header() {
+		req.Header[name] = values
+	}
+	return req, nil
+}
+
+// MirrorTransactionID converts a transaction ID from the SDK notation ("0.0.2@1700000000.000000001")
+// to the notation of the mirror node ("0.0.2-1700000000-000000001"). Other IDs are returned unchanged.
+func MirrorTransactionID(id string) string {
+	account, validStart, found := strings.Cut(id, "@")
+	if !found {
+		return id
+	}
+	return account + "-" + strings.Replace(validStart, ".", "-", 1)
+}
+
+// mirrorNextPath converts a pagination link of the mirror node (e.g. "/api/v1/tokens/0.0.1/nfts?serialnumber=lt:42")
+// into a path relative to the configured base URL, which already ends in the API version
+func (a *Activities) mirrorNextPath(next string) (string, error) {
+	nextURL, err := url.Parse(next)
+	if err != nil {
+		return "", err
+	}
+	base, err := url.Parse(a.Config.Mirror.BaseURL)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(nextURL.RequestURI(), base.Path), nil
+}
@@ -0,0 +1,47 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/eventschema"
+)
+
+// nsLookupTimeout bounds the DNS lookup of the nameservers of one domain
+const nsLookupTimeout = 10 * time.Second
+
+// lookupNameservers returns the nameservers a domain is delegated to in the DNS, through NAMESERVER_RESOLVER
+// when set. A domain that was just registered may not be delegated yet, so a failed lookup only warns and
+// the domain is minted without nameservers.
+func (a *Activities) lookupNameservers(ctx context.Context, domainName string) []string {
+	resolver := net.DefaultResolver
+	if addr := a.Config.Events.NSResolver; addr != "" {
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, nsLookupTimeout)
+	defer cancel()
+
+	records, err := resolver.LookupNS(ctx, domainName)
+	if err != nil {
+		fmt.Printf("Warning: could not look up the nameservers of %s: %v\n", domainName, err)
+		return nil
+	}
+	hosts := make([]string, len(records))
+	for i, record := range records {
+		hosts[i] = record.Host
+	}
+	nameservers, err := eventschema.NormalizeNameservers(hosts)
+	if err != nil {
+		fmt.Printf("Warning: ignoring the nameservers of %s: %v\n", domainName, err)
+		return nil
+	}
+	return nameservers
+}
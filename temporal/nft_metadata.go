@@ -0,0 +1,71 @@
+package temporal
+
+import (
+	"strings"
+)
+
+// maxNFTMetadataSize is the maximum size of NFT metadata accepted by Hedera
+const maxNFTMetadataSize = 100
+
+// maxMemoSize is the maximum size of transaction memos accepted by Hedera
+const maxMemoSize = 100
+
+// eventMemoPrefix prefixes the event hash in the memo of transactions without ingest run
+const eventMemoPrefix = "sdl event sha256:"
+
+// NFTMetadata returns the metadata of the NFT of a domain: its label, followed by the canonical hash of the
+// event it was minted for ("label#hash"). The hash is truncated to fit the metadata size limit for long labels,
+// the full hash is in the memo of the mint transaction.
+func NFTMetadata(label, eventHash string) string {
+	if eventHash == "" {
+		return label
+	}
+	metadata := label + "#" + eventHash
+	if len(metadata) > maxNFTMetadataSize {
+		metadata = metadata[:maxNFTMetadataSize]
+	}
+	return metadata
+}
+
+// ParseNFTMetadata splits NFT metadata into the label and the (possibly truncated) event hash.
+// Metadata of NFTs minted before event hashes were recorded is just the label.
+func ParseNFTMetadata(metadata string) (label, eventHash string) {
+	label, eventHash, _ = strings.Cut(strings.TrimSpace(metadata), "#")
+	return label, eventHash
+}
+
+// EventTrace is what the memo of a mint or burn transaction tells of the event it was submitted for
+type EventTrace struct {
+	RunID     string // Run ID of the ingest run that read the event, empty for memos without run
+	EventHash string // Hex canonical hash of the event, truncated in memos with a run ID
+}
+
+// EventMemo returns the memo of the mint or burn transaction of an event, tracing it back to its ingest run and
+// event without the off-chain records: "sdl run=<run ID> sha256:<hash>", the hash truncated to the memo size
+// limit. Without run ID, e.g. for claims, the memo is "sdl event sha256:<hash>" with the full hash.
+func EventMemo(runID, eventHash string) string {
+	if runID == "" {
+		return eventMemoPrefix + eventHash
+	}
+	memo := "sdl run=" + runID + " sha256:" + eventHash
+	if len(memo) > maxMemoSize {
+		memo = memo[:maxMemoSize]
+	}
+	return memo
+}
+
+// ParseEventMemo parses the memo of a mint or burn transaction, reporting whether it is the memo of an event
+func ParseEventMemo(memo string) (EventTrace, bool) {
+	if hash, ok := strings.CutPrefix(memo, eventMemoPrefix); ok {
+		return EventTrace{EventHash: hash}, true
+	}
+	rest, ok := strings.CutPrefix(memo, "sdl run=")
+	if !ok {
+		return EventTrace{}, false
+	}
+	runID, hash, ok := strings.Cut(rest, " sha256:")
+	if !ok || runID == "" || hash == "" {
+		return EventTrace{}, false
+	}
+	return EventTrace{RunID: runID, EventHash: hash}, true
+}
@@ -0,0 +1,157 @@
+package temporal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Events announced on the webhooks of NOTIFY_WEBHOOKS
+const (
+	NotifyRunStarted   = "run_started"
+	NotifyRunCompleted = "run_completed"
+	NotifyFailureAlert = "failure_alert"
+)
+
+// notifyTimeout bounds the post to a webhook, a slow chat service must not hold up the run
+const notifyTimeout = 10 * time.Second
+
+// Notification is an event of an ingest run posted to the webhooks of NOTIFY_WEBHOOKS.
+// Slack and Discord webhooks receive its text, other webhooks the whole notification as JSON.
+type Notification struct {
+	Event      string     `json:"event"` // NotifyRunStarted, NotifyRunCompleted or NotifyFailureAlert
+	WorkflowID string     `json:"workflow_id"`
+	FilePath   string     `json:"file_path"`
+	Text       string     `json:"text"`
+	Failed     int        `json:"failed,omitempty"`    // Domains failed so far, of a failure alert
+	Threshold  int        `json:"threshold,omitempty"` // NOTIFY_FAILURE_THRESHOLD, of a failure alert
+	Report     *RunReport `json:"report,omitempty"`    // Summary of a completed run
+}
+
+// NotifyActivity posts a notification to every webhook of NOTIFY_WEBHOOKS. A webhook that cannot be reached is
+// reported without failing the activity, which only fails when none received the notification, so a retry
+// does not post it twice.
+func (a *Activities) NotifyActivity(ctx context.Context, n Notification) error {
+	if n.Text == "" {
+		n.Text = notificationText(n)
+	}
+	var errs []error
+	for _, webhook := range a.Config.Notify.Webhooks {
+		if err := postNotification(ctx, webhook, n); err != nil {
+			fmt.Printf("Warning: failed to post the %s notification of %s to %s: %v\n", n.Event, n.WorkflowID, webhookHost(webhook), err)
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 && len(errs) == len(a.Config.Notify.Webhooks) {
+		return fmt.Errorf("no webhook received the notification: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// postNotification posts a notification to a webhook, in the payload of its chat service
+func postNotification(ctx context.Context, webhook string, n Notification) error {
+	var payload any = n
+	switch webhookHost(webhook) {
+	case "hooks.slack.com":
+		payload = map[string]string{"text": n.Text}
+	case "discord.com", "discordapp.com":
+		payload = map[string]string{"content": n.Text}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// webhookHost returns the host of a webhook, which identifies it in logs without leaking its secret path
+func webhookHost(webhook string) string {
+	u, err := url.Parse(webhook)
+	if err != nil {
+		return "an invalid webhook"
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// notificationText returns the message of a notification
+func notificationText(n Notification) string {
+	switch n.Event {
+	case NotifyRunStarted:
+		return fmt.Sprintf("Ingest of %s started (workflow %s)", n.FilePath, n.WorkflowID)
+	case NotifyFailureAlert:
+		return fmt.Sprintf("Ingest of %s has %d failed domains, the alert threshold is %d (workflow %s)", n.FilePath, n.Failed, n.Threshold, n.WorkflowID)
+	case NotifyRunCompleted:
+		if n.Report != nil {
+			return runSummary(*n.Report)
+		}
+	}
+	return fmt.Sprintf("Ingest of %s: %s (workflow %s)", n.FilePath, n.Event, n.WorkflowID)
+}
+
+// runSummary summarizes the report of a run in a line per zone
+func runSummary(report RunReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Ingest of %s %s", report.FilePath, report.Outcome)
+	if report.Partial {
+		b.WriteString(" (partial)")
+	}
+	fmt.Fprintf(&b, " in %s: %d events, fees %s (workflow %s)", report.FinishedAt.Sub(report.StartedAt).Round(time.Second), report.TotalEvents,
+		hedera.HbarFromTinybar(report.FeesTinybar), report.WorkflowID)
+	if report.Error != "" {
+		fmt.Fprintf(&b, "\nError: %s", report.Error)
+	}
+	if report.Suppressed > 0 {
+		fmt.Fprintf(&b, "\n%d repeated events suppressed", report.Suppressed)
+	}
+	for _, zone := range report.Zones {
+		fmt.Fprintf(&b, "\n.%s: %d minted, %d burned, %d skipped, %d failed of %d", zone.Zone, zone.Minted, zone.Burned, zone.Skipped, zone.Failed, zone.Total)
+	}
+	refused := make([]string, 0, len(report.RefusedZones))
+	for zone := range report.RefusedZones {
+		refused = append(refused, zone)
+	}
+	sort.Strings(refused)
+	for _, zone := range refused {
+		fmt.Fprintf(&b, "\n.%s: %d refused", zone, report.RefusedZones[zone])
+	}
+	for _, m := range report.Typosquats {
+		fmt.Fprintf(&b, "\n%s resembles %s (%.2f)", m.Domain, m.Brand, m.Score)
+	}
+	for _, g := range report.GeneratedLabels {
+		fmt.Fprintf(&b, "\n%s looks generated (%.2f)", g.Domain, g.Score)
+	}
+	return b.String()
+}
+
+// notify posts a notification of the run, a failure is logged without failing the run
+func notify(ctx workflow.Context, n Notification) {
+	n.WorkflowID = workflow.GetInfo(ctx).WorkflowExecution.ID
+	if err := workflow.ExecuteActivity(ctx, "NotifyActivity", n).Get(ctx, nil); err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to post notification", "event", n.Event, "error", err)
+	}
+}
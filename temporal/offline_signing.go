@@ -0,0 +1,460 @@
+package temporal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/signer"
+)
+
+// offlineValidDuration is how long transactions signed offline are valid once their validity starts, the
+// longest duration Hedera accepts
+const offlineValidDuration = 180 * time.Second
+
+// signedPollInterval is how often SIGNING_DIR is checked for the signed transaction
+const signedPollInterval = 5 * time.Second
+
+// Application error types of the transactions signed offline
+const (
+	ErrTypeSigningExpired  = "SigningExpired"  // The transaction was not signed before it expired
+	ErrTypeSignedMismatch  = "SignedMismatch"  // The signed transaction is not the transaction exported
+	ErrTypeSignedMalformed = "SignedMalformed" // The signed transaction cannot be decoded
+)
+
+// PendingTransaction is a frozen transaction exported to SIGNING_DIR, to be signed on an air-gapped machine
+// or by a signing service and submitted by SubmitSignedTransactionActivity
+type PendingTransaction struct {
+	ID            string    `json:"id"`   // Names the files of the transaction, see PendingTransactionPath and SignedTransactionPath
+	Type          string    `json:"type"` // TransactionTokenMint or TransactionTokenCreation
+	Summary       string    `json:"summary"`
+	Zone          string    `json:"zone"`
+	Domain        string    `json:"domain,omitempty"`   // The domain minted
+	TokenID       string    `json:"token_id,omitempty"` // The collection minted into
+	TransactionID string    `json:"transaction_id"`
+	ValidStart    time.Time `json:"valid_start"` // Submitted from then on
+	ValidUntil    time.Time `json:"valid_until"` // Must be signed before
+	Bytes         []byte    `json:"bytes"`       // The frozen transaction, Base64 encoded in JSON
+	ExportedAt    time.Time `json:"exported_at"`
+
+	Mint        *MintingInfo        `json:"mint,omitempty"`         // The registration minted, as redacted
+	MetadataURI string              `json:"metadata_uri,omitempty"` // The metadata document of the mint, if uploaded
+	Collection  *ZoneCollectionInfo `json:"collection,omitempty"`   // The collection created, without its token ID
+}
+
+// SubmittedTransaction is the outcome of a transaction signed offline once it reached consensus
+type SubmittedTransaction struct {
+	TransactionID string              `json:"transaction_id"`
+	Mint          *MintResult         `json:"mint,omitempty"`       // The NFT minted
+	Collection    *ZoneCollectionInfo `json:"collection,omitempty"` // The collection created, registered in the zone registry
+}
+
+// PendingTransactionPath returns the path of an exported transaction in SIGNING_DIR
+func PendingTransactionPath(dir, id string) string {
+	return filepath.Join(dir, id+".json")
+}
+
+// SignedTransactionPath returns the path the signed bytes of an exported transaction are expected at
+func SignedTransactionPath(dir, id string) string {
+	return filepath.Join(dir, id+".signed")
+}
+
+// exportTransaction freezes a transaction for the operator account and exports it to SIGNING_DIR. Its validity
+// starts HEDERA_SIGNING_DELAY from now, which is how long the signer has to sign it. The transaction is exported
+// with the signatures of the given signers, e.g. the admin signer of collection creations.
+func (a *Activities) exportTransaction(ctx context.Context, tx hedera.TransactionInterface, pending PendingTransaction, signers ...signer.Signer) (PendingTransaction, error) {
+	accountID, _, err := a.operatorAccount()
+	if err != nil {
+		return pending, err
+	}
+	client, err := a.newHederaClient()
+	if err != nil {
+		return pending, err
+	}
+
+	validStart := time.Now().Add(a.Config.Hedera.SigningDelay)
+	txID := hedera.NewTransactionIDWithValidStart(accountID, validStart)
+	if _, err := hedera.TransactionSetTransactionID(tx, txID); err != nil {
+		return pending, err
+	}
+	if _, err := hedera.TransactionSetTransactionValidDuration(tx, offlineValidDuration); err != nil {
+		return pending, err
+	}
+	if _, err := hedera.TransactionFreezeWith(tx, client); err != nil {
+		return pending, fmt.Errorf("failed to freeze transaction: %w", err)
+	}
+	if nodes, _ := hedera.TransactionGetNodeAccountIDs(tx); len(nodes) == 0 {
+		return pending, fmt.Errorf("no node of network %s to submit the transaction to", a.network())
+	}
+	for _, txSigner := range signers {
+		if txSigner == nil {
+			continue
+		}
+		if err := signer.SignTransaction(ctx, txSigner, tx); err != nil {
+			return pending, fmt.Errorf("failed to sign transaction: %w", err)
+		}
+	}
+	if pending.Bytes, err = hedera.TransactionToBytes(tx); err != nil {
+		return pending, fmt.Errorf("failed to serialize transaction: %w", err)
+	}
+
+	pending.TransactionID = txID.String()
+	pending.ID = MirrorTransactionID(pending.TransactionID)
+	pending.ValidStart = validStart.UTC()
+	pending.ValidUntil = validStart.Add(offlineValidDuration).UTC()
+	pending.ExportedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(pending, "", "  ")
+	if err != nil {
+		return pending, fmt.Errorf("failed to marshal pending transaction: %w", err)
+	}
+	if err := os.MkdirAll(a.Config.Registry.SigningDir, 0755); err != nil {
+		return pending, fmt.Errorf("failed to create signing directory: %w", err)
+	}
+	if err := os.WriteFile(PendingTransactionPath(a.Config.Registry.SigningDir, pending.ID), data, 0644); err != nil {
+		return pending, fmt.Errorf("failed to export transaction: %w", err)
+	}
+	fmt.Printf("Exported %s for signing: %s. Sign it before %s\n", pending.TransactionID, pending.Summary, pending.ValidUntil.Local().Format(time.DateTime))
+	return pending, nil
+}
+
+// SubmitSignedTransactionActivity waits for a transaction exported for offline signing to be signed, checks it
+// is the transaction exported, and submits it once valid. A mint is recorded like the mints of MintNFTActivity,
+// a collection is registered in the zone registry. Transactions not signed in time fail with ErrTypeSigningExpired.
+func (a *Activities) SubmitSignedTransactionActivity(ctx context.Context, pending PendingTransaction) (SubmittedTransaction, error) {
+	signedPath := SignedTransactionPath(a.Config.Registry.SigningDir, pending.ID)
+	var data []byte
+	for {
+		var err error
+		if data, err = os.ReadFile(signedPath); err == nil {
+			break
+		} else if !os.IsNotExist(err) {
+			return SubmittedTransaction{}, err
+		}
+		if time.Now().After(pending.ValidUntil) {
+			a.removePendingTransaction(pending)
+			return SubmittedTransaction{}, temporal.NewNonRetryableApplicationError(
+				fmt.Sprintf("transaction %s was not signed before it expired at %s", pending.TransactionID, pending.ValidUntil.Format(time.RFC3339)),
+				ErrTypeSigningExpired, nil)
+		}
+		if err := waitHeartbeating(ctx, signedPollInterval, "waiting for signature", pending.TransactionID); err != nil {
+			return SubmittedTransaction{}, err
+		}
+	}
+
+	tx, err := hedera.TransactionFromBytes(data)
+	if err != nil {
+		return SubmittedTransaction{}, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("failed to decode %s: %v", signedPath, err), ErrTypeSignedMalformed, err)
+	}
+	_, operatorKey, err := a.operatorAccount()
+	if err != nil {
+		return SubmittedTransaction{}, err
+	}
+	if err := checkSignedTransaction(pending, tx, operatorKey); err != nil {
+		return SubmittedTransaction{}, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("%s: %v", signedPath, err), ErrTypeSignedMismatch, err)
+	}
+
+	// The network refuses transactions before their validity starts
+	if wait := time.Until(pending.ValidStart); wait > 0 {
+		fmt.Printf("Transaction %s is signed, submitting it at %s\n", pending.TransactionID, pending.ValidStart.Local().Format(time.DateTime))
+		if err := waitHeartbeating(ctx, wait, "signed", pending.TransactionID); err != nil {
+			return SubmittedTransaction{}, err
+		}
+	}
+
+	client, err := a.newHederaClient()
+	if err != nil {
+		return SubmittedTransaction{}, err
+	}
+	if err := a.txLimiter.Wait(ctx); err != nil {
+		return SubmittedTransaction{}, err
+	}
+	if workerStopping(ctx) {
+		return SubmittedTransaction{}, errWorkerShutdown("signed transaction " + pending.TransactionID)
+	}
+	txID, _ := hedera.TransactionGetTransactionID(tx)
+	// A retry may find the transaction submitted by the attempt before, its receipt is then all that is missing
+	var precheck hedera.ErrHederaPreCheckStatus
+	if _, err := hedera.TransactionExecute(tx, client); err != nil &&
+		!(errors.As(err, &precheck) && precheck.Status == hedera.StatusDuplicateTransaction) {
+		return SubmittedTransaction{}, fmt.Errorf("transaction execution failed: %w", err)
+	}
+	heartbeat(ctx, "submitted", pending.TransactionID)
+
+	// The worker holds no key to pay for the record query, the receipt is free and the mirror node has the rest
+	receipt, err := hedera.NewTransactionReceiptQuery().SetTransactionID(txID).Execute(client)
+	if err != nil {
+		return SubmittedTransaction{}, fmt.Errorf("failed to get transaction receipt: %w", err)
+	}
+	if receipt.Status != hedera.StatusSuccess {
+		return SubmittedTransaction{}, fmt.Errorf("transaction %s failed with status %s", pending.TransactionID, receipt.Status)
+	}
+	record := hedera.TransactionRecord{
+		Receipt:            receipt,
+		TransactionID:      txID,
+		TransactionMemo:    mustTransactionMemo(tx),
+		ConsensusTimestamp: time.Now(),
+	}
+	if hash, err := hedera.TransactionGetTransactionHash(tx); err == nil {
+		record.TransactionHash = hash
+	}
+	if mirrorTx, err := a.waitForMirrorTransaction(ctx, pending.ID); err != nil {
+		fmt.Printf("Warning: Could not get transaction %s from the mirror node, recording it without fee: %v\n", pending.TransactionID, err)
+	} else {
+		record.ConsensusTimestamp = parseMirrorTimestamp(mirrorTx.ConsensusTimestamp)
+		record.TransactionFee = hedera.HbarFromTinybar(mirrorTx.ChargedTxFee)
+	}
+
+	submitted := SubmittedTransaction{TransactionID: pending.TransactionID}
+	switch pending.Type {
+	case TransactionTokenMint:
+		result := a.completeMint(ctx, *pending.Mint, pending.TokenID, pending.MetadataURI, record)
+		submitted.Mint = &result
+	case TransactionTokenCreation:
+		if receipt.TokenID == nil {
+			return SubmittedTransaction{}, fmt.Errorf("token creation failed: no token ID in receipt")
+		}
+		collection := *pending.Collection
+		collection.TokenID = receipt.TokenID.String()
+		collection.CreatedAt = record.ConsensusTimestamp
+		a.saveTransactionRecord(ctx, newTransactionRecord(TransactionTokenCreation, pending.Zone, collection.TokenID, record))
+		fmt.Printf("Successfully created NFT collection for .%s zone with token ID: %s\n", pending.Zone, a.displayID(collection.TokenID))
+		if err := a.registerZoneCollection(collection); err != nil {
+			fmt.Printf("Warning: Could not register collection %s of .%s: %v\n", collection.TokenID, pending.Zone, err)
+		}
+		submitted.Collection = &collection
+	}
+	a.removePendingTransaction(pending)
+	return submitted, nil
+}
+
+// waitHeartbeating waits for the given duration, heartbeating so that long waits do not time the activity out
+func waitHeartbeating(ctx context.Context, d time.Duration, details ...interface{}) error {
+	deadline := time.Now().Add(d)
+	for {
+		heartbeat(ctx, details...)
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(min(wait, 30*time.Second)):
+		}
+	}
+}
+
+// checkSignedTransaction returns an error unless a signed transaction is the transaction exported, signed by the
+// operator key
+func checkSignedTransaction(pending PendingTransaction, tx hedera.TransactionInterface, operatorKey hedera.PublicKey) error {
+	exported, err := hedera.TransactionFromBytes(pending.Bytes)
+	if err != nil {
+		return fmt.Errorf("failed to decode the transaction exported: %w", err)
+	}
+	txID, err := hedera.TransactionGetTransactionID(tx)
+	if err != nil || txID.String() != pending.TransactionID {
+		return fmt.Errorf("transaction ID %s, exported %s", txID, pending.TransactionID)
+	}
+	if mustTransactionMemo(tx) != mustTransactionMemo(exported) {
+		return errors.New("the memo differs from the transaction exported")
+	}
+	switch signed := tx.(type) {
+	case hedera.TokenMintTransaction:
+		original, ok := exported.(hedera.TokenMintTransaction)
+		if !ok || signed.GetTokenID().String() != original.GetTokenID().String() ||
+			fmt.Sprint(signed.GetMetadatas()) != fmt.Sprint(original.GetMetadatas()) {
+			return errors.New("not the mint exported")
+		}
+	case hedera.TokenCreateTransaction:
+		original, ok := exported.(hedera.TokenCreateTransaction)
+		if !ok || signed.GetTokenName() != original.GetTokenName() || signed.GetTokenSymbol() != original.GetTokenSymbol() ||
+			signed.GetTreasuryAccountID().String() != original.GetTreasuryAccountID().String() ||
+			fmt.Sprint(signed.GetSupplyKey()) != fmt.Sprint(original.GetSupplyKey()) {
+			return errors.New("not the collection creation exported")
+		}
+	default:
+		return fmt.Errorf("unexpected transaction type %T", tx)
+	}
+	// Every node the transaction may be submitted to has its own body, each must be signed by the operator key
+	signatures, err := hedera.TransactionGetSignatures(tx)
+	if err != nil || len(signatures) == 0 {
+		return errors.New("the transaction is not signed")
+	}
+	for node, keys := range signatures {
+		signed := false
+		for key := range keys {
+			signed = signed || key.StringDer() == operatorKey.StringDer()
+		}
+		if !signed {
+			return fmt.Errorf("the transaction to node %s is not signed by the operator key", node)
+		}
+	}
+	return nil
+}
+
+// mustTransactionMemo returns the memo of a transaction, empty if it has none
+func mustTransactionMemo(tx hedera.TransactionInterface) string {
+	memo, _ := hedera.TransactionGetTransactionMemo(tx)
+	return memo
+}
+
+// waitForMirrorTransaction fetches a transaction from the mirror node, waiting for the mirror node to import it
+func (a *Activities) waitForMirrorTransaction(ctx context.Context, mirrorID string) (MirrorNodeTransaction, error) {
+	var err error
+	for attempt := 0; attempt < 15; attempt++ {
+		var response MirrorNodeTransactionsResponse
+		if err = a.mirrorGet(ctx, "/transactions/"+mirrorID, &response); err == nil && len(response.Transactions) > 0 {
+			return response.Transactions[0], nil
+		}
+		select {
+		case <-ctx.Done():
+			return MirrorNodeTransaction{}, ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+	if err == nil {
+		err = errMirrorNotFound
+	}
+	return MirrorNodeTransaction{}, err
+}
+
+// registerZoneCollection adds a collection to the zone registry
+func (a *Activities) registerZoneCollection(collection ZoneCollectionInfo) error {
+	unlock, err := a.lockZoneRegistry()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	registry, err := a.loadZoneRegistry()
+	if err != nil {
+		return err
+	}
+	registry.Collections[collection.Zone] = collection
+	registry.LastUpdated = time.Now()
+	return a.saveZoneRegistry(registry)
+}
+
+// removePendingTransaction removes the files of a transaction submitted or expired from SIGNING_DIR
+func (a *Activities) removePendingTransaction(pending PendingTransaction) {
+	for _, path := range []string{
+		PendingTransactionPath(a.Config.Registry.SigningDir, pending.ID),
+		SignedTransactionPath(a.Config.Registry.SigningDir, pending.ID),
+	} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			fmt.Printf("Warning: Could not remove %s: %v\n", path, err)
+		}
+	}
+}
+
+// PendingTransactionsActivity lists the transactions of SIGNING_DIR waiting to be signed, by validity
+func (a *Activities) PendingTransactionsActivity(ctx context.Context) ([]PendingTransaction, error) {
+	entries, err := os.ReadDir(a.Config.Registry.SigningDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var pending []PendingTransaction
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(a.Config.Registry.SigningDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var tx PendingTransaction
+		if err := json.Unmarshal(data, &tx); err != nil {
+			fmt.Printf("Warning: Skipping %s: %v\n", entry.Name(), err)
+			continue
+		}
+		if _, err := os.Stat(SignedTransactionPath(a.Config.Registry.SigningDir, tx.ID)); err == nil {
+			continue // Signed, waiting to be submitted
+		}
+		pending = append(pending, tx)
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].ValidStart.Before(pending[j].ValidStart) })
+	return pending, nil
+}
+
+// SignPendingTransactionActivity signs a transaction of SIGNING_DIR with HEDERA_PRIVATE_KEY and writes the signed
+// bytes next to it. It is meant to run where the key is kept, with a copy of SIGNING_DIR.
+func (a *Activities) SignPendingTransactionActivity(ctx context.Context, id string) (PendingTransaction, error) {
+	var pending PendingTransaction
+	data, err := os.ReadFile(PendingTransactionPath(a.Config.Registry.SigningDir, id))
+	if err != nil {
+		return pending, err
+	}
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return pending, fmt.Errorf("invalid pending transaction %s: %w", id, err)
+	}
+	if time.Now().After(pending.ValidUntil) {
+		return pending, fmt.Errorf("transaction %s expired at %s", pending.TransactionID, pending.ValidUntil.Local().Format(time.DateTime))
+	}
+	accountID, privateKey, err := a.operatorCredentials()
+	if err != nil {
+		return pending, err
+	}
+	tx, err := hedera.TransactionFromBytes(pending.Bytes)
+	if err != nil {
+		return pending, fmt.Errorf("failed to decode transaction %s: %w", id, err)
+	}
+	if txID, _ := hedera.TransactionGetTransactionID(tx); txID.AccountID == nil || txID.AccountID.String() != accountID.String() {
+		return pending, fmt.Errorf("transaction %s is paid by another account than %s", pending.TransactionID, accountID)
+	}
+	if tx, err = hedera.TransactionSign(tx, privateKey); err != nil {
+		return pending, fmt.Errorf("failed to sign transaction %s: %w", id, err)
+	}
+	signed, err := hedera.TransactionToBytes(tx)
+	if err != nil {
+		return pending, fmt.Errorf("failed to serialize transaction %s: %w", id, err)
+	}
+	if err := os.WriteFile(SignedTransactionPath(a.Config.Registry.SigningDir, id), signed, 0644); err != nil {
+		return pending, err
+	}
+	return pending, nil
+}
+
+// submitSigned waits for a transaction exported for offline signing to be signed and submitted, for as long as
+// the transaction is valid
+func submitSigned(ctx workflow.Context, pending PendingTransaction) (SubmittedTransaction, error) {
+	options := defaultActivityOptions()
+	options.StartToCloseTimeout = pending.ValidUntil.Sub(workflow.Now(ctx)) + 10*time.Minute
+	options.HeartbeatTimeout = 2 * time.Minute
+	ctx = workflow.WithActivityOptions(ctx, options)
+	workflow.GetLogger(ctx).Info("Waiting for the transaction to be signed", "transaction", pending.TransactionID, "summary", pending.Summary,
+		"until", pending.ValidUntil)
+	var submitted SubmittedTransaction
+	err := workflow.ExecuteActivity(ctx, "SubmitSignedTransactionActivity", pending).Get(ctx, &submitted)
+	return submitted, err
+}
+
+// lookupOrCreateZoneCollection looks up or creates the collection of a zone, waiting for its creation to be
+// signed when signing offline
+func lookupOrCreateZoneCollection(ctx workflow.Context, zone string) (ZoneCollectionInfo, error) {
+	var zoneCollection ZoneCollectionInfo
+	if err := workflow.ExecuteActivity(ctx, "LookupOrCreateZoneCollectionActivity", zone).Get(ctx, &zoneCollection); err != nil {
+		return ZoneCollectionInfo{}, err
+	}
+	if zoneCollection.Pending == nil {
+		return zoneCollection, nil
+	}
+	submitted, err := submitSigned(ctx, *zoneCollection.Pending)
+	if err != nil {
+		return ZoneCollectionInfo{}, err
+	}
+	return *submitted.Collection, nil
+}
@@ -0,0 +1,94 @@
+package temporal
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+)
+
+// LatestNFT is the latest NFT of a domain in the collection of its zone
+type LatestNFT struct {
+	TokenID      string `json:"token_id"`
+	SerialNumber int64  `json:"serial_number"`
+	EventHash    string `json:"event_hash,omitempty"` // Hash of the event it was minted for, possibly truncated
+	Burned       bool   `json:"burned,omitempty"`
+}
+
+// MintedDomainsActivity returns the serial numbers of the given domains already minted in the collection of a
+// zone. A domain whose latest NFT was burned is not minted.
+func (a *Activities) MintedDomainsActivity(ctx context.Context, zoneCollection ZoneCollectionInfo, domains []string) (map[string]int64, error) {
+	latest, err := a.LatestNFTsActivity(ctx, zoneCollection, domains)
+	if err != nil || latest == nil {
+		return nil, err
+	}
+	minted := make(map[string]int64)
+	for name, nft := range latest {
+		if !nft.Burned {
+			minted[name] = nft.SerialNumber
+		}
+	}
+	return minted, nil
+}
+
+// LatestNFTsActivity returns the latest NFTs of the given domains in the collection of a zone, burned or not,
+// listing the NFTs of the collection, and of its earlier shards, once instead of searching for every domain.
+// Domains the collection never held an NFT of are left out.
+func (a *Activities) LatestNFTsActivity(ctx context.Context, zoneCollection ZoneCollectionInfo, domains []string) (map[string]LatestNFT, error) {
+	labels := make(map[string]string, len(domains)) // label -> domain
+	for _, name := range domains {
+		dn, err := domain.NewDomainName(name)
+		if err != nil {
+			continue // Invalid domains fail when minted
+		}
+		labels[dn.Label()] = name
+	}
+	if len(labels) == 0 {
+		return nil, nil
+	}
+
+	// The latest NFT of a label tells whether it is minted, it may have been burned and minted again. The shards
+	// of a sharded zone are listed from the newest, whose NFTs are later than those of the older shards.
+	latest := make(map[string]LatestNFT)
+	listed := 0
+	for _, tokenID := range zoneCollection.TokenIDs() {
+		nfts, err := a.queryCollectionNFTs(ctx, tokenID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the NFTs of collection %s: %w", tokenID, err)
+		}
+		listed += len(nfts)
+		shardLatest := make(map[string]LatestNFT)
+		for _, nft := range nfts {
+			metadata := strings.TrimSpace(nft.Metadata)
+			if decoded, err := base64.StdEncoding.DecodeString(metadata); err == nil {
+				metadata = string(decoded)
+			}
+			label, eventHash := ParseNFTMetadata(metadata)
+			name, wanted := labels[label]
+			if !wanted {
+				continue
+			}
+			if _, newer := latest[name]; newer {
+				continue
+			}
+			if previous, ok := shardLatest[name]; !ok || nft.SerialNumber > previous.SerialNumber {
+				shardLatest[name] = LatestNFT{TokenID: tokenID, SerialNumber: nft.SerialNumber, EventHash: eventHash, Burned: nft.Deleted}
+			}
+		}
+		for name, nft := range shardLatest {
+			latest[name] = nft
+		}
+	}
+
+	minted := 0
+	for _, nft := range latest {
+		if !nft.Burned {
+			minted++
+		}
+	}
+	fmt.Printf("Checked %d domains against the %d NFTs of collection %s: %d already minted, %d burned\n",
+		len(labels), listed, a.displayID(zoneCollection.TokenID), minted, len(latest)-minted)
+	return latest, nil
+}
@@ -0,0 +1,185 @@
+package temporal
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/entityid"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/merkle"
+)
+
+// ErrNotAnchored is returned when no anchored batch holds the event of a domain
+var ErrNotAnchored = errors.New("no anchored batch holds the domain")
+
+// InclusionProof proves that the event of a domain is a leaf of a Merkle tree whose root was anchored to HCS.
+// It is self-contained: anybody can check it with the public mirror node, without trusting the operator.
+type InclusionProof struct {
+	Domain         string    `json:"domain"`
+	Zone           string    `json:"zone"`
+	BatchID        string    `json:"batch_id"`
+	EventHash      string    `json:"event_hash"` // Data of the leaf
+	LeafIndex      int       `json:"leaf_index"`
+	TreeSize       int       `json:"tree_size"`
+	Algorithm      string    `json:"algorithm"`
+	AuditPath      []string  `json:"audit_path"` // Sibling hashes from the leaf up to the root
+	Root           string    `json:"root"`
+	TopicID        string    `json:"topic_id"`
+	SequenceNumber uint64    `json:"sequence_number"` // Message of the topic anchoring the root
+	ConsensusTime  time.Time `json:"consensus_time"`
+}
+
+// InclusionProofActivity builds the inclusion proof of the event of a domain against the most recently anchored
+// batch holding it, from the Merkle trees in the anchor directory
+func (a *Activities) InclusionProofActivity(ctx context.Context, domainName string) (InclusionProof, error) {
+	dn, err := domain.NewDomainName(domainName)
+	if err != nil {
+		return InclusionProof{}, fmt.Errorf("invalid domain name: %w", err)
+	}
+	paths, err := filepath.Glob(filepath.Join(a.Config.Registry.AnchorDir, "*.json"))
+	if err != nil {
+		return InclusionProof{}, err
+	}
+
+	var latest BatchAnchor
+	index := -1
+	for _, path := range paths {
+		anchor, err := a.loadAnchor(path)
+		if err != nil || anchor.SequenceNumber == 0 {
+			continue // Not an anchor, or its root was never anchored
+		}
+		if index >= 0 && !anchor.ConsensusTime.After(latest.ConsensusTime) {
+			continue
+		}
+		for i, event := range anchor.Events {
+			if event.Domain == dn.String() {
+				latest, index = anchor, i
+			}
+		}
+	}
+	if index < 0 {
+		return InclusionProof{}, fmt.Errorf("%s: %w", dn.String(), ErrNotAnchored)
+	}
+	return latest.Proof(index)
+}
+
+// Proof returns the inclusion proof of the event at index of an anchored batch
+func (b BatchAnchor) Proof(index int) (InclusionProof, error) {
+	leaves := make([][]byte, len(b.Events))
+	for i, event := range b.Events {
+		h, err := merkle.ParseHash(event.EventHash)
+		if err != nil {
+			return InclusionProof{}, fmt.Errorf("event on line %d: %w", event.Line, err)
+		}
+		leaves[i] = h[:]
+	}
+	path, err := merkle.Proof(leaves, index)
+	if err != nil {
+		return InclusionProof{}, err
+	}
+
+	proof := InclusionProof{
+		Domain:         b.Events[index].Domain,
+		Zone:           b.Zone,
+		BatchID:        b.BatchID,
+		EventHash:      b.Events[index].EventHash,
+		LeafIndex:      index,
+		TreeSize:       len(b.Events),
+		Algorithm:      b.Algorithm,
+		Root:           b.Root,
+		TopicID:        b.TopicID,
+		SequenceNumber: b.SequenceNumber,
+		ConsensusTime:  b.ConsensusTime,
+	}
+	for _, h := range path {
+		proof.AuditPath = append(proof.AuditPath, h.String())
+	}
+	return proof, nil
+}
+
+// Verify checks that the audit path leads from the event hash to the root of the proof
+func (p InclusionProof) Verify() error {
+	if p.Algorithm != merkle.Algorithm {
+		return fmt.Errorf("unsupported algorithm %q", p.Algorithm)
+	}
+	leaf, err := merkle.ParseHash(p.EventHash)
+	if err != nil {
+		return err
+	}
+	root, err := merkle.ParseHash(p.Root)
+	if err != nil {
+		return err
+	}
+	path := make([]merkle.Hash, len(p.AuditPath))
+	for i, s := range p.AuditPath {
+		if path[i], err = merkle.ParseHash(s); err != nil {
+			return err
+		}
+	}
+	if !merkle.VerifyInclusion(leaf[:], p.LeafIndex, p.TreeSize, path, root) {
+		return fmt.Errorf("the audit path does not lead from leaf %d to root %s", p.LeafIndex, p.Root)
+	}
+	return nil
+}
+
+// VerifyInclusionProofActivity checks an inclusion proof: its audit path, and the anchor message on the mirror node
+func (a *Activities) VerifyInclusionProofActivity(ctx context.Context, proof InclusionProof) ([]CheckResult, error) {
+	checks := []CheckResult{{Name: "audit path", OK: true, Detail: fmt.Sprintf("leaf %d of %d leads to root %s", proof.LeafIndex, proof.TreeSize, proof.Root)}}
+	if err := proof.Verify(); err != nil {
+		checks[0] = CheckResult{Name: "audit path", Detail: err.Error()}
+		return checks, nil
+	}
+
+	check := CheckResult{Name: "hcs anchor"}
+	id, err := entityid.ParseTopic(proof.TopicID, a.network())
+	if err != nil {
+		check.Detail = err.Error()
+		return append(checks, check), nil
+	}
+	topicID := id.String()
+	var message MirrorNodeTopicMessage
+	if err := a.mirrorGet(ctx, fmt.Sprintf("/topics/%s/messages/%d", topicID, proof.SequenceNumber), &message); err != nil {
+		check.Detail = err.Error()
+		return append(checks, check), nil
+	}
+	data, err := base64.StdEncoding.DecodeString(message.Message)
+	if err != nil {
+		check.Detail = fmt.Sprintf("message %d of %s is not base64: %v", proof.SequenceNumber, a.displayID(topicID), err)
+		return append(checks, check), nil
+	}
+	keys, err := a.producerKeys()
+	if err != nil {
+		return checks, err
+	}
+	payload, producer, err := openAuditMessage(keys, data)
+	if err != nil {
+		check.Detail = fmt.Sprintf("message %d of %s: %v", proof.SequenceNumber, a.displayID(topicID), err)
+		return append(checks, check), nil
+	}
+	var anchor AnchorMessage
+	if err := json.Unmarshal(payload, &anchor); err != nil || anchor.Type != "merkle_root" {
+		check.Detail = fmt.Sprintf("message %d of %s is not a Merkle root anchor", proof.SequenceNumber, a.displayID(topicID))
+		return append(checks, check), nil
+	}
+	switch {
+	case anchor.Root != proof.Root:
+		check.Detail = fmt.Sprintf("anchored root is %s, not %s", anchor.Root, proof.Root)
+	case anchor.Leaves != proof.TreeSize:
+		check.Detail = fmt.Sprintf("anchored tree has %d leaves, not %d", anchor.Leaves, proof.TreeSize)
+	case anchor.Algorithm != proof.Algorithm || anchor.Zone != proof.Zone || anchor.BatchID != proof.BatchID:
+		check.Detail = "the anchor message does not describe the batch of the proof"
+	default:
+		check.OK = true
+		check.Detail = fmt.Sprintf("message %d of %s at %s, paid by %s", message.SequenceNumber, a.displayID(topicID),
+			message.ConsensusTimestamp, a.displayID(message.PayerAccountID))
+		if producer != "" {
+			check.Detail += fmt.Sprintf(", published by %s", producer)
+		}
+	}
+	return append(checks, check), nil
+}
@@ -0,0 +1,125 @@
+package temporal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/eventschema"
+)
+
+// eventSchemas decodes the registry events of every schema version this build knows
+var eventSchemas = eventschema.Default()
+
+// Causes of quarantined events
+const (
+	QuarantineUnknownSchema = "unknown_schema" // The event declares a schema version this build cannot decode
+	QuarantineZoneMismatch  = "zone_mismatch"  // The domain of the event is not a name of its zone
+	QuarantineParseError    = "parse_error"    // The line or its event could not be parsed
+	QuarantineMintFailed    = "mint_failed"    // The mint of the domain failed after all retries
+	QuarantineBurnFailed    = "burn_failed"    // The burn of the domain failed after all retries
+)
+
+// QuarantinedEvent is an event kept in QUARANTINE_DIR because it could not be processed, e.g. as it declares
+// a schema version this build cannot decode. The line is kept as read, to be reprocessed once it can be.
+type QuarantinedEvent struct {
+	Line          string    `json:"line"`
+	LineNumber    int       `json:"line_number"`
+	EventHash     string    `json:"event_hash"`
+	SchemaVersion int       `json:"schema_version,omitempty"`
+	Cause         string    `json:"cause,omitempty"`       // Events quarantined before causes were recorded have an unknown schema
+	Reason        string    `json:"reason"`                // Diagnostic of the cause
+	WorkflowID    string    `json:"workflow_id,omitempty"` // The run that read the event
+	RunID         string    `json:"run_id,omitempty"`      // Its run, whose report the outcome of a retry is merged into
+	Domain        string    `json:"domain,omitempty"`      // Of events that were parsed
+	Zone          string    `json:"zone,omitempty"`
+	QuarantinedAt time.Time `json:"quarantined_at"`
+
+	// Retries by RetryQuarantineWorkflow that did not resolve the event
+	Retries     int        `json:"retries,omitempty"`
+	LastRetryAt *time.Time `json:"last_retry_at,omitempty"`
+}
+
+// QuarantinePath returns the path of the quarantined event with the given hash
+func QuarantinePath(dir, eventHash string) string {
+	return filepath.Join(dir, eventHash+".json")
+}
+
+// lineHash identifies a line whose event could not be hashed
+func lineHash(line string) string {
+	sum := sha256.Sum256([]byte(line))
+	return hex.EncodeToString(sum[:])
+}
+
+// quarantineEvent stores an event in the quarantine directory. The file is named after the event hash, so
+// an event read again, e.g. when the activity is retried, is quarantined once.
+func (a *Activities) quarantineEvent(ctx context.Context, event QuarantinedEvent) error {
+	path := QuarantinePath(a.Config.Registry.QuarantineDir, event.EventHash)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if activity.IsActivity(ctx) && event.WorkflowID == "" {
+		execution := activity.GetInfo(ctx).WorkflowExecution
+		event.WorkflowID, event.RunID = execution.ID, execution.RunID
+	}
+	event.QuarantinedAt = time.Now().UTC()
+
+	if err := os.MkdirAll(a.Config.Registry.QuarantineDir, 0755); err != nil {
+		return fmt.Errorf("failed to create quarantine directory: %w", err)
+	}
+	data, err := json.MarshalIndent(event, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal quarantined event: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to quarantine event: %w", err)
+	}
+	return nil
+}
+
+// QuarantineEventActivity quarantines an event the workflow could not process, e.g. a domain whose mint
+// failed after all retries, for RetryQuarantineWorkflow
+func (a *Activities) QuarantineEventActivity(ctx context.Context, event QuarantinedEvent) error {
+	return a.quarantineEvent(ctx, event)
+}
+
+// ingestRun returns the ingest run of a zone workflow, its parent, or the workflow itself when it has none
+func ingestRun(ctx workflow.Context) workflow.Execution {
+	if parent := workflow.GetInfo(ctx).ParentWorkflowExecution; parent != nil {
+		return *parent
+	}
+	return workflow.GetInfo(ctx).WorkflowExecution
+}
+
+// quarantineFailure quarantines the event of a domain whose mint or burn failed, under the ingest run that
+// read it. Domains without their event, e.g. imported ones, are not quarantined; a failure is only logged.
+func quarantineFailure(ctx workflow.Context, info MintingInfo, cause string, failure error) {
+	if info.FullEventJSON == "" || info.EventHash == "" {
+		return
+	}
+	run := ingestRun(ctx)
+	event := QuarantinedEvent{
+		Line:       strings.TrimSuffix(strings.TrimPrefix(info.FullEventJSON, "{"), "}"),
+		LineNumber: info.LineNumber,
+		EventHash:  info.EventHash,
+		Cause:      cause,
+		Reason:     failure.Error(),
+		WorkflowID: run.ID,
+		RunID:      run.RunID,
+		Domain:     info.DomainName,
+		Zone:       info.Zone.String(),
+	}
+	ctx = workflow.WithActivityOptions(ctx, defaultActivityOptions())
+	if err := workflow.ExecuteActivity(ctx, "QuarantineEventActivity", event).Get(ctx, nil); err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to quarantine event", "domain", info.DomainName, "zone", info.Zone, "error", err)
+	}
+}
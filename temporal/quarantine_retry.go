@@ -0,0 +1,505 @@
+package temporal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/zonepolicy"
+)
+
+// RetryQuarantineRequest is the input of RetryQuarantineWorkflow
+type RetryQuarantineRequest struct {
+	Causes     []string           // Causes of the quarantined events retried, every cause if empty
+	WorkflowID string             // Only the events read by this ingest run, of every run if empty
+	HCS        config.HCSConfig   // HCS topics the mints are published to
+	Zones      config.ZonesConfig // Zones ingested, the events of other zones stay quarantined
+	MaxMints   int                // Events the retry may mint or burn at most, unlimited if zero (MAX_MINTS_PER_RUN)
+
+	BudgetTinybar int64   // Fees the retry may spend before it pauses, unlimited if zero (RUN_BUDGET_HBAR)
+	BudgetUSD     float64 // Budget in US dollars, converted when BudgetTinybar is zero (RUN_BUDGET_USD)
+
+	// How long minted NFTs may take to show on the mirror node, not checked if zero (MIRROR_VISIBILITY_TIMEOUT)
+	VisibilityTimeout time.Duration
+}
+
+// Outcomes of retried events
+const (
+	RetryMinted  = "minted"
+	RetryBurned  = "burned"
+	RetrySkipped = "skipped" // Already minted, or not minted when it was burned
+	RetryIgnored = "ignored" // The policy of the zone ignores the action of the event
+	RetryFailed  = "failed"  // Still cannot be processed, the event stays quarantined
+	RetryRefused = "refused" // Its zone is refused by ZONE_ALLOWLIST or ZONE_DENYLIST, the event stays quarantined
+)
+
+// RetriedEvent is the outcome of the retry of a quarantined event, merged into the report of the run that read it
+type RetriedEvent struct {
+	EventHash  string    `json:"event_hash"` // Of the quarantined event
+	LineNumber int       `json:"line_number"`
+	Domain     string    `json:"domain,omitempty"`
+	Zone       string    `json:"zone,omitempty"`
+	Cause      string    `json:"cause"` // Why the event was quarantined
+	Outcome    string    `json:"outcome"`
+	Error      string    `json:"error,omitempty"` // Why the event still cannot be processed
+	FeeTinybar int64     `json:"fee_tinybar,omitempty"`
+	RetriedAt  time.Time `json:"retried_at"`
+}
+
+// Resolved reports whether the event was processed and left the quarantine
+func (e RetriedEvent) Resolved() bool {
+	return e.Outcome != "" && e.Outcome != RetryFailed && e.Outcome != RetryRefused
+}
+
+// QuarantineFilter selects quarantined events
+type QuarantineFilter struct {
+	Causes     []string // Every cause if empty
+	WorkflowID string   // Every run if empty
+}
+
+// ReparsedEvent is a quarantined event parsed again with the current code and configuration
+type ReparsedEvent struct {
+	Event QuarantinedEvent
+	Info  MintingInfo // The event to process, unless Error is set
+	Error string      // Why the event still cannot be processed
+}
+
+// RetryMerge is the outcome of the retried events of one ingest run
+type RetryMerge struct {
+	WorkflowID string
+	RunID      string
+	Events     []RetriedEvent
+}
+
+// RetryQuarantineResult summarizes a RetryQuarantineWorkflow
+type RetryQuarantineResult struct {
+	Retried  int
+	Resolved int
+	Failed   int
+	Reports  []string // Run reports the outcomes were merged into
+}
+
+// RetryQuarantineWorkflow reprocesses quarantined events with the current code and configuration: events
+// that could not be parsed are parsed again, events whose mint or burn failed are minted or burned again.
+// Resolved events leave the quarantine, the others stay with their retry counted. The outcomes are merged
+// into the report of the ingest run that read the events.
+func RetryQuarantineWorkflow(ctx workflow.Context, req RetryQuarantineRequest) (RetryQuarantineResult, error) {
+	logger := workflow.GetLogger(ctx)
+	ctx = workflow.WithActivityOptions(ctx, defaultActivityOptions())
+	var result RetryQuarantineResult
+
+	var quarantined []QuarantinedEvent
+	filter := QuarantineFilter{Causes: req.Causes, WorkflowID: req.WorkflowID}
+	if err := workflow.ExecuteActivity(ctx, "ListQuarantinedActivity", filter).Get(ctx, &quarantined); err != nil {
+		return result, err
+	}
+	if len(quarantined) == 0 {
+		logger.Info("No quarantined events to retry")
+		return result, nil
+	}
+	var reparsed []ReparsedEvent
+	if err := workflow.ExecuteActivity(ctx, "ReparseQuarantinedActivity", quarantined).Get(ctx, &reparsed); err != nil {
+		return result, err
+	}
+
+	// The outcome of every event, in the order of reparsed; events left without one were not retried
+	outcomes := make([]RetriedEvent, len(reparsed))
+	byHash := make(map[string]int)
+	var infos []MintingInfo
+	for i, r := range reparsed {
+		outcomes[i] = RetriedEvent{
+			EventHash:  r.Event.EventHash,
+			LineNumber: r.Event.LineNumber,
+			Domain:     r.Event.Domain,
+			Zone:       r.Event.Zone,
+			Cause:      r.Event.Cause,
+		}
+		if outcomes[i].Cause == "" {
+			outcomes[i].Cause = QuarantineUnknownSchema
+		}
+		if r.Error != "" {
+			outcomes[i].Outcome, outcomes[i].Error = RetryFailed, r.Error
+			continue
+		}
+		outcomes[i].Domain, outcomes[i].Zone = r.Info.DomainName, r.Info.Zone.String()
+		byHash[r.Info.EventHash] = i
+		infos = append(infos, r.Info)
+	}
+
+	groups, zones, _ := groupByZone(infos, req.Zones)
+	for _, info := range infos {
+		if !req.Zones.Allowed(info.Zone.String()) {
+			outcome := &outcomes[byHash[info.EventHash]]
+			outcome.Outcome, outcome.Error = RetryRefused, fmt.Sprintf("zone %s is refused", info.Zone)
+		}
+	}
+
+	// Refuse the retry before any collection is looked up or created, like an ingest run
+	pending := 0
+	for _, zone := range zones {
+		pending += len(groups[zone])
+	}
+	if err := checkMintCap(pending, req.MaxMints); err != nil {
+		logger.Error("Refusing to mint more domains than the cap", "pending", pending, "maxMints", req.MaxMints)
+		return result, err
+	}
+	budget, err := resolveBudget(ctx, req.BudgetTinybar, req.BudgetUSD)
+	if err != nil {
+		logger.Error("Failed to convert the budget of the retry", "error", err)
+		return result, err
+	}
+
+	var fees int64
+	for _, zone := range zones {
+		if err := retryZone(ctx, req, zone, groups[zone], outcomes, byHash, &fees, &budget); err != nil {
+			logger.Info("Retry of the quarantine canceled", "zone", zone)
+			break
+		}
+	}
+
+	// Settle the quarantine and the reports of what was retried, even when the retry was canceled
+	cleanupCtx, _ := workflow.NewDisconnectedContext(ctx)
+	now := workflow.Now(ctx)
+	var retried []RetriedEvent
+	var merges []RetryMerge
+	for i := range outcomes {
+		if outcomes[i].Outcome == "" {
+			continue
+		}
+		outcomes[i].RetriedAt = now
+		retried = append(retried, outcomes[i])
+		result.Retried++
+		if outcomes[i].Resolved() {
+			result.Resolved++
+		} else {
+			result.Failed++
+		}
+
+		// Events quarantined before their run was recorded have no report to merge into
+		event := reparsed[i].Event
+		if event.RunID == "" {
+			continue
+		}
+		j := slices.IndexFunc(merges, func(m RetryMerge) bool { return m.WorkflowID == event.WorkflowID && m.RunID == event.RunID })
+		if j < 0 {
+			merges = append(merges, RetryMerge{WorkflowID: event.WorkflowID, RunID: event.RunID})
+			j = len(merges) - 1
+		}
+		merges[j].Events = append(merges[j].Events, outcomes[i])
+	}
+	if err := workflow.ExecuteActivity(cleanupCtx, "SettleQuarantinedActivity", retried).Get(cleanupCtx, nil); err != nil {
+		logger.Error("Failed to settle quarantined events", "error", err)
+		return result, err
+	}
+	for _, merge := range merges {
+		var path string
+		if err := workflow.ExecuteActivity(cleanupCtx, "MergeRetriedEventsActivity", merge).Get(cleanupCtx, &path); err != nil {
+			logger.Warn("Failed to merge retried events into run report", "workflowID", merge.WorkflowID, "error", err)
+			continue
+		}
+		if path != "" {
+			result.Reports = append(result.Reports, path)
+		}
+	}
+	logger.Info("Retried quarantined events", "retried", result.Retried, "resolved", result.Resolved, "failed", result.Failed)
+	if ctx.Err() != nil {
+		return result, temporal.NewCanceledError(result)
+	}
+	return result, nil
+}
+
+// retryZone mints or burns the retried events of a zone as its policy says, recording their outcomes and
+// adding their fees up. It pauses before a mint or burn once the fees reach the budget, and only fails when
+// the retry is canceled.
+func retryZone(ctx workflow.Context, req RetryQuarantineRequest, zone string, domains []MintingInfo, outcomes []RetriedEvent, byHash map[string]int, fees, budget *int64) error {
+	logger := workflow.GetLogger(ctx)
+	fail := func(err error) {
+		for _, info := range domains {
+			outcome := &outcomes[byHash[info.EventHash]]
+			if outcome.Outcome == "" {
+				outcome.Outcome, outcome.Error = RetryFailed, err.Error()
+			}
+		}
+	}
+
+	var policy zonepolicy.Policy
+	if err := workflow.ExecuteActivity(ctx, "ZonePolicyActivity", zone).Get(ctx, &policy); err != nil {
+		logger.Error("Failed to load zone policy", "zone", zone, "error", err)
+		fail(err)
+		return ctx.Err()
+	}
+	zoneCollection, err := lookupOrCreateZoneCollection(ctx, zone)
+	if err != nil {
+		logger.Error("Failed to lookup/create zone collection", "zone", zone, "error", err)
+		fail(err)
+		return ctx.Err()
+	}
+
+	// Mints and burns are not interrupted by a cancellation, like those of ProcessZoneWorkflow
+	mintOptions := defaultActivityOptions()
+	mintOptions.HeartbeatTimeout = 2 * time.Minute
+	uncancelableCtx, _ := workflow.NewDisconnectedContext(ctx)
+	mintCtx := workflow.WithActivityOptions(uncancelableCtx, mintOptions)
+
+	batch := ZoneBatch{Zone: zone, VisibilityTimeout: req.VisibilityTimeout}
+	zoneBatchTopics(&batch, req.HCS)
+	progress := ZoneProgress{Zone: zone, WorkflowID: workflow.GetInfo(ctx).WorkflowExecution.ID}
+	var interval time.Duration
+	if policy.TransactionsPerSecond > 0 {
+		interval = time.Duration(float64(time.Second) / policy.TransactionsPerSecond)
+	}
+	var lastTransaction time.Time
+	var minted []MintingInfo
+	for _, info := range domains {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		outcome := &outcomes[byHash[info.EventHash]]
+		action := policy.Action(info.Action)
+		if action == zonepolicy.ActionIgnore {
+			outcome.Outcome = RetryIgnored
+			continue
+		}
+		if err := awaitBudget(ctx, *fees, budget); err != nil {
+			return err
+		}
+		if wait := lastTransaction.Add(interval).Sub(workflow.Now(ctx)); interval > 0 && wait > 0 {
+			if err := workflow.Sleep(ctx, wait); err != nil {
+				return err
+			}
+		}
+		lastTransaction = workflow.Now(ctx)
+		info.MetadataStore = policy.MetadataStore
+
+		before := progress
+		if action == zonepolicy.ActionBurn {
+			burnDomain(mintCtx, info, zoneCollection, &progress)
+		} else {
+			mintDomain(mintCtx, uncancelableCtx, batch, info, zoneCollection, &progress)
+		}
+		outcome.FeeTinybar = progress.FeesTinybar - before.FeesTinybar
+		*fees += outcome.FeeTinybar
+		switch {
+		case progress.Failed > before.Failed:
+			outcome.Outcome, outcome.Error = RetryFailed, progress.RecentFailures[len(progress.RecentFailures)-1].Error
+		case progress.Minted > before.Minted:
+			outcome.Outcome = RetryMinted
+			minted = append(minted, info)
+		case progress.Burned > before.Burned:
+			outcome.Outcome = RetryBurned
+		default:
+			outcome.Outcome = RetrySkipped
+		}
+	}
+
+	// Anchored zones anchor the retried mints as a batch of their own
+	if batch.AnchorTopic != "" && len(minted) > 0 {
+		anchorBatch(ctx, batch.AnchorTopic, fmt.Sprintf("%s_zone_%s", progress.WorkflowID, zone), zone, minted)
+	}
+	return nil
+}
+
+// ListQuarantinedActivity returns the quarantined events of the filter, by run and line
+func (a *Activities) ListQuarantinedActivity(ctx context.Context, filter QuarantineFilter) ([]QuarantinedEvent, error) {
+	paths, err := filepath.Glob(filepath.Join(a.Config.Registry.QuarantineDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var events []QuarantinedEvent
+	for _, path := range paths {
+		event, err := readQuarantinedEvent(path)
+		if err != nil {
+			return nil, err
+		}
+		if event.Cause == "" {
+			event.Cause = QuarantineUnknownSchema
+		}
+		if len(filter.Causes) > 0 && !slices.Contains(filter.Causes, event.Cause) {
+			continue
+		}
+		if filter.WorkflowID != "" && event.WorkflowID != filter.WorkflowID {
+			continue
+		}
+		events = append(events, event)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].WorkflowID != events[j].WorkflowID {
+			return events[i].WorkflowID < events[j].WorkflowID
+		}
+		return events[i].LineNumber < events[j].LineNumber
+	})
+	return events, nil
+}
+
+// ReparseQuarantinedActivity parses quarantined events again, as ParseAndFilterEventsActivity would now
+func (a *Activities) ReparseQuarantinedActivity(ctx context.Context, events []QuarantinedEvent) ([]ReparsedEvent, error) {
+	parser, err := a.eventParser()
+	if err != nil {
+		return nil, err
+	}
+	reparsed := make([]ReparsedEvent, len(events))
+	for i, event := range events {
+		reparsed[i].Event = event
+		info, quarantined, err := parser.parse(event.Line, event.LineNumber)
+		switch {
+		case quarantined != nil:
+			reparsed[i].Error = quarantined.Reason
+		case err != nil:
+			reparsed[i].Error = err.Error()
+		default:
+			// Transactions of the retry are traced back to the run that read the event
+			info.RunID = event.RunID
+			reparsed[i].Info = info
+		}
+	}
+	return reparsed, nil
+}
+
+// SettleQuarantinedActivity removes the resolved events from the quarantine, and records the retry of the
+// others. An event whose retry is recorded already is left as is, so the activity can be retried.
+func (a *Activities) SettleQuarantinedActivity(ctx context.Context, events []RetriedEvent) error {
+	for _, e := range events {
+		path := QuarantinePath(a.Config.Registry.QuarantineDir, e.EventHash)
+		if e.Resolved() {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to remove quarantined event: %w", err)
+			}
+			continue
+		}
+		event, err := readQuarantinedEvent(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if event.LastRetryAt != nil && event.LastRetryAt.Equal(e.RetriedAt) {
+			continue
+		}
+		retriedAt := e.RetriedAt
+		event.Retries++
+		event.LastRetryAt = &retriedAt
+		event.Reason = e.Error
+		data, err := json.MarshalIndent(event, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal quarantined event: %w", err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("failed to update quarantined event: %w", err)
+		}
+	}
+	return nil
+}
+
+// MergeRetriedEventsActivity merges the outcomes of retried events into the report of the ingest run that
+// read them, and returns its path. A run without a report, e.g. one that still runs, is left alone.
+func (a *Activities) MergeRetriedEventsActivity(ctx context.Context, merge RetryMerge) (string, error) {
+	path := RunReportPath(a.Config.Reports.Dir, merge.WorkflowID, merge.RunID)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Printf("Warning: no report of run %s to merge %d retried events into\n", merge.WorkflowID, len(merge.Events))
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read run report: %w", err)
+	}
+	var report RunReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return "", fmt.Errorf("failed to parse run report %s: %w", path, err)
+	}
+	if mergeRetried(&report, merge.Events) {
+		report.FeesUSD = 0 // Converted again at the current rate
+	}
+	written, err := a.WriteRunReportActivity(ctx, report)
+	if err != nil {
+		return written.Path, err
+	}
+	// The archived copy is replaced too
+	if _, err := a.ArchiveRunReportActivity(ctx, report.WorkflowID, report.RunID); err != nil {
+		fmt.Printf("Warning: merged run report of %s not archived: %v\n", report.WorkflowID, err)
+	}
+	return written.Path, nil
+}
+
+// mergeRetried merges retried events into a run report, once per event: an event resolved by an earlier
+// retry is not counted again. Resolved events whose mint or burn failed are moved from the failed domains
+// of their zone, the others were never counted by the run and are added to its totals. It reports whether
+// the fees of the run changed.
+func mergeRetried(report *RunReport, events []RetriedEvent) bool {
+	feesChanged := false
+	for _, e := range events {
+		i := slices.IndexFunc(report.Retried, func(r RetriedEvent) bool { return r.EventHash == e.EventHash })
+		if i >= 0 && report.Retried[i].Resolved() {
+			continue
+		}
+		if i >= 0 {
+			report.Retried[i] = e
+		} else {
+			report.Retried = append(report.Retried, e)
+		}
+		if !e.Resolved() {
+			continue
+		}
+
+		zone := reportZone(report, e.Zone)
+		switch e.Cause {
+		case QuarantineMintFailed, QuarantineBurnFailed:
+			if zone.Failed > 0 {
+				zone.Failed--
+			}
+		default:
+			zone.Total++
+			report.TotalEvents++
+		}
+		switch e.Outcome {
+		case RetryMinted:
+			zone.Minted++
+		case RetryBurned:
+			zone.Burned++
+		case RetrySkipped:
+			zone.Skipped++
+		case RetryIgnored:
+			zone.Ignored++
+		}
+		if e.FeeTinybar > 0 {
+			zone.FeesTinybar += e.FeeTinybar
+			report.FeesTinybar += e.FeeTinybar
+			feesChanged = true
+		}
+	}
+	return feesChanged
+}
+
+// reportZone returns the progress of a zone in a run report, adding it if the run had no domain of the zone
+func reportZone(report *RunReport, zone string) *ZoneProgress {
+	for i := range report.Zones {
+		if report.Zones[i].Zone == zone {
+			return &report.Zones[i]
+		}
+	}
+	report.Zones = append(report.Zones, ZoneProgress{Zone: zone, Done: true})
+	return &report.Zones[len(report.Zones)-1]
+}
+
+// readQuarantinedEvent reads a quarantined event from its file
+func readQuarantinedEvent(path string) (QuarantinedEvent, error) {
+	var event QuarantinedEvent
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return event, err
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return event, fmt.Errorf("failed to parse quarantined event %s: %w", filepath.Base(path), err)
+	}
+	return event, nil
+}
@@ -0,0 +1,85 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/zonepolicy"
+)
+
+// newRetryEnv returns a test environment retrying mint failures of the given domains with stub activities,
+// whose mints cost feeTinybar each and are counted in mints
+func newRetryEnv(domains []string, feeTinybar int64, mints *int) *testsuite.TestWorkflowEnvironment {
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterActivityWithOptions(func(context.Context, QuarantineFilter) ([]QuarantinedEvent, error) {
+		events := make([]QuarantinedEvent, len(domains))
+		for i, d := range domains {
+			events[i] = QuarantinedEvent{EventHash: fmt.Sprintf("h%d", i), LineNumber: i + 1, Domain: d, Zone: "build", Cause: QuarantineMintFailed}
+		}
+		return events, nil
+	}, activity.RegisterOptions{Name: "ListQuarantinedActivity"})
+	env.RegisterActivityWithOptions(func(_ context.Context, events []QuarantinedEvent) ([]ReparsedEvent, error) {
+		reparsed := make([]ReparsedEvent, len(events))
+		for i, e := range events {
+			reparsed[i] = ReparsedEvent{Event: e, Info: MintingInfo{DomainName: e.Domain, Zone: "build", EventHash: e.EventHash, LineNumber: e.LineNumber}}
+		}
+		return reparsed, nil
+	}, activity.RegisterOptions{Name: "ReparseQuarantinedActivity"})
+	env.RegisterActivityWithOptions(func(context.Context, string) (zonepolicy.Policy, error) {
+		return zonepolicy.Policy{}, nil
+	}, activity.RegisterOptions{Name: "ZonePolicyActivity"})
+	env.RegisterActivityWithOptions(func(context.Context, string) (ZoneCollectionInfo, error) {
+		return ZoneCollectionInfo{TokenID: "0.0.7"}, nil
+	}, activity.RegisterOptions{Name: "LookupOrCreateZoneCollectionActivity"})
+	env.RegisterActivityWithOptions(func(_ context.Context, info MintingInfo, collection ZoneCollectionInfo) (MintResult, error) {
+		*mints++
+		return MintResult{Domain: info.DomainName, TokenID: collection.TokenID, SerialNumber: int64(*mints), TransactionID: "tx", FeeTinybar: feeTinybar}, nil
+	}, activity.RegisterOptions{Name: "MintNFTActivity"})
+	env.RegisterActivityWithOptions(func(context.Context, []RetriedEvent) error {
+		return nil
+	}, activity.RegisterOptions{Name: "SettleQuarantinedActivity"})
+	return env
+}
+
+func TestRetryQuarantineWorkflow_MintCap(t *testing.T) {
+	mints := 0
+	env := newRetryEnv([]string{"a.build", "b.build"}, 0, &mints)
+	env.ExecuteWorkflow(RetryQuarantineWorkflow, RetryQuarantineRequest{MaxMints: 1})
+	require.True(t, env.IsWorkflowCompleted())
+
+	var appErr *temporal.ApplicationError
+	require.True(t, errors.As(env.GetWorkflowError(), &appErr))
+	assert.Equal(t, ErrTypeMintCapExceeded, appErr.Type())
+	assert.Zero(t, mints, "nothing is minted past the cap")
+}
+
+func TestRetryQuarantineWorkflow_PausesOnBudget(t *testing.T) {
+	mints := 0
+	env := newRetryEnv([]string{"a.build", "b.build", "c.build"}, 10, &mints)
+	env.RegisterDelayedCallback(func() {
+		assert.Equal(t, 1, mints, "the retry pauses once its fees reach the budget")
+		env.SignalWorkflow(BudgetSignal, BudgetRaise{AddTinybar: 10})
+	}, time.Hour)
+	env.RegisterDelayedCallback(func() {
+		assert.Equal(t, 2, mints)
+		env.SignalWorkflow(BudgetSignal, BudgetRaise{Unlimited: true})
+	}, 2*time.Hour)
+
+	env.ExecuteWorkflow(RetryQuarantineWorkflow, RetryQuarantineRequest{BudgetTinybar: 10})
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	var result RetryQuarantineResult
+	require.NoError(t, env.GetWorkflowResult(&result))
+	assert.Equal(t, 3, mints)
+	assert.Equal(t, 3, result.Resolved)
+}
@@ -0,0 +1,79 @@
+package temporal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/metadata"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/redact"
+)
+
+// MintReceiptVersion is the version of the mint receipt format
+const MintReceiptVersion = 1
+
+// MintReceipt is the compact proof of a mint published to the receipts topic. The receipts of a
+// topic are enough to reconstruct the complete mint history without the registry's own records.
+type MintReceipt struct {
+	Version       int                `json:"v"`
+	DomainHash    string             `json:"domain_hash"` // Hex SHA-256 of the normalized domain name
+	Zone          string             `json:"zone"`
+	TokenID       string             `json:"token_id"`
+	SerialNumber  int64              `json:"serial"`
+	TransactionID string             `json:"mint_tx"`
+	ConsensusAt   time.Time          `json:"consensus_at"`
+	MetadataURI   string             `json:"metadata_uri,omitempty"`  // Off-chain metadata document of the NFT
+	Nameservers   []string           `json:"ns,omitempty"`            // Delegation of the domain when it was minted
+	Registrant    string             `json:"registrant_fp,omitempty"` // Fingerprint of the registrant
+	Redacted      []redact.Redaction `json:"redacted,omitempty"`      // Fields withheld by the redaction policy of the registry
+	Previous      *metadata.Lineage  `json:"previous,omitempty"`      // NFT of the earlier registration of a re-registered domain
+}
+
+// DomainHash returns the hex SHA-256 of a domain name, as published in mint receipts
+func DomainHash(domainName string) string {
+	sum := sha256.Sum256([]byte(domainName))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewMintReceipt builds the receipt of a successful mint
+func NewMintReceipt(zone string, result MintResult) MintReceipt {
+	return MintReceipt{
+		Version:       MintReceiptVersion,
+		DomainHash:    DomainHash(result.Domain),
+		Zone:          zone,
+		TokenID:       result.TokenID,
+		SerialNumber:  result.SerialNumber,
+		TransactionID: result.TransactionID,
+		ConsensusAt:   result.ConsensusAt.UTC(),
+		MetadataURI:   result.MetadataURI,
+		Nameservers:   result.Nameservers,
+		Registrant:    result.Registrant,
+		Redacted:      result.Redacted,
+		Previous:      result.Previous,
+	}
+}
+
+// receiptsTopicMemo is the memo of the receipts topic, whichever of its messages creates it
+const receiptsTopicMemo = "Mint receipts of the shadow domain ledger"
+
+// PublishMintReceiptActivity publishes the receipt of a mint to the receipts topic, creating the topic on first use.
+// Only the operator can submit to the topic, so its receipts can be attributed to the registry, and with
+// HCS_PRODUCER_KEY set every receipt is enveloped with the signature of the worker that published it.
+func (a *Activities) PublishMintReceiptActivity(ctx context.Context, topicName, zone string, result MintResult) (TopicMessage, error) {
+	topic, err := a.LookupOrCreateTopicActivity(ctx, topicName, receiptsTopicMemo, true, true)
+	if err != nil {
+		return TopicMessage{}, fmt.Errorf("failed to look up receipts topic: %w", err)
+	}
+	receipt, err := json.Marshal(NewMintReceipt(zone, result))
+	if err != nil {
+		return TopicMessage{}, fmt.Errorf("failed to marshal mint receipt: %w", err)
+	}
+	message, err := a.sealAuditMessage(receipt)
+	if err != nil {
+		return TopicMessage{}, err
+	}
+	return a.SendMessageToTopicActivity(ctx, topic.TopicID, message)
+}
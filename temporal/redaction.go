@@ -0,0 +1,35 @@
+package temporal
+
+import (
+	"time"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/redact"
+)
+
+// redactMintingInfo applies REDACTION_POLICY to the personal data of a registration before any of it is
+// published, returning the registration as it may be published with the fields that were redacted.
+// Hashed fields are keyed with the registrant fingerprint key, so equal values still link.
+func (a *Activities) redactMintingInfo(info MintingInfo) (MintingInfo, error) {
+	policy := a.Config.Events.Redaction
+	if len(policy) == 0 {
+		return info, nil
+	}
+	var hash func(field, value string) string
+	if policy.Hashes() {
+		key, err := a.fingerprintKey()
+		if err != nil {
+			return info, err
+		}
+		hash = key.Sum
+	}
+
+	r := policy.NewRedactor(hash)
+	info.RegistrarID = r.String(redact.FieldRegistrar, info.RegistrarID)
+	info.Nameservers = r.Strings(redact.FieldNameservers, info.Nameservers)
+	info.RegistrantFingerprint = r.String(redact.FieldRegistrant, info.RegistrantFingerprint)
+	if r.Strip(redact.FieldRegisteredAt, !info.RegistrationTime.IsZero()) {
+		info.RegistrationTime = time.Time{}
+	}
+	info.Redacted = r.Redactions
+	return info, nil
+}
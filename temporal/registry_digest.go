@@ -0,0 +1,346 @@
+package temporal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/entityid"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/store"
+)
+
+// RegistryDigestVersion is the version of the registry digest message format
+const RegistryDigestVersion = 1
+
+// DefaultRegistryDigestInterval is how often RegistryDigestWorkflow digests the registry state by default
+const DefaultRegistryDigestInterval = time.Hour
+
+// ErrTypeNoDigestTopic is the application error type of digests without HCS_DIGEST_TOPIC
+const ErrTypeNoDigestTopic = "NoDigestTopic"
+
+// registryDigestsPerRun bounds the iterations of RegistryDigestWorkflow before it continues as new
+const registryDigestsPerRun = 100
+
+// Components of the registry state digested
+const (
+	DigestComponentZones   = "zones"   // The collections of the zone registry
+	DigestComponentTopics  = "topics"  // The topics of the topic registry
+	DigestComponentDomains = "domains" // The domains of the registry store, when REGISTRY_STORE_DSN is set
+)
+
+// DigestComponent is the digest of one part of the registry state
+type DigestComponent struct {
+	Name    string `json:"name"`
+	Entries int    `json:"entries"`
+	SHA256  string `json:"sha256"`
+}
+
+// RegistryDigest is the digest of the registry state, published to HCS_DIGEST_TOPIC by RegistryDigestWorkflow.
+// Each component hashes its entries in a fixed order, one JSON line each, so the digest does not depend on
+// the formatting of the files; the digest hashes the names and hashes of the components.
+type RegistryDigest struct {
+	Version    int               `json:"v"`
+	Type       string            `json:"type"` // Always "registry_digest"
+	Digest     string            `json:"digest"`
+	Components []DigestComponent `json:"components"`
+	At         time.Time         `json:"at"`
+}
+
+// RegistryDigestRequest is the input of RegistryDigestWorkflow
+type RegistryDigestRequest struct {
+	Topic      string        `json:"topic"`                 // Topic registry name of the digest topic, HCS_DIGEST_TOPIC
+	Interval   time.Duration `json:"interval"`              // Between two digests of the registry state
+	LastDigest string        `json:"last_digest,omitempty"` // Last digest published, carried over when continuing as new
+}
+
+// PublishedDigest is a registry digest and where it was published, if it was
+type PublishedDigest struct {
+	RegistryDigest
+	Published      bool      `json:"published"` // False when the state did not change since the last digest
+	TopicID        string    `json:"topic_id,omitempty"`
+	SequenceNumber uint64    `json:"sequence_number,omitempty"`
+	ConsensusTime  time.Time `json:"consensus_time,omitempty"`
+}
+
+// RegistryDigestWorkflow digests the zone registry, the topic registry and the registry store every interval
+// and publishes the digest to the digest topic whenever the state changed since the last one. The digests on
+// HCS prove later what the registry held, see VerifyRegistryActivity.
+func RegistryDigestWorkflow(ctx workflow.Context, req RegistryDigestRequest) error {
+	logger := workflow.GetLogger(ctx)
+	if req.Topic == "" {
+		return temporal.NewNonRetryableApplicationError("HCS_DIGEST_TOPIC is not set", ErrTypeNoDigestTopic, nil)
+	}
+	if req.Interval <= 0 {
+		req.Interval = DefaultRegistryDigestInterval
+	}
+	ctx = workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	for range registryDigestsPerRun {
+		var digest PublishedDigest
+		err := workflow.ExecuteActivity(ctx, "PublishRegistryDigestActivity", req.Topic, req.LastDigest).Get(ctx, &digest)
+		switch {
+		case err != nil:
+			// The next digest covers the state missed
+			logger.Error("Failed to digest the registry", "error", err)
+		case digest.Published:
+			logger.Info("Published registry digest", "digest", digest.Digest, "sequence", digest.SequenceNumber)
+			req.LastDigest = digest.Digest
+		default:
+			req.LastDigest = digest.Digest
+		}
+		if err := workflow.Sleep(ctx, req.Interval); err != nil {
+			return err
+		}
+	}
+
+	// Keep the history of the workflow bounded
+	return workflow.NewContinueAsNewError(ctx, RegistryDigestWorkflow, req)
+}
+
+// PublishRegistryDigestActivity digests the registry state and publishes the digest to the digest topic,
+// creating the topic on first use, unless it equals the last digest published
+func (a *Activities) PublishRegistryDigestActivity(ctx context.Context, topicName, lastDigest string) (PublishedDigest, error) {
+	digest, err := a.registryDigest(ctx)
+	if err != nil {
+		return PublishedDigest{}, err
+	}
+	result := PublishedDigest{RegistryDigest: digest}
+	if digest.Digest == lastDigest {
+		fmt.Printf("Registry state unchanged since digest %s\n", digest.Digest)
+		return result, nil
+	}
+
+	topic, err := a.LookupOrCreateTopicActivity(ctx, topicName, "Registry state digests of the shadow domain ledger", true, true)
+	if err != nil {
+		return result, fmt.Errorf("failed to look up digest topic: %w", err)
+	}
+	message, err := json.Marshal(digest)
+	if err != nil {
+		return result, fmt.Errorf("failed to marshal registry digest: %w", err)
+	}
+	sealed, err := a.sealAuditMessage(message)
+	if err != nil {
+		return result, err
+	}
+	sent, err := a.SendMessageToTopicActivity(ctx, topic.TopicID, sealed)
+	if err != nil {
+		return result, err
+	}
+	result.Published = true
+	result.TopicID, result.SequenceNumber, result.ConsensusTime = sent.TopicID, sent.SequenceNumber, sent.ConsensusTime
+	fmt.Printf("Published registry digest %s as message %d of topic %s\n", digest.Digest, sent.SequenceNumber, a.displayID(sent.TopicID))
+	return result, nil
+}
+
+// registryDigest digests the current registry state
+func (a *Activities) registryDigest(ctx context.Context) (RegistryDigest, error) {
+	digest := RegistryDigest{Version: RegistryDigestVersion, Type: "registry_digest", At: time.Now().UTC()}
+
+	zones, err := a.loadZoneRegistry()
+	if err != nil {
+		return digest, fmt.Errorf("failed to load zone registry: %w", err)
+	}
+	collections := make([]ZoneCollectionInfo, 0, len(zones.Collections))
+	for _, collection := range zones.Collections {
+		collections = append(collections, collection)
+	}
+	sort.Slice(collections, func(i, j int) bool { return collections[i].Zone < collections[j].Zone })
+	component, err := digestEntries(DigestComponentZones, collections)
+	if err != nil {
+		return digest, err
+	}
+	digest.Components = append(digest.Components, component)
+
+	topics, err := a.loadTopicRegistry()
+	if err != nil {
+		return digest, fmt.Errorf("failed to load topic registry: %w", err)
+	}
+	infos := make([]TopicInfo, 0, len(topics.Topics))
+	for _, topic := range topics.Topics {
+		infos = append(infos, topic)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].TopicName < infos[j].TopicName })
+	if component, err = digestEntries(DigestComponentTopics, infos); err != nil {
+		return digest, err
+	}
+	digest.Components = append(digest.Components, component)
+
+	s, err := a.domainStore(ctx)
+	if err != nil {
+		return digest, fmt.Errorf("failed to open registry store: %w", err)
+	}
+	if s != nil {
+		if component, err = digestStore(ctx, s); err != nil {
+			return digest, err
+		}
+		digest.Components = append(digest.Components, component)
+	}
+
+	h := sha256.New()
+	for _, component := range digest.Components {
+		io.WriteString(h, component.Name+" "+component.SHA256+"\n")
+	}
+	digest.Digest = hex.EncodeToString(h.Sum(nil))
+	return digest, nil
+}
+
+// digestEntries hashes entries, one JSON line each, in order
+func digestEntries[T any](name string, entries []T) (DigestComponent, error) {
+	h := sha256.New()
+	for _, entry := range entries {
+		if err := writeDigestLine(h, entry); err != nil {
+			return DigestComponent{}, fmt.Errorf("failed to digest %s: %w", name, err)
+		}
+	}
+	return DigestComponent{Name: name, Entries: len(entries), SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// digestStore hashes the domains of the registry store in the order they were minted. Only what the ledger
+// recorded is hashed, not the time the rows were last updated.
+func digestStore(ctx context.Context, s *store.Store) (DigestComponent, error) {
+	const pageSize = 1000
+	h := sha256.New()
+	component := DigestComponent{Name: DigestComponentDomains}
+	q := store.Query{Limit: pageSize}
+	for {
+		domains, next, err := s.ListDomains(ctx, q)
+		if err != nil {
+			return component, err
+		}
+		for _, d := range domains {
+			d.UpdatedAt = time.Time{}
+			if err := writeDigestLine(h, d); err != nil {
+				return component, fmt.Errorf("failed to digest %s: %w", component.Name, err)
+			}
+		}
+		component.Entries += len(domains)
+		if next == nil {
+			break
+		}
+		q.After = next
+	}
+	component.SHA256 = hex.EncodeToString(h.Sum(nil))
+	return component, nil
+}
+
+// writeDigestLine writes the JSON encoding of an entry and a newline to a hash
+func writeDigestLine(h hash.Hash, entry any) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	h.Write(append(line, '\n'))
+	return nil
+}
+
+// RegistryVerification compares the registry state with the last digest published on the digest topic
+type RegistryVerification struct {
+	Local          RegistryDigest  `json:"local"`
+	Published      *RegistryDigest `json:"published,omitempty"` // Nil when the topic has no digest
+	TopicID        string          `json:"topic_id"`
+	SequenceNumber uint64          `json:"sequence_number,omitempty"`
+	ConsensusTime  time.Time       `json:"consensus_time,omitempty"`
+	Producer       string          `json:"producer,omitempty"` // Producer of the envelope of the digest, empty for plain messages
+	Changed        []string        `json:"changed,omitempty"`  // Components that differ from the published digest
+}
+
+// Verified reports whether the registry state matches the last digest published
+func (v RegistryVerification) Verified() bool {
+	return v.Published != nil && v.Published.Digest == v.Local.Digest
+}
+
+// VerifyRegistryActivity digests the registry state and compares it with the last digest published on the
+// digest topic, given by ID or by its name in the topic registry. A registry that was changed outside the
+// pipeline no longer matches; so does one the pipeline changed since the last digest, until the next one.
+func (a *Activities) VerifyRegistryActivity(ctx context.Context, topic string) (RegistryVerification, error) {
+	var v RegistryVerification
+	topicID, err := entityid.ParseTopic(topic, a.network())
+	if err != nil {
+		info, found, lookupErr := a.lookupTopic(ctx, topic)
+		if lookupErr != nil {
+			return v, lookupErr
+		}
+		if !found {
+			return v, fmt.Errorf("digest topic %q is neither a topic ID nor in the topic registry", topic)
+		}
+		if topicID, err = entityid.ParseTopic(info.TopicID, a.network()); err != nil {
+			return v, fmt.Errorf("invalid digest topic: %w", err)
+		}
+	}
+	v.TopicID = topicID.String()
+
+	if v.Local, err = a.registryDigest(ctx); err != nil {
+		return v, err
+	}
+
+	// The last digest is the most recent message of the topic that is one, from a trusted producer
+	keys, err := a.producerKeys()
+	if err != nil {
+		return v, err
+	}
+	path := fmt.Sprintf("/topics/%s/messages?limit=100&order=desc", v.TopicID)
+	for path != "" && v.Published == nil {
+		var response MirrorNodeTopicMessagesResponse
+		if err := a.mirrorGet(ctx, path, &response); err != nil {
+			if errors.Is(err, errMirrorNotFound) {
+				return v, fmt.Errorf("digest topic %s not found", v.TopicID)
+			}
+			return v, err
+		}
+		for _, message := range response.Messages {
+			data, err := base64.StdEncoding.DecodeString(message.Message)
+			if err != nil {
+				continue
+			}
+			payload, producer, err := openAuditMessage(keys, data)
+			if err != nil {
+				fmt.Printf("Skipping message %d of digest topic %s: %v\n", message.SequenceNumber, a.displayID(v.TopicID), err)
+				continue
+			}
+			var digest RegistryDigest
+			if json.Unmarshal(payload, &digest) != nil || digest.Type != "registry_digest" || digest.Digest == "" {
+				continue
+			}
+			v.Published, v.Producer = &digest, producer
+			v.SequenceNumber = message.SequenceNumber
+			v.ConsensusTime = parseMirrorTimestamp(message.ConsensusTimestamp)
+			break
+		}
+		path = ""
+		if response.Links.Next != "" && v.Published == nil {
+			if path, err = a.mirrorNextPath(response.Links.Next); err != nil {
+				return v, fmt.Errorf("invalid pagination link: %w", err)
+			}
+		}
+	}
+	if v.Published == nil {
+		return v, nil
+	}
+
+	published := make(map[string]string)
+	for _, component := range v.Published.Components {
+		published[component.Name] = component.SHA256
+	}
+	for _, component := range v.Local.Components {
+		if published[component.Name] != component.SHA256 {
+			v.Changed = append(v.Changed, component.Name)
+		}
+		delete(published, component.Name)
+	}
+	for name := range published {
+		v.Changed = append(v.Changed, name) // Digested then, missing now
+	}
+	sort.Strings(v.Changed)
+	return v, nil
+}
@@ -0,0 +1,106 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/atrest"
+)
+
+// registryKey returns the key the registry files are encrypted with, or nil when they are written in clear.
+// A KMS data key is decrypted on first use, a failed decryption is attempted again by the next call.
+func (a *Activities) registryKey() (*atrest.Key, error) {
+	encryption := a.Config.Encryption
+	if !encryption.Enabled() {
+		return nil, nil
+	}
+	a.keyMu.Lock()
+	defer a.keyMu.Unlock()
+	if a.encryptionKey != nil {
+		return a.encryptionKey, nil
+	}
+	var key *atrest.Key
+	var err error
+	if encryption.Key != "" {
+		key, err = atrest.ParseKey(encryption.Key)
+	} else {
+		key, err = atrest.KMS{
+			Region:          encryption.KMSRegion,
+			AccessKeyID:     encryption.KMSAccessKeyID,
+			SecretAccessKey: encryption.KMSSecretAccessKey,
+			Endpoint:        encryption.KMSEndpoint,
+		}.DecryptKey(context.Background(), encryption.KMSDataKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the registry encryption key: %w", err)
+	}
+	a.encryptionKey = key
+	return key, nil
+}
+
+// readRegistryFile reads a registry file, decrypting it if it is encrypted. Files in clear are read whatever
+// the setting, so the files written before encryption was enabled are encrypted by their next save.
+func (a *Activities) readRegistryFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil || !atrest.Sealed(data) {
+		return data, err
+	}
+	key, err := a.registryKey()
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, fmt.Errorf("%s is encrypted, but neither REGISTRY_ENCRYPTION_KEY nor REGISTRY_KMS_DATA_KEY is set", path)
+	}
+	plaintext, err := key.Open(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", path, err)
+	}
+	return plaintext, nil
+}
+
+// sealRegistryFile encrypts the content of a registry file, unless the registry files are written in clear
+func (a *Activities) sealRegistryFile(data []byte) ([]byte, error) {
+	key, err := a.registryKey()
+	if err != nil || key == nil {
+		return data, err
+	}
+	return key.Seal(data)
+}
+
+// writeRegistryFile encrypts and writes a registry file, creating the directory of its network and tenant on
+// first use. The file is replaced in one rename: readers that do not take its lock never see it truncated, and a
+// worker crashing while it writes leaves the previous content.
+func (a *Activities) writeRegistryFile(path string, data []byte) error {
+	sealed, err := a.sealRegistryFile(data)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, sealed)
+}
+
+// writeFileAtomic replaces a file in one rename, so readers see its content before or after the write, and a
+// write that fails or is interrupted leaves it as it was
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	return err
+}
@@ -0,0 +1,39 @@
+package temporal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+)
+
+func TestWriteRegistryFile_Replaces(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "testnet")
+	path := filepath.Join(dir, "ingested_files.json")
+	a := &Activities{Config: &config.Config{}}
+
+	require.NoError(t, a.writeRegistryFile(path, []byte(`{"files":{"a":{},"b":{}}}`)))
+	before, err := os.Open(path)
+	require.NoError(t, err)
+	defer before.Close()
+
+	require.NoError(t, a.writeRegistryFile(path, []byte(`{"files":{}}`)))
+	data, err := a.readRegistryFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"files":{}}`, string(data))
+
+	// The file is replaced, not truncated under a reader that opened it before
+	old := make([]byte, 64)
+	n, err := before.Read(old)
+	require.NoError(t, err)
+	assert.Equal(t, `{"files":{"a":{},"b":{}}}`, string(old[:n]))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "no temporary file is left behind")
+	assert.Equal(t, "ingested_files.json", entries[0].Name())
+}
@@ -0,0 +1,246 @@
+package temporal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"go.temporal.io/sdk/temporal"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+)
+
+// ErrRegistryTampered is returned for registry files that do not match their signature
+var ErrRegistryTampered = errors.New("registry file does not match its signature")
+
+// ErrRegistryNetwork is returned for registry files recording the entities of another network than HEDERA_NETWORK
+var ErrRegistryNetwork = errors.New("registry file belongs to another network")
+
+// Application error types of refused registry files
+const (
+	ErrTypeRegistryTampered = "RegistryTampered" // The file does not match its signature
+	ErrTypeRegistryNetwork  = "RegistryNetwork"  // The file records the entities of another network
+)
+
+// registryLoadError returns the error of a registry that could not be loaded. Registries refused for their
+// signature or their network fail without retries, they stay refused until the file is restored, signed again
+// or HEDERA_NETWORK is corrected.
+func registryLoadError(what string, err error) error {
+	switch {
+	case errors.Is(err, ErrRegistryTampered):
+		return temporal.NewNonRetryableApplicationError(fmt.Sprintf("refusing the %s: %v", what, err), ErrTypeRegistryTampered, err)
+	case errors.Is(err, ErrRegistryNetwork):
+		return temporal.NewNonRetryableApplicationError(fmt.Sprintf("refusing the %s: %v", what, err), ErrTypeRegistryNetwork, err)
+	}
+	return fmt.Errorf("failed to load the %s: %w", what, err)
+}
+
+// RegistrySignature is the signature of a registry file by the operator key, stored next to it, see SignaturePath.
+// The signature is written before the file is replaced, so it keeps the signature of the content the file held
+// until then: readers, and a save interrupted in between, find the file matching one of the two.
+type RegistrySignature struct {
+	PublicKey string    `json:"public_key"` // DER encoded public key of the operator key that signed the file
+	SHA256    string    `json:"sha256"`     // Hex SHA-256 digest of the content of the file
+	Signature string    `json:"signature"`  // Hex signature of the content of the file
+	SignedAt  time.Time `json:"signed_at"`
+
+	Previous        *RegistrySignature `json:"previous,omitempty"`         // Signature of the content replaced
+	PreviousMissing bool               `json:"previous_missing,omitempty"` // The file did not exist before
+}
+
+// verifies reports whether the signature is a signature of data by the key
+func (s RegistrySignature) verifies(key hedera.PublicKey, data []byte) bool {
+	raw, err := hex.DecodeString(s.Signature)
+	return err == nil && s.PublicKey == key.StringDer() && key.Verify(data, raw)
+}
+
+// RegistryFileCheck is the outcome of checking a registry file against its signature
+type RegistryFileCheck struct {
+	Path     string    `json:"path"`
+	SignedAt time.Time `json:"signed_at,omitempty"`
+	Error    string    `json:"error,omitempty"` // Empty when the file matches its signature
+}
+
+// SignaturePath returns the path of the signature of a registry file
+func SignaturePath(path string) string {
+	return path + ".sig"
+}
+
+// registryFiles returns the registry files signed when REGISTRY_INTEGRITY is not off
+func (a *Activities) registryFiles() []string {
+	return []string{a.Config.Registry.ZoneFile, a.Config.Registry.TopicFile}
+}
+
+// checkRegistryNetwork refuses a registry file recording the entities of another network than HEDERA_NETWORK,
+// whose token, topic and account IDs must never be used on this one. Files recording no network predate the
+// scoping of the registry by network, they record this one from their next save.
+func (a *Activities) checkRegistryNetwork(path, network string) error {
+	if network != "" && network != a.network() {
+		return fmt.Errorf("%w: %s records the entities of %s, not of %s", ErrRegistryNetwork, path, network, a.network())
+	}
+	return nil
+}
+
+// signRegistryFile signs the content about to be written to a registry file, unless REGISTRY_INTEGRITY is off.
+// It is called with the lock of the file held, before the file is replaced: the signature of the content the
+// file holds, if it verifies, is kept as the previous one.
+func (a *Activities) signRegistryFile(path string, data []byte) error {
+	if a.Config.Registry.Integrity == config.IntegrityOff {
+		return nil
+	}
+	key, err := hedera.PrivateKeyFromString(a.Config.Hedera.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("invalid HEDERA_PRIVATE_KEY: %w", err)
+	}
+	digest := sha256.Sum256(data)
+	signature := RegistrySignature{
+		PublicKey: key.PublicKey().StringDer(),
+		SHA256:    hex.EncodeToString(digest[:]),
+		Signature: hex.EncodeToString(key.Sign(data)),
+		SignedAt:  time.Now().UTC(),
+	}
+	current, err := a.readRegistryFile(path)
+	switch {
+	case os.IsNotExist(err):
+		signature.PreviousMissing = true
+	case err == nil:
+		if previous, err := a.verifyRegistryFile(path, current); err == nil && previous.Signature != "" {
+			signature.Previous = &previous
+		}
+	}
+	encoded, err := json.MarshalIndent(signature, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal signature: %w", err)
+	}
+	if err := writeFileAtomic(SignaturePath(path), encoded); err != nil {
+		return fmt.Errorf("failed to write signature of %s: %w", path, err)
+	}
+	return nil
+}
+
+// checkRegistryFile checks the content read from a registry file against its signature, nil data standing for
+// a missing file. Unless REGISTRY_INTEGRITY is off, a file failing the check is refused with an error wrapping
+// ErrRegistryTampered in enforce mode, and reported but used in warn mode.
+func (a *Activities) checkRegistryFile(path string, data []byte) error {
+	if a.Config.Registry.Integrity == config.IntegrityOff {
+		return nil
+	}
+	_, err := a.verifyRegistryFile(path, data)
+	if err == nil {
+		return nil
+	}
+	if a.Config.Registry.Integrity == config.IntegrityEnforce {
+		return err
+	}
+	fmt.Printf("Warning: %v\n", err)
+	return nil
+}
+
+// verifyRegistryFile returns the signature of a registry file, or an error wrapping ErrRegistryTampered unless
+// the operator key signed the content of the file, as its latest or previous signature. A missing file only
+// verifies without signature, or with the signature of its first save.
+func (a *Activities) verifyRegistryFile(path string, data []byte) (RegistrySignature, error) {
+	var signature RegistrySignature
+	sigData, err := os.ReadFile(SignaturePath(path))
+	switch {
+	case os.IsNotExist(err) && data == nil:
+		return signature, nil
+	case os.IsNotExist(err):
+		return signature, fmt.Errorf("%w: %s is not signed", ErrRegistryTampered, path)
+	case err != nil:
+		return signature, fmt.Errorf("failed to read signature of %s: %w", path, err)
+	}
+	if err := json.Unmarshal(sigData, &signature); err != nil {
+		return signature, fmt.Errorf("%w: invalid signature of %s: %v", ErrRegistryTampered, path, err)
+	}
+	if data == nil {
+		if signature.PreviousMissing {
+			return RegistrySignature{}, nil // Signed by its first save, which has not replaced it yet
+		}
+		return signature, fmt.Errorf("%w: %s is signed but missing", ErrRegistryTampered, path)
+	}
+
+	key, err := hedera.PrivateKeyFromString(a.Config.Hedera.PrivateKey)
+	if err != nil {
+		return signature, fmt.Errorf("invalid HEDERA_PRIVATE_KEY: %w", err)
+	}
+	publicKey := key.PublicKey()
+	for _, candidate := range []*RegistrySignature{&signature, signature.Previous} {
+		if candidate != nil && candidate.verifies(publicKey, data) {
+			matched := *candidate
+			matched.Previous, matched.PreviousMissing = nil, false
+			return matched, nil
+		}
+	}
+	if signature.PublicKey != publicKey.StringDer() {
+		return signature, fmt.Errorf("%w: %s is signed by another key", ErrRegistryTampered, path)
+	}
+	return signature, fmt.Errorf("%w: %s was modified since it was signed at %s", ErrRegistryTampered, path, signature.SignedAt.Format(time.RFC3339))
+}
+
+// SignRegistryFilesActivity signs the registry files as they are, e.g. when REGISTRY_INTEGRITY is enabled or
+// after a change made by hand was reviewed. Missing files are skipped.
+func (a *Activities) SignRegistryFilesActivity(ctx context.Context) ([]string, error) {
+	if a.Config.Registry.Integrity == config.IntegrityOff {
+		return nil, errors.New("REGISTRY_INTEGRITY is off, the registry files are not signed")
+	}
+	var signed []string
+	for _, path := range a.registryFiles() {
+		ok, err := a.signRegistryFileAsIs(path)
+		if err != nil {
+			return signed, err
+		}
+		if ok {
+			fmt.Printf("Signed %s\n", path)
+			signed = append(signed, path)
+		}
+	}
+	return signed, nil
+}
+
+// signRegistryFileAsIs signs the content of a registry file under its lock, so a save of the workers is not
+// signed over with the content it replaced. It reports false for a missing file.
+func (a *Activities) signRegistryFileAsIs(path string) (bool, error) {
+	lock := a.lockTopicRegistry
+	if path == a.Config.Registry.ZoneFile {
+		lock = a.lockZoneRegistry
+	}
+	unlock, err := lock()
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	data, err := a.readRegistryFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, a.signRegistryFile(path, data)
+}
+
+// CheckRegistryFilesActivity checks every registry file against its signature, whatever REGISTRY_INTEGRITY is
+func (a *Activities) CheckRegistryFilesActivity(ctx context.Context) ([]RegistryFileCheck, error) {
+	var checks []RegistryFileCheck
+	for _, path := range a.registryFiles() {
+		data, err := a.readRegistryFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return checks, err
+		}
+		check := RegistryFileCheck{Path: path}
+		signature, err := a.verifyRegistryFile(path, data)
+		if err != nil {
+			check.Error = err.Error()
+		}
+		check.SignedAt = signature.SignedAt
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
@@ -0,0 +1,81 @@
+package temporal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+)
+
+func integrityActivities(t *testing.T) *Activities {
+	t.Helper()
+	key, err := hedera.PrivateKeyGenerateEd25519()
+	require.NoError(t, err)
+	dir := t.TempDir()
+	return &Activities{Config: &config.Config{
+		Hedera: config.HederaConfig{PrivateKey: key.String()},
+		Registry: config.RegistryConfig{
+			ZoneFile:  filepath.Join(dir, "zone_collections.json"),
+			TopicFile: filepath.Join(dir, "hcs_topics.json"),
+			Integrity: config.IntegrityEnforce,
+		},
+	}}
+}
+
+func TestRegistrySignature_InterruptedSave(t *testing.T) {
+	a := integrityActivities(t)
+	path := a.Config.Registry.ZoneFile
+
+	// The signature of the first save is written before the file exists
+	require.NoError(t, a.signRegistryFile(path, []byte(`{"v":1}`)))
+	_, err := a.verifyRegistryFile(path, nil)
+	require.NoError(t, err, "first save interrupted before the file was written")
+	require.NoError(t, os.WriteFile(path, []byte(`{"v":1}`), 0644))
+	_, err = a.verifyRegistryFile(path, []byte(`{"v":1}`))
+	require.NoError(t, err)
+
+	// A save interrupted after its signature keeps the file it replaced valid, twice in a row
+	require.NoError(t, a.signRegistryFile(path, []byte(`{"v":2}`)))
+	_, err = a.verifyRegistryFile(path, []byte(`{"v":1}`))
+	require.NoError(t, err, "old content with the new signature")
+	require.NoError(t, a.signRegistryFile(path, []byte(`{"v":3}`)))
+	_, err = a.verifyRegistryFile(path, []byte(`{"v":1}`))
+	require.NoError(t, err, "the previous signature is the one of the content on disk")
+	_, err = a.verifyRegistryFile(path, []byte(`{"v":3}`))
+	require.NoError(t, err)
+
+	// Neither signature covers other content, nor a missing file once it was written
+	_, err = a.verifyRegistryFile(path, []byte(`{"v":2}`))
+	assert.ErrorIs(t, err, ErrRegistryTampered)
+	_, err = a.verifyRegistryFile(path, nil)
+	assert.ErrorIs(t, err, ErrRegistryTampered)
+}
+
+func TestSaveZoneRegistry_Signed(t *testing.T) {
+	a := integrityActivities(t)
+	for i := range 2 {
+		registry := &ZoneRegistry{Collections: map[domain.Zone]ZoneCollectionInfo{
+			"build": {Zone: "build", TokenID: "0.0." + string(rune('1'+i))},
+		}}
+		require.NoError(t, a.saveZoneRegistry(registry))
+		loaded, err := a.loadZoneRegistry()
+		require.NoError(t, err)
+		assert.Equal(t, registry.Collections["build"].TokenID, loaded.Collections["build"].TokenID)
+	}
+
+	signed, err := a.SignRegistryFilesActivity(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{a.Config.Registry.ZoneFile}, signed, "the missing topic registry is skipped")
+	checks, err := a.CheckRegistryFilesActivity(context.Background())
+	require.NoError(t, err)
+	for _, check := range checks {
+		assert.Empty(t, check.Error, check.Path)
+	}
+}
@@ -0,0 +1,37 @@
+package temporal
+
+import (
+	"fmt"
+	"sync"
+)
+
+// lockRegistry serializes the read-modify-write cycles of a registry file: mu those of the activities of this
+// worker, and a lock on the file next to the registry (<file>.lock) those of the worker processes sharing it,
+// e.g. the main worker and the zone workers of worker --zones. Call the returned function to unlock it.
+func lockRegistry(mu *sync.Mutex, path string) (func(), error) {
+	mu.Lock()
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		mu.Unlock()
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	return func() {
+		unlock()
+		mu.Unlock()
+	}, nil
+}
+
+// lockZoneRegistry locks the zone registry, see lockRegistry
+func (a *Activities) lockZoneRegistry() (func(), error) {
+	return lockRegistry(&a.zonesMu, a.Config.Registry.ZoneFile)
+}
+
+// lockIngestLedger locks the ingest ledger, whose cursors the zone workers advance, see lockRegistry
+func (a *Activities) lockIngestLedger() (func(), error) {
+	return lockRegistry(&a.ledgerMu, a.Config.Registry.IngestLedgerFile)
+}
+
+// lockTopicRegistry locks the topic registry, whose topics the zone workers create on first use, see lockRegistry
+func (a *Activities) lockTopicRegistry() (func(), error) {
+	return lockRegistry(&a.topicsMu, a.Config.Registry.TopicFile)
+}
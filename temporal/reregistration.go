@@ -0,0 +1,38 @@
+package temporal
+
+import (
+	"strings"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/metadata"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/zonepolicy"
+)
+
+// Reregistration is what the reregistration policy of a zone does with the registration of a domain the
+// collection holds an NFT of
+type Reregistration struct {
+	Duplicate bool              // The registration is the one the live NFT was minted for, or the live NFT is reused
+	Burn      bool              // The live NFT of the earlier registration is burned before the new serial is minted
+	Previous  *metadata.Lineage // The earlier NFT the new serial is linked to, nil if not linked
+}
+
+// Reregister applies a reregistration policy to the registration of a domain whose latest NFT is given. A live NFT
+// minted for another event belongs to an earlier registration; one minted for the same event, or whose event is
+// not known, e.g. minted before event hashes were recorded or for a domain list import, is a duplicate. Burned
+// NFTs cannot be revived, so a domain whose NFT was burned always gets a new serial.
+func Reregister(mode string, latest LatestNFT, eventHash string) Reregistration {
+	lineage := &metadata.Lineage{TokenID: latest.TokenID, SerialNumber: latest.SerialNumber, EventHash: latest.EventHash}
+	if !latest.Burned {
+		sameEvent := latest.EventHash == "" || eventHash == "" || strings.HasPrefix(eventHash, latest.EventHash)
+		if sameEvent || mode == zonepolicy.ReregistrationReuse {
+			return Reregistration{Duplicate: true}
+		}
+		if mode == zonepolicy.ReregistrationLink {
+			return Reregistration{Burn: true, Previous: lineage}
+		}
+		return Reregistration{Burn: true}
+	}
+	if mode == zonepolicy.ReregistrationRemint {
+		return Reregistration{}
+	}
+	return Reregistration{Previous: lineage}
+}
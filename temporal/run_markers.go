@@ -0,0 +1,94 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+// RunMarkerVersion is the version of the run marker format
+const RunMarkerVersion = 1
+
+// Events of the lifecycle of an ingest run, published as run markers
+const (
+	RunMarkerRunStarted    = "run_started"
+	RunMarkerZoneStarted   = "zone_started"
+	RunMarkerZoneCompleted = "zone_completed"
+	RunMarkerRunCompleted  = "run_completed"
+)
+
+// RunMarker records a step of an ingest run on the receipts topic, so the receipts and anchors of the ledger can be
+// traced back to the run, and the file, that minted them. Counts are those of the zone for zone markers, and of
+// the whole run for run_completed.
+type RunMarker struct {
+	Version     int       `json:"v"`
+	Type        string    `json:"type"` // Always "ingest_run"
+	Event       string    `json:"event"`
+	WorkflowID  string    `json:"workflow_id"`
+	RunID       string    `json:"run_id"`
+	ContentHash string    `json:"content_hash,omitempty"`
+	At          time.Time `json:"at"` // Time of the step in the workflow
+	Zone        string    `json:"zone,omitempty"`
+	Zones       int       `json:"zones,omitempty"` // Zones of the run, for run_completed
+	Total       int       `json:"total,omitempty"` // Domains of the zone, or events of the file
+	Minted      int       `json:"minted,omitempty"`
+	Burned      int       `json:"burned,omitempty"`
+	Skipped     int       `json:"skipped,omitempty"`
+	Failed      int       `json:"failed,omitempty"`
+	FeesTinybar int64     `json:"fees_tinybar,omitempty"`
+	Outcome     string    `json:"outcome,omitempty"`     // Outcome of the run, for run_completed
+	ReportHash  string    `json:"report_hash,omitempty"` // Hex SHA-256 of the run report as written, for run_completed, see WrittenRunReport
+}
+
+// PublishRunMarkersActivity publishes run markers to the receipts topic in order, creating the topic on first use.
+// The markers are published as given: the report hash of run_completed markers is taken by the workflow from
+// the report it wrote, so any worker can publish them. They are enveloped like receipts with HCS_PRODUCER_KEY set.
+func (a *Activities) PublishRunMarkersActivity(ctx context.Context, topicName string, markers []RunMarker) (int, error) {
+	topic, err := a.LookupOrCreateTopicActivity(ctx, topicName, receiptsTopicMemo, true, true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up receipts topic: %w", err)
+	}
+	records := make([]any, len(markers))
+	for i, marker := range markers {
+		records[i] = marker
+	}
+	return a.publishAuditRecords(ctx, topic.TopicID, "run markers", records)
+}
+
+// newRunMarker returns a marker of the current step of an ingest run
+func newRunMarker(ctx workflow.Context, event, contentHash string) RunMarker {
+	info := workflow.GetInfo(ctx)
+	return RunMarker{
+		Version:     RunMarkerVersion,
+		Type:        "ingest_run",
+		Event:       event,
+		WorkflowID:  info.WorkflowExecution.ID,
+		RunID:       info.WorkflowExecution.RunID,
+		ContentHash: contentHash,
+		At:          workflow.Now(ctx).UTC(),
+	}
+}
+
+// zoneRunMarker returns a zone marker with the counts of the progress of the zone
+func zoneRunMarker(ctx workflow.Context, event, contentHash string, zone ZoneProgress) RunMarker {
+	marker := newRunMarker(ctx, event, contentHash)
+	marker.Zone, marker.Total = zone.Zone, zone.Total
+	if event == RunMarkerZoneCompleted {
+		marker.Minted, marker.Burned, marker.Skipped, marker.Failed = zone.Minted, zone.Burned, zone.Skipped, zone.Failed
+		marker.FeesTinybar = zone.FeesTinybar
+	}
+	return marker
+}
+
+// recordRunMarkers publishes run markers to the receipts topic, when HCS_RECEIPTS_TOPIC is set. Failures only warn, the
+// run goes on regardless.
+func recordRunMarkers(ctx workflow.Context, topicName string, markers ...RunMarker) {
+	if topicName == "" || len(markers) == 0 {
+		return
+	}
+	if err := workflow.ExecuteActivity(ctx, "PublishRunMarkersActivity", topicName, markers).Get(ctx, nil); err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to record run markers", "event", markers[0].Event, "count", len(markers), "error", err)
+	}
+}
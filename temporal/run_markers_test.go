@@ -0,0 +1,71 @@
+package temporal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+)
+
+func TestWriteRunReportActivity_Hash(t *testing.T) {
+	a := &Activities{Config: &config.Config{Reports: config.ReportsConfig{Dir: t.TempDir()}}}
+	written, err := a.WriteRunReportActivity(context.Background(), RunReport{WorkflowID: "wf", RunID: "run", Outcome: "completed"})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(written.Path)
+	require.NoError(t, err)
+	sum := sha256.Sum256(data)
+	assert.Equal(t, hex.EncodeToString(sum[:]), written.Hash)
+	assert.Equal(t, RunReportPath(a.Config.Reports.Dir, "wf", "run"), written.Path)
+}
+
+func TestRecordRunMarkers(t *testing.T) {
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	published := make(map[string][]RunMarker)
+	env.RegisterActivityWithOptions(func(_ context.Context, topicName string, markers []RunMarker) (int, error) {
+		published[topicName] = append(published[topicName], markers...)
+		return len(markers), nil
+	}, activity.RegisterOptions{Name: "PublishRunMarkersActivity"})
+
+	env.ExecuteWorkflow(func(ctx workflow.Context) error {
+		ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{StartToCloseTimeout: time.Minute})
+		zone := ZoneProgress{Zone: "build", Total: 3, Minted: 2, Failed: 1, FeesTinybar: 100}
+		recordRunMarkers(ctx, "receipts",
+			zoneRunMarker(ctx, RunMarkerZoneStarted, "c0ffee", zone),
+			zoneRunMarker(ctx, RunMarkerZoneCompleted, "c0ffee", zone))
+		completed := newRunMarker(ctx, RunMarkerRunCompleted, "c0ffee")
+		completed.ReportHash = "abc123"
+		recordRunMarkers(ctx, "receipts", completed)
+		recordRunMarkers(ctx, "", completed) // Receipts disabled
+		return nil
+	})
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+
+	require.Len(t, published, 1)
+	markers := published["receipts"]
+	require.Len(t, markers, 3)
+	assert.Equal(t, RunMarkerZoneStarted, markers[0].Event)
+	assert.Equal(t, 3, markers[0].Total)
+	assert.Zero(t, markers[0].Minted, "zone_started carries no outcome")
+	assert.Equal(t, RunMarkerZoneCompleted, markers[1].Event)
+	assert.Equal(t, []int{2, 1}, []int{markers[1].Minted, markers[1].Failed})
+	assert.Equal(t, int64(100), markers[1].FeesTinybar)
+	assert.Equal(t, RunMarkerRunCompleted, markers[2].Event)
+	assert.Equal(t, "abc123", markers[2].ReportHash, "the hash of the workflow is published as is")
+	for _, marker := range markers {
+		assert.Equal(t, "c0ffee", marker.ContentHash)
+		assert.Equal(t, "ingest_run", marker.Type)
+	}
+}
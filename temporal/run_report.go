@@ -0,0 +1,140 @@
+package temporal
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/archive"
+)
+
+// ErrTypeInvalidReportBucket is the application error type of a REPORT_BUCKET that is not a bucket location
+const ErrTypeInvalidReportBucket = "InvalidReportBucket"
+
+// RunReportPath returns the path of the report of an ingest run in the given directory
+func RunReportPath(dir, workflowID, runID string) string {
+	return filepath.Join(dir, fmt.Sprintf("%s_%s.json", workflowID, runID))
+}
+
+// WrittenRunReport is a run report as written by WriteRunReportActivity
+type WrittenRunReport struct {
+	Path string
+	Hash string // Hex SHA-256 of the report as written, the report_hash of its run_completed marker
+}
+
+// WriteRunReportActivity writes the report of an ingest run to the report directory and returns its path and hash
+func (a *Activities) WriteRunReportActivity(ctx context.Context, report RunReport) (WrittenRunReport, error) {
+	if err := os.MkdirAll(a.Config.Reports.Dir, 0755); err != nil {
+		return WrittenRunReport{}, fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	// The fees in US dollars are informative, a report is written without them if the rate is unavailable
+	if report.FeesTinybar > 0 && report.FeesUSD == 0 {
+		if rate, err := a.ExchangeRateActivity(ctx); err != nil {
+			fmt.Printf("Warning: run report without fees in USD: %v\n", err)
+		} else {
+			report.FeesUSD = rate.USD(report.FeesTinybar)
+		}
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return WrittenRunReport{}, fmt.Errorf("failed to marshal run report: %w", err)
+	}
+
+	path := RunReportPath(a.Config.Reports.Dir, report.WorkflowID, report.RunID)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return WrittenRunReport{}, fmt.Errorf("failed to write run report: %w", err)
+	}
+	fmt.Printf("Wrote %s run report to %s\n", report.Outcome, path)
+	a.storeRunReport(ctx, report)
+	sum := sha256.Sum256(data)
+	return WrittenRunReport{Path: path, Hash: hex.EncodeToString(sum[:])}, nil
+}
+
+// RunReportArchiveKey returns the object key a run report is archived under, without extension:
+// <network>/<zone>/<date>/<run ID>, dated by the start of the run in UTC
+func RunReportArchiveKey(network, zone string, startedAt time.Time, runID string) string {
+	return path.Join(network, zone, startedAt.UTC().Format(time.DateOnly), runID)
+}
+
+// unzonedRunReport is the zone of the archive keys of reports of runs that did not get to any zone
+const unzonedRunReport = "none"
+
+// ArchiveRunReportActivity uploads the report of an ingest run, as written to the report directory, to
+// REPORT_BUCKET, as JSON and as CSV with a row per zone. The report is uploaded under the key of every zone of the
+// run, see RunReportArchiveKey, so the reports of a zone are found under one prefix; uploading again replaces
+// them. Nothing is uploaded without REPORT_BUCKET. Returns the keys of the uploaded objects.
+func (a *Activities) ArchiveRunReportActivity(ctx context.Context, workflowID, runID string) ([]string, error) {
+	if a.Config.Reports.Bucket == "" {
+		return nil, nil
+	}
+	bucket, err := archive.NewBucket(a.Config.Reports.Bucket, a.s3Options())
+	if err != nil {
+		return nil, temporal.NewNonRetryableApplicationError("invalid REPORT_BUCKET", ErrTypeInvalidReportBucket, err)
+	}
+
+	data, err := os.ReadFile(RunReportPath(a.Config.Reports.Dir, workflowID, runID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run report: %w", err)
+	}
+	var report RunReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse run report: %w", err)
+	}
+	csvData, err := runReportCSV(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write run report CSV: %w", err)
+	}
+
+	zones := []string{unzonedRunReport}
+	if len(report.Zones) > 0 {
+		zones = zones[:0]
+		for _, zone := range report.Zones {
+			zones = append(zones, zone.Zone)
+		}
+	}
+	var keys []string
+	for _, zone := range zones {
+		key := RunReportArchiveKey(a.network(), zone, report.StartedAt, runID)
+		if err := bucket.Put(ctx, key+".json", data, "application/json"); err != nil {
+			return keys, fmt.Errorf("failed to archive run report: %w", err)
+		}
+		if err := bucket.Put(ctx, key+".csv", csvData, "text/csv"); err != nil {
+			return keys, fmt.Errorf("failed to archive run report: %w", err)
+		}
+		keys = append(keys, key+".json", key+".csv")
+	}
+	fmt.Printf("Archived run report of %s to %s under %d zones\n", workflowID, bucket, len(zones))
+	return keys, nil
+}
+
+// runReportCSV returns the per-zone counts of a run report as CSV, a row per zone with the fields of the run
+func runReportCSV(report RunReport) ([]byte, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Write([]string{"workflow_id", "run_id", "file_path", "outcome", "partial", "started_at", "finished_at", "zone",
+		"total", "minted", "burned", "skipped", "ignored", "duplicates", "failed", "not_visible", "fees_tinybar"})
+	for _, zone := range report.Zones {
+		w.Write([]string{
+			report.WorkflowID, report.RunID, report.FilePath, report.Outcome, strconv.FormatBool(report.Partial),
+			report.StartedAt.UTC().Format(time.RFC3339), report.FinishedAt.UTC().Format(time.RFC3339), zone.Zone,
+			strconv.Itoa(zone.Total), strconv.Itoa(zone.Minted), strconv.Itoa(zone.Burned), strconv.Itoa(zone.Skipped),
+			strconv.Itoa(zone.Ignored), strconv.Itoa(zone.Duplicates), strconv.Itoa(zone.Failed),
+			strconv.Itoa(zone.NotVisible), strconv.FormatInt(zone.FeesTinybar, 10),
+		})
+	}
+	w.Flush()
+	return b.Bytes(), w.Error()
+}
@@ -0,0 +1,111 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+)
+
+// shardSize returns the serials a shard of a zone collection holds: ZONE_SHARD_SIZE, or the size recorded in
+// the zone registry when the zone was sharded under an earlier setting. Zero when the zone is not sharded.
+func (a *Activities) shardSize(collection ZoneCollectionInfo) int {
+	if a.Config.Zones.ShardSize > 0 {
+		return a.Config.Zones.ShardSize
+	}
+	return collection.ShardSize
+}
+
+// latestSerial returns the highest serial minted in a collection, burned or not, 0 when nothing was minted
+func (a *Activities) latestSerial(ctx context.Context, tokenID string) (int64, error) {
+	var response MirrorNodeNFTsResponse
+	err := a.mirrorGet(ctx, fmt.Sprintf("/tokens/%s/nfts?order=desc&limit=1", tokenID), &response)
+	if errors.Is(err, errMirrorNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(response.NFTs) == 0 {
+		return 0, nil
+	}
+	return response.NFTs[0].SerialNumber, nil
+}
+
+// shardZoneCollection returns the collection a zone mints into: its registered collection, or a new shard once
+// the registered one holds the shard size of the zone. The new shard is recorded in the zone registry with the
+// earlier shards, which lookups keep searching. Shards are checked when a zone batch starts, so a shard may
+// exceed its size by the mints of a batch. The caller holds the lock of the zone registry, which is loaded again
+// once the shard is created, so the entries recorded meanwhile are kept.
+func (a *Activities) shardZoneCollection(ctx context.Context, collection ZoneCollectionInfo) (ZoneCollectionInfo, error) {
+	size := a.shardSize(collection)
+	if size == 0 || collection.Pending != nil {
+		return collection, nil
+	}
+	serial, err := a.latestSerial(ctx, collection.TokenID)
+	if err != nil {
+		fmt.Printf("Warning: could not count the serials of %s, minting into it: %v\n", a.displayID(collection.TokenID), err)
+		return collection, nil
+	}
+	if serial < int64(size) {
+		return collection, nil
+	}
+
+	shard := max(collection.Shard, 1) + 1
+	fmt.Printf("Collection %s of .%s holds %d serials, creating shard %d\n", a.displayID(collection.TokenID), collection.Zone, serial, shard)
+	next, err := a.createNFTCollection(ctx, collection.Zone.String(), ZoneCollectionInfo{
+		Shard:     shard,
+		ShardSize: size,
+		Shards:    append(slices.Clone(collection.Shards), collection.TokenID),
+	})
+	if err != nil {
+		return ZoneCollectionInfo{}, err
+	}
+	if next.Pending != nil {
+		return next, nil // Registered by SubmitSignedTransactionActivity once created
+	}
+	next.MigratedFrom = collection.MigratedFrom
+	registry, err := a.loadZoneRegistry()
+	if err != nil {
+		return ZoneCollectionInfo{}, a.collectionNotRecordedError(next, fmt.Errorf("shard %d: %w", shard, err))
+	}
+	registry.Collections[collection.Zone] = next
+	registry.LastUpdated = time.Now()
+	if err := a.saveZoneRegistry(registry); err != nil {
+		return ZoneCollectionInfo{}, a.collectionNotRecordedError(next, fmt.Errorf("shard %d: %w", shard, err))
+	}
+	return next, nil
+}
+
+// zoneTokenIDs returns the token IDs of every shard of the collection of a zone, newest first: from the zone
+// registry, or the collection found on the mirror node by its token name
+func (a *Activities) zoneTokenIDs(ctx context.Context, zone string) ([]string, error) {
+	if registry, err := a.loadZoneRegistry(); err == nil {
+		if collection, ok := registry.Collections[domain.Zone(zone)]; ok && collection.TokenID != "" {
+			return collection.TokenIDs(), nil
+		}
+	}
+	tokenID, err := a.resolveZoneCollection(ctx, zone, "")
+	if err != nil {
+		return nil, err
+	}
+	return []string{tokenID}, nil
+}
+
+// searchForDomainInShards searches the shards of a zone collection for the NFT of a label, newest shard first,
+// so the NFT found is the latest of the label
+func (a *Activities) searchForDomainInShards(ctx context.Context, tokenIDs []string, label string) (MirrorNodeNFT, bool, error) {
+	for _, tokenID := range tokenIDs {
+		nft, found, err := a.searchForDomainInCollection(ctx, tokenID, label)
+		if err != nil || found {
+			if found && nft.TokenID == "" {
+				nft.TokenID = tokenID
+			}
+			return nft, found, err
+		}
+	}
+	return MirrorNodeNFT{}, false, nil
+}
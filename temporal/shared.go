@@ -1,54 +1,250 @@
 package temporal
 
-import "time"
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
 
-const IngestTaskQueue = "DOMAIN_INGEST_TASK_QUEUE"
+	"go.temporal.io/sdk/temporal"
 
-// EventData matches the structure of the JSON object inside the log file.
-// We use json tags to map the JSON keys to our struct fields.
-type EventData struct {
-	Initiator   string `json:"i"`
-	RegistrarID string `json:"r"`
-	Type        string `json:"t"`
-	DomainName  string `json:"o"`
-	Event       string `json:"e"`
-	Timestamp   string `json:"s"` // Keep as string for now, parse later
-	Zone        string `json:"z"`
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/metadata"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/redact"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/zonepolicy"
+)
+
+// ErrTypeWorkerShutdown is the application error type returned when an activity is not started because its worker is draining
+const ErrTypeWorkerShutdown = "WorkerShutdown"
+
+// ErrTypeMintCapExceeded is the application error type of runs stopped before minting more domains than MAX_MINTS_PER_RUN
+const ErrTypeMintCapExceeded = "MintCapExceeded"
+
+// IngestRequest is the input of IngestFileWorkflow
+type IngestRequest struct {
+	FilePath       string             // The registry event log to ingest
+	ContentHash    string             // SHA-256 of the file content, also the basis of the workflow ID
+	ZoneTaskQueues map[string]string  // zone -> task queue for sharded zones, other zones use the parent's queue
+	ResumeFrom     map[string]int     // zone -> last processed line of a previous run, events up to it are skipped
+	HCS            config.HCSConfig   // HCS topics the mints are published to
+	Zones          config.ZonesConfig // Zones ingested, the domains of other zones are refused before their collection is created
+	MaxMints       int                // Domains the run may mint at most, unlimited if zero (MAX_MINTS_PER_RUN)
+	BudgetTinybar  int64              // Fees the run may spend before it pauses, unlimited if zero (RUN_BUDGET_HBAR)
+	BudgetUSD      float64            // Budget in US dollars, converted at the exchange rate of the network when BudgetTinybar is zero (RUN_BUDGET_USD)
+	Notify         bool               // Announce the start and end of the run on the webhooks of NOTIFY_WEBHOOKS
+	FailureAlert   int                // Failed domains of the run that raise an alert on the webhooks, none if zero (NOTIFY_FAILURE_THRESHOLD)
+	EmailReport    bool               // Email the report of the run to REPORT_EMAIL_TO
+	ArchiveReport  bool               // Upload the report of the run to REPORT_BUCKET
+	EscalateAfter  int                // Consecutive failures of a zone that page the operators and pause it, never if zero (ESCALATION_THRESHOLD)
+
+	// How long minted NFTs may take to show on the mirror node, not checked if zero (MIRROR_VISIBILITY_TIMEOUT)
+	VisibilityTimeout time.Duration
+}
+
+// ZoneBatch is the input of ProcessZoneWorkflow: all domains of one zone from an ingest run
+type ZoneBatch struct {
+	Zone            string
+	Domains         []MintingInfo
+	ContentHash     string // Identifies the ingest run whose cursor is advanced, empty disables checkpointing
+	ResumeAfterLine int    // Domains on or before this line were processed by a previous run
+	ReceiptsTopic   string // Topic registry name of the HCS topic receiving mint receipts, empty disables receipts
+	AnchorTopic     string // Topic registry name of the HCS topic the Merkle root of the batch is anchored to, empty disables anchoring
+	ReportFees      bool   // Signal the fee of every mint and burn to the parent, which pauses the zone when its budget is spent
+	EscalateAfter   int    // Consecutive failures that open an incident and pause the zone until ResumeZoneSignal, never if zero
+
+	// How long minted NFTs may take to show on the mirror node, see VerifyMintVisibleActivity; not checked if zero
+	VisibilityTimeout time.Duration
+
+	Continued *ZoneContinuation // Set by a ProcessZoneWorkflow continuing as new, nil for its first run
+}
+
+// zoneDomainsPerRun bounds the history of one ProcessZoneWorkflow run, after which it continues as new
+const zoneDomainsPerRun = 1000
+
+// cursorCheckpointLines is the number of domains a ProcessZoneWorkflow processes between two checkpoints of its line
+const cursorCheckpointLines = 100
+
+// ZoneContinuation is the state a ProcessZoneWorkflow continues as new with
+type ZoneContinuation struct {
+	Next                int                  // Index in Domains of the next domain to process
+	Progress            ZoneProgress         // Progress of the earlier runs
+	Latest              map[string]LatestNFT // NFTs the earlier runs minted or burned, of the domains left
+	ConsecutiveFailures int
+	LastTransaction     time.Time
+}
+
+// zoneBatchTopics sets the HCS topics of a zone batch: anchored zones anchor a Merkle root of the batch
+// instead of publishing a receipt of every mint
+func zoneBatchTopics(batch *ZoneBatch, hcs config.HCSConfig) {
+	if hcs.Anchored(batch.Zone) {
+		batch.AnchorTopic = hcs.AnchorTopic
+	} else {
+		batch.ReceiptsTopic = hcs.ReceiptsTopic
+	}
 }
 
-// RegistryEvent is the top-level object in each log line.
-type RegistryEvent struct {
-	Event EventData `json:"registry-event"`
+// suppressDuplicates drops the events repeated in a run, before any of them reaches Hedera: mirror nodes lag
+// behind the mints, so repeats would otherwise be minted twice. An event of a domain (zone and label) repeats
+// the run when an earlier event of the domain has the same event hash, or when the last event kept for the
+// domain has the same action, e.g. a registration sent again with another timestamp, hence another hash.
+// Events of another action are kept, so a domain deleted and registered again in the run is burned and minted
+// as the policy of its zone says. Events without action, e.g. domain list imports, are registrations. The
+// first of the repeated events is kept.
+func suppressDuplicates(infos []MintingInfo) (kept []MintingInfo, suppressed int) {
+	seen := make(map[string]bool, len(infos))
+	lastAction := make(map[string]string, len(infos))
+	kept = make([]MintingInfo, 0, len(infos))
+	for _, info := range infos {
+		name := domain.DomainName(strings.ToLower(info.DomainName))
+		key := info.Zone.String() + "/" + name.Label()
+		action := strings.ToLower(strings.TrimSpace(info.Action))
+		if action == "" {
+			action = zonepolicy.EventCreate
+		}
+		last, known := lastAction[key]
+		if known && last == action || info.EventHash != "" && seen[key+"#"+info.EventHash] {
+			suppressed++
+			continue
+		}
+		seen[key+"#"+info.EventHash] = true
+		lastAction[key] = action
+		kept = append(kept, info)
+	}
+	return kept, suppressed
+}
+
+// groupByZone groups domains by zone and returns the zones in order, as map iteration order is random and
+// replays must schedule the children of the zones identically. The domains of zones the zone config refuses
+// are left out, their number is returned by zone.
+func groupByZone(infos []MintingInfo, allowed config.ZonesConfig) (groups map[string][]MintingInfo, zones []string, refused map[string]int) {
+	groups = make(map[string][]MintingInfo)
+	for _, info := range infos {
+		zone := info.Zone.String()
+		if !allowed.Allowed(zone) {
+			if refused == nil {
+				refused = make(map[string]int)
+			}
+			refused[zone]++
+			continue
+		}
+		groups[zone] = append(groups[zone], info)
+	}
+	zones = make([]string, 0, len(groups))
+	for zone := range groups {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+	return groups, zones, refused
+}
+
+// checkMintCap returns a non-retryable error when a run would mint more domains than its cap, so the wrong
+// (huge) file is not ingested against mainnet by accident. pending counts every domain left to process, it
+// is an upper bound of the mints as domains that are already minted are skipped.
+func checkMintCap(pending, maxMints int) error {
+	if maxMints <= 0 || pending <= maxMints {
+		return nil
+	}
+	return temporal.NewNonRetryableApplicationError(
+		fmt.Sprintf("run would mint up to %d domains, more than MAX_MINTS_PER_RUN (%d): raise the cap to ingest them", pending, maxMints),
+		ErrTypeMintCapExceeded, nil)
+}
+
+// ZoneTaskQueue returns the task queue serving a sharded zone
+func ZoneTaskQueue(baseQueue, zone string) string {
+	return baseQueue + "-zone-" + zone
+}
+
+// ZoneTaskQueues maps each sharded zone to its task queue
+func ZoneTaskQueues(baseQueue string, shardedZones []string) map[string]string {
+	queues := make(map[string]string, len(shardedZones))
+	for _, zone := range shardedZones {
+		queues[zone] = ZoneTaskQueue(baseQueue, zone)
+	}
+	return queues
 }
 
 // MintingInfo contains all the necessary data for the minting activity.
 type MintingInfo struct {
-	DomainName       string
-	RegistrationTime time.Time
-	RegistrarID      string
-	Zone             string // The zone this domain belongs to (e.g., "build", "com", etc.)
-	FullEventJSON    string // Store the original event for metadata
+	DomainName            string
+	RegistrationTime      time.Time
+	RegistrarID           string
+	Zone                  domain.Zone        // The zone this domain belongs to (e.g., "build", "com", etc.)
+	FullEventJSON         string             // Store the original event for metadata
+	LineNumber            int                // 1-based line of the event in the ingested file, used as the resume cursor
+	SignedBy              string             // Key ID of the registry key that signed the event, empty if unsigned or not verified
+	EventHash             string             // Hex canonical hash of the registry-event object (see pkg/eventhash), empty without event
+	RunID                 string             // Run ID of the ingest run that read the event, traced in the memo of its transactions
+	Nameservers           []string           // Delegation of the domain, recorded when NAMESERVER_CAPTURE is enabled
+	RegistrantFingerprint string             // Keyed hash of the registrant handle (see pkg/fingerprint), empty without key or registrant
+	Redacted              []redact.Redaction // Fields withheld by REDACTION_POLICY, set when the registration is minted
+	Action                string             // Action of the event, e.g. create or delete, empty for domain list imports
+	MetadataStore         string             // Replaces METADATA_STORE, set from the policy of the zone
+	Typosquat             *TyposquatMatch    // Brand of TYPOSQUAT_WATCHLIST the registration resembles, nil if none
+	Generated             *GeneratedLabel    // Set when the label looks algorithmically generated, see DGA_THRESHOLD
+	Prechecked            bool               // The zone workflow found the domain not minted, see LatestNFTsActivity
+	Previous              *metadata.Lineage  // NFT of the earlier registration of the domain, set by the reregistration policy
+}
+
+// MintResult is the outcome of a successful MintNFTActivity
+type MintResult struct {
+	Domain        string             `json:"domain"`
+	TokenID       string             `json:"token_id"`
+	SerialNumber  int64              `json:"serial_number"`
+	TransactionID string             `json:"transaction_id,omitempty"` // Empty for duplicates
+	Duplicate     bool               `json:"duplicate"`                // The domain was already minted, nothing was submitted
+	ConsensusAt   time.Time          `json:"consensus_at,omitempty"`   // Consensus time of the mint, zero for duplicates
+	MetadataURI   string             `json:"metadata_uri,omitempty"`   // URI of the uploaded metadata document, empty without METADATA_STORE
+	Nameservers   []string           `json:"nameservers,omitempty"`    // Delegation of the domain when it was minted, if captured
+	Registrant    string             `json:"registrant_fp,omitempty"`  // Fingerprint of the registrant, never the registrant itself
+	Redacted      []redact.Redaction `json:"redacted,omitempty"`       // Fields withheld from the published records by REDACTION_POLICY
+	FeeTinybar    int64              `json:"fee_tinybar,omitempty"`    // Fee charged for the mint, zero for duplicates
+	Previous      *metadata.Lineage  `json:"previous,omitempty"`       // NFT of the earlier registration the mint is linked to
+
+	// The mint exported for signing offline, submitted by SubmitSignedTransactionActivity once signed
+	Pending *PendingTransaction `json:"pending,omitempty"`
 }
 
 // ZoneCollectionInfo holds information about an NFT collection for a specific zone
 type ZoneCollectionInfo struct {
-	Zone        string    `json:"zone"`         // The zone name (e.g., "build", "com")
-	TokenID     string    `json:"token_id"`     // Hedera token ID for this zone's collection
-	TokenName   string    `json:"token_name"`   // Human readable token name
-	TokenSymbol string    `json:"token_symbol"` // Token symbol
-	CreatedAt   time.Time `json:"created_at"`   // When this collection was created
-	CreatedBy   string    `json:"created_by"`   // Account ID that created this collection
+	Zone        domain.Zone `json:"zone"`                   // The zone name (e.g., "build", "com")
+	TokenID     string      `json:"token_id"`               // Hedera token ID for this zone's collection
+	TokenName   string      `json:"token_name"`             // Human readable token name
+	TokenSymbol string      `json:"token_symbol"`           // Token symbol
+	CreatedAt   time.Time   `json:"created_at"`             // When this collection was created
+	CreatedBy   string      `json:"created_by"`             // Account ID that created this collection
+	MetadataURI string      `json:"metadata_uri,omitempty"` // Collection document of the branding, stored in the token metadata
+
+	// The creation exported for signing offline, the collection has no token ID until it is signed and submitted
+	Pending *PendingTransaction `json:"pending,omitempty"`
+
+	// Collections of the zone before it was migrated to this one, oldest first
+	MigratedFrom []string `json:"migrated_from,omitempty"`
+
+	// Zones too large for one collection are sharded: they mint into this collection, the last shard, until it
+	// holds ShardSize serials, and their domains are looked up in every shard from the newest
+	Shard     int      `json:"shard,omitempty"`      // Number of this collection among the shards of the zone, from 1
+	ShardSize int      `json:"shard_size,omitempty"` // Serials of a shard after which the next one is created
+	Shards    []string `json:"shards,omitempty"`     // Token IDs of the earlier shards, oldest first
+}
+
+// TokenIDs returns the token IDs of every shard of a zone collection, newest first, the collection itself when
+// the zone is not sharded
+func (c ZoneCollectionInfo) TokenIDs() []string {
+	tokenIDs := []string{c.TokenID}
+	for i := len(c.Shards) - 1; i >= 0; i-- {
+		tokenIDs = append(tokenIDs, c.Shards[i])
+	}
+	return tokenIDs
 }
 
 // ZoneRegistry tracks all zone collections to avoid duplicates
 type ZoneRegistry struct {
-	Collections map[string]ZoneCollectionInfo `json:"collections"` // zone -> collection info
-	LastUpdated time.Time                     `json:"last_updated"`
+	Collections map[domain.Zone]ZoneCollectionInfo `json:"collections"` // zone -> collection info, zones read from the file are normalized
+	LastUpdated time.Time                          `json:"last_updated"`
+	Network     string                             `json:"network,omitempty"` // Hedera network of the collections, see checkRegistryNetwork
 }
 
-// ZoneRegistryFile is the file where we persist the zone registry
-const ZoneRegistryFile = "zone_collections.json"
-
 // HCS-related structures
 
 // TopicInfo holds information about an HCS topic
@@ -60,6 +256,8 @@ type TopicInfo struct {
 	CreatedBy   string    `json:"created_by"`  // Account ID that created this topic
 	AdminKey    string    `json:"admin_key"`   // Admin key for topic management (optional)
 	SubmitKey   string    `json:"submit_key"`  // Submit key for message submission (optional)
+	// Sequence number of the record of the topic on the registry topic, zero if it is not published
+	RegistrySequence uint64 `json:"registry_sequence,omitempty"`
 }
 
 // TopicMessage represents a message sent to an HCS topic
@@ -78,13 +276,166 @@ type TopicSubscriptionInfo struct {
 	StartTime time.Time `json:"start_time"` // When to start reading from (optional)
 	EndTime   time.Time `json:"end_time"`   // When to stop reading (optional)
 	Limit     int       `json:"limit"`      // Max number of messages to read (optional)
+	// How long to wait for messages when there is no end time (optional, defaults to a minute)
+	Wait time.Duration `json:"wait"`
+	// Consumer group whose committed offset the subscription resumes after (optional). Without an offset,
+	// the group reads from StartTime, or from the first message of the topic.
+	ConsumerGroup string `json:"consumer_group,omitempty"`
+}
+
+// TopicReadRequest selects a closed range of topic messages: a consensus time window, a sequence range, or both
+type TopicReadRequest struct {
+	TopicID      string    `json:"topic_id"`      // Topic to read
+	StartTime    time.Time `json:"start_time"`    // First consensus time included (optional)
+	EndTime      time.Time `json:"end_time"`      // Last consensus time included (required without ToSequence)
+	FromSequence uint64    `json:"from_sequence"` // First sequence number included (optional)
+	ToSequence   uint64    `json:"to_sequence"`   // Last sequence number included (required without EndTime)
 }
 
 // TopicRegistry tracks HCS topics to avoid duplicates and enable reuse
 type TopicRegistry struct {
 	Topics      map[string]TopicInfo `json:"topics"` // topic name -> topic info
 	LastUpdated time.Time            `json:"last_updated"`
+	Network     string               `json:"network,omitempty"` // Hedera network of the topics, see checkRegistryNetwork
+	// Registry topic the file caches, and the sequence number of its last record applied
+	RegistryTopic    string `json:"registry_topic,omitempty"`
+	RegistrySequence uint64 `json:"registry_sequence,omitempty"`
+}
+
+// Ingest ledger structures
+
+// Outcomes of an ingest run as recorded in the ingest ledger
+const (
+	IngestOutcomeRunning   = "running"
+	IngestOutcomeCompleted = "completed"
+	IngestOutcomeFailed    = "failed"
+	IngestOutcomeCanceled  = "canceled"
+)
+
+// IngestedFileInfo records the latest ingest run of a file's content
+type IngestedFileInfo struct {
+	ContentHash string         `json:"content_hash"`     // SHA-256 of the file content
+	FilePath    string         `json:"file_path"`        // Path the content was ingested from
+	Size        int64          `json:"size"`             // File size in bytes
+	WorkflowID  string         `json:"workflow_id"`      // Ingest workflow ID
+	RunID       string         `json:"run_id"`           // Ingest workflow run ID
+	Outcome     string         `json:"outcome"`          // running, completed or failed
+	StartedAt   time.Time      `json:"started_at"`       // When the run started
+	FinishedAt  time.Time      `json:"finished_at"`      // When the run finished (zero while running)
+	Cursor      map[string]int `json:"cursor,omitempty"` // zone -> last processed line, used to resume the run
 }
 
-// TopicRegistryFile is the file where we persist the topic registry
-const TopicRegistryFile = "hcs_topics.json"
+// IngestLedger tracks every ingested file to prevent re-ingestion
+type IngestLedger struct {
+	Files       map[string]IngestedFileInfo `json:"files"` // content hash -> latest ingest run
+	LastUpdated time.Time                   `json:"last_updated"`
+}
+
+// Progress structures, returned by the ProgressQuery of the ingest workflows
+
+// ProgressQuery is the query type answered by IngestFileWorkflow and ProcessZoneWorkflow
+const ProgressQuery = "progress"
+
+// Stages of an ingest run as reported by its progress
+const (
+	StageReading   = "reading"
+	StageParsing   = "parsing"
+	StageMinting   = "minting"
+	StagePaused    = "paused" // The budget of the run is spent, see BudgetSignal
+	StageCompleted = "completed"
+	StageFailed    = "failed"
+	StageCanceled  = "canceled"
+)
+
+// maxRecentFailures bounds the failures kept in progress snapshots
+const maxRecentFailures = 5
+
+// MintFailure describes a domain that could not be minted
+type MintFailure struct {
+	Domain string    `json:"domain"`
+	Zone   string    `json:"zone"`
+	Error  string    `json:"error"`
+	Time   time.Time `json:"time"`
+}
+
+// ZoneProgress reports the progress of one zone of an ingest run
+type ZoneProgress struct {
+	Zone           string        `json:"zone"`
+	WorkflowID     string        `json:"workflow_id"` // ProcessZoneWorkflow handling the zone
+	Total          int           `json:"total"`       // Domains of the zone in the file
+	Minted         int           `json:"minted"`
+	Burned         int           `json:"burned"`
+	Skipped        int           `json:"skipped"`    // Already minted or burned, or processed by a resumed run
+	Ignored        int           `json:"ignored"`    // Events whose action the policy of the zone ignores
+	Duplicates     int           `json:"duplicates"` // Of the skipped domains, those that were already minted
+	Failed         int           `json:"failed"`
+	NotVisible     int           `json:"not_visible,omitempty"` // Minted NFTs the mirror node did not show within the visibility timeout
+	FeesTinybar    int64         `json:"fees_tinybar"`          // Fees of the mints and burns of the zone
+	Paused         bool          `json:"paused,omitempty"`
+	Escalated      bool          `json:"escalated,omitempty"`   // Paused by an incident until ResumeZoneSignal
+	Escalations    int           `json:"escalations,omitempty"` // Incidents opened for the zone
+	Done           bool          `json:"done"`
+	RecentFailures []MintFailure `json:"recent_failures,omitempty"`
+}
+
+// Processed returns the number of domains that have been handled, successfully or not
+func (z ZoneProgress) Processed() int {
+	return z.Minted + z.Burned + z.Skipped + z.Ignored + z.Failed
+}
+
+// addFailure records a failure, keeping only the most recent ones
+func (z *ZoneProgress) addFailure(f MintFailure) {
+	z.Failed++
+	z.RecentFailures = append(z.RecentFailures, f)
+	if len(z.RecentFailures) > maxRecentFailures {
+		z.RecentFailures = z.RecentFailures[len(z.RecentFailures)-maxRecentFailures:]
+	}
+}
+
+// IngestProgress reports the progress of an ingest run.
+// Zone counts are only totals here, live counts come from querying each zone's workflow.
+type IngestProgress struct {
+	FilePath    string         `json:"file_path"`
+	Stage       string         `json:"stage"`
+	StartedAt   time.Time      `json:"started_at"`
+	TotalEvents int            `json:"total_events"`
+	Zones       []ZoneProgress `json:"zones"`
+	// Domains of zones refused by ZONE_ALLOWLIST or ZONE_DENYLIST, by zone
+	RefusedZones map[string]int `json:"refused_zones,omitempty"`
+	// Registrations resembling a brand of TYPOSQUAT_WATCHLIST
+	Typosquats []TyposquatMatch `json:"typosquats,omitempty"`
+	// Domains whose labels look algorithmically generated, see DGA_THRESHOLD
+	GeneratedLabels []GeneratedLabel `json:"generated_labels,omitempty"`
+	// Events repeating an earlier event of the file, dropped before minting
+	Suppressed int `json:"suppressed,omitempty"`
+	// Fees signaled by the zones so far and the budget of the run, tracked when the run has a budget
+	FeesTinybar   int64 `json:"fees_tinybar,omitempty"`
+	BudgetTinybar int64 `json:"budget_tinybar,omitempty"`
+}
+
+// RunReport summarizes an ingest run once it has stopped. Canceled runs produce a partial report.
+type RunReport struct {
+	WorkflowID  string         `json:"workflow_id"`
+	RunID       string         `json:"run_id"`
+	FilePath    string         `json:"file_path"`
+	ContentHash string         `json:"content_hash,omitempty"`
+	Outcome     string         `json:"outcome"` // IngestOutcomeCompleted, IngestOutcomeFailed or IngestOutcomeCanceled
+	Partial     bool           `json:"partial"` // Not every domain of the file was processed
+	Error       string         `json:"error,omitempty"`
+	StartedAt   time.Time      `json:"started_at"`
+	FinishedAt  time.Time      `json:"finished_at"`
+	TotalEvents int            `json:"total_events"`
+	Suppressed  int            `json:"suppressed,omitempty"` // Events repeating an earlier event of the file, not minted
+	Zones       []ZoneProgress `json:"zones"`
+	// Domains of zones refused by ZONE_ALLOWLIST or ZONE_DENYLIST, by zone
+	RefusedZones  map[string]int `json:"refused_zones,omitempty"`
+	FeesTinybar   int64          `json:"fees_tinybar"`             // Fees of the mints and burns of all zones
+	BudgetTinybar int64          `json:"budget_tinybar,omitempty"` // Budget of the run when it stopped, zero if unlimited
+	FeesUSD       float64        `json:"fees_usd,omitempty"`       // The fees at the exchange rate when the report was written
+	// Registrations resembling a brand of TYPOSQUAT_WATCHLIST, for review; they are minted like any other
+	Typosquats []TyposquatMatch `json:"typosquats,omitempty"`
+	// Domains whose labels look algorithmically generated, see DGA_THRESHOLD; they are minted like any other
+	GeneratedLabels []GeneratedLabel `json:"generated_labels,omitempty"`
+	// Quarantined events of the run retried by RetryQuarantineWorkflow, the zone counts include those resolved
+	Retried []RetriedEvent `json:"retried,omitempty"`
+}
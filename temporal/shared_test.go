@@ -0,0 +1,91 @@
+package temporal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/converter"
+)
+
+// roundTrip encodes a value with the default data converter of Temporal and decodes it into out
+func roundTrip(t *testing.T, in, out any) {
+	t.Helper()
+	dc := converter.GetDefaultDataConverter()
+	payloads, err := dc.ToPayloads(in)
+	require.NoError(t, err)
+	require.NoError(t, dc.FromPayloads(payloads, out))
+}
+
+func TestZeroZone_RoundTrip(t *testing.T) {
+	var info MintingInfo
+	roundTrip(t, MintingInfo{DomainName: "example.build"}, &info)
+	assert.Equal(t, MintingInfo{DomainName: "example.build"}, info)
+
+	var collections []ZoneCollectionInfo
+	roundTrip(t, []ZoneCollectionInfo{{TokenID: "0.0.100"}}, &collections)
+	assert.Equal(t, []ZoneCollectionInfo{{TokenID: "0.0.100"}}, collections)
+
+	var reparsed []ReparsedEvent
+	roundTrip(t, []ReparsedEvent{{Error: "still bad"}}, &reparsed)
+	assert.Equal(t, []ReparsedEvent{{Error: "still bad"}}, reparsed)
+}
+
+func TestSuppressDuplicates(t *testing.T) {
+	event := func(name, action, hash string) MintingInfo {
+		return MintingInfo{DomainName: name, Zone: "build", Action: action, EventHash: hash}
+	}
+	tests := []struct {
+		name       string
+		infos      []MintingInfo
+		kept       []MintingInfo
+		suppressed int
+	}{
+		{
+			name:  "distinct domains",
+			infos: []MintingInfo{event("a.build", "create", "h1"), event("b.build", "create", "h2")},
+			kept:  []MintingInfo{event("a.build", "create", "h1"), event("b.build", "create", "h2")},
+		},
+		{
+			name:       "same event twice",
+			infos:      []MintingInfo{event("a.build", "create", "h1"), event("a.build", "create", "h1")},
+			kept:       []MintingInfo{event("a.build", "create", "h1")},
+			suppressed: 1,
+		},
+		{
+			name:       "registration sent again with another hash",
+			infos:      []MintingInfo{event("a.build", "create", "h1"), event("A.build", "CREATE", "h2")},
+			kept:       []MintingInfo{event("a.build", "create", "h1")},
+			suppressed: 1,
+		},
+		{
+			name:       "domain list import",
+			infos:      []MintingInfo{event("a.build", "", ""), event("b.build", "", ""), event("a.build", "", "")},
+			kept:       []MintingInfo{event("a.build", "", ""), event("b.build", "", "")},
+			suppressed: 1,
+		},
+		{
+			name:  "deleted and registered again",
+			infos: []MintingInfo{event("a.build", "create", "h1"), event("a.build", "delete", "h2"), event("a.build", "create", "h3")},
+			kept:  []MintingInfo{event("a.build", "create", "h1"), event("a.build", "delete", "h2"), event("a.build", "create", "h3")},
+		},
+		{
+			name:       "earlier event replayed after another action",
+			infos:      []MintingInfo{event("a.build", "create", "h1"), event("a.build", "delete", "h2"), event("a.build", "create", "h1")},
+			kept:       []MintingInfo{event("a.build", "create", "h1"), event("a.build", "delete", "h2")},
+			suppressed: 1,
+		},
+		{
+			name:  "same label in another zone",
+			infos: []MintingInfo{event("a.build", "create", "h1"), {DomainName: "a.dev", Zone: "dev", Action: "create", EventHash: "h1"}},
+			kept:  []MintingInfo{event("a.build", "create", "h1"), {DomainName: "a.dev", Zone: "dev", Action: "create", EventHash: "h1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, suppressed := suppressDuplicates(tt.infos)
+			assert.Equal(t, tt.kept, kept)
+			assert.Equal(t, tt.suppressed, suppressed)
+		})
+	}
+}
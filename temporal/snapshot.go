@@ -0,0 +1,286 @@
+package temporal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/export"
+)
+
+// ErrTypeInvalidSnapshot is the application error type of snapshot requests that can never succeed
+const ErrTypeInvalidSnapshot = "InvalidSnapshot"
+
+// snapshotManifest is the file of a snapshot directory describing the snapshot
+const snapshotManifest = "snapshot.json"
+
+// SnapshotRequest is the input of SnapshotWorkflow
+type SnapshotRequest struct {
+	At     time.Time `json:"at"`               // Consensus time the ledger is captured at
+	Zones  []string  `json:"zones,omitempty"`  // Zones to capture, all zones of the zone registry if empty
+	Format string    `json:"format,omitempty"` // export.FormatCSV, export.FormatJSON or export.FormatParquet, CSV if empty
+}
+
+// ZoneSnapshot is the part of a snapshot capturing one zone
+type ZoneSnapshot struct {
+	Zone    string `json:"zone"`
+	TokenID string `json:"token_id,omitempty"`
+	Active  int    `json:"active"` // Domains whose NFT existed and was not burned at the time of the snapshot
+	Burned  int    `json:"burned"` // NFTs minted and burned before the time of the snapshot
+	File    string `json:"file,omitempty"`
+	Error   string `json:"error,omitempty"` // Set when the zone could not be captured
+}
+
+// LedgerSnapshot describes a snapshot, stored as snapshot.json next to the files of its zones
+type LedgerSnapshot struct {
+	ID        string         `json:"id"`
+	At        time.Time      `json:"at"`
+	Format    string         `json:"format"`
+	Zones     []ZoneSnapshot `json:"zones"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// DomainStateAt is what the ledger said about a domain at a point in time
+type DomainStateAt struct {
+	Domain         string    `json:"domain"`
+	At             time.Time `json:"at"`
+	TokenID        string    `json:"token_id,omitempty"`
+	SerialNumber   int64     `json:"serial_number,omitempty"`
+	Registered     bool      `json:"registered"` // The NFT of the domain existed and was not burned
+	MintedAt       time.Time `json:"minted_at,omitempty"`
+	OwnerAccountID string    `json:"owner_account_id,omitempty"`
+}
+
+// SnapshotID returns the ID of the snapshot of the ledger at a point in time
+func SnapshotID(at time.Time) string {
+	return at.UTC().Format("20060102T150405Z")
+}
+
+// SnapshotWorkflow materializes the active domains of every zone at a consensus time into an exportable
+// snapshot: a file per zone, with the owner of each NFT at that time, and a snapshot.json describing them,
+// in SNAPSHOT_DIR/<snapshot ID>. The state is rebuilt from the mint, transfer and burn history on the mirror
+// node, so a snapshot can be taken of any past time.
+func SnapshotWorkflow(ctx workflow.Context, req SnapshotRequest) (LedgerSnapshot, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting snapshot workflow", "at", req.At, "zones", req.Zones)
+
+	if req.At.IsZero() || req.At.After(workflow.Now(ctx)) {
+		return LedgerSnapshot{}, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("cannot take a snapshot at %s, it must be a time in the past", req.At.Format(time.RFC3339)), ErrTypeInvalidSnapshot, nil)
+	}
+	if req.Format == "" {
+		req.Format = export.FormatCSV
+	}
+	snapshot := LedgerSnapshot{ID: SnapshotID(req.At), At: req.At, Format: req.Format}
+
+	ctx = workflow.WithActivityOptions(ctx, defaultActivityOptions())
+	zones := req.Zones
+	if len(zones) == 0 {
+		var collections []ZoneCollectionInfo
+		if err := workflow.ExecuteActivity(ctx, "ListZoneCollectionsActivity").Get(ctx, &collections); err != nil {
+			logger.Error("Failed to list zone collections", "error", err)
+			return snapshot, err
+		}
+		for _, collection := range collections {
+			zones = append(zones, collection.Zone.String())
+		}
+	}
+
+	// Large collections are paged through for longer than the default timeout, with heartbeats
+	zoneOptions := defaultActivityOptions()
+	zoneOptions.StartToCloseTimeout = time.Hour
+	zoneOptions.HeartbeatTimeout = 2 * time.Minute
+	zoneCtx := workflow.WithActivityOptions(ctx, zoneOptions)
+	for _, zone := range zones {
+		var zoneSnapshot ZoneSnapshot
+		err := workflow.ExecuteActivity(zoneCtx, "SnapshotZoneActivity", snapshot.ID, zone, req.At, req.Format).Get(ctx, &zoneSnapshot)
+		if err != nil {
+			logger.Error("Failed to capture zone", "zone", zone, "error", err)
+			zoneSnapshot = ZoneSnapshot{Zone: zone, Error: err.Error()}
+		}
+		snapshot.Zones = append(snapshot.Zones, zoneSnapshot)
+	}
+
+	snapshot.CreatedAt = workflow.Now(ctx)
+	if err := workflow.ExecuteActivity(ctx, "SaveSnapshotActivity", snapshot).Get(ctx, nil); err != nil {
+		logger.Error("Failed to save snapshot", "error", err)
+		return snapshot, err
+	}
+	logger.Info("Snapshot workflow completed", "id", snapshot.ID, "zones", len(snapshot.Zones))
+	return snapshot, nil
+}
+
+// SnapshotZoneActivity writes the domains of a zone collection that were active at a consensus time, with their
+// owner at that time. NFTs are paged in serial order, which is the order they were minted in, so paging stops
+// at the first NFT minted after the snapshot.
+func (a *Activities) SnapshotZoneActivity(ctx context.Context, snapshotID, zone string, at time.Time, format string) (ZoneSnapshot, error) {
+	result := ZoneSnapshot{Zone: zone}
+	tokenID, err := a.resolveZoneCollection(ctx, zone, "")
+	if err != nil {
+		return result, err
+	}
+	result.TokenID = tokenID
+
+	dir := filepath.Join(a.Config.Registry.SnapshotDir, snapshotID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return result, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	output := filepath.Join(dir, zone+"."+format)
+
+	// Write to a temporary file next to the output, so a failed attempt leaves nothing behind
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(output)+".*")
+	if err != nil {
+		return result, fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	w, err := export.NewWriter(tmp, format)
+	if err != nil {
+		return result, temporal.NewNonRetryableApplicationError(err.Error(), ErrTypeInvalidSnapshot, err)
+	}
+
+	fmt.Printf("Capturing .%s collection %s at %s\n", zone, a.displayID(tokenID), at.Format(time.RFC3339))
+	path := fmt.Sprintf("/tokens/%s/nfts?limit=100&order=asc", tokenID)
+	for path != "" {
+		var response MirrorNodeNFTsResponse
+		if err := a.mirrorGet(ctx, path, &response); err != nil {
+			return result, err
+		}
+		path = ""
+		for _, nft := range response.NFTs {
+			if parseMirrorTimestamp(nft.CreatedAt).After(at) {
+				response.Links.Next = "" // Every later serial was minted later still
+				break
+			}
+			owner, active, err := a.nftStateAt(ctx, nft, at)
+			if err != nil {
+				return result, err
+			}
+			if !active {
+				result.Burned++
+				continue
+			}
+			row := a.exportedNFT(zone, nft)
+			row.OwnerAccountID, row.Deleted = owner, false
+			if err := w.Write(row); err != nil {
+				return result, fmt.Errorf("failed to write serial %d: %w", nft.SerialNumber, err)
+			}
+			result.Active++
+		}
+		heartbeat(ctx, result.Active)
+
+		if response.Links.Next != "" {
+			if path, err = a.mirrorNextPath(response.Links.Next); err != nil {
+				return result, fmt.Errorf("invalid pagination link: %w", err)
+			}
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return result, fmt.Errorf("failed to finish snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return result, err
+	}
+	if err := os.Rename(tmp.Name(), output); err != nil {
+		return result, fmt.Errorf("failed to move snapshot into place: %w", err)
+	}
+	result.File = output
+	fmt.Printf("Captured %d active domains of .%s (%d burned)\n", result.Active, zone, result.Burned)
+	return result, nil
+}
+
+// nftStateAt returns the owner of an NFT at a consensus time, and whether it still existed then. NFTs not
+// modified since are in their current state; the others are looked up in their transaction history.
+func (a *Activities) nftStateAt(ctx context.Context, nft MirrorNodeNFT, at time.Time) (string, bool, error) {
+	if nft.ModifiedAt == "" || !parseMirrorTimestamp(nft.ModifiedAt).After(at) {
+		return nft.AccountID, !nft.Deleted, nil
+	}
+	var history MirrorNodeNFTTransactionsResponse
+	path := fmt.Sprintf("/tokens/%s/nfts/%d/transactions?order=desc&limit=1&timestamp=lte:%s", nft.TokenID, nft.SerialNumber, mirrorTimestamp(at))
+	if err := a.mirrorGet(ctx, path, &history); err != nil {
+		return "", false, fmt.Errorf("failed to get history of serial %d: %w", nft.SerialNumber, err)
+	}
+	if len(history.Transactions) == 0 {
+		return "", false, fmt.Errorf("no transaction of serial %d before %s", nft.SerialNumber, at.Format(time.RFC3339))
+	}
+	switch last := history.Transactions[0]; last.Type {
+	case "TOKENBURN", "TOKENWIPE":
+		return "", false, nil
+	default:
+		return last.ReceiverAccountID, true, nil
+	}
+}
+
+// SaveSnapshotActivity writes the description of a snapshot next to the files of its zones
+func (a *Activities) SaveSnapshotActivity(ctx context.Context, snapshot LedgerSnapshot) error {
+	dir := filepath.Join(a.Config.Registry.SnapshotDir, snapshot.ID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, snapshotManifest), data, 0644)
+}
+
+// ListSnapshotsActivity returns the snapshots of SNAPSHOT_DIR, oldest point in time first
+func (a *Activities) ListSnapshotsActivity(ctx context.Context) ([]LedgerSnapshot, error) {
+	manifests, err := filepath.Glob(filepath.Join(a.Config.Registry.SnapshotDir, "*", snapshotManifest))
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make([]LedgerSnapshot, 0, len(manifests))
+	for _, manifest := range manifests {
+		data, err := os.ReadFile(manifest)
+		if err != nil {
+			return nil, err
+		}
+		var snapshot LedgerSnapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return nil, fmt.Errorf("failed to parse snapshot %s: %w", manifest, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].At.Before(snapshots[j].At) })
+	return snapshots, nil
+}
+
+// DomainAtActivity answers what the ledger said about a domain at a consensus time: whether its NFT existed
+// and had not been burned, and who owned it. Like verify, the NFT is searched among the most recent NFTs of
+// the zone collection.
+func (a *Activities) DomainAtActivity(ctx context.Context, domainName string, at time.Time) (DomainStateAt, error) {
+	dn, err := domain.NewDomainName(domainName)
+	if err != nil {
+		return DomainStateAt{}, fmt.Errorf("invalid domain name: %w", err)
+	}
+	state := DomainStateAt{Domain: dn.String(), At: at}
+	zone := dn.ParentDomain()
+	if zone == "" {
+		return state, fmt.Errorf("%s is a zone, not a domain within a zone", dn.String())
+	}
+	if state.TokenID, err = a.resolveZoneCollection(ctx, zone, ""); err != nil {
+		return state, err
+	}
+	nft, found, err := a.searchForDomainInCollection(ctx, state.TokenID, dn.Label())
+	if err != nil || !found {
+		return state, err
+	}
+	// A domain minted again after a burn has a later serial, only the most recent NFT is looked at
+	state.SerialNumber = nft.SerialNumber
+	state.MintedAt = parseMirrorTimestamp(nft.CreatedAt)
+	if state.MintedAt.After(at) {
+		return state, nil
+	}
+	state.OwnerAccountID, state.Registered, err = a.nftStateAt(ctx, nft, at)
+	return state, err
+}
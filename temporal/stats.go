@@ -0,0 +1,178 @@
+package temporal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// ZoneStats aggregates the activity of the ledger in one zone
+type ZoneStats struct {
+	Zone            string    `json:"zone"`
+	TokenID         string    `json:"token_id"`
+	Minted          int       `json:"minted"`            // NFTs ever minted in the collection, including burned ones
+	MintedThisMonth int       `json:"minted_this_month"` // NFTs minted since the start of the current month (UTC)
+	Burned          int       `json:"burned"`
+	FeesTinybar     int64     `json:"fees_tinybar"`       // Fees charged to the treasury for creating the collection, minting and burning
+	FeesUSD         float64   `json:"fees_usd,omitempty"` // The fees at the current exchange rate, unset if the rate is unavailable
+	DuplicateSkips  int       `json:"duplicate_skips"`    // Domains of ingest runs that were already minted
+	LastIngestAt    time.Time `json:"last_ingest_at,omitempty"`
+	Error           string    `json:"error,omitempty"` // Set when the mirror node could not be queried for the zone
+}
+
+// statsTransactionTypes are the treasury transactions whose fees are attributed to a collection
+var statsTransactionTypes = []string{"TOKENCREATION", "TOKENMINT", "TOKENBURN", "TOKENWIPE"}
+
+// ZoneStatsActivity aggregates per-zone statistics of the zones in the zone registry, or of the given zones only.
+// Mint and burn counts come from the NFTs of each collection and fees from the transactions of the collection
+// treasuries on the mirror node, converted to US dollars at the current exchange rate; duplicate skips and the last ingest time come from the run reports.
+func (a *Activities) ZoneStatsActivity(ctx context.Context, zones []string) ([]ZoneStats, error) {
+	collections, err := a.ListZoneCollectionsActivity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reports, err := a.loadRunReports()
+	if err != nil {
+		return nil, err
+	}
+
+	// Fees are shown in US dollars when the exchange rate is available
+	rate, rateErr := a.ExchangeRateActivity(ctx)
+	if rateErr != nil {
+		fmt.Printf("Warning: fees without USD amounts: %v\n", rateErr)
+	}
+
+	now := time.Now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	var stats []ZoneStats
+	fees := make(map[string]map[string]int64) // treasury -> token ID -> fees
+	for _, collection := range collections {
+		zone := collection.Zone.String()
+		if len(zones) > 0 && !slices.Contains(zones, zone) {
+			continue
+		}
+		zoneStats := ZoneStats{Zone: zone, TokenID: collection.TokenID}
+		for _, report := range reports {
+			for _, zone := range report.Zones {
+				if zone.Zone != zoneStats.Zone {
+					continue
+				}
+				zoneStats.DuplicateSkips += zone.Duplicates
+				if report.StartedAt.After(zoneStats.LastIngestAt) {
+					zoneStats.LastIngestAt = report.StartedAt
+				}
+			}
+		}
+
+		if err := a.countCollectionNFTs(ctx, &zoneStats, monthStart); err != nil {
+			zoneStats.Error = err.Error()
+			stats = append(stats, zoneStats)
+			continue
+		}
+
+		var token MirrorNodeToken
+		if err := a.mirrorGet(ctx, "/tokens/"+collection.TokenID, &token); err != nil {
+			zoneStats.Error = err.Error()
+			stats = append(stats, zoneStats)
+			continue
+		}
+		if _, ok := fees[token.TreasuryAccountID]; !ok {
+			treasuryFees, err := a.treasuryFees(ctx, token.TreasuryAccountID)
+			if err != nil {
+				zoneStats.Error = err.Error()
+				stats = append(stats, zoneStats)
+				continue
+			}
+			fees[token.TreasuryAccountID] = treasuryFees
+		}
+		zoneStats.FeesTinybar = fees[token.TreasuryAccountID][collection.TokenID]
+		if rateErr == nil {
+			zoneStats.FeesUSD = rate.USD(zoneStats.FeesTinybar)
+		}
+		stats = append(stats, zoneStats)
+	}
+	return stats, nil
+}
+
+// countCollectionNFTs pages through the NFTs of a collection, counting mints and burns
+func (a *Activities) countCollectionNFTs(ctx context.Context, stats *ZoneStats, monthStart time.Time) error {
+	path := fmt.Sprintf("/tokens/%s/nfts?limit=100&order=asc", stats.TokenID)
+	for path != "" {
+		var response MirrorNodeNFTsResponse
+		if err := a.mirrorGet(ctx, path, &response); err != nil {
+			return err
+		}
+		for _, nft := range response.NFTs {
+			stats.Minted++
+			if nft.Deleted {
+				stats.Burned++
+			}
+			if !parseMirrorTimestamp(nft.CreatedAt).Before(monthStart) {
+				stats.MintedThisMonth++
+			}
+		}
+		heartbeat(ctx, stats.Zone, stats.Minted)
+
+		path = ""
+		if response.Links.Next != "" {
+			var err error
+			if path, err = a.mirrorNextPath(response.Links.Next); err != nil {
+				return fmt.Errorf("invalid pagination link: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// treasuryFees sums the fees charged to a treasury account for collection transactions, by token ID
+func (a *Activities) treasuryFees(ctx context.Context, treasury string) (map[string]int64, error) {
+	fees := make(map[string]int64)
+	for _, txType := range statsTransactionTypes {
+		path := fmt.Sprintf("/transactions?account.id=%s&transactiontype=%s&limit=100&order=asc", treasury, txType)
+		for path != "" {
+			var response MirrorNodeTransactionsResponse
+			if err := a.mirrorGet(ctx, path, &response); err != nil {
+				return nil, err
+			}
+			for _, tx := range response.Transactions {
+				fees[tx.EntityID] += tx.ChargedTxFee
+			}
+			heartbeat(ctx, treasury, txType)
+
+			path = ""
+			if response.Links.Next != "" {
+				var err error
+				if path, err = a.mirrorNextPath(response.Links.Next); err != nil {
+					return nil, fmt.Errorf("invalid pagination link: %w", err)
+				}
+			}
+		}
+	}
+	return fees, nil
+}
+
+// loadRunReports loads all run reports of the report directory, skipping files that are not run reports
+func (a *Activities) loadRunReports() ([]RunReport, error) {
+	paths, err := filepath.Glob(filepath.Join(a.Config.Reports.Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var reports []RunReport
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read run report: %w", err)
+		}
+		var report RunReport
+		if err := json.Unmarshal(data, &report); err != nil || report.WorkflowID == "" {
+			continue
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
@@ -0,0 +1,243 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/workflow"
+	"google.golang.org/grpc"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/entityid"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/hcs"
+)
+
+const (
+	defaultSubscriptionLimit = 100              // Messages read by a subscription without a limit
+	defaultSubscriptionWait  = time.Minute      // Time a subscription without an end time waits for messages
+	subscriptionHeartbeat    = 10 * time.Second // Heartbeat interval while waiting for messages
+)
+
+// maxSubscriptionLimit caps the messages of a subscription, they are all returned at once
+const maxSubscriptionLimit = maxTopicReadMessages
+
+// subscriptionProgress is recorded in the heartbeats of SubscribeToTopicActivity, so a retried attempt resumes
+// after the consensus timestamp of the last message of the previous one, and waits no longer than it had left.
+// It holds the cursor only, the heartbeats stay small however many messages were received: a retried attempt
+// reads the messages received before it again, from From up to Position.
+type subscriptionProgress struct {
+	Start    time.Time    `json:"start"`
+	From     hcs.Position `json:"from"`     // Position the subscription started after, the offset of its consumer group
+	Received int          `json:"received"` // Messages received up to Position
+	Position hcs.Position `json:"position"`
+	Until    time.Time    `json:"until,omitempty"` // End of the wait of a subscription without an end time
+}
+
+// subscriptionActivityOptions returns the options of SubscribeToTopicActivity for subscriptions waiting for
+// messages up to wait: the heartbeats let a lost worker be detected, and the retry resume, within seconds
+func subscriptionActivityOptions(wait time.Duration) workflow.ActivityOptions {
+	if wait <= 0 {
+		wait = defaultSubscriptionWait
+	}
+	options := defaultActivityOptions()
+	options.StartToCloseTimeout = wait + options.StartToCloseTimeout
+	options.HeartbeatTimeout = 3 * subscriptionHeartbeat
+	return options
+}
+
+// topicConsumer connects to the gRPC API of the mirror node: MIRROR_NODE_GRPC, or the mirror node of the network.
+// The configured mirror node headers, such as the API key, are sent with every subscription.
+func (a *Activities) topicConsumer() (*hcs.Consumer, *grpc.ClientConn, error) {
+	address := a.Config.Mirror.GRPCAddress
+	if address == "" {
+		client, err := a.newHederaClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		mirrors := client.GetMirrorNetwork()
+		client.Close()
+		if len(mirrors) == 0 {
+			return nil, nil, fmt.Errorf("no mirror node gRPC address for %s, set MIRROR_NODE_GRPC", a.network())
+		}
+		address = mirrors[0]
+	}
+	conn, err := hcs.Dial(address)
+	if err != nil {
+		return nil, nil, err
+	}
+	consumer := hcs.NewConsumer(conn)
+	if header := a.Config.Mirror.Header(); len(header) > 0 {
+		consumer.Metadata = make(map[string]string, len(header))
+		for name := range header {
+			consumer.Metadata[name] = header.Get(name)
+		}
+	}
+	return consumer, conn, nil
+}
+
+// SubscribeToTopicActivity reads the messages of an HCS topic from the gRPC streaming API of the mirror node.
+// The subscription blocks until it is over: once Limit messages are read, at most maxSubscriptionLimit, once
+// EndTime is reached, or, without an end time, after Wait. Dropped streams are resumed after the last message
+// received, and progress is recorded in heartbeats, with the consensus timestamp of the last message, so a
+// retried attempt reads the messages received so far again and continues where the previous one stopped, within
+// what was left of the wait. A canceled
+// subscription stops at once, as does one whose worker shuts down, to be resumed on another worker. With a
+// ConsumerGroup, the subscription starts after the offset committed by the group, which the caller commits
+// once the messages are processed.
+func (a *Activities) SubscribeToTopicActivity(ctx context.Context, subscription TopicSubscriptionInfo) ([]TopicMessage, error) {
+	topicID, err := entityid.ParseTopic(subscription.TopicID, a.network())
+	if err != nil {
+		return nil, fmt.Errorf("invalid topic ID: %w", err)
+	}
+	limit := subscription.Limit
+	if limit <= 0 {
+		limit = defaultSubscriptionLimit // Prevent runaway subscriptions
+	}
+	limit = min(limit, maxSubscriptionLimit)
+
+	progress := subscriptionProgress{Start: subscription.StartTime}
+	if subscription.ConsumerGroup != "" {
+		offset, err := a.GetTopicOffsetActivity(ctx, subscription.ConsumerGroup, topicID.String())
+		if err != nil {
+			return nil, err
+		}
+		if offset.SequenceNumber > 0 {
+			progress.Start = offset.ConsensusTime
+			progress.Position = hcs.Position{SequenceNumber: offset.SequenceNumber, ConsensusTime: offset.ConsensusTime}
+			progress.From = progress.Position
+		} else if progress.Start.IsZero() {
+			progress.Start = time.Unix(0, 0) // A new group reads the topic from its first message
+		}
+	}
+	if activity.IsActivity(ctx) && activity.HasHeartbeatDetails(ctx) {
+		if err := activity.GetHeartbeatDetails(ctx, &progress); err == nil {
+			fmt.Printf("Resuming subscription to %s after %d messages, from sequence %d at %s\n", subscription.TopicID,
+				progress.Received, progress.Position.SequenceNumber, progress.Position.ConsensusTime.Format(time.RFC3339Nano))
+		}
+	}
+	if progress.Start.IsZero() {
+		progress.Start = time.Now()
+	}
+	if subscription.EndTime.IsZero() && progress.Until.IsZero() {
+		wait := subscription.Wait
+		if wait <= 0 {
+			wait = defaultSubscriptionWait
+		}
+		progress.Until = time.Now().Add(wait)
+	}
+
+	consumer, conn, err := a.topicConsumer()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	toMessage := func(msg hcs.Message) TopicMessage {
+		return TopicMessage{
+			TopicID:        subscription.TopicID,
+			SequenceNumber: msg.SequenceNumber,
+			ConsensusTime:  msg.ConsensusTime,
+			Message:        string(msg.Contents),
+			RunningHash:    fmt.Sprintf("%x", msg.RunningHash),
+			PayerAccountID: msg.PayerAccountID,
+		}
+	}
+	var messages []TopicMessage
+	if progress.Received > 0 {
+		// The messages of the previous attempts are on the mirror node, up to the position heartbeated
+		_, err := consumer.Consume(ctx, hcs.Query{
+			TopicID: topicID,
+			Start:   progress.Start,
+			End:     progress.Position.ConsensusTime.Add(time.Nanosecond),
+			After:   progress.From,
+		}, func(msg hcs.Message) error {
+			messages = append(messages, toMessage(msg))
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the messages received before the retry again: %w", err)
+		}
+		if len(messages) != progress.Received {
+			return nil, fmt.Errorf("read %d messages up to sequence %d again, the previous attempts received %d",
+				len(messages), progress.Position.SequenceNumber, progress.Received)
+		}
+	}
+	if len(messages) >= limit || subscription.EndTime.IsZero() && !time.Now().Before(progress.Until) {
+		return messages, nil
+	}
+
+	// Progress is shared with the heartbeat goroutine
+	var mu sync.Mutex
+	record := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		heartbeat(ctx, progress)
+	}
+	consumer.OnReconnect = func(attempt int, err error, from hcs.Position) {
+		fmt.Printf("Subscription to %s dropped (%v), reconnecting after sequence %d (attempt %d)\n",
+			subscription.TopicID, err, from.SequenceNumber, attempt)
+		record()
+	}
+
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if subscription.EndTime.IsZero() {
+		subCtx, cancel = context.WithDeadline(subCtx, progress.Until)
+		defer cancel()
+	}
+	record()
+	go func() {
+		ticker := time.NewTicker(subscriptionHeartbeat)
+		defer ticker.Stop()
+		var stop <-chan struct{}
+		if activity.IsActivity(ctx) {
+			stop = activity.GetWorkerStopChannel(ctx)
+		}
+		for {
+			select {
+			case <-subCtx.Done():
+				return
+			case <-stop:
+				cancel()
+				return
+			case <-ticker.C:
+				record()
+			}
+		}
+	}()
+
+	fmt.Printf("Subscribing to topic %s (limit %d messages)\n", subscription.TopicID, limit)
+	_, err = consumer.Consume(subCtx, hcs.Query{
+		TopicID: topicID,
+		Start:   progress.Start,
+		End:     subscription.EndTime,
+		Limit:   uint64(limit - len(messages)),
+		After:   progress.Position,
+	}, func(msg hcs.Message) error {
+		fmt.Printf("Received message: sequence %d at %s\n", msg.SequenceNumber, msg.ConsensusTime.Format(time.RFC3339))
+		messages = append(messages, toMessage(msg))
+		mu.Lock()
+		progress.Received++
+		progress.Position = hcs.Position{SequenceNumber: msg.SequenceNumber, ConsensusTime: msg.ConsensusTime}
+		mu.Unlock()
+		record()
+		return nil
+	})
+	switch {
+	case err == nil:
+	case workerStopping(ctx):
+		return nil, errWorkerShutdown("subscription to " + subscription.TopicID)
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	case errors.Is(err, context.DeadlineExceeded):
+		// The wait for messages is over
+	default:
+		return nil, err
+	}
+
+	fmt.Printf("Subscription completed. Received %d messages\n", len(messages))
+	return messages, nil
+}
@@ -0,0 +1,99 @@
+package temporal
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hiero-ledger/hiero-sdk-go/v2/proto/mirror"
+	"github.com/hiero-ledger/hiero-sdk-go/v2/proto/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/testsuite"
+	"google.golang.org/grpc"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/hcs"
+)
+
+// fakeMirror serves the messages of a topic, one per second after t0, and records the queries it was sent
+type fakeMirror struct {
+	mirror.UnimplementedConsensusServiceServer
+
+	t0       time.Time
+	messages int
+	queries  chan *mirror.ConsensusTopicQuery
+}
+
+func (m *fakeMirror) SubscribeTopic(q *mirror.ConsensusTopicQuery, stream mirror.ConsensusService_SubscribeTopicServer) error {
+	m.queries <- q
+	start := time.Unix(q.ConsensusStartTime.Seconds, int64(q.ConsensusStartTime.Nanos))
+	sent := uint64(0)
+	for seq := 1; seq <= m.messages; seq++ {
+		at := m.t0.Add(time.Duration(seq) * time.Second)
+		if at.Before(start) {
+			continue
+		}
+		if q.ConsensusEndTime != nil && !at.Before(time.Unix(q.ConsensusEndTime.Seconds, int64(q.ConsensusEndTime.Nanos))) {
+			return nil
+		}
+		err := stream.Send(&mirror.ConsensusTopicResponse{
+			ConsensusTimestamp: &services.Timestamp{Seconds: at.Unix(), Nanos: int32(at.Nanosecond())},
+			Message:            []byte{byte('0' + seq)},
+			SequenceNumber:     uint64(seq),
+		})
+		if err != nil {
+			return err
+		}
+		if sent++; q.Limit > 0 && sent == q.Limit {
+			return nil
+		}
+	}
+	<-stream.Context().Done()
+	return nil
+}
+
+func TestSubscribeToTopicActivity_Resume(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := &fakeMirror{t0: t0, messages: 5, queries: make(chan *mirror.ConsensusTopicQuery, 10)}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	mirror.RegisterConsensusServiceServer(server, m)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	a := &Activities{Config: &config.Config{Mirror: config.MirrorConfig{GRPCAddress: listener.Addr().String()}}}
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestActivityEnvironment()
+	env.RegisterActivity(a)
+	// The previous attempt received the first two messages, its heartbeat carries their position only
+	env.SetHeartbeatDetails(subscriptionProgress{
+		Start:    t0,
+		Received: 2,
+		Position: hcs.Position{SequenceNumber: 2, ConsensusTime: t0.Add(2 * time.Second)},
+	})
+
+	value, err := env.ExecuteActivity(a.SubscribeToTopicActivity, TopicSubscriptionInfo{
+		TopicID: "0.0.1234",
+		EndTime: t0.Add(time.Hour),
+		Limit:   4,
+	})
+	require.NoError(t, err)
+	var messages []TopicMessage
+	require.NoError(t, value.Get(&messages))
+
+	require.Len(t, messages, 4)
+	for i, msg := range messages {
+		assert.Equal(t, uint64(i+1), msg.SequenceNumber)
+		assert.Equal(t, string(rune('1'+i)), msg.Message)
+		assert.Equal(t, "0.0.1234", msg.TopicID)
+	}
+
+	replay := <-m.queries
+	assert.Equal(t, t0.Add(2*time.Second+time.Nanosecond), time.Unix(replay.ConsensusEndTime.Seconds, int64(replay.ConsensusEndTime.Nanos)).UTC(),
+		"the received messages are read again up to the position")
+	resume := <-m.queries
+	assert.Equal(t, uint64(2), resume.Limit, "the subscription continues for what is left of its limit")
+	assert.Equal(t, t0.Add(2*time.Second+time.Nanosecond), time.Unix(resume.ConsensusStartTime.Seconds, int64(resume.ConsensusStartTime.Nanos)).UTC())
+}
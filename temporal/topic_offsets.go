@@ -0,0 +1,301 @@
+package temporal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/entityid"
+)
+
+// DefaultConsumeBatchSize is the number of messages ConsumeTopicWorkflow handles per batch when the request does not say
+const DefaultConsumeBatchSize = 100
+
+// consumeBatchesPerRun bounds the history of one ConsumeTopicWorkflow run, after which it continues as new
+const consumeBatchesPerRun = 50
+
+// consumerGroupPattern restricts consumer group names to what fits in an offset key and a workflow ID
+var consumerGroupPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// TopicOffset is the last message of a topic processed by a consumer group
+type TopicOffset struct {
+	Group          string    `json:"group"`
+	TopicID        string    `json:"topic_id"`
+	SequenceNumber uint64    `json:"sequence_number"` // 0 if the group has not processed any message yet
+	ConsensusTime  time.Time `json:"consensus_time"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TopicOffsets holds the committed offsets of the consumer groups, keyed by group and topic
+type TopicOffsets struct {
+	Offsets     map[string]TopicOffset `json:"offsets"` // "group/topic_id" -> offset
+	LastUpdated time.Time              `json:"last_updated"`
+}
+
+// ConsumeTopicRequest is the input of ConsumeTopicWorkflow
+type ConsumeTopicRequest struct {
+	Group     string        // Consumer group, whose offset is committed after every batch
+	TopicID   string        // Topic to consume
+	BatchSize int           // Messages per batch, DefaultConsumeBatchSize if zero
+	Wait      time.Duration // Time a batch waits for messages, a minute if zero
+	Follow    bool          // Keep waiting for messages once the group has caught up, instead of returning
+
+	// Carried over when the workflow continues as new
+	Consumed int
+}
+
+// ConsumeTopicResult is the result of ConsumeTopicWorkflow
+type ConsumeTopicResult struct {
+	Consumed int         `json:"consumed"`
+	Offset   TopicOffset `json:"offset"`
+}
+
+// topicOffsetKey returns the key of the offset of a group, validating the group and the topic
+func (a *Activities) topicOffsetKey(group, topicID string) (string, string, error) {
+	if !consumerGroupPattern.MatchString(group) {
+		return "", "", fmt.Errorf("invalid consumer group %q: use letters, digits, '.', '_' and '-'", group)
+	}
+	topic, err := entityid.ParseTopic(topicID, a.network())
+	if err != nil {
+		return "", "", fmt.Errorf("invalid topic ID: %w", err)
+	}
+	return group + "/" + topic.String(), topic.String(), nil
+}
+
+// GetTopicOffsetActivity returns the offset committed by a consumer group for a topic, with a zero
+// sequence number if the group has not processed any message of the topic yet
+func (a *Activities) GetTopicOffsetActivity(ctx context.Context, group, topicID string) (TopicOffset, error) {
+	key, topicID, err := a.topicOffsetKey(group, topicID)
+	if err != nil {
+		return TopicOffset{}, err
+	}
+	offsets, err := a.loadTopicOffsets()
+	if err != nil {
+		return TopicOffset{}, fmt.Errorf("failed to load topic offsets: %w", err)
+	}
+	if offset, ok := offsets.Offsets[key]; ok {
+		return offset, nil
+	}
+	return TopicOffset{Group: group, TopicID: topicID}, nil
+}
+
+// CommitTopicOffsetActivity records the last message a consumer group processed. Offsets only move forward:
+// committing a message at or before the committed one leaves the offset as it is, so a retried commit is
+// harmless. Use ResetTopicOffsetActivity to consume messages again.
+func (a *Activities) CommitTopicOffsetActivity(ctx context.Context, offset TopicOffset) (TopicOffset, error) {
+	key, topicID, err := a.topicOffsetKey(offset.Group, offset.TopicID)
+	if err != nil {
+		return TopicOffset{}, err
+	}
+	if offset.SequenceNumber == 0 {
+		return TopicOffset{}, errors.New("cannot commit sequence number 0")
+	}
+	offset.TopicID = topicID
+
+	a.offsetsMu.Lock()
+	defer a.offsetsMu.Unlock()
+	offsets, err := a.loadTopicOffsets()
+	if err != nil {
+		return TopicOffset{}, fmt.Errorf("failed to load topic offsets: %w", err)
+	}
+	if committed, ok := offsets.Offsets[key]; ok && committed.SequenceNumber >= offset.SequenceNumber {
+		return committed, nil
+	}
+	offset.UpdatedAt = time.Now().UTC()
+	offsets.Offsets[key] = offset
+	if err := a.saveTopicOffsets(offsets); err != nil {
+		return TopicOffset{}, fmt.Errorf("failed to save topic offsets: %w", err)
+	}
+	return offset, nil
+}
+
+// ResetTopicOffsetActivity moves the offset of a consumer group to a message of the topic, after which the
+// group resumes. Sequence number 0 removes the offset, so the group consumes the topic from the start.
+func (a *Activities) ResetTopicOffsetActivity(ctx context.Context, group, topicID string, sequence uint64) (TopicOffset, error) {
+	key, topicID, err := a.topicOffsetKey(group, topicID)
+	if err != nil {
+		return TopicOffset{}, err
+	}
+	offset := TopicOffset{Group: group, TopicID: topicID, SequenceNumber: sequence}
+	if sequence > 0 {
+		// The stream resumes by consensus time, so the time of the message is needed as well
+		var message MirrorNodeTopicMessage
+		if err := a.mirrorGet(ctx, fmt.Sprintf("/topics/%s/messages/%d", topicID, sequence), &message); err != nil {
+			return TopicOffset{}, fmt.Errorf("failed to look up message %d of %s: %w", sequence, a.displayID(topicID), err)
+		}
+		offset.ConsensusTime = parseMirrorTimestamp(message.ConsensusTimestamp)
+	}
+
+	a.offsetsMu.Lock()
+	defer a.offsetsMu.Unlock()
+	offsets, err := a.loadTopicOffsets()
+	if err != nil {
+		return TopicOffset{}, fmt.Errorf("failed to load topic offsets: %w", err)
+	}
+	if sequence == 0 {
+		delete(offsets.Offsets, key)
+	} else {
+		offset.UpdatedAt = time.Now().UTC()
+		offsets.Offsets[key] = offset
+	}
+	if err := a.saveTopicOffsets(offsets); err != nil {
+		return TopicOffset{}, fmt.Errorf("failed to save topic offsets: %w", err)
+	}
+	fmt.Printf("Reset offset of group %s on %s to sequence %d\n", group, a.displayID(topicID), sequence)
+	return offset, nil
+}
+
+// ListTopicOffsetsActivity returns the committed offsets, sorted by group and topic
+func (a *Activities) ListTopicOffsetsActivity(ctx context.Context) ([]TopicOffset, error) {
+	offsets, err := a.loadTopicOffsets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load topic offsets: %w", err)
+	}
+	list := make([]TopicOffset, 0, len(offsets.Offsets))
+	for _, offset := range offsets.Offsets {
+		list = append(list, offset)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Group != list[j].Group {
+			return list[i].Group < list[j].Group
+		}
+		return list[i].TopicID < list[j].TopicID
+	})
+	return list, nil
+}
+
+// HandleTopicMessagesActivity processes a batch of messages consumed by a consumer group. Messages are
+// reported with the producer of their envelope, if any. With HCS_PRODUCER_KEYS_FILE, messages not signed by a
+// trusted producer are rejected: they are reported, not processed, and the offset moves past them.
+func (a *Activities) HandleTopicMessagesActivity(ctx context.Context, group string, messages []TopicMessage) error {
+	keys, err := a.producerKeys()
+	if err != nil {
+		return err
+	}
+	for _, message := range messages {
+		payload, producer, err := openAuditMessage(keys, []byte(message.Message))
+		if err != nil {
+			fmt.Printf("[%s] %s #%d at %s rejected: %v\n", group, a.displayID(message.TopicID), message.SequenceNumber,
+				message.ConsensusTime.Format(time.RFC3339), err)
+			continue
+		}
+		if producer == "" {
+			producer = "-"
+		}
+		fmt.Printf("[%s] %s #%d at %s (producer %s): %s\n", group, a.displayID(message.TopicID), message.SequenceNumber,
+			message.ConsensusTime.Format(time.RFC3339), producer, payload)
+	}
+	return nil
+}
+
+// loadTopicOffsets reads the topic offsets from their JSON file
+func (a *Activities) loadTopicOffsets() (*TopicOffsets, error) {
+	data, err := a.readRegistryFile(a.Config.Registry.TopicOffsetFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &TopicOffsets{
+				Offsets:     make(map[string]TopicOffset),
+				LastUpdated: time.Now(),
+			}, nil
+		}
+		return nil, err
+	}
+
+	var offsets TopicOffsets
+	if err := json.Unmarshal(data, &offsets); err != nil {
+		return nil, err
+	}
+	if offsets.Offsets == nil {
+		offsets.Offsets = make(map[string]TopicOffset)
+	}
+	return &offsets, nil
+}
+
+// saveTopicOffsets saves the topic offsets to their JSON file
+func (a *Activities) saveTopicOffsets(offsets *TopicOffsets) error {
+	offsets.LastUpdated = time.Now()
+	data, err := json.MarshalIndent(offsets, "", "  ")
+	if err != nil {
+		return err
+	}
+	return a.writeRegistryFile(a.Config.Registry.TopicOffsetFile, data)
+}
+
+// ConsumeTopicWorkflow consumes a topic as a consumer group: batches of messages after the offset of the
+// group are handled, then the offset is committed to the last message of the batch. A batch handled by
+// this workflow is recorded in its history and is not handled again when a worker restarts, and a new
+// run of the workflow resumes after the committed offset, so messages are neither dropped nor processed
+// twice. Without Follow, the workflow returns once a batch finds no new message.
+func ConsumeTopicWorkflow(ctx workflow.Context, req ConsumeTopicRequest) (ConsumeTopicResult, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting topic consumer workflow", "group", req.Group, "topicID", req.TopicID, "consumed", req.Consumed)
+
+	ctx = workflow.WithActivityOptions(ctx, defaultActivityOptions())
+	if req.BatchSize <= 0 {
+		req.BatchSize = DefaultConsumeBatchSize
+	}
+
+	var offset TopicOffset
+	if err := workflow.ExecuteActivity(ctx, "GetTopicOffsetActivity", req.Group, req.TopicID).Get(ctx, &offset); err != nil {
+		logger.Error("Failed to get topic offset", "error", err)
+		return ConsumeTopicResult{}, err
+	}
+
+	subscribeCtx := workflow.WithActivityOptions(ctx, subscriptionActivityOptions(req.Wait))
+	for i := 0; i < consumeBatchesPerRun; i++ {
+		var messages []TopicMessage
+		err := workflow.ExecuteActivity(subscribeCtx, "SubscribeToTopicActivity", TopicSubscriptionInfo{
+			TopicID:       req.TopicID,
+			Limit:         req.BatchSize,
+			Wait:          req.Wait,
+			ConsumerGroup: req.Group,
+		}).Get(ctx, &messages)
+		if ctx.Err() != nil {
+			// Canceled while waiting for messages, the committed offset is where the next run resumes
+			logger.Info("Topic consumer canceled", "group", req.Group, "topicID", req.TopicID, "consumed", req.Consumed)
+			result := ConsumeTopicResult{Consumed: req.Consumed, Offset: offset}
+			return result, temporal.NewCanceledError(result)
+		}
+		if err != nil {
+			logger.Error("Failed to read topic messages", "error", err)
+			return ConsumeTopicResult{Consumed: req.Consumed, Offset: offset}, err
+		}
+		if len(messages) == 0 {
+			if !req.Follow {
+				logger.Info("Topic consumer caught up", "group", req.Group, "topicID", req.TopicID, "consumed", req.Consumed)
+				return ConsumeTopicResult{Consumed: req.Consumed, Offset: offset}, nil
+			}
+			continue
+		}
+
+		if err := workflow.ExecuteActivity(ctx, "HandleTopicMessagesActivity", req.Group, messages).Get(ctx, nil); err != nil {
+			logger.Error("Failed to handle topic messages", "error", err)
+			return ConsumeTopicResult{Consumed: req.Consumed, Offset: offset}, err
+		}
+		last := messages[len(messages)-1]
+		err = workflow.ExecuteActivity(ctx, "CommitTopicOffsetActivity", TopicOffset{
+			Group:          req.Group,
+			TopicID:        req.TopicID,
+			SequenceNumber: last.SequenceNumber,
+			ConsensusTime:  last.ConsensusTime,
+		}).Get(ctx, &offset)
+		if err != nil {
+			logger.Error("Failed to commit topic offset", "error", err)
+			return ConsumeTopicResult{Consumed: req.Consumed, Offset: offset}, err
+		}
+		req.Consumed += len(messages)
+		logger.Info("Committed topic offset", "group", req.Group, "sequenceNumber", offset.SequenceNumber)
+	}
+
+	// Keep the history of a long consumption bounded
+	logger.Info("Continuing topic consumer as new", "sequenceNumber", offset.SequenceNumber)
+	return ConsumeTopicResult{}, workflow.NewContinueAsNewError(ctx, ConsumeTopicWorkflow, req)
+}
@@ -0,0 +1,122 @@
+package temporal
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/entityid"
+)
+
+// ErrTypeUnboundedTopicRead is the application error type of topic reads without an end
+const ErrTypeUnboundedTopicRead = "UnboundedTopicRead"
+
+// maxTopicReadMessages caps the messages of a single read, larger ranges must be read in several windows
+const maxTopicReadMessages = 1000
+
+// ReadTopicMessagesActivity reads the messages of an HCS topic within a closed range from the mirror node REST API.
+// The range must end, at a consensus time or a sequence number, so the activity returns the same messages
+// however often it is retried. When the range ends at a sequence number the mirror node has not ingested
+// yet, the read fails and is retried, rather than returning a partial range.
+func (a *Activities) ReadTopicMessagesActivity(ctx context.Context, req TopicReadRequest) ([]TopicMessage, error) {
+	if req.EndTime.IsZero() && req.ToSequence == 0 {
+		return nil, temporal.NewNonRetryableApplicationError(
+			"topic read needs an end time or a last sequence number", ErrTypeUnboundedTopicRead, nil)
+	}
+	if req.ToSequence > 0 && req.FromSequence > req.ToSequence {
+		return nil, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("sequence range %d-%d is empty", req.FromSequence, req.ToSequence), ErrTypeUnboundedTopicRead, nil)
+	}
+	if !req.StartTime.IsZero() && !req.EndTime.IsZero() && req.EndTime.Before(req.StartTime) {
+		return nil, temporal.NewNonRetryableApplicationError(
+			"topic read ends before it starts", ErrTypeUnboundedTopicRead, nil)
+	}
+	topicID, err := entityid.ParseTopic(req.TopicID, a.network())
+	if err != nil {
+		return nil, fmt.Errorf("invalid topic ID: %w", err)
+	}
+
+	query := url.Values{"order": {"asc"}, "limit": {"100"}}
+	if !req.StartTime.IsZero() {
+		query.Add("timestamp", "gte:"+mirrorTimestamp(req.StartTime))
+	}
+	if !req.EndTime.IsZero() {
+		query.Add("timestamp", "lte:"+mirrorTimestamp(req.EndTime))
+	}
+	if req.FromSequence > 0 {
+		query.Add("sequencenumber", fmt.Sprintf("gte:%d", req.FromSequence))
+	}
+	if req.ToSequence > 0 {
+		query.Add("sequencenumber", fmt.Sprintf("lte:%d", req.ToSequence))
+	}
+
+	var messages []TopicMessage
+	path := fmt.Sprintf("/topics/%s/messages?%s", topicID, query.Encode())
+	for path != "" {
+		var response MirrorNodeTopicMessagesResponse
+		if err := a.mirrorGet(ctx, path, &response); err != nil {
+			return nil, err
+		}
+		for _, m := range response.Messages {
+			message, err := topicMessageFromMirror(req.TopicID, m)
+			if err != nil {
+				return nil, err
+			}
+			messages = append(messages, message)
+		}
+		if len(messages) > maxTopicReadMessages {
+			return nil, temporal.NewNonRetryableApplicationError(
+				fmt.Sprintf("range holds more than %d messages, read it in smaller windows", maxTopicReadMessages), ErrTypeUnboundedTopicRead, nil)
+		}
+
+		path = ""
+		if response.Links.Next != "" {
+			if path, err = a.mirrorNextPath(response.Links.Next); err != nil {
+				return nil, fmt.Errorf("invalid pagination link: %w", err)
+			}
+		}
+	}
+
+	// Without an end time, the range is complete once its last message is known to the mirror node
+	if req.EndTime.IsZero() {
+		last := uint64(0)
+		if len(messages) > 0 {
+			last = messages[len(messages)-1].SequenceNumber
+		}
+		if last < req.ToSequence {
+			return nil, fmt.Errorf("mirror node has messages of %s up to sequence %d, waiting for %d", a.displayID(req.TopicID), last, req.ToSequence)
+		}
+	}
+	fmt.Printf("Read %d messages of topic %s\n", len(messages), a.displayID(req.TopicID))
+	return messages, nil
+}
+
+// topicMessageFromMirror converts a message returned by /topics/{id}/messages
+func topicMessageFromMirror(topicID string, m MirrorNodeTopicMessage) (TopicMessage, error) {
+	contents, err := base64.StdEncoding.DecodeString(m.Message)
+	if err != nil {
+		return TopicMessage{}, fmt.Errorf("invalid contents of message %d: %w", m.SequenceNumber, err)
+	}
+	runningHash, err := base64.StdEncoding.DecodeString(m.RunningHash)
+	if err != nil {
+		return TopicMessage{}, fmt.Errorf("invalid running hash of message %d: %w", m.SequenceNumber, err)
+	}
+	return TopicMessage{
+		TopicID:        topicID,
+		SequenceNumber: m.SequenceNumber,
+		ConsensusTime:  parseMirrorTimestamp(m.ConsensusTimestamp),
+		Message:        string(contents),
+		RunningHash:    hex.EncodeToString(runningHash),
+		PayerAccountID: m.PayerAccountID,
+	}, nil
+}
+
+// mirrorTimestamp formats a time as a mirror node timestamp ("seconds.nanoseconds")
+func mirrorTimestamp(t time.Time) string {
+	return fmt.Sprintf("%d.%09d", t.Unix(), t.Nanosecond())
+}
@@ -0,0 +1,219 @@
+package temporal
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/entityid"
+)
+
+// TopicRecordCreated is the type of the registry topic record of a created topic
+const TopicRecordCreated = "topic.created"
+
+// TopicRecord is published to HCS_REGISTRY_TOPIC for every topic the ledger creates, so any worker can
+// discover the topics by name without sharing the topic registry file
+type TopicRecord struct {
+	Type string `json:"type"`
+	TopicInfo
+}
+
+// TopicRegistrySync is the outcome of syncing the topic registry file with the registry topic
+type TopicRegistrySync struct {
+	RegistryTopic string   `json:"registry_topic"`
+	Sequence      uint64   `json:"sequence"`  // Sequence number of the last record read
+	Records       int      `json:"records"`   // Records read
+	Added         []string `json:"added"`     // Topics new to the file, or replaced by an earlier record
+	Conflicts     []string `json:"conflicts"` // Topics whose name an earlier record already claimed, ignored
+}
+
+// SyncTopicRegistryActivity applies the records published to the registry topic since the last sync to the
+// topic registry file. The first record of a name wins: later records of another topic under the same name,
+// created by workers racing for it, are reported as conflicts and ignored.
+func (a *Activities) SyncTopicRegistryActivity(ctx context.Context) (TopicRegistrySync, error) {
+	if a.Config.HCS.RegistryTopic == "" {
+		return TopicRegistrySync{}, errors.New("HCS_REGISTRY_TOPIC is not set")
+	}
+	registryTopic, err := entityid.ParseTopic(a.Config.HCS.RegistryTopic, a.network())
+	if err != nil {
+		return TopicRegistrySync{}, fmt.Errorf("invalid registry topic: %w", err)
+	}
+
+	unlock, err := a.lockTopicRegistry()
+	if err != nil {
+		return TopicRegistrySync{}, err
+	}
+	defer unlock()
+	registry, err := a.loadTopicRegistry()
+	if err != nil {
+		return TopicRegistrySync{}, fmt.Errorf("failed to load topic registry: %w", err)
+	}
+	if registry.Topics == nil {
+		registry.Topics = make(map[string]TopicInfo)
+	}
+	if registry.RegistryTopic != registryTopic.String() {
+		// The file caches another registry topic, or none yet: apply every record of this one
+		registry.RegistryTopic, registry.RegistrySequence = registryTopic.String(), 0
+		for name, topic := range registry.Topics {
+			topic.RegistrySequence = 0
+			registry.Topics[name] = topic
+		}
+	}
+	result := TopicRegistrySync{RegistryTopic: registry.RegistryTopic, Sequence: registry.RegistrySequence}
+
+	path := fmt.Sprintf("/topics/%s/messages?limit=100&order=asc&sequencenumber=gt:%d", registryTopic, registry.RegistrySequence)
+	for path != "" {
+		var response MirrorNodeTopicMessagesResponse
+		if err := a.mirrorGet(ctx, path, &response); err != nil {
+			return result, err
+		}
+		for _, message := range response.Messages {
+			result.Records++
+			result.Sequence = message.SequenceNumber
+			record, ok := decodeTopicRecord(message)
+			if _, err := entityid.ParseTopic(record.TopicID, a.network()); !ok || err != nil {
+				fmt.Printf("Skipping message %d of registry topic %s: not a topic record\n", message.SequenceNumber, a.displayID(registry.RegistryTopic))
+				continue
+			}
+			record.RegistrySequence = message.SequenceNumber
+			cached, exists := registry.Topics[record.TopicName]
+			switch {
+			case exists && cached.TopicID == record.TopicID && cached.RegistrySequence != 0:
+				// Already known, possibly published by this worker
+			case exists && cached.TopicID != record.TopicID && cached.RegistrySequence != 0 && cached.RegistrySequence < record.RegistrySequence:
+				result.Conflicts = append(result.Conflicts, record.TopicName)
+			default:
+				registry.Topics[record.TopicName] = record.TopicInfo
+				result.Added = append(result.Added, record.TopicName)
+			}
+		}
+
+		path = ""
+		if response.Links.Next != "" {
+			if path, err = a.mirrorNextPath(response.Links.Next); err != nil {
+				return result, fmt.Errorf("invalid pagination link: %w", err)
+			}
+		}
+	}
+
+	registry.RegistrySequence = result.Sequence
+	if err := a.saveTopicRegistry(registry); err != nil {
+		return result, fmt.Errorf("failed to save topic registry: %w", err)
+	}
+	fmt.Printf("Synced topic registry with %s: %d records, %d topics added, %d conflicts\n",
+		a.displayID(registry.RegistryTopic), result.Records, len(result.Added), len(result.Conflicts))
+	return result, nil
+}
+
+// PublishTopicRegistryActivity publishes the topics of the topic registry file that are not on the registry
+// topic yet, such as topics created before HCS_REGISTRY_TOPIC was set. The file is synced first, so names
+// already claimed on the registry topic are not published again.
+func (a *Activities) PublishTopicRegistryActivity(ctx context.Context) ([]TopicInfo, error) {
+	if _, err := a.SyncTopicRegistryActivity(ctx); err != nil {
+		return nil, err
+	}
+	registry, err := a.loadTopicRegistry()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load topic registry: %w", err)
+	}
+	names := make([]string, 0, len(registry.Topics))
+	for name, topic := range registry.Topics {
+		if topic.RegistrySequence == 0 && !a.isRegistryTopic(topic.TopicID) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	published := make([]TopicInfo, 0, len(names))
+	for _, name := range names {
+		topic, err := a.publishTopic(ctx, registry.Topics[name])
+		if err != nil {
+			return published, err
+		}
+		published = append(published, topic)
+	}
+	return published, nil
+}
+
+// lookupTopic finds a topic by name in the topic registry file. On a miss, the file is synced with the
+// registry topic first, if one is configured, as another worker may have created the topic.
+func (a *Activities) lookupTopic(ctx context.Context, name string) (TopicInfo, bool, error) {
+	registry, err := a.loadTopicRegistry()
+	if err != nil {
+		return TopicInfo{}, false, fmt.Errorf("failed to load topic registry: %w", err)
+	}
+	if topic, exists := registry.Topics[name]; exists || a.Config.HCS.RegistryTopic == "" {
+		return topic, exists, nil
+	}
+	if _, err := a.SyncTopicRegistryActivity(ctx); err != nil {
+		return TopicInfo{}, false, fmt.Errorf("failed to sync topic registry: %w", err)
+	}
+	if registry, err = a.loadTopicRegistry(); err != nil {
+		return TopicInfo{}, false, fmt.Errorf("failed to load topic registry: %w", err)
+	}
+	topic, exists := registry.Topics[name]
+	return topic, exists, nil
+}
+
+// publishTopic publishes the record of a topic to the registry topic and records its sequence number
+func (a *Activities) publishTopic(ctx context.Context, topic TopicInfo) (TopicInfo, error) {
+	record, err := json.Marshal(TopicRecord{Type: TopicRecordCreated, TopicInfo: topic})
+	if err != nil {
+		return topic, fmt.Errorf("failed to marshal topic record: %w", err)
+	}
+	sent, err := a.SendMessageToTopicActivity(ctx, a.Config.HCS.RegistryTopic, string(record))
+	if err != nil {
+		return topic, fmt.Errorf("failed to publish topic '%s' to the registry topic: %w", topic.TopicName, err)
+	}
+	topic.RegistrySequence = sent.SequenceNumber
+	if err := a.registerTopic(topic); err != nil {
+		fmt.Printf("Warning: Could not register topic in registry: %v\n", err)
+	}
+	return topic, nil
+}
+
+// isRegistryTopic reports whether a topic is HCS_REGISTRY_TOPIC, which is not recorded on itself
+func (a *Activities) isRegistryTopic(topicID string) bool {
+	if a.Config.HCS.RegistryTopic == "" {
+		return false
+	}
+	registryTopic, err := entityid.ParseTopic(a.Config.HCS.RegistryTopic, a.network())
+	if err != nil {
+		return false
+	}
+	topic, err := entityid.ParseTopic(topicID, a.network())
+	return err == nil && topic == registryTopic
+}
+
+// decodeTopicRecord decodes a message of the registry topic, reporting whether it is a valid topic record
+func decodeTopicRecord(message MirrorNodeTopicMessage) (TopicRecord, bool) {
+	data, err := base64.StdEncoding.DecodeString(message.Message)
+	if err != nil {
+		return TopicRecord{}, false
+	}
+	var record TopicRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return TopicRecord{}, false
+	}
+	if record.Type != TopicRecordCreated || record.TopicName == "" || record.TopicID == "" {
+		return TopicRecord{}, false
+	}
+	return record, true
+}
+
+// ListTopicsActivity returns the topics of the topic registry file, sorted by name
+func (a *Activities) ListTopicsActivity(ctx context.Context) ([]TopicInfo, error) {
+	registry, err := a.loadTopicRegistry()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load topic registry: %w", err)
+	}
+	topics := make([]TopicInfo, 0, len(registry.Topics))
+	for _, topic := range registry.Topics {
+		topics = append(topics, topic)
+	}
+	sort.Slice(topics, func(i, j int) bool { return topics[i].TopicName < topics[j].TopicName })
+	return topics, nil
+}
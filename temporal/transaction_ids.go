@@ -0,0 +1,59 @@
+package temporal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"strings"
+	"time"
+
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"go.temporal.io/sdk/activity"
+)
+
+// dedupValidDuration is how long mints with a deterministic transaction ID are valid, the longest duration
+// Hedera accepts. The network rejects a transaction ID it has seen for as long as the transaction is valid.
+const dedupValidDuration = 180 * time.Second
+
+// dedupClockSkew moves deterministic valid starts back, so nodes whose clock is behind the scheduling time
+// do not see them in the future
+const dedupClockSkew = 5 * time.Second
+
+// dedupSubmitMargin is the validity a transaction must have left to be submitted with its deterministic ID
+const dedupSubmitMargin = 30 * time.Second
+
+// deterministicTransactionID derives the ID of a transaction from the identity of what it does, e.g. the token,
+// domain and event of a mint: paid by payer, valid from validStart to the second, with nanoseconds taken from
+// the hash of the identity. Submissions of the same identity with the same valid start share the ID, and the
+// network accepts only the first of them.
+func deterministicTransactionID(payer hedera.AccountID, validStart time.Time, identity ...string) hedera.TransactionID {
+	sum := sha256.Sum256([]byte(strings.Join(identity, "\x00")))
+	nanos := time.Duration(binary.BigEndian.Uint32(sum[:4]) % uint32(time.Second))
+	return hedera.NewTransactionIDWithValidStart(payer, validStart.Truncate(time.Second).Add(nanos))
+}
+
+// dedupTransactionID returns the deterministic ID of a transaction submitted by the current activity, valid
+// from the time the activity was first scheduled: every attempt of the activity then submits the same ID while
+// it is valid, and the network rejects the attempts after the first one that reached it as DUPLICATE_TRANSACTION.
+// Outside an activity, or once that validity is nearly over, it returns false and the transaction gets a
+// fresh ID; later attempts rely on the mirror node to find what an earlier attempt submitted.
+func dedupTransactionID(ctx context.Context, payer hedera.AccountID, identity ...string) (hedera.TransactionID, bool) {
+	if !activity.IsActivity(ctx) {
+		return hedera.TransactionID{}, false
+	}
+	validStart, ok := dedupValidStart(activity.GetInfo(ctx).ScheduledTime, time.Now())
+	if !ok {
+		return hedera.TransactionID{}, false
+	}
+	return deterministicTransactionID(payer, validStart, identity...), true
+}
+
+// dedupValidStart returns the valid start of the deterministic ID of a transaction first scheduled at the given
+// time, or false when the transaction would have less than dedupSubmitMargin of validity left at now
+func dedupValidStart(scheduled, now time.Time) (time.Time, bool) {
+	validStart := scheduled.Add(-dedupClockSkew)
+	if now.Sub(validStart) > dedupValidDuration-dedupSubmitMargin {
+		return time.Time{}, false
+	}
+	return validStart, true
+}
@@ -0,0 +1,64 @@
+package temporal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeterministicTransactionID(t *testing.T) {
+	payer := hedera.AccountID{Account: 2}
+	validStart := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	id := deterministicTransactionID(payer, validStart, "0.0.100", "example.build", "h1")
+	assert.Equal(t, id.String(), deterministicTransactionID(payer, validStart.Add(300*time.Millisecond), "0.0.100", "example.build", "h1").String(),
+		"the valid start is truncated to the second")
+	require.NotNil(t, id.AccountID)
+	assert.Equal(t, payer, *id.AccountID)
+	require.NotNil(t, id.ValidStart)
+	assert.Equal(t, validStart, id.ValidStart.Truncate(time.Second))
+
+	for _, identity := range [][]string{
+		{"0.0.100", "example.build", "h2"},
+		{"0.0.100", "other.build", "h1"},
+		{"0.0.101", "example.build", "h1"},
+		{"0.0.100example.build", "h1"},
+	} {
+		assert.NotEqual(t, id.String(), deterministicTransactionID(payer, validStart, identity...).String(), identity)
+	}
+	assert.NotEqual(t, id.String(), deterministicTransactionID(payer, validStart.Add(time.Second), "0.0.100", "example.build", "h1").String())
+	assert.NotEqual(t, id.String(), deterministicTransactionID(hedera.AccountID{Account: 3}, validStart, "0.0.100", "example.build", "h1").String())
+}
+
+func TestDedupValidStart(t *testing.T) {
+	scheduled := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		now  time.Time
+		ok   bool
+	}{
+		{"first attempt", scheduled, true},
+		{"retry within the validity", scheduled.Add(time.Minute), true},
+		{"last retry with enough validity left", scheduled.Add(dedupValidDuration - dedupSubmitMargin - dedupClockSkew), true},
+		{"retry too close to the end of the validity", scheduled.Add(dedupValidDuration - dedupSubmitMargin - dedupClockSkew + time.Second), false},
+		{"retry past the validity", scheduled.Add(dedupValidDuration), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validStart, ok := dedupValidStart(scheduled, tt.now)
+			assert.Equal(t, tt.ok, ok)
+			if ok {
+				assert.Equal(t, scheduled.Add(-dedupClockSkew), validStart)
+			}
+		})
+	}
+}
+
+func TestDedupTransactionID_OutsideActivity(t *testing.T) {
+	_, ok := dedupTransactionID(context.Background(), hedera.AccountID{Account: 2}, "0.0.100", "example.build")
+	assert.False(t, ok)
+}
@@ -0,0 +1,134 @@
+package temporal
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"go.temporal.io/sdk/activity"
+)
+
+// Types of the transactions kept in TRANSACTION_RECORD_DIR, as named by the mirror node
+const (
+	TransactionTokenCreation = "TOKENCREATION"
+	TransactionTokenMint     = "TOKENMINT"
+	TransactionTokenBurn     = "TOKENBURN"
+	TransactionTokenWipe     = "TOKENWIPE"
+)
+
+// Types of the other transactions of an NFT, as named by the mirror node
+const (
+	TransactionCryptoTransfer  = "CRYPTOTRANSFER"
+	TransactionTokenUpdateNFTs = "TOKENUPDATENFTS"
+)
+
+// TransactionRecord is the full record of a transaction of the ledger: a collection created, or an NFT minted
+// or burned. Records are kept in TRANSACTION_RECORD_DIR so the ledger can be audited without the mirror node.
+type TransactionRecord struct {
+	TransactionID   string                `json:"transaction_id"`
+	Type            string                `json:"type"`
+	Status          string                `json:"status"`
+	ConsensusAt     time.Time             `json:"consensus_at"`
+	FeeTinybar      int64                 `json:"fee_tinybar"`      // Fee charged to the payer
+	TransactionHash string                `json:"transaction_hash"` // Hex encoded
+	Memo            string                `json:"memo,omitempty"`
+	TokenID         string                `json:"token_id"`
+	Serials         []int64               `json:"serials,omitempty"` // NFTs minted or burned
+	Transfers       []TransactionTransfer `json:"transfers,omitempty"`
+	Zone            string                `json:"zone"`
+	Domain          string                `json:"domain,omitempty"`
+	WorkflowID      string                `json:"workflow_id,omitempty"` // The run that submitted the transaction
+	RecordedAt      time.Time             `json:"recorded_at"`
+}
+
+// TransactionTransfer is an HBAR transfer of a transaction, e.g. the payment of its fee
+type TransactionTransfer struct {
+	Account       string `json:"account"`
+	AmountTinybar int64  `json:"amount_tinybar"`
+}
+
+// TransactionRecordPath returns the path of the record of a transaction in the given directory.
+// Files are named after the mirror node notation of the ID, which holds no "@".
+func TransactionRecordPath(dir, transactionID string) string {
+	return filepath.Join(dir, MirrorTransactionID(transactionID)+".json")
+}
+
+// newTransactionRecord converts the record of a transaction returned by the network
+func newTransactionRecord(txType, zone, tokenID string, record hedera.TransactionRecord) TransactionRecord {
+	transfers := make([]TransactionTransfer, 0, len(record.Transfers))
+	for _, transfer := range record.Transfers {
+		transfers = append(transfers, TransactionTransfer{
+			Account:       transfer.AccountID.String(),
+			AmountTinybar: transfer.Amount.AsTinybar(),
+		})
+	}
+	return TransactionRecord{
+		TransactionID:   record.TransactionID.String(),
+		Type:            txType,
+		Status:          record.Receipt.Status.String(),
+		ConsensusAt:     record.ConsensusTimestamp.UTC(),
+		FeeTinybar:      record.TransactionFee.AsTinybar(),
+		TransactionHash: hex.EncodeToString(record.TransactionHash),
+		Memo:            record.TransactionMemo,
+		TokenID:         tokenID,
+		Serials:         record.Receipt.SerialNumbers,
+		Transfers:       transfers,
+		Zone:            zone,
+	}
+}
+
+// saveTransactionRecord keeps the record of a transaction in TRANSACTION_RECORD_DIR. The transaction is final,
+// so a record that cannot be kept is reported without failing the activity that submitted it.
+func (a *Activities) saveTransactionRecord(ctx context.Context, record TransactionRecord) {
+	if activity.IsActivity(ctx) {
+		record.WorkflowID = activity.GetInfo(ctx).WorkflowExecution.ID
+	}
+	record.RecordedAt = time.Now().UTC()
+	if err := a.writeTransactionRecord(record); err != nil {
+		fmt.Printf("Warning: failed to keep the record of transaction %s: %v\n", record.TransactionID, err)
+	}
+}
+
+// writeTransactionRecord writes the record of a transaction to TRANSACTION_RECORD_DIR
+func (a *Activities) writeTransactionRecord(record TransactionRecord) error {
+	dir := a.Config.Registry.TransactionDir
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create transaction record directory: %w", err)
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal transaction record: %w", err)
+	}
+	return os.WriteFile(TransactionRecordPath(dir, record.TransactionID), data, 0644)
+}
+
+// ListTransactionRecordsActivity returns the transaction records of TRANSACTION_RECORD_DIR in consensus order,
+// of the given zone only unless it is empty
+func (a *Activities) ListTransactionRecordsActivity(ctx context.Context, zone string) ([]TransactionRecord, error) {
+	paths, err := filepath.Glob(filepath.Join(a.Config.Registry.TransactionDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var records []TransactionRecord
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read transaction record: %w", err)
+		}
+		var record TransactionRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, fmt.Errorf("failed to decode transaction record %s: %w", path, err)
+		}
+		if zone == "" || record.Zone == zone {
+			records = append(records, record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].ConsensusAt.Before(records[j].ConsensusAt) })
+	return records, nil
+}
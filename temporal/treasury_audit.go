@@ -0,0 +1,198 @@
+package temporal
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/store"
+)
+
+// Issues a treasury audit flags
+const (
+	AuditTransferred = "transferred" // The NFT left the treasury other than by a claim
+	AuditNotIndexed  = "not_indexed" // The NFT is not the one the domain index records for its domain
+	AuditMissing     = "missing"     // The domain index records an NFT the collection does not have, or which was burned
+)
+
+// TreasuryFinding is an NFT a treasury audit flags
+type TreasuryFinding struct {
+	Issue         string `json:"issue"`
+	Domain        string `json:"domain"`
+	TokenID       string `json:"token_id"`
+	SerialNumber  int64  `json:"serial_number"`
+	Holder        string `json:"holder,omitempty"`         // Current holder, outside the treasury
+	TransactionID string `json:"transaction_id,omitempty"` // Transfer out of the treasury
+}
+
+// CollectionAudit is the treasury audit of one collection, or shard, of a zone
+type CollectionAudit struct {
+	Zone       string            `json:"zone"`
+	TokenID    string            `json:"token_id"`
+	Treasury   string            `json:"treasury"`
+	Minted     int               `json:"minted"`      // NFTs of the collection that are not burned
+	InTreasury int               `json:"in_treasury"` // Of which held by the treasury
+	Claimed    int               `json:"claimed"`     // Of which transferred to their registrant by a claim
+	Indexed    bool              `json:"indexed"`     // Cross-checked against the registry store
+	Findings   []TreasuryFinding `json:"findings,omitempty"`
+	Error      string            `json:"error,omitempty"` // The collection could not be audited
+}
+
+// TreasuryAuditActivity lists the NFTs of the collections of the zone registry, or of the given zones only, with
+// their holders on the mirror node. NFTs outside the treasury are checked against the memo of their transfer
+// out of it, and flagged unless a claim transferred them. With REGISTRY_STORE_DSN, the NFTs are also
+// cross-checked against the domain index, both ways.
+func (a *Activities) TreasuryAuditActivity(ctx context.Context, zones []string) ([]CollectionAudit, error) {
+	collections, err := a.ListZoneCollectionsActivity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s, err := a.domainStore(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the registry store: %w", err)
+	}
+
+	var audits []CollectionAudit
+	for _, collection := range collections {
+		zone := collection.Zone.String()
+		if len(zones) > 0 && !slices.Contains(zones, zone) || collection.TokenID == "" {
+			continue
+		}
+		// The domain index of the zone, by token and serial
+		var index map[string]store.Domain
+		if s != nil {
+			if index, err = zoneIndex(ctx, s, collection.Zone); err != nil {
+				return nil, fmt.Errorf("failed to read the domain index of .%s: %w", zone, err)
+			}
+		}
+		shards := collection.TokenIDs()
+		for i := len(shards) - 1; i >= 0; i-- {
+			audit := CollectionAudit{Zone: zone, TokenID: shards[i], Indexed: index != nil}
+			if err := a.auditCollection(ctx, &audit, index); err != nil {
+				audit.Error = err.Error()
+			}
+			audits = append(audits, audit)
+		}
+		// What is left of the index is not in any shard of the zone
+		for _, d := range index {
+			audits[len(audits)-1].Findings = append(audits[len(audits)-1].Findings, TreasuryFinding{
+				Issue: AuditMissing, Domain: d.Name, TokenID: d.TokenID, SerialNumber: d.Serial,
+			})
+		}
+	}
+	return audits, nil
+}
+
+// zoneIndex returns the minted domains of a zone in the registry store, by token and serial
+func zoneIndex(ctx context.Context, s *store.Store, zone domain.Zone) (map[string]store.Domain, error) {
+	index := make(map[string]store.Domain)
+	q := store.Query{Zone: zone, Status: store.StatusMinted, Limit: 1000}
+	for {
+		domains, next, err := s.ListDomains(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range domains {
+			index[indexKey(d.TokenID, d.Serial)] = d
+		}
+		if next == nil {
+			return index, nil
+		}
+		q.After = next
+	}
+}
+
+func indexKey(tokenID string, serial int64) string {
+	return fmt.Sprintf("%s/%d", tokenID, serial)
+}
+
+// auditCollection audits the NFTs of a collection, removing those it finds from the index
+func (a *Activities) auditCollection(ctx context.Context, audit *CollectionAudit, index map[string]store.Domain) error {
+	var token MirrorNodeToken
+	if err := a.mirrorGet(ctx, "/tokens/"+audit.TokenID, &token); err != nil {
+		return err
+	}
+	audit.Treasury = token.TreasuryAccountID
+	nfts, err := a.queryCollectionNFTs(ctx, audit.TokenID)
+	if err != nil {
+		return err
+	}
+	for _, nft := range nfts {
+		if nft.Deleted {
+			continue
+		}
+		audit.Minted++
+		name := nftDomainName(nft, audit.Zone)
+
+		if index != nil {
+			key := indexKey(audit.TokenID, nft.SerialNumber)
+			if d, ok := index[key]; ok && d.Name == name {
+				delete(index, key)
+			} else {
+				audit.Findings = append(audit.Findings, TreasuryFinding{
+					Issue: AuditNotIndexed, Domain: name, TokenID: audit.TokenID, SerialNumber: nft.SerialNumber, Holder: nft.AccountID,
+				})
+			}
+		}
+
+		if nft.AccountID == audit.Treasury {
+			audit.InTreasury++
+			continue
+		}
+		heartbeat(ctx, audit.TokenID, nft.SerialNumber)
+		transactionID, claimed, err := a.transferOutOfTreasury(ctx, audit.TokenID, nft.SerialNumber, audit.Treasury, name)
+		if err != nil {
+			return err
+		}
+		if claimed {
+			audit.Claimed++
+			continue
+		}
+		audit.Findings = append(audit.Findings, TreasuryFinding{
+			Issue: AuditTransferred, Domain: name, TokenID: audit.TokenID, SerialNumber: nft.SerialNumber,
+			Holder: nft.AccountID, TransactionID: transactionID,
+		})
+	}
+	fmt.Printf("Audited %s of .%s: %d NFTs, %d in treasury %s, %d claimed, %d findings\n",
+		a.displayID(audit.TokenID), audit.Zone, audit.Minted, audit.InTreasury, audit.Treasury, audit.Claimed, len(audit.Findings))
+	return nil
+}
+
+// nftDomainName returns the name of the domain of an NFT of the collection of a zone, from its metadata
+func nftDomainName(nft MirrorNodeNFT, zone string) string {
+	metadata := strings.TrimSpace(nft.Metadata)
+	if decoded, err := base64.StdEncoding.DecodeString(metadata); err == nil {
+		metadata = string(decoded)
+	}
+	label, _ := ParseNFTMetadata(metadata)
+	return label + "." + zone
+}
+
+// transferOutOfTreasury finds the last transfer of an NFT out of the treasury, and reports whether it was the
+// claim of the domain. An NFT the treasury never sent, e.g. wiped and minted again elsewhere, has no transfer.
+func (a *Activities) transferOutOfTreasury(ctx context.Context, tokenID string, serial int64, treasury, domainName string) (string, bool, error) {
+	var history MirrorNodeNFTTransactionsResponse
+	if err := a.mirrorGet(ctx, fmt.Sprintf("/tokens/%s/nfts/%d/transactions?order=desc", tokenID, serial), &history); err != nil {
+		return "", false, err
+	}
+	for _, tx := range history.Transactions {
+		if tx.SenderAccountID != treasury {
+			continue
+		}
+		var response MirrorNodeTransactionsResponse
+		if err := a.mirrorGet(ctx, "/transactions/"+tx.TransactionID, &response); err != nil {
+			return tx.TransactionID, false, err
+		}
+		for _, transfer := range response.Transactions {
+			memo, _ := base64.StdEncoding.DecodeString(transfer.MemoBase64)
+			if string(memo) == claimMemo(domainName) {
+				return tx.TransactionID, true, nil
+			}
+		}
+		return tx.TransactionID, false, nil
+	}
+	return "", false, nil
+}
@@ -0,0 +1,56 @@
+package temporal
+
+import (
+	"strings"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/zonepolicy"
+)
+
+// TyposquatMatch flags a registration whose label resembles a brand of TYPOSQUAT_WATCHLIST
+type TyposquatMatch struct {
+	Domain      string  `json:"domain"`
+	Zone        string  `json:"zone"`
+	LineNumber  int     `json:"line_number"`
+	RegistrarID string  `json:"registrar_id,omitempty"`
+	Brand       string  `json:"brand"`
+	Score       float64 `json:"score"` // Keyboard-aware similarity of the label and the brand, from 0 to 1
+}
+
+// typosquatMatch returns the brand of TYPOSQUAT_WATCHLIST the label of a registered domain resembles most,
+// or nil if none reaches TYPOSQUAT_THRESHOLD or the event is not a registration
+func (a *Activities) typosquatMatch(info MintingInfo) *TyposquatMatch {
+	action := strings.ToLower(strings.TrimSpace(info.Action))
+	if len(a.Config.Events.Watchlist) == 0 || (action != "" && action != zonepolicy.EventCreate) {
+		return nil
+	}
+	name := domain.DomainName(info.DomainName)
+	label := domain.Label(name.Label())
+	var match *TyposquatMatch
+	for _, brand := range a.Config.Events.Watchlist {
+		score := domain.KeyboardSimilarity(label, domain.Label(brand))
+		if score < a.Config.Events.TyposquatThreshold || (match != nil && score <= match.Score) {
+			continue
+		}
+		match = &TyposquatMatch{
+			Domain:      info.DomainName,
+			Zone:        info.Zone.String(),
+			LineNumber:  info.LineNumber,
+			RegistrarID: info.RegistrarID,
+			Brand:       brand,
+			Score:       score,
+		}
+	}
+	return match
+}
+
+// typosquats returns the registrations flagged by typosquatMatch, in the order of the file
+func typosquats(infos []MintingInfo) []TyposquatMatch {
+	var matches []TyposquatMatch
+	for _, info := range infos {
+		if info.Typosquat != nil {
+			matches = append(matches, *info.Typosquat)
+		}
+	}
+	return matches
+}
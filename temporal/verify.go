@@ -0,0 +1,332 @@
+package temporal
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+)
+
+// DomainVerification is the outcome of verifying a domain against public ledger data only
+type DomainVerification struct {
+	Domain            string        `json:"domain"`
+	Zone              string        `json:"zone"`
+	TokenID           string        `json:"token_id,omitempty"`
+	SerialNumber      int64         `json:"serial_number,omitempty"`
+	MintTransactionID string        `json:"mint_transaction_id,omitempty"`
+	Checks            []CheckResult `json:"checks"`
+}
+
+// Verified returns true if the NFT of the domain was found and no check failed
+func (v DomainVerification) Verified() bool {
+	return v.SerialNumber != 0 && AllPassed(v.Checks)
+}
+
+// VerifyDomainActivity checks the ledger entry of a domain using nothing but the public mirror node:
+// it locates the zone collection (or uses tokenID when given), finds the NFT of the domain,
+// fetches its mint transaction and checks it was submitted successfully by the collection treasury.
+// Checks that cannot be performed on the data of this ledger are reported as skipped.
+func (a *Activities) VerifyDomainActivity(ctx context.Context, domainName, tokenID string) (DomainVerification, error) {
+	dn, err := domain.NewDomainName(domainName)
+	if err != nil {
+		return DomainVerification{}, fmt.Errorf("invalid domain name: %w", err)
+	}
+	v := DomainVerification{Domain: dn.String(), Zone: dn.ParentDomain()}
+	if v.Zone == "" {
+		return v, fmt.Errorf("%s is a zone, not a domain within a zone", dn.String())
+	}
+
+	// --- Collection ---
+	token, check := a.verifyCollection(ctx, v.Zone, tokenID)
+	v.Checks = append(v.Checks, check)
+	if !check.OK {
+		return v, nil
+	}
+	v.TokenID = token.TokenID
+
+	// --- NFT ---
+	nft, found, err := a.searchForDomainInCollection(ctx, token.TokenID, dn.Label())
+	check = CheckResult{Name: "nft"}
+	switch {
+	case err != nil:
+		check.Detail = err.Error()
+	case !found:
+		check.Detail = fmt.Sprintf("no NFT with label %q among the most recent NFTs of %s", dn.Label(), a.displayID(token.TokenID))
+	default:
+		check.OK = true
+		check.Detail = fmt.Sprintf("serial %d of %s", nft.SerialNumber, a.displayID(token.TokenID))
+		v.SerialNumber = nft.SerialNumber
+	}
+	v.Checks = append(v.Checks, check)
+	if !check.OK {
+		return v, nil
+	}
+
+	// --- Mint transaction ---
+	mintTx, check := a.verifyMintTransaction(ctx, token, nft.SerialNumber)
+	v.Checks = append(v.Checks, check)
+	if check.OK {
+		v.MintTransactionID = mintTx.TransactionID
+		v.Checks = append(v.Checks, a.verifyMintedByTreasury(token, mintTx))
+	}
+
+	// --- Event hash and audit trail ---
+	metadata := nft.Metadata
+	if decoded, err := base64.StdEncoding.DecodeString(metadata); err == nil {
+		metadata = string(decoded)
+	}
+	v.Checks = append(v.Checks, verifyEventHash(metadata, mintTx), a.verifyMintReceipt(ctx, v, mintTx))
+	return v, nil
+}
+
+// verifyEventHash checks the event hash in the NFT metadata matches the hash in the memo of the mint transaction.
+// Either may be truncated: the metadata for long labels, the memo when it names the ingest run of the mint.
+func verifyEventHash(metadata string, mintTx MirrorNodeTransaction) CheckResult {
+	check := CheckResult{Name: "event hash"}
+	_, metadataHash := ParseNFTMetadata(metadata)
+	memo, _ := base64.StdEncoding.DecodeString(mintTx.MemoBase64)
+	trace, hasMemoHash := ParseEventMemo(string(memo))
+	memoHash := trace.EventHash
+	switch {
+	case metadataHash == "" && !hasMemoHash:
+		check.Skipped = true
+		check.Detail = fmt.Sprintf("metadata %q holds no event hash", metadata)
+	case metadataHash == "":
+		check.Detail = fmt.Sprintf("metadata %q holds no event hash, the mint memo does", metadata)
+	case mintTx.TransactionID == "":
+		check.Skipped = true
+		check.Detail = "the mint transaction is unknown"
+	case !hasMemoHash:
+		check.Detail = fmt.Sprintf("the memo of %s holds no event hash", mintTx.TransactionID)
+	case len(memoHash) > 64 || !(strings.HasPrefix(memoHash, metadataHash) || strings.HasPrefix(metadataHash, memoHash)):
+		check.Detail = fmt.Sprintf("metadata hash %s does not match the mint memo hash %s", metadataHash, memoHash)
+	default:
+		check.OK = true
+		if len(metadataHash) > len(memoHash) {
+			memoHash = metadataHash
+		}
+		check.Detail = "sha256:" + memoHash
+		if trace.RunID != "" {
+			check.Detail += ", ingest run " + trace.RunID
+		}
+	}
+	return check
+}
+
+// receiptSearchPages bounds the pages of topic messages searched for the receipt of a mint
+const receiptSearchPages = 10
+
+// verifyMintReceipt looks for the receipt of the mint on the receipts topic, among the messages following the mint
+func (a *Activities) verifyMintReceipt(ctx context.Context, v DomainVerification, mintTx MirrorNodeTransaction) CheckResult {
+	check := CheckResult{Name: "hcs audit message"}
+	if a.Config.HCS.ReceiptsTopic == "" {
+		check.Skipped = true
+		check.Detail = "no receipts topic configured (HCS_RECEIPTS_TOPIC)"
+		return check
+	}
+	if mintTx.ConsensusTimestamp == "" {
+		check.Skipped = true
+		check.Detail = "the mint transaction is unknown"
+		return check
+	}
+	topic, err := a.GetTopicInfoActivity(ctx, a.Config.HCS.ReceiptsTopic)
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+
+	found, err := a.searchMintReceipt(ctx, topic.TopicID, mintTx.ConsensusTimestamp, v.TokenID, v.SerialNumber)
+	switch {
+	case err != nil:
+		check.Detail = err.Error()
+	case found == nil:
+		check.Detail = fmt.Sprintf("no receipt for serial %d on %s", v.SerialNumber, a.displayID(topic.TopicID))
+	case found.Receipt.DomainHash != DomainHash(v.Domain) || MirrorTransactionID(found.Receipt.TransactionID) != mintTx.TransactionID:
+		check.Detail = fmt.Sprintf("receipt #%d of %s does not match the domain and mint transaction", found.SequenceNumber, a.displayID(topic.TopicID))
+	default:
+		check.OK = true
+		check.Detail = fmt.Sprintf("receipt #%d of %s", found.SequenceNumber, a.displayID(topic.TopicID))
+		if found.Producer != "" {
+			check.Detail += fmt.Sprintf(", published by %s", found.Producer)
+		}
+	}
+	return check
+}
+
+// foundReceipt is a mint receipt found on the receipts topic
+type foundReceipt struct {
+	Receipt        MintReceipt
+	SequenceNumber uint64
+	ConsensusTime  time.Time
+	Producer       string // Key ID of the worker that enveloped the receipt, empty for plain receipts
+}
+
+// searchMintReceipt looks for the receipt of an NFT on the receipts topic, among the messages following the
+// mint at the given mirror node timestamp. It returns nil when the receipt is not found within
+// receiptSearchPages pages.
+func (a *Activities) searchMintReceipt(ctx context.Context, topicID, mintedAt, tokenID string, serial int64) (*foundReceipt, error) {
+	keys, err := a.producerKeys()
+	if err != nil {
+		return nil, err
+	}
+	path := fmt.Sprintf("/topics/%s/messages?limit=100&order=asc&timestamp=gte:%s", topicID, mintedAt)
+	for page := 0; path != "" && page < receiptSearchPages; page++ {
+		var response MirrorNodeTopicMessagesResponse
+		if err := a.mirrorGet(ctx, path, &response); err != nil {
+			return nil, err
+		}
+		for _, message := range response.Messages {
+			data, err := base64.StdEncoding.DecodeString(message.Message)
+			if err != nil {
+				continue
+			}
+			payload, producer, err := openAuditMessage(keys, data)
+			if err != nil {
+				continue // A forged receipt is not the receipt of the mint
+			}
+			var receipt MintReceipt
+			if err := json.Unmarshal(payload, &receipt); err != nil {
+				continue
+			}
+			if receipt.TokenID == tokenID && receipt.SerialNumber == serial {
+				return &foundReceipt{
+					Receipt:        receipt,
+					SequenceNumber: message.SequenceNumber,
+					ConsensusTime:  parseMirrorTimestamp(message.ConsensusTimestamp),
+					Producer:       producer,
+				}, nil
+			}
+		}
+
+		path = ""
+		if response.Links.Next != "" {
+			var err error
+			if path, err = a.mirrorNextPath(response.Links.Next); err != nil {
+				return nil, fmt.Errorf("invalid pagination link: %w", err)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// verifyCollection locates the NFT collection of a zone by its well-known token name, or loads the given token
+func (a *Activities) verifyCollection(ctx context.Context, zone, tokenID string) (MirrorNodeToken, CheckResult) {
+	check := CheckResult{Name: "collection"}
+	expectedName, err := a.zoneCollectionName(zone)
+	if err != nil {
+		check.Detail = err.Error()
+		return MirrorNodeToken{}, check
+	}
+
+	var token MirrorNodeToken
+	if tokenID != "" {
+		id, err := a.tokenIDFromString(tokenID)
+		if err != nil {
+			check.Detail = err.Error()
+			return token, check
+		}
+		if err := a.mirrorGet(ctx, "/tokens/"+id.String(), &token); err != nil {
+			check.Detail = err.Error()
+			return token, check
+		}
+		if token.Name != expectedName {
+			check.Detail = fmt.Sprintf("%s is %q, not the .%s collection %q", a.displayID(token.TokenID), token.Name, zone, expectedName)
+			return token, check
+		}
+	} else {
+		var response MirrorNodeTokensResponse
+		path := "/tokens?type=NON_FUNGIBLE_UNIQUE&limit=100&name=" + url.QueryEscape(expectedName)
+		if err := a.mirrorGet(ctx, path, &response); err != nil {
+			check.Detail = err.Error()
+			return token, check
+		}
+		// The name filter matches partially, and anybody can create a token with the same name:
+		// the oldest exact match is the collection created by the registry
+		var matches []MirrorNodeToken
+		for _, t := range response.Tokens {
+			if t.Name == expectedName {
+				matches = append(matches, t)
+			}
+		}
+		if len(matches) == 0 {
+			check.Detail = fmt.Sprintf("no token named %q", expectedName)
+			return token, check
+		}
+		token = matches[0]
+		for _, t := range matches[1:] {
+			if t.CreatedTimestamp < token.CreatedTimestamp {
+				token = t
+			}
+		}
+		if len(matches) > 1 {
+			check.Detail = fmt.Sprintf("%d tokens named %q, using the oldest; pass the token ID to verify another. ", len(matches), expectedName)
+		}
+	}
+
+	check.OK = true
+	check.Detail += fmt.Sprintf("%s %q (%s), treasury %s", a.displayID(token.TokenID), token.Name, token.Symbol, a.displayID(token.TreasuryAccountID))
+	return token, check
+}
+
+// verifyMintTransaction fetches the mint transaction of an NFT and checks it succeeded
+func (a *Activities) verifyMintTransaction(ctx context.Context, token MirrorNodeToken, serial int64) (MirrorNodeTransaction, CheckResult) {
+	check := CheckResult{Name: "mint transaction"}
+
+	var history MirrorNodeNFTTransactionsResponse
+	path := fmt.Sprintf("/tokens/%s/nfts/%d/transactions?order=asc", token.TokenID, serial)
+	if err := a.mirrorGet(ctx, path, &history); err != nil {
+		check.Detail = err.Error()
+		return MirrorNodeTransaction{}, check
+	}
+	var mintTxID string
+	for _, tx := range history.Transactions {
+		if tx.Type == "TOKENMINT" {
+			mintTxID = tx.TransactionID
+			break
+		}
+	}
+	if mintTxID == "" {
+		check.Detail = fmt.Sprintf("no TOKENMINT in the history of serial %d", serial)
+		return MirrorNodeTransaction{}, check
+	}
+
+	var response MirrorNodeTransactionsResponse
+	if err := a.mirrorGet(ctx, "/transactions/"+mintTxID, &response); err != nil {
+		check.Detail = err.Error()
+		return MirrorNodeTransaction{}, check
+	}
+	if len(response.Transactions) == 0 {
+		check.Detail = fmt.Sprintf("transaction %s: %v", mintTxID, errMirrorNotFound)
+		return MirrorNodeTransaction{}, check
+	}
+	tx := response.Transactions[0]
+	if tx.Result != "SUCCESS" {
+		check.Detail = fmt.Sprintf("%s has result %s", tx.TransactionID, tx.Result)
+		return tx, check
+	}
+	check.OK = true
+	check.Detail = fmt.Sprintf("%s reached consensus at %s", tx.TransactionID, tx.ConsensusTimestamp)
+	return tx, check
+}
+
+// verifyMintedByTreasury checks the mint was paid for by the treasury of the collection, i.e. the registry operator
+func (a *Activities) verifyMintedByTreasury(token MirrorNodeToken, tx MirrorNodeTransaction) CheckResult {
+	check := CheckResult{Name: "minted by treasury"}
+	payer, _, found := strings.Cut(tx.TransactionID, "-")
+	if !found {
+		check.Detail = fmt.Sprintf("malformed transaction ID %q", tx.TransactionID)
+		return check
+	}
+	if payer != token.TreasuryAccountID {
+		check.Detail = fmt.Sprintf("paid by %s, the treasury is %s", a.displayID(payer), a.displayID(token.TreasuryAccountID))
+		return check
+	}
+	check.OK = true
+	check.Detail = a.displayID(payer)
+	return check
+}
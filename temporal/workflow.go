@@ -1,33 +1,141 @@
 package temporal
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"time"
 
 	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/zonepolicy"
 )
 
-// IngestFileWorkflow orchestrates the domain ingestion and minting process
-func IngestFileWorkflow(ctx workflow.Context, filePath string) error {
+// IngestFileWorkflow orchestrates the domain ingestion and minting process.
+// Domains are grouped by zone and each zone is processed by a ProcessZoneWorkflow child,
+// on the zone's own task queue when the zone is sharded.
+func IngestFileWorkflow(ctx workflow.Context, req IngestRequest) (err error) {
 	logger := workflow.GetLogger(ctx)
+	filePath := req.FilePath
 	logger.Info("Starting domain ingestion workflow", "filePath", filePath)
 
-	// Set up activity options
-	activityOptions := workflow.ActivityOptions{
-		StartToCloseTimeout: 10 * time.Minute,
-		RetryPolicy: &temporal.RetryPolicy{
-			InitialInterval:    time.Second,
-			BackoffCoefficient: 2.0,
-			MaximumInterval:    time.Minute,
-			MaximumAttempts:    3,
-		},
+	ctx = workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	// Expose the progress of the run, e.g. for `wfstart tail`
+	progress := IngestProgress{
+		FilePath:      filePath,
+		Stage:         StageReading,
+		StartedAt:     workflow.Now(ctx),
+		BudgetTinybar: req.BudgetTinybar,
 	}
-	ctx = workflow.WithActivityOptions(ctx, activityOptions)
+	if err := workflow.SetQueryHandler(ctx, ProgressQuery, func() (IngestProgress, error) {
+		return progress, nil
+	}); err != nil {
+		return err
+	}
+	info := workflow.GetInfo(ctx)
+	record := IngestedFileInfo{
+		ContentHash: req.ContentHash,
+		FilePath:    filePath,
+		WorkflowID:  info.WorkflowExecution.ID,
+		RunID:       info.WorkflowExecution.RunID,
+		Outcome:     IngestOutcomeRunning,
+	}
+	recorded := false
+
+	// Whatever the outcome, report on the run and record it in the ingest ledger.
+	// This also runs when the workflow was canceled, hence the disconnected context.
+	defer func() {
+		outcome, stage := IngestOutcomeCompleted, StageCompleted
+		switch {
+		case temporal.IsCanceledError(err):
+			outcome, stage = IngestOutcomeCanceled, StageCanceled
+		case err != nil:
+			outcome, stage = IngestOutcomeFailed, StageFailed
+		}
+		progress.Stage = stage
+
+		cleanupCtx, _ := workflow.NewDisconnectedContext(ctx)
+		report := RunReport{
+			WorkflowID:  record.WorkflowID,
+			RunID:       record.RunID,
+			FilePath:    filePath,
+			ContentHash: req.ContentHash,
+			Outcome:     outcome,
+			StartedAt:   progress.StartedAt,
+			FinishedAt:  workflow.Now(ctx),
+			TotalEvents: progress.TotalEvents,
+			Suppressed:  progress.Suppressed,
+			Zones:       progress.Zones,
+
+			RefusedZones:  progress.RefusedZones,
+			BudgetTinybar: progress.BudgetTinybar,
+			Typosquats:    progress.Typosquats,
+
+			GeneratedLabels: progress.GeneratedLabels,
+		}
+		if err != nil {
+			report.Error = err.Error()
+		}
+		for _, zone := range progress.Zones {
+			if zone.Processed() < zone.Total {
+				report.Partial = true
+			}
+			report.FeesTinybar += zone.FeesTinybar
+		}
+		var written WrittenRunReport
+		if reportErr := workflow.ExecuteActivity(cleanupCtx, "WriteRunReportActivity", report).Get(cleanupCtx, &written); reportErr != nil {
+			logger.Error("Failed to write run report", "error", reportErr)
+		}
+		completed := newRunMarker(cleanupCtx, RunMarkerRunCompleted, req.ContentHash)
+		completed.Outcome, completed.Zones, completed.Total = outcome, len(report.Zones), report.TotalEvents
+		completed.FeesTinybar, completed.ReportHash = report.FeesTinybar, written.Hash
+		for _, zone := range report.Zones {
+			completed.Minted += zone.Minted
+			completed.Burned += zone.Burned
+			completed.Skipped += zone.Skipped
+			completed.Failed += zone.Failed
+		}
+		recordRunMarkers(cleanupCtx, req.HCS.ReceiptsTopic, completed)
+		if req.Notify {
+			notify(cleanupCtx, Notification{Event: NotifyRunCompleted, FilePath: filePath, Report: &report})
+		}
+		if req.EmailReport {
+			if emailErr := workflow.ExecuteActivity(cleanupCtx, "EmailRunReportActivity", report).Get(cleanupCtx, nil); emailErr != nil {
+				logger.Error("Failed to email run report", "error", emailErr)
+			}
+		}
+		if req.ArchiveReport {
+			if archiveErr := workflow.ExecuteActivity(cleanupCtx, "ArchiveRunReportActivity", report.WorkflowID, report.RunID).Get(cleanupCtx, nil); archiveErr != nil {
+				logger.Error("Failed to archive run report", "error", archiveErr)
+			}
+		}
+
+		if recorded {
+			record.Outcome = outcome
+			if recordErr := workflow.ExecuteActivity(cleanupCtx, "RecordIngestActivity", record).Get(cleanupCtx, nil); recordErr != nil {
+				logger.Error("Failed to record ingest outcome", "error", recordErr)
+			}
+		}
+	}()
+
+	// Track the run in the ingest ledger so fully ingested content is not processed again
+	if req.ContentHash != "" {
+		if err := workflow.ExecuteActivity(ctx, "RecordIngestActivity", record).Get(ctx, nil); err != nil {
+			logger.Error("Failed to record ingest start", "error", err)
+			return err
+		}
+		recorded = true
+	}
+	if req.Notify {
+		notify(ctx, Notification{Event: NotifyRunStarted, FilePath: filePath})
+	}
+	recordRunMarkers(ctx, req.HCS.ReceiptsTopic, newRunMarker(ctx, RunMarkerRunStarted, req.ContentHash))
 
 	// Step 1: Read the file
 	var lines []string
-	err := workflow.ExecuteActivity(ctx, "ReadFileActivity", filePath).Get(ctx, &lines)
+	err = workflow.ExecuteActivity(ctx, "ReadFileActivity", filePath).Get(ctx, &lines)
 	if err != nil {
 		logger.Error("Failed to read file", "error", err)
 		return err
@@ -35,6 +143,7 @@ func IngestFileWorkflow(ctx workflow.Context, filePath string) error {
 	logger.Info("Read file successfully", "lineCount", len(lines))
 
 	// Step 2: Parse and filter events
+	progress.Stage = StageParsing
 	var mintingInfos []MintingInfo
 	err = workflow.ExecuteActivity(ctx, "ParseAndFilterEventsActivity", lines).Get(ctx, &mintingInfos)
 	if err != nil {
@@ -42,51 +151,443 @@ func IngestFileWorkflow(ctx workflow.Context, filePath string) error {
 		return err
 	}
 	logger.Info("Parsed events successfully", "eventCount", len(mintingInfos))
+	progress.TotalEvents = len(mintingInfos)
+	if mintingInfos, progress.Suppressed = suppressDuplicates(mintingInfos); progress.Suppressed > 0 {
+		logger.Warn("Suppressed events repeated in the file", "count", progress.Suppressed)
+	}
+	if progress.Typosquats = typosquats(mintingInfos); len(progress.Typosquats) > 0 {
+		logger.Warn("Registrations resemble watched brands", "count", len(progress.Typosquats))
+	}
+	if progress.GeneratedLabels = generatedLabels(mintingInfos); len(progress.GeneratedLabels) > 0 {
+		logger.Warn("Labels look algorithmically generated", "count", len(progress.GeneratedLabels))
+	}
+	recordHostEvents(ctx, req.HCS, req.Zones, lines, 1, req.ResumeFrom)
+	recordContactEvents(ctx, req.HCS, req.Zones, lines, 1, req.ResumeFrom)
 
-	// Step 3: Group domains by zone and process each zone
-	zoneGroups := make(map[string][]MintingInfo)
-	for _, info := range mintingInfos {
-		zone := info.Zone
-		zoneGroups[zone] = append(zoneGroups[zone], info)
+	// Step 3: Group domains by zone, before any collection is created for a zone that is not allowed
+	zoneGroups, zones, refused := groupByZone(mintingInfos, req.Zones)
+	for zone, count := range refused {
+		logger.Warn("Refusing domains of a zone not allowed", "zone", zone, "domainCount", count)
 	}
+	progress.RefusedZones = refused
 
 	logger.Info("Grouped domains by zone", "zoneCount", len(zoneGroups))
 
-	// Step 4: Process each zone
-	for zone, domainInfos := range zoneGroups {
-		logger.Info("Processing zone", "zone", zone, "domainCount", len(domainInfos))
+	// Step 4: Stop runs that would mint more domains than the cap, before any collection is created.
+	// The report of the run then lists the zones of the file, none of them processed.
+	parentID := workflow.GetInfo(ctx).WorkflowExecution.ID
+	progress.Zones = make([]ZoneProgress, len(zones))
+	pending := 0
+	for i, zone := range zones {
+		progress.Zones[i] = ZoneProgress{
+			Zone:       zone,
+			WorkflowID: fmt.Sprintf("%s_zone_%s", parentID, zone),
+			Total:      len(zoneGroups[zone]),
+		}
+		for _, info := range zoneGroups[zone] {
+			if info.LineNumber > req.ResumeFrom[zone] {
+				pending++
+			}
+		}
+	}
+	if err := checkMintCap(pending, req.MaxMints); err != nil {
+		logger.Error("Refusing to mint more domains than the cap", "pending", pending, "maxMints", req.MaxMints)
+		return err
+	}
+
+	// Step 5: Process each zone in its own child workflow, all zones in parallel.
+	// With a budget, the zones signal their fees and are paused once it is spent.
+	progress.Stage = StageMinting
+	if progress.BudgetTinybar, err = resolveBudget(ctx, req.BudgetTinybar, req.BudgetUSD); err != nil {
+		logger.Error("Failed to convert the budget of the run", "error", err)
+		return err
+	}
+	if progress.BudgetTinybar > 0 {
+		watchBudget(ctx, &progress)
+	}
+	failed, alerted := 0, false // Alert once when the failures of the finished zones reach the threshold
+	started := make([]RunMarker, len(zones))
+	for i := range zones {
+		started[i] = zoneRunMarker(ctx, RunMarkerZoneStarted, req.ContentHash, progress.Zones[i])
+	}
+	recordRunMarkers(ctx, req.HCS.ReceiptsTopic, started...)
+	children := make([]workflow.ChildWorkflowFuture, len(zones))
+	for i, zone := range zones {
+		childOptions := workflow.ChildWorkflowOptions{
+			WorkflowID: progress.Zones[i].WorkflowID,
+			// On cancellation, wait for the zone to finish its in-flight mint and report its progress
+			WaitForCancellation: true,
+		}
+		if queue, sharded := req.ZoneTaskQueues[zone]; sharded {
+			childOptions.TaskQueue = queue
+		}
+		logger.Info("Processing zone", "zone", zone, "domainCount", len(zoneGroups[zone]), "taskQueue", childOptions.TaskQueue)
+		zoneBatch := ZoneBatch{
+			Zone:            zone,
+			Domains:         zoneGroups[zone],
+			ContentHash:     req.ContentHash,
+			ResumeAfterLine: req.ResumeFrom[zone],
+			ReportFees:      progress.BudgetTinybar > 0,
+			EscalateAfter:   req.EscalateAfter,
 
-		// Look up or create the NFT collection for this zone
-		var zoneCollection ZoneCollectionInfo
-		err = workflow.ExecuteActivity(ctx, "LookupOrCreateZoneCollectionActivity", zone).Get(ctx, &zoneCollection)
+			VisibilityTimeout: req.VisibilityTimeout,
+		}
+		zoneBatchTopics(&zoneBatch, req.HCS)
+		childCtx := workflow.WithChildOptions(ctx, childOptions)
+		children[i] = workflow.ExecuteChildWorkflow(childCtx, ProcessZoneWorkflow, zoneBatch)
+	}
+	for i, child := range children {
+		var zoneProgress ZoneProgress
+		err := child.Get(ctx, &zoneProgress)
+		var canceledErr *temporal.CanceledError
+		if errors.As(err, &canceledErr) && canceledErr.HasDetails() {
+			_ = canceledErr.Details(&zoneProgress)
+		}
+		if zoneProgress.Zone != "" {
+			progress.Zones[i] = zoneProgress
+		}
+		progress.Zones[i].Done = true
+		failed += progress.Zones[i].Failed
+		recordRunMarkers(ctx, req.HCS.ReceiptsTopic, zoneRunMarker(ctx, RunMarkerZoneCompleted, req.ContentHash, progress.Zones[i]))
+		if req.Notify && req.FailureAlert > 0 && failed >= req.FailureAlert && !alerted {
+			notify(ctx, Notification{Event: NotifyFailureAlert, FilePath: filePath, Failed: failed, Threshold: req.FailureAlert})
+			alerted = true
+		}
 		if err != nil {
-			logger.Error("Failed to lookup/create zone collection", "zone", zone, "error", err)
+			logger.Error("Failed to process zone", "zone", zones[i], "error", err)
 			continue // Continue with other zones
 		}
+	}
 
-		// Mint NFTs for all domains in this zone
-		for _, info := range domainInfos {
-			err = workflow.ExecuteActivity(ctx, "MintNFTActivity", info, zoneCollection).Get(ctx, nil)
-			if err != nil {
-				logger.Error("Failed to mint NFT", "domain", info.DomainName, "zone", zone, "error", err)
-				// Continue with other domains instead of failing the entire workflow
-				continue
-			}
-			logger.Info("Successfully minted NFT", "domain", info.DomainName, "zone", zone)
-		}
+	if ctx.Err() != nil {
+		logger.Info("Domain ingestion workflow canceled", "totalZones", len(zoneGroups))
+		return temporal.NewCanceledError()
 	}
 
 	logger.Info("Completed domain ingestion workflow", "totalZones", len(zoneGroups))
 	return nil
 }
 
-// HCSDemoWorkflow demonstrates HCS functionality with topic creation, messaging, and subscription
-func HCSDemoWorkflow(ctx workflow.Context, topicName string) error {
+// ProcessZoneWorkflow looks up or creates the NFT collection of a zone and mints all of its domains.
+// It runs on the task queue chosen by the parent, so sharded zones are served by their own workers.
+// When canceled, the in-flight mint is finished and checkpointed before the workflow stops,
+// returning its progress in the details of the CanceledError.
+// Each mint is published as a receipt to HCS, or, for anchored zones, the Merkle root over the events
+// of the whole batch is anchored once all domains are processed.
+// The policy of the zone (ZONE_POLICY_FILE) decides whether the event of a domain is minted, burned or
+// ignored, paces the mints and burns, splits the anchored batch, and picks the metadata store.
+// The line of the ingest run is checkpointed every cursorCheckpointLines domains, and the workflow continues as
+// new every zoneDomainsPerRun domains, or sooner when Temporal suggests it, to keep its history bounded.
+func ProcessZoneWorkflow(ctx workflow.Context, batch ZoneBatch) (ZoneProgress, error) {
 	logger := workflow.GetLogger(ctx)
-	logger.Info("Starting HCS demo workflow", "topicName", topicName)
+	zone := batch.Zone
+	continued := batch.Continued
+	if continued == nil {
+		continued = &ZoneContinuation{}
+	}
+	start := continued.Next
 
-	// Set up activity options
-	activityOptions := workflow.ActivityOptions{
+	activityOptions := defaultActivityOptions()
+	ctx = workflow.WithActivityOptions(ctx, activityOptions)
+
+	// Mints heartbeat so a draining or crashed worker is detected quickly and the mint is retried elsewhere.
+	// Mints and checkpoints are not interrupted by a cancellation, which is only honored between domains.
+	mintOptions := activityOptions
+	mintOptions.HeartbeatTimeout = 2 * time.Minute
+	uncancelableCtx, _ := workflow.NewDisconnectedContext(ctx)
+	mintCtx := workflow.WithActivityOptions(uncancelableCtx, mintOptions)
+
+	progress := ZoneProgress{
+		Zone:       zone,
+		WorkflowID: workflow.GetInfo(ctx).WorkflowExecution.ID,
+		Total:      len(batch.Domains),
+	}
+	if batch.Continued != nil {
+		progress = batch.Continued.Progress
+	}
+	if err := workflow.SetQueryHandler(ctx, ProgressQuery, func() (ZoneProgress, error) {
+		return progress, nil
+	}); err != nil {
+		return progress, err
+	}
+	defer func() { progress.Done = true }()
+	if batch.ReportFees {
+		followPauses(ctx, &progress)
+	}
+
+	var policy zonepolicy.Policy
+	if err := workflow.ExecuteActivity(ctx, "ZonePolicyActivity", zone).Get(ctx, &policy); err != nil {
+		logger.Error("Failed to load zone policy", "zone", zone, "error", err)
+		return progress, err
+	}
+
+	// Look up or create the NFT collection for this zone
+	zoneCollection, err := lookupOrCreateZoneCollection(ctx, zone)
+	if err != nil {
+		logger.Error("Failed to lookup/create zone collection", "zone", zone, "error", err)
+		return progress, err
+	}
+
+	// Look up the latest NFTs of the domains once for the whole batch, the mints then only search the collection
+	// when retried. Without the check every mint searches the collection for its domain, and registrations of
+	// domains the collection holds an NFT of are duplicates, whatever the reregistration policy.
+	var latest map[string]LatestNFT
+	var toMint []string
+	for _, info := range batch.Domains[start:] {
+		if info.LineNumber > batch.ResumeAfterLine && policy.Action(info.Action) == zonepolicy.ActionMint {
+			toMint = append(toMint, info.DomainName)
+		}
+	}
+	prechecked := false
+	if len(toMint) > 0 {
+		if err := workflow.ExecuteActivity(ctx, "LatestNFTsActivity", zoneCollection, toMint).Get(ctx, &latest); err != nil {
+			logger.Warn("Failed to check the collection for minted domains, checking each domain", "zone", zone, "error", err)
+		} else {
+			prechecked = true
+		}
+	}
+	if latest == nil {
+		latest = make(map[string]LatestNFT)
+	}
+	// The mirror node may not show the NFTs minted and burned by the earlier runs yet
+	maps.Copy(latest, continued.Latest)
+
+	// Checkpoint the line so a failed or canceled run can be resumed after it. The domains processed since the
+	// last checkpoint are processed again by the resumed run, which finds them minted.
+	checkpointLine, sinceCheckpoint := 0, 0
+	checkpoint := func() {
+		if checkpointLine == 0 {
+			return
+		}
+		cursorCtx := workflow.WithActivityOptions(uncancelableCtx, activityOptions)
+		err := workflow.ExecuteActivity(cursorCtx, "SaveIngestCursorActivity", batch.ContentHash, zone, checkpointLine).Get(cursorCtx, nil)
+		if err != nil {
+			logger.Warn("Failed to save ingest cursor", "zone", zone, "line", checkpointLine, "error", err)
+		}
+		checkpointLine, sinceCheckpoint = 0, 0
+	}
+
+	canceled := func() (ZoneProgress, error) {
+		checkpoint()
+		logger.Info("Zone processing canceled", "zone", zone, "processed", progress.Processed(), "total", progress.Total)
+		progress.Done = true
+		return progress, temporal.NewCanceledError(progress)
+	}
+	var interval time.Duration // Between the transactions of the zone, set by the tps of its policy
+	if policy.TransactionsPerSecond > 0 {
+		interval = time.Duration(float64(time.Second) / policy.TransactionsPerSecond)
+	}
+	lastTransaction := continued.LastTransaction
+	consecutiveFailures := continued.ConsecutiveFailures // Escalated once they reach batch.EscalateAfter
+
+	// Mint or burn the NFTs of all domains in this zone, as the policy says
+	for i := start; i < len(batch.Domains); i++ {
+		info := batch.Domains[i]
+		if ctx.Err() != nil {
+			return canceled()
+		}
+		if i-start == zoneDomainsPerRun || i > start && workflow.GetInfo(ctx).GetContinueAsNewSuggested() {
+			checkpoint()
+			applyPendingPauses(ctx, &progress)
+			batch.Continued = &ZoneContinuation{
+				Next:                i,
+				Progress:            progress,
+				Latest:              make(map[string]LatestNFT),
+				ConsecutiveFailures: consecutiveFailures,
+				LastTransaction:     lastTransaction,
+			}
+			for _, left := range batch.Domains[i:] {
+				if nft, ok := latest[left.DomainName]; ok {
+					batch.Continued.Latest[left.DomainName] = nft
+				}
+			}
+			logger.Info("Continuing zone as new", "zone", zone, "next", i, "processed", progress.Processed())
+			return progress, workflow.NewContinueAsNewError(ctx, ProcessZoneWorkflow, batch)
+		}
+		resumed := info.LineNumber <= batch.ResumeAfterLine
+		action := policy.Action(info.Action)
+		var reregistration Reregistration
+		if nft, ok := latest[info.DomainName]; ok && action == zonepolicy.ActionMint {
+			reregistration = Reregister(policy.ReregistrationMode(), nft, info.EventHash)
+		}
+		switch {
+		case resumed:
+			progress.Skipped++ // Processed by the run being resumed
+		case action == zonepolicy.ActionIgnore:
+			progress.Ignored++
+		case reregistration.Duplicate:
+			logger.Info("Domain already minted, skipping", "domain", info.DomainName, "zone", zone, "serial", latest[info.DomainName].SerialNumber)
+			progress.Skipped++
+			progress.Duplicates++
+		default:
+			if progress.Paused {
+				logger.Info("Zone paused, the budget of the run is spent", "zone", zone)
+				if err := workflow.Await(ctx, func() bool { return !progress.Paused }); err != nil {
+					return canceled()
+				}
+			}
+			if wait := lastTransaction.Add(interval).Sub(workflow.Now(ctx)); interval > 0 && wait > 0 {
+				if err := workflow.Sleep(ctx, wait); err != nil {
+					return canceled()
+				}
+			}
+			lastTransaction = workflow.Now(ctx)
+			info.MetadataStore = policy.MetadataStore
+			info.Prechecked = prechecked
+			info.RunID = ingestRun(ctx).RunID
+			info.Previous = reregistration.Previous
+			var fee int64
+			failed := progress.Failed
+			if action == zonepolicy.ActionBurn || reregistration.Burn {
+				// Burned before the domain is minted again when the policy remints re-registered domains
+				burn := burnDomain(mintCtx, info, zoneCollection, &progress)
+				fee = burn.FeeTinybar
+				if burn.TransactionID != "" {
+					// May be registered again later in the batch
+					latest[info.DomainName] = LatestNFT{TokenID: burn.TokenID, SerialNumber: burn.SerialNumber, EventHash: latest[info.DomainName].EventHash, Burned: true}
+				}
+				if action == zonepolicy.ActionMint && progress.Failed > failed {
+					logger.Warn("Domain registered again not minted, the NFT of its earlier registration could not be burned", "domain", info.DomainName, "zone", zone)
+				}
+			}
+			if action == zonepolicy.ActionMint && progress.Failed == failed {
+				mint := mintDomain(mintCtx, uncancelableCtx, batch, info, zoneCollection, &progress)
+				fee += mint.FeeTinybar
+				if mint.SerialNumber > 0 {
+					latest[info.DomainName] = LatestNFT{TokenID: mint.TokenID, SerialNumber: mint.SerialNumber, EventHash: info.EventHash}
+				}
+			}
+			if batch.ReportFees {
+				reportFee(uncancelableCtx, fee)
+			}
+			if progress.Failed > failed {
+				consecutiveFailures++
+			} else {
+				consecutiveFailures = 0
+			}
+		}
+
+		if batch.ContentHash != "" && !resumed {
+			checkpointLine = info.LineNumber
+			if sinceCheckpoint++; sinceCheckpoint == cursorCheckpointLines {
+				checkpoint()
+			}
+		}
+
+		// Anchor every full batch of the policy
+		if batch.AnchorTopic != "" && policy.BatchSize > 0 && (i+1)%policy.BatchSize == 0 {
+			n := (i + 1) / policy.BatchSize
+			anchorBatch(ctx, batch.AnchorTopic, fmt.Sprintf("%s_batch_%d", progress.WorkflowID, n), zone, batch.Domains[i+1-policy.BatchSize:i+1])
+		}
+
+		// Page the operators and wait for them when the zone keeps failing, instead of failing every domain left
+		if batch.EscalateAfter > 0 && consecutiveFailures >= batch.EscalateAfter {
+			if err := escalate(ctx, &progress, consecutiveFailures); err != nil {
+				return canceled()
+			}
+			consecutiveFailures = 0
+		}
+	}
+
+	checkpoint()
+
+	// Anchor the Merkle root of the batch, or of its last partial batch, a failure does not undo the mints
+	if batch.AnchorTopic != "" {
+		if policy.BatchSize == 0 {
+			anchorBatch(ctx, batch.AnchorTopic, progress.WorkflowID, zone, batch.Domains)
+		} else if rest := len(batch.Domains) % policy.BatchSize; rest > 0 {
+			n := len(batch.Domains)/policy.BatchSize + 1
+			anchorBatch(ctx, batch.AnchorTopic, fmt.Sprintf("%s_batch_%d", progress.WorkflowID, n), zone, batch.Domains[len(batch.Domains)-rest:])
+		}
+	}
+	progress.Done = true
+	return progress, nil
+}
+
+// mintDomain mints the NFT of a domain and publishes its receipt, recording the outcome in the progress.
+// It returns the outcome of the mint, whose serial number is zero if the mint failed.
+func mintDomain(mintCtx, uncancelableCtx workflow.Context, batch ZoneBatch, info MintingInfo, zoneCollection ZoneCollectionInfo, progress *ZoneProgress) MintResult {
+	logger := workflow.GetLogger(mintCtx)
+	zone := batch.Zone
+	var result MintResult
+	err := workflow.ExecuteActivity(mintCtx, "MintNFTActivity", info, zoneCollection).Get(mintCtx, &result)
+	if err == nil && result.Pending != nil {
+		// Signing offline, the mint is submitted once signed
+		var submitted SubmittedTransaction
+		if submitted, err = submitSigned(mintCtx, *result.Pending); err == nil {
+			result = *submitted.Mint
+		}
+	}
+	switch {
+	case err != nil:
+		logger.Error("Failed to mint NFT", "domain", info.DomainName, "zone", zone, "error", err)
+		progress.addFailure(MintFailure{Domain: info.DomainName, Zone: zone, Error: err.Error(), Time: workflow.Now(mintCtx)})
+		quarantineFailure(uncancelableCtx, info, QuarantineMintFailed, err)
+		// Continue with other domains instead of failing the entire zone
+	case result.Duplicate:
+		progress.Skipped++
+		progress.Duplicates++
+	default:
+		logger.Info("Successfully minted NFT", "domain", info.DomainName, "zone", zone)
+		progress.Minted++
+		progress.FeesTinybar += result.FeeTinybar
+		if batch.VisibilityTimeout > 0 && !verifyMintVisible(uncancelableCtx, result, batch.VisibilityTimeout) {
+			progress.NotVisible++
+		}
+
+		// Publish the proof of the mint, a failure does not undo the mint
+		if batch.ReceiptsTopic != "" {
+			receiptCtx := workflow.WithActivityOptions(uncancelableCtx, defaultActivityOptions())
+			err = workflow.ExecuteActivity(receiptCtx, "PublishMintReceiptActivity", batch.ReceiptsTopic, zone, result).Get(receiptCtx, nil)
+			if err != nil {
+				logger.Warn("Failed to publish mint receipt", "domain", info.DomainName, "zone", zone, "error", err)
+			}
+		}
+	}
+	if err != nil {
+		return MintResult{}
+	}
+	return result
+}
+
+// burnDomain burns the NFT of a domain, recording the outcome in the progress. It returns the outcome of the
+// burn, without transaction ID if nothing was burned.
+func burnDomain(mintCtx workflow.Context, info MintingInfo, zoneCollection ZoneCollectionInfo, progress *ZoneProgress) BurnResult {
+	logger := workflow.GetLogger(mintCtx)
+	var result BurnResult
+	err := workflow.ExecuteActivity(mintCtx, "BurnNFTActivity", info, zoneCollection).Get(mintCtx, &result)
+	switch {
+	case err != nil:
+		logger.Error("Failed to burn NFT", "domain", info.DomainName, "zone", info.Zone, "error", err)
+		progress.addFailure(MintFailure{Domain: info.DomainName, Zone: info.Zone.String(), Error: err.Error(), Time: workflow.Now(mintCtx)})
+		quarantineFailure(mintCtx, info, QuarantineBurnFailed, err)
+	case result.NotMinted:
+		progress.Skipped++
+	default:
+		logger.Info("Successfully burned NFT", "domain", info.DomainName, "zone", info.Zone, "serial", result.SerialNumber)
+		progress.Burned++
+		progress.FeesTinybar += result.FeeTinybar
+	}
+	if err != nil {
+		return BurnResult{}
+	}
+	return result
+}
+
+// anchorBatch anchors the Merkle root over the events of the given domains, a failure does not undo the mints
+func anchorBatch(ctx workflow.Context, topic, batchID, zone string, domains []MintingInfo) {
+	anchor, err := NewBatchAnchor(batchID, zone, domains)
+	if err == nil {
+		err = workflow.ExecuteActivity(ctx, "AnchorBatchActivity", topic, anchor).Get(ctx, nil)
+	}
+	if err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to anchor batch", "zone", zone, "batch", batchID, "error", err)
+	}
+}
+
+// defaultActivityOptions returns the activity options shared by all workflows
+func defaultActivityOptions() workflow.ActivityOptions {
+	return workflow.ActivityOptions{
 		StartToCloseTimeout: 10 * time.Minute,
 		RetryPolicy: &temporal.RetryPolicy{
 			InitialInterval:    time.Second,
@@ -95,7 +596,15 @@ func HCSDemoWorkflow(ctx workflow.Context, topicName string) error {
 			MaximumAttempts:    3,
 		},
 	}
-	ctx = workflow.WithActivityOptions(ctx, activityOptions)
+}
+
+// HCSDemoWorkflow demonstrates HCS functionality with topic creation, messaging, and reading messages back
+func HCSDemoWorkflow(ctx workflow.Context, topicName string) error {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting HCS demo workflow", "topicName", topicName)
+
+	// Set up activity options
+	ctx = workflow.WithActivityOptions(ctx, defaultActivityOptions())
 
 	// Step 1: Create or lookup a topic
 	var topicInfo TopicInfo
@@ -116,7 +625,7 @@ func HCSDemoWorkflow(ctx workflow.Context, topicName string) error {
 		fmt.Sprintf("HCS Demo started for topic: %s", topicName),
 		fmt.Sprintf("Topic ID: %s", topicInfo.TopicID),
 		"This is a test message for domain event streaming",
-		fmt.Sprintf("Demo completed at: %s", time.Now().Format(time.RFC3339)),
+		fmt.Sprintf("Demo completed at: %s", workflow.Now(ctx).Format(time.RFC3339)),
 	}
 
 	var sentMessages []TopicMessage
@@ -134,20 +643,30 @@ func HCSDemoWorkflow(ctx workflow.Context, topicName string) error {
 		workflow.Sleep(ctx, 2*time.Second)
 	}
 
-	// Step 3: Subscribe and read back the messages
-	subscription := TopicSubscriptionInfo{
-		TopicID:   topicInfo.TopicID,
-		StartTime: time.Now().Add(-5 * time.Minute), // Start from 5 minutes ago
-		Limit:     10,                               // Read up to 10 messages
-	}
-
+	// Step 3: Read back the messages that were sent. The range is closed by their sequence numbers,
+	// so the read returns the same messages on every attempt and replay.
 	var receivedMessages []TopicMessage
-	err = workflow.ExecuteActivity(ctx, "SubscribeToTopicActivity", subscription).Get(ctx, &receivedMessages)
-	if err != nil {
-		logger.Error("Failed to subscribe to topic", "error", err)
-		// Don't fail the workflow - subscription issues are not critical
-	} else {
-		logger.Info("Subscription completed", "messagesReceived", len(receivedMessages))
+	if len(sentMessages) > 0 {
+		read := TopicReadRequest{
+			TopicID:      topicInfo.TopicID,
+			FromSequence: sentMessages[0].SequenceNumber,
+			ToSequence:   sentMessages[len(sentMessages)-1].SequenceNumber,
+		}
+
+		// The read fails until the mirror node has ingested the last message, which takes a few seconds
+		readOptions := defaultActivityOptions()
+		readOptions.RetryPolicy.InitialInterval = 2 * time.Second
+		readOptions.RetryPolicy.MaximumInterval = 10 * time.Second
+		readOptions.RetryPolicy.MaximumAttempts = 10
+		readCtx := workflow.WithActivityOptions(ctx, readOptions)
+
+		err = workflow.ExecuteActivity(readCtx, "ReadTopicMessagesActivity", read).Get(ctx, &receivedMessages)
+		if err != nil {
+			logger.Error("Failed to read topic messages", "error", err)
+			// Don't fail the workflow - reading back is not critical
+		} else {
+			logger.Info("Read topic messages", "messagesReceived", len(receivedMessages))
+		}
 	}
 
 	// Step 4: Show registry status
@@ -0,0 +1,286 @@
+package temporal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/config"
+)
+
+// IngestWorkflowIDPrefix prefixes the IDs of all IngestFileWorkflow executions
+const IngestWorkflowIDPrefix = "domain-ingest-workflow_"
+
+// ImportWorkflowIDPrefix prefixes the IDs of all ImportDomainListWorkflow executions
+const ImportWorkflowIDPrefix = "domain-import-workflow_"
+
+// ClaimWorkflowIDPrefix prefixes the IDs of all ClaimDomainWorkflow executions
+const ClaimWorkflowIDPrefix = "domain-claim-workflow_"
+
+// BrandingWorkflowIDPrefix prefixes the IDs of all BrandCollectionWorkflow executions
+const BrandingWorkflowIDPrefix = "collection-branding-workflow_"
+
+// SupplyKeyWorkflowIDPrefix prefixes the IDs of all RotateSupplyKeyWorkflow executions
+const SupplyKeyWorkflowIDPrefix = "collection-supply-key-workflow_"
+
+// MigrationWorkflowIDPrefix prefixes the IDs of all MigrateCollectionWorkflow executions
+const MigrationWorkflowIDPrefix = "collection-migration-workflow_"
+
+// TopicConsumerWorkflowIDPrefix prefixes the IDs of all ConsumeTopicWorkflow executions
+const TopicConsumerWorkflowIDPrefix = "topic-consumer-workflow_"
+
+// SnapshotWorkflowIDPrefix prefixes the IDs of all SnapshotWorkflow executions
+const SnapshotWorkflowIDPrefix = "ledger-snapshot-workflow_"
+
+// IngestFilesWorkflowIDPrefix prefixes the IDs of all IngestFilesWorkflow executions
+const IngestFilesWorkflowIDPrefix = "domain-ingest-files-workflow_"
+
+// IngestBatchWorkflowIDPrefix prefixes the IDs of all IngestBatchWorkflow executions
+const IngestBatchWorkflowIDPrefix = "domain-ingest-batch-workflow_"
+
+// FollowWorkflowIDPrefix prefixes the IDs of all FollowFileWorkflow executions
+const FollowWorkflowIDPrefix = "domain-follow-workflow_"
+
+// BackfillWorkflowIDPrefix prefixes the IDs of all BackfillWorkflow executions
+const BackfillWorkflowIDPrefix = "archive-backfill-workflow_"
+
+// IngestLedgerLookupWorkflowIDPrefix prefixes the IDs of all LookupIngestRunWorkflow executions
+const IngestLedgerLookupWorkflowIDPrefix = "ingest-ledger-lookup-workflow_"
+
+// HashFile returns the hex encoded SHA-256 digest of a file's content
+func HashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", fmt.Errorf("failed to hash file: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ScopedWorkflowID prefixes a workflow ID with the scope of the deployment (TemporalConfig.WorkflowIDScope), so
+// the registries sharing a Temporal namespace never start, or dedupe against, each other's workflows. Workflows
+// starting ingests scope them alike, see IngestFilesRequest.
+func ScopedWorkflowID(scope, id string) string {
+	if scope == "" {
+		return id
+	}
+	return scope + "/" + id
+}
+
+// IngestWorkflowID derives the ingest workflow ID from the content hash of the ingested file.
+// Identical content always maps to the same ID, regardless of the path or name of the file.
+func IngestWorkflowID(contentHash string) string {
+	return IngestWorkflowIDPrefix + contentHash
+}
+
+// IngestWorkflowOptions returns the start options for ingesting content with the given hash.
+// A workflow ID that already completed successfully cannot be reused, so the same content is never
+// ingested twice; a failed, canceled or terminated ingest of the same content may be started again.
+func IngestWorkflowOptions(t config.TemporalConfig, contentHash string) client.StartWorkflowOptions {
+	return client.StartWorkflowOptions{
+		ID:                                       ScopedWorkflowID(t.WorkflowIDScope, IngestWorkflowID(contentHash)),
+		TaskQueue:                                t.TaskQueue,
+		WorkflowIDReusePolicy:                    enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE_FAILED_ONLY,
+		WorkflowExecutionErrorWhenAlreadyStarted: true,
+	}
+}
+
+// ForcedIngestWorkflowID returns the ID of a forced re-ingest of content, with a timestamp suffix so it
+// never collides with earlier runs of the same content
+func ForcedIngestWorkflowID(contentHash string, now time.Time) string {
+	return fmt.Sprintf("%s_forced_%s", IngestWorkflowID(contentHash), now.UTC().Format("20060102T150405Z"))
+}
+
+// ForcedIngestWorkflowOptions returns start options that re-ingest content which was already ingested.
+func ForcedIngestWorkflowOptions(t config.TemporalConfig, contentHash string, now time.Time) client.StartWorkflowOptions {
+	options := IngestWorkflowOptions(t, contentHash)
+	options.ID = ScopedWorkflowID(t.WorkflowIDScope, ForcedIngestWorkflowID(contentHash, now))
+	options.WorkflowIDReusePolicy = enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE
+	return options
+}
+
+// ImportWorkflowOptions returns the start options for importing a domain list with the given content hash.
+// Like an ingest, a list that was imported successfully is not imported again, a failed import may be restarted.
+func ImportWorkflowOptions(t config.TemporalConfig, contentHash string) client.StartWorkflowOptions {
+	options := IngestWorkflowOptions(t, contentHash)
+	options.ID = ScopedWorkflowID(t.WorkflowIDScope, ImportWorkflowIDPrefix+contentHash)
+	return options
+}
+
+// IngestFilesWorkflowOptions returns the start options for ingesting a set of files with the given content hashes.
+// The workflow ID is derived from the set, in any order, so the same set is ingested by one workflow at a time;
+// it may be started again, the files that were fully ingested are then skipped.
+func IngestFilesWorkflowOptions(t config.TemporalConfig, contentHashes []string) client.StartWorkflowOptions {
+	sorted := append([]string(nil), contentHashes...)
+	sort.Strings(sorted)
+	h := sha256.New()
+	for _, contentHash := range sorted {
+		io.WriteString(h, contentHash+"\n")
+	}
+	return client.StartWorkflowOptions{
+		ID:                                       ScopedWorkflowID(t.WorkflowIDScope, IngestFilesWorkflowIDPrefix+hex.EncodeToString(h.Sum(nil))),
+		TaskQueue:                                t.TaskQueue,
+		WorkflowIDReusePolicy:                    enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE,
+		WorkflowExecutionErrorWhenAlreadyStarted: true,
+	}
+}
+
+// ClaimWorkflowID returns the ID of the claim workflow of a domain, so a domain has at most one claim in progress
+func ClaimWorkflowID(domainName string) string {
+	return ClaimWorkflowIDPrefix + domainName
+}
+
+// ClaimWorkflowOptions returns the start options for claiming a domain.
+// A domain that was claimed successfully cannot be claimed again, a failed claim may be started again.
+func ClaimWorkflowOptions(t config.TemporalConfig, domainName string) client.StartWorkflowOptions {
+	return client.StartWorkflowOptions{
+		ID:                                       ScopedWorkflowID(t.WorkflowIDScope, ClaimWorkflowID(domainName)),
+		TaskQueue:                                t.TaskQueue,
+		WorkflowIDReusePolicy:                    enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE_FAILED_ONLY,
+		WorkflowExecutionErrorWhenAlreadyStarted: true,
+	}
+}
+
+// BrandingWorkflowOptions returns the start options for branding the collection of a zone.
+// A zone collection is branded by one workflow at a time, and may be branded again afterwards.
+func BrandingWorkflowOptions(t config.TemporalConfig, zone string) client.StartWorkflowOptions {
+	return client.StartWorkflowOptions{
+		ID:                                       ScopedWorkflowID(t.WorkflowIDScope, BrandingWorkflowIDPrefix+zone),
+		TaskQueue:                                t.TaskQueue,
+		WorkflowIDReusePolicy:                    enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE,
+		WorkflowExecutionErrorWhenAlreadyStarted: true,
+	}
+}
+
+// SupplyKeyWorkflowOptions returns the start options for updating the supply key of the collection of a zone.
+// The key of a zone collection is updated by one workflow at a time, and may be updated again afterwards.
+func SupplyKeyWorkflowOptions(t config.TemporalConfig, zone string) client.StartWorkflowOptions {
+	return client.StartWorkflowOptions{
+		ID:                                       ScopedWorkflowID(t.WorkflowIDScope, SupplyKeyWorkflowIDPrefix+zone),
+		TaskQueue:                                t.TaskQueue,
+		WorkflowIDReusePolicy:                    enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE,
+		WorkflowExecutionErrorWhenAlreadyStarted: true,
+	}
+}
+
+// MigrationWorkflowOptions returns the start options for migrating the collection of a zone.
+// A zone is migrated by one workflow at a time, and may be migrated again afterwards.
+func MigrationWorkflowOptions(t config.TemporalConfig, zone string) client.StartWorkflowOptions {
+	return client.StartWorkflowOptions{
+		ID:                                       ScopedWorkflowID(t.WorkflowIDScope, MigrationWorkflowIDPrefix+zone),
+		TaskQueue:                                t.TaskQueue,
+		WorkflowIDReusePolicy:                    enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE,
+		WorkflowExecutionErrorWhenAlreadyStarted: true,
+	}
+}
+
+// TopicConsumerWorkflowOptions returns the start options for consuming a topic as a consumer group.
+// A group consumes a topic with one workflow at a time, so its offset has a single writer.
+func TopicConsumerWorkflowOptions(t config.TemporalConfig, group, topicID string) client.StartWorkflowOptions {
+	return client.StartWorkflowOptions{
+		ID:                                       ScopedWorkflowID(t.WorkflowIDScope, TopicConsumerWorkflowIDPrefix+group+"_"+topicID),
+		TaskQueue:                                t.TaskQueue,
+		WorkflowIDReusePolicy:                    enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE,
+		WorkflowExecutionErrorWhenAlreadyStarted: true,
+	}
+}
+
+// SnapshotWorkflowOptions returns the start options for a snapshot of the ledger at a point in time.
+// A snapshot is taken by one workflow at a time, and may be taken again, e.g. of other zones.
+func SnapshotWorkflowOptions(t config.TemporalConfig, at time.Time) client.StartWorkflowOptions {
+	return client.StartWorkflowOptions{
+		ID:                                       ScopedWorkflowID(t.WorkflowIDScope, SnapshotWorkflowIDPrefix+SnapshotID(at)),
+		TaskQueue:                                t.TaskQueue,
+		WorkflowIDReusePolicy:                    enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE,
+		WorkflowExecutionErrorWhenAlreadyStarted: true,
+	}
+}
+
+// BackfillWorkflowOptions returns the start options for backfilling a date range of an archive.
+// A range is backfilled by one workflow at a time; it may be backfilled again, skipping the ingested files.
+func BackfillWorkflowOptions(t config.TemporalConfig, source string, from, to time.Time) client.StartWorkflowOptions {
+	return client.StartWorkflowOptions{
+		ID:                                       ScopedWorkflowID(t.WorkflowIDScope, fmt.Sprintf("%s%s_%s_%s", BackfillWorkflowIDPrefix, source, from.Format(time.DateOnly), to.Format(time.DateOnly))),
+		TaskQueue:                                t.TaskQueue,
+		WorkflowIDReusePolicy:                    enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE,
+		WorkflowExecutionErrorWhenAlreadyStarted: true,
+	}
+}
+
+// IngestBatchWorkflowOptions returns the start options for ingesting the log files of a directory one at a time.
+// A directory is ingested by one batch at a time; it may be ingested again, skipping the ingested files.
+func IngestBatchWorkflowOptions(t config.TemporalConfig, dir string) client.StartWorkflowOptions {
+	return client.StartWorkflowOptions{
+		ID:                                       ScopedWorkflowID(t.WorkflowIDScope, IngestBatchWorkflowIDPrefix+dir),
+		TaskQueue:                                t.TaskQueue,
+		WorkflowIDReusePolicy:                    enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE,
+		WorkflowExecutionErrorWhenAlreadyStarted: true,
+	}
+}
+
+// FollowWorkflowOptions returns the start options for following a growing log file.
+// A file is followed by one workflow at a time, so its lines are not minted twice over; it may be followed again
+// once that workflow was canceled.
+func FollowWorkflowOptions(t config.TemporalConfig, filePath string) client.StartWorkflowOptions {
+	return client.StartWorkflowOptions{
+		ID:                                       ScopedWorkflowID(t.WorkflowIDScope, FollowWorkflowIDPrefix+filePath),
+		TaskQueue:                                t.TaskQueue,
+		WorkflowIDReusePolicy:                    enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE,
+		WorkflowExecutionErrorWhenAlreadyStarted: true,
+	}
+}
+
+// IngestLedgerLookupWorkflowOptions returns the start options for looking up an ingest run in the ingest ledger.
+// Concurrent lookups of the same run share one workflow; a run may be looked up again once it completed.
+func IngestLedgerLookupWorkflowOptions(t config.TemporalConfig, query IngestLedgerQuery) client.StartWorkflowOptions {
+	key := query.ContentHash
+	if query.WorkflowID != "" {
+		key = query.WorkflowID
+	}
+	return client.StartWorkflowOptions{
+		ID:                       ScopedWorkflowID(t.WorkflowIDScope, IngestLedgerLookupWorkflowIDPrefix+key),
+		TaskQueue:                t.TaskQueue,
+		WorkflowIDReusePolicy:    enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE,
+		WorkflowIDConflictPolicy: enumspb.WORKFLOW_ID_CONFLICT_POLICY_USE_EXISTING,
+	}
+}
+
+// QuarantineRetryWorkflowID is the ID of RetryQuarantineWorkflow executions
+const QuarantineRetryWorkflowID = "quarantine-retry-workflow"
+
+// RetryQuarantineWorkflowOptions returns the start options for retrying quarantined events.
+// The quarantine is retried by one workflow at a time, so an event is not minted by two retries at once.
+func RetryQuarantineWorkflowOptions(t config.TemporalConfig) client.StartWorkflowOptions {
+	return client.StartWorkflowOptions{
+		ID:                                       ScopedWorkflowID(t.WorkflowIDScope, QuarantineRetryWorkflowID),
+		TaskQueue:                                t.TaskQueue,
+		WorkflowIDReusePolicy:                    enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE,
+		WorkflowExecutionErrorWhenAlreadyStarted: true,
+	}
+}
+
+// RegistryDigestWorkflowID is the ID of RegistryDigestWorkflow executions
+const RegistryDigestWorkflowID = "registry-digest-workflow"
+
+// RegistryDigestWorkflowOptions returns the start options for digesting the registry state periodically.
+// The registry is digested by one workflow at a time, it may be started again once canceled.
+func RegistryDigestWorkflowOptions(t config.TemporalConfig) client.StartWorkflowOptions {
+	return client.StartWorkflowOptions{
+		ID:                                       ScopedWorkflowID(t.WorkflowIDScope, RegistryDigestWorkflowID),
+		TaskQueue:                                t.TaskQueue,
+		WorkflowIDReusePolicy:                    enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE,
+		WorkflowExecutionErrorWhenAlreadyStarted: true,
+	}
+}
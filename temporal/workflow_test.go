@@ -0,0 +1,85 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/zonepolicy"
+)
+
+// runZoneBatch runs a ProcessZoneWorkflow with stub activities, returning its progress or the batch it continued
+// as new with, and the lines it checkpointed
+func runZoneBatch(t *testing.T, batch ZoneBatch) (ZoneProgress, *ZoneBatch, []int) {
+	t.Helper()
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	var cursors []int
+	env.RegisterActivityWithOptions(func(context.Context, string) (zonepolicy.Policy, error) {
+		return zonepolicy.Policy{}, nil
+	}, activity.RegisterOptions{Name: "ZonePolicyActivity"})
+	env.RegisterActivityWithOptions(func(_ context.Context, zone string) (ZoneCollectionInfo, error) {
+		return ZoneCollectionInfo{TokenID: "0.0.7"}, nil
+	}, activity.RegisterOptions{Name: "LookupOrCreateZoneCollectionActivity"})
+	env.RegisterActivityWithOptions(func(context.Context, ZoneCollectionInfo, []string) (map[string]LatestNFT, error) {
+		return nil, nil // The mirror node shows none of the mints yet
+	}, activity.RegisterOptions{Name: "LatestNFTsActivity"})
+	serial := int64(0)
+	env.RegisterActivityWithOptions(func(_ context.Context, info MintingInfo, collection ZoneCollectionInfo) (MintResult, error) {
+		serial++
+		return MintResult{Domain: info.DomainName, TokenID: collection.TokenID, SerialNumber: serial, TransactionID: "tx"}, nil
+	}, activity.RegisterOptions{Name: "MintNFTActivity"})
+	env.RegisterActivityWithOptions(func(_ context.Context, contentHash, zone string, line int) error {
+		cursors = append(cursors, line)
+		return nil
+	}, activity.RegisterOptions{Name: "SaveIngestCursorActivity"})
+
+	env.ExecuteWorkflow(ProcessZoneWorkflow, batch)
+	require.True(t, env.IsWorkflowCompleted())
+	err := env.GetWorkflowError()
+	var continueAsNew *workflow.ContinueAsNewError
+	if errors.As(err, &continueAsNew) {
+		var next ZoneBatch
+		require.NoError(t, converter.GetDefaultDataConverter().FromPayloads(continueAsNew.Input, &next))
+		return ZoneProgress{}, &next, cursors
+	}
+	require.NoError(t, err)
+	var progress ZoneProgress
+	require.NoError(t, env.GetWorkflowResult(&progress))
+	return progress, nil, cursors
+}
+
+func TestProcessZoneWorkflow_ContinuesAsNew(t *testing.T) {
+	batch := ZoneBatch{Zone: "build", ContentHash: "c0ffee"}
+	for i := range zoneDomainsPerRun + 50 {
+		batch.Domains = append(batch.Domains, MintingInfo{DomainName: fmt.Sprintf("d%d.build", i), EventHash: fmt.Sprintf("h%d", i), LineNumber: i + 1})
+	}
+	// Registered again after the workflow continued as new, before the mirror node shows its first mint
+	batch.Domains[zoneDomainsPerRun+10] = batch.Domains[0]
+	batch.Domains[zoneDomainsPerRun+10].LineNumber = zoneDomainsPerRun + 11
+
+	_, next, cursors := runZoneBatch(t, batch)
+	require.NotNil(t, next, "the workflow continues as new")
+	require.NotNil(t, next.Continued)
+	assert.Equal(t, zoneDomainsPerRun, next.Continued.Next)
+	assert.Equal(t, zoneDomainsPerRun, next.Continued.Progress.Minted)
+	assert.Contains(t, next.Continued.Latest, "d0.build", "the NFTs of the domains left are carried")
+	assert.Len(t, next.Continued.Latest, 1)
+	require.Len(t, cursors, zoneDomainsPerRun/cursorCheckpointLines, "the line is checkpointed every cursorCheckpointLines domains")
+	assert.Equal(t, zoneDomainsPerRun, cursors[len(cursors)-1])
+
+	progress, again, cursors := runZoneBatch(t, *next)
+	require.Nil(t, again)
+	assert.Equal(t, len(batch.Domains), progress.Total)
+	assert.Equal(t, len(batch.Domains)-1, progress.Minted)
+	assert.Equal(t, 1, progress.Duplicates)
+	assert.Equal(t, []int{zoneDomainsPerRun + 50}, cursors, "the last line is checkpointed when the zone is done")
+}
@@ -0,0 +1,236 @@
+package temporal
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/compressed"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domain"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/domainlist"
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/zonepolicy"
+)
+
+// Issues a zone audit flags
+const (
+	ZoneAuditMissing   = "missing"   // The source expects the domain, the collection has no NFT of it
+	ZoneAuditExtra     = "extra"     // The collection has an NFT of a domain the source does not expect
+	ZoneAuditDuplicate = "duplicate" // The collection has several NFTs of the domain
+)
+
+// ZoneAuditRequest is the input of ZoneAuditActivity
+type ZoneAuditRequest struct {
+	Zone        string
+	FilePath    string // Event log, or domain list with List set; compressed files are decompressed while read
+	List        bool   // The file is a domain list, plain or CSV, of the domains the zone should have
+	IgnoreExtra bool   // Leave out the NFTs of domains the file does not mention, e.g. for a file of one day of events
+}
+
+// ZoneAuditFinding is a domain a zone audit flags
+type ZoneAuditFinding struct {
+	Issue   string   `json:"issue"`
+	Domain  string   `json:"domain"`
+	NFTs    []string `json:"nfts,omitempty"`    // Live NFTs of the domain in the collection, as token/serial
+	Line    int      `json:"line,omitempty"`    // Line of the file that last set the expected state of the domain
+	Deleted bool     `json:"deleted,omitempty"` // For extra NFTs: the file burns the domain
+	Indexed bool     `json:"indexed,omitempty"` // The domain index records an NFT of the domain
+}
+
+// ZoneAudit is the outcome of comparing a source file with the collection of a zone
+type ZoneAudit struct {
+	Zone       string             `json:"zone"`
+	FilePath   string             `json:"file_path"`
+	TokenIDs   []string           `json:"token_ids"` // Shards of the collection, newest first
+	Expected   int                `json:"expected"`  // Domains the file expects to be minted
+	Burned     int                `json:"burned"`    // Domains the file expects to be burned
+	Minted     int                `json:"minted"`    // Live NFTs of the collection
+	Indexed    bool               `json:"indexed"`   // Cross-checked against the registry store
+	Missing    int                `json:"missing"`
+	Extra      int                `json:"extra"`
+	Duplicates int                `json:"duplicates"`
+	Findings   []ZoneAuditFinding `json:"findings,omitempty"`
+}
+
+// ZoneAuditActivity compares the domains a source file expects in a zone with the NFTs of the collection of the
+// zone. An event log is replayed through the policy of the zone: the domains of the events it mints are
+// expected, and those of the events it burns are not, the last event of a domain deciding. A domain list expects
+// all of its domains. The NFTs of every shard of the collection are listed on the mirror node, and with
+// REGISTRY_STORE_DSN the findings tell whether the domain index records an NFT of their domain, e.g. one minted
+// too recently to show on the mirror node.
+func (a *Activities) ZoneAuditActivity(ctx context.Context, req ZoneAuditRequest) (ZoneAudit, error) {
+	zone, err := domain.NewZone(req.Zone)
+	if err != nil {
+		return ZoneAudit{}, err
+	}
+	audit := ZoneAudit{Zone: zone.String(), FilePath: req.FilePath}
+
+	// Domain -> line of the file that last expected it minted (positive) or burned (negative)
+	var expected map[string]int
+	if req.List {
+		expected, err = listedDomains(req.FilePath, audit.Zone)
+	} else {
+		expected, err = a.loggedDomains(ctx, req.FilePath, audit.Zone)
+	}
+	if err != nil {
+		return audit, err
+	}
+	for _, line := range expected {
+		if line > 0 {
+			audit.Expected++
+		} else {
+			audit.Burned++
+		}
+	}
+
+	collections, err := a.ListZoneCollectionsActivity(ctx)
+	if err != nil {
+		return audit, err
+	}
+	var collection *ZoneCollectionInfo
+	for i := range collections {
+		if collections[i].Zone == zone && collections[i].TokenID != "" {
+			collection = &collections[i]
+		}
+	}
+	if collection == nil {
+		return audit, fmt.Errorf("no collection of .%s in the zone registry", audit.Zone)
+	}
+	audit.TokenIDs = collection.TokenIDs()
+
+	// Domain -> its live NFTs
+	nfts := make(map[string][]string)
+	for _, tokenID := range audit.TokenIDs {
+		listed, err := a.queryCollectionNFTs(ctx, tokenID)
+		if err != nil {
+			return audit, fmt.Errorf("failed to list the NFTs of %s: %w", tokenID, err)
+		}
+		for _, nft := range listed {
+			if nft.Deleted {
+				continue
+			}
+			audit.Minted++
+			name := nftDomainName(nft, audit.Zone)
+			nfts[name] = append(nfts[name], indexKey(tokenID, nft.SerialNumber))
+		}
+		heartbeat(ctx, tokenID)
+	}
+
+	indexed := make(map[string]bool)
+	s, err := a.domainStore(ctx)
+	if err != nil {
+		return audit, fmt.Errorf("failed to open the registry store: %w", err)
+	}
+	if s != nil {
+		index, err := zoneIndex(ctx, s, zone)
+		if err != nil {
+			return audit, fmt.Errorf("failed to read the domain index of .%s: %w", audit.Zone, err)
+		}
+		for _, d := range index {
+			indexed[d.Name] = true
+		}
+		audit.Indexed = true
+	}
+
+	flag := func(issue, name string) {
+		line := expected[name]
+		finding := ZoneAuditFinding{Issue: issue, Domain: name, NFTs: nfts[name], Line: line, Indexed: indexed[name]}
+		if line < 0 {
+			finding.Line, finding.Deleted = -line, true
+		}
+		audit.Findings = append(audit.Findings, finding)
+	}
+	for name, line := range expected {
+		if line > 0 && len(nfts[name]) == 0 {
+			flag(ZoneAuditMissing, name)
+			audit.Missing++
+		}
+	}
+	for name, held := range nfts {
+		line, ok := expected[name]
+		// A domain the file burns is flagged even when the NFTs of other domains are left out
+		if !ok && !req.IgnoreExtra || ok && line < 0 {
+			flag(ZoneAuditExtra, name)
+			audit.Extra++
+		}
+		if len(held) > 1 && (ok || !req.IgnoreExtra) {
+			flag(ZoneAuditDuplicate, name)
+			audit.Duplicates++
+		}
+	}
+	sort.Slice(audit.Findings, func(i, j int) bool {
+		if audit.Findings[i].Domain != audit.Findings[j].Domain {
+			return audit.Findings[i].Domain < audit.Findings[j].Domain
+		}
+		return audit.Findings[i].Issue < audit.Findings[j].Issue
+	})
+	fmt.Printf("Audited .%s against %s: %d expected, %d NFTs, %d missing, %d extra, %d duplicates\n",
+		audit.Zone, req.FilePath, audit.Expected, audit.Minted, audit.Missing, audit.Extra, audit.Duplicates)
+	return audit, nil
+}
+
+// loggedDomains replays the domain events of a zone in an event log through the policy of the zone
+func (a *Activities) loggedDomains(ctx context.Context, filePath, zone string) (map[string]int, error) {
+	policy, err := a.ZonePolicyActivity(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	parser, err := a.eventParser()
+	if err != nil {
+		return nil, err
+	}
+	file, err := compressed.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	expected := make(map[string]int)
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		info, quarantined, err := parser.parse(scanner.Text(), lineNumber)
+		switch {
+		case errors.Is(err, errNotAnEvent), errors.Is(err, errNotADomainEvent):
+			continue
+		case quarantined != nil:
+			fmt.Printf("Skipping event on line %d, it would be quarantined: %s\n", lineNumber, quarantined.Reason)
+			continue
+		case err != nil:
+			fmt.Printf("Skipping event on line %d: %v\n", lineNumber, err)
+			continue
+		}
+		if info.Zone.String() != zone {
+			continue
+		}
+		switch policy.Action(info.Action) {
+		case zonepolicy.ActionMint:
+			expected[info.DomainName] = lineNumber
+		case zonepolicy.ActionBurn:
+			expected[info.DomainName] = -lineNumber
+		}
+	}
+	return expected, scanner.Err()
+}
+
+// listedDomains returns the domains of a zone in a domain list
+func listedDomains(filePath, zone string) (map[string]int, error) {
+	file, err := compressed.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	expected := make(map[string]int)
+	err = domainlist.Read(file, domainlist.IsCSV(compressed.TrimExt(filePath)), func(entry domainlist.Entry) error {
+		if entry.Err != nil {
+			fmt.Printf("Skipping invalid entry: %v\n", entry.Err)
+			return nil
+		}
+		if z, err := domain.NewZone(entry.Zone); err == nil && z.String() == zone {
+			expected[entry.Domain] = entry.Line
+		}
+		return nil
+	})
+	return expected, err
+}
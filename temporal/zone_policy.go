@@ -0,0 +1,162 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	hedera "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"go.temporal.io/sdk/temporal"
+
+	"github.com/onasunnymorning/shadow-domain-ledger/pkg/zonepolicy"
+)
+
+// ErrTypeNotInTreasury is the application error type of burns of NFTs that were transferred out of the treasury
+const ErrTypeNotInTreasury = "NotInTreasury"
+
+// BurnResult is the outcome of a successful BurnNFTActivity
+type BurnResult struct {
+	Domain        string    `json:"domain"`
+	TokenID       string    `json:"token_id"`
+	SerialNumber  int64     `json:"serial_number,omitempty"`
+	TransactionID string    `json:"transaction_id,omitempty"` // Empty when nothing was burned
+	NotMinted     bool      `json:"not_minted"`               // The domain has no NFT, or it was burned already
+	ConsensusAt   time.Time `json:"consensus_at,omitempty"`
+	FeeTinybar    int64     `json:"fee_tinybar,omitempty"` // Fee charged for the burn
+	WipedFrom     string    `json:"wiped_from,omitempty"`  // The holder the NFT was wiped from, outside the treasury
+}
+
+// ZonePolicyActivity returns the processing policy of a zone from ZONE_POLICY_FILE, or the zero policy, which
+// mints every event, when it is unset. The metadata store the policy names must be configured on the worker.
+func (a *Activities) ZonePolicyActivity(ctx context.Context, zone string) (zonepolicy.Policy, error) {
+	if a.Config.Zones.PolicyFile == "" {
+		return zonepolicy.Policy{}, nil
+	}
+	file, err := zonepolicy.Load(a.Config.Zones.PolicyFile)
+	if err != nil {
+		return zonepolicy.Policy{}, fmt.Errorf("failed to load zone policies: %w", err)
+	}
+	policy := file.For(zone)
+	if policy.MetadataStore != zonepolicy.StoreNone {
+		if errs := a.Config.Metadata.ValidateStore(policy.MetadataStore); len(errs) > 0 {
+			return policy, fmt.Errorf("metadata store %s of .%s: %w", policy.MetadataStore, zone, errors.Join(errs...))
+		}
+	}
+	return policy, nil
+}
+
+// BurnNFTActivity burns the NFT of a domain, e.g. when its registration is deleted. NFTs held outside the
+// treasury are wiped from their holder when an admin signer holds the wipe key of the collection, and fail with
+// ErrTypeNotInTreasury otherwise: collections only have a wipe key when created with an admin signer. Domains without
+// an NFT, or whose NFT was burned already, are reported as not minted.
+func (a *Activities) BurnNFTActivity(ctx context.Context, info MintingInfo, zoneCollection ZoneCollectionInfo) (BurnResult, error) {
+	result := BurnResult{Domain: info.DomainName, TokenID: zoneCollection.TokenID}
+	minted, nft, err := a.isDomainAlreadyMinted(ctx, info.DomainName, zoneCollection)
+	if err != nil {
+		return result, fmt.Errorf("failed to look up the NFT of %s: %w", info.DomainName, err)
+	}
+	if !minted {
+		fmt.Printf("Domain %s has no NFT in collection %s, nothing to burn\n", info.DomainName, a.displayID(zoneCollection.TokenID))
+		result.NotMinted = true
+		return result, nil
+	}
+	result.SerialNumber = nft.SerialNumber
+	// The NFT may be in an earlier shard of the zone
+	if nft.TokenID != "" {
+		result.TokenID = nft.TokenID
+	}
+
+	accountID, privateKey, err := a.operatorCredentials()
+	if err != nil {
+		return result, err
+	}
+	// NFTs held outside the treasury are wiped from their holder, with the admin signer holding the wipe key
+	admin, err := a.adminSigner()
+	if err != nil {
+		return result, err
+	}
+	wipe := nft.AccountID != accountID.String()
+	if wipe && admin == nil {
+		return result, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("NFT %d of %s is held by %s, only NFTs in the treasury can be burned", nft.SerialNumber, info.DomainName, nft.AccountID),
+			ErrTypeNotInTreasury, nil)
+	}
+	tokenID, err := a.tokenIDFromString(result.TokenID)
+	if err != nil {
+		return result, fmt.Errorf("invalid zone collection token ID: %w", err)
+	}
+	client, err := a.newHederaClient()
+	if err != nil {
+		return result, err
+	}
+	defer client.Close()
+	client.SetOperator(accountID, privateKey)
+
+	var memo string
+	if info.EventHash != "" {
+		memo = EventMemo(info.RunID, info.EventHash)
+	}
+	if err := a.txLimiter.Wait(ctx); err != nil {
+		return result, err
+	}
+	if workerStopping(ctx) {
+		return result, errWorkerShutdown("burn for " + info.DomainName)
+	}
+	var txResponse hedera.TransactionResponse
+	if wipe {
+		txResponse, err = a.wipeNFT(ctx, client, admin, tokenID, nft.AccountID, nft.SerialNumber, memo)
+	} else {
+		txResponse, err = hedera.NewTokenBurnTransaction().
+			SetTokenID(tokenID).
+			SetSerialNumbers([]int64{nft.SerialNumber}).
+			SetTransactionMemo(memo).
+			Execute(client)
+	}
+	// Collections created without an admin signer have no wipe key, wipes of their NFTs fail at precheck or consensus
+	errNoWipeKey := func(err error) error {
+		return temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("NFT %d of %s is held by %s and %s has no wipe key", nft.SerialNumber, info.DomainName, nft.AccountID, result.TokenID),
+			ErrTypeNotInTreasury, err)
+	}
+	if wipe && isStatus(err, hedera.StatusTokenHasNoWipeKey) {
+		return result, errNoWipeKey(err)
+	}
+	if err != nil {
+		return result, fmt.Errorf("transaction execution failed: %w", err)
+	}
+	heartbeat(ctx, "submitted", txResponse.TransactionID.String())
+	record, err := txResponse.GetRecord(client)
+	if wipe && isStatus(err, hedera.StatusTokenHasNoWipeKey) {
+		return result, errNoWipeKey(err)
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to get transaction record: %w", err)
+	}
+	result.TransactionID = txResponse.TransactionID.String()
+	result.ConsensusAt = record.ConsensusTimestamp
+	result.FeeTinybar = record.TransactionFee.AsTinybar()
+	txType := TransactionTokenBurn
+	if wipe {
+		txType = TransactionTokenWipe
+		result.WipedFrom = nft.AccountID
+	}
+	txRecord := newTransactionRecord(txType, info.Zone.String(), result.TokenID, record)
+	txRecord.Domain = info.DomainName
+	// The receipts of burns and wipes carry no serials
+	txRecord.Serials = []int64{nft.SerialNumber}
+	a.saveTransactionRecord(ctx, txRecord)
+	a.storeBurn(ctx, info.DomainName, result.TransactionID, result.ConsensusAt)
+	a.publishLedgerEvent(ctx, LedgerEvent{
+		Type:          LedgerEventBurn,
+		Domain:        info.DomainName,
+		Zone:          info.Zone.String(),
+		TokenID:       result.TokenID,
+		SerialNumber:  nft.SerialNumber,
+		TransactionID: result.TransactionID,
+		At:            result.ConsensusAt,
+	})
+	fmt.Printf("Burned NFT %d of %s in .%s collection (token ID: %s)\n",
+		nft.SerialNumber, info.DomainName, info.Zone, a.displayID(result.TokenID))
+	return result, nil
+}